@@ -5,6 +5,7 @@ import (
 	"Infiya-ai-pipeline/internal/handlers"
 	"Infiya-ai-pipeline/internal/middleware"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 	"Infiya-ai-pipeline/internal/routes"
 	"Infiya-ai-pipeline/internal/services"
 	"context"
@@ -16,6 +17,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // timezone preferences must resolve in minimal containers
 )
 
 const (
@@ -43,6 +45,12 @@ func main() {
 		"port", config.HTTP.Port,
 		"log_level", config.Log.Level)
 
+	shutdownTracing, err := tracing.Init(context.Background(), config.Tracing, serviceName, serviceVersion)
+	if err != nil {
+		appLogger.WithError(err).Fatal("Failed to initialize tracing")
+	}
+	appLogger.Info("Tracing initialized", "exporter", config.Tracing.Exporter, "sample_ratio", config.Tracing.SampleRatio)
+
 	if config.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 		appLogger.Info("Running in production mode")
@@ -56,13 +64,14 @@ func main() {
 		appLogger.WithError(err).Fatal("Failed to initialize services")
 	}
 
-	handlerContainer := initializeHandlers(serviceContainer.orchestrator, appLogger)
+	handlerContainer := initializeHandlers(config, serviceContainer.orchestrator, appLogger)
 
 	router := gin.New()
 
 	setupMiddleware(router, config, appLogger)
 
-	routes.SetupRoutes(router, handlerContainer.workflow, handlerContainer.health, handlerContainer.metrics)
+	routes.SetupRoutes(router, handlerContainer.workflow, handlerContainer.health, handlerContainer.metrics,
+		handlerContainer.admin, handlerContainer.subscription, handlerContainer.debug, middleware.AdminAuthMiddleware(config.Admin))
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", config.HTTP.Port),
@@ -72,6 +81,7 @@ func main() {
 		IdleTimeout:  config.HTTP.IdleTimeout,
 	}
 
+	// the listener comes up first so liveness and readiness probes answer while dependencies warm up
 	go func() {
 		appLogger.Info("HTTP server starting",
 			"addr", server.Addr,
@@ -85,6 +95,20 @@ func main() {
 		}
 	}()
 
+	if err := awaitDependencies(config, serviceContainer.orchestrator, appLogger); err != nil {
+		appLogger.WithError(err).Fatal("Startup health gate failed")
+	}
+	serviceContainer.orchestrator.MarkReady()
+
+	pollerCtx, stopPoller := context.WithCancel(context.Background())
+	defer stopPoller()
+	if config.Subscriptions.Enabled {
+		go serviceContainer.orchestrator.RunSubscriptionPoller(pollerCtx)
+	}
+	if config.Etc.ChromaCompactionInterval > 0 {
+		go serviceContainer.orchestrator.RunCompaction(pollerCtx)
+	}
+
 	appLogger.Info("Service started successfully",
 		"service", serviceName,
 		"version", serviceVersion,
@@ -104,10 +128,16 @@ func main() {
 	// Block until signal received
 	sig := <-quit
 	appLogger.Info("Received shutdown signal", "signal", sig.String())
+	stopPoller()
 
-	// Graceful shutdown with timeout
-	appLogger.Info("Starting graceful shutdown...")
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
+	// Stop taking new workflows, let in-flight ones finish within the grace period, then cancel the rest
+	appLogger.Info("Starting graceful shutdown...", "grace_period", config.HTTP.ShutdownGracePeriod)
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), config.HTTP.ShutdownGracePeriod)
+	cancelled := serviceContainer.orchestrator.Drain(drainCtx)
+	cancelDrain()
+	appLogger.Info("Workflow drain completed", "cancelled_workflows", cancelled)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
@@ -123,6 +153,11 @@ func main() {
 		appLogger.Info("Service cleanup completed")
 	}
 
+	// flush buffered spans last so the shutdown itself is traced
+	if err := shutdownTracing(ctx); err != nil {
+		appLogger.WithError(err).Error("Failed to flush traces")
+	}
+
 	appLogger.Info("Infiya Manager AI Service shutdown complete",
 		"service", serviceName,
 		"version", serviceVersion,
@@ -130,14 +165,23 @@ func main() {
 
 }
 
-func initializeHandlers(orchestrator *services.Orchestrator, logger *logger.Logger) *HandlerContainer {
+func initializeHandlers(config *config.Config, orchestrator *services.Orchestrator, logger *logger.Logger) *HandlerContainer {
 	logger.Info("Initializing HTTP handlers")
 
-	return &HandlerContainer{
-		workflow: handlers.NewWorkflowHandler(orchestrator, logger),
-		health:   handlers.NewHealthHandler(orchestrator, logger),
-		metrics:  handlers.NewMetricsHandler(orchestrator, logger),
+	container := &HandlerContainer{
+		workflow:     handlers.NewWorkflowHandler(orchestrator, logger),
+		health:       handlers.NewHealthHandler(orchestrator, logger),
+		metrics:      handlers.NewMetricsHandler(orchestrator, logger),
+		admin:        handlers.NewAdminHandler(orchestrator, logger),
+		subscription: handlers.NewSubscriptionHandler(orchestrator, logger),
+	}
+
+	if config.Admin.DebugEndpoints {
+		logger.Warn("Debug endpoints enabled")
+		container.debug = handlers.NewDebugHandler(orchestrator, logger)
 	}
+
+	return container
 }
 
 func (sc *ServiceContainer) close() error {
@@ -164,9 +208,13 @@ type ServiceContainer struct {
 }
 
 type HandlerContainer struct {
-	workflow *handlers.WorkflowHandler
-	health   *handlers.HealthHandler
-	metrics  *handlers.MetricsHandler
+	workflow     *handlers.WorkflowHandler
+	health       *handlers.HealthHandler
+	metrics      *handlers.MetricsHandler
+	admin        *handlers.AdminHandler
+	subscription *handlers.SubscriptionHandler
+	// nil unless debug endpoints are enabled
+	debug *handlers.DebugHandler
 }
 
 func initializeServices(config *config.Config, logger *logger.Logger) (*ServiceContainer, error) {
@@ -208,25 +256,48 @@ func initializeServices(config *config.Config, logger *logger.Logger) (*ServiceC
 	}
 
 	logger.Info("Initializing ChromaDB service", "url", config.Etc.ChromaDBURL)
-	chromaDBService, err := services.NewChromaDBService(config.Etc, logger)
+	// chroma creates its collections on construction, give it the startup window to come up
+	var chromaDBService *services.ChromaDBService
+	err = retryStartup(config, "chromadb", logger, func(ctx context.Context) error {
+		var initErr error
+		chromaDBService, initErr = services.NewChromaDBService(config.Etc, logger)
+		return initErr
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize ChromaDB service: %w", err)
 	}
 
-	logger.Info("Initializing News service")
-	newsService, err := services.NewNewsService(config.Etc, logger)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize News service: %w", err)
+	if config.Etc.ArticleContentStore == services.ContentStoreRedis {
+		chromaDBService.SetContentStore(services.NewRedisContentStore(redisService, config.Etc.ArticleContentTTL))
+		logger.Info("Article content stored in redis", "ttl", config.Etc.ArticleContentTTL)
 	}
 
-	logger.Info("Initializing Youtube service")
-	youtubeService, err := services.NewYouTubeService(config.Youtube, logger)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Youtube service: %w", err)
+	var newsService *services.NewsService
+	var youtubeService *services.YouTubeService
+	if config.Eval.Enabled() {
+		logger.Info("Loading fixture corpus for evaluation", "path", config.Eval.FixturesPath)
+		corpus, err := services.LoadFixtureCorpus(config.Eval.FixturesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fixture corpus: %w", err)
+		}
+		newsService = services.NewFixtureNewsService(corpus, logger)
+		youtubeService = services.NewFixtureYouTubeService(corpus, logger)
+	} else {
+		logger.Info("Initializing News service")
+		newsService, err = services.NewNewsService(config.Etc, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize News service: %w", err)
+		}
+
+		logger.Info("Initializing Youtube service")
+		youtubeService, err = services.NewYouTubeService(config.Youtube, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Youtube service: %w", err)
+		}
 	}
 
 	logger.Info("Initializing Scraper service")
-	scraperService, err := services.NewScraperService(config.Scraper, logger)
+	scraperService, err := services.NewScraperService(config.Scraper, redisService, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Scraper service: %w", err)
 	}
@@ -244,15 +315,23 @@ func initializeServices(config *config.Config, logger *logger.Logger) (*ServiceC
 		logger,
 	)
 
-	// logger.Info("Performing initial health checks...")
-	// ctx, cancel := context.WithTimeout(context.Background(), 300*time.Second)
-	// defer cancel()
+	if config.Embedding.Fallback == services.EmbeddingFallbackGemini {
+		fallback := services.NewGeminiEmbeddingService(geminiService, config.Embedding, logger)
+		orchestrator.SetEmbeddingProvider(services.NewFallbackEmbeddingProvider(ollamaService, fallback, config.Embedding, logger))
+	}
 
-	// if err := orchestrator.HealthCheck(ctx); err != nil {
-	// 	logger.WithError(err).Warn("Initial health check failed, but continuing startup")
-	// } else {
-	// 	logger.Info("All services passed initial health checks")
-	// }
+	switch config.Etc.ResultStore {
+	case services.ResultStoreRedis:
+		orchestrator.SetResultStore(services.NewRedisResultStore(redisService, config.Etc.ResultRetention))
+		logger.Info("Workflow results persisted in redis", "retention", config.Etc.ResultRetention)
+	case services.ResultStoreFile:
+		resultStore, err := services.NewFileResultStore(config.Etc.ResultStoreDir, config.Etc.ResultRetention)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize result store: %w", err)
+		}
+		orchestrator.SetResultStore(resultStore)
+		logger.Info("Workflow results persisted to disk", "dir", config.Etc.ResultStoreDir, "retention", config.Etc.ResultRetention)
+	}
 
 	logger.Info("All services initialized successfully")
 
@@ -268,12 +347,44 @@ func initializeServices(config *config.Config, logger *logger.Logger) (*ServiceC
 
 }
 
+func retryStartup(config *config.Config, name string, logger *logger.Logger, fn func(ctx context.Context) error) error {
+	if !config.Startup.HealthGate {
+		return fn(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Startup.Deadline)
+	defer cancel()
+
+	return services.RetryWithBackoff(ctx, config.Startup, name, logger, fn)
+}
+
+func awaitDependencies(config *config.Config, orchestrator *services.Orchestrator, logger *logger.Logger) error {
+	if !config.Startup.HealthGate {
+		logger.Info("Startup health gate disabled, serving immediately")
+		return nil
+	}
+
+	logger.Info("Waiting for dependencies before serving traffic",
+		"deadline", config.Startup.Deadline,
+		"critical", config.Startup.CriticalServices)
+
+	if err := services.WaitForDependencies(context.Background(), config.Startup, orchestrator.DependencyChecks(), logger); err != nil {
+		return err
+	}
+
+	logger.Info("Startup health gate passed")
+	return nil
+}
+
 func setupMiddleware(router *gin.Engine, config *config.Config, logger *logger.Logger) {
 	logger.Info("Setting up middleware stack ")
 
 	router.Use(gin.Recovery())
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.LoggingMiddleware(logger))
+	router.Use(middleware.TenantMiddleware(config.Tenants))
+	router.Use(middleware.AdminFlagMiddleware(config.Admin))
+	router.Use(middleware.BodyLimitMiddleware(config.HTTP.MaxRequestBodyBytes))
 
 	logger.Info("Middleware Stack Configured Successfully")
 
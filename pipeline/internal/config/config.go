@@ -4,31 +4,90 @@ import (
 	"fmt"
 	"github.com/joho/godotenv"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	Environment string        `json:"environment"`
-	HTTP        HTTPConfig    `json:"http"`
-	Redis       RedisConfig   `json:"redis"`
-	Ollama      OllamaConfig  `json:"ollama"`
-	Gemini      GeminiConfig  `json:"gemini"`
-	Scraper     ScraperConfig `json:"scraper"`
-	Log         LogConfig     `json:"log"`
-	Youtube     YoutubeConfig `json:"youtube"`
-	Etc         EtcConfig     `json:"etc"`
+	Environment string          `json:"environment"`
+	HTTP        HTTPConfig      `json:"http"`
+	Redis       RedisConfig     `json:"redis"`
+	Ollama      OllamaConfig    `json:"ollama"`
+	Embedding   EmbeddingConfig `json:"embedding"`
+	Gemini      GeminiConfig    `json:"gemini"`
+	Scraper     ScraperConfig   `json:"scraper"`
+	Log         LogConfig       `json:"log"`
+	Youtube     YoutubeConfig   `json:"youtube"`
+	Etc         EtcConfig       `json:"etc"`
+	Workflow    WorkflowConfig  `json:"workflow"`
+	Tenants     TenantConfig    `json:"tenants"`
+	Safety      SafetyConfig    `json:"safety"`
+	Admin       AdminConfig     `json:"admin"`
+	Startup     StartupConfig   `json:"startup"`
+	Categories  CategoryConfig  `json:"categories"`
+	Tracing     TracingConfig   `json:"tracing"`
+	Quality     QualityConfig   `json:"quality"`
+	Pricing     PricingConfig   `json:"pricing"`
+	// "follow the story" subscriptions polled in the background
+	Subscriptions SubscriptionConfig `json:"subscriptions"`
+	Eval          EvalConfig         `json:"eval"`
+	UserIDs       UserIDConfig       `json:"user_ids"`
+}
+
+// EvalConfig switches the pipeline to a frozen corpus for offline evaluation
+type EvalConfig struct {
+	// JSON corpus of articles, videos and transcripts served instead of NewsAPI and YouTube, articles are not
+	// scraped either so a query always meets the same sources
+	FixturesPath string `json:"fixtures_path,omitempty"`
+	// "off", "request" records the workflows whose request metadata sets "record", which takes the admin key,
+	// and "all" records every one. A recording keeps the fetched articles and videos, transcripts, scrapes,
+	// embeddings and model outputs of the run in RecordingDir, and the admin replay endpoint reruns the pipeline
+	// against them.
+	WorkflowRecording string `json:"workflow_recording"`
+	RecordingDir      string `json:"recording_dir,omitempty"`
+	// recordings hold raw sources and model outputs, they are deleted after the retention and only the newest
+	// RecordingMaxFiles are kept
+	RecordingRetention time.Duration `json:"recording_retention"`
+	RecordingMaxFiles  int           `json:"recording_max_files"`
+}
+
+// WorkflowRecordingModes lists the accepted workflow recording modes
+var WorkflowRecordingModes = []string{"off", "request", "all"}
+
+// Enabled reports whether the pipeline runs against the fixture corpus
+func (eval EvalConfig) Enabled() bool {
+	return eval.FixturesPath != ""
 }
 
 type HTTPConfig struct {
-	Port         int           `json:"port"`
-	ReadTimeout  time.Duration `json:"read_timeout"`
+	Port        int           `json:"port"`
+	ReadTimeout time.Duration `json:"read_timeout"`
+	// the workflow endpoint extends its own write deadline to cover the queue timeout plus the workflow deadline,
+	// and streamed responses extend it per line, so this only bounds the short endpoints
 	WriteTimeout time.Duration `json:"write_timeout"`
 	IdleTimeout  time.Duration `json:"idle_timeout"`
+	// how long in-flight workflows may run after a shutdown signal before they are cancelled
+	ShutdownGracePeriod time.Duration `json:"shutdown_grace_period"`
+	// request bodies above this are rejected with 413 before a handler reads them
+	MaxRequestBodyBytes int64 `json:"max_request_body_bytes"`
 }
 
 type YoutubeConfig struct {
 	APIKey string `json:"api_key"`
+	// serve videos from the vector store while the daily API quota is exhausted
+	QuotaFallback bool `json:"quota_fallback"`
+	// videos below these are dropped before relevancy, zero disables a filter.
+	// The subscriber filter costs an extra channels lookup per fetch
+	MinDuration    time.Duration `json:"min_duration"`
+	MinViews       int64         `json:"min_views"`
+	MinSubscribers int64         `json:"min_subscribers"`
+	// fetched transcripts are reused across workflows for this long, zero disables the cache
+	TranscriptCacheTTL time.Duration `json:"transcript_cache_ttl"`
+	// videos without a transcript or a usable description get a generated summary, at most this many per
+	// workflow, the rest keep their raw description. Zero never generates one
+	MaxFallbackGenerations int `json:"max_fallback_generations"`
 }
 
 type RedisConfig struct {
@@ -38,6 +97,11 @@ type RedisConfig struct {
 	DialTimeout  time.Duration `json:"dial_timeout"`
 	ReadTimeout  time.Duration `json:"read_timeout"`
 	WriteTimeout time.Duration `json:"write_timeout"`
+	// when set the pipeline keeps serving statelessly while redis is unreachable
+	AllowStateless      bool          `json:"allow_stateless"`
+	HealthCheckInterval time.Duration `json:"health_check_interval"`
+	// stored conversation exchanges above this are cut back to the most recent ones on read instead of parsed whole
+	MaxContextBytes int `json:"max_context_bytes"`
 }
 
 // ollama for generating embeddings
@@ -49,6 +113,19 @@ type OllamaConfig struct {
 	RetryDelay     time.Duration `json:"retry_delay"`
 }
 
+// EmbeddingConfig is the provider that takes over embeddings while Ollama is unreachable
+type EmbeddingConfig struct {
+	// "none" or "gemini"
+	Fallback      string `json:"fallback"`
+	FallbackModel string `json:"fallback_model"`
+	// dimension of the vectors in the chroma collections, the fallback's output is cut to it and rejected if it
+	// still differs, 768 matches nomic-embed-text
+	Dimensions int `json:"dimensions"`
+	// consecutive Ollama failures that open its circuit, calls go straight to the fallback for the cooldown
+	FailureThreshold int           `json:"failure_threshold"`
+	Cooldown         time.Duration `json:"cooldown"`
+}
+
 // gemini for generating text
 type GeminiConfig struct {
 	APIKey      string        `json:"api_key"`
@@ -58,12 +135,410 @@ type GeminiConfig struct {
 	MaxTokens   int           `json:"max_tokens,omitempty"`
 	Temperature float64       `json:"temperature,omitempty"`
 	Timeout     time.Duration `json:"timeout"`
+	// caps simultaneous generation calls, requests beyond MaxQueue waiting callers fail fast
+	MaxConcurrency int `json:"max_concurrency"`
+	MaxQueue       int `json:"max_queue"`
+	// per agent sampling overrides keyed by agent name, agents keep their built in values for anything unset
+	AgentSampling map[string]AgentSampling `json:"agent_sampling,omitempty"`
+	// per agent model overrides keyed by agent name, so cheap agents can run on a faster model than the summarizer
+	AgentModels map[string]string `json:"agent_models,omitempty"`
+	// directory of <name>.tmpl files overriding the embedded prompt templates, empty uses the defaults only
+	PromptDir string `json:"prompt_dir,omitempty"`
+	// the conversation details rendered into the keyword, intent and follow-up prompts, from PromptContextFieldNames,
+	// and the size of the rendered snippet, empty fields renders all of them
+	PromptContextFields   []string `json:"prompt_context_fields,omitempty"`
+	PromptContextMaxChars int      `json:"prompt_context_max_chars"`
+	// persona prompts for a non-english answer instruct in the target language and ask for the persona as a native
+	// speaker would voice it, off appends the plain response language section instead
+	NativePersonaLanguage bool `json:"native_persona_language"`
+	// prompts estimated above PromptBudgetFraction of the model's ContextWindowTokens are trimmed by the calling
+	// agent's strategy from PromptTrimStrategyNames or rejected before they are sent, a 0 window disables the guard
+	ContextWindowTokens  int               `json:"context_window_tokens"`
+	PromptBudgetFraction float64           `json:"prompt_budget_fraction"`
+	PromptTrimStrategies map[string]string `json:"prompt_trim_strategies,omitempty"`
+}
+
+// PromptTrimStrategyNames lists how an oversized prompt is handled. "middle" cuts content out of the middle of the
+// prompt, keeping the opening instructions and the closing output format, agents without a strategy are rejected.
+var PromptTrimStrategyNames = []string{"middle", "reject"}
+
+// PromptContextFieldNames lists the conversation details a prompt can be given, in the order they are rendered
+var PromptContextFieldNames = []string{"intent", "topics", "keywords", "previous_query", "referenced_topic", "summary", "preferences"}
+
+// ChromaQueryIncludeFields lists the fields a similarity query may ask ChromaDB for, metadatas and distances are required
+var ChromaQueryIncludeFields = []string{"documents", "metadatas", "distances"}
+
+type AgentSampling struct {
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+	TopK        *float32 `json:"top_k,omitempty"`
+	MaxTokens   int32    `json:"max_tokens,omitempty"`
 }
 
 type EtcConfig struct {
 	NewsApiKey         string `json:"news_api_key"`
 	ChromaDBURL        string `json:"chroma_db_url"`
 	ChromaDBCollection string `json:"chroma_db_collection"`
+	// checked at startup, a missing tenant or database fails startup unless ChromaAutoCreate creates it
+	ChromaTenant     string `json:"chroma_tenant"`
+	ChromaDatabase   string `json:"chroma_database"`
+	ChromaAutoCreate bool   `json:"chroma_auto_create"`
+	// each application tenant stores and searches its own news and video collections, named after the tenant
+	ChromaTenantCollections bool `json:"chroma_tenant_collections"`
+	// vector search results below this cosine similarity are discarded
+	ChromaMinSimilarity float64 `json:"chroma_min_similarity"`
+	// large stores are split into batches submitted with bounded concurrency
+	ChromaBatchSize            int `json:"chroma_batch_size"`
+	ChromaBatchConcurrency     int `json:"chroma_batch_concurrency"`
+	ChromaMetadataContentLimit int `json:"chroma_metadata_content_limit"`
+	// duplicate documents (the same url stored under different ids) are removed every interval, 0 leaves
+	// compaction to the admin endpoint
+	ChromaCompactionInterval time.Duration `json:"chroma_compaction_interval"`
+	ChromaCompactionPageSize int           `json:"chroma_compaction_page_size"`
+	// fields returned by similarity queries, from ChromaQueryIncludeFields. Descriptions are read from metadata when
+	// documents are left out, embeddings are never requested.
+	ChromaQueryInclude []string `json:"chroma_query_include"`
+	// "metadata" keeps article bodies in chroma as before, "redis" stores them separately by article id
+	ArticleContentStore string        `json:"article_content_store"`
+	ArticleContentTTL   time.Duration `json:"article_content_ttl"`
+	// completed workflow results outlive the 6 hour workflow state in "redis" or in ResultStoreDir with "file",
+	// "none" keeps only the workflow state
+	ResultStore     string        `json:"result_store"`
+	ResultRetention time.Duration `json:"result_retention"`
+	ResultStoreDir  string        `json:"result_store_dir"`
+	// hybrid search only ranks stored articles that mention a query keyword and were published within the lookback
+	ChromaHybridSearch   bool          `json:"chroma_hybrid_search"`
+	ChromaHybridLookback time.Duration `json:"chroma_hybrid_lookback"`
+	// "off" ORs the keywords as extracted, "order" puts entities and specific terms first and "boost" also
+	// requires articles to mention one of the entity keywords
+	KeywordWeighting string `json:"keyword_weighting"`
+	// rate limited and transient NewsAPI failures are retried, a rate limit asking for a longer wait than
+	// NewsMaxRetryWait fails at once. A rejected API key is never retried and stops calls for NewsAuthCooldown.
+	NewsMaxRetries   int           `json:"news_max_retries"`
+	NewsRetryDelay   time.Duration `json:"news_retry_delay"`
+	NewsMaxRetryWait time.Duration `json:"news_max_retry_wait"`
+	NewsAuthCooldown time.Duration `json:"news_auth_cooldown"`
+}
+
+// workflow level limits applied by the orchestrator
+type WorkflowConfig struct {
+	Deadline time.Duration `json:"deadline"`
+	// caps workflows running at once, up to MaxQueue more wait QueueTimeout for a slot and the rest are turned away
+	MaxConcurrency     int           `json:"max_concurrency"`
+	MaxQueue           int           `json:"max_queue"`
+	QueueTimeout       time.Duration `json:"queue_timeout"`
+	IntentTieThreshold float64       `json:"intent_tie_threshold"`
+	IntentTieMargin    float64       `json:"intent_tie_margin"`
+	// follow ups classified below this confidence are re-routed instead of pulling in prior context
+	FollowUpMinConfidence float64 `json:"follow_up_min_confidence"`
+	// fetch limits per workflow profile, see FetchLimitsFor
+	FetchLimits        map[string]FetchLimits   `json:"fetch_limits"`
+	EmbeddingRelevancy EmbeddingRelevancyConfig `json:"embedding_relevancy"`
+	// minimum title/entity similarity for a video to be treated as covering the same story as an article
+	MediaLinkThreshold float64 `json:"media_link_threshold"`
+	// exchanges kept per conversation, older ones are evicted and optionally folded into the context summary
+	MaxStoredExchanges   int  `json:"max_stored_exchanges"`
+	FoldEvictedExchanges bool `json:"fold_evicted_exchanges"`
+	// keep CurrentTopics on the active thread, each turn topics decay by up to TopicDecayRate the further the query
+	// drifts from the running topic centroid and are evicted below TopicEvictWeight. A query less similar than
+	// TopicDriftThreshold starts a new thread and the old thread's topics decay by the full rate until mentioned again
+	TopicDrift          bool    `json:"topic_drift"`
+	TopicDecayRate      float64 `json:"topic_decay_rate"`
+	TopicEvictWeight    float64 `json:"topic_evict_weight"`
+	TopicDriftThreshold float64 `json:"topic_drift_threshold"`
+	// query enhancement and keyword extraction share one gemini call instead of two
+	CombinedQueryProcessing bool `json:"combined_query_processing"`
+	// "downweight" scales opinion article relevance by OpinionWeight and orders them last, "exclude" drops them, "keep" only tags
+	OpinionPolicy string  `json:"opinion_policy"`
+	OpinionWeight float64 `json:"opinion_weight"`
+	// inactivity after which the next query starts a new conversation session, 0 disables
+	SessionIdleTimeout time.Duration `json:"session_idle_timeout"`
+	// a query repeating the previous one within RepeatQueryWindow, usually a double submit, gets the previous
+	// answer back without running the pipeline again
+	RepeatQueryShortCircuit bool          `json:"repeat_query_short_circuit"`
+	RepeatQueryWindow       time.Duration `json:"repeat_query_window"`
+	// summaries stay within the fetched sources instead of supplementing them from model knowledge
+	StrictSourcesOnly bool `json:"strict_sources_only"`
+	// a failed article fetch no longer fails the workflow when videos were found, nor the other way round,
+	// the answer is built from the content type that succeeded and notes the missing one
+	PartialContent bool `json:"partial_content"`
+	// summaries cite their sources with [n] markers that index a numbered source list in the response
+	InlineCitations bool `json:"inline_citations"`
+	// chitchat touching on one of the user's favourite topics ends with TrendingSuggestionCount of its top
+	// headlines, cached for TrendingCacheTTL and offered to a user at most once per TrendingSuggestionInterval
+	TrendingSuggestions        bool          `json:"trending_suggestions"`
+	TrendingSuggestionCount    int           `json:"trending_suggestion_count"`
+	TrendingSuggestionInterval time.Duration `json:"trending_suggestion_interval"`
+	TrendingCacheTTL           time.Duration `json:"trending_cache_ttl"`
+	// recent conversation keywords carried into news searches that continue a prior topic
+	RecentKeywordBlend int `json:"recent_keyword_blend"`
+	// news searches whose relevancy pass comes back empty are retried this many times with a broadened query, 0 disables
+	MaxBroadenAttempts int `json:"max_broaden_attempts"`
+	// "generate" writes answers in the user's language directly, "translate" writes them in english and
+	// translates the final response, which keeps names and figures more consistent across languages
+	LanguageMode string `json:"language_mode"`
+	// "title" embeds title and description, "content" scrapes every fetched article first and embeds its leading
+	// paragraphs too. Content vectors match body-heavy queries better but add a scrape pass over the whole fetch
+	// (seconds per batch) before relevancy can start, ScrapeMinRelevance and ScrapeMaxArticles cannot apply to it
+	// since nothing is rated yet. The later scraper step does not fetch those articles again.
+	EmbeddingInput      string `json:"embedding_input"`
+	EmbeddingParagraphs int    `json:"embedding_paragraphs"`
+	EmbeddingChunkChars int    `json:"embedding_chunk_chars"`
+	// "ensemble" rates articles in overlapping windows of RelevancyWindowSize and merges the scores per article
+	// with RelevancyCombine ("max" or "average"), one gemini call per window instead of one in total. "embedding"
+	// ranks the vector search results by EmbeddingRelevancy without any gemini call, a request's
+	// "relevancy_mode" overrides it
+	RelevancyMode          string `json:"relevancy_mode"`
+	RelevancyWindowSize    int    `json:"relevancy_window_size"`
+	RelevancyWindowOverlap int    `json:"relevancy_window_overlap"`
+	RelevancyCombine       string `json:"relevancy_combine"`
+	// scrape the relevant articles as soon as article relevancy is done, overlapping video relevancy
+	ParallelScrape bool `json:"parallel_scrape"`
+	// only articles rated at least ScrapeMinRelevance are scraped, at most ScrapeMaxArticles of the most relevant,
+	// the rest keep their description. Zero disables either limit
+	ScrapeMinRelevance float64 `json:"scrape_min_relevance"`
+	ScrapeMaxArticles  int     `json:"scrape_max_articles"`
+	// pull attributed direct quotes out of scraped articles so the summary can cite them verbatim
+	QuoteExtraction     bool `json:"quote_extraction"`
+	MaxQuotesPerArticle int  `json:"max_quotes_per_article"`
+	// relevant articles kept per source and videos per channel so one outlet cannot dominate the answer, 0 disables
+	MaxArticlesPerSource int `json:"max_articles_per_source"`
+	MaxVideosPerChannel  int `json:"max_videos_per_channel"`
+	// most relevant articles rated when the user opts in to sentiment analysis
+	SentimentMaxArticles int `json:"sentiment_max_articles"`
+	// articles with more content than CondenseMinChars are condensed to about CondenseTargetChars by a
+	// separate call each before summarization, so one long piece does not crowd out the other sources
+	CondenseArticles    bool `json:"condense_articles"`
+	CondenseMinChars    int  `json:"condense_min_chars"`
+	CondenseTargetChars int  `json:"condense_target_chars"`
+	// articles whose embeddings are at least this similar are treated as one syndicated story, 0 disables
+	NearDuplicateThreshold float64 `json:"near_duplicate_threshold"`
+	// defaults per workflow type ("news", "chitchat", "follow_up") for users who left the personality blank,
+	// the sampling applies to the calls that write the response and wins over GEMINI_AGENT_SAMPLING
+	Profiles map[string]WorkflowProfile `json:"profiles,omitempty"`
+	// "relevance" groups sources by relevance band then freshness, "freshness" sorts by freshness tier first
+	SourceSort string `json:"source_sort"`
+	// "verbose" streams every agent's processing and completed updates, "quiet" only completed ones and
+	// "milestones" only the workflow level updates, a request's "update_verbosity" overrides it
+	UpdateVerbosity string `json:"update_verbosity"`
+	// attach a one line "why this was selected" to every source, derived from the matched keywords so it costs no
+	// model calls, a request's "selection_reasons" turns it on for that request
+	SelectionReasons bool `json:"selection_reasons"`
+	// emit typed events (intent_classified, keywords_extracted, articles_found, summary_chunk, sources, complete)
+	// with structured payloads alongside the agent updates, a request's "typed_events" turns them on
+	TypedEvents bool `json:"typed_events"`
+	// the execute endpoint streams the typed events as JSON lines over a chunked response when the request
+	// accepts application/x-ndjson, finishing with the full response
+	ChunkedResponses bool `json:"chunked_responses"`
+	// "off", "extract" takes the headline from the answer itself and "generate" asks the model for one, falling
+	// back to extraction, a request's "headline" turns on extraction when it is off
+	HeadlineMode string `json:"headline_mode"`
+	// a query asking about several unrelated stories is answered per story, at most MaxSubQueries of them with
+	// SubQueryConcurrency running at once, and the summaries are joined into one sectioned answer
+	CompoundQueries bool `json:"compound_queries"`
+	// enhanced queries that are empty, shorter than EnhancedQueryMinWords or, with EnhancedQueryKeepEntities,
+	// drop every name of the original query are discarded in favour of the query they came from
+	EnhancedQueryValidation   bool `json:"enhanced_query_validation"`
+	EnhancedQueryMinWords     int  `json:"enhanced_query_min_words"`
+	EnhancedQueryKeepEntities bool `json:"enhanced_query_keep_entities"`
+	MaxSubQueries             int  `json:"max_sub_queries"`
+	SubQueryConcurrency       int  `json:"sub_query_concurrency"`
+	// completed responses leave their sources out and point to GET /workflows/:id/sources instead, which serves
+	// them for SourcesTTL in pages of SourcesPageSize, at most SourcesMaxPageSize when the client asks for more
+	SourcesByReference bool          `json:"sources_by_reference"`
+	SourcesTTL         time.Duration `json:"sources_ttl"`
+	SourcesPageSize    int           `json:"sources_page_size"`
+	SourcesMaxPageSize int           `json:"sources_max_page_size"`
+}
+
+var WorkflowProfileNames = []string{"news", "chitchat", "follow_up"}
+
+// FetchLimitsFor returns the fetch limits of a workflow profile, profiles without their own use the news limits
+func (workflowConfig WorkflowConfig) FetchLimitsFor(profile string) FetchLimits {
+	if limits, ok := workflowConfig.FetchLimits[profile]; ok {
+		return limits
+	}
+	return workflowConfig.FetchLimits["news"]
+}
+
+type WorkflowProfile struct {
+	Personality string   `json:"personality,omitempty"`
+	Temperature *float32 `json:"temperature,omitempty"`
+	MaxTokens   int32    `json:"max_tokens,omitempty"`
+}
+
+// FetchLimits caps how much content a workflow pulls in. Every fetched article costs one
+// embedding call and grows the relevancy prompt, so these numbers drive most of the per query cost.
+// They are set per workflow profile, today only the news workflow fetches while chitchat and follow-up
+// answer from memory.
+type FetchLimits struct {
+	MaxArticles       int `json:"max_articles"`
+	MaxVideos         int `json:"max_videos"`
+	RecentMaxArticles int `json:"recent_max_articles"`
+	// articles beyond this are narrowed by cosine similarity before the llm relevancy pass
+	RelevancyCandidates int `json:"relevancy_candidates"`
+}
+
+// EmbeddingRelevancyConfig weighs the signals the embedding relevancy mode ranks by. Recency halves every
+// RecencyHalfLife and credibility counts sources listed in Quality.TrustedSources.
+type EmbeddingRelevancyConfig struct {
+	SimilarityWeight  float64       `json:"similarity_weight"`
+	RecencyWeight     float64       `json:"recency_weight"`
+	CredibilityWeight float64       `json:"credibility_weight"`
+	RecencyHalfLife   time.Duration `json:"recency_half_life"`
+	// results scoring below MinScore are dropped, at most MaxResults articles and MaxResults videos are kept
+	MinScore   float64 `json:"min_score"`
+	MaxResults int     `json:"max_results"`
+}
+
+// tenants are identified by a request header, tenants without an entry may use every persona
+type TenantConfig struct {
+	Header        string                    `json:"header"`
+	DefaultTenant string                    `json:"default_tenant"`
+	Personas      map[string]TenantPersonas `json:"personas"`
+	// instructions added to the written responses of every user in the tenant, ahead of the user's own
+	CustomInstructions map[string]string `json:"custom_instructions"`
+	// tenants a request may name besides the default and those with personas or custom instructions
+	Known []string `json:"known"`
+}
+
+// IsKnown reports whether the tenant is configured, the header is set by the caller so any other value is refused
+func (tenantConfig TenantConfig) IsKnown(tenantID string) bool {
+	if tenantID == tenantConfig.DefaultTenant || slices.Contains(tenantConfig.Known, tenantID) {
+		return true
+	}
+	_, hasPersonas := tenantConfig.Personas[tenantID]
+	_, hasInstructions := tenantConfig.CustomInstructions[tenantID]
+	return hasPersonas || hasInstructions
+}
+
+type TenantPersonas struct {
+	Allowed []string `json:"allowed"`
+	Default string   `json:"default"`
+}
+
+// untrusted web content checks applied before content reaches a prompt
+type SafetyConfig struct {
+	InjectionDetection bool `json:"injection_detection"`
+	// number of injection phrases in a single source before it is down-weighted
+	InjectionThreshold int `json:"injection_threshold"`
+}
+
+type AdminConfig struct {
+	// shared key for the admin api, admin routes are disabled when empty
+	APIKey string `json:"-"`
+	// exposes /api/v1/debug, whose endpoints spend gemini calls without running a workflow
+	DebugEndpoints bool `json:"debug_endpoints"`
+}
+
+// user ids end up in redis keys and stream names, anything outside ascii letters, digits and the
+// allowed punctuation is rejected at ingestion
+type UserIDConfig struct {
+	MaxLength          int    `json:"max_length"`
+	AllowedPunctuation string `json:"allowed_punctuation"`
+	// folds ids to lower case so "Alice" and "alice" share one conversation context
+	Lowercase bool `json:"lowercase"`
+}
+
+type SubscriptionConfig struct {
+	Enabled      bool          `json:"enabled"`
+	PollInterval time.Duration `json:"poll_interval"`
+	MaxPerUser   int           `json:"max_per_user"`
+	// each poll is one news search over the lookback window, capped at FetchLimit articles
+	FetchLimit      int           `json:"fetch_limit"`
+	Lookback        time.Duration `json:"lookback"`
+	FetchTimeout    time.Duration `json:"fetch_timeout"`
+	CallbackTimeout time.Duration `json:"callback_timeout"`
+	// hosts callbacks may be posted to, callbacks are refused while the list is empty
+	CallbackHosts []string `json:"callback_hosts"`
+	// lets callback hosts resolve to loopback and private addresses, for local development only
+	AllowPrivateCallbacks bool `json:"allow_private_callbacks"`
+}
+
+// QualityConfig weights the signals combined into the per workflow quality score, weights are relative
+// and a zero weight drops the signal
+type QualityConfig struct {
+	// relevant articles needed for full coverage credit
+	ArticleTarget int `json:"article_target"`
+	// runs scoring below this count as low quality in the metrics
+	LowThreshold      float64 `json:"low_threshold"`
+	CoverageWeight    float64 `json:"coverage_weight"`
+	RelevanceWeight   float64 `json:"relevance_weight"`
+	FallbackWeight    float64 `json:"fallback_weight"`
+	ScrapingWeight    float64 `json:"scraping_weight"`
+	TruncationWeight  float64 `json:"truncation_weight"`
+	CredibilityWeight float64 `json:"credibility_weight"`
+	// source names or domains counted as credible, the credibility signal is skipped when empty
+	TrustedSources []string `json:"trusted_sources"`
+	// answers backed by fewer than UncertaintyMinArticles articles, averaging below UncertaintyMinRelevance or whose
+	// rated articles split at least UncertaintyConflictShare against the majority tone get a caution note appended
+	UncertaintyNote          bool    `json:"uncertainty_note"`
+	UncertaintyMinArticles   int     `json:"uncertainty_min_articles"`
+	UncertaintyMinRelevance  float64 `json:"uncertainty_min_relevance"`
+	UncertaintyConflictShare float64 `json:"uncertainty_conflict_share"`
+}
+
+// PricingConfig turns recorded token counts and embedding requests into an estimated dollar cost.
+// Models listed in ModelPrices use their own prices, every other gemini or embedding model the defaults.
+type PricingConfig struct {
+	// adds estimated_cost_usd to responses, the metrics always carry the running totals
+	ExposeCost          bool                  `json:"expose_cost"`
+	GeminiInputPer1K    float64               `json:"gemini_input_per_1k"`
+	GeminiOutputPer1K   float64               `json:"gemini_output_per_1k"`
+	EmbeddingPerRequest float64               `json:"embedding_per_request"`
+	ModelPrices         map[string]ModelPrice `json:"model_prices,omitempty"`
+}
+
+type ModelPrice struct {
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+	PerRequest  float64 `json:"per_request"`
+}
+
+// Exporter is one of "none", "stdout" or "otlp", OTLPEndpoint falls back to the standard OTEL_EXPORTER_OTLP_* env vars
+type TracingConfig struct {
+	Exporter     string  `json:"exporter"`
+	OTLPEndpoint string  `json:"otlp_endpoint"`
+	SampleRatio  float64 `json:"sample_ratio"`
+}
+
+// keyword taxonomy used to categorize fetched articles before they are stored,
+// articles matching no keyword fall back to Default
+type CategoryConfig struct {
+	Taxonomy map[string][]string `json:"taxonomy"`
+	Default  string              `json:"default"`
+}
+
+// mirrors the NewsAPI top-headlines categories so stored and live categories line up
+var defaultCategoryTaxonomy = map[string][]string{
+	"business":      {"market", "markets", "stocks", "economy", "earnings", "inflation", "bank", "revenue", "shares", "investors", "trade", "startup"},
+	"entertainment": {"film", "movie", "music", "album", "celebrity", "box office", "tv", "series", "actor", "actress", "streaming", "festival"},
+	"health":        {"health", "disease", "vaccine", "hospital", "medical", "virus", "patients", "cancer", "mental health", "drug", "outbreak"},
+	"science":       {"science", "research", "scientists", "study", "space", "nasa", "climate", "physics", "species", "telescope", "researchers"},
+	"sports":        {"match", "league", "tournament", "championship", "football", "cricket", "tennis", "olympics", "coach", "season", "goal", "nba"},
+	"technology":    {"ai", "software", "app", "smartphone", "chip", "cybersecurity", "google", "apple", "microsoft", "tech", "startup", "robot"},
+	"politics":      {"election", "government", "minister", "parliament", "president", "senate", "policy", "vote", "campaign", "congress"},
+}
+
+// dependencies are retried with backoff until Deadline before the service reports ready,
+// only the critical ones abort startup when they never come up
+type StartupConfig struct {
+	HealthGate       bool          `json:"health_gate"`
+	Deadline         time.Duration `json:"deadline"`
+	AttemptTimeout   time.Duration `json:"attempt_timeout"`
+	InitialBackoff   time.Duration `json:"initial_backoff"`
+	MaxBackoff       time.Duration `json:"max_backoff"`
+	CriticalServices []string      `json:"critical_services"`
+}
+
+// IsCritical reports whether a failing dependency should abort startup
+func (startup StartupConfig) IsCritical(name string) bool {
+	for _, critical := range startup.CriticalServices {
+		if strings.EqualFold(critical, name) {
+			return true
+		}
+	}
+	return false
 }
 
 type LogConfig struct {
@@ -82,6 +557,32 @@ type ScraperConfig struct {
 	Timeout        time.Duration `json:"timeout"`
 	MaxConcurrency int           `json:"max_concurrency"`
 	RetryAttempts  int           `json:"retry_attempts"`
+	// how long a scraped page may be served from cache or revalidated, zero disables the cache
+	CacheMaxAge time.Duration `json:"cache_max_age"`
+	// article text shorter than this counts as a metadata only scrape
+	MinContentLength int `json:"min_content_length"`
+	// pages are truncated at this many bytes so a huge page cannot exhaust memory, 0 keeps colly's default
+	MaxBodyBytes int `json:"max_body_bytes"`
+	// look article text up in the publisher's feed before scraping, FeedSources maps domain to feed url
+	FeedFirst    bool              `json:"feed_first"`
+	FeedSources  map[string]string `json:"feed_sources"`
+	FeedCacheTTL time.Duration     `json:"feed_cache_ttl"`
+	// bylines and datelines opening an article and credits or related links closing it are dropped,
+	// at most BoilerplateMaxParagraphs from each end
+	TrimBoilerplate          bool `json:"trim_boilerplate"`
+	BoilerplateMaxParagraphs int  `json:"boilerplate_max_paragraphs"`
+	// extra phrases marking a paragraph as noise, on top of the built in list
+	NoisePhrases []string `json:"noise_phrases,omitempty"`
+	// headers and cookies sent to a domain and its subdomains, e.g. a consent cookie so EU sites skip the consent wall
+	DomainHeaders map[string]map[string]string `json:"domain_headers,omitempty"`
+	DomainCookies map[string]map[string]string `json:"domain_cookies,omitempty"`
+	// pages from HeadlessDomains whose static scrape comes up short are rendered by the headless browser service
+	// at HeadlessRendererURL, at most HeadlessMaxConcurrency at a time since each render is expensive
+	HeadlessFallback       bool          `json:"headless_fallback"`
+	HeadlessDomains        []string      `json:"headless_domains,omitempty"`
+	HeadlessRendererURL    string        `json:"headless_renderer_url"`
+	HeadlessMaxConcurrency int           `json:"headless_max_concurrency"`
+	HeadlessTimeout        time.Duration `json:"headless_timeout"`
 }
 
 func Load() (*Config, error) {
@@ -98,15 +599,21 @@ func Load() (*Config, error) {
 			ReadTimeout:  getDuration("HTTP_READ_TIMEOUT", 30*time.Second),
 			WriteTimeout: getDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
 			IdleTimeout:  getDuration("HTTP_IDLE_TIMEOUT", 120*time.Second),
+
+			ShutdownGracePeriod: getDuration("HTTP_SHUTDOWN_GRACE_PERIOD", 30*time.Second),
+			MaxRequestBodyBytes: int64(getInt("HTTP_MAX_REQUEST_BODY_BYTES", 1<<20)),
 		},
 
 		Redis: RedisConfig{
-			StreamsURL:   getEnv("REDIS_STREAMS_URL", "redis://localhost:6378"),
-			MemoryURL:    getEnv("REDIS_MEMORY_URL", "redis://localhost:6380"),
-			PoolSize:     getInt("REDIS_POOL_SIZE", 10),
-			DialTimeout:  getDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
-			ReadTimeout:  getDuration("REDIS_READ_TIMEOUT", 30*time.Second),
-			WriteTimeout: getDuration("REDIS_WRITE_TIMEOUT", 30*time.Second),
+			StreamsURL:          getEnv("REDIS_STREAMS_URL", "redis://localhost:6378"),
+			MemoryURL:           getEnv("REDIS_MEMORY_URL", "redis://localhost:6380"),
+			PoolSize:            getInt("REDIS_POOL_SIZE", 10),
+			DialTimeout:         getDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+			ReadTimeout:         getDuration("REDIS_READ_TIMEOUT", 30*time.Second),
+			WriteTimeout:        getDuration("REDIS_WRITE_TIMEOUT", 30*time.Second),
+			AllowStateless:      getBool("REDIS_ALLOW_STATELESS", true),
+			HealthCheckInterval: getDuration("REDIS_HEALTH_CHECK_INTERVAL", 10*time.Second),
+			MaxContextBytes:     getInt("REDIS_MAX_CONTEXT_BYTES", 512*1024),
 		},
 
 		Ollama: OllamaConfig{
@@ -116,6 +623,13 @@ func Load() (*Config, error) {
 			MaxRetries:     getInt("OLLAMA_MAX_RETRIES", 5),
 			RetryDelay:     getDuration("OLLAMA_RETRY_DELAY", 3*time.Second),
 		},
+		Embedding: EmbeddingConfig{
+			Fallback:         getEnv("EMBEDDING_FALLBACK", "none"),
+			FallbackModel:    getEnv("EMBEDDING_FALLBACK_MODEL", "gemini-embedding-001"),
+			Dimensions:       getInt("EMBEDDING_DIMENSIONS", 768),
+			FailureThreshold: getInt("EMBEDDING_FAILURE_THRESHOLD", 3),
+			Cooldown:         getDuration("EMBEDDING_COOLDOWN", time.Minute),
+		},
 		Gemini: GeminiConfig{
 			APIKey:      getEnv("GEMINI_API_KEY", ""),
 			Model:       getEnv("GEMINI_MODEL", "gemini-2.5-flash-lite"),
@@ -124,6 +638,21 @@ func Load() (*Config, error) {
 			Timeout:     getDuration("GEMINI_TIMEOUT", 30*time.Second),
 			MaxRetries:  getInt("GEMINI_MAX_RETRIES", 5),
 			RetryDelay:  getDuration("GEMINI_RETRY_DELAY", 5*time.Second),
+
+			MaxConcurrency:        getInt("GEMINI_MAX_CONCURRENCY", 8),
+			MaxQueue:              getInt("GEMINI_MAX_QUEUE", 32),
+			AgentSampling:         getAgentSampling("GEMINI_AGENT_SAMPLING"),
+			AgentModels:           getMap("GEMINI_AGENT_MODELS", nil),
+			PromptDir:             getEnv("PROMPT_TEMPLATE_DIR", ""),
+			PromptContextFields:   getList("PROMPT_CONTEXT_FIELDS", nil),
+			PromptContextMaxChars: getInt("PROMPT_CONTEXT_MAX_CHARS", 800),
+			NativePersonaLanguage: getBool("PERSONA_NATIVE_LANGUAGE", true),
+			ContextWindowTokens:   getInt("GEMINI_CONTEXT_WINDOW_TOKENS", 1048576),
+			PromptBudgetFraction:  getFloat64("GEMINI_PROMPT_BUDGET_FRACTION", 0.9),
+			PromptTrimStrategies: getMap("GEMINI_PROMPT_TRIM_STRATEGIES", map[string]string{
+				"summarizer": "middle", "relevancy": "middle", "condenser": "middle", "sentiment": "middle",
+				"chitchat": "middle", "query_processor": "middle", "keyword_extractor": "middle",
+			}),
 		},
 		Log: LogConfig{
 			Level:      getEnv("LOG_LEVEL", "info"),
@@ -136,18 +665,236 @@ func Load() (*Config, error) {
 			Compress:   getBool("LOG_COMPRESS", true),
 		},
 		Etc: EtcConfig{
-			NewsApiKey:         getEnv("NEWS_API_KEY", ""),
-			ChromaDBURL:        getEnv("CHROMA_DB_URL", "http://localhost:9000"),
-			ChromaDBCollection: getEnv("CHROMA_DB_COLLECTION", "Infiya-news-articles"),
+			NewsApiKey:              getEnv("NEWS_API_KEY", ""),
+			ChromaDBURL:             getEnv("CHROMA_DB_URL", "http://localhost:9000"),
+			ChromaDBCollection:      getEnv("CHROMA_DB_COLLECTION", "Infiya-news-articles"),
+			ChromaTenant:            getEnv("CHROMA_TENANT", "default_tenant"),
+			ChromaDatabase:          getEnv("CHROMA_DATABASE", "default_database"),
+			ChromaAutoCreate:        getBool("CHROMA_AUTO_CREATE", false),
+			ChromaTenantCollections: getBool("CHROMA_TENANT_COLLECTIONS", false),
+
+			ChromaMinSimilarity: getFloat64("CHROMA_MIN_SIMILARITY", 0.3),
+
+			ChromaBatchSize:            getInt("CHROMA_BATCH_SIZE", 25),
+			ChromaBatchConcurrency:     getInt("CHROMA_BATCH_CONCURRENCY", 3),
+			ChromaMetadataContentLimit: getInt("CHROMA_METADATA_CONTENT_LIMIT", 1000),
+			ChromaCompactionInterval:   getDuration("CHROMA_COMPACTION_INTERVAL", 0),
+			ChromaCompactionPageSize:   getInt("CHROMA_COMPACTION_PAGE_SIZE", 500),
+			ChromaQueryInclude:         getList("CHROMA_QUERY_INCLUDE", []string{"metadatas", "distances"}),
+
+			ArticleContentStore: getEnv("ARTICLE_CONTENT_STORE", "metadata"),
+			ArticleContentTTL:   getDuration("ARTICLE_CONTENT_TTL", 7*24*time.Hour),
+
+			ResultStore:     getEnv("RESULT_STORE", "none"),
+			ResultRetention: getDuration("RESULT_RETENTION", 30*24*time.Hour),
+			ResultStoreDir:  getEnv("RESULT_STORE_DIR", "data/results"),
+
+			ChromaHybridSearch:   getBool("CHROMA_HYBRID_SEARCH", false),
+			ChromaHybridLookback: getDuration("CHROMA_HYBRID_LOOKBACK", 7*24*time.Hour),
+			KeywordWeighting:     getEnv("NEWS_KEYWORD_WEIGHTING", "off"),
+			NewsMaxRetries:       getInt("NEWS_MAX_RETRIES", 2),
+			NewsRetryDelay:       getDuration("NEWS_RETRY_DELAY", time.Second),
+			NewsMaxRetryWait:     getDuration("NEWS_MAX_RETRY_WAIT", 10*time.Second),
+			NewsAuthCooldown:     getDuration("NEWS_AUTH_COOLDOWN", 5*time.Minute),
 		},
 		Scraper: ScraperConfig{
 			UserAgent:      getEnv("SCRAPER_USER_AGENT", "Infiya-ai-pipeline/1.0"),
 			Timeout:        getDuration("SCRAPER_TIMEOUT", 30*time.Second),
 			MaxConcurrency: getInt("SCRAPER_MAX_CONCURRENCY", 5),
 			RetryAttempts:  getInt("SCRAPER_RETRY_ATTEMPTS", 3),
+			CacheMaxAge:    getDuration("SCRAPER_CACHE_MAX_AGE", 24*time.Hour),
+
+			MinContentLength: getInt("SCRAPER_MIN_CONTENT_LENGTH", 200),
+			MaxBodyBytes:     getInt("SCRAPER_MAX_BODY_BYTES", 5<<20),
+			FeedFirst:        getBool("SCRAPER_FEED_FIRST", false),
+			FeedSources:      getMap("SCRAPER_FEED_SOURCES", map[string]string{}),
+			FeedCacheTTL:     getDuration("SCRAPER_FEED_CACHE_TTL", 10*time.Minute),
+
+			TrimBoilerplate:          getBool("SCRAPER_TRIM_BOILERPLATE", true),
+			BoilerplateMaxParagraphs: getInt("SCRAPER_BOILERPLATE_MAX_PARAGRAPHS", 2),
+			NoisePhrases:             getList("SCRAPER_NOISE_PHRASES", nil),
+			DomainHeaders:            getDomainProfiles("SCRAPER_DOMAIN_HEADERS"),
+			DomainCookies:            getDomainProfiles("SCRAPER_DOMAIN_COOKIES"),
+
+			HeadlessFallback:       getBool("SCRAPER_HEADLESS_FALLBACK", false),
+			HeadlessDomains:        getList("SCRAPER_HEADLESS_DOMAINS", nil),
+			HeadlessRendererURL:    getEnv("SCRAPER_HEADLESS_RENDERER_URL", ""),
+			HeadlessMaxConcurrency: getInt("SCRAPER_HEADLESS_MAX_CONCURRENCY", 1),
+			HeadlessTimeout:        getDuration("SCRAPER_HEADLESS_TIMEOUT", 30*time.Second),
 		},
 		Youtube: YoutubeConfig{
-			APIKey: getEnv("YOUTUBE_API_KEY", ""),
+			APIKey:        getEnv("YOUTUBE_API_KEY", ""),
+			QuotaFallback: getBool("YOUTUBE_QUOTA_FALLBACK", true),
+
+			MinDuration:    getDuration("YOUTUBE_MIN_DURATION", 0),
+			MinViews:       int64(getInt("YOUTUBE_MIN_VIEWS", 0)),
+			MinSubscribers: int64(getInt("YOUTUBE_MIN_SUBSCRIBERS", 0)),
+
+			TranscriptCacheTTL:     getDuration("YOUTUBE_TRANSCRIPT_CACHE_TTL", 7*24*time.Hour),
+			MaxFallbackGenerations: getInt("YOUTUBE_MAX_FALLBACK_GENERATIONS", 3),
+		},
+		Tenants: TenantConfig{
+			Header:             getEnv("TENANT_HEADER", "X-Tenant-ID"),
+			DefaultTenant:      getEnv("TENANT_DEFAULT", "default"),
+			Personas:           getTenantPersonas("TENANT_PERSONA_ALLOWLIST", "TENANT_DEFAULT_PERSONAS"),
+			CustomInstructions: getMap("TENANT_CUSTOM_INSTRUCTIONS", nil),
+			Known:              getList("TENANT_IDS", nil),
+		},
+		UserIDs: UserIDConfig{
+			MaxLength:          getInt("USER_ID_MAX_LENGTH", 128),
+			AllowedPunctuation: getEnv("USER_ID_ALLOWED_PUNCTUATION", "-_.@"),
+			Lowercase:          getBool("USER_ID_LOWERCASE", false),
+		},
+		Admin: AdminConfig{
+			APIKey:         getEnv("ADMIN_API_KEY", ""),
+			DebugEndpoints: getBool("DEBUG_ENDPOINTS_ENABLED", false),
+		},
+		Tracing: TracingConfig{
+			Exporter:     getEnv("TRACING_EXPORTER", "none"),
+			OTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", ""),
+			SampleRatio:  getFloat64("TRACING_SAMPLE_RATIO", 1.0),
+		},
+		Categories: CategoryConfig{
+			Taxonomy: getTaxonomy("ARTICLE_CATEGORY_TAXONOMY", defaultCategoryTaxonomy),
+			Default:  getEnv("ARTICLE_CATEGORY_DEFAULT", "general"),
+		},
+		Startup: StartupConfig{
+			HealthGate:       getBool("STARTUP_HEALTH_GATE", true),
+			Deadline:         getDuration("STARTUP_HEALTH_DEADLINE", 2*time.Minute),
+			AttemptTimeout:   getDuration("STARTUP_HEALTH_ATTEMPT_TIMEOUT", 30*time.Second),
+			InitialBackoff:   getDuration("STARTUP_HEALTH_INITIAL_BACKOFF", time.Second),
+			MaxBackoff:       getDuration("STARTUP_HEALTH_MAX_BACKOFF", 15*time.Second),
+			CriticalServices: getList("STARTUP_CRITICAL_SERVICES", []string{"redis", "gemini", "ollama", "chromadb"}),
+		},
+		Subscriptions: SubscriptionConfig{
+			Enabled:         getBool("SUBSCRIPTIONS_ENABLED", false),
+			PollInterval:    getDuration("SUBSCRIPTION_POLL_INTERVAL", 15*time.Minute),
+			MaxPerUser:      getInt("SUBSCRIPTION_MAX_PER_USER", 10),
+			FetchLimit:      getInt("SUBSCRIPTION_FETCH_LIMIT", 20),
+			Lookback:        getDuration("SUBSCRIPTION_LOOKBACK", 24*time.Hour),
+			FetchTimeout:    getDuration("SUBSCRIPTION_FETCH_TIMEOUT", 15*time.Second),
+			CallbackTimeout: getDuration("SUBSCRIPTION_CALLBACK_TIMEOUT", 10*time.Second),
+			CallbackHosts:   getList("SUBSCRIPTION_CALLBACK_HOSTS", nil),
+
+			AllowPrivateCallbacks: getBool("SUBSCRIPTION_CALLBACK_ALLOW_PRIVATE", false),
+		},
+		Quality: QualityConfig{
+			ArticleTarget:     getInt("QUALITY_ARTICLE_TARGET", 5),
+			LowThreshold:      getFloat64("QUALITY_LOW_THRESHOLD", 0.4),
+			CoverageWeight:    getFloat64("QUALITY_WEIGHT_COVERAGE", 0.25),
+			RelevanceWeight:   getFloat64("QUALITY_WEIGHT_RELEVANCE", 0.25),
+			FallbackWeight:    getFloat64("QUALITY_WEIGHT_FALLBACK", 0.15),
+			ScrapingWeight:    getFloat64("QUALITY_WEIGHT_SCRAPING", 0.15),
+			TruncationWeight:  getFloat64("QUALITY_WEIGHT_TRUNCATION", 0.1),
+			CredibilityWeight: getFloat64("QUALITY_WEIGHT_CREDIBILITY", 0.1),
+			TrustedSources:    getList("QUALITY_TRUSTED_SOURCES", nil),
+
+			UncertaintyNote:          getBool("QUALITY_UNCERTAINTY_NOTE", false),
+			UncertaintyMinArticles:   getInt("QUALITY_UNCERTAINTY_MIN_ARTICLES", 3),
+			UncertaintyMinRelevance:  getFloat64("QUALITY_UNCERTAINTY_MIN_RELEVANCE", 0.5),
+			UncertaintyConflictShare: getFloat64("QUALITY_UNCERTAINTY_CONFLICT_SHARE", 0.3),
+		},
+		Pricing: PricingConfig{
+			ExposeCost:          getBool("COST_EXPOSE", false),
+			GeminiInputPer1K:    getFloat64("COST_GEMINI_INPUT_PER_1K", 0.0003),
+			GeminiOutputPer1K:   getFloat64("COST_GEMINI_OUTPUT_PER_1K", 0.0025),
+			EmbeddingPerRequest: getFloat64("COST_EMBEDDING_PER_REQUEST", 0),
+			ModelPrices:         getModelPrices("COST_MODEL_PRICES"),
+		},
+		Safety: SafetyConfig{
+			InjectionDetection: getBool("PROMPT_INJECTION_DETECTION", true),
+			InjectionThreshold: getInt("PROMPT_INJECTION_THRESHOLD", 1),
+		},
+		Workflow: WorkflowConfig{
+			Deadline:           getDuration("WORKFLOW_DEADLINE", 120*time.Second),
+			MaxConcurrency:     getInt("WORKFLOW_MAX_CONCURRENCY", 50),
+			MaxQueue:           getInt("WORKFLOW_MAX_QUEUE", 100),
+			QueueTimeout:       getDuration("WORKFLOW_QUEUE_TIMEOUT", 30*time.Second),
+			IntentTieThreshold: getFloat64("INTENT_TIE_THRESHOLD", 0.7),
+			IntentTieMargin:    getFloat64("INTENT_TIE_MARGIN", 0.15),
+
+			FollowUpMinConfidence:      getFloat64("FOLLOW_UP_MIN_CONFIDENCE", 0.65),
+			MediaLinkThreshold:         getFloat64("MEDIA_LINK_THRESHOLD", 0.35),
+			SourceSort:                 getEnv("SOURCE_SORT", "relevance"),
+			UpdateVerbosity:            getEnv("UPDATE_VERBOSITY", "verbose"),
+			SelectionReasons:           getBool("SELECTION_REASONS", false),
+			TypedEvents:                getBool("TYPED_EVENTS", false),
+			ChunkedResponses:           getBool("CHUNKED_RESPONSES", true),
+			HeadlineMode:               getEnv("HEADLINE_MODE", "off"),
+			CompoundQueries:            getBool("COMPOUND_QUERIES", false),
+			EnhancedQueryValidation:    getBool("ENHANCED_QUERY_VALIDATION", true),
+			EnhancedQueryMinWords:      getInt("ENHANCED_QUERY_MIN_WORDS", 2),
+			EnhancedQueryKeepEntities:  getBool("ENHANCED_QUERY_KEEP_ENTITIES", true),
+			MaxSubQueries:              getInt("MAX_SUB_QUERIES", 3),
+			SubQueryConcurrency:        getInt("SUB_QUERY_CONCURRENCY", 2),
+			SourcesByReference:         getBool("SOURCES_BY_REFERENCE", false),
+			SourcesTTL:                 getDuration("SOURCES_TTL", 24*time.Hour),
+			SourcesPageSize:            getInt("SOURCES_PAGE_SIZE", 20),
+			SourcesMaxPageSize:         getInt("SOURCES_MAX_PAGE_SIZE", 100),
+			RecentKeywordBlend:         getInt("RECENT_KEYWORD_BLEND", 3),
+			MaxBroadenAttempts:         getInt("MAX_BROADEN_ATTEMPTS", 1),
+			SentimentMaxArticles:       getInt("SENTIMENT_MAX_ARTICLES", 10),
+			CondenseArticles:           getBool("CONDENSE_ARTICLES", false),
+			CondenseMinChars:           getInt("CONDENSE_MIN_CHARS", 8000),
+			CondenseTargetChars:        getInt("CONDENSE_TARGET_CHARS", 2000),
+			QuoteExtraction:            getBool("QUOTE_EXTRACTION_ENABLED", false),
+			ParallelScrape:             getBool("PARALLEL_SCRAPE", false),
+			ScrapeMinRelevance:         getFloat64("SCRAPE_MIN_RELEVANCE", 0),
+			ScrapeMaxArticles:          getInt("SCRAPE_MAX_ARTICLES", 0),
+			RelevancyMode:              getEnv("RELEVANCY_MODE", "single"),
+			RelevancyWindowSize:        getInt("RELEVANCY_WINDOW_SIZE", 15),
+			RelevancyWindowOverlap:     getInt("RELEVANCY_WINDOW_OVERLAP", 5),
+			RelevancyCombine:           getEnv("RELEVANCY_COMBINE", "max"),
+			MaxArticlesPerSource:       getInt("MAX_ARTICLES_PER_SOURCE", 0),
+			MaxVideosPerChannel:        getInt("MAX_VIDEOS_PER_CHANNEL", 0),
+			EmbeddingInput:             getEnv("EMBEDDING_INPUT", "title"),
+			EmbeddingParagraphs:        getInt("EMBEDDING_PARAGRAPHS", 4),
+			EmbeddingChunkChars:        getInt("EMBEDDING_CHUNK_CHARS", 1500),
+			MaxQuotesPerArticle:        getInt("QUOTE_MAX_PER_ARTICLE", 3),
+			LanguageMode:               getEnv("LANGUAGE_MODE", "generate"),
+			NearDuplicateThreshold:     getFloat64("NEAR_DUPLICATE_THRESHOLD", 0.95),
+			Profiles:                   getWorkflowProfiles("WORKFLOW_PROFILES"),
+			StrictSourcesOnly:          getBool("STRICT_SOURCES_ONLY", false),
+			PartialContent:             getBool("PARTIAL_CONTENT_RESULTS", true),
+			InlineCitations:            getBool("INLINE_CITATIONS", false),
+			TrendingSuggestions:        getBool("TRENDING_SUGGESTIONS", false),
+			TrendingSuggestionCount:    getInt("TRENDING_SUGGESTION_COUNT", 3),
+			TrendingSuggestionInterval: getDuration("TRENDING_SUGGESTION_INTERVAL", 24*time.Hour),
+			TrendingCacheTTL:           getDuration("TRENDING_CACHE_TTL", time.Hour),
+			SessionIdleTimeout:         getDuration("SESSION_IDLE_TIMEOUT", 6*time.Hour),
+			RepeatQueryShortCircuit:    getBool("REPEAT_QUERY_SHORT_CIRCUIT", true),
+			RepeatQueryWindow:          getDuration("REPEAT_QUERY_WINDOW", 30*time.Second),
+			OpinionPolicy:              getEnv("OPINION_POLICY", "downweight"),
+			OpinionWeight:              getFloat64("OPINION_WEIGHT", 0.5),
+			CombinedQueryProcessing:    getBool("COMBINED_QUERY_PROCESSING", false),
+			MaxStoredExchanges:         getInt("CONVERSATION_MAX_EXCHANGES", 50),
+			FoldEvictedExchanges:       getBool("CONVERSATION_FOLD_EVICTED", true),
+			TopicDrift:                 getBool("TOPIC_DRIFT_ENABLED", false),
+			TopicDecayRate:             getFloat64("TOPIC_DECAY_RATE", 0.5),
+			TopicEvictWeight:           getFloat64("TOPIC_EVICT_WEIGHT", 0.25),
+			TopicDriftThreshold:        getFloat64("TOPIC_DRIFT_THRESHOLD", 0.5),
+			FetchLimits: getFetchLimits("WORKFLOW_FETCH_LIMITS", FetchLimits{
+				MaxArticles:         getInt("NEWS_FETCH_MAX_ARTICLES", 100),
+				MaxVideos:           getInt("NEWS_FETCH_MAX_VIDEOS", 8),
+				RecentMaxArticles:   getInt("NEWS_FETCH_RECENT_MAX_ARTICLES", 15),
+				RelevancyCandidates: getInt("NEWS_FETCH_RELEVANCY_CANDIDATES", 30),
+			}),
+			EmbeddingRelevancy: EmbeddingRelevancyConfig{
+				SimilarityWeight:  getFloat64("EMBEDDING_RELEVANCY_SIMILARITY_WEIGHT", 0.7),
+				RecencyWeight:     getFloat64("EMBEDDING_RELEVANCY_RECENCY_WEIGHT", 0.2),
+				CredibilityWeight: getFloat64("EMBEDDING_RELEVANCY_CREDIBILITY_WEIGHT", 0.1),
+				RecencyHalfLife:   getDuration("EMBEDDING_RELEVANCY_RECENCY_HALF_LIFE", 48*time.Hour),
+				MinScore:          getFloat64("EMBEDDING_RELEVANCY_MIN_SCORE", 0.4),
+				MaxResults:        getInt("EMBEDDING_RELEVANCY_MAX_RESULTS", 8),
+			},
+		},
+		Eval: EvalConfig{
+			FixturesPath:      getEnv("EVAL_FIXTURES_PATH", ""),
+			WorkflowRecording: getEnv("WORKFLOW_RECORDING", "off"),
+			RecordingDir:      getEnv("WORKFLOW_RECORDING_DIR", "recordings"),
+
+			RecordingRetention: getDuration("WORKFLOW_RECORDING_RETENTION", 7*24*time.Hour),
+			RecordingMaxFiles:  getInt("WORKFLOW_RECORDING_MAX_FILES", 200),
 		},
 	}
 
@@ -162,12 +909,291 @@ func validateConfig(config *Config) error {
 	if config.Gemini.APIKey == "" {
 		return fmt.Errorf("Gemini API key is required")
 	}
-	if config.Etc.NewsApiKey == "" {
+	if config.Etc.NewsApiKey == "" && !config.Eval.Enabled() {
 		return fmt.Errorf("News API Key is required")
 	}
 	if config.HTTP.Port == 0 {
 		return fmt.Errorf("HTTP port is required")
 	}
+	if config.Startup.HealthGate && (config.Startup.Deadline <= 0 || config.Startup.AttemptTimeout <= 0) {
+		return fmt.Errorf("Startup health gate deadline and attempt timeout must be positive")
+	}
+	if !slices.Contains([]string{"none", "redis", "file"}, config.Etc.ResultStore) {
+		return fmt.Errorf("Result store must be one of 'none', 'redis' or 'file'")
+	}
+	if config.Etc.ResultStore != "none" && config.Etc.ResultRetention <= 0 {
+		return fmt.Errorf("Result retention must be positive")
+	}
+	if config.Etc.ResultStore == "file" && config.Etc.ResultStoreDir == "" {
+		return fmt.Errorf("Result store directory is required for the file result store")
+	}
+	if config.Etc.ArticleContentStore != "metadata" && config.Etc.ArticleContentStore != "redis" {
+		return fmt.Errorf("Article content store must be metadata or redis")
+	}
+	if config.Etc.NewsMaxRetries < 0 {
+		return fmt.Errorf("News max retries cannot be negative")
+	}
+	if config.Etc.KeywordWeighting != "off" && config.Etc.KeywordWeighting != "order" && config.Etc.KeywordWeighting != "boost" {
+		return fmt.Errorf("Keyword weighting must be off, order or boost")
+	}
+	if config.Embedding.Fallback != "none" && config.Embedding.Fallback != "gemini" {
+		return fmt.Errorf("Embedding fallback must be none or gemini")
+	}
+	if config.Embedding.Fallback != "none" && config.Embedding.FailureThreshold < 1 {
+		return fmt.Errorf("Embedding failure threshold must be at least 1")
+	}
+	if config.Workflow.SourceSort != "relevance" && config.Workflow.SourceSort != "freshness" {
+		return fmt.Errorf("Source sort must be relevance or freshness")
+	}
+	if config.Workflow.UpdateVerbosity != "verbose" && config.Workflow.UpdateVerbosity != "quiet" && config.Workflow.UpdateVerbosity != "milestones" {
+		return fmt.Errorf("Update verbosity must be verbose, quiet or milestones")
+	}
+	if config.Workflow.TrendingSuggestions {
+		if config.Workflow.TrendingSuggestionCount < 1 || config.Workflow.TrendingSuggestionCount > 5 {
+			return fmt.Errorf("Trending suggestion count must be between 1 and 5")
+		}
+		if config.Workflow.TrendingSuggestionInterval < 0 || config.Workflow.TrendingCacheTTL <= 0 {
+			return fmt.Errorf("Trending suggestion interval cannot be negative and the trending cache TTL must be positive")
+		}
+	}
+	if config.Workflow.EnhancedQueryValidation && config.Workflow.EnhancedQueryMinWords < 1 {
+		return fmt.Errorf("Enhanced query min words must be at least 1")
+	}
+	if config.Workflow.CompoundQueries && (config.Workflow.MaxSubQueries < 2 || config.Workflow.SubQueryConcurrency <= 0) {
+		return fmt.Errorf("Max sub queries must be at least 2 and sub query concurrency must be positive")
+	}
+	if config.Workflow.SourcesPageSize <= 0 || config.Workflow.SourcesMaxPageSize < config.Workflow.SourcesPageSize {
+		return fmt.Errorf("Sources page size must be positive and not above the max page size")
+	}
+	if config.Workflow.SourcesByReference && config.Workflow.SourcesTTL <= 0 {
+		return fmt.Errorf("Sources TTL must be positive when sources are served by reference")
+	}
+	if config.Workflow.RepeatQueryShortCircuit && config.Workflow.RepeatQueryWindow <= 0 {
+		return fmt.Errorf("Repeat query window must be positive when repeat short circuit is enabled")
+	}
+	if config.Workflow.HeadlineMode != "off" && config.Workflow.HeadlineMode != "extract" && config.Workflow.HeadlineMode != "generate" {
+		return fmt.Errorf("Headline mode must be off, extract or generate")
+	}
+	if config.Workflow.OpinionPolicy != "keep" && config.Workflow.OpinionPolicy != "downweight" && config.Workflow.OpinionPolicy != "exclude" {
+		return fmt.Errorf("Opinion policy must be keep, downweight or exclude")
+	}
+	if config.Workflow.OpinionWeight < 0 || config.Workflow.OpinionWeight > 1 {
+		return fmt.Errorf("Opinion weight must be between 0 and 1")
+	}
+	if config.Youtube.MinDuration < 0 || config.Youtube.MinViews < 0 || config.Youtube.MinSubscribers < 0 {
+		return fmt.Errorf("YouTube video filters cannot be negative")
+	}
+	if config.Youtube.TranscriptCacheTTL < 0 {
+		return fmt.Errorf("YouTube transcript cache TTL cannot be negative")
+	}
+	if config.Youtube.MaxFallbackGenerations < 0 {
+		return fmt.Errorf("YouTube max fallback generations cannot be negative")
+	}
+	if config.HTTP.MaxRequestBodyBytes <= 0 {
+		return fmt.Errorf("Max request body size must be positive")
+	}
+	if config.Scraper.MaxBodyBytes < 0 {
+		return fmt.Errorf("Scraper max body size cannot be negative")
+	}
+	if config.Scraper.HeadlessFallback {
+		if config.Scraper.HeadlessRendererURL == "" {
+			return fmt.Errorf("Headless renderer URL is required when the headless fallback is enabled")
+		}
+		if config.Scraper.HeadlessMaxConcurrency <= 0 || config.Scraper.HeadlessTimeout <= 0 {
+			return fmt.Errorf("Headless max concurrency and timeout must be positive")
+		}
+	}
+	if !slices.Contains(WorkflowRecordingModes, config.Eval.WorkflowRecording) {
+		return fmt.Errorf("Unknown workflow recording mode %s, expected one of %s", config.Eval.WorkflowRecording, strings.Join(WorkflowRecordingModes, ", "))
+	}
+	if config.Eval.WorkflowRecording != "off" && config.Eval.RecordingDir == "" {
+		return fmt.Errorf("Workflow recording directory is required when workflow recording is on")
+	}
+	if config.Eval.RecordingRetention < 0 || config.Eval.RecordingMaxFiles < 0 {
+		return fmt.Errorf("Workflow recording retention and max files cannot be negative")
+	}
+	if config.Etc.ChromaTenant == "" || config.Etc.ChromaDatabase == "" {
+		return fmt.Errorf("ChromaDB tenant and database are required")
+	}
+	if config.Etc.ChromaCompactionPageSize <= 0 {
+		return fmt.Errorf("ChromaDB compaction page size must be positive")
+	}
+	if config.Etc.ChromaCompactionInterval != 0 && config.Etc.ChromaCompactionInterval < 10*time.Minute {
+		return fmt.Errorf("ChromaDB compaction interval must be 0 or at least 10 minutes")
+	}
+	for _, field := range config.Etc.ChromaQueryInclude {
+		if !slices.Contains(ChromaQueryIncludeFields, field) {
+			return fmt.Errorf("Unknown ChromaDB query include field %s, expected one of %s", field, strings.Join(ChromaQueryIncludeFields, ", "))
+		}
+	}
+	if !slices.Contains(config.Etc.ChromaQueryInclude, "metadatas") || !slices.Contains(config.Etc.ChromaQueryInclude, "distances") {
+		return fmt.Errorf("ChromaDB query include must contain metadatas and distances")
+	}
+	pricing := config.Pricing
+	if pricing.GeminiInputPer1K < 0 || pricing.GeminiOutputPer1K < 0 || pricing.EmbeddingPerRequest < 0 {
+		return fmt.Errorf("Prices cannot be negative")
+	}
+	for model, price := range pricing.ModelPrices {
+		if price.InputPer1K < 0 || price.OutputPer1K < 0 || price.PerRequest < 0 {
+			return fmt.Errorf("Prices for model %s cannot be negative", model)
+		}
+	}
+	quality := config.Quality
+	if quality.CoverageWeight < 0 || quality.RelevanceWeight < 0 || quality.FallbackWeight < 0 ||
+		quality.ScrapingWeight < 0 || quality.TruncationWeight < 0 || quality.CredibilityWeight < 0 {
+		return fmt.Errorf("Quality weights cannot be negative")
+	}
+	if quality.ArticleTarget <= 0 {
+		return fmt.Errorf("Quality article target must be positive")
+	}
+	if quality.UncertaintyMinArticles < 0 {
+		return fmt.Errorf("Uncertainty min articles cannot be negative")
+	}
+	if quality.UncertaintyMinRelevance < 0 || quality.UncertaintyMinRelevance > 1 ||
+		quality.UncertaintyConflictShare <= 0 || quality.UncertaintyConflictShare > 0.5 {
+		return fmt.Errorf("Uncertainty min relevance must be between 0 and 1 and conflict share between 0 and 0.5")
+	}
+	if config.Workflow.MaxStoredExchanges < 0 {
+		return fmt.Errorf("Conversation max exchanges cannot be negative")
+	}
+	if config.Workflow.TopicDecayRate < 0 || config.Workflow.TopicDecayRate > 1 {
+		return fmt.Errorf("Topic decay rate must be between 0 and 1")
+	}
+	if config.Workflow.TopicEvictWeight < 0 || config.Workflow.TopicEvictWeight > 1 {
+		return fmt.Errorf("Topic evict weight must be between 0 and 1")
+	}
+	if config.Workflow.TopicDriftThreshold < -1 || config.Workflow.TopicDriftThreshold > 1 {
+		return fmt.Errorf("Topic drift threshold must be between -1 and 1")
+	}
+	for _, field := range config.Gemini.PromptContextFields {
+		if !slices.Contains(PromptContextFieldNames, field) {
+			return fmt.Errorf("Unknown prompt context field %s, expected one of %s", field, strings.Join(PromptContextFieldNames, ", "))
+		}
+	}
+	if config.Gemini.PromptContextMaxChars < 0 {
+		return fmt.Errorf("Prompt context max chars cannot be negative")
+	}
+	if config.Gemini.ContextWindowTokens < 0 {
+		return fmt.Errorf("Gemini context window tokens cannot be negative")
+	}
+	if config.Gemini.PromptBudgetFraction <= 0 || config.Gemini.PromptBudgetFraction > 1 {
+		return fmt.Errorf("Gemini prompt budget fraction must be above 0 and at most 1")
+	}
+	for agent, strategy := range config.Gemini.PromptTrimStrategies {
+		if !slices.Contains(PromptTrimStrategyNames, strategy) {
+			return fmt.Errorf("Unknown prompt trim strategy %s for %s, expected one of %s", strategy, agent, strings.Join(PromptTrimStrategyNames, ", "))
+		}
+	}
+	for name, profile := range config.Workflow.Profiles {
+		if !slices.Contains(WorkflowProfileNames, name) {
+			return fmt.Errorf("Unknown workflow profile %s, expected one of %s", name, strings.Join(WorkflowProfileNames, ", "))
+		}
+		if profile.Temperature != nil && (*profile.Temperature < 0 || *profile.Temperature > 2) {
+			return fmt.Errorf("Workflow profile %s temperature must be between 0 and 2", name)
+		}
+		if profile.MaxTokens < 0 {
+			return fmt.Errorf("Workflow profile %s max tokens cannot be negative", name)
+		}
+	}
+	if config.Workflow.NearDuplicateThreshold < 0 || config.Workflow.NearDuplicateThreshold > 1 {
+		return fmt.Errorf("Near duplicate threshold must be between 0 and 1")
+	}
+	if config.Workflow.LanguageMode != "generate" && config.Workflow.LanguageMode != "translate" {
+		return fmt.Errorf("Language mode must be either 'generate' or 'translate'")
+	}
+	if config.Workflow.EmbeddingInput != "title" && config.Workflow.EmbeddingInput != "content" {
+		return fmt.Errorf("Embedding input must be either 'title' or 'content'")
+	}
+	if config.Workflow.EmbeddingInput == "content" && (config.Workflow.EmbeddingParagraphs <= 0 || config.Workflow.EmbeddingChunkChars <= 0) {
+		return fmt.Errorf("Embedding paragraphs and chunk chars must be positive for content embeddings")
+	}
+	if config.Workflow.QuoteExtraction && config.Workflow.MaxQuotesPerArticle <= 0 {
+		return fmt.Errorf("Max quotes per article must be positive when quote extraction is enabled")
+	}
+	if config.Workflow.ScrapeMinRelevance < 0 || config.Workflow.ScrapeMinRelevance > 1 {
+		return fmt.Errorf("Scrape min relevance must be between 0 and 1")
+	}
+	if config.Workflow.ScrapeMaxArticles < 0 {
+		return fmt.Errorf("Scrape max articles cannot be negative")
+	}
+	if config.Workflow.RelevancyMode != "single" && config.Workflow.RelevancyMode != "ensemble" && config.Workflow.RelevancyMode != "embedding" {
+		return fmt.Errorf("Relevancy mode must be single, ensemble or embedding")
+	}
+	embeddingRelevancy := config.Workflow.EmbeddingRelevancy
+	if embeddingRelevancy.SimilarityWeight < 0 || embeddingRelevancy.RecencyWeight < 0 || embeddingRelevancy.CredibilityWeight < 0 ||
+		embeddingRelevancy.SimilarityWeight+embeddingRelevancy.RecencyWeight+embeddingRelevancy.CredibilityWeight <= 0 {
+		return fmt.Errorf("Embedding relevancy weights cannot be negative and must not all be zero")
+	}
+	if embeddingRelevancy.RecencyHalfLife <= 0 || embeddingRelevancy.MaxResults <= 0 {
+		return fmt.Errorf("Embedding relevancy recency half life and max results must be positive")
+	}
+	if config.Workflow.RelevancyMode == "ensemble" {
+		if config.Workflow.RelevancyWindowSize <= 0 {
+			return fmt.Errorf("Relevancy window size must be positive")
+		}
+		if config.Workflow.RelevancyWindowOverlap < 0 || config.Workflow.RelevancyWindowOverlap >= config.Workflow.RelevancyWindowSize {
+			return fmt.Errorf("Relevancy window overlap must be between 0 and the window size")
+		}
+		if config.Workflow.RelevancyCombine != "max" && config.Workflow.RelevancyCombine != "average" {
+			return fmt.Errorf("Relevancy combine must be max or average")
+		}
+	}
+	if config.Workflow.MaxArticlesPerSource < 0 || config.Workflow.MaxVideosPerChannel < 0 {
+		return fmt.Errorf("Per source article and per channel video caps cannot be negative")
+	}
+	if config.Workflow.SentimentMaxArticles <= 0 {
+		return fmt.Errorf("Sentiment max articles must be positive")
+	}
+	if config.Workflow.CondenseArticles && (config.Workflow.CondenseTargetChars <= 0 || config.Workflow.CondenseMinChars <= config.Workflow.CondenseTargetChars) {
+		return fmt.Errorf("Condense target chars must be positive and below condense min chars")
+	}
+	// every attempt is a full fetch, embed and relevancy round, keep it from running away
+	if config.Workflow.MaxBroadenAttempts < 0 || config.Workflow.MaxBroadenAttempts > 3 {
+		return fmt.Errorf("Max broaden attempts must be between 0 and 3")
+	}
+	if config.Redis.MaxContextBytes <= 0 {
+		return fmt.Errorf("Redis max context bytes must be positive")
+	}
+	if config.UserIDs.MaxLength <= 0 {
+		return fmt.Errorf("User ID max length must be positive")
+	}
+	if strings.ContainsAny(config.UserIDs.AllowedPunctuation, ":*?[]{}\\ \t\r\n") {
+		return fmt.Errorf("User ID allowed punctuation must not include separators, glob characters or whitespace")
+	}
+	if config.Subscriptions.Enabled {
+		if config.Subscriptions.PollInterval < time.Minute {
+			return fmt.Errorf("Subscription poll interval must be at least a minute")
+		}
+		if config.Subscriptions.MaxPerUser <= 0 || config.Subscriptions.FetchLimit <= 0 {
+			return fmt.Errorf("Subscription max per user and fetch limit must be positive")
+		}
+		if config.Subscriptions.Lookback < time.Hour {
+			return fmt.Errorf("Subscription lookback must be at least an hour")
+		}
+	}
+	if config.Workflow.MaxConcurrency <= 0 {
+		return fmt.Errorf("Workflow max concurrency must be positive")
+	}
+	if config.Workflow.MaxQueue < 0 {
+		return fmt.Errorf("Workflow max queue cannot be negative")
+	}
+	if config.Workflow.QueueTimeout <= 0 {
+		return fmt.Errorf("Workflow queue timeout must be positive")
+	}
+	if config.Workflow.Deadline <= 0 {
+		return fmt.Errorf("Workflow deadline must be positive")
+	}
+	if _, ok := config.Workflow.FetchLimits["news"]; !ok {
+		return fmt.Errorf("News fetch limits are required")
+	}
+	for name, limits := range config.Workflow.FetchLimits {
+		if !slices.Contains(WorkflowProfileNames, name) {
+			return fmt.Errorf("Unknown workflow profile %s in fetch limits, expected one of %s", name, strings.Join(WorkflowProfileNames, ", "))
+		}
+		if limits.MaxArticles <= 0 || limits.MaxVideos < 0 {
+			return fmt.Errorf("Fetch limits of workflow profile %s must be positive", name)
+		}
+	}
 
 	return nil
 }
@@ -180,6 +1206,21 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func getInt(key string, fallback int) int {
 	value := os.Getenv(key)
 	if value != "" {
@@ -223,3 +1264,279 @@ func getFloat64(key string, fallback float64) float64 {
 	}
 	return fallback
 }
+
+// getAgentSampling parses "agent:temperature=0.2,top_p=0.9,top_k=40,max_tokens=2048;agent2:..." ignoring malformed values
+func getAgentSampling(key string) map[string]AgentSampling {
+	agents := make(map[string]AgentSampling)
+
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		agent, params, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || agent == "" {
+			continue
+		}
+
+		sampling := agents[agent]
+		for _, param := range strings.Split(params, ",") {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found {
+				continue
+			}
+			value = strings.TrimSpace(value)
+
+			if name == "max_tokens" {
+				if maxTokens, err := strconv.ParseInt(value, 10, 32); err == nil {
+					sampling.MaxTokens = int32(maxTokens)
+				}
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(value, 32)
+			if err != nil {
+				continue
+			}
+			number := float32(parsed)
+			switch name {
+			case "temperature":
+				sampling.Temperature = &number
+			case "top_p":
+				sampling.TopP = &number
+			case "top_k":
+				sampling.TopK = &number
+			}
+		}
+
+		agents[agent] = sampling
+	}
+
+	return agents
+}
+
+// getModelPrices parses "gemini-2.5-pro:input=0.00125,output=0.01;nomic-embed-text:latest:request=0.00001" ignoring
+// malformed values, the prices follow the last ':' since ollama model names carry a tag
+func getModelPrices(key string) map[string]ModelPrice {
+	prices := make(map[string]ModelPrice)
+
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		entry = strings.TrimSpace(entry)
+		separator := strings.LastIndexByte(entry, ':')
+		if separator < 0 {
+			continue
+		}
+		model, params := strings.TrimSpace(entry[:separator]), entry[separator+1:]
+		if model == "" {
+			continue
+		}
+
+		price := prices[model]
+		for _, param := range strings.Split(params, ",") {
+			field, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+
+			switch field {
+			case "input":
+				price.InputPer1K = parsed
+			case "output":
+				price.OutputPer1K = parsed
+			case "request":
+				price.PerRequest = parsed
+			}
+		}
+
+		prices[model] = price
+	}
+
+	return prices
+}
+
+// getWorkflowProfiles parses "news:personality=calm-anchor,temperature=0.4,max_tokens=4096;chitchat:..." ignoring malformed values
+func getWorkflowProfiles(key string) map[string]WorkflowProfile {
+	profiles := make(map[string]WorkflowProfile)
+
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		name, params, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || name == "" {
+			continue
+		}
+
+		profile := profiles[name]
+		for _, param := range strings.Split(params, ",") {
+			field, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found {
+				continue
+			}
+			value = strings.TrimSpace(value)
+
+			switch field {
+			case "personality":
+				profile.Personality = value
+			case "temperature":
+				if parsed, err := strconv.ParseFloat(value, 32); err == nil {
+					temperature := float32(parsed)
+					profile.Temperature = &temperature
+				}
+			case "max_tokens":
+				if maxTokens, err := strconv.ParseInt(value, 10, 32); err == nil {
+					profile.MaxTokens = int32(maxTokens)
+				}
+			}
+		}
+
+		profiles[name] = profile
+	}
+
+	return profiles
+}
+
+// getFetchLimits parses "follow_up:max_articles=10,max_videos=0;news:..." on top of the NEWS_FETCH_* limits,
+// which are also the "news" profile's unless it is listed, ignoring malformed values
+func getFetchLimits(key string, news FetchLimits) map[string]FetchLimits {
+	limits := map[string]FetchLimits{"news": news}
+
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		name, params, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || name == "" {
+			continue
+		}
+
+		profile := news
+		for _, param := range strings.Split(params, ",") {
+			field, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found {
+				continue
+			}
+			parsed, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				continue
+			}
+
+			switch field {
+			case "max_articles":
+				profile.MaxArticles = parsed
+			case "max_videos":
+				profile.MaxVideos = parsed
+			case "recent_max_articles":
+				profile.RecentMaxArticles = parsed
+			case "relevancy_candidates":
+				profile.RelevancyCandidates = parsed
+			}
+		}
+
+		limits[name] = profile
+	}
+
+	return limits
+}
+
+// getDomainProfiles parses "example.com:Referer=https://www.google.com/,X-Header=value;example.org:...",
+// domains are lower cased without a leading www. and values may contain ':' but not ','
+func getDomainProfiles(key string) map[string]map[string]string {
+	profiles := make(map[string]map[string]string)
+
+	for _, entry := range strings.Split(os.Getenv(key), ";") {
+		domain, params, found := strings.Cut(strings.TrimSpace(entry), ":")
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		if !found || domain == "" {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ",") {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+			if !found || name == "" {
+				continue
+			}
+			if profiles[domain] == nil {
+				profiles[domain] = make(map[string]string)
+			}
+			profiles[domain][name] = value
+		}
+	}
+
+	return profiles
+}
+
+// getMap parses "key=value;key2=value2", values may contain ':' and ',' which rules out the taxonomy format
+func getMap(key string, fallback map[string]string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	entries := make(map[string]string)
+	for _, entry := range strings.Split(value, ";") {
+		name, mapped, found := strings.Cut(strings.TrimSpace(entry), "=")
+		name, mapped = strings.TrimSpace(name), strings.TrimSpace(mapped)
+		if found && name != "" && mapped != "" {
+			entries[name] = mapped
+		}
+	}
+	return entries
+}
+
+// getTaxonomy parses "category:keyword,keyword;category:keyword", replacing the fallback taxonomy entirely when set
+func getTaxonomy(key string, fallback map[string][]string) map[string][]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	taxonomy := make(map[string][]string)
+	for _, entry := range strings.Split(value, ";") {
+		category, keywords, found := strings.Cut(strings.TrimSpace(entry), ":")
+		category = strings.TrimSpace(category)
+		if !found || category == "" {
+			continue
+		}
+
+		for _, keyword := range strings.Split(keywords, ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				taxonomy[category] = append(taxonomy[category], keyword)
+			}
+		}
+	}
+
+	if len(taxonomy) == 0 {
+		return fallback
+	}
+	return taxonomy
+}
+
+// getTenantPersonas parses "tenant:persona,persona;tenant:persona" allowlists and "tenant:persona;..." defaults
+func getTenantPersonas(allowlistKey, defaultsKey string) map[string]TenantPersonas {
+	tenants := make(map[string]TenantPersonas)
+
+	for _, entry := range strings.Split(os.Getenv(allowlistKey), ";") {
+		tenant, personas, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || tenant == "" {
+			continue
+		}
+
+		var allowed []string
+		for _, persona := range strings.Split(personas, ",") {
+			if persona = strings.TrimSpace(persona); persona != "" {
+				allowed = append(allowed, persona)
+			}
+		}
+
+		tenants[tenant] = TenantPersonas{Allowed: allowed}
+	}
+
+	for _, entry := range strings.Split(os.Getenv(defaultsKey), ";") {
+		tenant, persona, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || tenant == "" {
+			continue
+		}
+
+		tenantPersonas := tenants[tenant]
+		tenantPersonas.Default = strings.TrimSpace(persona)
+		tenants[tenant] = tenantPersonas
+	}
+
+	return tenants
+}
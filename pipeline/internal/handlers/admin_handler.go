@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	orchestrator *services.Orchestrator
+	logger       *logger.Logger
+}
+
+func NewAdminHandler(orchestrator *services.Orchestrator, logger *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		orchestrator: orchestrator,
+		logger:       logger,
+	}
+}
+
+func (adminHandler *AdminHandler) ListWorkflows(ctx *gin.Context) {
+	workflows := adminHandler.orchestrator.ListActiveWorkflows()
+
+	response := models.AdminWorkflowsResponse{
+		Count:     len(workflows),
+		Workflows: workflows,
+		Timestamp: time.Now(),
+	}
+	if len(workflows) > 0 {
+		response.OldestAgeMs = workflows[0].ElapsedMs
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Active workflows retrieved",
+		Data:    response,
+	})
+}
+
+// CompactCollection removes documents stored more than once under different ids, keeping the freshest copy
+func (adminHandler *AdminHandler) CompactCollection(ctx *gin.Context) {
+	var req models.CompactCollectionRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(bindErrorResponse(err))
+			return
+		}
+	}
+	if req.Collection == "" {
+		req.Collection = services.NewsCollectionName
+	}
+	if req.Collection != services.NewsCollectionName && req.Collection != services.VideosCollectionName {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid Collection",
+			Error:   fmt.Sprintf("collection must be %s or %s", services.NewsCollectionName, services.VideosCollectionName),
+		})
+		return
+	}
+
+	adminHandler.logger.Warn("Admin compacting collection", "collection", req.Collection, "tenant", req.Tenant,
+		"dry_run", req.DryRun, "client_ip", ctx.ClientIP())
+
+	report, err := adminHandler.orchestrator.CompactCollection(ctx.Request.Context(), req.Collection, req.Tenant, req.DryRun)
+	if err != nil {
+		adminHandler.logger.WithError(err).Error("Collection compaction failed", "collection", req.Collection)
+		ctx.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Collection compaction failed",
+			Error:   err.Error(),
+			Data:    report,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Collection compacted",
+		Data:    report,
+	})
+}
+
+// ReplayWorkflow reruns a recorded workflow against its recorded inputs and model outputs and reports whether
+// the answer came out the same
+func (adminHandler *AdminHandler) ReplayWorkflow(ctx *gin.Context) {
+	workflowID := ctx.Param("id")
+
+	adminHandler.logger.Warn("Admin replaying workflow", "workflow_id", workflowID, "client_ip", ctx.ClientIP())
+
+	report, err := adminHandler.orchestrator.ReplayWorkflow(ctx.Request.Context(), workflowID)
+	if err != nil {
+		if errors.Is(err, services.ErrRecordingNotFound) {
+			ctx.JSON(http.StatusNotFound, models.APIResponse{
+				Success: false,
+				Message: "Workflow recording not found",
+				Error:   err.Error(),
+			})
+			return
+		}
+		adminHandler.logger.WithError(err).Error("Workflow replay failed", "workflow_id", workflowID)
+		ctx.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Workflow replay failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Workflow replayed",
+		Data:    report,
+	})
+}
+
+func (adminHandler *AdminHandler) CancelWorkflow(ctx *gin.Context) {
+	workflowID := ctx.Param("id")
+	if workflowID == "" {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Workflow ID is required",
+		})
+		return
+	}
+
+	adminHandler.logger.Warn("Admin cancelling workflow", "workflow_id", workflowID, "client_ip", ctx.ClientIP())
+
+	if err := adminHandler.orchestrator.CancelWorkflow(workflowID); err != nil {
+		ctx.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Workflow not found or cannot be cancelled",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Workflow cancellation requested",
+		Data: map[string]string{
+			"workflow_id": workflowID,
+			"status":      string(models.WorkflowStatusCancelled),
+		},
+	})
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newStuckOrchestrator returns a ready orchestrator whose Gemini calls hang until the caller gives up, so every
+// workflow it starts stays in flight until it is cancelled
+func newStuckOrchestrator(t *testing.T, workflowConfig config.WorkflowConfig) *services.Orchestrator {
+	t.Helper()
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	gemini := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the server only notices the client hanging up once the request body has been read
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(gemini.Close)
+	t.Setenv("GOOGLE_GEMINI_BASE_URL", gemini.URL)
+
+	cfg := config.Config{}
+	cfg.Gemini = config.GeminiConfig{APIKey: "test-key", Model: "gemini-test", MaxRetries: 1, Timeout: time.Minute, MaxConcurrency: 4}
+	cfg.Workflow = workflowConfig
+	cfg.Workflow.Deadline = time.Minute
+	cfg.UserIDs = config.UserIDConfig{MaxLength: 128, AllowedPunctuation: "-_.@"}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	listener.Close()
+	cfg.Redis = config.RedisConfig{
+		StreamsURL: "redis://" + listener.Addr().String(), MemoryURL: "redis://" + listener.Addr().String(),
+		AllowStateless: true, DialTimeout: 100 * time.Millisecond,
+	}
+
+	redisService, err := services.NewRedisService(cfg.Redis, log)
+	if err != nil {
+		t.Fatalf("NewRedisService() error = %v", err)
+	}
+	geminiService, err := services.NewGeminiService(cfg.Gemini, log)
+	if err != nil {
+		t.Fatalf("NewGeminiService() error = %v", err)
+	}
+	orchestrator := services.NewOrchestrator(redisService, geminiService, nil, nil, nil, nil, nil, cfg, log)
+	orchestrator.MarkReady()
+	return orchestrator
+}
+
+func newAdminTestRouter(t *testing.T) (*gin.Engine, *services.Orchestrator) {
+	t.Helper()
+	orchestrator := newStuckOrchestrator(t, config.WorkflowConfig{MaxConcurrency: 4})
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewAdminHandler(orchestrator, log)
+	router.GET("/admin/workflows", handler.ListWorkflows)
+	router.DELETE("/admin/workflows/:id", handler.CancelWorkflow)
+	router.POST("/admin/chromadb/compact", handler.CompactCollection)
+	return router, orchestrator
+}
+
+// seedWorkflow starts a workflow that stays in flight, its response arrives once it is cancelled
+func seedWorkflow(t *testing.T, orchestrator *services.Orchestrator, workflowID string) <-chan *models.WorkflowResponse {
+	t.Helper()
+	responses := make(chan *models.WorkflowResponse, 1)
+	go func() {
+		response, _ := orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+			UserID: "user-1", WorkflowID: workflowID, Query: "what is happening with the elections",
+		})
+		responses <- response
+	}()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		for _, workflow := range orchestrator.ListActiveWorkflows() {
+			if workflow.WorkflowID == workflowID && workflow.CurrentAgent != "" {
+				return responses
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("workflow %s never started an agent", workflowID)
+		}
+	}
+}
+
+func serveAdmin(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+func TestAdminListsActiveWorkflows(t *testing.T) {
+	router, orchestrator := newAdminTestRouter(t)
+	first := seedWorkflow(t, orchestrator, "workflow-first")
+	second := seedWorkflow(t, orchestrator, "workflow-second")
+	t.Cleanup(func() {
+		orchestrator.CancelWorkflow("workflow-first")
+		orchestrator.CancelWorkflow("workflow-second")
+		<-first
+		<-second
+	})
+
+	recorder := serveAdmin(router, http.MethodGet, "/admin/workflows")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET /admin/workflows = %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	var body struct {
+		Data models.AdminWorkflowsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	listed := body.Data
+	if listed.Count != 2 || len(listed.Workflows) != 2 {
+		t.Fatalf("listed %d workflows, want 2: %+v", listed.Count, listed.Workflows)
+	}
+	if listed.Workflows[0].WorkflowID != "workflow-first" {
+		t.Errorf("first listed workflow = %s, want the oldest one first", listed.Workflows[0].WorkflowID)
+	}
+	if listed.OldestAgeMs != listed.Workflows[0].ElapsedMs {
+		t.Errorf("oldest age = %dms, want the first workflow's %dms", listed.OldestAgeMs, listed.Workflows[0].ElapsedMs)
+	}
+	for _, workflow := range listed.Workflows {
+		if workflow.UserID != "user-1" || workflow.CurrentAgent == "" || workflow.Status == "" {
+			t.Errorf("workflow %s listed without its user, agent or status: %+v", workflow.WorkflowID, workflow)
+		}
+	}
+}
+
+func TestAdminCancelsAnActiveWorkflow(t *testing.T) {
+	router, orchestrator := newAdminTestRouter(t)
+	responses := seedWorkflow(t, orchestrator, "workflow-stuck")
+
+	if recorder := serveAdmin(router, http.MethodDelete, "/admin/workflows/workflow-stuck"); recorder.Code != http.StatusOK {
+		t.Fatalf("DELETE /admin/workflows/workflow-stuck = %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	select {
+	case response := <-responses:
+		if response.Status != string(models.WorkflowStatusCancelled) {
+			t.Errorf("cancelled workflow status = %s, want cancelled", response.Status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("workflow kept running after it was cancelled")
+	}
+	if count := orchestrator.GetActiveWorkflowsCount(); count != 0 {
+		t.Errorf("%d workflows still active after the cancellation", count)
+	}
+
+	if recorder := serveAdmin(router, http.MethodDelete, "/admin/workflows/workflow-stuck"); recorder.Code != http.StatusNotFound {
+		t.Errorf("cancelling a finished workflow = %d, want 404", recorder.Code)
+	}
+}
+
+func TestAdminCompactionRejectsInvalidRequests(t *testing.T) {
+	router, _ := newAdminTestRouter(t)
+	for _, body := range []string{`{"collection": "user_profiles"}`, `{"dry_run": "yes"}`} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/admin/chromadb/compact", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("POST /admin/chromadb/compact %s = %d %s, want 400", body, recorder.Code, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugHandler serves tooling for prompt engineers, it is only wired up when debug endpoints are enabled
+type DebugHandler struct {
+	orchestrator *services.Orchestrator
+	logger       *logger.Logger
+}
+
+func NewDebugHandler(orchestrator *services.Orchestrator, logger *logger.Logger) *DebugHandler {
+	return &DebugHandler{
+		orchestrator: orchestrator,
+		logger:       logger,
+	}
+}
+
+// ClassifyQuery returns the classifier's verdict for a query without running any downstream agent
+func (debugHandler *DebugHandler) ClassifyQuery(ctx *gin.Context) {
+	var req models.DebugClassifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(bindErrorResponse(err))
+		return
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Query is required",
+		})
+		return
+	}
+
+	result, err := debugHandler.orchestrator.PreviewClassification(ctx.Request.Context(), req.Query, req.ConversationHistory)
+	if err != nil {
+		debugHandler.logger.WithError(err).Error("Classification preview failed")
+		ctx.JSON(http.StatusBadGateway, models.APIResponse{
+			Success: false,
+			Message: "Classification failed",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Query classified",
+		Data:    result,
+	})
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newClassifyingDebugHandler returns a debug handler whose Gemini API answers every call with classification and
+// keeps the prompts it was sent
+func newClassifyingDebugHandler(t *testing.T, classification string) (*DebugHandler, func() []string) {
+	t.Helper()
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	var mu sync.Mutex
+	var prompts []string
+	gemini := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Contents []struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"contents"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		mu.Lock()
+		for _, content := range request.Contents {
+			for _, part := range content.Parts {
+				prompts = append(prompts, part.Text)
+			}
+		}
+		mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{{
+				"content":      map[string]any{"role": "model", "parts": []map[string]string{{"text": classification}}},
+				"finishReason": "STOP",
+			}},
+		})
+	}))
+	t.Cleanup(gemini.Close)
+	t.Setenv("GOOGLE_GEMINI_BASE_URL", gemini.URL)
+
+	cfg := config.Config{}
+	cfg.Gemini = config.GeminiConfig{APIKey: "test-key", Model: "gemini-test", MaxRetries: 1, Timeout: time.Minute, MaxConcurrency: 4}
+	cfg.Workflow.MaxConcurrency = 1
+	geminiService, err := services.NewGeminiService(cfg.Gemini, log)
+	if err != nil {
+		t.Fatalf("NewGeminiService() error = %v", err)
+	}
+
+	received := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), prompts...)
+	}
+	return NewDebugHandler(services.NewOrchestrator(nil, geminiService, nil, nil, nil, nil, nil, cfg, log), log), received
+}
+
+func classifyQuery(handler *DebugHandler, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/api/v1/debug/classify", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+
+	handler.ClassifyQuery(ctx)
+	return recorder
+}
+
+func TestClassifyQueryReturnsTheClassifierVerdict(t *testing.T) {
+	handler, prompts := newClassifyingDebugHandler(t, `{"intent": "FOLLOW_UP_DISCUSSION", "confidence": 0.87,
+		"reasoning": "asks why the earlier rate decision matters", "referenced_topic": "interest rates",
+		"enhanced_query": "why the federal reserve held interest rates", "referenced_exchange_id": "exchange-4"}`)
+
+	var history []string
+	for i := 1; i <= 4; i++ {
+		history = append(history, fmt.Sprintf(`{"id": "exchange-%d", "user_query": "question %d", "ai_response": "answer %d", "intent": "NEW_NEWS_QUERY"}`, i, i, i))
+	}
+	recorder := classifyQuery(handler, `{"query": "why does that matter?", "conversation_history": [`+strings.Join(history, ",")+`]}`)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200", recorder.Code, recorder.Body.String())
+	}
+	var response struct {
+		Success bool                                `json:"success"`
+		Data    services.IntentClassificationResult `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	want := services.IntentClassificationResult{
+		Intent: string(models.IntentFollowUpDiscussion), Confidence: 0.87, Reasoning: "asks why the earlier rate decision matters",
+		ReferencedTopic: "interest rates", EnhancedQuery: "why the federal reserve held interest rates", ReferencedExchangeID: "exchange-4",
+	}
+	got := response.Data
+	if !response.Success || got.Intent != want.Intent || got.Confidence != want.Confidence || got.Reasoning != want.Reasoning ||
+		got.ReferencedTopic != want.ReferencedTopic || got.EnhancedQuery != want.EnhancedQuery || got.ReferencedExchangeID != want.ReferencedExchangeID {
+		t.Errorf("classification = %+v, want %+v", got, want)
+	}
+
+	// one call to the classifier only, seeing the same three most recent exchanges a workflow would
+	sent := prompts()
+	if len(sent) != 1 {
+		t.Fatalf("gemini received %d prompts, want only the classifier's", len(sent))
+	}
+	for _, exchange := range []string{"question 2", "question 3", "question 4"} {
+		if !strings.Contains(sent[0], exchange) {
+			t.Errorf("classifier prompt is missing %q", exchange)
+		}
+	}
+	if strings.Contains(sent[0], "question 1") {
+		t.Error("classifier prompt contains an exchange older than a workflow would see")
+	}
+}
+
+func TestClassifyQueryRequiresAQuery(t *testing.T) {
+	handler, prompts := newClassifyingDebugHandler(t, `{"intent": "NEW_NEWS_QUERY", "confidence": 0.9}`)
+
+	for _, body := range []string{`{"conversation_history": []}`, `{"query": "   "}`} {
+		if recorder := classifyQuery(handler, body); recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %s, want 400", body, recorder.Code, recorder.Body.String())
+		}
+	}
+	if sent := prompts(); len(sent) != 0 {
+		t.Errorf("gemini received %d prompts for invalid requests, want none", len(sent))
+	}
+}
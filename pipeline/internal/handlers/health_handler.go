@@ -10,6 +10,9 @@ import (
 	"time"
 )
 
+// healthCheckTimeout bounds a health probe, every dependency check has to answer well within a probe interval
+const healthCheckTimeout = 10 * time.Second
+
 type HealthHandler struct {
 	orchestrator *services.Orchestrator
 	logger       *logger.Logger
@@ -29,7 +32,7 @@ func (healthHandler *HealthHandler) HealthCheck(ctx *gin.Context) {
 
 	healthHandler.logger.Debug("Health Check requested")
 
-	Newctx, cancel := context.WithTimeout(ctx.Request.Context(), time.Second*100)
+	Newctx, cancel := context.WithTimeout(ctx.Request.Context(), healthCheckTimeout)
 	defer cancel()
 
 	err := healthHandler.orchestrator.HealthCheck(Newctx)
@@ -60,6 +63,8 @@ func (healthHandler *HealthHandler) HealthCheck(ctx *gin.Context) {
 		healthHandler.logger.Debug("Health Check succeeded", "duration", time.Since(startTime))
 	}
 
+	services["youtube"] = healthHandler.orchestrator.YouTubeQuotaStatus()
+
 	response := models.HealthResponse{
 		Status:    status,
 		Timestamp: time.Now(),
@@ -80,6 +85,25 @@ func (healthHandler *HealthHandler) LivenessProbe(c *gin.Context) {
 }
 
 func (healthHandler *HealthHandler) ReadinessProbe(c *gin.Context) {
+	if healthHandler.orchestrator.IsDraining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":           "draining",
+			"ready":            false,
+			"active_workflows": healthHandler.orchestrator.GetActiveWorkflowsCount(),
+			"timestamp":        time.Now(),
+		})
+		return
+	}
+
+	if !healthHandler.orchestrator.IsReady() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "starting",
+			"ready":     false,
+			"timestamp": time.Now(),
+		})
+		return
+	}
+
 	activeWorkflows := healthHandler.orchestrator.GetActiveWorkflowsCount()
 	ready := activeWorkflows < 100
 
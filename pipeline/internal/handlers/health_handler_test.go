@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestHealthHandler(t *testing.T) (*HealthHandler, *services.Orchestrator) {
+	t.Helper()
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	cfg := config.Config{}
+	cfg.Workflow.MaxConcurrency = 1
+	orchestrator := services.NewOrchestrator(nil, nil, nil, nil, nil, nil, nil, cfg, log)
+	return NewHealthHandler(orchestrator, log), orchestrator
+}
+
+func probeReadiness(t *testing.T, handler *HealthHandler) (int, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/ready", nil)
+
+	handler.ReadinessProbe(ctx)
+
+	var body struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode readiness body: %v", err)
+	}
+	return recorder.Code, body.Status
+}
+
+func TestReadinessProbeWaitsForTheStartupGate(t *testing.T) {
+	handler, orchestrator := newTestHealthHandler(t)
+
+	if code, status := probeReadiness(t, handler); code != http.StatusServiceUnavailable || status != "starting" {
+		t.Errorf("before the gate passed: %d %q, want 503 starting", code, status)
+	}
+
+	orchestrator.MarkReady()
+	if code, status := probeReadiness(t, handler); code != http.StatusOK || status != "ready" {
+		t.Errorf("after the gate passed: %d %q, want 200 ready", code, status)
+	}
+}
+
+func TestReadinessProbeReportsDraining(t *testing.T) {
+	handler, orchestrator := newTestHealthHandler(t)
+	orchestrator.MarkReady()
+	orchestrator.StartDraining()
+
+	if code, status := probeReadiness(t, handler); code != http.StatusServiceUnavailable || status != "draining" {
+		t.Errorf("while draining: %d %q, want 503 draining", code, status)
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// bindErrorResponse maps a failed JSON bind to its response, a body cut off by the size limit is a 413
+func bindErrorResponse(err error) (int, models.APIResponse) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge, models.APIResponse{
+			Success: false,
+			Message: "Request body too large",
+			Error:   err.Error(),
+		}
+	}
+
+	return http.StatusBadRequest, models.APIResponse{
+		Success: false,
+		Message: "Invalid Request Format",
+		Error:   err.Error(),
+	}
+}
+
+// boolMetadata reads an optional boolean flag from the request metadata, a missing key is false and any other
+// type is an error
+func boolMetadata(metadata map[string]any, key string) (value bool, err error) {
+	raw, exists := metadata[key]
+	if !exists {
+		return false, nil
+	}
+	value, ok := raw.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+	return value, nil
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxSubscriptionTopicLength = 200
+	maxSubscriptionKeywords    = 10
+)
+
+type SubscriptionHandler struct {
+	orchestrator *services.Orchestrator
+	logger       *logger.Logger
+}
+
+func NewSubscriptionHandler(orchestrator *services.Orchestrator, logger *logger.Logger) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		orchestrator: orchestrator,
+		logger:       logger,
+	}
+}
+
+func (subscriptionHandler *SubscriptionHandler) CreateSubscription(ctx *gin.Context) {
+	if !subscriptionHandler.enabled(ctx) {
+		return
+	}
+
+	var req models.CreateSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(bindErrorResponse(err))
+		return
+	}
+
+	if req.UserID != "" {
+		userID, ok := canonicalUserID(ctx, subscriptionHandler.orchestrator, req.UserID)
+		if !ok {
+			return
+		}
+		req.UserID = userID
+	}
+
+	if err := validateSubscriptionRequest(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid Subscription",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	subscription, err := subscriptionHandler.orchestrator.CreateSubscription(ctx.Request.Context(), ctx.GetString("tenant_id"), &req)
+	if err != nil {
+		subscriptionHandler.logger.WithError(err).Error("Failed to create subscription", "user_id", req.UserID)
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrSubscriptionLimit) {
+			status = http.StatusConflict
+		} else if errors.Is(err, services.ErrCallbackNotAllowed) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, models.APIResponse{
+			Success: false,
+			Message: "Failed to create subscription",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Subscription created",
+		Data:    subscription,
+	})
+}
+
+func (subscriptionHandler *SubscriptionHandler) ListSubscriptions(ctx *gin.Context) {
+	if !subscriptionHandler.enabled(ctx) {
+		return
+	}
+
+	userID := ctx.Query("user_id")
+	if userID == "" {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "user_id is required",
+		})
+		return
+	}
+
+	userID, ok := canonicalUserID(ctx, subscriptionHandler.orchestrator, userID)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := subscriptionHandler.orchestrator.ListSubscriptions(ctx.Request.Context(), userID)
+	if err != nil {
+		subscriptionHandler.logger.WithError(err).Error("Failed to list subscriptions", "user_id", userID)
+		ctx.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to list subscriptions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Subscriptions retrieved",
+		Data:    subscriptions,
+	})
+}
+
+func (subscriptionHandler *SubscriptionHandler) DeleteSubscription(ctx *gin.Context) {
+	if !subscriptionHandler.enabled(ctx) {
+		return
+	}
+
+	subscriptionID := ctx.Param("id")
+	userID := ctx.Query("user_id")
+	if subscriptionID == "" || userID == "" {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Subscription ID and user_id are required",
+		})
+		return
+	}
+
+	userID, ok := canonicalUserID(ctx, subscriptionHandler.orchestrator, userID)
+	if !ok {
+		return
+	}
+
+	if err := subscriptionHandler.orchestrator.DeleteSubscription(ctx.Request.Context(), userID, subscriptionID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrSubscriptionNotFound) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, models.APIResponse{
+			Success: false,
+			Message: "Failed to delete subscription",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Subscription deleted",
+		Data:    map[string]string{"subscription_id": subscriptionID},
+	})
+}
+
+// enabled answers 503 and reports false when subscriptions are switched off
+func (subscriptionHandler *SubscriptionHandler) enabled(ctx *gin.Context) bool {
+	if subscriptionHandler.orchestrator.SubscriptionsEnabled() {
+		return true
+	}
+
+	ctx.JSON(http.StatusServiceUnavailable, models.APIResponse{
+		Success: false,
+		Message: "Subscriptions are disabled",
+	})
+	return false
+}
+
+func validateSubscriptionRequest(req *models.CreateSubscriptionRequest) error {
+	if req.UserID == "" {
+		return fmt.Errorf("user_id is required")
+	}
+
+	topic := strings.TrimSpace(req.Topic)
+	if topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	if len(topic) > maxSubscriptionTopicLength {
+		return fmt.Errorf("topic exceeds %d characters", maxSubscriptionTopicLength)
+	}
+	if len(req.Keywords) > maxSubscriptionKeywords {
+		return fmt.Errorf("at most %d keywords are allowed", maxSubscriptionKeywords)
+	}
+
+	if req.CallbackURL != "" {
+		parsed, err := url.Parse(req.CallbackURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("callback_url must be an absolute http or https url")
+		}
+	}
+
+	return nil
+}
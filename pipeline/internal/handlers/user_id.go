@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/services"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// canonicalUserID answers 400 and reports false when the user id cannot be used in a storage key
+func canonicalUserID(ctx *gin.Context, orchestrator *services.Orchestrator, userID string) (string, bool) {
+	canonical, err := orchestrator.CanonicalUserID(userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Invalid User ID",
+			Error:   err.Error(),
+		})
+		return "", false
+	}
+	return canonical, true
+}
+
+// requestedUserID is the canonical user named by the "user_id" query or the X-User-ID header, empty when the
+// caller names none. It answers 400 and reports false when the named id cannot be used.
+func requestedUserID(ctx *gin.Context, orchestrator *services.Orchestrator) (string, bool) {
+	userID := ctx.Query("user_id")
+	if userID == "" {
+		userID = ctx.GetHeader("X-User-ID")
+	}
+	if userID == "" {
+		return "", true
+	}
+	return canonicalUserID(ctx, orchestrator, userID)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestExecuteWorkflowRejectsMalformedUserIDs(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	for _, userID := range []string{`user:1`, `user-*`, `user\n1`, `user 1`, `usér`, strings.Repeat("a", 129)} {
+		recorder := executeWorkflowRequest(handler, `{"user_id": "`+userID+`", "query": "latest news"}`, false)
+
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "Invalid User ID") {
+			t.Errorf("user_id %q got %d %s, want 400 Invalid User ID", userID, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	// a valid id gets past the check to the next validation
+	recorder := executeWorkflowRequest(handler, `{"user_id": "alice.smith@example.com", "query": "latest news", "metadata": {"explain": "yes"}}`, false)
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "explain must be a boolean") {
+		t.Errorf("valid user id got %d %s, want it accepted", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestWebsocketRejectsAMalformedUserID(t *testing.T) {
+	_, wsURL := newWebsocketTestServer(t)
+
+	malformed := strings.TrimSuffix(wsURL, "user-1") + url.QueryEscape("user:*")
+	_, response, err := websocket.DefaultDialer.Dial(malformed, nil)
+	if err == nil || response == nil || response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Dial() with a malformed user id = %v, want a 400 before upgrading", err)
+	}
+}
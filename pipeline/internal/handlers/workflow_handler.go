@@ -5,13 +5,20 @@ import (
 	"Infiya-ai-pipeline/internal/pkg/logger"
 	"Infiya-ai-pipeline/internal/services"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// time left to write the response once the longest possible workflow has finished
+const syncResponseWriteWait = 10 * time.Second
+
 type WorkflowHandler struct {
 	orchestrator *services.Orchestrator
 	logger       *logger.Logger
@@ -29,27 +36,112 @@ func NewWorkflowHandler(orchestrator *services.Orchestrator, logger *logger.Logg
 func (workflowHandler *WorkflowHandler) ExecuteWorkflow(ctx *gin.Context) {
 	startTime := time.Now()
 
+	if workflowHandler.orchestrator.IsDraining() {
+		ctx.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Message: "Service is shutting down",
+			Error:   "not accepting new workflows",
+		})
+		return
+	}
+
+	if !workflowHandler.orchestrator.IsReady() {
+		ctx.JSON(http.StatusServiceUnavailable, models.APIResponse{
+			Success: false,
+			Message: "Service is starting",
+			Error:   "dependencies are not ready yet",
+		})
+		return
+	}
+
 	var req models.ExecuteWorkflowRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		workflowHandler.logger.WithError(err).Error("failed to bind workflow request")
+		ctx.JSON(bindErrorResponse(err))
+		return
+	}
+
+	userID, ok := canonicalUserID(ctx, workflowHandler.orchestrator, req.UserID)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	if err := workflowHandler.validateUserPreferences(req.UserPreferences); err != nil {
+		workflowHandler.logger.WithError(err).Error("Invalid User Preferences")
 		ctx.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
-			Message: "Invalid Request Format",
+			Message: "Invalid User Preferences",
 			Error:   err.Error(),
 		})
 		return
 	}
 
-	if err := workflowHandler.validateUserPreferences(req.UserPreferences); err != nil {
-		workflowHandler.logger.WithError(err).Error("Invalid User Preferences")
+	if _, err := services.ParseGenerationOverrides(req.Metadata); err != nil {
+		workflowHandler.logger.WithError(err).Error("Invalid Generation Overrides")
 		ctx.JSON(http.StatusBadRequest, models.APIResponse{
 			Success: false,
-			Message: "Invalid User Preferences",
+			Message: "Invalid Metadata",
 			Error:   err.Error(),
 		})
 		return
 	}
 
+	for _, key := range []string{"explain", "include_intermediate", "typed_events", "headline", "selection_reasons",
+		"articles_only_response", "record"} {
+		if _, err := boolMetadata(req.Metadata, key); err != nil {
+			ctx.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid Metadata",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+
+	// a recording keeps the user's sources and answers on disk, only operators may ask for one
+	if record, _ := boolMetadata(req.Metadata, "record"); record && !ctx.GetBool("is_admin") {
+		ctx.JSON(http.StatusForbidden, models.APIResponse{
+			Success: false,
+			Message: "Invalid Metadata",
+			Error:   "record requires the admin key",
+		})
+		return
+	}
+
+	if policy, exists := req.Metadata["opinion_policy"]; exists {
+		if value, ok := policy.(string); !ok || !services.IsValidOpinionPolicy(value) {
+			ctx.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid Metadata",
+				Error:   "opinion_policy must be keep, downweight or exclude",
+			})
+			return
+		}
+	}
+
+	if verbosity, exists := req.Metadata["update_verbosity"]; exists {
+		if value, ok := verbosity.(string); !ok || !services.IsValidUpdateVerbosity(value) {
+			ctx.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid Metadata",
+				Error:   "update_verbosity must be verbose, quiet or milestones",
+			})
+			return
+		}
+	}
+
+	if mode, exists := req.Metadata["relevancy_mode"]; exists {
+		if value, ok := mode.(string); !ok || !services.IsValidRelevancyMode(value) {
+			ctx.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid Metadata",
+				Error:   "relevancy_mode must be single, ensemble or embedding",
+			})
+			return
+		}
+	}
+
 	// Use workflow_id from request if provided, otherwise generate new one
 	workflowID := req.WorkflowID
 	if workflowID == "" {
@@ -58,11 +150,22 @@ func (workflowHandler *WorkflowHandler) ExecuteWorkflow(ctx *gin.Context) {
 
 	workflowHandler.logger.Info("Using workflow ID", "provided_id", req.WorkflowID, "final_id", workflowID)
 
+	// the streamed response is built from the typed events, so they are turned on for the request
+	streamLines := workflowHandler.orchestrator.ChunkedResponsesEnabled() && acceptsJSONLines(ctx.GetHeader("Accept"))
+	if streamLines {
+		if req.Metadata == nil {
+			req.Metadata = make(map[string]interface{})
+		}
+		req.Metadata["typed_events"] = true
+	}
+
 	worflowRequest := &models.WorkflowRequest{
 		UserID:          req.UserID,
 		Query:           req.Query,
 		UserPreferences: req.UserPreferences,
 		WorkflowID:      workflowID,
+		Metadata:        req.Metadata,
+		TenantID:        ctx.GetString("tenant_id"),
 	}
 
 	workflowHandler.logger.Info(" Executing workflow ",
@@ -77,7 +180,23 @@ func (workflowHandler *WorkflowHandler) ExecuteWorkflow(ctx *gin.Context) {
 	newCtx, cancel := context.WithTimeout(ctx.Request.Context(), 2000*time.Second)
 	defer cancel()
 
+	if streamLines {
+		workflowHandler.streamWorkflow(ctx, newCtx, worflowRequest, startTime)
+		return
+	}
+
+	// the server's write timeout is shorter than a workflow may run, the timeout response must still get out
+	writeDeadline := time.Now().Add(workflowHandler.orchestrator.MaxWorkflowDuration() + syncResponseWriteWait)
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(writeDeadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		workflowHandler.logger.WithError(err).Warn("Failed to extend the workflow response write deadline", "workflow_id", workflowID)
+	}
+
 	response, err := workflowHandler.orchestrator.ExecuteWorkflow(newCtx, worflowRequest)
+	if rejectedByAdmission(err) {
+		workflowHandler.logger.Warn("Workflow rejected by admission control", "workflow_id", workflowID, "reason", err.Error())
+		respondAtCapacity(ctx, err)
+		return
+	}
 	if err != nil {
 		workflowHandler.logger.WithError(err).Error("Workflow Execution Failed", "workflow_id", workflowID, "duration", time.Since(startTime))
 		ctx.JSON(http.StatusOK, models.APIResponse{
@@ -88,6 +207,16 @@ func (workflowHandler *WorkflowHandler) ExecuteWorkflow(ctx *gin.Context) {
 		return
 	}
 
+	if response.Status == string(models.WorkflowStatusTimeout) {
+		workflowHandler.logger.Warn("Workflow timed out", "workflow_id", workflowID, "duration", time.Since(startTime))
+		ctx.JSON(http.StatusOK, models.APIResponse{
+			Success: false,
+			Message: "Workflow timed out",
+			Data:    response,
+		})
+		return
+	}
+
 	workflowHandler.logger.Info("Workflow completed successfully",
 		"workflow_id", workflowID,
 		"user_id", req.UserID,
@@ -117,6 +246,31 @@ func (workflowHandler *WorkflowHandler) GetWorkflowStatus(ctx *gin.Context) {
 	workflowHandler.logger.Info("Getting workflow status", "workflow_id", workflowID)
 	workflowCtx, err := workflowHandler.orchestrator.GetWorkflowStatus(workflowID)
 	if err != nil {
+		// the workflow state is short lived, completed workflows may still have a persisted result
+		// a result of another tenant, or of another user when the caller names one, is not found
+		result, resultErr := workflowHandler.orchestrator.GetWorkflowResult(ctx.Request.Context(), workflowID)
+		if resultErr != nil {
+			workflowHandler.logger.WithError(resultErr).Warn("Failed to read persisted workflow result", "workflow_id", workflowID)
+		} else if result != nil {
+			userID, ok := requestedUserID(ctx, workflowHandler.orchestrator)
+			if !ok {
+				return
+			}
+			if result.TenantID != ctx.GetString("tenant_id") || (userID != "" && userID != result.UserID) {
+				ctx.JSON(http.StatusNotFound, models.APIResponse{
+					Success: false,
+					Message: "Workflow not found",
+				})
+				return
+			}
+			ctx.JSON(http.StatusOK, models.APIResponse{
+				Success: true,
+				Message: "Workflow status retrieved",
+				Data:    convertResultToStatusResponse(result),
+			})
+			return
+		}
+
 		workflowHandler.logger.WithError(err).Error("Failed to get workflow status", "workflow_id", workflowID)
 		ctx.JSON(http.StatusNotFound, models.APIResponse{
 			Success: false,
@@ -136,6 +290,71 @@ func (workflowHandler *WorkflowHandler) GetWorkflowStatus(ctx *gin.Context) {
 
 }
 
+// GetWorkflowSources serves a workflow's sources a page at a time, "page" counts from 1 and "page_size" defaults
+// to the configured size. Sources of another tenant, or of another user when the caller names one, are not found.
+func (workflowHandler *WorkflowHandler) GetWorkflowSources(ctx *gin.Context) {
+	workflowID := ctx.Param("id")
+	if workflowID == "" {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Workflow ID is required",
+		})
+		return
+	}
+
+	page, pageSize := 1, 0
+	var err error
+	if value := ctx.Query("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			ctx.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid Pagination",
+				Error:   "page must be a positive integer",
+			})
+			return
+		}
+	}
+	if value := ctx.Query("page_size"); value != "" {
+		if pageSize, err = strconv.Atoi(value); err != nil || pageSize < 1 {
+			ctx.JSON(http.StatusBadRequest, models.APIResponse{
+				Success: false,
+				Message: "Invalid Pagination",
+				Error:   "page_size must be a positive integer",
+			})
+			return
+		}
+	}
+
+	sources, err := workflowHandler.orchestrator.GetWorkflowSources(ctx.Request.Context(), workflowID)
+	if err != nil {
+		workflowHandler.logger.WithError(err).Error("Failed to get workflow sources", "workflow_id", workflowID)
+		ctx.JSON(http.StatusInternalServerError, models.APIResponse{
+			Success: false,
+			Message: "Failed to get workflow sources",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, ok := requestedUserID(ctx, workflowHandler.orchestrator)
+	if !ok {
+		return
+	}
+	if sources == nil || sources.TenantID != ctx.GetString("tenant_id") || (userID != "" && userID != sources.UserID) {
+		ctx.JSON(http.StatusNotFound, models.APIResponse{
+			Success: false,
+			Message: "Workflow sources not found",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Workflow sources retrieved",
+		Data:    sources.Paginate(page, workflowHandler.orchestrator.SourcesPageSize(pageSize)),
+	})
+}
+
 func (workflowHandler *WorkflowHandler) CancelWorkflow(ctx *gin.Context) {
 	workflowID := ctx.Param("id")
 	if workflowID == "" {
@@ -202,28 +421,48 @@ func (workflowHandler *WorkflowHandler) convertToStatusResponse(ctx *models.Work
 	}
 
 	return models.WorkflowStatusResponse{
-		WorkflowID: ctx.ID,
-		RequestID:  ctx.RequestID,
-		Status:     string(ctx.Status),
-		Intent:     ctx.Intent,
-		Response:   ctx.Response,
-		Summary:    ctx.Summary,
-		TotalTime:  totalTime,
+		WorkflowID:      ctx.ID,
+		RequestID:       ctx.RequestID,
+		Status:          string(ctx.Status),
+		Intent:          ctx.Intent,
+		Response:        ctx.Response,
+		Summary:         ctx.Summary,
+		TotalTime:       totalTime,
+		AgentExecutions: ctx.AgentExecutions,
 		ProcessingStats: models.ProcessingStatsResponse{
 			APICallsCount:    ctx.ProcessingStats.APICallsCount,
 			ArticlesFound:    ctx.ProcessingStats.ArticlesFound,
 			ArticlesFiltered: ctx.ProcessingStats.ArticlesFiltered,
 			EmbeddingsCount:  ctx.ProcessingStats.EmbeddingsCount,
+			QualityScore:     ctx.ProcessingStats.QualityScore,
 		},
 		AgentStats: agentStats,
 	}
 
 }
 
+func convertResultToStatusResponse(result *models.WorkflowResult) models.WorkflowStatusResponse {
+	return models.WorkflowStatusResponse{
+		WorkflowID: result.WorkflowID,
+		RequestID:  result.RequestID,
+		Status:     result.Status,
+		Intent:     result.Intent,
+		Response:   result.Response,
+		Summary:    result.Summary,
+		TotalTime:  result.TotalTime,
+		ProcessingStats: models.ProcessingStatsResponse{
+			QualityScore: result.QualityScore,
+		},
+		AgentStats: []models.AgentStatsResponse{},
+		Sources:    result.Sources,
+		Persisted:  true,
+	}
+}
+
 func (workflowHandler *WorkflowHandler) validateUserPreferences(userPreferences models.UserPreferences) error {
-	validPersonalities := []string{"calm-anchor", "friendly-explainer", "investigative-reporter", "youthful-trendspotter", "global-correspondent", "ai-analyst"}
+	validPersonalities := models.AvailablePersonas
 
-	if userPreferences.NewsPersonality != "" {
+	if userPreferences.NewsPersonality != "" && userPreferences.NewsPersonality != models.NoPersona {
 		valid := false
 		for _, validPersonality := range validPersonalities {
 			if userPreferences.NewsPersonality == validPersonality {
@@ -250,6 +489,51 @@ func (workflowHandler *WorkflowHandler) validateUserPreferences(userPreferences
 		}
 	}
 
+	if userPreferences.Region != "" {
+		valid := false
+		for _, region := range models.SupportedRegions {
+			if strings.EqualFold(userPreferences.Region, region) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid region: %s", userPreferences.Region)
+		}
+	}
+
+	if userPreferences.Timezone != "" {
+		if _, err := time.LoadLocation(userPreferences.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %s", userPreferences.Timezone)
+		}
+	}
+
+	validSummaryFormats := []string{"prose", "bullets", "sections", "tldr", "json"}
+	if userPreferences.SummaryFormat != "" {
+		valid := false
+		for _, f := range validSummaryFormats {
+			if userPreferences.SummaryFormat == f {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid summary_format: %s", userPreferences.SummaryFormat)
+		}
+	}
+
+	if utf8.RuneCountInString(userPreferences.CustomInstructions) > models.MaxCustomInstructionLength {
+		return fmt.Errorf("custom_instructions exceeds %d characters", models.MaxCustomInstructionLength)
+	}
+
+	if userPreferences.OutputFormat != "" {
+		switch models.OutputFormat(userPreferences.OutputFormat) {
+		case models.OutputFormatMarkdown, models.OutputFormatPlaintext, models.OutputFormatSSML:
+		default:
+			return fmt.Errorf("invalid output_format: %s", userPreferences.OutputFormat)
+		}
+	}
+
 	// Validate FavouriteTopics length
 	if len(userPreferences.FavouriteTopics) > 10 {
 		return fmt.Errorf("too many favourite topics: maximum 10 allowed")
@@ -258,3 +542,17 @@ func (workflowHandler *WorkflowHandler) validateUserPreferences(userPreferences
 	return nil
 
 }
+
+func (workflowHandler *WorkflowHandler) ListPersonas(ctx *gin.Context) {
+	tenantID := ctx.GetString("tenant_id")
+
+	ctx.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Personas retrieved",
+		Data: gin.H{
+			"tenant_id":       tenantID,
+			"personas":        workflowHandler.orchestrator.AllowedPersonas(tenantID),
+			"default_persona": workflowHandler.orchestrator.DefaultPersona(tenantID),
+		},
+	})
+}
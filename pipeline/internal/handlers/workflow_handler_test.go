@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/middleware"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestWorkflowHandler returns a handler over a ready orchestrator without backing services,
+// only requests rejected before a workflow starts can be served by it
+func newTestWorkflowHandler(t *testing.T) (*WorkflowHandler, *services.Orchestrator) {
+	t.Helper()
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	cfg := config.Config{}
+	cfg.Workflow.MaxConcurrency = 1
+	cfg.UserIDs = config.UserIDConfig{MaxLength: 128, AllowedPunctuation: "-_.@"}
+	orchestrator := services.NewOrchestrator(nil, nil, nil, nil, nil, nil, nil, cfg, log)
+	orchestrator.MarkReady()
+	return NewWorkflowHandler(orchestrator, log), orchestrator
+}
+
+func executeWorkflowRequest(handler *WorkflowHandler, body string, isAdmin bool) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/workflow", strings.NewReader(body))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Set("is_admin", isAdmin)
+
+	handler.ExecuteWorkflow(ctx)
+	return recorder
+}
+
+func TestExecuteWorkflowReservesRecordingForAdmins(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "latest news", "metadata": {"record": true}}`, false)
+
+	if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), "admin key") {
+		t.Errorf("got %d %s, want 403 asking for the admin key", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsNewWorkflowsWhileDraining(t *testing.T) {
+	handler, orchestrator := newTestWorkflowHandler(t)
+	orchestrator.StartDraining()
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "latest news"}`, false)
+
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), "shutting down") {
+		t.Errorf("got %d %s, want 503 while shutting down", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowTurnsAwayWorkflowsBeyondCapacity(t *testing.T) {
+	orchestrator := newStuckOrchestrator(t, config.WorkflowConfig{MaxConcurrency: 1, QueueTimeout: time.Second})
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	handler := NewWorkflowHandler(orchestrator, log)
+
+	running := seedWorkflow(t, orchestrator, "workflow-running")
+	t.Cleanup(func() {
+		orchestrator.CancelWorkflow("workflow-running")
+		<-running
+	})
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "latest news"}`, false)
+
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), "at capacity") {
+		t.Errorf("got %d %s, want 503 at capacity", recorder.Code, recorder.Body.String())
+	}
+	if retryAfter := recorder.Header().Get("Retry-After"); retryAfter != "5" {
+		t.Errorf("Retry-After = %q, want 5", retryAfter)
+	}
+}
+
+func TestExecuteWorkflowTurnsAwayQueuedWorkflowsWhenDrainingStarts(t *testing.T) {
+	orchestrator := newStuckOrchestrator(t, config.WorkflowConfig{MaxConcurrency: 1, MaxQueue: 1, QueueTimeout: 30 * time.Second})
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	handler := NewWorkflowHandler(orchestrator, log)
+
+	running := seedWorkflow(t, orchestrator, "workflow-running")
+	t.Cleanup(func() {
+		orchestrator.CancelWorkflow("workflow-running")
+		<-running
+	})
+
+	queued := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		queued <- executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "latest news"}`, false)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); orchestrator.AdmissionStats()["queued"].(int64) != 1; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the request never queued for a slot")
+		}
+	}
+	orchestrator.StartDraining()
+
+	recorder := <-queued
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), "shutting down") {
+		t.Errorf("got %d %s, want 503 while shutting down", recorder.Code, recorder.Body.String())
+	}
+	if retryAfter := recorder.Header().Get("Retry-After"); retryAfter != "5" {
+		t.Errorf("Retry-After = %q, want 5", retryAfter)
+	}
+}
+
+func TestExecuteWorkflowRejectsInvalidGenerationOverrides(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "latest news", "metadata": {"temperature": 3}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "temperature") {
+		t.Errorf("got %d %s, want 400 naming the temperature override", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsNonBooleanExplain(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "latest news", "metadata": {"explain": "yes"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "explain must be a boolean") {
+		t.Errorf("got %d %s, want 400 asking for a boolean explain flag", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowValidatesRegionAndTimezone(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	tests := []struct {
+		preferences string
+		want        string
+	}{
+		{preferences: `{"region": "zz"}`, want: "invalid region: zz"},
+		{preferences: `{"region": "IN", "timezone": "Mars/Olympus_Mons"}`, want: "invalid timezone: Mars/Olympus_Mons"},
+	}
+	for _, tt := range tests {
+		recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "election results", "user_preferences": `+tt.preferences+`}`, false)
+
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), tt.want) {
+			t.Errorf("preferences %s: got %d %s, want 400 with %q", tt.preferences, recorder.Code, recorder.Body.String(), tt.want)
+		}
+	}
+}
+
+func TestExecuteWorkflowRejectsAnUnknownOutputFormat(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "election results", "user_preferences": {"output_format": "html"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "invalid output_format: html") {
+		t.Errorf("got %d %s, want 400 naming the output format", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsAnUnknownOpinionPolicy(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	for _, policy := range []string{`"hide"`, `true`} {
+		recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"opinion_policy": `+policy+`}}`, false)
+
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "opinion_policy must be") {
+			t.Errorf("opinion_policy %s: got %d %s, want 400", policy, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestExecuteWorkflowRejectsOverlongCustomInstructions(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+	instructions := strings.Repeat("a", models.MaxCustomInstructionLength+1)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "user_preferences": {"custom_instructions": "`+instructions+`"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "custom_instructions exceeds") {
+		t.Errorf("got %d %s, want 400 naming the custom instructions limit", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsNonBooleanArticlesOnly(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"articles_only_response": "yes"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "articles_only_response must be a boolean") {
+		t.Errorf("got %d %s, want 400 asking for a boolean flag", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsNonBooleanIncludeIntermediate(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"include_intermediate": 1}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "include_intermediate must be a boolean") {
+		t.Errorf("got %d %s, want 400 asking for a boolean flag", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsAnUnknownUpdateVerbosity(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"update_verbosity": "chatty"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "update_verbosity must be") {
+		t.Errorf("got %d %s, want 400 naming the supported verbosities", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsNonBooleanSelectionReasons(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"selection_reasons": "yes"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "selection_reasons must be a boolean") {
+		t.Errorf("got %d %s, want 400 asking for a boolean flag", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsNonBooleanTypedEvents(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"typed_events": "on"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "typed_events must be a boolean") {
+		t.Errorf("got %d %s, want 400 asking for a boolean flag", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsANonBooleanHeadline(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"headline": "short"}}`, false)
+
+	if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "headline must be a boolean") {
+		t.Errorf("got %d %s, want 400 asking for a boolean flag", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestExecuteWorkflowRejectsAnUnknownRelevancyMode(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	for _, mode := range []string{`"cheap"`, `true`} {
+		recorder := executeWorkflowRequest(handler, `{"user_id": "user-1", "query": "rates", "metadata": {"relevancy_mode": `+mode+`}}`, false)
+
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), "relevancy_mode must be single, ensemble or embedding") {
+			t.Errorf("relevancy_mode %s got %d %s, want 400 naming the modes", mode, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestValidateUserPreferencesAcceptsNoPersona(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+
+	if err := handler.validateUserPreferences(models.UserPreferences{NewsPersonality: models.NoPersona}); err != nil {
+		t.Errorf("validateUserPreferences(none) error = %v, want the persona step skippable", err)
+	}
+	if err := handler.validateUserPreferences(models.UserPreferences{NewsPersonality: "pirate"}); err == nil {
+		t.Error("validateUserPreferences(pirate) accepted an unknown persona")
+	}
+}
+
+func TestExecuteWorkflowRejectsAnOversizedBody(t *testing.T) {
+	handler, _ := newTestWorkflowHandler(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.BodyLimitMiddleware(256))
+	router.POST("/workflow", handler.ExecuteWorkflow)
+
+	// sent without a Content-Length, so the limit only trips while the body is bound
+	body := `{"user_id": "user-1", "query": "` + strings.Repeat("rates ", 100) + `"}`
+	request := httptest.NewRequest(http.MethodPost, "/workflow", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.ContentLength = -1
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge || !strings.Contains(recorder.Body.String(), "Request body too large") {
+		t.Errorf("got %d %s, want 413", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestListPersonasIsFilteredPerTenant(t *testing.T) {
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	cfg := config.Config{}
+	cfg.Workflow.MaxConcurrency = 1
+	cfg.Tenants = config.TenantConfig{Header: "X-Tenant-ID", DefaultTenant: "default", Personas: map[string]config.TenantPersonas{
+		"corp": {Allowed: []string{"calm-anchor", "ai-analyst"}, Default: "calm-anchor"},
+	}}
+	handler := NewWorkflowHandler(services.NewOrchestrator(nil, nil, nil, nil, nil, nil, nil, cfg, log), log)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.TenantMiddleware(cfg.Tenants))
+	router.GET("/personas", handler.ListPersonas)
+
+	listPersonas := func(tenant string) (personas []string, defaultPersona string) {
+		t.Helper()
+		request := httptest.NewRequest(http.MethodGet, "/personas", nil)
+		if tenant != "" {
+			request.Header.Set("X-Tenant-ID", tenant)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		var body struct {
+			Data struct {
+				Personas       []string `json:"personas"`
+				DefaultPersona string   `json:"default_persona"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode personas: %v", err)
+		}
+		return body.Data.Personas, body.Data.DefaultPersona
+	}
+
+	if personas, defaultPersona := listPersonas("corp"); !slices.Equal(personas, []string{"calm-anchor", "ai-analyst"}) || defaultPersona != "calm-anchor" {
+		t.Errorf("corp personas = %v default %s, want its allowlist and calm-anchor", personas, defaultPersona)
+	}
+	if personas, defaultPersona := listPersonas(""); !slices.Equal(personas, models.AvailablePersonas) || defaultPersona != models.DefaultPersona {
+		t.Errorf("default tenant personas = %v default %s, want every persona", personas, defaultPersona)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/redistest"
+	"Infiya-ai-pipeline/internal/services"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWorkflowSourcesArePaginated(t *testing.T) {
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	redis := redistest.NewServer(t)
+	redisService, err := services.NewRedisService(config.RedisConfig{StreamsURL: redis.URL(), MemoryURL: redis.URL(), DialTimeout: time.Second}, log)
+	if err != nil {
+		t.Fatalf("NewRedisService() error = %v", err)
+	}
+	t.Cleanup(func() { redisService.Close() })
+
+	cfg := config.Config{}
+	cfg.Workflow.SourcesPageSize = 2
+	cfg.Workflow.SourcesMaxPageSize = 3
+	cfg.UserIDs = config.UserIDConfig{MaxLength: 128, AllowedPunctuation: "-_.@"}
+	orchestrator := services.NewOrchestrator(redisService, nil, nil, nil, nil, nil, nil, cfg, log)
+
+	stored := &models.WorkflowSources{WorkflowID: "workflow-1", UserID: "user-1", StoredAt: time.Now()}
+	for i := 1; i <= 5; i++ {
+		stored.Sources = append(stored.Sources, models.ResponseSource{Type: "article", Title: fmt.Sprintf("Story %d", i), URL: fmt.Sprintf("https://news.example.com/%d", i)})
+	}
+	if err := redisService.StoreWorkflowSources(context.Background(), stored, time.Hour); err != nil {
+		t.Fatalf("StoreWorkflowSources() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := NewWorkflowHandler(orchestrator, log)
+	routers := map[string]*gin.Engine{}
+	for _, tenant := range []string{"", "acme"} {
+		router := gin.New()
+		router.Use(func(ctx *gin.Context) { ctx.Set("tenant_id", tenant) })
+		router.GET("/workflows/:id/sources", handler.GetWorkflowSources)
+		routers[tenant] = router
+	}
+	getSources := func(tenant, path string) (*httptest.ResponseRecorder, models.SourcesPage) {
+		recorder := httptest.NewRecorder()
+		routers[tenant].ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var body struct {
+			Data models.SourcesPage `json:"data"`
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &body)
+		return recorder, body.Data
+	}
+
+	pages := []struct {
+		query     string
+		wantPage  int
+		wantSize  int
+		wantFirst string
+		wantCount int
+	}{
+		{query: "", wantPage: 1, wantSize: 2, wantFirst: "Story 1", wantCount: 2},
+		{query: "?page=3", wantPage: 3, wantSize: 2, wantFirst: "Story 5", wantCount: 1},
+		{query: "?page=2&page_size=10", wantPage: 2, wantSize: 3, wantFirst: "Story 4", wantCount: 2},
+		{query: "?page=9", wantPage: 9, wantSize: 2},
+	}
+	for _, tt := range pages {
+		recorder, page := getSources("", "/workflows/workflow-1/sources"+tt.query)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("%q got %d %s, want 200", tt.query, recorder.Code, recorder.Body.String())
+		}
+		if page.Page != tt.wantPage || page.PageSize != tt.wantSize || page.Total != 5 || len(page.Sources) != tt.wantCount {
+			t.Errorf("%q page = %d size %d total %d with %d sources, want %d size %d total 5 with %d",
+				tt.query, page.Page, page.PageSize, page.Total, len(page.Sources), tt.wantPage, tt.wantSize, tt.wantCount)
+		}
+		if tt.wantCount > 0 && page.Sources[0].Title != tt.wantFirst {
+			t.Errorf("%q starts at %q, want %q", tt.query, page.Sources[0].Title, tt.wantFirst)
+		}
+	}
+	if _, page := getSources("", "/workflows/workflow-1/sources"); page.TotalPages != 3 {
+		t.Errorf("total pages = %d, want 3", page.TotalPages)
+	}
+
+	refused := []struct {
+		name   string
+		tenant string
+		path   string
+		want   int
+	}{
+		{name: "page zero", path: "/workflows/workflow-1/sources?page=0", want: http.StatusBadRequest},
+		{name: "page size not a number", path: "/workflows/workflow-1/sources?page_size=all", want: http.StatusBadRequest},
+		{name: "another user", path: "/workflows/workflow-1/sources?user_id=user-2", want: http.StatusNotFound},
+		{name: "another tenant", tenant: "acme", path: "/workflows/workflow-1/sources", want: http.StatusNotFound},
+		{name: "unknown workflow", path: "/workflows/workflow-unknown/sources", want: http.StatusNotFound},
+	}
+	for _, tt := range refused {
+		if recorder, _ := getSources(tt.tenant, tt.path); recorder.Code != tt.want {
+			t.Errorf("%s got %d %s, want %d", tt.name, recorder.Code, recorder.Body.String(), tt.want)
+		}
+	}
+	if recorder, _ := getSources("", "/workflows/workflow-1/sources?user_id=user-1"); recorder.Code != http.StatusOK {
+		t.Errorf("owner got %d, want 200", recorder.Code)
+	}
+}
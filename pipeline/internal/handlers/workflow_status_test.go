@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/redistest"
+	"Infiya-ai-pipeline/internal/services"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWorkflowStatusFallsBackToThePersistedResult(t *testing.T) {
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	redis := redistest.NewServer(t)
+	redisService, err := services.NewRedisService(config.RedisConfig{StreamsURL: redis.URL(), MemoryURL: redis.URL(), DialTimeout: time.Second}, log)
+	if err != nil {
+		t.Fatalf("NewRedisService() error = %v", err)
+	}
+	t.Cleanup(func() { redisService.Close() })
+
+	cfg := config.Config{}
+	cfg.UserIDs = config.UserIDConfig{MaxLength: 128, AllowedPunctuation: "-_.@"}
+	orchestrator := services.NewOrchestrator(redisService, nil, nil, nil, nil, nil, nil, cfg, log)
+	resultStore, err := services.NewFileResultStore(t.TempDir(), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileResultStore() error = %v", err)
+	}
+	orchestrator.SetResultStore(resultStore)
+
+	// the workflow finished yesterday, its state has long expired
+	err = resultStore.PutResult(context.Background(), &models.WorkflowResult{
+		WorkflowID: "workflow-yesterday", UserID: "user-1", TenantID: "acme", Status: "completed", Response: "The budget passed.", StoredAt: time.Now(),
+		Sources: []models.ResponseSource{{Type: "article", Title: "Budget passes", URL: "https://news.example.com/budget"}},
+	})
+	if err != nil {
+		t.Fatalf("PutResult() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	handler := NewWorkflowHandler(orchestrator, log)
+	getStatusAs := func(tenant, path string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(ctx *gin.Context) { ctx.Set("tenant_id", tenant) })
+		router.GET("/workflows/:id/status", handler.GetWorkflowStatus)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+	getStatus := func(workflowID string) *httptest.ResponseRecorder {
+		return getStatusAs("acme", "/workflows/"+workflowID+"/status")
+	}
+
+	recorder := getStatus("workflow-yesterday")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("got %d %s, want 200 from the persisted result", recorder.Code, recorder.Body.String())
+	}
+	var body struct {
+		Data models.WorkflowStatusResponse `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if status := body.Data; !status.Persisted || status.Status != "completed" || status.Response != "The budget passed." || len(status.Sources) != 1 {
+		t.Errorf("status = %+v, want the persisted completed result with its source", status)
+	}
+
+	if recorder := getStatus("workflow-unknown"); recorder.Code != http.StatusNotFound {
+		t.Errorf("unknown workflow got %d, want 404", recorder.Code)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		tenant string
+		path   string
+		want   int
+	}{
+		{name: "the owner", tenant: "acme", path: "/workflows/workflow-yesterday/status?user_id=user-1", want: http.StatusOK},
+		{name: "another tenant", tenant: "globex", path: "/workflows/workflow-yesterday/status", want: http.StatusNotFound},
+		{name: "no tenant", path: "/workflows/workflow-yesterday/status", want: http.StatusNotFound},
+		{name: "another user", tenant: "acme", path: "/workflows/workflow-yesterday/status?user_id=user-2", want: http.StatusNotFound},
+	} {
+		if recorder := getStatusAs(tt.tenant, tt.path); recorder.Code != tt.want {
+			t.Errorf("%s got %d, want %d", tt.name, recorder.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// each stream line gets this long to be written, the stream as a whole outlives the server's write timeout
+const streamLineWriteWait = 10 * time.Second
+
+// jsonLinesMediaTypes are the Accept values that ask for the streamed JSON lines response
+var jsonLinesMediaTypes = []string{"application/x-ndjson", "application/jsonl"}
+
+func acceptsJSONLines(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		for _, jsonLines := range jsonLinesMediaTypes {
+			if mediaType == jsonLines {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rejectedByAdmission reports whether admission control turned the workflow away before it started
+func rejectedByAdmission(err error) bool {
+	return errors.Is(err, services.ErrWorkflowQueueFull) || errors.Is(err, services.ErrWorkflowQueueTimeout) ||
+		errors.Is(err, services.ErrWorkflowDraining)
+}
+
+// admissionMessage tells a shutdown apart from a full server
+func admissionMessage(err error) string {
+	if errors.Is(err, services.ErrWorkflowDraining) {
+		return "Service is shutting down"
+	}
+	return "Service is at capacity"
+}
+
+// respondAtCapacity answers a workflow refused by admission control
+func respondAtCapacity(ctx *gin.Context, err error) {
+	var appErr *models.AppError
+	if errors.As(err, &appErr) && appErr.RetryAfter != nil {
+		ctx.Header("Retry-After", strconv.Itoa(int(appErr.RetryAfter.Seconds())))
+	}
+	ctx.JSON(http.StatusServiceUnavailable, models.APIResponse{
+		Success: false,
+		Message: admissionMessage(err),
+		Error:   err.Error(),
+	})
+}
+
+// streamWorkflow runs the workflow and writes its typed events as JSON lines while it runs: intent and keywords,
+// the sources once selected, the summary chunks and the sources again, then a final "complete" line carrying the
+// body the plain endpoint returns, or an "error" line. Nothing is written before the first event, so a workflow
+// refused by admission control still gets its 503.
+func (workflowHandler *WorkflowHandler) streamWorkflow(ctx *gin.Context, runCtx context.Context, request *models.WorkflowRequest, startTime time.Time) {
+	events, unsubscribe := workflowHandler.orchestrator.SubscribeWorkflowEvents(request.WorkflowID)
+	defer unsubscribe()
+
+	type outcome struct {
+		response *models.WorkflowResponse
+		err      error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		response, err := workflowHandler.orchestrator.ExecuteWorkflow(runCtx, request)
+		done <- outcome{response: response, err: err}
+	}()
+
+	started := false
+	encoder := json.NewEncoder(ctx.Writer)
+	responseController := http.NewResponseController(ctx.Writer)
+	writeLine := func(event string, payload any) {
+		if !started {
+			ctx.Header("Content-Type", "application/x-ndjson")
+			ctx.Header("Cache-Control", "no-cache")
+			ctx.Header("X-Accel-Buffering", "no")
+			ctx.Status(http.StatusOK)
+			started = true
+		}
+		if err := responseController.SetWriteDeadline(time.Now().Add(streamLineWriteWait)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			workflowHandler.logger.WithError(err).Warn("Failed to extend the workflow stream write deadline", "workflow_id", request.WorkflowID)
+		}
+		if err := encoder.Encode(models.StreamLine{Event: event, Payload: payload, Timestamp: time.Now()}); err != nil {
+			workflowHandler.logger.WithError(err).Warn("Failed to write workflow stream line", "workflow_id", request.WorkflowID)
+			return
+		}
+		ctx.Writer.Flush()
+	}
+
+	writeEvent := func(update *models.AgentUpdate) {
+		// the final line carries the whole response instead
+		if update.Event != models.EventComplete {
+			writeLine(string(update.Event), update.Data["payload"])
+		}
+	}
+
+	for {
+		select {
+		case update := <-events:
+			writeEvent(update)
+
+		case result := <-done:
+			// every event was handed over before the workflow returned, the last ones may still be buffered
+			for drained := false; !drained; {
+				select {
+				case update := <-events:
+					writeEvent(update)
+				default:
+					drained = true
+				}
+			}
+
+			switch {
+			case rejectedByAdmission(result.err):
+				workflowHandler.logger.Warn("Workflow rejected by admission control", "workflow_id", request.WorkflowID, "reason", result.err.Error())
+				if !started {
+					respondAtCapacity(ctx, result.err)
+					return
+				}
+				writeLine("error", models.APIResponse{Success: false, Message: admissionMessage(result.err), Error: result.err.Error()})
+			case result.err != nil:
+				workflowHandler.logger.WithError(result.err).Error("Workflow Execution Failed", "workflow_id", request.WorkflowID, "duration", time.Since(startTime))
+				writeLine("error", models.APIResponse{Success: false, Message: "Workflow Execution failed", Error: result.err.Error()})
+			case result.response.Status == string(models.WorkflowStatusTimeout):
+				workflowHandler.logger.Warn("Workflow timed out", "workflow_id", request.WorkflowID, "duration", time.Since(startTime))
+				writeLine("error", models.APIResponse{Success: false, Message: "Workflow timed out", Data: result.response})
+			default:
+				workflowHandler.logger.Info("Workflow completed successfully",
+					"workflow_id", request.WorkflowID,
+					"duration", time.Since(startTime),
+					"streamed", true)
+				writeLine(string(models.EventComplete), models.APIResponse{Success: true, Message: "Workflow completed successfully", Data: result.response})
+			}
+			return
+		}
+	}
+}
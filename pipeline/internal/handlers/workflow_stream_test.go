@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/services"
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAcceptsJSONLines(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "application/x-ndjson", want: true},
+		{accept: "application/json, application/jsonl;q=0.9", want: true},
+		{accept: "Application/X-NDJSON", want: true},
+		{accept: "application/json", want: false},
+		{accept: "text/event-stream", want: false},
+		{accept: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := acceptsJSONLines(tt.accept); got != tt.want {
+			t.Errorf("acceptsJSONLines(%q) = %t, want %t", tt.accept, got, tt.want)
+		}
+	}
+}
+
+// newChitchatServer serves the execute endpoint over a workflow whose Gemini API classifies every query as chitchat
+// and answers it with reply, redis is unreachable so the workflow runs statelessly
+func newChitchatServer(t *testing.T, chunkedResponses bool, reply string) string {
+	t.Helper()
+	return newSlowChitchatServer(t, chunkedResponses, reply, 0, 0)
+}
+
+// newSlowChitchatServer is newChitchatServer with every Gemini call taking geminiDelay, served with the given
+// write timeout like the production server
+func newSlowChitchatServer(t *testing.T, chunkedResponses bool, reply string, geminiDelay, writeTimeout time.Duration) string {
+	t.Helper()
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	gemini := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		time.Sleep(geminiDelay)
+		text := reply
+		if strings.Contains(string(body), "intent classifier") {
+			text = `{"intent": "CHITCHAT", "confidence": 0.95, "reasoning": "greeting"}`
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"candidates": []map[string]any{{
+				"content":      map[string]any{"role": "model", "parts": []map[string]string{{"text": text}}},
+				"finishReason": "STOP",
+			}},
+		})
+	}))
+	t.Cleanup(gemini.Close)
+	t.Setenv("GOOGLE_GEMINI_BASE_URL", gemini.URL)
+
+	cfg := config.Config{}
+	cfg.Gemini = config.GeminiConfig{APIKey: "test-key", Model: "gemini-test", MaxRetries: 1, Timeout: time.Minute, MaxConcurrency: 4}
+	cfg.Workflow = config.WorkflowConfig{MaxConcurrency: 4, Deadline: time.Minute, ChunkedResponses: chunkedResponses}
+	cfg.UserIDs = config.UserIDConfig{MaxLength: 128, AllowedPunctuation: "-_.@"}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	listener.Close()
+	cfg.Redis = config.RedisConfig{
+		StreamsURL: "redis://" + listener.Addr().String(), MemoryURL: "redis://" + listener.Addr().String(),
+		AllowStateless: true, DialTimeout: 100 * time.Millisecond,
+	}
+	redisService, err := services.NewRedisService(cfg.Redis, log)
+	if err != nil {
+		t.Fatalf("NewRedisService() error = %v", err)
+	}
+	geminiService, err := services.NewGeminiService(cfg.Gemini, log)
+	if err != nil {
+		t.Fatalf("NewGeminiService() error = %v", err)
+	}
+	orchestrator := services.NewOrchestrator(redisService, geminiService, nil, nil, nil, nil, nil, cfg, log)
+	orchestrator.MarkReady()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/workflow", NewWorkflowHandler(orchestrator, log).ExecuteWorkflow)
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = writeTimeout
+	server.Start()
+	t.Cleanup(server.Close)
+	return server.URL + "/workflow"
+}
+
+func postWorkflow(t *testing.T, url, accept string) *http.Response {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(`{"user_id": "user-1", "query": "hello there"}`))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", accept)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("POST /workflow error = %v", err)
+	}
+	t.Cleanup(func() { response.Body.Close() })
+	return response
+}
+
+func TestExecuteWorkflowStreamsJSONLines(t *testing.T) {
+	url := newChitchatServer(t, true, "Hi! Ask me about the news whenever you like.")
+
+	response := postWorkflow(t, url, "application/x-ndjson")
+
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("got %d %s, want a 200 ndjson stream", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	if !slices.Contains(response.TransferEncoding, "chunked") {
+		t.Errorf("transfer encoding = %v, want a chunked body", response.TransferEncoding)
+	}
+
+	var events []string
+	var summary strings.Builder
+	var final struct {
+		Event   string `json:"event"`
+		Payload struct {
+			Success bool                    `json:"success"`
+			Data    models.WorkflowResponse `json:"data"`
+		} `json:"payload"`
+	}
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		var line struct {
+			Event   string          `json:"event"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		events = append(events, line.Event)
+		switch line.Event {
+		case string(models.EventSummaryChunk):
+			var chunk models.SummaryChunkPayload
+			json.Unmarshal(line.Payload, &chunk)
+			summary.WriteString(chunk.Text)
+		case string(models.EventComplete):
+			json.Unmarshal(scanner.Bytes(), &final)
+		}
+	}
+
+	want := []string{string(models.EventIntentClassified), string(models.EventSummaryChunk), string(models.EventComplete)}
+	if !slices.Equal(events, want) {
+		t.Fatalf("stream carried %v, want %v", events, want)
+	}
+	if summary.String() != "Hi! Ask me about the news whenever you like." {
+		t.Errorf("summary chunks = %q, want the reply", summary.String())
+	}
+	if !final.Payload.Success || final.Payload.Data.Message != summary.String() || final.Payload.Data.WorkflowID == "" {
+		t.Errorf("final line = %+v, want the complete workflow response", final.Payload)
+	}
+}
+
+func TestExecuteWorkflowStreamsPastTheServerWriteTimeout(t *testing.T) {
+	// the workflow takes longer than the server may spend writing a response
+	url := newSlowChitchatServer(t, true, "Hi! Ask me about the news whenever you like.", 300*time.Millisecond, 200*time.Millisecond)
+
+	response := postWorkflow(t, url, "application/x-ndjson")
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("got %d, want a 200 stream", response.StatusCode)
+	}
+
+	var events []string
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		var line struct {
+			Event string `json:"event"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		events = append(events, line.Event)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("stream ended with %v after %v", err, events)
+	}
+	if len(events) == 0 || events[len(events)-1] != string(models.EventComplete) {
+		t.Errorf("stream carried %v, want it to end with the complete line", events)
+	}
+}
+
+func TestExecuteWorkflowAnswersPastTheServerWriteTimeout(t *testing.T) {
+	url := newSlowChitchatServer(t, false, "Hi! Ask me about the news whenever you like.", 300*time.Millisecond, 200*time.Millisecond)
+
+	response := postWorkflow(t, url, "application/json")
+
+	var body struct {
+		Success bool                    `json:"success"`
+		Data    models.WorkflowResponse `json:"data"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatalf("body is not a JSON response: %v", err)
+	}
+	if response.StatusCode != http.StatusOK || !body.Success || body.Data.Message == "" {
+		t.Errorf("got %d %+v, want the workflow response", response.StatusCode, body)
+	}
+}
+
+func TestExecuteWorkflowStreamsOnlyWhenAsked(t *testing.T) {
+	tests := []struct {
+		name             string
+		chunkedResponses bool
+		accept           string
+	}{
+		{name: "plain accept", chunkedResponses: true, accept: "application/json"},
+		{name: "streaming disabled", chunkedResponses: false, accept: "application/x-ndjson"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := newChitchatServer(t, tt.chunkedResponses, "Hi! Ask me about the news whenever you like.")
+
+			response := postWorkflow(t, url, tt.accept)
+
+			var body struct {
+				Success bool                    `json:"success"`
+				Data    models.WorkflowResponse `json:"data"`
+			}
+			if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+				t.Fatalf("body is not a single JSON response: %v", err)
+			}
+			if response.StatusCode != http.StatusOK || !body.Success || body.Data.Message == "" {
+				t.Errorf("got %d %+v, want the plain workflow response", response.StatusCode, body)
+			}
+			if contentType := response.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+				t.Errorf("content type = %s, want application/json", contentType)
+			}
+		})
+	}
+}
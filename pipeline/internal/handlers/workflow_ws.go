@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/middleware"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/services"
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = (wsPongWait * 9) / 10
+	// updates queued for a slow client beyond this are dropped, terminal updates are always delivered
+	wsSendBuffer = 64
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || middleware.IsAllowedOrigin(origin)
+	},
+}
+
+// StreamWorkflowUpdates upgrades to a websocket and relays the workflow's agent updates until it finishes
+func (workflowHandler *WorkflowHandler) StreamWorkflowUpdates(ctx *gin.Context) {
+	workflowID := ctx.Param("id")
+	userID := ctx.Query("user_id")
+	if userID == "" {
+		userID = ctx.GetHeader("X-User-ID")
+	}
+
+	if workflowID == "" || userID == "" {
+		ctx.JSON(http.StatusBadRequest, models.APIResponse{
+			Success: false,
+			Message: "Workflow ID and user_id are required",
+		})
+		return
+	}
+
+	userID, ok := canonicalUserID(ctx, workflowHandler.orchestrator, userID)
+	if !ok {
+		return
+	}
+
+	conn, err := wsUpgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// the upgrader has already written the error response
+		workflowHandler.logger.WithError(err).Warn("Websocket upgrade failed", "workflow_id", workflowID)
+		return
+	}
+	defer conn.Close()
+
+	relayCtx, cancel := context.WithCancel(ctx.Request.Context())
+	defer cancel()
+
+	send := make(chan *models.AgentUpdate, wsSendBuffer)
+	var dropped atomic.Int64
+
+	// the read pump only exists to process pongs and notice the client going away
+	go func() {
+		defer cancel()
+		conn.SetReadLimit(512)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(send)
+		err := workflowHandler.orchestrator.RelayWorkflowUpdates(relayCtx, userID, workflowID, func(update *models.AgentUpdate) error {
+			if services.IsTerminalUpdate(update) {
+				select {
+				case send <- update:
+				case <-relayCtx.Done():
+					return relayCtx.Err()
+				}
+				return nil
+			}
+
+			select {
+			case send <- update:
+			default:
+				dropped.Add(1)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			workflowHandler.logger.WithError(err).Warn("Workflow update relay stopped", "workflow_id", workflowID)
+		}
+	}()
+
+	ticker := time.NewTicker(wsPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case update, ok := <-send:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "workflow finished"))
+				workflowHandler.logger.Debug("Websocket relay closed", "workflow_id", workflowID, "dropped_updates", dropped.Load())
+				return
+			}
+			if err := conn.WriteJSON(update); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-relayCtx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/redistest"
+	"Infiya-ai-pipeline/internal/services"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// newWebsocketTestServer serves the websocket route over an orchestrator whose redis is an in-process fake
+func newWebsocketTestServer(t *testing.T) (*services.RedisService, string) {
+	t.Helper()
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	redis := redistest.NewServer(t)
+	redisService, err := services.NewRedisService(config.RedisConfig{StreamsURL: redis.URL(), MemoryURL: redis.URL(), DialTimeout: time.Second}, log)
+	if err != nil {
+		t.Fatalf("NewRedisService() error = %v", err)
+	}
+	t.Cleanup(func() { redisService.Close() })
+
+	cfg := config.Config{}
+	cfg.UserIDs = config.UserIDConfig{MaxLength: 128, AllowedPunctuation: "-_.@"}
+	orchestrator := services.NewOrchestrator(redisService, nil, nil, nil, nil, nil, nil, cfg, log)
+	handler := NewWorkflowHandler(orchestrator, log)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/workflows/:id/ws", handler.StreamWorkflowUpdates)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return redisService, "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/workflows/wf-1/ws?user_id=user-1"
+}
+
+func TestWebsocketRelaysTheWorkflowsUpdatesInOrder(t *testing.T) {
+	redisService, wsURL := newWebsocketTestServer(t)
+	ctx := context.Background()
+	publish := func(workflowID, agent string) {
+		t.Helper()
+		update := &models.AgentUpdate{WorkflowID: workflowID, AgentName: agent, Status: models.AgentStatusProcessing, Timestamp: time.Now()}
+		if err := redisService.PublishAgentUpdate(ctx, "user-1", update); err != nil {
+			t.Fatalf("PublishAgentUpdate() error = %v", err)
+		}
+	}
+
+	// updates published before the client connects are replayed
+	publish("wf-1", "classifier")
+	publish("wf-other", "classifier")
+	publish("wf-1", "keyword_extractor")
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	publish("wf-1", "summarizer")
+	publish("wf-1", string(models.UpdateTypeWorkflowCompleted))
+
+	var agents []string
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		var update models.AgentUpdate
+		if err := conn.ReadJSON(&update); err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseNormalClosure {
+				t.Fatalf("ReadJSON() error = %v, want a normal close after the workflow finished", err)
+			}
+			break
+		}
+		if update.WorkflowID != "wf-1" {
+			t.Errorf("received an update for %s", update.WorkflowID)
+		}
+		agents = append(agents, update.AgentName)
+	}
+
+	want := []string{"classifier", "keyword_extractor", "summarizer", string(models.UpdateTypeWorkflowCompleted)}
+	if !slices.Equal(agents, want) {
+		t.Errorf("relayed agents = %v, want %v", agents, want)
+	}
+}
+
+func TestWebsocketRejectsAMissingUserID(t *testing.T) {
+	_, wsURL := newWebsocketTestServer(t)
+
+	_, response, err := websocket.DefaultDialer.Dial(strings.TrimSuffix(wsURL, "?user_id=user-1"), nil)
+	if err == nil || response == nil || response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Dial() without a user id = %v, want a 400 before upgrading", err)
+	}
+}
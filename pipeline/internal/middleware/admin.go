@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuthMiddleware guards admin routes with a shared key sent as X-Admin-Key or a bearer token,
+// admin routes stay closed when no key is configured
+func AdminAuthMiddleware(adminConfig config.AdminConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if adminConfig.APIKey == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Message: "Admin API is disabled",
+			})
+			return
+		}
+
+		if !hasAdminKey(c, adminConfig) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, models.APIResponse{
+				Success: false,
+				Message: "Unauthorized",
+			})
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// AdminFlagMiddleware stores under "is_admin" whether the caller sent the admin key, so public routes can
+// reserve options like workflow recording for operators
+func AdminFlagMiddleware(adminConfig config.AdminConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		c.Set("is_admin", adminConfig.APIKey != "" && hasAdminKey(c, adminConfig))
+		c.Next()
+	})
+}
+
+func hasAdminKey(c *gin.Context, adminConfig config.AdminConfig) bool {
+	key := c.GetHeader("X-Admin-Key")
+	if key == "" {
+		key = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(key), []byte(adminConfig.APIKey)) == 1
+}
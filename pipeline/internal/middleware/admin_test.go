@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminFlagMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiKey  string
+		headers map[string]string
+		want    bool
+	}{
+		{name: "no key sent", apiKey: "secret", want: false},
+		{name: "admin header", apiKey: "secret", headers: map[string]string{"X-Admin-Key": "secret"}, want: true},
+		{name: "bearer token", apiKey: "secret", headers: map[string]string{"Authorization": "Bearer secret"}, want: true},
+		{name: "wrong key", apiKey: "secret", headers: map[string]string{"X-Admin-Key": "guess"}, want: false},
+		{name: "admin api disabled", apiKey: "", headers: map[string]string{"X-Admin-Key": ""}, want: false},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var isAdmin bool
+			router := gin.New()
+			router.Use(AdminFlagMiddleware(config.AdminConfig{APIKey: tt.apiKey}))
+			router.GET("/", func(c *gin.Context) { isAdmin = c.GetBool("is_admin") })
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tt.headers {
+				request.Header.Set(key, value)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), request)
+
+			if isAdmin != tt.want {
+				t.Errorf("is_admin = %v, want %v", isAdmin, tt.want)
+			}
+		})
+	}
+}
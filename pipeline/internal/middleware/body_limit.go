@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitMiddleware rejects request bodies over maxBytes with 413. A declared Content-Length is refused
+// up front, anything else is cut off by http.MaxBytesReader while the handler reads it.
+func BodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, models.APIResponse{
+				Success: false,
+				Message: "Request body too large",
+				Error:   fmt.Sprintf("request body exceeds %d bytes", maxBytes),
+			})
+			return
+		}
+
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	})
+}
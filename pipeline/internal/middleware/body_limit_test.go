@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		chunked     bool
+		wantStatus  int
+		wantHandled bool
+		wantReadErr bool
+	}{
+		{name: "within the limit", body: strings.Repeat("a", 64), wantStatus: http.StatusOK, wantHandled: true},
+		{name: "declared length over the limit", body: strings.Repeat("a", 65), wantStatus: http.StatusRequestEntityTooLarge},
+		// no Content-Length, the handler finds out while reading
+		{name: "chunked body over the limit", body: strings.Repeat("a", 65), chunked: true,
+			wantStatus: http.StatusOK, wantHandled: true, wantReadErr: true},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled bool
+			var readErr error
+			router := gin.New()
+			router.Use(BodyLimitMiddleware(64))
+			router.POST("/", func(c *gin.Context) {
+				handled = true
+				_, readErr = io.ReadAll(c.Request.Body)
+			})
+
+			request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.chunked {
+				request.ContentLength = -1
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus || handled != tt.wantHandled {
+				t.Errorf("got %d, handled %t, want %d, handled %t", recorder.Code, handled, tt.wantStatus, tt.wantHandled)
+			}
+			var maxBytesErr *http.MaxBytesError
+			if got := errors.As(readErr, &maxBytesErr); got != tt.wantReadErr {
+				t.Errorf("read error = %v, want a MaxBytesError %t", readErr, tt.wantReadErr)
+			}
+		})
+	}
+}
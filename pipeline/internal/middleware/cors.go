@@ -5,21 +5,26 @@ import (
 	"net/http"
 )
 
+var allowedOrigins = map[string]bool{
+	"http://localhost:8000": true,
+	"http://localhost:5173": true,
+}
+
+// IsAllowedOrigin applies the CORS origin policy, also used to vet websocket upgrades
+func IsAllowedOrigin(origin string) bool {
+	return allowedOrigins[origin] || gin.Mode() == gin.DebugMode
+}
+
 func CORSMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 
-		allowedOrigins := map[string]bool{
-			"http://localhost:8000": true,
-			"http://localhost:5173": true,
-		}
-
-		if allowedOrigins[origin] || gin.Mode() == gin.DebugMode {
+		if IsAllowedOrigin(origin) {
 			c.Header("Access-Control-Allow-Origin", origin)
 		}
 
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Requested-With, X-User-ID")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Requested-With, X-User-ID, X-Tenant-ID")
 		c.Header("Access-Control-Expose-Headers", "Content-Length, X-Request-ID")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "86400") // 24 hours
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TenantMiddleware stores the calling tenant in the gin context under "tenant_id", tenants that are not
+// configured are refused since any caller can set the header
+func TenantMiddleware(tenantConfig config.TenantConfig) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		tenantID := strings.TrimSpace(c.GetHeader(tenantConfig.Header))
+		if tenantID == "" {
+			tenantID = tenantConfig.DefaultTenant
+		}
+
+		if !tenantConfig.IsKnown(tenantID) {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.APIResponse{
+				Success: false,
+				Message: "Unknown tenant",
+			})
+			return
+		}
+
+		c.Set("tenant_id", tenantID)
+		c.Next()
+	})
+}
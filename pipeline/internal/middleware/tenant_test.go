@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	tenantConfig := config.TenantConfig{
+		Header:             "X-Tenant-ID",
+		DefaultTenant:      "default",
+		Known:              []string{"acme"},
+		Personas:           map[string]config.TenantPersonas{"corp": {Allowed: []string{"calm-anchor"}}},
+		CustomInstructions: map[string]string{"globex": "Be terse."},
+	}
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "no header uses the default tenant", wantStatus: http.StatusOK, wantTenant: "default"},
+		{name: "listed tenant", header: "acme", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "tenant with personas", header: "corp", wantStatus: http.StatusOK, wantTenant: "corp"},
+		{name: "tenant with custom instructions", header: " globex ", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "unknown tenant is refused", header: "initech", wantStatus: http.StatusForbidden},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tenantID string
+			router := gin.New()
+			router.Use(TenantMiddleware(tenantConfig))
+			router.GET("/", func(c *gin.Context) { tenantID = c.GetString("tenant_id") })
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				request.Header.Set("X-Tenant-ID", tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus || tenantID != tt.wantTenant {
+				t.Errorf("status = %d tenant = %q, want %d and %q", recorder.Code, tenantID, tt.wantStatus, tt.wantTenant)
+			}
+		})
+	}
+}
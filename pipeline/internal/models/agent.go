@@ -6,6 +6,7 @@ type AgentUpdate struct {
 	WorkflowID     string                 `json:"workflow_id"`
 	RequestID      string                 `json:"request_id"`
 	AgentName      string                 `json:"agent_name"`
+	Event          EventType              `json:"event,omitempty"` // set on typed events, the payload is in Data["payload"]
 	Status         AgentStatus            `json:"status"`
 	Message        string                 `json:"message"`
 	Progress       float64                `json:"progress"`
@@ -52,6 +53,7 @@ const (
 	UpdateTypeAssistantResponse UpdateType = "assistant_response"
 	UpdateTypeWorkflowCompleted UpdateType = "workflow_completed"
 	UpdateTypeWorkflowError     UpdateType = "workflow_error"
+	UpdateTypeWorkflowTimeout   UpdateType = "workflow_timeout"
 	UpdateTypeProgress          UpdateType = "progress"
 )
 
@@ -105,6 +107,21 @@ type AgentConfig struct {
 	DependsOn      []string      `json:"depends_on"`
 	RequiredInputs []string      `json:"required_inputs"`
 	Outputs        []string      `json:"outputs"`
+	// Gemini model the agent generates with, empty uses the globally configured model
+	Model string `json:"model,omitempty"`
+}
+
+// ApplyAgentModels sets the model of each agent named in agentModels, adding agents without a default config
+func ApplyAgentModels(configs map[string]AgentConfig, agentModels map[string]string) map[string]AgentConfig {
+	for agent, model := range agentModels {
+		agentConfig, ok := configs[agent]
+		if !ok {
+			agentConfig = AgentConfig{Name: agent, Enabled: true}
+		}
+		agentConfig.Model = model
+		configs[agent] = agentConfig
+	}
+	return configs
 }
 
 func DefaultAgentConfigs() map[string]AgentConfig {
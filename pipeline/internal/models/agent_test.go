@@ -0,0 +1,21 @@
+package models
+
+import "testing"
+
+func TestApplyAgentModels(t *testing.T) {
+	configs := ApplyAgentModels(DefaultAgentConfigs(), map[string]string{"classifier": "gemini-lite", "headline": "gemini-lite"})
+
+	classifier := configs["classifier"]
+	if classifier.Model != "gemini-lite" || classifier.Timeout != DefaultAgentConfigs()["classifier"].Timeout {
+		t.Errorf("classifier = %+v, want its defaults with the lite model", classifier)
+	}
+	// agents without a default config are added so their calls still find the override
+	if headline := configs["headline"]; headline.Model != "gemini-lite" || !headline.Enabled {
+		t.Errorf("headline = %+v, want an enabled agent on the lite model", headline)
+	}
+	for name, agent := range configs {
+		if name != "classifier" && name != "headline" && agent.Model != "" {
+			t.Errorf("%s uses %s, want the global model", name, agent.Model)
+		}
+	}
+}
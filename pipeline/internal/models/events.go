@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// EventType names a typed workflow event. Clients that opt into typed events receive them on the same stream
+// as the agent updates, with the event in AgentUpdate.Event and its payload under Data["payload"].
+type EventType string
+
+const (
+	EventIntentClassified  EventType = "intent_classified"
+	EventKeywordsExtracted EventType = "keywords_extracted"
+	EventArticlesFound     EventType = "articles_found"
+	EventSummaryChunk      EventType = "summary_chunk"
+	EventSources           EventType = "sources"
+	EventComplete          EventType = "complete"
+)
+
+type IntentClassifiedPayload struct {
+	Intent          string  `json:"intent"`
+	Confidence      float64 `json:"confidence"`
+	IsFollowUp      bool    `json:"is_follow_up"`
+	ReferencedTopic string  `json:"referenced_topic,omitempty"`
+}
+
+type KeywordsExtractedPayload struct {
+	Keywords      []string `json:"keywords"`
+	EnhancedQuery string   `json:"enhanced_query,omitempty"`
+}
+
+// ArticlesFoundPayload lists the relevant sources as soon as they are known, before the summary is written
+type ArticlesFoundPayload struct {
+	Articles int              `json:"articles"`
+	Videos   int              `json:"videos"`
+	Sources  []ResponseSource `json:"sources"`
+}
+
+// SummaryChunkPayload is one piece of the response, chunks arrive in Index order and Last marks the final one
+type SummaryChunkPayload struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+	Last  bool   `json:"last"`
+}
+
+type SourcesPayload struct {
+	Sources []ResponseSource `json:"sources"`
+}
+
+// StreamLine is one line of a JSON lines workflow response, Payload is the typed event payload or, for the
+// final "complete" or "error" line, the body the non streaming endpoint would have returned
+type StreamLine struct {
+	Event     string    `json:"event"`
+	Payload   any       `json:"payload,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type CompletePayload struct {
+	Status           string `json:"status"`
+	Intent           string `json:"intent"`
+	ProcessingTimeMs int64  `json:"processing_time_ms"`
+	SummaryChunks    int    `json:"summary_chunks"`
+}
@@ -9,6 +9,13 @@ type ExecuteWorkflowRequest struct {
 	Query           string          `json:"query"`
 	WorkflowID      string          `json:"workflow_id"`
 	UserPreferences UserPreferences `json:"user_preferences"`
+	Metadata        map[string]any  `json:"metadata,omitempty"`
+}
+
+// DebugClassifyRequest previews intent classification for a query against an optional synthetic history
+type DebugClassifyRequest struct {
+	Query               string                 `json:"query" binding:"required"`
+	ConversationHistory []ConversationExchange `json:"conversation_history,omitempty"`
 }
 
 type WorkflowStatusResponse struct {
@@ -21,6 +28,10 @@ type WorkflowStatusResponse struct {
 	TotalTime       float64                 `json:"total_time"`
 	ProcessingStats ProcessingStatsResponse `json:"processing_stats"`
 	AgentStats      []AgentStatsResponse    `json:"agent_stats"`
+	AgentExecutions []AgentExecution        `json:"agent_executions,omitempty"`
+	Sources         []ResponseSource        `json:"sources,omitempty"`
+	// served from the durable result store after the workflow state expired, agent details are not kept
+	Persisted bool `json:"persisted,omitempty"`
 }
 
 type ProcessingStatsResponse struct {
@@ -28,6 +39,8 @@ type ProcessingStatsResponse struct {
 	ArticlesFound    int `json:"articles_found"`
 	ArticlesFiltered int `json:"articles_filtered"`
 	EmbeddingsCount  int `json:"embeddings_count"`
+	// 0..1, news workflows only
+	QualityScore *float64 `json:"quality_score,omitempty"`
 }
 
 type AgentStatsResponse struct {
@@ -45,6 +58,44 @@ type APIResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
+// ActiveWorkflowInfo is an in-flight workflow as shown to operators
+type ActiveWorkflowInfo struct {
+	WorkflowID   string    `json:"workflow_id"`
+	UserID       string    `json:"user_id"`
+	TenantID     string    `json:"tenant_id,omitempty"`
+	Intent       string    `json:"intent,omitempty"`
+	CurrentAgent string    `json:"current_agent,omitempty"`
+	Status       string    `json:"status"`
+	StartTime    time.Time `json:"start_time"`
+	ElapsedMs    int64     `json:"elapsed_ms"`
+}
+
+type AdminWorkflowsResponse struct {
+	Count       int                  `json:"count"`
+	OldestAgeMs int64                `json:"oldest_age_ms"`
+	Workflows   []ActiveWorkflowInfo `json:"workflows"`
+	Timestamp   time.Time            `json:"timestamp"`
+}
+
+type CompactCollectionRequest struct {
+	// news_articles when empty
+	Collection string `json:"collection"`
+	// compacts the tenant's own collection when tenant collections are on
+	Tenant string `json:"tenant,omitempty"`
+	DryRun bool   `json:"dry_run"`
+}
+
+// CompactionReport counts what a collection compaction found and removed
+type CompactionReport struct {
+	Collection      string `json:"collection"`
+	Scanned         int    `json:"scanned"`
+	DuplicateGroups int    `json:"duplicate_groups"`
+	Duplicates      int    `json:"duplicates"`
+	Deleted         int    `json:"deleted"`
+	DryRun          bool   `json:"dry_run"`
+	DurationMs      int64  `json:"duration_ms"`
+}
+
 type HealthResponse struct {
 	Status    string            `json:"status"`
 	Timestamp time.Time         `json:"timestamp"`
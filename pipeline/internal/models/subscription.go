@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Subscription follows a story for a user, the poller reports articles it has not seen before
+type Subscription struct {
+	ID          string   `json:"id"`
+	UserID      string   `json:"user_id"`
+	TenantID    string   `json:"tenant_id,omitempty"`
+	Topic       string   `json:"topic"`
+	Keywords    []string `json:"keywords"`
+	CallbackURL string   `json:"callback_url,omitempty"`
+	// most recent article ids already reported, bounded so long running subscriptions stay small
+	SeenArticleIDs []string  `json:"seen_article_ids,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastCheckedAt  time.Time `json:"last_checked_at,omitempty"`
+	LastNotifiedAt time.Time `json:"last_notified_at,omitempty"`
+}
+
+type CreateSubscriptionRequest struct {
+	UserID      string   `json:"user_id"`
+	Topic       string   `json:"topic"`
+	Keywords    []string `json:"keywords,omitempty"`
+	CallbackURL string   `json:"callback_url,omitempty"`
+}
+
+// SubscriptionUpdate is sent to the user's update stream and the callback url when a story has new articles
+type SubscriptionUpdate struct {
+	SubscriptionID string           `json:"subscription_id"`
+	UserID         string           `json:"user_id"`
+	Topic          string           `json:"topic"`
+	Articles       []ResponseSource `json:"articles"`
+	Timestamp      time.Time        `json:"timestamp"`
+}
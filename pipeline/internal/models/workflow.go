@@ -1,27 +1,31 @@
 package models
 
 import (
+	"fmt"
 	"github.com/google/uuid"
+	"strings"
 	"time"
 )
 
 type YouTubeVideo struct {
-	ID             string    `json:"id"`
-	Title          string    `json:"title"`
-	Description    string    `json:"description"`
-	Transcript     string    `json:"transcript,omitempty"`
-	ChannelID      string    `json:"channel_id"`
-	Channel        string    `json:"channel"`
-	ThumbnailURL   string    `json:"thumbnail_url"`
-	PublishedAt    time.Time `json:"published_at"`
-	URL            string    `json:"url"`
-	Tags           []string  `json:"tags"`
-	ViewCount      string    `json:"view_count,omitempty"`
-	LikeCount      string    `json:"like_count,omitempty"`
-	CommentCount   string    `json:"comment_count,omitempty"`
-	Duration       string    `json:"duration,omitempty"`
-	SourceType     string    `json:"source_type"`
-	RelevancyScore float64   `json:"relevancy_score,omitempty"`
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	Transcript   string    `json:"transcript,omitempty"`
+	ChannelID    string    `json:"channel_id"`
+	Channel      string    `json:"channel"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	PublishedAt  time.Time `json:"published_at"`
+	URL          string    `json:"url"`
+	Tags         []string  `json:"tags"`
+	ViewCount    string    `json:"view_count,omitempty"`
+	LikeCount    string    `json:"like_count,omitempty"`
+	CommentCount string    `json:"comment_count,omitempty"`
+	Duration     string    `json:"duration,omitempty"` // ISO 8601 as YouTube reports it, e.g. PT5M30S
+	// parsed from Duration, 0 when unknown
+	DurationSeconds int     `json:"duration_seconds,omitempty"`
+	SourceType      string  `json:"source_type"`
+	RelevancyScore  float64 `json:"relevancy_score,omitempty"`
 }
 
 type WorkflowRequest struct {
@@ -31,21 +35,178 @@ type WorkflowRequest struct {
 	UserPreferences UserPreferences   `json:"user_preferences" binding:"required"`
 	Context         map[string]string `json:"context,omitempty"`
 	Metadata        map[string]any    `json:"metadata,omitempty"`
+	TenantID        string            `json:"tenant_id,omitempty"`
 }
 
 type WorkflowResponse struct {
-	WorkflowID string    `json:"workflow_id"`
-	Status     string    `json:"status"`
-	Message    string    `json:"message"`
-	RequestID  string    `json:"request_id"`
-	Timestamp  time.Time `json:"timestamp"`
-	TotalTime  *float64  `json:"total_time_ms,omitempty"`
+	WorkflowID string `json:"workflow_id"`
+	Status     string `json:"status"`
+	// one line headline for conversation lists and notifications, only populated when headlines are enabled
+	Title string `json:"title,omitempty"`
+	// the query repeated the previous one within the repeat window and Message is the previous answer
+	Repeat    bool      `json:"repeat,omitempty"`
+	Message   string    `json:"message"`
+	RequestID string    `json:"request_id"`
+	Timestamp time.Time `json:"timestamp"`
+	TotalTime *float64  `json:"total_time_ms,omitempty"`
+	// "answer" carries the written response, "articles_only" only the ranked Sources
+	ResponseType string `json:"response_type"`
+	// set when redis was unavailable, no conversation memory was used and Updates replaces the stream
+	Stateless       bool             `json:"stateless,omitempty"`
+	Updates         []*AgentUpdate   `json:"updates,omitempty"`
+	AgentExecutions []AgentExecution `json:"agent_executions,omitempty"`
+	// only populated when the request opts in with Metadata["explain"]
+	Explanation *WorkflowExplanation `json:"explanation,omitempty"`
+	// only populated when the request opts in with Metadata["include_intermediate"]
+	Intermediate *IntermediateOutputs `json:"intermediate,omitempty"`
+	// articles and videos the answer drew on, ordered for display
+	Sources []ResponseSource `json:"sources,omitempty"`
+	// set instead of Sources when sources are served by reference, the paginated list lives at this path
+	SourcesURL string `json:"sources_url,omitempty"`
+	// how many sources SourcesURL serves
+	SourcesCount int `json:"sources_count,omitempty"`
+	// numbered list the inline [n] markers of Message refer to, only populated when citations are on
+	Citations []ResponseCitation `json:"citations,omitempty"`
+	// content types ("articles", "videos") that could not be fetched, the answer is built from the others
+	UnavailableContent []string `json:"unavailable_content,omitempty"`
+	// top headlines of a favourite topic the chitchat touched on, only populated when trending suggestions are on
+	TrendingSuggestions []string `json:"trending_suggestions,omitempty"`
+	// only populated when the user opts in to sentiment analysis
+	Sentiment *SentimentSummary `json:"sentiment,omitempty"`
+	// only populated when cost reporting is enabled
+	EstimatedCostUSD *float64 `json:"estimated_cost_usd,omitempty"`
+	// why the answer carries a caution note, only populated when the uncertainty note is enabled and was added
+	Uncertainty []string `json:"uncertainty,omitempty"`
+}
+
+const (
+	ResponseTypeAnswer       = "answer"
+	ResponseTypeArticlesOnly = "articles_only"
+)
+
+type FreshnessTier string
+
+const (
+	FreshnessBreaking FreshnessTier = "breaking"
+	FreshnessToday    FreshnessTier = "today"
+	FreshnessThisWeek FreshnessTier = "this_week"
+	FreshnessOlder    FreshnessTier = "older"
+	FreshnessUndated  FreshnessTier = "undated"
+)
+
+// FreshnessRank orders tiers newest first, undated always sorts last
+var FreshnessRank = map[FreshnessTier]int{
+	FreshnessBreaking: 0,
+	FreshnessToday:    1,
+	FreshnessThisWeek: 2,
+	FreshnessOlder:    3,
+	FreshnessUndated:  4,
+}
+
+// publish times this far ahead of our clock are feed skew, anything further out is bad data
+const freshnessClockSkew = 5 * time.Minute
+
+// ComputeFreshnessTier labels a publish time relative to now. Zero times and times well in the future
+// are treated as undated rather than guessed at.
+func ComputeFreshnessTier(publishedAt, now time.Time) FreshnessTier {
+	if publishedAt.IsZero() || publishedAt.After(now.Add(freshnessClockSkew)) {
+		return FreshnessUndated
+	}
+
+	age := now.Sub(publishedAt)
+	switch {
+	case age < time.Hour:
+		return FreshnessBreaking
+	case age < 24*time.Hour:
+		return FreshnessToday
+	case age < 7*24*time.Hour:
+		return FreshnessThisWeek
+	default:
+		return FreshnessOlder
+	}
+}
+
+// ResponseCitation is one entry of the numbered source list, Index matches the [n] markers in the answer
+type ResponseCitation struct {
+	Index  int    `json:"index"`
+	Type   string `json:"type"` // "article" or "video"
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Source string `json:"source,omitempty"`
+}
+
+type ResponseSource struct {
+	Type           string            `json:"type"` // "article" or "video"
+	Title          string            `json:"title"`
+	URL            string            `json:"url"`
+	Source         string            `json:"source,omitempty"`
+	PublishedAt    *time.Time        `json:"published_at,omitempty"`
+	Freshness      FreshnessTier     `json:"freshness"`
+	RelevanceScore float64           `json:"relevance_score,omitempty"`
+	ArticleType    string            `json:"article_type,omitempty"` // articles only, "opinion" marks editorials and columns
+	Sentiment      *ArticleSentiment `json:"sentiment,omitempty"`
+	Duration       string            `json:"duration,omitempty"` // videos only, e.g. "5:30"
+	Reason         string            `json:"reason,omitempty"`   // why it was selected, when selection reasons are on
+}
+
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+// ArticleSentiment is the tone of one article, Score runs -1..1 and Magnitude 0..1 regardless of direction
+type ArticleSentiment struct {
+	Label     string  `json:"label"`
+	Score     float64 `json:"score"`
+	Magnitude float64 `json:"magnitude"`
+}
+
+// SentimentSummary aggregates the rated articles behind an answer
+type SentimentSummary struct {
+	Label        string            `json:"label"`
+	Score        float64           `json:"score"`
+	Magnitude    float64           `json:"magnitude"`
+	ArticleCount int               `json:"article_count"`
+	Counts       map[string]int    `json:"counts"`
+	BySource     []SourceSentiment `json:"by_source,omitempty"`
+}
+
+type SourceSentiment struct {
+	Source       string  `json:"source"`
+	Label        string  `json:"label"`
+	Score        float64 `json:"score"`
+	ArticleCount int     `json:"article_count"`
+}
+
+// WorkflowExplanation describes why the assistant took the path it did
+type WorkflowExplanation struct {
+	Intent               string   `json:"intent"`
+	Confidence           float64  `json:"confidence"`
+	Reasoning            string   `json:"reasoning,omitempty"`
+	ReferencedTopic      string   `json:"referenced_topic,omitempty"`
+	ReferencedExchangeID string   `json:"referenced_exchange_id,omitempty"`
+	AgentSequence        []string `json:"agent_sequence"`
+}
+
+// IntermediateOutputs exposes what each agent produced on the way to the answer, for debugging and richer client UIs
+type IntermediateOutputs struct {
+	EnhancedQuery    string   `json:"enhanced_query"`
+	Keywords         []string `json:"keywords"`
+	ArticlesFound    int      `json:"articles_found"`
+	ArticlesFiltered int      `json:"articles_filtered"`
+	VideosFound      int      `json:"videos_found"`
+	VideosFiltered   int      `json:"videos_filtered"`
+	// summary before the persona rewrite and the response after it
+	Summary  string `json:"summary"`
+	Response string `json:"response"`
 }
 
 type WorkflowContext struct {
 	// Workflow Identification
 	ID                   string              `json:"id"`
 	UserID               string              `json:"user_id"`
+	TenantID             string              `json:"tenant_id,omitempty"`
 	SessionID            string              `json:"session_id"`
 	RequestID            string              `json:"request_id"`
 	OriginalQuery        string              `json:"original_query"`
@@ -55,6 +216,7 @@ type WorkflowContext struct {
 	EndTime              *time.Time          `json:"end_time,omitempty"`
 	Intent               string              `json:"intent,omitempty"`
 	IntentConfidence     float64             `json:"intent_confidence,omitempty"`
+	IntentReasoning      string              `json:"intent_reasoning,omitempty"`
 	Keywords             []string            `json:"keywords,omitempty"`
 	Videos               []YouTubeVideo      `json:"videos,omitempty"`
 	Articles             []NewsArticle       `json:"articles,omitempty"`
@@ -67,26 +229,37 @@ type WorkflowContext struct {
 	AgentExecutions      []AgentExecution    `json:"agent_executions,omitempty"`
 	ProcessingStats      ProcessingStats     `json:"processing_stats"`
 	Metadata             map[string]any      `json:"metadata,omitempty"`
+	RequestMetadata      map[string]any      `json:"request_metadata,omitempty"` // caller supplied overrides, never written by agents
+	Stateless            bool                `json:"stateless,omitempty"`
 }
 
 type ConversationContext struct {
-	SessionID           string                 `json:"session_id"`
-	UserID              string                 `json:"user_id"`
-	Exchanges           []ConversationExchange `json:"exchanges"`
-	TotalExchanges      int                    `json:"total_exchanges"`
-	CurrentTopics       []string               `json:"current_topics"`
-	RecentKeywords      []string               `json:"recent_keywords"`
-	LastQuery           string                 `json:"last_query"`
-	LastResponse        string                 `json:"last_response"`
-	LastIntent          string                 `json:"last_intent"`
-	LastReferencedTopic string                 `json:"last_referenced_topic,omitempty"`
-	LastSummary         string                 `json:"last_summary,omitempty"`
-	SessionStartTime    time.Time              `json:"session_start_time"`
-	LastActiveTime      time.Time              `json:"last_active_time"`
-	MessageCount        int                    `json:"message_count"`
-	UserPreferences     UserPreferences        `json:"user_preferences"`
-	ContextSummary      string                 `json:"context_summary,omitempty"` // Brief summary of conversation so far
-	UpdatedAt           time.Time              `json:"updated_at"`
+	SessionID      string                 `json:"session_id"`
+	UserID         string                 `json:"user_id"`
+	Exchanges      []ConversationExchange `json:"exchanges"`
+	TotalExchanges int                    `json:"total_exchanges"`
+	CurrentTopics  []string               `json:"current_topics"`
+	// only kept when topic drift tracking is on, how alive each current topic still is and where the thread is heading
+	TrackedTopics       map[string]TrackedTopic `json:"tracked_topics,omitempty"`
+	TopicCentroid       []float64               `json:"topic_centroid,omitempty"`
+	RecentKeywords      []string                `json:"recent_keywords"`
+	LastQuery           string                  `json:"last_query"`
+	LastResponse        string                  `json:"last_response"`
+	LastIntent          string                  `json:"last_intent"`
+	LastReferencedTopic string                  `json:"last_referenced_topic,omitempty"`
+	LastSummary         string                  `json:"last_summary,omitempty"`
+	SessionStartTime    time.Time               `json:"session_start_time"`
+	LastActiveTime      time.Time               `json:"last_active_time"`
+	MessageCount        int                     `json:"message_count"`
+	UserPreferences     UserPreferences         `json:"user_preferences"`
+	ContextSummary      string                  `json:"context_summary,omitempty"` // Brief summary of conversation so far
+	UpdatedAt           time.Time               `json:"updated_at"`
+}
+
+// TrackedTopic is a current topic's weight, stale once the conversation drifted away from the thread it came from
+type TrackedTopic struct {
+	Weight float64 `json:"weight"`
+	Stale  bool    `json:"stale,omitempty"`
 }
 
 type ConversationExchange struct {
@@ -108,7 +281,66 @@ type UserPreferences struct {
 	NewsPersonality string   `json:"news_personality"`
 	FavouriteTopics []string `json:"favourite_topics"`
 	ResponseLength  string   `json:"content_length"`
-}
+	SummaryFormat   string   `json:"summary_format,omitempty"`
+	Language        string   `json:"language,omitempty"`      // ISO 639-1 code, english when empty
+	Region          string   `json:"region,omitempty"`        // ISO 3166-1 alpha-2 code
+	Timezone        string   `json:"timezone,omitempty"`      // IANA timezone name
+	OutputFormat    string   `json:"output_format,omitempty"` // markdown when empty
+	// StrictSourcesOnly overrides the configured default, nil keeps it
+	StrictSourcesOnly *bool `json:"strict_sources_only,omitempty"`
+	// CustomInstructions adjust tone and focus of the written responses, e.g. "be terse"
+	CustomInstructions string `json:"custom_instructions,omitempty"`
+	// IncludeSentiment rates the tone of each relevant article, it costs one extra model call
+	IncludeSentiment bool `json:"include_sentiment,omitempty"`
+	// InlineCitations overrides the configured default, nil keeps it
+	InlineCitations *bool `json:"inline_citations,omitempty"`
+}
+
+// MaxCustomInstructionLength bounds custom instructions in characters, they are added to every user facing prompt
+const MaxCustomInstructionLength = 500
+
+// SupportedRegions are the country codes accepted by both NewsAPI and YouTube for localized search
+var SupportedRegions = []string{
+	"ae", "ar", "at", "au", "be", "bg", "br", "ca", "ch", "cn", "co", "cu", "cz", "de", "eg", "fr", "gb", "gr",
+	"hk", "hu", "id", "ie", "il", "in", "it", "jp", "kr", "lt", "lv", "ma", "mx", "my", "ng", "nl", "no", "nz",
+	"ph", "pl", "pt", "ro", "rs", "ru", "sa", "se", "sg", "si", "sk", "th", "tr", "tw", "ua", "us", "ve", "za",
+}
+
+// AvailablePersonas lists every news personality the persona agent knows how to apply
+var AvailablePersonas = []string{
+	"calm-anchor",
+	"friendly-explainer",
+	"investigative-reporter",
+	"youthful-trendspotter",
+	"global-correspondent",
+	"ai-analyst",
+}
+
+// DefaultPersona is used when neither the user nor the tenant picked one
+const DefaultPersona = "friendly-explainer"
+
+// NoPersona skips the persona rewrite, machine consumers get the neutral summary as the response
+const NoPersona = "none"
+
+// SummaryFormat selects the layout of the news summary
+type SummaryFormat string
+
+const (
+	SummaryFormatProse    SummaryFormat = "prose"
+	SummaryFormatBullets  SummaryFormat = "bullets"
+	SummaryFormatSections SummaryFormat = "sections"
+	SummaryFormatTLDR     SummaryFormat = "tldr"
+	SummaryFormatJSON     SummaryFormat = "json"
+)
+
+// OutputFormat is how the final response text is rendered for the client
+type OutputFormat string
+
+const (
+	OutputFormatMarkdown  OutputFormat = "markdown"
+	OutputFormatPlaintext OutputFormat = "plaintext"
+	OutputFormatSSML      OutputFormat = "ssml"
+)
 
 type NewsArticle struct {
 	ID             string    `json:"id"`
@@ -123,8 +355,23 @@ type NewsArticle struct {
 	Category       string    `json:"category,omitempty"`
 	RelevanceScore float64   `json:"relevance_score,omitempty"`
 	EmbeddingID    string    `json:"embedding_id,omitempty"`
+	ArticleType    string    `json:"article_type,omitempty"` // "news" or "opinion"
+	// only rated when the user opts in to sentiment analysis
+	Sentiment *ArticleSentiment `json:"sentiment,omitempty"`
+	// attributed direct quotations pulled from the scraped content when quote extraction is on
+	Quotes []ArticleQuote `json:"quotes,omitempty"`
+}
+
+type ArticleQuote struct {
+	Text    string `json:"text"`
+	Speaker string `json:"speaker"`
 }
 
+const (
+	ArticleTypeNews    = "news"
+	ArticleTypeOpinion = "opinion"
+)
+
 type AgentExecution struct {
 	AgentName    string         `json:"agent_name"`
 	StartTime    time.Time      `json:"start_time"`
@@ -148,9 +395,25 @@ type ProcessingStats struct {
 	VideosFiltered      int                      `json:"videos_filtered,omitempty"`
 	APICallsCount       int                      `json:"api_calls_count,omitempty"`
 	TokensUsed          int                      `json:"tokens_used,omitempty"`
+	InputTokens         int                      `json:"input_tokens,omitempty"`
+	OutputTokens        int                      `json:"output_tokens,omitempty"`
+	EmbeddingRequests   int                      `json:"embedding_requests,omitempty"`
+	EstimatedCostUSD    float64                  `json:"estimated_cost_usd,omitempty"`
 	EmbeddingsCount     int                      `json:"embeddings_count,omitempty"`
 	EmbeddingDuration   time.Duration            `json:"embedding_duration,omitempty"`
 	CacheHitsCount      int                      `json:"cache_hits_count,omitempty"`
+	ScrapeAttempts      int                      `json:"scrape_attempts,omitempty"`
+	ArticlesScraped     int                      `json:"articles_scraped,omitempty"`
+	// relevant articles left unscraped by the relevance gate
+	ScrapesSkipped int `json:"scrapes_skipped,omitempty"`
+	// transcript-less videos given a generated summary, and those left with their raw description past the cap
+	VideoFallbacksGenerated int `json:"video_fallbacks_generated,omitempty"`
+	VideoFallbacksSkipped   int `json:"video_fallbacks_skipped,omitempty"`
+	// degraded paths taken, e.g. "vector_search" when articles came from the fresh fetch instead
+	Fallbacks        []string `json:"fallbacks,omitempty"`
+	SummaryTruncated bool     `json:"summary_truncated,omitempty"`
+	// 0..1, only set for news workflows
+	QualityScore *float64 `json:"quality_score,omitempty"`
 }
 
 type AgentStats struct {
@@ -190,6 +453,7 @@ func NewWorkflowContext(req WorkflowRequest, requestID string) *WorkflowContext
 	return &WorkflowContext{
 		ID:            workflowID,
 		UserID:        req.UserID,
+		TenantID:      req.TenantID,
 		RequestID:     requestID,
 		OriginalQuery: req.Query,
 		Status:        WorkflowStatusPending,
@@ -212,6 +476,7 @@ func NewWorkflowContext(req WorkflowRequest, requestID string) *WorkflowContext
 		IsFollowUp:      false,
 		AgentExecutions: []AgentExecution{},
 		Metadata:        make(map[string]any),
+		RequestMetadata: req.Metadata,
 		ProcessingStats: ProcessingStats{
 			TotalDuration:       0,
 			AgentStats:          make(map[string]AgentStats),
@@ -229,11 +494,12 @@ func NewWorkflowContext(req WorkflowRequest, requestID string) *WorkflowContext
 
 func NewWorkflowResponse(workflowID, requestID, status, message string) *WorkflowResponse {
 	return &WorkflowResponse{
-		WorkflowID: workflowID,
-		Status:     status,
-		Message:    message,
-		RequestID:  requestID,
-		Timestamp:  time.Now(),
+		WorkflowID:   workflowID,
+		Status:       status,
+		Message:      message,
+		RequestID:    requestID,
+		Timestamp:    time.Now(),
+		ResponseType: ResponseTypeAnswer,
 	}
 }
 
@@ -252,6 +518,20 @@ func (wc *WorkflowContext) MarkFailed() {
 	wc.ProcessingStats.TotalDuration = time.Since(wc.StartTime)
 }
 
+func (wc *WorkflowContext) MarkTimedOut() {
+	wc.Status = WorkflowStatusTimeout
+	now := time.Now()
+	wc.EndTime = &now
+	wc.ProcessingStats.TotalDuration = time.Since(wc.StartTime)
+}
+
+func (wc *WorkflowContext) MarkCancelled() {
+	wc.Status = WorkflowStatusCancelled
+	now := time.Now()
+	wc.EndTime = &now
+	wc.ProcessingStats.TotalDuration = time.Since(wc.StartTime)
+}
+
 func (wc *WorkflowContext) MarkAsFollowUp(referencedTopic, referencedExchangeID string) {
 	wc.IsFollowUp = true
 	wc.ReferencedTopic = referencedTopic
@@ -295,17 +575,30 @@ func (wc *WorkflowContext) GetDuration() time.Duration {
 	return time.Since(wc.StartTime)
 }
 
-func (wc *WorkflowContext) AddKeywords(keywords []string) {
+// MaxKeywords caps the search keyword set, earlier keywords win so extracted ones outrank blended ones
+const MaxKeywords = 15
+
+// AddKeywords appends keywords not already present (case-insensitive) and returns how many were added
+func (wc *WorkflowContext) AddKeywords(keywords []string) int {
 	existingMap := make(map[string]bool)
 	for _, kw := range wc.Keywords {
-		existingMap[kw] = true
+		existingMap[strings.ToLower(kw)] = true
 	}
 
+	added := 0
 	for _, kw := range keywords {
-		if !existingMap[kw] {
-			wc.Keywords = append(wc.Keywords, kw)
+		if len(wc.Keywords) >= MaxKeywords {
+			break
 		}
+		key := strings.ToLower(strings.TrimSpace(kw))
+		if key == "" || existingMap[key] {
+			continue
+		}
+		existingMap[key] = true
+		wc.Keywords = append(wc.Keywords, kw)
+		added++
 	}
+	return added
 }
 
 func (wc *WorkflowContext) SetIntent(intent string) {
@@ -317,6 +610,82 @@ func (wc *WorkflowContext) SetEnhancedQuery(enhancedQuery string) {
 	wc.EnhancedQuery = enhancedQuery
 }
 
+// RequestInt reads an integer override from the request metadata, JSON numbers arrive as float64
+func (wc *WorkflowContext) RequestInt(key string) (int, bool) {
+	switch value := wc.RequestMetadata[key].(type) {
+	case float64:
+		return int(value), true
+	case int:
+		return value, true
+	default:
+		return 0, false
+	}
+}
+
+// RecordFallback notes a degraded path once, the quality score penalises any fallback
+func (wc *WorkflowContext) RecordFallback(name string) {
+	for _, existing := range wc.ProcessingStats.Fallbacks {
+		if existing == name {
+			return
+		}
+	}
+	wc.ProcessingStats.Fallbacks = append(wc.ProcessingStats.Fallbacks, name)
+}
+
+// RequestString reads a non-empty string override from the request metadata
+func (wc *WorkflowContext) RequestString(key string) (string, bool) {
+	value, ok := wc.RequestMetadata[key].(string)
+	return value, ok && value != ""
+}
+
+func (wc *WorkflowContext) RequestBool(key string) bool {
+	value, ok := wc.RequestMetadata[key].(bool)
+	return ok && value
+}
+
+// Explain builds the reasoning trace returned to callers that opt into explain mode
+func (wc *WorkflowContext) Explain() *WorkflowExplanation {
+	explanation := &WorkflowExplanation{
+		Intent:        wc.Intent,
+		Confidence:    wc.IntentConfidence,
+		Reasoning:     wc.IntentReasoning,
+		AgentSequence: make([]string, 0, len(wc.AgentExecutions)),
+	}
+
+	if wc.IsFollowUp {
+		explanation.ReferencedTopic = wc.ReferencedTopic
+		explanation.ReferencedExchangeID = wc.ReferencedExchangeID
+	}
+
+	for _, execution := range wc.AgentExecutions {
+		if n := len(explanation.AgentSequence); n > 0 && explanation.AgentSequence[n-1] == execution.AgentName {
+			continue
+		}
+		explanation.AgentSequence = append(explanation.AgentSequence, execution.AgentName)
+	}
+
+	return explanation
+}
+
+// Intermediate collects the agent outputs returned to callers that opt into intermediate outputs
+func (wc *WorkflowContext) Intermediate() *IntermediateOutputs {
+	keywords := wc.Keywords
+	if keywords == nil {
+		keywords = []string{}
+	}
+
+	return &IntermediateOutputs{
+		EnhancedQuery:    wc.EnhancedQuery,
+		Keywords:         keywords,
+		ArticlesFound:    wc.ProcessingStats.ArticlesFound,
+		ArticlesFiltered: wc.ProcessingStats.ArticlesFiltered,
+		VideosFound:      wc.ProcessingStats.VideosFound,
+		VideosFiltered:   wc.ProcessingStats.VideosFiltered,
+		Summary:          wc.Summary,
+		Response:         wc.Response,
+	}
+}
+
 func (wc *WorkflowContext) IsCompleted() bool {
 	return wc.Status == WorkflowStatusCompleted
 }
@@ -329,8 +698,17 @@ func (wc *WorkflowContext) IsProcessing() bool {
 	return wc.Status == WorkflowStatusProcessing
 }
 
+// ExchangeRetention bounds how many exchanges a conversation keeps, the whole slice is rewritten to redis every turn
+type ExchangeRetention struct {
+	MaxExchanges int  // 0 keeps every exchange
+	FoldEvicted  bool // evicted queries are folded into ContextSummary so the gist survives
+}
+
+// maxContextSummaryLength keeps folded history from growing into the payload the cap is meant to bound
+const maxContextSummaryLength = 2000
+
 // ConversationContext Methods
-func (cc *ConversationContext) AddExchange(userQuery, aiResponse, intent string, topics, entities, keywords []string) {
+func (cc *ConversationContext) AddExchange(userQuery, aiResponse, intent string, topics, entities, keywords []string, retention ExchangeRetention) {
 	exchange := ConversationExchange{
 		ID:           uuid.New().String(),
 		Timestamp:    time.Now(),
@@ -347,6 +725,7 @@ func (cc *ConversationContext) AddExchange(userQuery, aiResponse, intent string,
 	cc.Exchanges = append(cc.Exchanges, exchange)
 	cc.TotalExchanges++
 	cc.MessageCount++
+	cc.evictExchanges(retention)
 
 	// Update context tracking
 	cc.updateRecentContext(topics, entities, keywords)
@@ -357,11 +736,83 @@ func (cc *ConversationContext) AddExchange(userQuery, aiResponse, intent string,
 	cc.UpdatedAt = time.Now()
 }
 
+// evictExchanges drops the oldest exchanges beyond the cap
+func (cc *ConversationContext) evictExchanges(retention ExchangeRetention) {
+	if retention.MaxExchanges <= 0 || len(cc.Exchanges) <= retention.MaxExchanges {
+		return
+	}
+
+	overflow := len(cc.Exchanges) - retention.MaxExchanges
+	if retention.FoldEvicted {
+		cc.foldIntoSummary(cc.Exchanges[:overflow])
+	}
+
+	// copy so the evicted exchanges are not pinned by the backing array
+	cc.Exchanges = append([]ConversationExchange(nil), cc.Exchanges[overflow:]...)
+}
+
+func (cc *ConversationContext) foldIntoSummary(evicted []ConversationExchange) {
+	var builder strings.Builder
+	builder.WriteString(cc.ContextSummary)
+	for _, exchange := range evicted {
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fmt.Sprintf("- [%s] %s", exchange.Timestamp.Format("2006-01-02"), strings.TrimSpace(exchange.UserQuery)))
+		if exchange.Intent != "" {
+			builder.WriteString(fmt.Sprintf(" (%s)", exchange.Intent))
+		}
+	}
+
+	summary := builder.String()
+	if len(summary) > maxContextSummaryLength {
+		// keep the most recent history, cut at a line boundary
+		summary = summary[len(summary)-maxContextSummaryLength:]
+		if idx := strings.Index(summary, "\n"); idx >= 0 {
+			summary = summary[idx+1:]
+		}
+	}
+	cc.ContextSummary = summary
+}
+
+// IsSessionIdle reports whether the user has been inactive longer than timeout, a zero timeout never expires
+func (cc *ConversationContext) IsSessionIdle(now time.Time, timeout time.Duration) bool {
+	return timeout > 0 && !cc.LastActiveTime.IsZero() && now.Sub(cc.LastActiveTime) > timeout
+}
+
+// StartNewSession clears the short-term conversation state that drives follow-up detection. Exchanges,
+// the context summary and preferences are long-term and survive, exchanges from earlier sessions just
+// stop counting as recent.
+func (cc *ConversationContext) StartNewSession(now time.Time) {
+	cc.SessionStartTime = now
+	cc.CurrentTopics = []string{}
+	cc.TrackedTopics = nil
+	cc.TopicCentroid = nil
+	cc.RecentKeywords = []string{}
+	cc.LastReferencedTopic = ""
+	cc.LastQuery = ""
+	cc.LastResponse = ""
+	cc.LastIntent = ""
+	cc.LastSummary = ""
+}
+
+// SessionExchanges returns the exchanges made since the current session started
+func (cc *ConversationContext) SessionExchanges() []ConversationExchange {
+	for i, exchange := range cc.Exchanges {
+		if !exchange.Timestamp.Before(cc.SessionStartTime) {
+			return cc.Exchanges[i:]
+		}
+	}
+	return []ConversationExchange{}
+}
+
+// GetRecentExchanges returns up to count of the latest exchanges in the current session
 func (cc *ConversationContext) GetRecentExchanges(count int) []ConversationExchange {
-	if len(cc.Exchanges) <= count {
-		return cc.Exchanges
+	exchanges := cc.SessionExchanges()
+	if len(exchanges) <= count {
+		return exchanges
 	}
-	return cc.Exchanges[len(cc.Exchanges)-count:]
+	return exchanges[len(exchanges)-count:]
 }
 
 func (cc *ConversationContext) FindRelevantExchanges(query string, maxCount int) []ConversationExchange {
@@ -377,14 +828,15 @@ func (cc *ConversationContext) updateRecentContext(topics, entities, keywords []
 }
 
 func (cc *ConversationContext) HasPreviousExchanges() bool {
-	return len(cc.Exchanges) > 0
+	return len(cc.SessionExchanges()) > 0
 }
 
 func (cc *ConversationContext) GetLastExchange() *ConversationExchange {
-	if len(cc.Exchanges) == 0 {
+	exchanges := cc.SessionExchanges()
+	if len(exchanges) == 0 {
 		return nil
 	}
-	return &cc.Exchanges[len(cc.Exchanges)-1]
+	return &exchanges[len(exchanges)-1]
 }
 
 // Helper Functions
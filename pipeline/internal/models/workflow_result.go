@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WorkflowResult is the durable copy of a completed workflow, kept after the active state expires so
+// clients holding a workflow id can still fetch the answer
+type WorkflowResult struct {
+	WorkflowID   string           `json:"workflow_id"`
+	RequestID    string           `json:"request_id"`
+	UserID       string           `json:"user_id"`
+	TenantID     string           `json:"tenant_id,omitempty"`
+	Query        string           `json:"query"`
+	Status       string           `json:"status"`
+	Intent       string           `json:"intent,omitempty"`
+	ResponseType string           `json:"response_type"`
+	Response     string           `json:"response"`
+	Summary      string           `json:"summary,omitempty"`
+	Sources      []ResponseSource `json:"sources,omitempty"`
+	TotalTime    float64          `json:"total_time_ms"`
+	QualityScore *float64         `json:"quality_score,omitempty"`
+	CompletedAt  time.Time        `json:"completed_at"`
+	StoredAt     time.Time        `json:"stored_at"`
+}
+
+// WorkflowSources is the full source list of a workflow whose response referenced it by URL
+type WorkflowSources struct {
+	WorkflowID string           `json:"workflow_id"`
+	UserID     string           `json:"user_id"`
+	TenantID   string           `json:"tenant_id,omitempty"`
+	Sources    []ResponseSource `json:"sources"`
+	StoredAt   time.Time        `json:"stored_at"`
+}
+
+// SourcesPage is one page of a workflow's sources
+type SourcesPage struct {
+	WorkflowID string           `json:"workflow_id"`
+	Sources    []ResponseSource `json:"sources"`
+	Page       int              `json:"page"`
+	PageSize   int              `json:"page_size"`
+	Total      int              `json:"total"`
+	TotalPages int              `json:"total_pages"`
+}
+
+// Paginate returns the given 1-based page, a page past the end comes back empty
+func (sources *WorkflowSources) Paginate(page, pageSize int) SourcesPage {
+	total := len(sources.Sources)
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+
+	return SourcesPage{
+		WorkflowID: sources.WorkflowID,
+		Sources:    sources.Sources[start:end],
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + pageSize - 1) / pageSize,
+	}
+}
+
+// WorkflowRecording captures every external input and model output of one workflow run, so a reported answer can
+// be replayed against exactly what the pipeline saw
+type WorkflowRecording struct {
+	WorkflowID string            `json:"workflow_id"`
+	UserID     string            `json:"user_id"`
+	Request    WorkflowRequest   `json:"request"`
+	Calls      []RecordedCall    `json:"calls"`
+	Response   *WorkflowResponse `json:"response,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	RecordedAt time.Time         `json:"recorded_at"`
+}
+
+// RecordedCall is one call to an external service or model, its result is kept as the service returned it
+type RecordedCall struct {
+	Kind       string          `json:"kind"`
+	Key        string          `json:"key"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+}
+
+// ReplayReport compares a replayed workflow with its recording. Diverged calls were served the next recorded call
+// of their kind because nothing was recorded for their exact input, missing calls had no recording left at all.
+type ReplayReport struct {
+	WorkflowID       string            `json:"workflow_id"`
+	ReplayWorkflowID string            `json:"replay_workflow_id"`
+	Matches          bool              `json:"matches"`
+	RecordedMessage  string            `json:"recorded_message"`
+	ReplayedMessage  string            `json:"replayed_message"`
+	RecordedCalls    int               `json:"recorded_calls"`
+	ReplayedCalls    int               `json:"replayed_calls"`
+	DivergedCalls    int               `json:"diverged_calls"`
+	MissingCalls     int               `json:"missing_calls"`
+	Response         *WorkflowResponse `json:"response,omitempty"`
+	Error            string            `json:"error,omitempty"`
+}
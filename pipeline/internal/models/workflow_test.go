@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeFreshnessTierBoundaries(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		publishedAt time.Time
+		want        FreshnessTier
+	}{
+		{name: "just published", publishedAt: now, want: FreshnessBreaking},
+		{name: "within the clock skew", publishedAt: now.Add(4 * time.Minute), want: FreshnessBreaking},
+		{name: "beyond the clock skew", publishedAt: now.Add(6 * time.Minute), want: FreshnessUndated},
+		{name: "just under an hour", publishedAt: now.Add(-time.Hour + time.Second), want: FreshnessBreaking},
+		{name: "exactly an hour", publishedAt: now.Add(-time.Hour), want: FreshnessToday},
+		{name: "just under a day", publishedAt: now.Add(-24*time.Hour + time.Second), want: FreshnessToday},
+		{name: "exactly a day", publishedAt: now.Add(-24 * time.Hour), want: FreshnessThisWeek},
+		{name: "just under a week", publishedAt: now.Add(-7*24*time.Hour + time.Second), want: FreshnessThisWeek},
+		{name: "exactly a week", publishedAt: now.Add(-7 * 24 * time.Hour), want: FreshnessOlder},
+		{name: "zero time", publishedAt: time.Time{}, want: FreshnessUndated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputeFreshnessTier(tt.publishedAt, now); got != tt.want {
+				t.Errorf("ComputeFreshnessTier() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddKeywordsDedupesAndCapsTheSet(t *testing.T) {
+	wc := &WorkflowContext{Keywords: []string{"Election"}}
+
+	if added := wc.AddKeywords([]string{"election", " ", "results", "Results"}); added != 1 {
+		t.Errorf("AddKeywords() added %d, want only results", added)
+	}
+
+	var many []string
+	for i := 0; i < 2*MaxKeywords; i++ {
+		many = append(many, fmt.Sprintf("keyword-%d", i))
+	}
+	wc.AddKeywords(many)
+	if len(wc.Keywords) != MaxKeywords || wc.Keywords[0] != "Election" || wc.Keywords[1] != "results" {
+		t.Errorf("keywords = %v, want the first %d with earlier keywords kept", wc.Keywords, MaxKeywords)
+	}
+}
+
+func TestAddExchangeEvictsTheOldestBeyondTheCap(t *testing.T) {
+	cc := &ConversationContext{}
+	retention := ExchangeRetention{MaxExchanges: 3}
+
+	for i := 1; i <= 5; i++ {
+		cc.AddExchange(fmt.Sprintf("query %d", i), "answer", "NEWS", nil, nil, nil, retention)
+	}
+
+	var queries []string
+	for _, exchange := range cc.Exchanges {
+		queries = append(queries, exchange.UserQuery)
+	}
+	if len(queries) != 3 || queries[0] != "query 3" || queries[2] != "query 5" {
+		t.Errorf("stored queries = %v, want the newest 3", queries)
+	}
+	if cc.TotalExchanges != 5 {
+		t.Errorf("TotalExchanges = %d, want every exchange counted", cc.TotalExchanges)
+	}
+	if cc.ContextSummary != "" {
+		t.Errorf("ContextSummary = %q, want nothing folded when folding is off", cc.ContextSummary)
+	}
+}
+
+func TestAddExchangeFoldsEvictedQueriesIntoTheSummary(t *testing.T) {
+	cc := &ConversationContext{}
+	retention := ExchangeRetention{MaxExchanges: 2, FoldEvicted: true}
+
+	for i := 1; i <= 4; i++ {
+		cc.AddExchange(fmt.Sprintf("query %d", i), "answer", "NEWS", nil, nil, nil, retention)
+	}
+
+	lines := strings.Split(cc.ContextSummary, "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "query 1 (NEWS)") || !strings.HasSuffix(lines[1], "query 2 (NEWS)") {
+		t.Errorf("ContextSummary = %q, want the two evicted queries in order", cc.ContextSummary)
+	}
+}
+
+func TestAddExchangeWithoutACapKeepsEverything(t *testing.T) {
+	cc := &ConversationContext{}
+	for i := 0; i < 10; i++ {
+		cc.AddExchange("query", "answer", "NEWS", nil, nil, nil, ExchangeRetention{})
+	}
+	if len(cc.Exchanges) != 10 {
+		t.Errorf("stored %d exchanges, want all 10", len(cc.Exchanges))
+	}
+}
@@ -159,7 +159,7 @@ func (l *Logger) LogAgent(workflowID, agentName, action string, duration time.Du
 	if err != nil {
 		entry.WithError(err).Error(fmt.Sprintf("agent %s : action %s Failed", agentName, action))
 	} else {
-		entry.Info(fmt.Sprintf("Agent %s : Action %s completed successfully", agentName))
+		entry.Info(fmt.Sprintf("Agent %s : Action %s completed successfully", agentName, action))
 	}
 
 }
@@ -0,0 +1,511 @@
+// Package redistest runs an in-process server speaking enough RESP2 for the commands the pipeline sends to
+// redis, so tests can exercise RedisService without a real instance. Keys live in memory and expire lazily.
+package redistest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// how often a blocked XREAD re-checks its streams
+const blockPollInterval = 10 * time.Millisecond
+
+type Server struct {
+	mu      sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+	sets    map[string]map[string]bool
+	streams map[string][]streamEntry
+	expires map[string]time.Time
+
+	addr   string
+	closed chan struct{}
+}
+
+type streamEntry struct {
+	id     streamID
+	fields []string
+}
+
+type streamID struct {
+	ms, seq int64
+}
+
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+func (id streamID) after(other streamID) bool {
+	return id.ms > other.ms || (id.ms == other.ms && id.seq > other.seq)
+}
+
+// NewServer starts a server that is shut down when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	server := &Server{
+		strings: make(map[string]string),
+		hashes:  make(map[string]map[string]string),
+		sets:    make(map[string]map[string]bool),
+		streams: make(map[string][]streamEntry),
+		expires: make(map[string]time.Time),
+		closed:  make(chan struct{}),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen for fake redis: %v", err)
+	}
+	server.addr = listener.Addr().String()
+
+	var connections sync.WaitGroup
+	t.Cleanup(func() {
+		close(server.closed)
+		listener.Close()
+		connections.Wait()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			go func() {
+				defer connections.Done()
+				server.serve(conn)
+			}()
+		}
+	}()
+
+	return server
+}
+
+// URL is the redis:// address to configure clients with
+func (server *Server) URL() string {
+	return "redis://" + server.addr
+}
+
+func (server *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	var queued [][]string
+	inTransaction := false
+	for {
+		args, err := readRESPCommand(reader)
+		if err != nil {
+			return
+		}
+
+		var reply any
+		switch name := strings.ToUpper(args[0]); {
+		case name == "MULTI":
+			inTransaction, queued, reply = true, nil, "OK"
+		case name == "EXEC":
+			replies := make([]any, 0, len(queued))
+			for _, command := range queued {
+				replies = append(replies, server.execute(command))
+			}
+			inTransaction, queued, reply = false, nil, replies
+		case name == "DISCARD":
+			inTransaction, queued, reply = false, nil, "OK"
+		case inTransaction:
+			queued, reply = append(queued, args), "QUEUED"
+		case name == "XREAD":
+			reply = server.blockingRead(args, server.execute(args))
+		default:
+			reply = server.execute(args)
+		}
+
+		writeRESP(writer, reply)
+		if err := writer.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// execute runs one command, replies are a status string, an error, an int, a *string bulk (nil for null) or a slice
+func (server *Server) execute(args []string) any {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	name := strings.ToUpper(args[0])
+	for _, key := range args[1:min(len(args), 2)] {
+		server.expire(key)
+	}
+
+	switch name {
+	case "PING":
+		return "PONG"
+	case "HELLO":
+		return errors.New("ERR unknown command 'HELLO'")
+	case "CLIENT", "SELECT":
+		return "OK"
+	case "GET":
+		if value, exists := server.strings[args[1]]; exists {
+			return &value
+		}
+		return (*string)(nil)
+	case "MGET":
+		values := make([]any, 0, len(args)-1)
+		for _, key := range args[1:] {
+			server.expire(key)
+			if value, exists := server.strings[key]; exists {
+				values = append(values, &value)
+			} else {
+				values = append(values, (*string)(nil))
+			}
+		}
+		return values
+	case "SET":
+		return server.set(args)
+	case "SETNX":
+		return server.set([]string{"SET", args[1], args[2], "NX"})
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			server.expire(key)
+			if server.exists(key) {
+				deleted++
+			}
+			server.delete(key)
+		}
+		return deleted
+	case "EXISTS":
+		count := 0
+		for _, key := range args[1:] {
+			server.expire(key)
+			if server.exists(key) {
+				count++
+			}
+		}
+		return count
+	case "EXPIRE":
+		seconds, _ := strconv.Atoi(args[2])
+		if !server.exists(args[1]) {
+			return 0
+		}
+		server.expires[args[1]] = time.Now().Add(time.Duration(seconds) * time.Second)
+		return 1
+	case "HSET", "HMSET":
+		hash := server.hashes[args[1]]
+		if hash == nil {
+			hash = make(map[string]string)
+			server.hashes[args[1]] = hash
+		}
+		added := 0
+		for i := 2; i+1 < len(args); i += 2 {
+			if _, exists := hash[args[i]]; !exists {
+				added++
+			}
+			hash[args[i]] = args[i+1]
+		}
+		if name == "HMSET" {
+			return "OK"
+		}
+		return added
+	case "HGETALL":
+		values := []any{}
+		for field, value := range server.hashes[args[1]] {
+			values = append(values, &field, &value)
+		}
+		return values
+	case "HMGET":
+		values := make([]any, 0, len(args)-2)
+		for _, field := range args[2:] {
+			if value, exists := server.hashes[args[1]][field]; exists {
+				values = append(values, &value)
+			} else {
+				values = append(values, (*string)(nil))
+			}
+		}
+		return values
+	case "SADD":
+		set := server.sets[args[1]]
+		if set == nil {
+			set = make(map[string]bool)
+			server.sets[args[1]] = set
+		}
+		added := 0
+		for _, member := range args[2:] {
+			if !set[member] {
+				set[member] = true
+				added++
+			}
+		}
+		return added
+	case "SREM":
+		removed := 0
+		for _, member := range args[2:] {
+			if server.sets[args[1]][member] {
+				delete(server.sets[args[1]], member)
+				removed++
+			}
+		}
+		return removed
+	case "SMEMBERS":
+		members := []any{}
+		for member := range server.sets[args[1]] {
+			members = append(members, &member)
+		}
+		return members
+	case "XADD":
+		return server.xadd(args)
+	case "XREAD":
+		return server.xread(args)
+	}
+	return fmt.Errorf("ERR unknown command '%s'", args[0])
+}
+
+func (server *Server) set(args []string) any {
+	key, value := args[1], args[2]
+	var ttl time.Duration
+	onlyIfMissing := false
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			onlyIfMissing = true
+		case "EX":
+			seconds, _ := strconv.Atoi(args[i+1])
+			ttl, i = time.Duration(seconds)*time.Second, i+1
+		case "PX":
+			milliseconds, _ := strconv.Atoi(args[i+1])
+			ttl, i = time.Duration(milliseconds)*time.Millisecond, i+1
+		}
+	}
+
+	if onlyIfMissing && server.exists(key) {
+		return (*string)(nil)
+	}
+	server.delete(key)
+	server.strings[key] = value
+	if ttl > 0 {
+		server.expires[key] = time.Now().Add(ttl)
+	}
+	return "OK"
+}
+
+func (server *Server) xadd(args []string) any {
+	stream := args[1]
+	i := 2
+	for ; i < len(args) && args[i] != "*"; i++ {
+	}
+
+	id := streamID{ms: time.Now().UnixMilli()}
+	if entries := server.streams[stream]; len(entries) > 0 {
+		if last := entries[len(entries)-1].id; !id.after(last) {
+			id = streamID{ms: last.ms, seq: last.seq + 1}
+		}
+	}
+	server.streams[stream] = append(server.streams[stream], streamEntry{id: id, fields: args[i+1:]})
+
+	reply := id.String()
+	return &reply
+}
+
+// xread answers XREAD [COUNT n] [BLOCK ms] STREAMS key... id..., a null reply means nothing is newer than the ids
+func (server *Server) xread(args []string) any {
+	count := 0
+	i := 1
+	for ; i < len(args) && strings.ToUpper(args[i]) != "STREAMS"; i++ {
+		if strings.ToUpper(args[i]) == "COUNT" {
+			count, _ = strconv.Atoi(args[i+1])
+		}
+	}
+	names := args[i+1:]
+	keys, ids := names[:len(names)/2], names[len(names)/2:]
+
+	var replies []any
+	for n, key := range keys {
+		after := streamID{}
+		if ids[n] == "$" {
+			if entries := server.streams[key]; len(entries) > 0 {
+				after = entries[len(entries)-1].id
+			}
+		} else {
+			ms, seq, _ := strings.Cut(ids[n], "-")
+			after.ms, _ = strconv.ParseInt(ms, 10, 64)
+			after.seq, _ = strconv.ParseInt(seq, 10, 64)
+		}
+
+		var messages []any
+		for _, entry := range server.streams[key] {
+			if !entry.id.after(after) {
+				continue
+			}
+			id := entry.id.String()
+			fields := make([]any, len(entry.fields))
+			for f := range entry.fields {
+				fields[f] = &entry.fields[f]
+			}
+			messages = append(messages, []any{&id, fields})
+			if count > 0 && len(messages) == count {
+				break
+			}
+		}
+		if len(messages) > 0 {
+			stream := key
+			replies = append(replies, []any{&stream, messages})
+		}
+	}
+	if len(replies) == 0 {
+		return []any(nil)
+	}
+	return replies
+}
+
+// blockingRead retries an empty XREAD that asked to BLOCK until an entry arrives, the block expires or the
+// server shuts down
+func (server *Server) blockingRead(args []string, reply any) any {
+	block := time.Duration(-1)
+	for i := 1; i+1 < len(args); i++ {
+		if strings.ToUpper(args[i]) == "BLOCK" {
+			milliseconds, _ := strconv.Atoi(args[i+1])
+			block = time.Duration(milliseconds) * time.Millisecond
+		}
+	}
+	if block < 0 {
+		return reply
+	}
+
+	var deadline <-chan time.Time
+	if block > 0 {
+		deadline = time.After(block)
+	}
+	ticker := time.NewTicker(blockPollInterval)
+	defer ticker.Stop()
+	for {
+		if replies, _ := reply.([]any); replies != nil {
+			return reply
+		}
+		select {
+		case <-deadline:
+			return reply
+		case <-server.closed:
+			return reply
+		case <-ticker.C:
+			reply = server.execute(args)
+		}
+	}
+}
+
+func (server *Server) exists(key string) bool {
+	_, isString := server.strings[key]
+	return isString || server.hashes[key] != nil || server.sets[key] != nil || server.streams[key] != nil
+}
+
+func (server *Server) delete(key string) {
+	delete(server.strings, key)
+	delete(server.hashes, key)
+	delete(server.sets, key)
+	delete(server.streams, key)
+	delete(server.expires, key)
+}
+
+func (server *Server) expire(key string) {
+	if deadline, exists := server.expires[key]; exists && time.Now().After(deadline) {
+		server.delete(key)
+	}
+}
+
+// Keys returns the string keys starting with prefix
+func (server *Server) Keys(prefix string) []string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	var keys []string
+	for key := range server.strings {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// TTL returns how long key has left to live, zero when it never expires
+func (server *Server) TTL(key string) time.Duration {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if deadline, exists := server.expires[key]; exists {
+		return time.Until(deadline)
+	}
+	return 0
+}
+
+// Advance moves every expiry deadline closer by d, as if d had passed
+func (server *Server) Advance(d time.Duration) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for key, deadline := range server.expires {
+		server.expires[key] = deadline.Add(-d)
+	}
+}
+
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, err
+		}
+		args[i] = string(value[:size])
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
+
+func writeRESP(writer *bufio.Writer, reply any) {
+	switch reply := reply.(type) {
+	case string:
+		fmt.Fprintf(writer, "+%s\r\n", reply)
+	case error:
+		fmt.Fprintf(writer, "-%s\r\n", reply.Error())
+	case int:
+		fmt.Fprintf(writer, ":%d\r\n", reply)
+	case *string:
+		if reply == nil {
+			writer.WriteString("$-1\r\n")
+			return
+		}
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(*reply), *reply)
+	case []any:
+		if reply == nil {
+			writer.WriteString("*-1\r\n")
+			return
+		}
+		fmt.Fprintf(writer, "*%d\r\n", len(reply))
+		for _, item := range reply {
+			writeRESP(writer, item)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	// RequestIDHeader carries the pipeline request id on every outbound call
+	RequestIDHeader = "X-Request-ID"
+
+	tracerName = "Infiya-ai-pipeline"
+)
+
+type requestIDKey struct{}
+
+// Init installs the global tracer provider for the configured exporter. With the "none" exporter spans are
+// still created so request ids propagate, they are just never exported.
+func Init(ctx context.Context, tracingConfig config.TracingConfig, serviceName, serviceVersion string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch tracingConfig.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if tracingConfig.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(tracingConfig.OTLPEndpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", tracingConfig.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", tracingConfig.Exporter, err)
+	}
+
+	res := resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(serviceVersion),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tracingConfig.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// StartSpan starts a child span of whatever span is in ctx, tagging it with the request id when one is set
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		attributes = append(attributes, attribute.String("request_id", requestID))
+	}
+	return Tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan marks the span failed when err is set and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Transport wraps an http transport so outbound calls get a client span, trace context headers and the request id header
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(&requestIDTransport{base: base})
+}
+
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+func (transport *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if requestID := RequestIDFromContext(req.Context()); requestID != "" && req.Header.Get(RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, requestID)
+	}
+	return transport.base.RoundTrip(req)
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransportPropagatesTheRequestID(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	ctx, span := StartSpan(WithRequestID(context.Background(), "request-42"), "test")
+	defer span.End()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	response, err := (&http.Client{Transport: Transport(nil)}).Do(request)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	response.Body.Close()
+
+	if got := received.Get(RequestIDHeader); got != "request-42" {
+		t.Errorf("%s = %q, want request-42", RequestIDHeader, got)
+	}
+}
+
+func TestTransportKeepsAnExplicitRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(RequestIDHeader)
+	}))
+	defer server.Close()
+
+	request, _ := http.NewRequestWithContext(WithRequestID(context.Background(), "from-context"), http.MethodGet, server.URL, nil)
+	request.Header.Set(RequestIDHeader, "explicit")
+	response, err := (&http.Client{Transport: Transport(nil)}).Do(request)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	response.Body.Close()
+
+	if received != "explicit" {
+		t.Errorf("%s = %q, want the caller's explicit value", RequestIDHeader, received)
+	}
+}
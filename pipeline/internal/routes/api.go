@@ -11,6 +11,10 @@ func SetupRoutes(
 	workflowHandler *handlers.WorkflowHandler,
 	healthHandler *handlers.HealthHandler,
 	metricsHandler *handlers.MetricsHandler,
+	adminHandler *handlers.AdminHandler,
+	subscriptionHandler *handlers.SubscriptionHandler,
+	debugHandler *handlers.DebugHandler,
+	adminAuth gin.HandlerFunc,
 ) {
 	// Root endpoint
 	router.GET("/", func(c *gin.Context) {
@@ -29,10 +33,22 @@ func SetupRoutes(
 		{
 			workflows.POST("/execute", workflowHandler.ExecuteWorkflow)
 			workflows.GET("/:id/status", workflowHandler.GetWorkflowStatus)
+			workflows.GET("/:id/sources", workflowHandler.GetWorkflowSources)
+			workflows.GET("/:id/ws", workflowHandler.StreamWorkflowUpdates)
 			workflows.DELETE("/:id", workflowHandler.CancelWorkflow)
 			workflows.GET("/active", workflowHandler.GetActiveWorkflows)
 		}
 
+		v1.GET("/personas", workflowHandler.ListPersonas)
+
+		// Subscription routes
+		subscriptions := v1.Group("/subscriptions")
+		{
+			subscriptions.POST("", subscriptionHandler.CreateSubscription)
+			subscriptions.GET("", subscriptionHandler.ListSubscriptions)
+			subscriptions.DELETE("/:id", subscriptionHandler.DeleteSubscription)
+		}
+
 		// Health routes
 		health := v1.Group("/health")
 		{
@@ -48,5 +64,22 @@ func SetupRoutes(
 			metrics.GET("/orchestrator", metricsHandler.GetOrchestratorStats)
 			metrics.GET("/system", metricsHandler.GetSystemResources)
 		}
+
+		// Admin routes
+		admin := v1.Group("/admin", adminAuth)
+		{
+			admin.GET("/workflows", adminHandler.ListWorkflows)
+			admin.DELETE("/workflows/:id", adminHandler.CancelWorkflow)
+			admin.POST("/workflows/:id/replay", adminHandler.ReplayWorkflow)
+			admin.POST("/chromadb/compact", adminHandler.CompactCollection)
+		}
+
+		// Debug routes, only registered when enabled
+		if debugHandler != nil {
+			debug := v1.Group("/debug")
+			{
+				debug.POST("/classify", debugHandler.ClassifyQuery)
+			}
+		}
 	}
 }
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// how long cancelled workflows get to unwind and write their responses once the grace period is over
+const drainCancelWait = 5 * time.Second
+
+// workflowControl holds the handles ops need on an in-flight workflow. The intent and status are copies kept
+// under mu, the workflow goroutine writes the context fields while ops read them.
+type workflowControl struct {
+	cancel    context.CancelFunc
+	cancelled atomic.Bool
+
+	mu           sync.RWMutex
+	currentAgent string
+	intent       string
+	status       models.WorkflowStatus
+}
+
+func (control *workflowControl) setCurrentAgent(agentName string) {
+	control.mu.Lock()
+	control.currentAgent = agentName
+	control.mu.Unlock()
+}
+
+func (control *workflowControl) CurrentAgent() string {
+	control.mu.RLock()
+	defer control.mu.RUnlock()
+	return control.currentAgent
+}
+
+func (control *workflowControl) setProgress(intent string, status models.WorkflowStatus) {
+	control.mu.Lock()
+	control.intent = intent
+	control.status = status
+	control.mu.Unlock()
+}
+
+func (control *workflowControl) progress() (string, models.WorkflowStatus) {
+	control.mu.RLock()
+	defer control.mu.RUnlock()
+	return control.intent, control.status
+}
+
+// trackProgress copies the workflow's intent and status to its control, it must run on the workflow goroutine
+// right after that goroutine changed them
+func (orchestrator *Orchestrator) trackProgress(workflowCtx *models.WorkflowContext) {
+	if control, ok := orchestrator.workflowControls.Load(workflowCtx.ID); ok {
+		control.(*workflowControl).setProgress(workflowCtx.Intent, workflowCtx.Status)
+	}
+}
+
+// ListActiveWorkflows returns every in-flight workflow, oldest first
+func (orchestrator *Orchestrator) ListActiveWorkflows() []models.ActiveWorkflowInfo {
+	now := time.Now()
+	workflows := []models.ActiveWorkflowInfo{}
+
+	orchestrator.activeWorkflows.Range(func(key, value interface{}) bool {
+		workflowCtx := value.(*models.WorkflowContext)
+		info := models.ActiveWorkflowInfo{
+			WorkflowID: workflowCtx.ID,
+			UserID:     workflowCtx.UserID,
+			TenantID:   workflowCtx.TenantID,
+			StartTime:  workflowCtx.StartTime,
+			ElapsedMs:  now.Sub(workflowCtx.StartTime).Milliseconds(),
+		}
+		// a running workflow changes its intent and status, they are read from the control's copy
+		if control, ok := orchestrator.workflowControls.Load(key); ok {
+			intent, status := control.(*workflowControl).progress()
+			info.Intent = intent
+			info.Status = string(status)
+			info.CurrentAgent = control.(*workflowControl).CurrentAgent()
+		} else {
+			info.Intent = workflowCtx.Intent
+			info.Status = string(workflowCtx.Status)
+		}
+		workflows = append(workflows, info)
+		return true
+	})
+
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].StartTime.Before(workflows[j].StartTime)
+	})
+
+	return workflows
+}
+
+// StartDraining stops the orchestrator from accepting new workflows, in-flight ones keep running and queued ones
+// are turned away with ErrWorkflowDraining
+func (orchestrator *Orchestrator) StartDraining() {
+	if orchestrator.draining.CompareAndSwap(false, true) {
+		if orchestrator.drainCh != nil {
+			close(orchestrator.drainCh)
+		}
+		orchestrator.logger.Info("Draining workflows",
+			"active_workflows", orchestrator.GetActiveWorkflowsCount(),
+			"queued_workflows", orchestrator.workflowsQueued.Load())
+	}
+}
+
+func (orchestrator *Orchestrator) IsDraining() bool {
+	return orchestrator.draining.Load()
+}
+
+// Drain waits for in-flight workflows until the context ends, then cancels the stragglers and returns how many were cancelled
+func (orchestrator *Orchestrator) Drain(ctx context.Context) int {
+	orchestrator.StartDraining()
+
+	if orchestrator.waitForWorkflows(ctx) {
+		orchestrator.logger.Info("All in-flight workflows completed during drain")
+		return 0
+	}
+
+	cancelled := 0
+	orchestrator.activeWorkflows.Range(func(key, value interface{}) bool {
+		if err := orchestrator.CancelWorkflow(key.(string)); err == nil {
+			cancelled++
+		}
+		return true
+	})
+	orchestrator.logger.Warn("Grace period elapsed, cancelled remaining workflows", "cancelled", cancelled)
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), drainCancelWait)
+	defer cancel()
+	if !orchestrator.waitForWorkflows(waitCtx) {
+		orchestrator.logger.Warn("Cancelled workflows did not unwind in time", "active_workflows", orchestrator.GetActiveWorkflowsCount())
+	}
+
+	return cancelled
+}
+
+// waitForWorkflows reports whether every active workflow finished, and every admitted or queued one let go of
+// admission control, before the context ended
+func (orchestrator *Orchestrator) waitForWorkflows(ctx context.Context) bool {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if orchestrator.GetActiveWorkflowsCount() == 0 && orchestrator.workflowsInFlight.Load() == 0 &&
+			orchestrator.workflowsQueued.Load() == 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
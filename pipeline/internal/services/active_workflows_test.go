@@ -0,0 +1,107 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"testing"
+	"time"
+)
+
+// startHeldWorkflow runs a news workflow in the background and waits until it is in flight
+func startHeldWorkflow(t *testing.T, workflow *testWorkflow) <-chan *models.WorkflowResponse {
+	t.Helper()
+	responses := make(chan *models.WorkflowResponse, 1)
+	go func() {
+		response, err := workflow.run("workflow-draining")
+		if err != nil {
+			t.Errorf("ExecuteWorkflow() error = %v", err)
+		}
+		responses <- response
+	}()
+
+	waitUntil(t, "workflow is active", func() bool { return workflow.orchestrator.GetActiveWorkflowsCount() > 0 })
+	return responses
+}
+
+func TestDrainLetsInFlightWorkflowsFinish(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	release := workflow.holdAgent(t, "Content Personalizer")
+	responses := startHeldWorkflow(t, workflow)
+
+	drained := make(chan int, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		drained <- workflow.orchestrator.Drain(ctx)
+	}()
+
+	waitUntil(t, "orchestrator is draining", workflow.orchestrator.IsDraining)
+	select {
+	case <-drained:
+		t.Fatal("Drain() returned while a workflow was still in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	release()
+	if cancelled := <-drained; cancelled != 0 {
+		t.Errorf("Drain() cancelled %d workflows, want 0", cancelled)
+	}
+	if response := <-responses; response.Status != string(models.WorkflowStatusCompleted) {
+		t.Errorf("in-flight workflow status = %s, want completed", response.Status)
+	}
+}
+
+func TestDrainCancelsWorkflowsStillRunningAfterTheGracePeriod(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	workflow.holdAgent(t, "Content Personalizer")
+	responses := startHeldWorkflow(t, workflow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if cancelled := workflow.orchestrator.Drain(ctx); cancelled != 1 {
+		t.Errorf("Drain() cancelled %d workflows, want 1", cancelled)
+	}
+
+	if response := <-responses; response.Status != string(models.WorkflowStatusCancelled) {
+		t.Errorf("straggler status = %s, want cancelled", response.Status)
+	}
+	if count := workflow.orchestrator.GetActiveWorkflowsCount(); count != 0 {
+		t.Errorf("%d workflows still active after the drain", count)
+	}
+}
+
+func TestListActiveWorkflowsWhileOneIsRunning(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	release := workflow.holdAgent(t, "Content Personalizer")
+
+	// listing runs alongside the workflow goroutine the whole time, go test -race flags unguarded reads
+	stop := make(chan struct{})
+	listed := make(chan struct{})
+	go func() {
+		defer close(listed)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				workflow.orchestrator.ListActiveWorkflows()
+			}
+		}
+	}()
+	responses := startHeldWorkflow(t, workflow)
+
+	waitUntil(t, "the classified intent is listed", func() bool {
+		workflows := workflow.orchestrator.ListActiveWorkflows()
+		return len(workflows) == 1 && workflows[0].Intent == string(models.IntentNewNewsQuery)
+	})
+	if status := workflow.orchestrator.ListActiveWorkflows()[0].Status; status != string(models.WorkflowStatusPending) {
+		t.Errorf("listed status = %s, want %s", status, models.WorkflowStatusPending)
+	}
+
+	release()
+	if response := <-responses; response.Status != string(models.WorkflowStatusCompleted) {
+		t.Errorf("workflow status = %s, want completed", response.Status)
+	}
+	close(stop)
+	<-listed
+}
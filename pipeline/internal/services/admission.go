@@ -0,0 +1,90 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"time"
+)
+
+// how long clients turned away by admission control are told to wait before retrying
+const workflowRetryAfter = 5 * time.Second
+
+// ErrWorkflowQueueFull is returned when every workflow slot is taken and the wait queue is full as well
+var ErrWorkflowQueueFull = models.NewUnavailableError("WORKFLOW_QUEUE_FULL", "Too many workflows in progress").
+	WithRetryAfter(workflowRetryAfter)
+
+// ErrWorkflowQueueTimeout is returned when a queued workflow did not get a slot within the queue timeout
+var ErrWorkflowQueueTimeout = models.NewUnavailableError("WORKFLOW_QUEUE_TIMEOUT", "Timed out waiting for a workflow slot").
+	WithRetryAfter(workflowRetryAfter)
+
+// ErrWorkflowDraining is returned once the orchestrator drains for shutdown, queued workflows included
+var ErrWorkflowDraining = models.NewUnavailableError("WORKFLOW_DRAINING", "Not accepting new workflows while shutting down").
+	WithRetryAfter(workflowRetryAfter)
+
+// admitWorkflow waits for a free workflow slot, failing fast once MaxQueue workflows are already waiting or the
+// orchestrator is draining. The returned release must be called when the workflow finishes.
+func (orchestrator *Orchestrator) admitWorkflow(ctx context.Context) (func(), error) {
+	release := func() {
+		<-orchestrator.workflowSlots
+		orchestrator.workflowsInFlight.Add(-1)
+	}
+
+	if orchestrator.IsDraining() {
+		orchestrator.workflowsRejected.Add(1)
+		return nil, ErrWorkflowDraining
+	}
+
+	select {
+	case orchestrator.workflowSlots <- struct{}{}:
+		orchestrator.workflowsInFlight.Add(1)
+		return release, nil
+	default:
+	}
+
+	if orchestrator.workflowsQueued.Add(1) > int64(orchestrator.config.Workflow.MaxQueue) {
+		orchestrator.workflowsQueued.Add(-1)
+		orchestrator.workflowsRejected.Add(1)
+		return nil, ErrWorkflowQueueFull
+	}
+	defer orchestrator.workflowsQueued.Add(-1)
+
+	timer := time.NewTimer(orchestrator.config.Workflow.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case orchestrator.workflowSlots <- struct{}{}:
+		// draining may have started while this workflow waited, the slot goes straight back
+		if orchestrator.IsDraining() {
+			<-orchestrator.workflowSlots
+			orchestrator.workflowsRejected.Add(1)
+			return nil, ErrWorkflowDraining
+		}
+		orchestrator.workflowsInFlight.Add(1)
+		return release, nil
+	case <-orchestrator.drainCh:
+		orchestrator.workflowsRejected.Add(1)
+		return nil, ErrWorkflowDraining
+	case <-timer.C:
+		orchestrator.workflowsRejected.Add(1)
+		return nil, ErrWorkflowQueueTimeout
+	case <-ctx.Done():
+		return nil, models.NewTimeoutError("WORKFLOW_QUEUE_CANCELLED", "Request ended while waiting for a workflow slot").WithCause(ctx.Err())
+	}
+}
+
+// MaxWorkflowDuration is the longest ExecuteWorkflow can take, a full wait in the queue followed by a workflow
+// running up to its deadline
+func (orchestrator *Orchestrator) MaxWorkflowDuration() time.Duration {
+	return orchestrator.config.Workflow.QueueTimeout + orchestrator.config.Workflow.Deadline
+}
+
+// AdmissionStats reports the workflows running and waiting for a slot
+func (orchestrator *Orchestrator) AdmissionStats() map[string]interface{} {
+	return map[string]interface{}{
+		"in_flight":       orchestrator.workflowsInFlight.Load(),
+		"queued":          orchestrator.workflowsQueued.Load(),
+		"rejected":        orchestrator.workflowsRejected.Load(),
+		"max_concurrency": cap(orchestrator.workflowSlots),
+		"max_queue":       orchestrator.config.Workflow.MaxQueue,
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAdmissionRejectsWorkflowsBeyondTheLimitAndQueue(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"WORKFLOW_MAX_CONCURRENCY": "1", "WORKFLOW_MAX_QUEUE": "1"})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	release := workflow.holdAgent(t, "Content Personalizer")
+	stat := func(name string) int64 { return workflow.orchestrator.AdmissionStats()[name].(int64) }
+
+	responses := make(chan *models.WorkflowResponse, 2)
+	start := func(id string) {
+		go func() {
+			response, err := workflow.run(id)
+			if err != nil {
+				t.Errorf("ExecuteWorkflow(%s) error = %v", id, err)
+			}
+			responses <- response
+		}()
+	}
+	start("workflow-running")
+	waitUntil(t, "a workflow is running", func() bool { return stat("in_flight") == 1 })
+	start("workflow-queued")
+	waitUntil(t, "a workflow is queued", func() bool { return stat("queued") == 1 })
+
+	_, err := workflow.run("workflow-rejected")
+	if !errors.Is(err, ErrWorkflowQueueFull) {
+		t.Fatalf("ExecuteWorkflow() beyond the limit and queue error = %v, want ErrWorkflowQueueFull", err)
+	}
+	var appErr *models.AppError
+	if !errors.As(err, &appErr) || appErr.RetryAfter == nil {
+		t.Error("rejection does not tell the client when to retry")
+	}
+	if rejected := stat("rejected"); rejected != 1 {
+		t.Errorf("rejected = %d, want 1", rejected)
+	}
+
+	// finishing the running workflow hands its slot to the queued one, then both slots are free again
+	release()
+	for i := 0; i < 2; i++ {
+		if response := <-responses; response.Status != string(models.WorkflowStatusCompleted) {
+			t.Errorf("admitted workflow status = %s, want completed", response.Status)
+		}
+	}
+	if inFlight, queued := stat("in_flight"), stat("queued"); inFlight != 0 || queued != 0 {
+		t.Errorf("in_flight = %d, queued = %d after the workflows finished, want 0", inFlight, queued)
+	}
+	if response, err := workflow.run("workflow-after"); err != nil || response.Status != string(models.WorkflowStatusCompleted) {
+		t.Errorf("ExecuteWorkflow() once capacity was released = %v, want a completed workflow", err)
+	}
+}
+
+func TestAdmissionTimesOutQueuedWorkflows(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"WORKFLOW_MAX_CONCURRENCY": "1", "WORKFLOW_QUEUE_TIMEOUT": "50ms"})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	workflow.holdAgent(t, "Content Personalizer")
+
+	go workflow.run("workflow-running")
+	waitUntil(t, "a workflow is running", func() bool { return workflow.orchestrator.AdmissionStats()["in_flight"].(int64) == 1 })
+
+	if _, err := workflow.run("workflow-waiting"); !errors.Is(err, ErrWorkflowQueueTimeout) {
+		t.Errorf("ExecuteWorkflow() error = %v, want ErrWorkflowQueueTimeout", err)
+	}
+	if queued := workflow.orchestrator.AdmissionStats()["queued"].(int64); queued != 0 {
+		t.Errorf("queued = %d after the timeout, want 0", queued)
+	}
+}
+
+func TestDrainTurnsAwayQueuedAndNewWorkflows(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"WORKFLOW_MAX_CONCURRENCY": "1", "WORKFLOW_QUEUE_TIMEOUT": "30s"})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	release := workflow.holdAgent(t, "Content Personalizer")
+	stat := func(name string) int64 { return workflow.orchestrator.AdmissionStats()[name].(int64) }
+
+	running := make(chan error, 1)
+	go func() {
+		_, err := workflow.run("workflow-running")
+		running <- err
+	}()
+	waitUntil(t, "a workflow is running", func() bool { return stat("in_flight") == 1 })
+
+	queued := make(chan error, 1)
+	go func() {
+		_, err := workflow.run("workflow-queued")
+		queued <- err
+	}()
+	waitUntil(t, "a workflow is queued", func() bool { return stat("queued") == 1 })
+
+	drained := make(chan int, 1)
+	go func() { drained <- workflow.orchestrator.Drain(context.Background()) }()
+
+	// the queued workflow is woken and turned away instead of starting mid-drain
+	if err := <-queued; !errors.Is(err, ErrWorkflowDraining) {
+		t.Errorf("queued ExecuteWorkflow() error = %v, want ErrWorkflowDraining", err)
+	}
+	if _, err := workflow.run("workflow-new"); !errors.Is(err, ErrWorkflowDraining) {
+		t.Errorf("new ExecuteWorkflow() while draining error = %v, want ErrWorkflowDraining", err)
+	}
+	select {
+	case <-drained:
+		t.Fatal("Drain() returned while a workflow was still in flight")
+	default:
+	}
+
+	release()
+	if err := <-running; err != nil {
+		t.Errorf("running ExecuteWorkflow() error = %v, want it to finish", err)
+	}
+	if cancelled := <-drained; cancelled != 0 {
+		t.Errorf("Drain() cancelled %d workflows, want 0", cancelled)
+	}
+	if inFlight, queued := stat("in_flight"), stat("queued"); inFlight != 0 || queued != 0 {
+		t.Errorf("in_flight = %d, queued = %d after the drain, want 0", inFlight, queued)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestAgentsCallGeminiWithTheirConfiguredModel(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{
+		"GEMINI_AGENT_MODELS": "classifier=gemini-lite; keyword_extractor=gemini-lite; summarizer=gemini-pro",
+	})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+
+	if _, err := workflow.run("workflow-agent-models"); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	agents := []struct {
+		name   string
+		marker string
+		want   string
+	}{
+		{name: "classifier", marker: "intent classifier", want: "gemini-lite"},
+		{name: "keyword extractor", marker: "Keyword Extractor", want: "gemini-lite"},
+		{name: "summarizer", marker: "Multimedia News Synthesizer", want: "gemini-pro"},
+		// no override, the global model
+		{name: "persona", marker: "Content Personalizer", want: cfg.Gemini.Model},
+	}
+	calls := workflow.gemini.received()
+	for _, agent := range agents {
+		called := false
+		for _, call := range calls {
+			if !strings.Contains(call.SystemPrompt, agent.marker) {
+				continue
+			}
+			called = true
+			if call.Model != agent.want {
+				t.Errorf("%s called %s, want %s", agent.name, call.Model, agent.want)
+			}
+		}
+		if !called {
+			t.Errorf("%s was never called", agent.name)
+		}
+	}
+}
+
+func TestRequestModelOverridesTheAgentModel(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"GEMINI_AGENT_MODELS": "summarizer=gemini-pro"})
+	_, service := newFakeGemini(t, cfg.Gemini, nil)
+
+	tests := []struct {
+		name  string
+		agent string
+		model string
+		want  string
+	}{
+		{name: "agent override", agent: "summarizer", want: "gemini-pro"},
+		{name: "no override", agent: "persona", want: cfg.Gemini.Model},
+		{name: "request model", agent: "summarizer", model: "gemini-experimental", want: "gemini-experimental"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &GenerationRequest{Model: tt.model}
+			service.applyAgentSampling(tt.agent, req)
+			if got := service.requestModel(req); got != tt.want {
+				t.Errorf("requestModel() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,22 +4,37 @@ import (
 	"Infiya-ai-pipeline/internal/config"
 	"Infiya-ai-pipeline/internal/models"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/genai"
+	"net/http"
 )
 
 type GeminiService struct {
-	client *genai.Client
-	config config.GeminiConfig
-	logger *logger.Logger
+	client    *genai.Client
+	config    config.GeminiConfig
+	logger    *logger.Logger
+	prompts   *PromptRegistry
+	semaphore chan struct{}
+	inFlight  atomic.Int64
+	queued    atomic.Int64
+	// per agent settings, only the model is consulted here
+	agentConfigs map[string]models.AgentConfig
 }
 
+// ErrGeminiQueueFull is returned without retrying when too many generation calls are already waiting
+var ErrGeminiQueueFull = models.NewRateLimitError("GEMINI_QUEUE_FULL", "Too many pending Gemini requests", time.Second)
+
+// ErrSummaryTruncated comes back with the partial summary when generation hit the token limit
+var ErrSummaryTruncated = errors.New("summary truncated at max tokens")
+
 type GenerationRequest struct {
 	Prompt          string
 	MaxTokens       int32
@@ -30,21 +45,98 @@ type GenerationRequest struct {
 	TopK            *float32
 	DisableThinking bool
 	ResponseFormat  string
+	Seed            *int32
+	// user facing generations carry the custom instruction in their system role and the workflow profile's sampling
+	UserFacing bool
+	// the agent making the call, set by applyAgentSampling and used to pick how an oversized prompt is trimmed
+	Agent string
+	// empty generates with the configured model, applyAgentSampling fills in the agent's own model
+	Model string
+}
+
+// GenerationOverrides are per request sampling overrides used to reproduce a generation while debugging
+type GenerationOverrides struct {
+	Temperature *float32 `json:"temperature,omitempty"`
+	Seed        *int32   `json:"seed,omitempty"`
+}
+
+type generationOverridesKey struct{}
+
+// WithGenerationOverrides attaches overrides to ctx so every agent call made with it uses them
+func WithGenerationOverrides(ctx context.Context, overrides *GenerationOverrides) context.Context {
+	if overrides == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, generationOverridesKey{}, overrides)
+}
+
+func generationOverridesFromContext(ctx context.Context) *GenerationOverrides {
+	overrides, _ := ctx.Value(generationOverridesKey{}).(*GenerationOverrides)
+	return overrides
+}
+
+// ParseGenerationOverrides reads the optional "temperature" and "seed" keys from request metadata, returns nil when neither is set
+func ParseGenerationOverrides(metadata map[string]any) (*GenerationOverrides, error) {
+	overrides := &GenerationOverrides{}
+
+	if raw, ok := metadata["temperature"]; ok && raw != nil {
+		temperature, ok := raw.(float64)
+		if !ok {
+			return nil, fmt.Errorf("temperature override must be a number")
+		}
+		if temperature < 0 || temperature > 2 {
+			return nil, fmt.Errorf("temperature override must be between 0 and 2, got %v", temperature)
+		}
+		value := float32(temperature)
+		overrides.Temperature = &value
+	}
+
+	if raw, ok := metadata["seed"]; ok && raw != nil {
+		seed, ok := raw.(float64)
+		if !ok || seed != float64(int32(seed)) {
+			return nil, fmt.Errorf("seed override must be a 32 bit integer")
+		}
+		value := int32(seed)
+		overrides.Seed = &value
+	}
+
+	if overrides.Temperature == nil && overrides.Seed == nil {
+		return nil, nil
+	}
+
+	return overrides, nil
 }
 
 type GenerationResponse struct {
-	Content        string
+	Content string
+	// thinking tokens are billed as output and counted there
+	InputTokens    int
+	OutputTokens   int
 	TokensUsed     int
 	FinishReason   string
 	ProcessingTime time.Duration
 }
 
+// StructuredSummary is the machine readable summary returned for the "json" summary format
+type StructuredSummary struct {
+	Summary   string   `json:"summary"`
+	KeyPoints []string `json:"key_points"`
+}
+
 type QueryEnhancementResult struct {
 	OriginalQuery  string        `json:"original_query"`
 	EnhancedQuery  string        `json:"enhanced_query"`
 	ProcessingTime time.Duration `json:"processing_time"`
 }
 
+// QueryProcessingResult is the combined query enhancement and keyword extraction output
+type QueryProcessingResult struct {
+	OriginalQuery  string        `json:"original_query"`
+	EnhancedQuery  string        `json:"enhanced_query"`
+	Keywords       []string      `json:"keywords"`
+	ProcessingTime time.Duration `json:"processing_time"`
+}
+
 func NewGeminiService(config config.GeminiConfig, log *logger.Logger) (*GeminiService, error) {
 	if config.APIKey == "" {
 		return nil, errors.New("Gemini API key required")
@@ -53,18 +145,33 @@ func NewGeminiService(config config.GeminiConfig, log *logger.Logger) (*GeminiSe
 	ctx := context.Background()
 
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  config.APIKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     config.APIKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: &http.Client{Transport: tracing.Transport(nil)},
 	})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
+	prompts, err := NewPromptRegistry(config.PromptDir, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prompt templates: %w", err)
+	}
+
+	maxConcurrency := config.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+
 	service := &GeminiService{
-		client: client,
-		config: config,
-		logger: log,
+		client:    client,
+		config:    config,
+		logger:    log,
+		prompts:   prompts,
+		semaphore: make(chan struct{}, maxConcurrency),
+
+		agentConfigs: models.ApplyAgentModels(models.DefaultAgentConfigs(), config.AgentModels),
 	}
 
 	// err = service.testConnection()
@@ -76,6 +183,10 @@ func NewGeminiService(config config.GeminiConfig, log *logger.Logger) (*GeminiSe
 		"model", config.Model,
 		"Max_tokens ", config.MaxTokens,
 		"Temperature ", config.Temperature,
+		"max_concurrency", maxConcurrency,
+		"max_queue", config.MaxQueue,
+		"agent_sampling_overrides", len(config.AgentSampling),
+		"agent_model_overrides", len(config.AgentModels),
 	)
 
 	return service, nil
@@ -105,6 +216,13 @@ func (service *GeminiService) testConnection() error {
 }
 
 func (service *GeminiService) GenerateContent(ctx context.Context, request *GenerationRequest) (*GenerationResponse, error) {
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		key := hashContent([]byte(request.SystemRole + request.Context + request.Prompt))
+		return recordCall(ctx, recorder, "gemini:"+request.Agent, key, func(ctx context.Context) (*GenerationResponse, error) {
+			return service.GenerateContent(ctx, request)
+		})
+	}
+
 	startTime := time.Now()
 
 	service.logger.LogService("AI", "generate_content",
@@ -112,7 +230,9 @@ func (service *GeminiService) GenerateContent(ctx context.Context, request *Gene
 			"prompt_length": len(request.Prompt),
 			"max_tokens":    request.MaxTokens,
 			"temperature":   request.Temperature,
-			"model":         service.config.Model,
+			"model":         service.requestModel(request),
+			"agent":         request.Agent,
+			"overrides":     generationOverridesFromContext(ctx),
 		}, nil)
 
 	var response *GenerationResponse
@@ -120,7 +240,7 @@ func (service *GeminiService) GenerateContent(ctx context.Context, request *Gene
 
 	for attempt := 1; attempt <= service.config.MaxRetries; attempt++ {
 		response, err = service.makeGenerationRequest(ctx, request)
-		if err == nil {
+		if err == nil || errors.Is(err, ErrGeminiQueueFull) || errors.Is(err, ErrPromptTooLarge) {
 			break
 		}
 
@@ -162,15 +282,101 @@ func (service *GeminiService) GenerateContent(ctx context.Context, request *Gene
 
 }
 
+// applyAgentSampling lets configured sampling parameters replace an agent's built in ones
+func (service *GeminiService) applyAgentSampling(agentName string, req *GenerationRequest) {
+	req.Agent = agentName
+	if req.Model == "" {
+		req.Model = service.agentConfigs[agentName].Model
+	}
+	sampling, ok := service.config.AgentSampling[agentName]
+	if !ok {
+		return
+	}
+
+	if sampling.Temperature != nil {
+		req.Temperature = sampling.Temperature
+	}
+	if sampling.TopP != nil {
+		req.TopP = sampling.TopP
+	}
+	if sampling.TopK != nil {
+		req.TopK = sampling.TopK
+	}
+	if sampling.MaxTokens > 0 {
+		req.MaxTokens = sampling.MaxTokens
+	}
+}
+
+// requestModel is the model a request generates with, its own or the configured one
+func (service *GeminiService) requestModel(req *GenerationRequest) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return service.config.Model
+}
+
+// acquireSlot waits for a free generation slot, failing fast once MaxQueue callers are already waiting
+func (service *GeminiService) acquireSlot(ctx context.Context) (func(), error) {
+	release := func() {
+		<-service.semaphore
+		service.inFlight.Add(-1)
+	}
+
+	select {
+	case service.semaphore <- struct{}{}:
+		service.inFlight.Add(1)
+		return release, nil
+	default:
+	}
+
+	if service.queued.Add(1) > int64(service.config.MaxQueue) {
+		service.queued.Add(-1)
+		return nil, ErrGeminiQueueFull
+	}
+	defer service.queued.Add(-1)
+
+	select {
+	case service.semaphore <- struct{}{}:
+		service.inFlight.Add(1)
+		return release, nil
+	case <-ctx.Done():
+		return nil, models.NewTimeoutError("GEMINI_TIMEOUT", "Timed out waiting for a Gemini slot").WithCause(ctx.Err())
+	}
+}
+
+// ConcurrencyStats reports the generation calls currently running and waiting for a slot
+func (service *GeminiService) ConcurrencyStats() map[string]interface{} {
+	return map[string]interface{}{
+		"in_flight":       service.inFlight.Load(),
+		"queued":          service.queued.Load(),
+		"max_concurrency": cap(service.semaphore),
+		"max_queue":       service.config.MaxQueue,
+	}
+}
+
 func (service *GeminiService) makeGenerationRequest(ctx context.Context, req *GenerationRequest) (*GenerationResponse, error) {
+	req, err := service.guardPromptSize(req)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := service.acquireSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
 	genCtx, cancel := context.WithTimeout(ctx, service.config.Timeout)
 	defer cancel()
 
 	config := &genai.GenerateContentConfig{}
 
-	if req.SystemRole != "" {
-		config.SystemInstruction = genai.NewContentFromText(req.SystemRole, genai.RoleUser)
+	systemRole := req.SystemRole
+	if req.UserFacing {
+		systemRole = appendCustomInstruction(systemRole, customInstructionFromContext(ctx))
+	}
+	if systemRole != "" {
+		config.SystemInstruction = genai.NewContentFromText(systemRole, genai.RoleUser)
 	}
 
 	if req.Temperature != nil {
@@ -187,6 +393,29 @@ func (service *GeminiService) makeGenerationRequest(ctx context.Context, req *Ge
 		config.MaxOutputTokens = maxTokens
 	}
 
+	if req.Seed != nil {
+		config.Seed = req.Seed
+	}
+
+	if profile := workflowProfileFromContext(ctx); profile != nil && req.UserFacing {
+		if profile.Temperature != nil {
+			config.Temperature = profile.Temperature
+		}
+		if profile.MaxTokens > 0 {
+			config.MaxOutputTokens = profile.MaxTokens
+		}
+	}
+
+	// debugging overrides win over the per agent defaults
+	if overrides := generationOverridesFromContext(ctx); overrides != nil {
+		if overrides.Temperature != nil {
+			config.Temperature = overrides.Temperature
+		}
+		if overrides.Seed != nil {
+			config.Seed = overrides.Seed
+		}
+	}
+
 	if req.TopP != nil {
 		config.TopP = req.TopP
 	}
@@ -219,7 +448,8 @@ func (service *GeminiService) makeGenerationRequest(ctx context.Context, req *Ge
 		content = genai.Text(req.Prompt)
 	}
 
-	result, err := service.client.Models.GenerateContent(genCtx, service.config.Model, content, config)
+	model := service.requestModel(req)
+	result, err := service.client.Models.GenerateContent(genCtx, model, content, config)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate ai/gemini request: %w", err)
@@ -238,11 +468,19 @@ func (service *GeminiService) makeGenerationRequest(ctx context.Context, req *Ge
 		}
 	}
 
-	tokensUsed := len(req.Prompt)/4 + len(text)/4
+	// the four characters per token estimate only covers a response without usage metadata
+	inputTokens, outputTokens := len(req.Prompt)/4, len(text)/4
+	if usage := result.UsageMetadata; usage != nil && usage.PromptTokenCount > 0 {
+		inputTokens = int(usage.PromptTokenCount)
+		outputTokens = int(usage.CandidatesTokenCount + usage.ThoughtsTokenCount)
+	}
+	recordGeneration(ctx, model, inputTokens, outputTokens)
 
 	response := &GenerationResponse{
 		Content:      text,
-		TokensUsed:   tokensUsed,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		TokensUsed:   inputTokens + outputTokens,
 		FinishReason: string(candidate.FinishReason),
 	}
 
@@ -274,6 +512,7 @@ func (service *GeminiService) EnhanceQueryForSearch(ctx context.Context, query s
 		MaxTokens:       1000,
 		DisableThinking: false,
 	}
+	service.applyAgentSampling("query_enhancer", req)
 
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
@@ -342,6 +581,7 @@ func (service *GeminiService) ClassifyIntent(ctx context.Context, query string,
 		MaxTokens:       2500,
 		DisableThinking: false,
 	}
+	service.applyAgentSampling("classifier", req)
 
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
@@ -404,6 +644,7 @@ func (service *GeminiService) ClassifyIntentWithContext(ctx context.Context, que
 		MaxTokens:       5120,
 		DisableThinking: false,
 	}
+	service.applyAgentSampling("classifier", req)
 
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
@@ -421,6 +662,7 @@ func (service *GeminiService) ClassifyIntentWithContext(ctx context.Context, que
 		"intent":                 result.Intent,
 		"confidence":             result.Confidence,
 		"referenced_topic":       result.ReferencedTopic,
+		"candidates":             result.Candidates,
 		"conversation_exchanges": len(conversationHistory),
 		"tokens_used":            resp.TokensUsed,
 	}, nil)
@@ -489,7 +731,10 @@ func (service *GeminiService) GenerateContextualResponse(ctx context.Context, qu
 		SystemRole:      "You are Infiya, a knowledgeable AI news assistant providing contextual follow-up responses",
 		MaxTokens:       2048,
 		DisableThinking: false,
+
+		UserFacing: true,
 	}
+	service.applyAgentSampling("chitchat", req)
 
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
@@ -527,6 +772,7 @@ func (service *GeminiService) ExtractKeyWords(ctx context.Context, query string,
 		MaxTokens:       300,
 		DisableThinking: false,
 	}
+	service.applyAgentSampling("keyword_extractor", req)
 
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
@@ -549,6 +795,77 @@ func (service *GeminiService) ExtractKeyWords(ctx context.Context, query string,
 	return keywords, nil
 }
 
+// Combined Query Processing agent, enhancement and keyword extraction in one JSON mode call
+func (service *GeminiService) EnhanceAndExtractKeywords(ctx context.Context, query string, context map[string]interface{}) (*QueryProcessingResult, error) {
+	start := time.Now()
+
+	prompt := service.buildQueryProcessingPrompt(query, context)
+
+	req := &GenerationRequest{
+		Prompt:          prompt,
+		Temperature:     &[]float32{0.2}[0],
+		SystemRole:      "You are an Expert Query Expansion and Keyword Extraction Specialist for news search",
+		MaxTokens:       1000,
+		DisableThinking: false,
+		ResponseFormat:  "application/json",
+	}
+	service.applyAgentSampling("query_processor", req)
+
+	resp, err := service.GenerateContent(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("Query Processing failed: %w", err)
+	}
+
+	result, err := parseQueryProcessingResponse(resp.Content, query)
+	if err != nil {
+		return nil, fmt.Errorf("Query Processing response invalid: %w", err)
+	}
+	result.ProcessingTime = time.Since(start)
+
+	service.logger.LogAgent("", "query_processor", "enhance_and_extract", result.ProcessingTime, map[string]interface{}{
+		"original_query": query,
+		"enhanced_query": result.EnhancedQuery,
+		"keywords_count": len(result.Keywords),
+		"tokens_used":    resp.TokensUsed,
+	}, nil)
+
+	return result, nil
+}
+
+// parseQueryProcessingResponse falls back to the original query when only keywords come back, no keywords is an error
+func parseQueryProcessingResponse(content string, originalQuery string) (*QueryProcessingResult, error) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	var parsed QueryProcessingResult
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &parsed); err != nil {
+		return nil, err
+	}
+
+	result := &QueryProcessingResult{
+		OriginalQuery: originalQuery,
+		EnhancedQuery: strings.TrimSpace(parsed.EnhancedQuery),
+		Keywords:      make([]string, 0, len(parsed.Keywords)),
+	}
+	if result.EnhancedQuery == "" {
+		result.EnhancedQuery = originalQuery
+	}
+
+	for _, keyword := range parsed.Keywords {
+		keyword = strings.Trim(strings.TrimSpace(keyword), "\"'.,!?;:")
+		if len(keyword) > 2 {
+			result.Keywords = append(result.Keywords, keyword)
+		}
+	}
+	if len(result.Keywords) == 0 {
+		return nil, fmt.Errorf("no keywords returned")
+	}
+
+	return result, nil
+}
+
 func (service *GeminiService) parseKeywordsResponse(response string) []string {
 	keywords := []string{}
 	if response == "" {
@@ -590,21 +907,12 @@ func (service *GeminiService) GetRelevantArticles(ctx context.Context, articles
 		return []models.NewsArticle{}, nil
 	}
 
-	prompt := service.buildRelevancyAgentPrompt(articles, context)
+	req := service.relevancyRequest(articles, context)
 
 	fmt.Println("RelevantArticles prompt : ")
-	fmt.Println(prompt)
+	fmt.Println(req.Prompt)
 	fmt.Println()
 
-	req := &GenerationRequest{
-		Prompt:          prompt,
-		Temperature:     &[]float32{0.3}[0],
-		SystemRole:      "You are an expert news relevancy evaluator. Analyze articles and return only the most relevant ones in the specified JSON format.",
-		MaxTokens:       8192,
-		DisableThinking: true,
-		ResponseFormat:  "application/json",
-	}
-
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("relevancy evaluation failed: %w", err)
@@ -631,7 +939,44 @@ func (service *GeminiService) GetRelevantArticles(ctx context.Context, articles
 	return relevantArticles, nil
 }
 
-func (service *GeminiService) parseRelevantArticlesResponse(response string, originalArticles []models.NewsArticle) ([]models.NewsArticle, error) {
+func (service *GeminiService) relevancyRequest(articles []models.NewsArticle, context map[string]interface{}) *GenerationRequest {
+	req := &GenerationRequest{
+		Prompt:          service.buildRelevancyAgentPrompt(articles, context),
+		Temperature:     &[]float32{0.3}[0],
+		SystemRole:      "You are an expert news relevancy evaluator. Analyze articles and return only the most relevant ones in the specified JSON format.",
+		MaxTokens:       8192,
+		DisableThinking: true,
+		ResponseFormat:  "application/json",
+	}
+	service.applyAgentSampling("relevancy", req)
+	return req
+}
+
+type relevantArticleItem struct {
+	ID             int     `json:"id"`
+	Title          string  `json:"title"`
+	URL            string  `json:"url"`
+	Source         string  `json:"source"`
+	Author         string  `json:"author"`
+	PublishedAt    string  `json:"published_at"`
+	Description    string  `json:"description"`
+	Content        string  `json:"content"`
+	ImageURL       string  `json:"image_url"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
+
+type relevancyResponse struct {
+	RelevantArticles  []relevantArticleItem `json:"relevant_articles"`
+	EvaluationSummary struct {
+		TotalEvaluated   string  `json:"total_evaluated"`
+		RelevantFound    string  `json:"relevant_found"`
+		AverageRelevancy float64 `json:"average_relevancy"`
+		ThresholdUsed    float64 `json:"threshold_used"`
+	} `json:"evaluation_summary"`
+}
+
+// decodeRelevancyResponse parses the relevancy agent's JSON, item ids index the articles listed in the prompt
+func decodeRelevancyResponse(response string) (*relevancyResponse, error) {
 	response = strings.TrimSpace(response)
 
 	if strings.HasPrefix(response, "```json") {
@@ -644,33 +989,18 @@ func (service *GeminiService) parseRelevantArticlesResponse(response string, ori
 		response = strings.TrimSpace(response)
 	}
 
-	type RelevantArticleResponse struct {
-		ID             int     `json:"id"`
-		Title          string  `json:"title"`
-		URL            string  `json:"url"`
-		Source         string  `json:"source"`
-		Author         string  `json:"author"`
-		PublishedAt    string  `json:"published_at"`
-		Description    string  `json:"description"`
-		Content        string  `json:"content"`
-		ImageURL       string  `json:"image_url"`
-		RelevanceScore float64 `json:"relevance_score"`
-	}
-
-	type RelevancyResponse struct {
-		RelevantArticles  []RelevantArticleResponse `json:"relevant_articles"`
-		EvaluationSummary struct {
-			TotalEvaluated   string  `json:"total_evaluated"`
-			RelevantFound    string  `json:"relevant_found"`
-			AverageRelevancy float64 `json:"average_relevancy"`
-			ThresholdUsed    float64 `json:"threshold_used"`
-		} `json:"evaluation_summary"`
-	}
-
-	var parsedResponse RelevancyResponse
+	var parsedResponse relevancyResponse
 	if err := json.Unmarshal([]byte(response), &parsedResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse Json response: %w", err)
 	}
+	return &parsedResponse, nil
+}
+
+func (service *GeminiService) parseRelevantArticlesResponse(response string, originalArticles []models.NewsArticle) ([]models.NewsArticle, error) {
+	parsedResponse, err := decodeRelevancyResponse(response)
+	if err != nil {
+		return nil, err
+	}
 
 	var relevantArticles []models.NewsArticle
 	for _, item := range parsedResponse.RelevantArticles {
@@ -744,18 +1074,47 @@ func (service *GeminiService) calculateAverageRelevance(articles []models.NewsAr
 
 }
 
+// emptyResultMessages are the honest "nothing found" notices, keyed by ISO 639-1 language code
+var emptyResultMessages = map[string]string{
+	"en": "I couldn't find any news articles or videos about this from the last month. Try rephrasing your question, using broader terms, or asking about a wider timeframe.",
+	"es": "No encontré artículos ni videos de noticias sobre esto en el último mes. Intenta reformular tu pregunta, usar términos más generales o ampliar el periodo de tiempo.",
+	"fr": "Je n'ai trouvé aucun article ni aucune vidéo d'actualité à ce sujet au cours du dernier mois. Essayez de reformuler votre question, d'utiliser des termes plus généraux ou d'élargir la période.",
+	"de": "Ich habe im letzten Monat keine Nachrichtenartikel oder Videos dazu gefunden. Formuliere deine Frage um, verwende allgemeinere Begriffe oder wähle einen größeren Zeitraum.",
+	"pt": "Não encontrei artigos nem vídeos de notícias sobre isso no último mês. Tente reformular sua pergunta, usar termos mais amplos ou ampliar o período.",
+	"it": "Non ho trovato articoli né video di notizie su questo argomento nell'ultimo mese. Prova a riformulare la domanda, usare termini più generali o ampliare il periodo.",
+	"hi": "मुझे पिछले एक महीने में इसके बारे में कोई समाचार लेख या वीडियो नहीं मिला। अपना प्रश्न दोबारा लिखें, व्यापक शब्दों का उपयोग करें, या लंबी समय-सीमा के बारे में पूछें।",
+}
+
+// EmptyResultSummary returns the localized notice used when no articles or videos were found, in the requested summary format
+func EmptyResultSummary(language, format string) string {
+	message, ok := emptyResultMessages[strings.ToLower(language)]
+	if !ok {
+		message = emptyResultMessages["en"]
+	}
+
+	if models.SummaryFormat(format) == models.SummaryFormatJSON {
+		structured, _ := json.Marshal(StructuredSummary{Summary: message, KeyPoints: []string{}})
+		return string(structured)
+	}
+	return message
+}
+
 // Summarization Agent
-func (service *GeminiService) SummarizeContent(ctx context.Context, query string, allContent []string) (string, error) {
+// strictSources forbids the model from supplementing thin coverage with its own training knowledge,
+// citations numbers the sources and asks for inline [n] markers
+func (service *GeminiService) SummarizeContent(ctx context.Context, query string, allContent []string, format string, links []MediaLink, strictSources bool, citations bool) (string, error) {
 	if len(allContent) == 0 {
-		return "No news articles or videos were found within the last one month", nil
+		return EmptyResultSummary("", format), nil
 	}
 
-	currentDate := time.Now().Format("2006-01-02")
+	locale := searchLocaleFromContext(ctx)
+	currentDate := time.Now().In(locale.Location()).Format("2006-01-02")
 
 	// Separate articles and videos from the combined content
 	articles, videos := service.separateContentTypes(allContent)
 
-	prompt := service.buildMultimediaSummarizationPrompt(query, articles, videos, currentDate)
+	prompt := service.buildMultimediaSummarizationPrompt(query, articles, videos, currentDate, format, locale,
+		responseLanguageFromContext(ctx), links, strictSources, citations)
 
 	fmt.Println("Multimedia Summarizing prompt")
 	fmt.Println(prompt)
@@ -767,6 +1126,13 @@ func (service *GeminiService) SummarizeContent(ctx context.Context, query string
 		SystemRole:      "You are an Expert Multimedia News Synthesizer specializing in both articles and video content",
 		MaxTokens:       8192,
 		DisableThinking: false,
+
+		UserFacing: true,
+	}
+	service.applyAgentSampling("summarizer", req)
+
+	if models.SummaryFormat(format) == models.SummaryFormatJSON {
+		req.ResponseFormat = "application/json"
 	}
 
 	resp, err := service.GenerateContent(ctx, req)
@@ -785,11 +1151,48 @@ func (service *GeminiService) SummarizeContent(ctx context.Context, query string
 		"total_content": len(allContent),
 		"tokens_used":   resp.TokensUsed,
 		"summary":       resp.Content,
+		"format":        format,
 	}, nil)
 
+	if models.SummaryFormat(format) == models.SummaryFormatJSON {
+		structured, err := ParseStructuredSummary(resp.Content)
+		if err != nil {
+			return "", fmt.Errorf("Multimedia Summarize Content returned invalid JSON : %w", err)
+		}
+		normalized, _ := json.Marshal(structured)
+		return string(normalized), nil
+	}
+
+	if resp.FinishReason == string(genai.FinishReasonMaxTokens) {
+		return resp.Content, ErrSummaryTruncated
+	}
+
 	return resp.Content, nil
 }
 
+// ParseStructuredSummary decodes a JSON format summary, tolerating a markdown code fence around it
+func ParseStructuredSummary(content string) (*StructuredSummary, error) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	var structured StructuredSummary
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &structured); err != nil {
+		return nil, err
+	}
+
+	if structured.Summary == "" && len(structured.KeyPoints) == 0 {
+		return nil, fmt.Errorf("structured summary is empty")
+	}
+
+	if structured.KeyPoints == nil {
+		structured.KeyPoints = []string{}
+	}
+
+	return &structured, nil
+}
+
 // Helper function to separate articles and videos
 func (service *GeminiService) separateContentTypes(allContent []string) ([]string, []string) {
 	var articles []string
@@ -809,116 +1212,46 @@ func (service *GeminiService) separateContentTypes(allContent []string) ([]strin
 	return articles, videos
 }
 
-// Enhanced multimedia summarization prompt
-func (service *GeminiService) buildMultimediaSummarizationPrompt(query string, articles []string, videos []string, currentDate string) string {
-	// Process articles (limit to 5 for token efficiency)
-	articlesText := ""
-	articleCount := len(articles)
-	if articleCount > 5 {
-		articleCount = 5
-	}
-
-	for i := 0; i < articleCount; i++ {
-		articlesText += fmt.Sprintf("📰 Article %d:\n%s\n\n", i+1, articles[i])
-	}
-
-	// Process videos (limit to 8 for token efficiency)
-	videosText := ""
-	videoCount := len(videos)
-	if videoCount > 8 {
-		videoCount = 8
-	}
+// summaryFormatInstruction returns the output section of the summarization prompt for the requested format
+func summaryFormatInstruction(format string, strictSources bool) string {
+	switch models.SummaryFormat(format) {
+	case models.SummaryFormatProse:
+		return `🎯 OUTPUT FORMAT:
+Write the summary as plain flowing prose in 3-5 paragraphs. Do not use headings, numbered sections or bullet points.
+Open with the direct answer to the user's question, then weave in key details, context and video insights naturally.
+`
+	case models.SummaryFormatBullets:
+		return `🎯 OUTPUT FORMAT:
+Write the summary as 5-8 tight bullet points, one fact or insight per bullet, each under 25 words.
+The first bullet must directly answer the user's question. No headings, no introduction and no closing paragraph.
+`
+	case models.SummaryFormatTLDR:
+		return `🎯 OUTPUT FORMAT:
+Write a TL;DR of at most 3 sentences that directly answers the user's question with the single most important facts.
+No headings, no bullet points and no background beyond what the answer needs.
+`
+	case models.SummaryFormatJSON:
+		return `🎯 OUTPUT FORMAT:
+Respond ONLY with a JSON object, no markdown and no text outside the JSON:
+{
+    "summary": "2-3 sentence direct answer to the user's question",
+    "key_points": ["one fact or insight per entry, 3-8 entries"]
+}
+`
+	default:
+		if strictSources {
+			return `🎯 OUTPUT FORMAT:
+Provide a complete, structured multimedia summary that directly answers the user's question using only the provided articles and videos. Maintain transparency about information sources and say clearly where coverage is insufficient.
 
-	for i := 0; i < videoCount; i++ {
-		videosText += fmt.Sprintf("🎥 Video %d:\n%s\n\n", i+1, videos[i])
-	}
-
-	return fmt.Sprintf(`You are an expert multimedia news synthesizer that creates comprehensive, query-focused summaries using articles, videos, and relevant knowledge.
-
----
-🎯 USER QUERY ANALYSIS:
-"%s"
-
-📰 SOURCE ARTICLES (Past 30 days): %d articles
-%s
-
-🎥 SOURCE VIDEOS (Past 30 days): %d videos
-%s
-
-📅 CURRENT DATE: %s
-
----
-🔍 CRITICAL MULTIMEDIA INSTRUCTIONS:
-
-**STEP 1: QUERY INTENT ANALYSIS**
-- Identify the core question type: WHY (causes/reasons), WHAT (facts/events), HOW (process/method), WHEN (timeline), WHERE (location), WHO (people/entities)
-- Determine if the user wants: Explanation, Analysis, Comparison, Timeline, Background, or Implications
-
-**STEP 2: MULTIMEDIA INFORMATION SYNTHESIS STRATEGY**
-- **PRIMARY SOURCES**: Use information from provided articles and videos when available
-- **CROSS-MEDIA VALIDATION**: When both articles and videos cover the same topic, cross-reference for completeness and accuracy
-- **MULTIMEDIA PERSPECTIVES**: Leverage unique strengths of each medium:
-  - **Articles**: Detailed analysis, quotes, statistics, comprehensive background
-  - **Videos**: Visual evidence, expert interviews, real-time footage, public reactions, demonstrations
-- **KNOWLEDGE SUPPLEMENT**: If multimedia sources are insufficient but you have relevant knowledge, use it to provide complete context
-- **SOURCE TRANSPARENCY**: Clearly distinguish between:
-  - Article information: "According to news reports..." or "Articles indicate..."
-  - Video content: "Video coverage shows..." or "As seen in video reports..."
-  - Combined sources: "Both articles and videos confirm..." or "While articles report [X], videos reveal [Y]..."
-  - Your knowledge: "Based on established information..." or "Historically, this occurred because..."
-
-**STEP 3: MULTIMEDIA RESPONSE APPROACH**
-For WHY questions: 
-- Use articles for detailed analysis and expert opinions
-- Use videos for visual evidence and expert interviews
-- Combine: "Articles explain the underlying causes as [X], while video interviews with experts highlight [Y]"
-
-For WHAT questions:
-- Articles for comprehensive facts and statistics
-- Videos for real-time developments and visual confirmation
-- Structure: Current facts from both sources + necessary context
-
-For HOW questions:
-- Articles for step-by-step explanations and background processes
-- Videos for demonstrations and visual examples
-- Integrate: "The process involves [from articles], as demonstrated in video coverage showing [specific examples]"
-
-For WHEN questions:
-- Use both for timeline construction
-- Videos often provide real-time updates and breaking developments
-- Articles provide detailed chronological analysis
-
-For WHO/WHERE questions:
-- Articles for comprehensive background and detailed profiles
-- Videos for visual identification, interviews, and location footage
-
-**STEP 4: MULTIMEDIA SYNTHESIS REQUIREMENTS**
-1. **Direct Answer First**: Open with information that directly addresses the query using the best multimedia evidence
-2. **Cross-Media Integration**: Seamlessly weave together insights from articles and videos
-3. **Visual Context**: When videos provide visual evidence, mention it: "Video footage confirms..." or "As captured in video reports..."
-4. **Expert Voices**: Highlight when videos include expert interviews or official statements
-5. **Engagement Indicators**: Consider video metrics (views, channels) as indicators of story significance
-6. **Factual Accuracy**: Prioritize information confirmed by multiple sources across both media types
-7. **Specific Details**: Include names, dates, numbers, locations, and visual evidence from both sources
-8. **Context Integration**: Blend recent multimedia sources with necessary background knowledge
-9. **Gap Acknowledgment**: If neither articles, videos, nor your knowledge fully answer the query, state limitations clearly
-
-**STEP 5: MULTIMEDIA QUALITY CONTROL**
-- Ensure the first paragraph directly answers the user's question using the best multimedia evidence
-- When using knowledge beyond provided sources, make it clear and distinguish the source
-- Present conflicting information transparently, especially when articles and videos present different angles
-- Prioritize recent video content for breaking news and real-time developments
-- Use article content for in-depth analysis and comprehensive background
-
-**STEP 6: TEMPORAL AND PLATFORM AWARENESS**
-- Videos often contain more recent or real-time information
-- Articles provide deeper analysis and more comprehensive context
-- Consider video publication dates and view counts as relevance indicators
-- Acknowledge when query references very recent developments not covered in available sources
-- For ongoing situations: Use videos for latest updates, articles for comprehensive analysis
-
----
-🎯 OUTPUT FORMAT:
+**RESPONSE STRUCTURE:**
+1. **Direct Answer** (using best available multimedia evidence)
+2. **Key Details** (cross-referenced from articles and videos)
+3. **Context & Background** (only as reported in the sources)
+4. **Visual/Video Insights** (unique perspectives from video content)
+5. **Coverage Gaps** (what the sources do not answer)
+`
+		}
+		return `🎯 OUTPUT FORMAT:
 Provide a complete, structured multimedia summary that directly answers the user's question by intelligently synthesizing information from articles, videos, and relevant knowledge. Maintain transparency about information sources and acknowledge any coverage limitations.
 
 **RESPONSE STRUCTURE:**
@@ -927,34 +1260,99 @@ Provide a complete, structured multimedia summary that directly answers the user
 3. **Context & Background** (supplemented with knowledge when needed)
 4. **Visual/Video Insights** (unique perspectives from video content)
 5. **Analysis** (synthesized understanding from all sources)
+`
+	}
+}
+
+// Enhanced multimedia summarization prompt
+func (service *GeminiService) buildMultimediaSummarizationPrompt(query string, articles []string, videos []string, currentDate string, format string, locale SearchLocale, language string, links []MediaLink, strictSources bool, citations bool) string {
+	// Process articles (limited for token efficiency)
+	articlesText := ""
+	articleCount := min(len(articles), summaryArticleLimit)
+
+	for i := 0; i < articleCount; i++ {
+		label := ""
+		if citations {
+			label = fmt.Sprintf(" [%d]", i+1)
+		}
+		articlesText += fmt.Sprintf("📰 Article %d%s:\n%s\n\n", i+1, label, articles[i])
+	}
+
+	// Process videos (limited for token efficiency), their citation numbers continue after the articles
+	videosText := ""
+	videoCount := min(len(videos), summaryVideoLimit)
 
-Remember: Your goal is to provide the most comprehensive, accurate answer by leveraging the unique strengths of both textual articles and video content.`,
-		query, len(articles), articlesText, len(videos), videosText, currentDate)
+	for i := 0; i < videoCount; i++ {
+		label := ""
+		if citations {
+			label = fmt.Sprintf(" [%d]", articleCount+i+1)
+		}
+		videosText += fmt.Sprintf("🎥 Video %d%s:\n%s\n\n", i+1, label, videos[i])
+	}
+
+	return service.prompts.Render("multimedia_summarization", map[string]any{
+		"Query":             query,
+		"ArticleCount":      len(articles),
+		"Articles":          articlesText,
+		"VideoCount":        len(videos),
+		"Videos":            videosText,
+		"CurrentDate":       currentDate,
+		"MediaLinks":        mediaLinksInstruction(links),
+		"Locale":            localeInstruction(locale),
+		"Language":          responseLanguageInstruction(language),
+		"FormatInstruction": summaryFormatInstruction(format, strictSources),
+		"StrictSources":     strictSources,
+		"Citations":         citations,
+	})
 }
 
 // persona agent
 func (service *GeminiService) AddPersonalityToResponse(ctx context.Context, query string, response string, personality string) (string, error) {
+	return service.addPersonality(ctx, query, response, personality, "")
+}
+
+const emptyResultPersonaGuard = `
+IMPORTANT - NO RESULTS NOTICE:
+The content above is a notice that no news was found, it is not a news summary.
+- Keep it a short notice in the same language as the notice
+- Do NOT add, invent or imply any news, events, facts, dates or sources
+- You may keep the suggestion to rephrase the question or broaden the timeframe
+`
+
+// AddPersonalityToEmptyResult restyles the no results notice in the persona's voice without letting it invent news
+func (service *GeminiService) AddPersonalityToEmptyResult(ctx context.Context, query string, notice string, personality string) (string, error) {
+	return service.addPersonality(ctx, query, notice, personality, emptyResultPersonaGuard)
+}
+
+func (service *GeminiService) addPersonality(ctx context.Context, query string, response string, personality string, guard string) (string, error) {
 
 	if personality == "" {
 		personality = "friendly-explainer" // Use default personality
 	}
 
+	// the builders render the language section, in generate mode it is the user's language
+	language := responseLanguageFromContext(ctx)
+
 	var prompt string
 	if personality == "calm-anchor" {
-		prompt = service.buildCalmAnchorPrompt(query, response)
+		prompt = service.buildCalmAnchorPrompt(query, response, language)
 	} else if personality == "friendly-explainer" {
-		prompt = service.buildFriendlyExplainerPrompt(query, response)
+		prompt = service.buildFriendlyExplainerPrompt(query, response, language)
 	} else if personality == "investigative-reporter" {
-		prompt = service.buildInvestigativeReporterPrompt(query, response)
+		prompt = service.buildInvestigativeReporterPrompt(query, response, language)
 	} else if personality == "youthful-trendspotter" {
-		prompt = service.buildYouthfulTrendspotterPrompt(query, response)
+		prompt = service.buildYouthfulTrendspotterPrompt(query, response, language)
 	} else if personality == "global-correspondent" {
-		prompt = service.buildGlobalCorrespondentPrompt(query, response)
+		prompt = service.buildGlobalCorrespondentPrompt(query, response, language)
 	} else if personality == "ai-analyst" {
-		prompt = service.buildAIAnalystPrompt(query, response)
+		prompt = service.buildAIAnalystPrompt(query, response, language)
 	} else {
 		// Use friendly-explainer as fallback for unknown personalities
-		prompt = service.buildFriendlyExplainerPrompt(query, response)
+		prompt = service.buildFriendlyExplainerPrompt(query, response, language)
+	}
+	prompt += guard
+	if citationMarkerPattern.MatchString(response) {
+		prompt += citationPersonaGuard
 	}
 
 	req := &GenerationRequest{
@@ -963,7 +1361,10 @@ func (service *GeminiService) AddPersonalityToResponse(ctx context.Context, quer
 		SystemRole:      "You are an Expert News Content Personalizer",
 		MaxTokens:       8192,
 		DisableThinking: true,
+
+		UserFacing: true,
 	}
+	service.applyAgentSampling("persona", req)
 
 	fmt.Println("Persona Prompt")
 	fmt.Println(prompt)
@@ -1006,7 +1407,10 @@ func (service *GeminiService) GenerateChitChatResponse(ctx context.Context, quer
 		SystemRole:      "You are Infiya, a friendly and knowledgeable AI News assistant",
 		MaxTokens:       1024,
 		DisableThinking: true,
+
+		UserFacing: true,
 	}
+	service.applyAgentSampling("chitchat", req)
 
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
@@ -1025,7 +1429,7 @@ func (service *GeminiService) GenerateChitChatResponse(ctx context.Context, quer
 }
 
 func (service *GeminiService) HealthCheck(ctx context.Context) error {
-	testCtx, cancel := context.WithTimeout(ctx, 1000*time.Second)
+	testCtx, cancel := context.WithTimeout(ctx, service.config.Timeout)
 	defer cancel()
 
 	var temperature float32 = 0
@@ -1076,6 +1480,7 @@ func (service *GeminiService) GetRelevantVideos(ctx context.Context, videos []mo
 		DisableThinking: true,
 		ResponseFormat:  "application/json",
 	}
+	service.applyAgentSampling("relevancy", req)
 
 	resp, err := service.GenerateContent(ctx, req)
 	if err != nil {
@@ -1119,22 +1524,7 @@ func (service *GeminiService) buildVideoRelevancyPrompt(videos []models.YouTubeV
 		originalQuery = oq
 	}
 
-	prompt := fmt.Sprintf(`You are an expert video relevancy evaluator. Your task is to analyze YouTube videos and determine which ones are most relevant to the user's news query.
-
-USER QUERY: %s
-ORIGINAL QUERY: %s
-KEYWORDS: %v
-
-Evaluate each video based on:
-1. Title and description relevance to the query
-2. TRANSCRIPT CONTENT relevance and depth (MOST IMPORTANT)
-3. Content freshness and timeliness 
-4. Channel credibility for news content
-5. Video engagement metrics (views, etc.)
-6. Keywords match strength in transcript
-
-VIDEOS TO EVALUATE:
-`, userQuery, originalQuery, keywords)
+	var videosText strings.Builder
 
 	for i, video := range videos {
 		publishedTime := video.PublishedAt.Format("2006-01-02 15:04")
@@ -1154,7 +1544,7 @@ VIDEOS TO EVALUATE:
 			}
 		}
 
-		prompt += fmt.Sprintf(`
+		videosText.WriteString(fmt.Sprintf(`
 VIDEO %d:
 - Title: %s
 - %s: %s
@@ -1165,43 +1555,15 @@ VIDEO %d:
 - URL: %s
 
 `, i, service.escapeJSON(video.Title), contentType, service.escapeJSON(contentToAnalyze),
-			service.escapeJSON(video.Channel), publishedTime, video.Duration, video.ViewCount, video.URL)
+			service.escapeJSON(video.Channel), publishedTime, videoDurationText(video), video.ViewCount, video.URL))
 	}
 
-	prompt += `
-Return ONLY a valid JSON response with this exact structure:
-{
-  "relevant_videos": [
-    {
-      "id": 0,
-      "title": "Video Title",
-      "url": "video_url",
-      "channel": "Channel Name",
-      "published_at": "2024-01-01T00:00:00Z",
-      "description": "Description",
-      "duration": "PT5M30S",
-      "view_count": "1000",
-      "relevance_score": 0.85
-    }
-  ],
-  "evaluation_summary": {
-    "total_evaluated": "5",
-    "relevant_found": "2", 
-    "average_relevancy": 0.75,
-    "threshold_used": 0.6
-  }
-}
-
-IMPORTANT RULES:
-- Prioritize videos with rich transcript content over description-only videos
-- Only include videos with relevance_score >= 0.6
-- Maximum 8 videos in the response
-- Use the exact id numbers from the input videos
-- Relevance scores should be between 0.0 and 1.0
-- Focus on news-related content and recency
-- Give higher scores to videos with comprehensive transcript coverage`
-
-	return prompt
+	return service.prompts.Render("video_relevancy", map[string]any{
+		"UserQuery":     userQuery,
+		"OriginalQuery": originalQuery,
+		"Keywords":      keywords,
+		"Videos":        videosText.String(),
+	})
 }
 
 func (service *GeminiService) parseRelevantVideosResponse(response string, originalVideos []models.YouTubeVideo) ([]models.YouTubeVideo, error) {
@@ -1259,6 +1621,8 @@ func (service *GeminiService) parseRelevantVideosResponse(response string, origi
 				Description: item.Description,
 				Duration:    item.Duration,
 				ViewCount:   item.ViewCount,
+
+				DurationSeconds: videoDurationSeconds(item.Duration),
 			}
 		}
 
@@ -1308,7 +1672,8 @@ func (service *GeminiService) calculateAverageVideoRelevance(videos []models.You
 
 // Below are the prompt building functions
 
-func (service *GeminiService) buildQueryExpansionPrompt(query string, context map[string]interface{}) string {
+// queryExpansionContext renders the conversation and preference sections shared by the query enhancement prompts
+func queryExpansionContext(context map[string]interface{}) (string, string) {
 	conversationContext := ""
 	if convCtx, ok := context["conversation_context"].(models.ConversationContext); ok {
 		if len(convCtx.CurrentTopics) > 0 {
@@ -1328,83 +1693,21 @@ func (service *GeminiService) buildQueryExpansionPrompt(query string, context ma
 			strings.Join(prefs.FavouriteTopics, ", "), prefs.NewsPersonality)
 	}
 
-	return fmt.Sprintf(`You are an expert query expansion specialist optimized for maximizing news article retrieval using AND-based keyword search.
-
-CRITICAL CONSTRAINT: Keywords will be joined with AND operators. ALL keywords must be present in each retrieved article. More keywords = exponentially fewer results.
-
----
-🎯 ORIGINAL USER QUERY: "%s"
-
-📝 CONVERSATION CONTEXT:
-%s
-
-👤 USER PREFERENCES: %s
-
----
-🔍 SYSTEMATIC QUERY ANALYSIS:
-
-**STEP 1: IDENTIFY CORE COMPONENTS**
-Decompose the query into essential elements using the 5W+H framework:
-- WHO (Person/Organization): The main actor/entity
-- WHAT (Action/Event): The core action or event  
-- WHERE (Location): Geographic scope (country-level preferred)
-- WHEN (Timeframe): If specified, use broad temporal terms
-- WHY/HOW (Context): Essential background context
-
-**STEP 2: STRATEGIC KEYWORD SELECTION**
-Select 4-6 keywords using this priority hierarchy:
-
-1. **MANDATORY CORE (2-3 keywords)**: Terms that MUST appear in relevant articles
-   - Primary entity (person, company, country)
-   - Main action/event/topic
-   
-2. **CONTEXTUAL AMPLIFIERS (1-2 keywords)**: Terms that improve relevance without being too restrictive
-   - Industry/domain context
-   - Broad action category
-   
-3. **OPTIONAL SPECIFICITY (0-1 keyword)**: Only if query explicitly mentions specific details
-   - Specific policies, dates, or technical terms
-
-**STEP 3: ANTI-PATTERNS TO AVOID**
-❌ **Entity Redundancy**: Don't use "Biden" AND "Biden administration"
-❌ **Geographic Over-specification**: Use "China" not "Beijing" + "Chinese government"  
-❌ **Synonym Stacking**: Don't use "trade" + "commerce" + "economic"
-❌ **Technical Jargon**: Avoid unless explicitly mentioned in query
-❌ **Time Fragmentation**: Use "recent" not "2024" + "this year" + "latest"
-
-**STEP 4: VALIDATION CHECK**
-Ask yourself: "Would a typical news article about this topic contain ALL these keywords?"
-If answer is NO → Remove least essential keywords
-
----
-📊 OPTIMIZATION EXAMPLES:
-
-**Financial Queries:**
-- "Tesla stock problems" → "Tesla stock price decline"
-- "Why did Meta fire employees?" → "Meta layoffs employees"
-
-**Political Queries:**
-- "Biden climate change policy" → "Biden climate policy"  
-- "Trump legal issues 2024" → "Trump legal charges"
-
-**International Relations:**
-- "China trade tensions with US" → "China US trade tensions"
-- "Russia Ukraine war updates" → "Russia Ukraine conflict"
-
-**Technology:**
-- "OpenAI ChatGPT regulations" → "OpenAI ChatGPT regulation"
-- "Apple iPhone sales decline" → "Apple iPhone sales"
+	return conversationContext, userPrefs
+}
 
-**Business/Economy:**
-- "Federal Reserve interest rates decision" → "Federal Reserve interest rates"
-- "Oil prices rising inflation" → "oil prices inflation"
+func (service *GeminiService) buildQueryExpansionPrompt(query string, context map[string]interface{}) string {
+	conversationContext, userPrefs := queryExpansionContext(context)
 
----
-🎯 RESPONSE FORMAT:
-ENHANCED_QUERY: <2-3 strategic keywords optimized for maximum OR-based retrieval>
+	// set when a previous search with these keywords found nothing relevant
+	broadenFrom, _ := context["broaden_from_keywords"].([]string)
 
-Remember: Success = Finding multiple relevant articles, not achieving keyword perfection.`,
-		query, conversationContext, userPrefs)
+	return service.prompts.Render("query_expansion", map[string]any{
+		"Query":               query,
+		"ConversationContext": conversationContext,
+		"UserPreferences":     userPrefs,
+		"BroadenFrom":         strings.Join(broadenFrom, ", "),
+	})
 }
 
 func (service *GeminiService) buildEnhancedChitchatPrompt(query string, context map[string]interface{}, history []models.ConversationExchange) string {
@@ -1448,269 +1751,75 @@ func (service *GeminiService) buildEnhancedChitchatPrompt(query string, context
 			exchange := history[i]
 			formattedHistory += fmt.Sprintf("Exchange %d:\n", i+1)
 			formattedHistory += fmt.Sprintf("  User: %s\n", exchange.UserQuery)
-			formattedHistory += fmt.Sprintf("  Infiya: %s\n\n", exchange.AIResponse)
+			formattedHistory += fmt.Sprintf("  Infiya: %s\n\n", truncateForPrompt(exchange.AIResponse, maxPromptPreviousResponseChars))
 		}
 	} else {
 		formattedHistory = "This is our first conversation.\n"
 	}
 
-	return fmt.Sprintf(`You are Infiya — a warm, witty, and friendly AI news assistant with perfect conversational memory.
-
-The user isn't asking about current events right now. Instead, they want to have a casual or light-hearted conversation.
-
----
-🗣️ Current User Message:
-"%s"
-
-🧠 Basic Context:
-%s
-
-👤 User Preferences: %s
-💬 Total Messages: %d
-
-📝 %s
-
----
-🎯 CRITICAL INSTRUCTIONS:
-1. **REMEMBER EVERYTHING**: You have access to our full conversation history above. Use it!
-2. **Reference specific details**: If the user mentioned their name, preferences, or anything personal, remember and use it
-3. **Answer questions about our conversation**: If they ask "What's my name?" or "What did I say earlier?", refer to the history
-4. **Be contextually aware**: Build on previous exchanges naturally
-5. **Maintain personality**: Stay friendly, engaging, and conversational
-6. **Show memory**: Demonstrate that you remember our conversation by referencing specific things
-
-EXAMPLES OF GOOD MEMORY USAGE:
-- If user said "My name is John" before, and now asks "What's my name?", respond: "Your name is John! You told me that when we were introducing ourselves."
-- If they ask about something they mentioned before, reference it specifically
-- Build on topics or jokes from previous exchanges
-
----
-💬 Respond as Infiya with full memory of our conversation history:`,
-		query,
-		conversationContext,
-		userPrefs,
-		messageCount,
-		formattedHistory)
+	return service.prompts.Render("chitchat", map[string]any{
+		"Query":               query,
+		"ConversationContext": conversationContext,
+		"UserPreferences":     userPrefs,
+		"MessageCount":        messageCount,
+		"History":             formattedHistory,
+	})
 }
 
-func (service *GeminiService) buildCalmAnchorPrompt(query, response string) string {
-	return fmt.Sprintf(`You are a trusted evening news anchor delivering information with authority and clarity to millions of viewers.
-
----
-VIEWER QUESTION: "%s"
-NEWSROOM SUMMARY: "%s"
-
----
-ANCHOR GUIDELINES:
-1. **Lead with Direct Answer**: Start by directly addressing what the viewer asked
-2. **Professional Delivery**: Use measured, confident tone suitable for prime-time broadcast
-3. **Factual Precision**: Present only verified information without speculation
-4. **Structured Flow**: Organize information logically (main point → supporting details → context)
-5. **Neutral Stance**: Maintain impartiality and avoid loaded language
-6. **Clear Attribution**: When presenting different viewpoints, clearly indicate sources
-7. **Appropriate Pacing**: Use sentence structure suitable for spoken delivery
-
-**CRITICAL**: If the summary doesn't fully answer the viewer's question, acknowledge this: "While we have information on [covered aspects], details about [missing elements] are not yet available."
-
-Present this as you would during the evening news broadcast:`, query, response)
+func (service *GeminiService) buildCalmAnchorPrompt(query, response, language string) string {
+	return service.prompts.Render("persona_calm_anchor", map[string]any{
+		"Query":    query,
+		"Response": response,
+		"Language": service.personaLanguageInstruction(language),
+	})
 }
 
-func (service *GeminiService) buildFriendlyExplainerPrompt(query, response string) string {
-	return fmt.Sprintf(`You're a knowledgeable friend who makes complex news accessible and engaging for curious readers.
-
----
-FRIEND'S QUESTION: "%s"
-WHAT YOU'VE RESEARCHED: "%s"
-
----
-FRIENDLY EXPLANATION STYLE:
-1. **Start with the Answer**: Directly address what they're asking about first
-2. **Make it Relatable**: Use analogies, examples, or comparisons they'd understand
-3. **Break Down Complexity**: Explain technical terms, political processes, or complex relationships simply
-4. **Conversational Tone**: Write like you're explaining this over coffee - warm but informative
-5. **Acknowledge Uncertainty**: If something isn't fully clear, say "Here's what we know so far..."
-6. **Connect the Dots**: Help them understand why this matters or how pieces fit together
-7. **Stay Accurate**: Keep it friendly but factually correct
-
-**IMPORTANT**: If the research doesn't completely answer their question, be honest: "I found information about [X and Y], but there's still some uncertainty about [Z]."
-
-Now explain this to your curious friend:`, query, response)
+func (service *GeminiService) buildFriendlyExplainerPrompt(query, response, language string) string {
+	return service.prompts.Render("persona_friendly_explainer", map[string]any{
+		"Query":    query,
+		"Response": response,
+		"Language": service.personaLanguageInstruction(language),
+	})
 }
 
-func (service *GeminiService) buildInvestigativeReporterPrompt(query, response string) string {
-	return fmt.Sprintf(`You're an investigative journalist who uncovers deeper stories and connections behind breaking news.
-
----
-INVESTIGATION FOCUS: "%s"
-INITIAL FINDINGS: "%s"
-
----
-INVESTIGATIVE APPROACH:
-1. **Lead with Key Discovery**: Start with the most important finding that answers the core question
-2. **Expose Root Causes**: Dig into underlying factors, historical context, and systemic issues
-3. **Connect Patterns**: Identify relationships, trends, or recurring themes
-4. **Question Implications**: What does this mean for different stakeholders?
-5. **Highlight Gaps**: What questions remain unanswered? What needs further investigation?
-6. **Multiple Perspectives**: Present different viewpoints and potential motivations
-7. **Future Implications**: What might happen next based on these developments?
-
-**CRITICAL ANALYSIS**: If your sources don't provide complete answers, frame it investigatively: "While evidence shows [confirmed findings], key questions about [specific gaps] require further investigation."
-
-**TONE**: Serious, inquisitive, and analytically sharp - like a feature piece in The Atlantic or Washington Post.
-
-Present your investigative analysis:`, query, response)
+func (service *GeminiService) buildInvestigativeReporterPrompt(query, response, language string) string {
+	return service.prompts.Render("persona_investigative_reporter", map[string]any{
+		"Query":    query,
+		"Response": response,
+		"Language": service.personaLanguageInstruction(language),
+	})
 }
 
-func (service *GeminiService) buildYouthfulTrendspotterPrompt(query, response string) string {
-	return fmt.Sprintf(`You're a Gen-Z content creator who breaks down news in an engaging, authentic way for younger audiences across social platforms.
-
----
-🔥 TRENDING QUESTION: "%s"
-📊 THE FACTS: "%s"
-
----
-✨ CONTENT CREATION STRATEGY:
-
-**ENGAGEMENT PRIORITIES:**
-1. **Hook with the Answer**: Lead with the most interesting/surprising part that directly answers their question
-2. **Make it Relatable**: Connect to things Gen-Z cares about (social issues, tech, culture, future impact)
-3. **Break the Fourth Wall**: Acknowledge why this matters to young people specifically
-4. **Keep it Real**: Use authentic language, not forced slang - be genuinely engaging
-5. **Add Context**: Explain background that older generations might assume you know
-6. **Call Out BS**: If something seems off or incomplete, say so honestly
-7. **Future Focus**: How does this affect their generation's future?
-
-**TONE GUIDELINES:**
-- Conversational but informed (think Hasan Piker or ContraPoints, not cringe corporate social media)
-- Use shorter sentences and paragraphs for better mobile reading
-- Include relevant emotions - surprise, concern, excitement, frustration
-- Be skeptical of official narratives when appropriate
-- Show genuine curiosity about implications
-
-**CRITICAL**: If the facts don't fully answer the question, be upfront: "Okay so here's what we actually know... but honestly, there's still missing info about [specific gaps] that we need answers to."
-
-**AVOID**: Excessive emojis, outdated slang, talking down to readers, oversimplifying complex issues
-
-Create an engaging, detailed breakdown that treats your audience as intelligent people who want real answers:`, query, response)
+func (service *GeminiService) buildYouthfulTrendspotterPrompt(query, response, language string) string {
+	return service.prompts.Render("persona_youthful_trendspotter", map[string]any{
+		"Query":    query,
+		"Response": response,
+		"Language": service.personaLanguageInstruction(language),
+	})
 }
 
-func (service *GeminiService) buildGlobalCorrespondentPrompt(query, response string) string {
-	return fmt.Sprintf(`You're an experienced international correspondent reporting for a global audience with diverse cultural and political perspectives.
-
----
-🌐 INTERNATIONAL INQUIRY: "%s"
-📰 FIELD REPORTS: "%s"
-
----
-🎯 GLOBAL REPORTING FRAMEWORK:
-
-**CROSS-CULTURAL COMMUNICATION:**
-1. **Universal Answer First**: Lead with information that directly addresses the query regardless of reader's location
-2. **Multiple Perspectives**: Present how different regions/cultures might view this issue
-3. **Historical Context**: Provide background that international audiences might not know
-4. **Global Implications**: How does this affect different regions, economies, or international relations?
-5. **Cultural Sensitivity**: Avoid Western-centric assumptions or regional biases
-6. **Diplomatic Language**: Use neutral terms that don't favor any particular nation or ideology
-7. **International Law/Norms**: Reference relevant treaties, agreements, or international standards
-
-**REPORTING STANDARDS:**
-- Present competing national narratives without taking sides
-- Explain regional acronyms, political systems, or cultural references
-- Use international date formats, currency conversions, or measurements when relevant
-- Acknowledge when information comes from specific regional sources
-- Highlight how different media outlets in different countries are covering this
-
-**STRUCTURAL APPROACH:**
-- Open with core facts that answer the specific question
-- Expand to regional variations or interpretations
-- Include broader international context and implications
-- Close with what this means for global stability, trade, diplomacy, etc.
-
-**CRITICAL**: If reports are incomplete or regionally biased, state clearly: "Available information primarily comes from [specific sources/regions], with limited perspective from [other relevant parties]."
-
-File your international report:`, query, response)
+func (service *GeminiService) buildGlobalCorrespondentPrompt(query, response, language string) string {
+	return service.prompts.Render("persona_global_correspondent", map[string]any{
+		"Query":    query,
+		"Response": response,
+		"Language": service.personaLanguageInstruction(language),
+	})
 }
 
-func (service *GeminiService) buildAIAnalystPrompt(query, response string) string {
-	return fmt.Sprintf(`You're a senior AI industry analyst providing strategic intelligence for technology leaders, investors, and policymakers.
-
----
-🎯 STRATEGIC QUERY: "%s"
-📊 MARKET INTELLIGENCE: "%s"
-
----
-🧠 ANALYTICAL FRAMEWORK:
-
-**EXECUTIVE SUMMARY APPROACH:**
-1. **Key Finding First**: Lead with the core insight that directly answers the strategic question
-2. **Market Implications**: How does this impact AI companies, investments, or industry direction?
-3. **Technical Assessment**: Evaluate technological feasibility, challenges, or breakthroughs
-4. **Competitive Landscape**: Which players are positioned to benefit or lose?
-5. **Regulatory Environment**: Policy implications, compliance requirements, or regulatory risks
-6. **Timeline Analysis**: Short-term vs. long-term implications for the industry
-7. **Risk Assessment**: Technical, business, regulatory, or ethical risks to consider
-
-**STRATEGIC INTELLIGENCE STANDARDS:**
-- Use precise industry terminology without over-explaining basics
-- Quantify impact when possible (market size, growth rates, adoption timelines)
-- Reference relevant industry frameworks, standards, or best practices
-- Identify patterns, trends, or inflection points
-- Compare to historical precedents or similar market developments
-- Highlight contrarian perspectives or underappreciated risks
-
-**DECISION-MAKER FOCUS:**
-- What actions should leaders consider based on this information?
-- Which capabilities or partnerships become more valuable?
-- How should resource allocation or strategic priorities shift?
-- What assumptions need to be challenged or validated?
-
-**INTELLIGENCE GAPS:**
-If analysis is limited by available data, specify: "Current intelligence covers [confirmed aspects], but strategic assessment requires additional data on [specific intelligence gaps] for complete market evaluation."
-
-**OUTPUT STRUCTURE:**
-Format as a strategic briefing with clear sections, actionable insights, and executive-level recommendations.
-
-Deliver your strategic analysis:`, query, response)
+func (service *GeminiService) buildAIAnalystPrompt(query, response, language string) string {
+	return service.prompts.Render("persona_ai_analyst", map[string]any{
+		"Query":    query,
+		"Response": response,
+		"Language": service.personaLanguageInstruction(language),
+	})
 }
 
-func (service *GeminiService) buildDefaultPersonaPrompt(query, response string) string {
-	return fmt.Sprintf(`You are Infiya, a knowledgeable and reliable AI news assistant focused on providing clear, accurate information.
-
----
-🔍 USER QUESTION: "%s"
-📰 RESEARCH FINDINGS: "%s"
-
----
-📋 RESPONSE METHODOLOGY:
-
-**PRIMARY OBJECTIVES:**
-1. **Direct Response**: Begin by directly answering what the user specifically asked
-2. **Factual Accuracy**: Present only verified information from reliable sources
-3. **Logical Structure**: Organize information in a clear, easy-to-follow sequence
-4. **Appropriate Detail**: Provide sufficient context without overwhelming with unnecessary information
-5. **Balanced Perspective**: Present multiple viewpoints when they exist in the source material
-6. **Clear Attribution**: Distinguish between confirmed facts and reported claims
-7. **Accessible Language**: Use clear, professional language that's understandable to general audiences
-
-**QUALITY STANDARDS:**
-- Start with the most important information that answers their question
-- Use specific facts (names, dates, numbers, locations) when available
-- Explain technical terms or complex concepts when necessary
-- Maintain neutral tone while being engaging and informative
-- Acknowledge uncertainty or conflicting information when present
-- Provide context that helps users understand significance
-
-**STRUCTURE GUIDELINES:**
-- Lead paragraph: Direct answer to the user's question
-- Supporting paragraphs: Additional context, details, and related information
-- Concluding insights: Implications or significance, when appropriate
-
-**TRANSPARENCY REQUIREMENT:**
-If the available information doesn't fully address the user's question, clearly state: "Based on current reports, I can provide information about [covered topics], though details about [specific gaps] are not available in the sources I accessed."
-
-**TONE**: Professional yet approachable, informative without being overly formal, trustworthy and reliable.
-
-Provide a comprehensive response that directly serves the user's information needs:`, query, response)
+func (service *GeminiService) buildDefaultPersonaPrompt(query, response, language string) string {
+	return service.prompts.Render("persona_default", map[string]any{
+		"Query":    query,
+		"Response": response,
+		"Language": service.personaLanguageInstruction(language),
+	})
 }
 
 func (service *GeminiService) buildRelevancyAgentPrompt(articles []models.NewsArticle, context map[string]interface{}) string {
@@ -1754,119 +1863,28 @@ func (service *GeminiService) buildRelevancyAgentPrompt(articles []models.NewsAr
 
 	recentTopicsStr := strings.Join(recentTopics, ", ")
 
-	return fmt.Sprintf(`You are a news relevancy assessment AI. Your job is to evaluate a list of articles to determine how well each article addresses the user's query, considering the user's context and recent topics.
-
-Input:
-
-USER QUERY: "%s"
-
-RECENT CONTEXT TOPICS: %s
-
-ARTICLES:  
-[
-%s
-]
-
-Evaluation Criteria:
-1. The article must address the user's query directly with factual, relevant content.
-2. Match the user's intent and context to avoid unrelated or metaphorical uses of terms.
-3. Prioritize timely, recent, and credible news coverage.
-4. Evaluate completeness—does the article sufficiently cover the aspects of the query?
-5. Avoid articles that are opinion-based, speculative, or only tangentially related.
-6. Favor articles with informative titles, descriptions, and content.
-
-Scoring Scale (0.0 - 1.0):
-- 0.90-1.00: Excellent relevance, thorough, factual match.
-- 0.70-0.89: Good relevance, mostly aligned with query.
-- 0.50-0.69: Moderate relevance, partial match.
-- Below 0.50: Low or no relevance.
-
-Task:
-- Assign each article a relevance_score based on the scale above.
-- Return only articles with relevance_score >= 0.6.
-- If no articles meet the threshold, return the top 3 articles by score.
-- Limit the returned list to a maximum of 5 articles.
-- Sort results by relevance_score descending.
-
-Response:
+	return service.prompts.Render("article_relevancy", map[string]any{
+		"UserQuery":    userQuery,
+		"RecentTopics": recentTopicsStr,
+		"Articles":     articlesJSON,
+	})
+}
 
-Return a JSON object with exactly this structure (no extra text, no explanation):
+func (service *GeminiService) buildQueryProcessingPrompt(query string, context map[string]interface{}) string {
+	conversationContext, userPrefs := queryExpansionContext(context)
 
-{
-  "relevant_articles": [
-    {
-      "id": 0,
-      "title": "Article Title Here",
-      "url": "https://article-url.com",
-      "source": "Source Name",
-      "author": "Author Name",
-      "published_at": "2025-07-30T12:00:00Z",
-      "description": "Article description here.",
-      "content": "Article content here.",
-      "image_url": "https://image-url.com",
-      "category": "news_category",
-      "relevance_score": 0.95
-    }
-  ],
-  "evaluation_summary": {
-    "total_evaluated": "",
-    "relevant_found": "",
-    "average_relevance": "",
-    "threshold_used": 0.6
-  }
-}
-`,
-		userQuery, recentTopicsStr, articlesJSON)
+	return service.prompts.Render("query_processing", map[string]any{
+		"Query":               query,
+		"ConversationContext": conversationContext,
+		"UserPreferences":     userPrefs,
+	})
 }
 
 func (service *GeminiService) buildKeywordExtractionPrompt(query string, context map[string]interface{}) string {
-	return fmt.Sprintf(`You are an expert keyword extraction agent specialized for comprehensive news search and retrieval optimization.
-
-Input:
-User Query: "%s"
-User Context: %v
-
-Task: Generate a comprehensive keyword set that maximizes news article discovery by thinking both literally and semantically about the query.
-
-EXTRACTION STRATEGY:
-
-1. **Core Entity Expansion**:
-   - If query mentions "social media companies" → include: Facebook, Meta, Google, Twitter, X, TikTok, Instagram, YouTube, Snapchat, LinkedIn
-   - If query mentions "tech companies" → include: Apple, Microsoft, Amazon, Tesla, Netflix, etc.
-   - If query mentions "banks" → include: JPMorgan, Goldman Sachs, Bank of America, Wells Fargo, etc.
-
-2. **Concept Broadening**:
-   - "AI regulation" → artificial intelligence, algorithm regulation, AI governance, machine learning oversight, algorithmic accountability, AI ethics, content moderation
-   - "tensions" → conflict, dispute, relations, diplomatic crisis, trade war
-   - "supply chain" → logistics, manufacturing, semiconductors, trade, exports, imports
-
-3. **Temporal & Colloquial Term Filtering**:
-   - EXCLUDE: "latest", "recent", "drama", "news", "update", "situation"
-   - REPLACE colloquial terms: "drama" → controversy, scandal, dispute, conflict
-
-4. **Regulatory & Legal Context**:
-   - Include relevant laws, acts, and regulatory bodies
-   - "regulation" → FTC, EU Commission, Congress, Senate, antitrust, compliance, policy
-
-5. **Geographic Expansion**:
-   - If countries mentioned, include related terms: "China" → Beijing, Chinese government, CCP
-   - "India" → New Delhi, Indian government, Modi
-
-6. **Synonym & Related Terms**:
-   - Add industry-specific terminology and synonyms
-   - Consider technical terms that journalists might use
-
-RESPONSE FORMAT:
-Return 5-10 keywords as a clean, comma-separated list optimized for news search APIs. Prioritize specific entities and technical terms over generic concepts.
-
-Example Transformations:
-Query: "drama with social media and AI regulation"
-Keywords: Facebook, Meta, Google, Twitter, artificial intelligence, algorithm regulation, FTC, EU AI Act
-
-Query: "tensions between India and China"  
-Keywords: India, China, border dispute, LAC, Galwan Valley, Modi, Xi Jinping, Himalayan border, Ladakh
-
-Now extract keywords for the given query:`, query, context)
+	return service.prompts.Render("keyword_extraction", map[string]any{
+		"Query":   query,
+		"Context": service.promptContext(context),
+	})
 }
 
 func (service *GeminiService) buildContextualResponsePrompt(query string, history []models.ConversationExchange, referencedTopic string, userPreferences models.UserPreferences, context map[string]interface{}) string {
@@ -1882,7 +1900,7 @@ PREVIOUS DISCUSSION CONTEXT:
 User Previously Asked: "%s"
 My Previous Response: "%s"
 Referenced Topic: "%s"
-`, relevantExchange.UserQuery, relevantExchange.AIResponse, referencedTopic)
+`, relevantExchange.UserQuery, truncateForPrompt(relevantExchange.AIResponse, maxPromptPreviousResponseChars), referencedTopic)
 	}
 
 	personalityGuidance := ""
@@ -1901,49 +1919,20 @@ Referenced Topic: "%s"
 		personalityGuidance = "Be conversational and informative, making complex topics accessible."
 	}
 
-	// Handle additional context
+	// the history and preferences are already rendered above, only the bounded summary lines are added
 	additionalContext := ""
-	if context != nil && len(context) > 0 {
-		additionalContext = "ADDITIONAL CONTEXT:\n"
-		for key, value := range context {
-			additionalContext += fmt.Sprintf("- %s: %v\n", key, value)
-		}
+	if rendered := service.promptContext(context); rendered != "None" {
+		additionalContext = "ADDITIONAL CONTEXT:\n" + rendered + "\n"
 	}
 
-	return fmt.Sprintf(`You are Infiya, a warm and knowledgeable AI news assistant. The user is following up on a previous conversation.
-
-%s
-
-CURRENT FOLLOW-UP QUERY: "%s"
-
-USER PREFERENCES:
-- News Personality: %s
-- Favorite Topics: %s
-
-PERSONALITY GUIDANCE: %s
-
-%s
-
-INSTRUCTIONS:
-1. **Reference Previous Context**: Acknowledge what we discussed before
-2. **Build on Previous Response**: Expand, clarify, or provide different perspectives
-3. **Maintain Personality**: Stay true to the user's preferred news personality
-4. **Provide Value**: Answer their follow-up question thoroughly
-5. **Natural Flow**: Make it feel like a continued conversation, not a new topic
-
-RESPONSE APPROACH:
-- If they want clarification: "When I mentioned [X] earlier, what I meant was..."
-- If they want more details: "To build on what we discussed about [topic]..."
-- If they want personal opinion: "Based on the situation we talked about..."
-- If they want implications: "Thinking about [previous topic], here's how it affects..."
-
-Respond as Infiya in a natural, conversational way that builds on our previous discussion.`,
-		contextSection,
-		query,
-		userPreferences.NewsPersonality,
-		strings.Join(userPreferences.FavouriteTopics, ", "),
-		personalityGuidance,
-		additionalContext)
+	return service.prompts.Render("contextual_response", map[string]any{
+		"ContextSection":      contextSection,
+		"Query":               query,
+		"NewsPersonality":     userPreferences.NewsPersonality,
+		"FavouriteTopics":     strings.Join(userPreferences.FavouriteTopics, ", "),
+		"PersonalityGuidance": personalityGuidance,
+		"AdditionalContext":   additionalContext,
+	})
 }
 
 func (service *GeminiService) buildEnhancedClassificationPrompt(query string, history []models.ConversationExchange) string {
@@ -1956,77 +1945,21 @@ func (service *GeminiService) buildEnhancedClassificationPrompt(query string, hi
 		}
 
 		for i, exchange := range recentHistory {
-			historyContext += fmt.Sprintf("Exchange %d:\nUser: %s\nInfiya: %s\n\n", i+1, exchange.UserQuery, exchange.AIResponse)
+			historyContext += fmt.Sprintf("Exchange %d:\nUser: %s\nInfiya: %s\n\n", i+1, exchange.UserQuery,
+				truncateForPrompt(exchange.AIResponse, maxPromptPreviousResponseChars))
 		}
 	}
-	return fmt.Sprintf(`Classify the user's intent based on their query and conversation history.
-
-	CONVERSATION HISTORY:
-	%s
-
-	CURRENT QUERY: "%s" 
-
-	CLASSIFICATION RULES:
-
-	1. **NEW_NEWS_QUERY** - Choose this if:
-   		- User asks about a completely new topic/event
-   		- Query is self-contained and doesn't reference previous discussion
-   		- User wants fresh news analysis
-   		- Examples: "What's happening with Tesla?", "Why are gas prices rising?"
-
-	2. **FOLLOW_UP_DISCUSSION** - Choose this if:
-   		- Query references previous conversation ("this", "that", "it", "the situation")
-		- User wants clarification, more details, or different perspective on previous topic
-   		- User asks related questions about the same topic
-   		- Examples: "Tell me more about this", "How does this affect me?", "What's your opinion?"
-
-	3. **CHITCHAT** - Choose this if:
-   		- General conversation, greetings, personal questions
-   		- User testing the AI or making casual conversation
-   		- Non-news related queries
-
-	RESPONSE FORMAT:
-	{
-    	"intent": "NEW_NEWS_QUERY|FOLLOW_UP_DISCUSSION|CHITCHAT",
-    	"confidence": 0.95,
-    	"reasoning": "Brief explanation",
-    	"referenced_topic": "topic from history if follow-up",
-    	"enhanced_query": "self-contained version if needed"
-	}
-
-	Respond only with the JSON.`, historyContext, query)
+	return service.prompts.Render("intent_classification_with_history", map[string]any{
+		"History": historyContext,
+		"Query":   query,
+	})
 }
 
 func (service *GeminiService) buildIntentClassificationPrompt(query string, context map[string]interface{}) string {
-	return fmt.Sprintf(`You are a highly accurate intent classifier for a news AI assistant. Classify user queries into one of two intents: "news" or "chit_chat".
-
-Input:
-Query: "%s"
-User Context: %v
-
-Classification Criteria:
-
-Classify as "news" if:
-- The query requests factual information about current or past events.
-- It concerns companies, people, technologies, locations, or any topic that could appear in news.
-- The user wants updates, summaries, reports, or analyses of occurrences or trends.
-- The query focuses on real-world events, statistics, or official data.
-
-Classify as "chit_chat" if:
-- The query consists of greetings, jokes, social questions, or casual conversation.
-- It seeks opinions, small talk, or non-news-related topics.
-- The query is ambiguous without news context.
-
-Output format (use exact syntax, no extra text):
-
-intent|confidence_score
-
-Examples:
-news|0.95
-chit_chat|0.88
-news|0.75
-chit_chat|0.60
-.`, query, context)
+	return service.prompts.Render("intent_classification", map[string]any{
+		"Query":   query,
+		"Context": service.promptContext(context),
+	})
 }
 
 func (service *GeminiService) Close() error {
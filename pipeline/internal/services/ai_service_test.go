@@ -0,0 +1,427 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSummaryFormatInstructionPerFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "prose", want: "plain flowing prose"},
+		{format: "bullets", want: "bullet points"},
+		{format: "tldr", want: "TL;DR"},
+		{format: "json", want: `"key_points"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := summaryFormatInstruction(tt.format, false); !strings.Contains(got, tt.want) {
+				t.Errorf("summaryFormatInstruction(%q) = %q, want it to mention %q", tt.format, got, tt.want)
+			}
+		})
+	}
+
+	if sections, unknown := summaryFormatInstruction("sections", false), summaryFormatInstruction("", false); sections != unknown {
+		t.Error("an unset format should fall back to the sections layout")
+	}
+}
+
+func TestParseStructuredSummary(t *testing.T) {
+	structured, err := ParseStructuredSummary("```json\n{\"summary\": \"Rates held\"}\n```")
+	if err != nil {
+		t.Fatalf("ParseStructuredSummary() error = %v", err)
+	}
+	if structured.Summary != "Rates held" || structured.KeyPoints == nil {
+		t.Errorf("ParseStructuredSummary() = %+v, want the summary with empty key points", structured)
+	}
+
+	for _, content := range []string{"Rates held", "{}"} {
+		if _, err := ParseStructuredSummary(content); err == nil {
+			t.Errorf("ParseStructuredSummary(%q) succeeded, want an error", content)
+		}
+	}
+}
+
+func TestSummarizeContentJSONFormat(t *testing.T) {
+	gemini, service := newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+		return "```json\n{\"summary\": \"The central bank held rates\", \"key_points\": [\"Rates unchanged\"]}\n```"
+	})
+
+	summary, err := service.SummarizeContent(context.Background(), "what did the central bank do",
+		[]string{"**ARTICLE** Central bank holds rates"}, string(models.SummaryFormatJSON), nil, false, false)
+	if err != nil {
+		t.Fatalf("SummarizeContent() error = %v", err)
+	}
+
+	var structured StructuredSummary
+	if err := json.Unmarshal([]byte(summary), &structured); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, summary)
+	}
+	if structured.Summary != "The central bank held rates" || len(structured.KeyPoints) != 1 {
+		t.Errorf("structured summary = %+v", structured)
+	}
+	if calls := gemini.received(); len(calls) != 1 || !strings.Contains(calls[0].Prompt, `"key_points"`) {
+		t.Error("summarization prompt did not ask for the JSON layout")
+	}
+}
+
+func TestSummarizeContentJSONFormatRejectsProse(t *testing.T) {
+	_, service := newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+		return "The central bank held rates."
+	})
+
+	_, err := service.SummarizeContent(context.Background(), "rates", []string{"**ARTICLE** Rates"}, "json", nil, false, false)
+	if err == nil {
+		t.Error("SummarizeContent() accepted a prose answer for the json format")
+	}
+}
+
+func TestStrictSourcesSummaryForbidsModelKnowledge(t *testing.T) {
+	supplementLanguage := []string{"KNOWLEDGE SUPPLEMENT", "relevant knowledge", "Your knowledge:", "background knowledge", "knowledge beyond provided sources"}
+	tests := []struct {
+		name          string
+		strictSources bool
+		want          []string
+		absent        []string
+	}{
+		{name: "strict", strictSources: true,
+			want:   []string{"only the provided articles and videos", "**SOURCES ONLY**", "**INSUFFICIENT COVERAGE**", "Coverage Gaps"},
+			absent: supplementLanguage},
+		{name: "knowledge supplement", strictSources: false,
+			want:   supplementLanguage,
+			absent: []string{"**SOURCES ONLY**", "**INSUFFICIENT COVERAGE**"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gemini, service := newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+				return "The central bank held rates."
+			})
+
+			if _, err := service.SummarizeContent(context.Background(), "what did the central bank do",
+				[]string{"**ARTICLE** Central bank holds rates"}, "", nil, tt.strictSources, false); err != nil {
+				t.Fatalf("SummarizeContent() error = %v", err)
+			}
+
+			prompt := gemini.received()[0].Prompt
+			for _, want := range tt.want {
+				if !strings.Contains(prompt, want) {
+					t.Errorf("summarization prompt is missing %q", want)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(prompt, absent) {
+					t.Errorf("summarization prompt contains %q", absent)
+				}
+			}
+		})
+	}
+}
+
+func TestEnhanceAndExtractKeywordsInOneJSONCall(t *testing.T) {
+	gemini, service := newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+		return `{"enhanced_query": "2026 Lok Sabha election results and seat counts", "keywords": ["Lok Sabha", "election results", "BJP", "ok"]}`
+	})
+
+	result, err := service.EnhanceAndExtractKeywords(context.Background(), "election results", nil)
+	if err != nil {
+		t.Fatalf("EnhanceAndExtractKeywords() error = %v", err)
+	}
+
+	if result.EnhancedQuery != "2026 Lok Sabha election results and seat counts" || result.OriginalQuery != "election results" {
+		t.Errorf("queries = %q from %q, want the enhanced query returned", result.EnhancedQuery, result.OriginalQuery)
+	}
+	if want := []string{"Lok Sabha", "election results", "BJP"}; !slices.Equal(result.Keywords, want) {
+		t.Errorf("keywords = %v, want %v with short keywords dropped", result.Keywords, want)
+	}
+	calls := gemini.received()
+	if len(calls) != 1 || calls[0].GenerationConfig.ResponseMIMEType != "application/json" {
+		t.Errorf("calls = %d, want one call in JSON mode", len(calls))
+	}
+}
+
+func TestParseQueryProcessingResponse(t *testing.T) {
+	result, err := parseQueryProcessingResponse("```json\n{\"keywords\": [\"rate cut\"]}\n```", "rates")
+	if err != nil || result.EnhancedQuery != "rates" || !slices.Equal(result.Keywords, []string{"rate cut"}) {
+		t.Errorf("parseQueryProcessingResponse() = %+v, %v, want the original query kept when none is returned", result, err)
+	}
+
+	for _, content := range []string{`{"enhanced_query": "rates"}`, `not json`} {
+		if _, err := parseQueryProcessingResponse(content, "rates"); err == nil {
+			t.Errorf("parseQueryProcessingResponse(%q) succeeded, want an error", content)
+		}
+	}
+}
+
+// blockingGemini holds every generation call until release is closed and remembers the most calls it served at once
+type blockingGemini struct {
+	running atomic.Int64
+	peak    atomic.Int64
+	release chan struct{}
+	once    sync.Once
+}
+
+// unblock lets every held and future call answer
+func (blocking *blockingGemini) unblock() {
+	blocking.once.Do(func() { close(blocking.release) })
+}
+
+func newBlockingGemini(t *testing.T, cfg config.GeminiConfig) (*blockingGemini, *GeminiService) {
+	t.Helper()
+	blocking := &blockingGemini{release: make(chan struct{})}
+	_, service := newFakeGemini(t, cfg, func(call fakeGeminiCall) string {
+		running := blocking.running.Add(1)
+		defer blocking.running.Add(-1)
+		for peak := blocking.peak.Load(); running > peak && !blocking.peak.CompareAndSwap(peak, running); peak = blocking.peak.Load() {
+		}
+		<-blocking.release
+		return "ok"
+	})
+	t.Cleanup(blocking.unblock)
+	return blocking, service
+}
+
+// waitForStat polls the service's concurrency stats until the named counter reaches want
+func waitForStat(t *testing.T, service *GeminiService, name string, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for service.ConcurrencyStats()[name].(int64) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s = %v, want %d", name, service.ConcurrencyStats()[name], want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEmptyResultSummaryIsLocalized(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{language: "", want: "I couldn't find any news articles or videos"},
+		{language: "es", want: "No encontré artículos ni videos"},
+		{language: "FR", want: "Je n'ai trouvé aucun article"},
+		{language: "xx", want: "I couldn't find any news articles or videos"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			summary := EmptyResultSummary(tt.language, "prose")
+			if !strings.HasPrefix(summary, tt.want) {
+				t.Errorf("EmptyResultSummary(%q) = %q, want it to start with %q", tt.language, summary, tt.want)
+			}
+			if summary == "No news articles or videos were found within the last one month" {
+				t.Error("EmptyResultSummary() returned the old fixed notice")
+			}
+		})
+	}
+
+	structured, err := ParseStructuredSummary(EmptyResultSummary("de", "json"))
+	if err != nil {
+		t.Fatalf("json empty result is not a structured summary: %v", err)
+	}
+	if !strings.Contains(structured.Summary, "Nachrichtenartikel") || structured.KeyPoints == nil {
+		t.Errorf("structured empty result = %+v, want the german notice and empty key points", structured)
+	}
+}
+
+func TestSummarizerPromptMentionsTheUsersLocale(t *testing.T) {
+	gemini, service := newFakeGemini(t, config.GeminiConfig{}, func(fakeGeminiCall) string { return "Results summary" })
+	content := []string{"**ARTICLE**\nTitle: Election results\nDescription: Counting continues"}
+
+	ctx := WithSearchLocale(context.Background(), SearchLocale{Region: "in", Timezone: "Asia/Kolkata"})
+	if _, err := service.SummarizeContent(ctx, "election results", content, "prose", nil, false, false); err != nil {
+		t.Fatalf("SummarizeContent() error = %v", err)
+	}
+	if _, err := service.SummarizeContent(context.Background(), "election results", content, "prose", nil, false, false); err != nil {
+		t.Fatalf("SummarizeContent() error = %v", err)
+	}
+
+	calls := gemini.received()
+	if len(calls) != 2 {
+		t.Fatalf("gemini received %d calls, want 2", len(calls))
+	}
+	for _, want := range []string{"**USER LOCALE**", `region "IN"`, "timezone (Asia/Kolkata)"} {
+		if !strings.Contains(calls[0].Prompt, want) {
+			t.Errorf("localized summarizer prompt is missing %q", want)
+		}
+	}
+	if strings.Contains(calls[1].Prompt, "USER LOCALE") {
+		t.Error("summarizer prompt without a locale has a locale section")
+	}
+}
+
+func TestGeminiSemaphoreBoundsConcurrentCalls(t *testing.T) {
+	blocking, service := newBlockingGemini(t, config.GeminiConfig{MaxConcurrency: 3, MaxQueue: 20})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.GenerateContent(context.Background(), &GenerationRequest{Prompt: "burst"})
+			errs <- err
+		}()
+	}
+
+	waitForStat(t, service, "in_flight", 3)
+	waitForStat(t, service, "queued", 7)
+	for deadline := time.Now().Add(5 * time.Second); blocking.running.Load() < 3 && time.Now().Before(deadline); {
+		time.Sleep(5 * time.Millisecond)
+	}
+	blocking.unblock()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GenerateContent() error = %v", err)
+		}
+	}
+	if peak := blocking.peak.Load(); peak != 3 {
+		t.Errorf("peak concurrent calls = %d, want 3", peak)
+	}
+	if stats := service.ConcurrencyStats(); stats["in_flight"].(int64) != 0 || stats["queued"].(int64) != 0 {
+		t.Errorf("stats after the burst = %v, want nothing in flight or queued", stats)
+	}
+}
+
+func TestGeminiQueueFailsFastWhenFull(t *testing.T) {
+	_, service := newBlockingGemini(t, config.GeminiConfig{MaxConcurrency: 1, MaxQueue: 1, MaxRetries: 3, RetryDelay: time.Second})
+
+	go service.GenerateContent(context.Background(), &GenerationRequest{Prompt: "running"})
+	waitForStat(t, service, "in_flight", 1)
+	go service.GenerateContent(context.Background(), &GenerationRequest{Prompt: "queued"})
+	waitForStat(t, service, "queued", 1)
+
+	// a full queue is not retried, the caller hears about it straight away
+	started := time.Now()
+	_, err := service.GenerateContent(context.Background(), &GenerationRequest{Prompt: "rejected"})
+	if !errors.Is(err, ErrGeminiQueueFull) {
+		t.Fatalf("GenerateContent() error = %v, want ErrGeminiQueueFull", err)
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("rejection took %s, want it to fail fast", elapsed)
+	}
+
+}
+
+func TestGeminiQueuedCallGivesUpWithItsContext(t *testing.T) {
+	_, service := newBlockingGemini(t, config.GeminiConfig{MaxConcurrency: 1, MaxQueue: 1})
+
+	go service.GenerateContent(context.Background(), &GenerationRequest{Prompt: "running"})
+	waitForStat(t, service, "in_flight", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := service.acquireSlot(ctx)
+	if err == nil || errors.Is(err, ErrGeminiQueueFull) {
+		t.Fatalf("acquireSlot() error = %v, want a timeout", err)
+	}
+	waitForStat(t, service, "queued", 0)
+}
+
+func TestParseGenerationOverrides(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		wantNil  bool
+		wantErr  bool
+	}{
+		{name: "no overrides keeps production behaviour", metadata: map[string]any{"explain": true}, wantNil: true},
+		{name: "temperature and seed", metadata: map[string]any{"temperature": 0.0, "seed": float64(42)}},
+		{name: "temperature out of range", metadata: map[string]any{"temperature": 2.5}, wantErr: true},
+		{name: "temperature as text", metadata: map[string]any{"temperature": "low"}, wantErr: true},
+		{name: "fractional seed", metadata: map[string]any{"seed": 1.5}, wantErr: true},
+		{name: "seed beyond 32 bits", metadata: map[string]any{"seed": float64(1 << 40)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, err := ParseGenerationOverrides(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGenerationOverrides() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (overrides == nil) != tt.wantNil {
+				t.Errorf("ParseGenerationOverrides() = %+v, want nil %v", overrides, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestGenerationOverridesReachTheGenerateContentConfig(t *testing.T) {
+	gemini, service := newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string { return "ok" })
+	agentTemperature := float32(0.7)
+	request := func() *GenerationRequest {
+		return &GenerationRequest{Prompt: "summarize", Temperature: &agentTemperature}
+	}
+
+	if _, err := service.GenerateContent(context.Background(), request()); err != nil {
+		t.Fatalf("GenerateContent() error = %v", err)
+	}
+
+	overrides, err := ParseGenerationOverrides(map[string]any{"temperature": 0.0, "seed": float64(42)})
+	if err != nil {
+		t.Fatalf("ParseGenerationOverrides() error = %v", err)
+	}
+	if _, err := service.GenerateContent(WithGenerationOverrides(context.Background(), overrides), request()); err != nil {
+		t.Fatalf("GenerateContent() with overrides error = %v", err)
+	}
+
+	calls := gemini.received()
+	if len(calls) != 2 {
+		t.Fatalf("got %d calls, want 2", len(calls))
+	}
+	if plain := calls[0].GenerationConfig; plain.Temperature == nil || *plain.Temperature != 0.7 || plain.Seed != nil {
+		t.Errorf("without overrides got temperature %v seed %v, want the agent's 0.7 and no seed", plain.Temperature, plain.Seed)
+	}
+	if reproducible := calls[1].GenerationConfig; reproducible.Temperature == nil || *reproducible.Temperature != 0 ||
+		reproducible.Seed == nil || *reproducible.Seed != 42 {
+		t.Errorf("with overrides got temperature %v seed %v, want 0 and 42", reproducible.Temperature, reproducible.Seed)
+	}
+}
+
+func TestConfiguredAgentSamplingReachesTheGenerationRequest(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{
+		"GEMINI_AGENT_SAMPLING": "keyword_extractor:temperature=0.7,top_p=0.8,top_k=20,max_tokens=123; broken; persona:top_p=oops",
+	})
+	if sampling := cfg.Gemini.AgentSampling["persona"]; sampling.TopP != nil {
+		t.Errorf("malformed persona top_p parsed as %v, want it ignored", *sampling.TopP)
+	}
+
+	gemini, service := newFakeGemini(t, cfg.Gemini, func(fakeGeminiCall) string { return "elections, results" })
+	if _, err := service.ExtractKeyWords(context.Background(), "election results", nil); err != nil {
+		t.Fatalf("ExtractKeyWords() error = %v", err)
+	}
+	if _, err := service.EnhanceQueryForSearch(context.Background(), "election results", nil); err != nil {
+		t.Fatalf("EnhanceQueryForSearch() error = %v", err)
+	}
+
+	calls := gemini.received()
+	if len(calls) != 2 {
+		t.Fatalf("gemini received %d calls, want 2", len(calls))
+	}
+	keywords := calls[0].GenerationConfig
+	if keywords.Temperature == nil || *keywords.Temperature != 0.7 || keywords.TopP == nil || *keywords.TopP != 0.8 ||
+		keywords.TopK == nil || *keywords.TopK != 20 || keywords.MaxOutputTokens != 123 {
+		t.Errorf("keyword extractor generation config = %+v, want the configured sampling", keywords)
+	}
+
+	// agents without configured sampling keep their built in values
+	enhancer := calls[1].GenerationConfig
+	if enhancer.Temperature == nil || *enhancer.Temperature != 0.3 || enhancer.TopP != nil || enhancer.MaxOutputTokens != 1000 {
+		t.Errorf("query enhancer generation config = %+v, want its built in sampling", enhancer)
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ArticleCategorizer assigns fetched articles a category from the configured taxonomy by keyword hits,
+// titles count double since they carry the topic more reliably than the body
+type ArticleCategorizer struct {
+	categories      []string
+	keywords        map[string][]string
+	defaultCategory string
+}
+
+func NewArticleCategorizer(categoryConfig config.CategoryConfig) *ArticleCategorizer {
+	categories := make([]string, 0, len(categoryConfig.Taxonomy))
+	keywords := make(map[string][]string, len(categoryConfig.Taxonomy))
+
+	for category, terms := range categoryConfig.Taxonomy {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" {
+			continue
+		}
+		categories = append(categories, category)
+		for _, term := range terms {
+			if term = normalizeCategoryText(term); term != "" {
+				keywords[category] = append(keywords[category], term)
+			}
+		}
+	}
+	// stable order so ties always resolve to the same category
+	sort.Strings(categories)
+
+	return &ArticleCategorizer{
+		categories:      categories,
+		keywords:        keywords,
+		defaultCategory: categoryConfig.Default,
+	}
+}
+
+// Categorize returns the best matching category, or the default when no keyword hits
+func (categorizer *ArticleCategorizer) Categorize(article models.NewsArticle) string {
+	title := " " + normalizeCategoryText(article.Title) + " "
+	body := " " + normalizeCategoryText(article.Description+" "+article.Content) + " "
+
+	bestCategory := categorizer.defaultCategory
+	bestScore := 0
+
+	for _, category := range categorizer.categories {
+		score := 0
+		for _, keyword := range categorizer.keywords[category] {
+			padded := " " + keyword + " "
+			score += 2*strings.Count(title, padded) + strings.Count(body, padded)
+		}
+		if score > bestScore {
+			bestCategory = category
+			bestScore = score
+		}
+	}
+
+	return bestCategory
+}
+
+// CategorizeAll fills in the category of articles that do not already carry one and returns the per category counts
+func (categorizer *ArticleCategorizer) CategorizeAll(articles []models.NewsArticle) map[string]int {
+	counts := make(map[string]int)
+	for i := range articles {
+		if articles[i].Category == "" {
+			articles[i].Category = categorizer.Categorize(articles[i])
+		}
+		counts[articles[i].Category]++
+	}
+	return counts
+}
+
+// normalizeCategoryText lowercases and collapses everything that is not a letter or digit into single spaces
+func normalizeCategoryText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
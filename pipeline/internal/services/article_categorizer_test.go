@@ -0,0 +1,52 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"testing"
+)
+
+func TestCategorizeUsesTheDefaultTaxonomy(t *testing.T) {
+	categorizer := NewArticleCategorizer(loadTestConfig(t, nil).Categories)
+
+	tests := []struct {
+		article models.NewsArticle
+		want    string
+	}{
+		{article: models.NewsArticle{Title: "Election turnout hits record as parliament vote nears"}, want: "politics"},
+		{article: models.NewsArticle{Title: "Cricket: India clinch the tournament", Description: "The coach praised the season."}, want: "sports"},
+		{article: models.NewsArticle{Title: "Stocks slide as inflation data rattles investors"}, want: "business"},
+		// the title outweighs a single body mention of another category
+		{article: models.NewsArticle{Title: "New vaccine cuts hospital visits", Description: "Shares of the maker rose."}, want: "health"},
+		{article: models.NewsArticle{Title: "Local bakery celebrates 50 years"}, want: "general"},
+	}
+	for _, tt := range tests {
+		if got := categorizer.Categorize(tt.article); got != tt.want {
+			t.Errorf("Categorize(%q) = %s, want %s", tt.article.Title, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryTaxonomyIsConfigurable(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{
+		"ARTICLE_CATEGORY_TAXONOMY": "Weather: rain, storm , monsoon; ; broken",
+		"ARTICLE_CATEGORY_DEFAULT":  "other",
+	})
+	categorizer := NewArticleCategorizer(cfg.Categories)
+
+	articles := []models.NewsArticle{
+		{Title: "Monsoon storm floods the coast"},
+		{Title: "Election turnout hits record"},
+		{Title: "Anything at all", Category: "sports"},
+	}
+	counts := categorizer.CategorizeAll(articles)
+
+	if articles[0].Category != "weather" || articles[1].Category != "other" {
+		t.Errorf("categories = %s, %s, want weather from the custom taxonomy and the custom default", articles[0].Category, articles[1].Category)
+	}
+	if articles[2].Category != "sports" {
+		t.Errorf("pre-set category was replaced with %s", articles[2].Category)
+	}
+	if counts["weather"] != 1 || counts["other"] != 1 || counts["sports"] != 1 {
+		t.Errorf("CategorizeAll() counts = %v", counts)
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CondenseArticle shortens one long article to what bears on the query, the map step before the summarizer
+// reduces all sources into the answer
+func (service *GeminiService) CondenseArticle(ctx context.Context, query string, article models.NewsArticle, targetChars int) (string, error) {
+	start := time.Now()
+
+	prompt := service.prompts.Render("article_condense", map[string]any{
+		"Query":       query,
+		"Title":       article.Title,
+		"Source":      article.Source,
+		"Content":     article.Content,
+		"TargetChars": targetChars,
+	})
+
+	req := &GenerationRequest{
+		Prompt:          prompt,
+		Temperature:     &[]float32{0.1}[0],
+		SystemRole:      "You are an expert news editor. Condense articles faithfully and return only the condensed text.",
+		MaxTokens:       2048,
+		DisableThinking: true,
+	}
+	service.applyAgentSampling("condenser", req)
+
+	resp, err := service.GenerateContent(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("Article condensation failed: %w", err)
+	}
+
+	condensed := strings.TrimSpace(resp.Content)
+	if condensed == "" {
+		return "", fmt.Errorf("Article condensation returned empty content")
+	}
+
+	service.logger.LogAgent("", "condenser", "condense_article", time.Since(start), map[string]interface{}{
+		"input_chars":  len(article.Content),
+		"output_chars": len(condensed),
+		"tokens_used":  resp.TokensUsed,
+	}, nil)
+
+	return condensed, nil
+}
+
+// condenseLongArticles condenses every article whose content runs past the configured length so a single long
+// piece cannot crowd the other sources out of the summarizer prompt. The articles are condensed in parallel,
+// an article whose condensation fails keeps its full text.
+func (workflowExecutor *WorkflowExecutor) condenseLongArticles(ctx context.Context) error {
+	startTime := time.Now()
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+	articles := workflowExecutor.workflowCtx.Articles
+
+	var long []int
+	for i, article := range articles {
+		if len(article.Content) > workflowConfig.CondenseMinChars {
+			long = append(long, i)
+		}
+	}
+	if len(long) == 0 {
+		return nil
+	}
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "condenser", models.AgentStatusProcessing,
+		fmt.Sprintf("Condensing %d long articles", len(long))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish condenser update")
+	}
+
+	condensed := make([]string, len(long))
+	errs := make([]error, len(long))
+	var wg sync.WaitGroup
+	for n, index := range long {
+		wg.Add(1)
+		go func(n int, article models.NewsArticle) {
+			defer wg.Done()
+			condensed[n], errs[n] = workflowExecutor.orchestrator.geminiService.CondenseArticle(ctx,
+				workflowExecutor.workflowCtx.OriginalQuery, article, workflowConfig.CondenseTargetChars)
+		}(n, articles[index])
+	}
+	wg.Wait()
+
+	inputChars, outputChars, done := 0, 0, 0
+	for n, index := range long {
+		workflowExecutor.workflowCtx.ProcessingStats.APICallsCount++
+		if errs[n] != nil {
+			workflowExecutor.logger.WithError(errs[n]).Warn("Article condensation failed, keeping full text",
+				"workflow_id", workflowExecutor.workflowCtx.ID,
+				"url", articles[index].URL)
+			continue
+		}
+		inputChars += len(articles[index].Content)
+		outputChars += len(condensed[n])
+		articles[index].Content = condensed[n]
+		done++
+	}
+	workflowExecutor.workflowCtx.Metadata["condensed_articles"] = done
+
+	duration := time.Since(startTime)
+	workflowExecutor.workflowCtx.UpdateAgentStats("condenser", models.AgentStats{
+		Name:      "condenser",
+		Duration:  duration,
+		Status:    string(models.AgentStatusCompleted),
+		StartTime: startTime,
+		EndTime:   time.Now(),
+	})
+	workflowExecutor.recordAgentExecution("condenser", duration,
+		map[string]any{"long_articles": len(long), "input_chars": inputChars},
+		map[string]any{"condensed": done, "output_chars": outputChars}, nil)
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "condenser", models.AgentStatusCompleted,
+		fmt.Sprintf("Condensed %d of %d long articles", done, len(long))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish condenser completion")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"strings"
+	"testing"
+)
+
+// condenseTestEnv condenses articles over 1000 characters, the test corpus articles are about 500
+var condenseTestEnv = map[string]string{"CONDENSE_ARTICLES": "true", "CONDENSE_MIN_CHARS": "1000", "CONDENSE_TARGET_CHARS": "200"}
+
+func newCondenseWorkflow(t *testing.T) *testWorkflow {
+	t.Helper()
+	cfg := loadTestConfig(t, condenseTestEnv)
+	cfg.Gemini.MaxRetries = 1
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	workflow.corpus.Articles[0].Content = "The long read on elections. " + strings.Repeat("Every district in detail. ", 200)
+	return workflow
+}
+
+func TestOverLengthArticlesAreCondensedBeforeSummarization(t *testing.T) {
+	workflow := newCondenseWorkflow(t)
+	workflow.answerAgent("Condense articles faithfully", func(fakeGeminiCall) string {
+		return "Condensed: the elections hinge on three districts."
+	})
+
+	response, err := workflow.run("workflow-condense")
+	if err != nil || response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("ExecuteWorkflow() = %v, %v", response, err)
+	}
+
+	var condenseCalls []fakeGeminiCall
+	var summarizerPrompt string
+	for _, call := range workflow.gemini.received() {
+		switch {
+		case strings.Contains(call.SystemPrompt, "Condense articles faithfully"):
+			if summarizerPrompt != "" {
+				t.Error("an article was condensed after the summarizer ran")
+			}
+			condenseCalls = append(condenseCalls, call)
+		case strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer"):
+			summarizerPrompt = call.Prompt
+		}
+	}
+
+	if len(condenseCalls) != 1 {
+		t.Fatalf("condensed %d articles, want only the one over the limit", len(condenseCalls))
+	}
+	if prompt := condenseCalls[0].Prompt; !strings.Contains(prompt, "The long read on elections.") || !strings.Contains(prompt, "under 200 characters") {
+		t.Errorf("condense prompt = %.200q, want the long article and the target length", prompt)
+	}
+	if !strings.Contains(summarizerPrompt, "Condensed: the elections hinge on three districts.") {
+		t.Error("the summarizer was not given the condensed article")
+	}
+	if strings.Contains(summarizerPrompt, "Every district in detail.") {
+		t.Error("the summarizer still received the full long article")
+	}
+	if !strings.Contains(summarizerPrompt, "Details about elections.") {
+		t.Error("the summarizer lost the articles under the limit")
+	}
+}
+
+func TestFailedCondensationKeepsTheFullArticle(t *testing.T) {
+	workflow := newCondenseWorkflow(t)
+	workflow.failAgent("Condense articles faithfully")
+
+	response, err := workflow.run("workflow-condense-failed")
+	if err != nil || response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("ExecuteWorkflow() = %v, %v", response, err)
+	}
+
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") && !strings.Contains(call.Prompt, "The long read on elections.") {
+			t.Error("the summarizer lost the article whose condensation failed")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"strings"
+)
+
+const (
+	// EmbeddingInputTitle embeds "title - description", cheap and available straight from the news API
+	EmbeddingInputTitle = "title"
+	// EmbeddingInputContent scrapes every fetched article before embedding and mean-pools the title with chunks
+	// of its leading paragraphs. Vectors reflect the body, at the cost of a scrape pass over the whole fetch before
+	// relevancy, outside the scrape gate. The scraper step skips the articles it already fetched.
+	EmbeddingInputContent = "content"
+)
+
+// articleEmbeddingChunks splits an article into embedding inputs, the title and description first followed by
+// its leading paragraphs packed into chunks of at most chunkChars
+func articleEmbeddingChunks(article models.NewsArticle, maxParagraphs, chunkChars int) []string {
+	chunks := []string{fmt.Sprintf("%s - %s", article.Title, article.Description)}
+	if article.Content == "" || maxParagraphs <= 0 {
+		return chunks
+	}
+
+	var paragraphs []string
+	for _, paragraph := range strings.Split(article.Content, "\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+		if len(paragraphs) == maxParagraphs {
+			break
+		}
+	}
+
+	var current strings.Builder
+	for _, paragraph := range paragraphs {
+		if current.Len() > 0 && current.Len()+len(paragraph)+1 > chunkChars {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		// a single oversized paragraph is cut rather than split, the lead carries most of the meaning
+		current.WriteString(truncateUTF8(paragraph, chunkChars))
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
+
+// meanPool averages equally sized embeddings into one, mismatched vectors are skipped
+func meanPool(embeddings [][]float64) []float64 {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	pooled := make([]float64, len(embeddings[0]))
+	count := 0
+	for _, embedding := range embeddings {
+		if len(embedding) != len(pooled) {
+			continue
+		}
+		for i, value := range embedding {
+			pooled[i] += value
+		}
+		count++
+	}
+
+	for i := range pooled {
+		pooled[i] /= float64(count)
+	}
+	return pooled
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestArticleEmbeddingChunks(t *testing.T) {
+	article := models.NewsArticle{Title: "Rates held", Description: "The bank paused",
+		Content: "First paragraph.\n\nSecond paragraph.\n  \nThird paragraph.\nFourth paragraph."}
+
+	tests := []struct {
+		name          string
+		article       models.NewsArticle
+		maxParagraphs int
+		chunkChars    int
+		want          []string
+	}{
+		{name: "paragraphs packed into chunks", article: article, maxParagraphs: 4, chunkChars: 40,
+			want: []string{"Rates held - The bank paused", "First paragraph.\nSecond paragraph.", "Third paragraph.\nFourth paragraph."}},
+		{name: "only the leading paragraphs", article: article, maxParagraphs: 2, chunkChars: 1500,
+			want: []string{"Rates held - The bank paused", "First paragraph.\nSecond paragraph."}},
+		{name: "an oversized paragraph is cut", article: models.NewsArticle{Title: "Rates held", Description: "The bank paused",
+			Content: strings.Repeat("a", 30)}, maxParagraphs: 4, chunkChars: 10,
+			want: []string{"Rates held - The bank paused", strings.Repeat("a", 10)}},
+		{name: "no content embeds the title", article: models.NewsArticle{Title: "Rates held", Description: "The bank paused"},
+			maxParagraphs: 4, chunkChars: 1500, want: []string{"Rates held - The bank paused"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := articleEmbeddingChunks(tt.article, tt.maxParagraphs, tt.chunkChars); !slices.Equal(got, tt.want) {
+				t.Errorf("articleEmbeddingChunks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeanPool(t *testing.T) {
+	pooled := meanPool([][]float64{{1, 2}, {3, 6}, {9}})
+	if !slices.Equal(pooled, []float64{2, 4}) {
+		t.Errorf("meanPool() = %v, want [2 4] with the mismatched vector skipped", pooled)
+	}
+	if pooled := meanPool(nil); pooled != nil {
+		t.Errorf("meanPool(nil) = %v, want nil", pooled)
+	}
+}
+
+func TestContentEmbeddingsIncludeTheArticleBody(t *testing.T) {
+	for _, input := range []string{EmbeddingInputContent, EmbeddingInputTitle} {
+		t.Run(input, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"EMBEDDING_INPUT": input}), "elections", models.IntentNewNewsQuery)
+			for i := range workflow.corpus.Articles {
+				workflow.corpus.Articles[i].Content = "The count in the northern districts finished overnight.\nTurnout beat the last election."
+			}
+			embeddings := &fakeEmbeddings{}
+			workflow.orchestrator.SetEmbeddingProvider(embeddings)
+
+			if _, err := workflow.run("workflow-embedding-input"); err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var bodyChunks int
+			for _, text := range embeddings.embeddedTexts() {
+				if strings.Contains(text, "northern districts") {
+					bodyChunks++
+				}
+			}
+			want := 0
+			if input == EmbeddingInputContent {
+				want = len(workflow.corpus.Articles)
+			}
+			if bodyChunks != want {
+				t.Errorf("%d embedded texts carry the article body, want %d", bodyChunks, want)
+			}
+		})
+	}
+}
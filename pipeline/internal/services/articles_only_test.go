@@ -0,0 +1,80 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestArticlesOnlyResponseSkipsSummarizationAndRanksSources(t *testing.T) {
+	// the configured freshness first order is ignored, integrators always get the best match first
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"SOURCE_SORT": "freshness"}), "elections", models.IntentNewNewsQuery)
+	// even ids are strong matches and odd ones weak, so relevance and freshness order disagree
+	workflow.answerAgent("news relevancy", func(call fakeGeminiCall) string {
+		var items []string
+		seen := make(map[string]bool)
+		for _, match := range promptArticleID.FindAllStringSubmatch(call.Prompt, -1) {
+			if seen[match[1]] {
+				continue
+			}
+			seen[match[1]] = true
+			score := 0.5
+			if len(items)%2 == 1 {
+				score = 0.9
+			}
+			items = append(items, fmt.Sprintf(`{"id": %s, "relevance_score": %.1f}`, match[1], score))
+		}
+		return fmt.Sprintf(`{"relevant_articles": [%s]}`, strings.Join(items, ", "))
+	})
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-articles-only", Query: "who is ahead in the elections",
+		Metadata: map[string]any{"articles_only_response": true},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.ResponseType != models.ResponseTypeArticlesOnly {
+		t.Errorf("response type = %s, want %s", response.ResponseType, models.ResponseTypeArticlesOnly)
+	}
+	for _, call := range workflow.gemini.received() {
+		for _, skipped := range []string{"intent classifier", "Multimedia News Synthesizer", "Content Personalizer"} {
+			if strings.Contains(call.SystemPrompt, skipped) {
+				t.Errorf("articles only workflow called the %s", skipped)
+			}
+		}
+	}
+	executions := make(map[string]bool)
+	for _, execution := range response.AgentExecutions {
+		executions[execution.AgentName] = true
+	}
+	for _, skipped := range []string{"scrapper", "summarizer", "persona"} {
+		if executions[skipped] {
+			t.Errorf("articles only workflow ran the %s agent", skipped)
+		}
+	}
+	if !executions["relevancy_agent"] {
+		t.Error("articles only workflow skipped the relevancy agent")
+	}
+
+	var articles []models.ResponseSource
+	for _, source := range response.Sources {
+		if source.Type == "article" {
+			articles = append(articles, source)
+		}
+	}
+	if len(articles) < 2 {
+		t.Fatalf("article sources = %+v, want the ranked articles", articles)
+	}
+	for i := 1; i < len(response.Sources); i++ {
+		if relevanceBand(response.Sources[i].RelevanceScore) > relevanceBand(response.Sources[i-1].RelevanceScore) {
+			t.Errorf("source %d (%.2f) ranks below a weaker match (%.2f)", i, response.Sources[i].RelevanceScore, response.Sources[i-1].RelevanceScore)
+		}
+	}
+	if first, last := articles[0].RelevanceScore, articles[len(articles)-1].RelevanceScore; first != 0.9 || last != 0.5 {
+		t.Errorf("articles run from %.2f to %.2f, want the strong matches first", first, last)
+	}
+}
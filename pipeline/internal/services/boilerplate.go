@@ -0,0 +1,73 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+)
+
+// leadingBoilerplatePatterns match bylines, datelines and reading-time lines that open a scraped article
+var leadingBoilerplatePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(By|BY)\s+\p{Lu}[\p{L}.'\-]+(\s+\p{Lu}[\p{L}.'\-]+){1,3}\s*(,|\||\band\b|-|$)`),
+	regexp.MustCompile(`(?i)^(published|updated|posted|last updated|first published)\b`),
+	regexp.MustCompile(`(?i)^(\w+\s+)?\d{1,2}:\d{2}\s*(am|pm)?\b`),
+	regexp.MustCompile(`(?i)^(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{1,2},?\s+\d{4}\b`),
+	regexp.MustCompile(`(?i)^\d{1,2}\s+(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{4}\b`),
+	regexp.MustCompile(`(?i)^\d+\s+min(ute)?s?\s+read\b`),
+	regexp.MustCompile(`(?i)^(listen to this article|this article is more than \d+ \w+ old)`),
+}
+
+// trailingBoilerplatePatterns match the credits, corrections and onward links that close a scraped article
+var trailingBoilerplatePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)^(reporting|additional reporting|writing|editing) by\b`),
+	regexp.MustCompile(`(?i)^(related|more|read more|see also|also read|recommended|more from|more on this story)\b\s*[:\-]?`),
+	regexp.MustCompile(`(?i)^(this (story|article) (has been|was) (updated|corrected))`),
+	regexp.MustCompile(`(?i)^(correction|clarification|editor'?s note)\s*:`),
+	regexp.MustCompile(`(?i)^(sign up|subscribe|get the latest|download our app|follow us|join our)\b`),
+	regexp.MustCompile(`(?i)^(copyright|©|\(c\))\s*\d{0,4}`),
+	regexp.MustCompile(`(?i)(all rights reserved|our standards:|the thomson reuters trust principles)\.?\s*$`),
+}
+
+// extendedNoisePhrases drop whole paragraphs on top of the scraper's base noise list
+var extendedNoisePhrases = []string{
+	"all rights reserved", "sponsored content", "recommended for you",
+	"download our app", "continue reading", "skip to content",
+	"listen to this article", "story continues below", "turn off your ad blocker",
+	"support our journalism", "already a subscriber", "create a free account",
+	"enable javascript", "this content is not available", "reporting by", "editing by",
+}
+
+// longer paragraphs carry real content even when they open with a dateline or close with a credit
+const maxBoilerplateParagraphLength = 200
+
+// trimBoilerplateParagraphs drops up to maxTrim paragraphs from each end of an article while they read as
+// boilerplate. At least one paragraph is always kept.
+func trimBoilerplateParagraphs(paragraphs []string, maxTrim int) []string {
+	if maxTrim <= 0 {
+		return paragraphs
+	}
+
+	start := 0
+	for start < maxTrim && start < len(paragraphs)-1 && isBoilerplateParagraph(paragraphs[start], leadingBoilerplatePatterns) {
+		start++
+	}
+
+	end := len(paragraphs)
+	for trimmed := 0; trimmed < maxTrim && end-1 > start && isBoilerplateParagraph(paragraphs[end-1], trailingBoilerplatePatterns); trimmed++ {
+		end--
+	}
+
+	return paragraphs[start:end]
+}
+
+func isBoilerplateParagraph(text string, patterns []*regexp.Regexp) bool {
+	text = strings.TrimSpace(text)
+	if len(text) > maxBoilerplateParagraphLength {
+		return false
+	}
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrimBoilerplateParagraphs(t *testing.T) {
+	body := []string{"The council approved the budget after a six hour debate.", "Spending on schools rises by a tenth."}
+
+	tests := []struct {
+		name       string
+		paragraphs []string
+		maxTrim    int
+		want       []string
+	}{
+		{name: "byline and dateline open the article",
+			paragraphs: slices.Concat([]string{"By Jane Smith and Tom Ellis, Reuters", "Published October 15, 2026"}, body),
+			maxTrim:    2, want: body},
+		{name: "credits and onward links close the article",
+			paragraphs: slices.Concat(body, []string{"Related: how the council spends", "Reporting by Jane Smith; Editing by Tom Ellis"}),
+			maxTrim:    2, want: body},
+		{name: "at most maxTrim from each end",
+			paragraphs: slices.Concat([]string{"By Jane Smith, Reuters", "Updated 3 hours ago", "5 min read"}, body),
+			maxTrim:    2, want: slices.Concat([]string{"5 min read"}, body)},
+		{name: "trimming disabled", paragraphs: slices.Concat([]string{"By Jane Smith, Reuters"}, body),
+			maxTrim: 0, want: slices.Concat([]string{"By Jane Smith, Reuters"}, body)},
+		{name: "one paragraph is always kept", paragraphs: []string{"By Jane Smith, Reuters"},
+			maxTrim: 2, want: []string{"By Jane Smith, Reuters"}},
+		{name: "long paragraphs are content even with a dateline",
+			paragraphs: slices.Concat([]string{"Published October 15, 2026: " + strings.Repeat("the council debated the budget line by line ", 5)}, body),
+			maxTrim:    2, want: slices.Concat([]string{"Published October 15, 2026: " + strings.Repeat("the council debated the budget line by line ", 5)}, body)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimBoilerplateParagraphs(tt.paragraphs, tt.maxTrim); !slices.Equal(got, tt.want) {
+				t.Errorf("trimBoilerplateParagraphs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// boilerplateWrappedArticle is a publisher page whose body sits between a byline, a dateline, a sponsor
+// note and the usual update notice and onward links
+var boilerplateWrappedArticle = []string{
+	"By Jane Smith and Tom Ellis, Reuters staff writers covering the central bank",
+	"Published October 15, 2026 at 9:30 am and last updated an hour later today",
+	"The central bank held its policy rate at five percent on Wednesday, pointing to stubborn services inflation.",
+	"Presented by Example Bank, the official partner of our markets and economy coverage",
+	"Officials signalled that one cut remained likely before the end of the year if wage growth keeps cooling.",
+	"Bond yields fell after the decision as traders brought forward their bets on the timing of the first cut.",
+	"More on this story: how the central bank decided the path of interest rates this year",
+	"This story has been updated with comments from officials at the finance ministry",
+}
+
+func TestScrapeTrimsBoilerplateAroundTheArticle(t *testing.T) {
+	t.Parallel()
+	var html strings.Builder
+	html.WriteString("<html><head><title>Rates held</title></head><body><article>")
+	for _, paragraph := range boilerplateWrappedArticle {
+		fmt.Fprintf(&html, "<p>%s</p>", paragraph)
+	}
+	html.WriteString("</article></body></html>")
+	server := newArticlePage(t, html.String())
+
+	tests := []struct {
+		name   string
+		config config.ScraperConfig
+		want   []string
+		absent []string
+	}{
+		{name: "trimmed", config: config.ScraperConfig{TrimBoilerplate: true, BoilerplateMaxParagraphs: 2, NoisePhrases: []string{"Official Partner"}},
+			want: boilerplateWrappedArticle[2:3], absent: []string{"By Jane Smith", "Published October", "official partner", "More on this story", "has been updated"}},
+		{name: "untouched", config: config.ScraperConfig{},
+			want: []string{"By Jane Smith", "official partner", "has been updated"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.config.Timeout, tt.config.RetryAttempts, tt.config.MinContentLength = 10*time.Second, 1, 60
+			scraper, err := NewScraperService(tt.config, nil, newTestLogger(t))
+			if err != nil {
+				t.Fatalf("NewScraperService() error = %v", err)
+			}
+
+			content, err := scraper.ScrapeURL(context.Background(), server.URL+"/rates")
+			if err != nil || !content.Success {
+				t.Fatalf("ScrapeURL() = %+v, %v, want a successful scrape", content, err)
+			}
+
+			// the story itself always survives
+			for _, paragraph := range []string{boilerplateWrappedArticle[2], boilerplateWrappedArticle[4], boilerplateWrappedArticle[5]} {
+				if !strings.Contains(content.Content, paragraph) {
+					t.Errorf("content lost %q", paragraph)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(content.Content, want) {
+					t.Errorf("content is missing %q", want)
+				}
+			}
+			for _, boilerplate := range tt.absent {
+				if strings.Contains(content.Content, boilerplate) {
+					t.Errorf("content still contains %q", boilerplate)
+				}
+			}
+		})
+	}
+}
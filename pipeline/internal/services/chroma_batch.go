@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	defaultChromaBatchSize        = 25
+	defaultChromaBatchConcurrency = 3
+)
+
+// BatchStoreError reports a store where some chunks were rejected, the rest of the documents were written
+type BatchStoreError struct {
+	Total     int
+	Stored    int
+	FailedIDs []string
+	Errs      []error
+}
+
+func (batchErr *BatchStoreError) Error() string {
+	return fmt.Sprintf("stored %d of %d documents, %d chunks failed: %v", batchErr.Stored, batchErr.Total, len(batchErr.Errs), errors.Join(batchErr.Errs...))
+}
+
+func (batchErr *BatchStoreError) Unwrap() []error {
+	return batchErr.Errs
+}
+
+// StoredCount returns how many documents a store call wrote, taking partial batch failures into account
+func StoredCount(err error, total int) int {
+	if err == nil {
+		return total
+	}
+	var batchErr *BatchStoreError
+	if errors.As(err, &batchErr) {
+		return batchErr.Stored
+	}
+	return 0
+}
+
+// chunkAddRequest splits a request into contiguous chunks of at most size documents, preserving input order
+func chunkAddRequest(request AddRequest, size int) []AddRequest {
+	if size <= 0 {
+		size = defaultChromaBatchSize
+	}
+
+	chunks := make([]AddRequest, 0, (len(request.IDs)+size-1)/size)
+	for start := 0; start < len(request.IDs); start += size {
+		end := min(start+size, len(request.IDs))
+		chunk := AddRequest{
+			IDs:        request.IDs[start:end],
+			Embeddings: request.Embeddings[start:end],
+		}
+		if len(request.Documents) > 0 {
+			chunk.Documents = request.Documents[start:end]
+		}
+		if len(request.Metadatas) > 0 {
+			chunk.Metadatas = request.Metadatas[start:end]
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// addInBatches submits the request in chunks with bounded concurrency. A failed chunk does not stop the others,
+// a *BatchStoreError lists what was not written.
+func (service *ChromaDBService) addInBatches(ctx context.Context, collectionName string, request AddRequest) error {
+	chunks := chunkAddRequest(request, service.batchSize)
+	if len(chunks) == 1 {
+		return service.addToCollection(ctx, collectionName, chunks[0])
+	}
+
+	return submitChunks(chunks, service.batchConcurrency, func(chunk AddRequest) error {
+		return service.addToCollection(ctx, collectionName, chunk)
+	})
+}
+
+func submitChunks(chunks []AddRequest, concurrency int, submit func(chunk AddRequest) error) error {
+	if concurrency <= 0 {
+		concurrency = defaultChromaBatchConcurrency
+	}
+
+	chunkErrs := make([]error, len(chunks))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, chunk AddRequest) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			chunkErrs[i] = submit(chunk)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	batchErr := &BatchStoreError{}
+	for i, chunk := range chunks {
+		batchErr.Total += len(chunk.IDs)
+		if chunkErrs[i] != nil {
+			batchErr.FailedIDs = append(batchErr.FailedIDs, chunk.IDs...)
+			batchErr.Errs = append(batchErr.Errs, fmt.Errorf("chunk %d: %w", i, chunkErrs[i]))
+			continue
+		}
+		batchErr.Stored += len(chunk.IDs)
+	}
+
+	if len(batchErr.Errs) == 0 {
+		return nil
+	}
+	return batchErr
+}
+
+// truncateUTF8 cuts s to at most limit bytes without splitting a multi-byte rune
+func truncateUTF8(s string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
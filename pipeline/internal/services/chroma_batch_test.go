@@ -0,0 +1,81 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func batchArticles(n int) ([]models.NewsArticle, [][]float64) {
+	articles := make([]models.NewsArticle, n)
+	embeddings := make([][]float64, n)
+	for i := range articles {
+		articles[i] = models.NewsArticle{ID: fmt.Sprintf("article-%02d", i), Title: fmt.Sprintf("story %d", i),
+			Content: strings.Repeat("scraped body ", 200)}
+		embeddings[i] = fakeEmbedding(articles[i].Title)
+	}
+	return articles, embeddings
+}
+
+func TestStoreArticlesSplitsLargeSetsIntoChunks(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	service.batchSize, service.batchConcurrency, service.metadataContentLimit = 25, 2, 100
+	articles, embeddings := batchArticles(60)
+
+	if err := service.StoreArticles(context.Background(), articles, embeddings); err != nil {
+		t.Fatalf("StoreArticles() error = %v", err)
+	}
+
+	chunks := chroma.writes(NewsCollectionName, false)
+	var sizes []int
+	var ids []string
+	for _, chunk := range chunks {
+		sizes = append(sizes, len(chunk.IDs))
+		ids = append(ids, chunk.IDs...)
+		for _, metadata := range chunk.Metadatas {
+			if content, _ := metadata["content"].(string); len(content) > 100 {
+				t.Errorf("metadata content is %d bytes, want at most the 100 byte limit", len(content))
+			}
+		}
+	}
+	slices.Sort(sizes)
+	if !slices.Equal(sizes, []int{10, 25, 25}) {
+		t.Errorf("chunk sizes = %v, want 25, 25 and 10", sizes)
+	}
+	slices.Sort(ids)
+	if len(ids) != 60 || ids[0] != "article-00" || ids[59] != "article-59" {
+		t.Errorf("stored %d ids, want every article once", len(ids))
+	}
+}
+
+func TestStoreArticlesReportsAFailedChunkWithoutAbortingTheRest(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	service.batchSize, service.batchConcurrency = 25, 2
+	chroma.rejects = func(collection string, request AddRequest) bool {
+		return slices.Contains(request.IDs, "article-30")
+	}
+	articles, embeddings := batchArticles(60)
+
+	err := service.StoreArticles(context.Background(), articles, embeddings)
+
+	var batchErr *BatchStoreError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("StoreArticles() error = %v, want a *BatchStoreError", err)
+	}
+	if batchErr.Total != 60 || batchErr.Stored != 35 || len(batchErr.Errs) != 1 {
+		t.Errorf("batch error = %d of %d stored with %d failed chunks, want 35 of 60 with 1", batchErr.Stored, batchErr.Total, len(batchErr.Errs))
+	}
+	if len(batchErr.FailedIDs) != 25 || batchErr.FailedIDs[0] != "article-25" || batchErr.FailedIDs[24] != "article-49" {
+		t.Errorf("failed ids = %v, want the second chunk", batchErr.FailedIDs)
+	}
+	if got := StoredCount(err, len(articles)); got != 35 {
+		t.Errorf("StoredCount() = %d, want 35", got)
+	}
+	if chunks := chroma.writes(NewsCollectionName, false); len(chunks) != 2 {
+		t.Errorf("stored chunks = %d, want the first and last chunk written", len(chunks))
+	}
+}
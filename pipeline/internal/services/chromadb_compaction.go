@@ -0,0 +1,156 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// trackingParams are stripped before urls are compared, they differ between copies of the same story
+var trackingParams = []string{"utm_", "fbclid", "gclid", "ocid", "cmpid"}
+
+// canonicalDocumentURL reduces a stored url to the parts that identify the story: scheme, "www.", fragment,
+// tracking parameters and a trailing slash are dropped
+func canonicalDocumentURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return strings.ToLower(raw)
+	}
+
+	query := parsed.Query()
+	for key := range query {
+		for _, param := range trackingParams {
+			if strings.HasPrefix(strings.ToLower(key), param) {
+				query.Del(key)
+				break
+			}
+		}
+	}
+
+	canonical := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.") + strings.TrimRight(parsed.Path, "/")
+	if encoded := query.Encode(); encoded != "" {
+		canonical += "?" + encoded
+	}
+	return canonical
+}
+
+// storedDocument is the part of a stored document compaction needs
+type storedDocument struct {
+	id       string
+	storedAt time.Time
+}
+
+// fresherThan prefers the later store time, ties go to the smaller id so repeated runs agree
+func (document storedDocument) fresherThan(other storedDocument) bool {
+	if !document.storedAt.Equal(other.storedAt) {
+		return document.storedAt.After(other.storedAt)
+	}
+	return document.id < other.id
+}
+
+func storedDocumentFromMetadata(id string, metadata map[string]interface{}) storedDocument {
+	document := storedDocument{id: id}
+	for _, field := range []string{"stored_at", "published_at"} {
+		value, _ := metadata[field].(string)
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			document.storedAt = parsed
+			break
+		}
+	}
+	return document
+}
+
+// CompactCollection collapses documents that share a canonical url under different ids, the freshest copy is
+// kept and the others deleted. With dryRun the duplicates are only counted.
+func (service *ChromaDBService) CompactCollection(ctx context.Context, collectionName string, dryRun bool) (*models.CompactionReport, error) {
+	if collectionName != NewsCollectionName && collectionName != VideosCollectionName {
+		return nil, fmt.Errorf("unknown collection %q", collectionName)
+	}
+
+	startTime := time.Now()
+	report := &models.CompactionReport{Collection: collectionName, DryRun: dryRun}
+
+	kept := make(map[string]storedDocument)
+	var duplicates []string
+	groups := make(map[string]bool)
+
+	for offset := 0; ; offset += service.compactionPageSize {
+		page, err := service.getFromCollection(ctx, collectionName, GetRequest{
+			Limit:   service.compactionPageSize,
+			Offset:  offset,
+			Include: []string{"metadatas"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read collection %s for compaction: %w", collectionName, err)
+		}
+
+		for i, id := range page.IDs {
+			var metadata map[string]interface{}
+			if i < len(page.Metadatas) {
+				metadata = page.Metadatas[i]
+			}
+			report.Scanned++
+
+			rawURL, _ := metadata["url"].(string)
+			if rawURL == "" {
+				continue
+			}
+			key := canonicalDocumentURL(rawURL)
+			document := storedDocumentFromMetadata(id, metadata)
+
+			existing, seen := kept[key]
+			if !seen {
+				kept[key] = document
+				continue
+			}
+
+			groups[key] = true
+			if document.fresherThan(existing) {
+				kept[key] = document
+				duplicates = append(duplicates, existing.id)
+			} else {
+				duplicates = append(duplicates, document.id)
+			}
+		}
+
+		if len(page.IDs) < service.compactionPageSize {
+			break
+		}
+	}
+
+	report.DuplicateGroups = len(groups)
+	report.Duplicates = len(duplicates)
+
+	if !dryRun {
+		for start := 0; start < len(duplicates); start += service.compactionPageSize {
+			batch := duplicates[start:min(start+service.compactionPageSize, len(duplicates))]
+			var err error
+			if collectionName == NewsCollectionName {
+				err = service.DeleteArticles(ctx, batch)
+			} else {
+				err = service.DeleteVideos(ctx, batch)
+			}
+			if err != nil {
+				report.DurationMs = time.Since(startTime).Milliseconds()
+				return report, fmt.Errorf("Failed to delete duplicates from %s after %d: %w", collectionName, report.Deleted, err)
+			}
+			report.Deleted += len(batch)
+		}
+	}
+
+	report.DurationMs = time.Since(startTime).Milliseconds()
+	service.logger.LogService("chromadb", "compact_collection", time.Since(startTime), map[string]interface{}{
+		"collection":       collectionName,
+		"scanned":          report.Scanned,
+		"duplicate_groups": report.DuplicateGroups,
+		"duplicates":       report.Duplicates,
+		"deleted":          report.Deleted,
+		"dry_run":          dryRun,
+	}, nil)
+
+	return report, nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCanonicalDocumentURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "https://www.example.com/story/", want: "example.com/story"},
+		{raw: "http://Example.com/story#comments", want: "example.com/story"},
+		{raw: "https://example.com/story?utm_source=feed&fbclid=abc", want: "example.com/story"},
+		{raw: "https://example.com/story?page=2&utm_medium=rss", want: "example.com/story?page=2"},
+		{raw: "https://www.youtube.com/watch?v=abc", want: "youtube.com/watch?v=abc"},
+	}
+	for _, tt := range tests {
+		if got := canonicalDocumentURL(tt.raw); got != tt.want {
+			t.Errorf("canonicalDocumentURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+// storeCompactionFixture writes documents straight into the fake collection, url and stored_at per id
+func storeCompactionFixture(chroma *fakeChroma, collection string, documents map[string][2]string) {
+	var request AddRequest
+	for id, fields := range documents {
+		request.IDs = append(request.IDs, id)
+		request.Documents = append(request.Documents, "document "+id)
+		request.Metadatas = append(request.Metadatas, map[string]interface{}{"url": fields[0], "stored_at": fields[1]})
+	}
+	chroma.added[collection] = append(chroma.added[collection], request)
+}
+
+func storedIDs(chroma *fakeChroma, collection string) []string {
+	var ids []string
+	for _, document := range chroma.stored(collection, nil, nil) {
+		ids = append(ids, document.id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestCompactCollectionKeepsTheFreshestCopy(t *testing.T) {
+	now := time.Now().UTC()
+	at := func(age time.Duration) string { return now.Add(-age).Format(time.RFC3339) }
+
+	tests := []struct {
+		name       string
+		collection string
+		dryRun     bool
+		wantKept   []string
+	}{
+		{name: "news", collection: NewsCollectionName, wantKept: []string{"budget-new", "launch", "rates-new", "untracked"}},
+		{name: "dry run", collection: NewsCollectionName, dryRun: true,
+			wantKept: []string{"budget-mid", "budget-new", "budget-old", "launch", "rates-new", "rates-old", "untracked"}},
+		{name: "videos", collection: VideosCollectionName, wantKept: []string{"budget-new", "launch", "rates-new", "untracked"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chroma, service := newFakeChroma(t)
+			// two documents per page so the duplicates span pages
+			service.compactionPageSize = 2
+			storeCompactionFixture(chroma, tt.collection, map[string][2]string{
+				"budget-old": {"https://www.example.com/budget?utm_source=feed", at(72 * time.Hour)},
+				"budget-new": {"https://example.com/budget/", at(time.Hour)},
+				"budget-mid": {"http://example.com/budget#top", at(24 * time.Hour)},
+				"rates-old":  {"https://example.com/rates", at(48 * time.Hour)},
+				"rates-new":  {"https://example.com/rates?fbclid=abc", at(2 * time.Hour)},
+				"launch":     {"https://example.com/launch", at(time.Hour)},
+				"untracked":  {"", at(time.Hour)},
+			})
+
+			report, err := service.CompactCollection(context.Background(), tt.collection, tt.dryRun)
+			if err != nil {
+				t.Fatalf("CompactCollection() error = %v", err)
+			}
+
+			if report.Scanned != 7 || report.DuplicateGroups != 2 || report.Duplicates != 3 {
+				t.Errorf("report = %+v, want 7 scanned and 3 duplicates in 2 groups", report)
+			}
+			wantDeleted := 3
+			if tt.dryRun {
+				wantDeleted = 0
+			}
+			if report.Deleted != wantDeleted || report.DryRun != tt.dryRun {
+				t.Errorf("deleted %d with dry run %t, want %d", report.Deleted, report.DryRun, wantDeleted)
+			}
+			if got := storedIDs(chroma, tt.collection); !reflect.DeepEqual(got, tt.wantKept) {
+				t.Errorf("collection holds %v, want %v", got, tt.wantKept)
+			}
+		})
+	}
+}
+
+func TestCompactCollectionRejectsUnknownCollections(t *testing.T) {
+	_, service := newFakeChroma(t)
+	service.compactionPageSize = 10
+
+	if _, err := service.CompactCollection(context.Background(), "user_profiles", false); err == nil {
+		t.Error("CompactCollection() compacted a collection that is not news or videos")
+	}
+}
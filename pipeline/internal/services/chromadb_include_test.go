@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// storeIncludeFixture stores one article and one video whose documents differ from the descriptions in their metadata
+func storeIncludeFixture(chroma *fakeChroma, embedding []float64) {
+	chroma.added[NewsCollectionName] = append(chroma.added[NewsCollectionName], AddRequest{
+		IDs:        []string{"article-1"},
+		Embeddings: [][]float64{embedding},
+		Documents:  []string{"Budget passes. The full stored document text."},
+		Metadatas: []map[string]interface{}{{
+			"id": "article-1", "title": "Budget passes", "url": "https://news.example.com/budget",
+			"description": "Parliament passed the budget.", "published_at": "2026-10-16T09:00:00Z",
+		}},
+	})
+	chroma.added[VideosCollectionName] = append(chroma.added[VideosCollectionName], AddRequest{
+		IDs:        []string{"video-1"},
+		Embeddings: [][]float64{embedding},
+		Documents:  []string{"Budget explained. The full stored transcript text."},
+		Metadatas: []map[string]interface{}{{
+			"video_id": "video-1", "title": "Budget explained", "url": "https://www.youtube.com/watch?v=video-1",
+			"description": "The budget in five minutes.", "published_at": "2026-10-16T09:00:00Z",
+		}},
+	})
+}
+
+func TestSimilarityQueriesRequestTheConfiguredFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		// descriptions come from the stored document when it is fetched, from metadata otherwise
+		wantArticleDescription string
+		wantVideoDescription   string
+	}{
+		{name: "lean default", include: []string{"metadatas", "distances"},
+			wantArticleDescription: "Parliament passed the budget.", wantVideoDescription: "The budget in five minutes."},
+		{name: "documents", include: []string{"documents", "metadatas", "distances"},
+			wantArticleDescription: "Budget passes. The full stored document text.", wantVideoDescription: "Budget explained. The full stored transcript text."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chroma, service := newFakeChroma(t)
+			service.queryInclude = tt.include
+			embedding := fakeEmbedding("budget")
+			storeIncludeFixture(chroma, embedding)
+			ctx := context.Background()
+
+			articles, err := service.SearchSimilarArticles(ctx, embedding, 5, 0, nil)
+			if err != nil || len(articles) != 1 {
+				t.Fatalf("SearchSimilarArticles() = %d results, %v", len(articles), err)
+			}
+			hybrid, err := service.SearchArticlesHybrid(ctx, embedding, ArticleMetadataFilter{}, 5, 0)
+			if err != nil || len(hybrid) != 1 {
+				t.Fatalf("SearchArticlesHybrid() = %d results, %v", len(hybrid), err)
+			}
+			videos, err := service.SearchSimilarVideos(ctx, embedding, 5, 0, nil)
+			if err != nil || len(videos) != 1 {
+				t.Fatalf("SearchSimilarVideos() = %d results, %v", len(videos), err)
+			}
+
+			queries := chroma.received("query")
+			if len(queries) != 3 {
+				t.Fatalf("chroma received %d queries, want 3", len(queries))
+			}
+			for _, query := range queries {
+				var include []string
+				for _, field := range query["include"].([]interface{}) {
+					include = append(include, field.(string))
+				}
+				if !slices.Equal(include, tt.include) {
+					t.Errorf("query include = %v, want %v", include, tt.include)
+				}
+				if slices.Contains(include, "embeddings") {
+					t.Error("query requested the stored embeddings")
+				}
+			}
+
+			for _, result := range append(articles, hybrid...) {
+				if result.Document.Description != tt.wantArticleDescription || result.Document.Title != "Budget passes" {
+					t.Errorf("article = %q %q, want description %q", result.Document.Title, result.Document.Description, tt.wantArticleDescription)
+				}
+			}
+			if video := videos[0].VideoDocument; video.Description != tt.wantVideoDescription || video.Title != "Budget explained" {
+				t.Errorf("video = %q %q, want description %q", video.Title, video.Description, tt.wantVideoDescription)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ArticleMetadataFilter narrows stored articles without an embedding, zero value fields are ignored
+type ArticleMetadataFilter struct {
+	Source   string    `json:"source,omitempty"`
+	Category string    `json:"category,omitempty"`
+	Since    time.Time `json:"since,omitempty"`
+	// matched against the stored title and description, any keyword is enough
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+type GetRequest struct {
+	Where         map[string]interface{} `json:"where,omitempty"`
+	WhereDocument map[string]interface{} `json:"where_document,omitempty"`
+	Limit         int                    `json:"limit,omitempty"`
+	Offset        int                    `json:"offset,omitempty"`
+	Include       []string               `json:"include,omitempty"`
+}
+
+type GetResponse struct {
+	IDs       []string                 `json:"ids"`
+	Documents []string                 `json:"documents"`
+	Metadatas []map[string]interface{} `json:"metadatas"`
+}
+
+func (filter ArticleMetadataFilter) IsEmpty() bool {
+	return filter.Source == "" && filter.Category == "" && filter.Since.IsZero() && len(filter.Keywords) == 0
+}
+
+// where builds the metadata clause, chroma only accepts one field per clause so several are wrapped in $and
+func (filter ArticleMetadataFilter) where() map[string]interface{} {
+	var clauses []map[string]interface{}
+
+	if filter.Source != "" {
+		clauses = append(clauses, map[string]interface{}{"source": filter.Source})
+	}
+	if filter.Category != "" {
+		// stored categories are lower case, see ArticleCategorizer
+		clauses = append(clauses, map[string]interface{}{"category": strings.ToLower(filter.Category)})
+	}
+	if !filter.Since.IsZero() {
+		clauses = append(clauses, map[string]interface{}{
+			"published_at": map[string]interface{}{"$gte": filter.Since.Format(time.RFC3339)},
+		})
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil
+	case 1:
+		return clauses[0]
+	default:
+		return map[string]interface{}{"$and": clauses}
+	}
+}
+
+// whereDocument builds the document text clause, $contains is case sensitive so a capitalised variant is tried too
+func (filter ArticleMetadataFilter) whereDocument() map[string]interface{} {
+	var clauses []map[string]interface{}
+	seen := make(map[string]bool)
+
+	for _, keyword := range filter.Keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		for _, variant := range []string{keyword, capitalizeFirst(keyword)} {
+			if seen[variant] {
+				continue
+			}
+			seen[variant] = true
+			clauses = append(clauses, map[string]interface{}{"$contains": variant})
+		}
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil
+	case 1:
+		return clauses[0]
+	default:
+		return map[string]interface{}{"$or": clauses}
+	}
+}
+
+func capitalizeFirst(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if r == utf8.RuneError {
+		return text
+	}
+	return string(unicode.ToUpper(r)) + text[size:]
+}
+
+// SearchArticlesByMetadata returns up to limit stored articles matching the filter without any vector ranking,
+// results carry no similarity. Useful for exact lookups such as everything from one source about a keyword this week.
+func (service *ChromaDBService) SearchArticlesByMetadata(ctx context.Context, filter ArticleMetadataFilter, limit int) ([]SearchResult, error) {
+	if filter.IsEmpty() {
+		return nil, fmt.Errorf("metadata filter cannot be empty")
+	}
+
+	if limit <= 0 {
+		limit = DefaultTopK
+	}
+
+	startTime := time.Now()
+
+	getRequest := GetRequest{
+		Where:         filter.where(),
+		WhereDocument: filter.whereDocument(),
+		Limit:         limit,
+		Include:       []string{"documents", "metadatas"},
+	}
+
+	getResponse, err := service.getFromCollection(ctx, NewsCollectionName, getRequest)
+	if err != nil {
+		service.logger.LogService("chromadb", "search_articles_by_metadata", time.Since(startTime), map[string]interface{}{
+			"limit": limit,
+		}, err)
+		return nil, fmt.Errorf("metadata search failed: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(getResponse.IDs))
+	for i := range getResponse.IDs {
+		if i >= len(getResponse.Metadatas) || i >= len(getResponse.Documents) {
+			break
+		}
+		metadata := getResponse.Metadatas[i]
+		results = append(results, SearchResult{
+			Document:   articleFromMetadata(metadata, getResponse.Documents[i]),
+			contentRef: getString(metadata, "content_ref"),
+		})
+	}
+	service.loadArticleContent(ctx, results)
+
+	service.logger.LogService("chromadb", "search_articles_by_metadata", time.Since(startTime), map[string]interface{}{
+		"limit":         limit,
+		"results_count": len(results),
+		"has_keywords":  len(filter.Keywords) > 0,
+		"collection":    NewsCollectionName,
+	}, nil)
+
+	return results, nil
+}
+
+// SearchArticlesHybrid ranks only the stored articles matching the filter by similarity to the query embedding
+func (service *ChromaDBService) SearchArticlesHybrid(ctx context.Context, queryEmbedding []float64, filter ArticleMetadataFilter, topK int, minSimilarity float64) ([]SearchResult, error) {
+	if len(queryEmbedding) == 0 {
+		return nil, fmt.Errorf("query_embedding cannot be empty")
+	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "article_hybrid_search", "", func(ctx context.Context) ([]SearchResult, error) {
+			return service.SearchArticlesHybrid(ctx, queryEmbedding, filter, topK, minSimilarity)
+		})
+	}
+
+	if topK <= 0 {
+		topK = DefaultTopK
+	}
+
+	startTime := time.Now()
+
+	queryRequest := QueryRequest{
+		QueryEmbeddings: [][]float64{queryEmbedding},
+		NResults:        topK,
+		Where:           filter.where(),
+		WhereDocument:   filter.whereDocument(),
+		Include:         service.queryInclude,
+	}
+
+	queryResponse, err := service.queryCollection(ctx, NewsCollectionName, queryRequest)
+	if err != nil {
+		service.logger.LogService("chromadb", "search_articles_hybrid", time.Since(startTime), map[string]interface{}{
+			"top_k": topK,
+		}, err)
+		return nil, fmt.Errorf("hybrid search query failed: %w", err)
+	}
+
+	results, dropped := service.convertToSearchResults(queryResponse, minSimilarity)
+	service.loadArticleContent(ctx, results)
+
+	service.logger.LogService("chromadb", "search_articles_hybrid", time.Since(startTime), map[string]interface{}{
+		"top_k":          topK,
+		"results_count":  len(results),
+		"min_similarity": minSimilarity,
+		"below_min":      dropped,
+		"has_keywords":   len(filter.Keywords) > 0,
+		"collection":     NewsCollectionName,
+	}, nil)
+
+	return results, nil
+}
+
+func (service *ChromaDBService) getFromCollection(ctx context.Context, collectionName string, getRequest GetRequest) (*GetResponse, error) {
+	collectionID, err := service.getCollectionID(ctx, collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get collection ID: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s/get", service.baseURL, service.tenant, service.database, collectionID)
+
+	jsonData, err := json.Marshal(getRequest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshall get request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create get request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := service.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed get collection request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed get collection request: invalid status code: %d", resp.StatusCode)
+	}
+
+	var getResponse GetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&getResponse); err != nil {
+		return nil, fmt.Errorf("Failed to decode get response: %w", err)
+	}
+
+	return &getResponse, nil
+}
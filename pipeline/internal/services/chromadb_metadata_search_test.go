@@ -0,0 +1,171 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+// storeMetadataSearchArticles stores one article matching every filter of the tests and one missing each of them
+func storeMetadataSearchArticles(t *testing.T, service *ChromaDBService) time.Time {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	article := func(id, source, category, title string, age time.Duration) models.NewsArticle {
+		return models.NewsArticle{ID: id, Source: source, Category: category, Title: title,
+			Description: "Coverage for the week", URL: "https://news.example.com/" + id, PublishedAt: now.Add(-age)}
+	}
+	articles := []models.NewsArticle{
+		article("match", "Reuters", "business", "Tesla cuts prices again", 24*time.Hour),
+		article("old", "Reuters", "business", "Tesla opens a new plant", 30*24*time.Hour),
+		article("other-source", "BBC", "business", "Tesla deliveries slow", 24*time.Hour),
+		article("other-keyword", "Reuters", "business", "Ford recalls trucks", 24*time.Hour),
+		article("other-category", "Reuters", "sports", "Tesla sponsors a team", 24*time.Hour),
+	}
+	embeddings := make([][]float64, len(articles))
+	for i, article := range articles {
+		embeddings[i] = fakeEmbedding(article.Title)
+	}
+	if err := service.StoreArticles(context.Background(), articles, embeddings); err != nil {
+		t.Fatalf("StoreArticles() error = %v", err)
+	}
+	return now
+}
+
+func assertJSONEqual(t *testing.T, name string, got interface{}, want interface{}) {
+	t.Helper()
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("%s = %s, want %s", name, gotJSON, wantJSON)
+	}
+}
+
+func resultIDs(results []SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Document.ID
+	}
+	return ids
+}
+
+func TestSearchArticlesByMetadataBuildsTheWhereClauses(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	now := storeMetadataSearchArticles(t, service)
+	since := now.Add(-7 * 24 * time.Hour)
+
+	results, err := service.SearchArticlesByMetadata(context.Background(), ArticleMetadataFilter{
+		Source: "Reuters", Category: "Business", Since: since, Keywords: []string{"tesla", " "},
+	}, 5)
+	if err != nil {
+		t.Fatalf("SearchArticlesByMetadata() error = %v", err)
+	}
+
+	requests := chroma.received("get")
+	if len(requests) != 1 {
+		t.Fatalf("get requests = %d, want 1", len(requests))
+	}
+	assertJSONEqual(t, "where", requests[0]["where"], map[string]interface{}{"$and": []interface{}{
+		map[string]interface{}{"source": "Reuters"},
+		map[string]interface{}{"category": "business"},
+		map[string]interface{}{"published_at": map[string]interface{}{"$gte": since.Format(time.RFC3339)}},
+	}})
+	assertJSONEqual(t, "where_document", requests[0]["where_document"], map[string]interface{}{"$or": []interface{}{
+		map[string]interface{}{"$contains": "tesla"},
+		map[string]interface{}{"$contains": "Tesla"},
+	}})
+	if limit := requests[0]["limit"]; limit != float64(5) {
+		t.Errorf("limit = %v, want 5", limit)
+	}
+
+	if ids := resultIDs(results); !slices.Equal(ids, []string{"match"}) {
+		t.Fatalf("results = %v, want only the recent Reuters business article about Tesla", ids)
+	}
+	if results[0].Document.Title != "Tesla cuts prices again" || results[0].Similarity != 0 {
+		t.Errorf("result = %+v, want the stored article without a similarity", results[0])
+	}
+}
+
+func TestSearchArticlesByMetadataSingleClauses(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	storeMetadataSearchArticles(t, service)
+
+	results, err := service.SearchArticlesByMetadata(context.Background(), ArticleMetadataFilter{Source: "BBC"}, 0)
+	if err != nil {
+		t.Fatalf("SearchArticlesByMetadata() error = %v", err)
+	}
+
+	request := chroma.received("get")[0]
+	assertJSONEqual(t, "where", request["where"], map[string]interface{}{"source": "BBC"})
+	if _, sent := request["where_document"]; sent {
+		t.Errorf("where_document = %v, want none without keywords", request["where_document"])
+	}
+	if request["limit"] != float64(DefaultTopK) {
+		t.Errorf("limit = %v, want the default %d", request["limit"], DefaultTopK)
+	}
+	if ids := resultIDs(results); !slices.Equal(ids, []string{"other-source"}) {
+		t.Errorf("results = %v, want the BBC article", ids)
+	}
+
+	if _, err := service.SearchArticlesByMetadata(context.Background(), ArticleMetadataFilter{}, 5); err == nil {
+		t.Error("an empty filter was accepted, want an error instead of a full collection scan")
+	}
+}
+
+func TestSearchArticlesHybridRanksOnlyFilteredArticles(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	storeMetadataSearchArticles(t, service)
+
+	results, err := service.SearchArticlesHybrid(context.Background(), fakeEmbedding("Ford recalls trucks"),
+		ArticleMetadataFilter{Keywords: []string{"Tesla"}}, 10, 0)
+	if err != nil {
+		t.Fatalf("SearchArticlesHybrid() error = %v", err)
+	}
+
+	request := chroma.received("query")[0]
+	assertJSONEqual(t, "where_document", request["where_document"], map[string]interface{}{"$contains": "Tesla"})
+	if _, sent := request["query_embeddings"]; !sent {
+		t.Error("hybrid search sent no query embedding")
+	}
+	ids := resultIDs(results)
+	if len(ids) != 4 || slices.Contains(ids, "other-keyword") {
+		t.Errorf("results = %v, want the four Tesla articles and not the closer Ford one", ids)
+	}
+}
+
+func TestHybridSearchFallsBackToVectorSearchWhenNothingMatches(t *testing.T) {
+	tests := []struct {
+		keyword  string
+		wantMode string
+		wantIDs  int
+	}{
+		{keyword: "Tesla", wantMode: "hybrid", wantIDs: 4},
+		{keyword: "Rivian", wantMode: "vector", wantIDs: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyword, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Etc.ChromaHybridSearch = true
+			cfg.Etc.ChromaHybridLookback = 365 * 24 * time.Hour
+			orchestrator := newTestOrchestrator(t, cfg)
+			var service *ChromaDBService
+			_, service = newFakeChroma(t)
+			orchestrator.chromaDBService = service
+			storeMetadataSearchArticles(t, service)
+
+			executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "electric car prices"})
+			executor.workflowCtx.Keywords = []string{tt.keyword}
+
+			results, mode, err := executor.searchStoredArticles(context.Background(), fakeEmbedding("electric car prices"), 0)
+			if err != nil {
+				t.Fatalf("searchStoredArticles() error = %v", err)
+			}
+			if mode != tt.wantMode || len(results) != tt.wantIDs {
+				t.Errorf("mode %s with %d results, want %s with %d", mode, len(results), tt.wantMode, tt.wantIDs)
+			}
+		})
+	}
+}
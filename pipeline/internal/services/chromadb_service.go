@@ -4,6 +4,7 @@ import (
 	"Infiya-ai-pipeline/internal/config"
 	"Infiya-ai-pipeline/internal/models"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -21,6 +22,25 @@ type ChromaDBService struct {
 	logger   *logger.Logger
 	tenant   string
 	database string
+	// news and video collections are suffixed with the tenant of the request, see WithCollectionTenant
+	tenantCollections bool
+	// tenants whose collections may be created, set by the orchestrator from the tenant config
+	knownTenant func(tenantID string) bool
+	// default similarity floor for the convenience search helpers
+	minSimilarity float64
+	// stores are split into chunks of batchSize submitted batchConcurrency at a time
+	batchSize        int
+	batchConcurrency int
+	// scraped content kept in article metadata is cut to this many bytes, 0 leaves it out
+	metadataContentLimit int
+	// when set full article bodies live here and metadata only holds a content_ref
+	contentStore ContentStore
+	// documents read and deleted per request while compacting a collection
+	compactionPageSize int
+	// fields requested by similarity queries, leaving out documents keeps the responses small
+	queryInclude []string
+	// re-embeds articles on refresh, the orchestrator hands over the provider its stores are embedded with
+	embeddings EmbeddingProvider
 }
 
 type Collection struct {
@@ -40,6 +60,7 @@ type QueryRequest struct {
 	QueryEmbeddings [][]float64            `json:"query_embeddings"`
 	NResults        int                    `json:"n_results"`
 	Where           map[string]interface{} `json:"where,omitempty"`
+	WhereDocument   map[string]interface{} `json:"where_document,omitempty"`
 	Include         []string               `json:"include,omitempty"`
 }
 
@@ -61,8 +82,9 @@ type AddRequest struct {
 
 type SearchResult struct {
 	Document   models.NewsArticle `json:"document"`
-	Similarity float64            `json:"similarity"`
-	Distance   float64            `json:"distance"`
+	contentRef string
+	Similarity float64 `json:"similarity"`
+	Distance   float64 `json:"distance"`
 }
 
 type VideoSearchResult struct {
@@ -86,12 +108,12 @@ func NewChromaDBService(config config.EtcConfig, log *logger.Logger) (*ChromaDBS
 
 	client := &http.Client{
 		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
+		Transport: tracing.Transport(&http.Transport{
 			MaxIdleConns:        10,
 			IdleConnTimeout:     30 * time.Second,
 			DisableCompression:  false,
 			MaxIdleConnsPerHost: 10,
-		},
+		}),
 	}
 
 	baseURL, err := url.Parse(config.ChromaDBURL)
@@ -103,22 +125,50 @@ func NewChromaDBService(config config.EtcConfig, log *logger.Logger) (*ChromaDBS
 		client:   client,
 		baseURL:  baseURL,
 		logger:   log,
-		tenant:   DefaultTenant,
-		database: DefaultDatabase,
+		tenant:   config.ChromaTenant,
+		database: config.ChromaDatabase,
+
+		tenantCollections: config.ChromaTenantCollections,
+
+		minSimilarity: config.ChromaMinSimilarity,
+
+		batchSize:            config.ChromaBatchSize,
+		batchConcurrency:     config.ChromaBatchConcurrency,
+		metadataContentLimit: config.ChromaMetadataContentLimit,
+		compactionPageSize:   config.ChromaCompactionPageSize,
+		queryInclude:         config.ChromaQueryInclude,
+	}
+
+	if service.tenant == "" {
+		service.tenant = DefaultTenant
+	}
+	if service.database == "" {
+		service.database = DefaultDatabase
+	}
+	if len(service.queryInclude) == 0 {
+		service.queryInclude = []string{"metadatas", "distances"}
 	}
 
-	if err := service.initialize(); err != nil {
+	if err := service.initialize(config.ChromaAutoCreate); err != nil {
 		return nil, fmt.Errorf("Failed to initialize ChromaDB service: %w", err)
 	}
 
 	log.Info("ChromaDB service initialized successfully", "base_url", config.ChromaDBURL,
-		"collection", NewsCollectionName)
+		"tenant", service.tenant,
+		"database", service.database,
+		"collection", NewsCollectionName,
+		"tenant_collections", service.tenantCollections)
 
 	return service, nil
 
 }
 
-func (service *ChromaDBService) initialize() error {
+// SetContentStore moves full article bodies out of chroma metadata into the given store
+func (service *ChromaDBService) SetContentStore(store ContentStore) {
+	service.contentStore = store
+}
+
+func (service *ChromaDBService) initialize(autoCreate bool) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -126,6 +176,10 @@ func (service *ChromaDBService) initialize() error {
 		return fmt.Errorf("Failed to connect to ChromaDB service: %w", err)
 	}
 
+	if err := service.ensureTenantAndDatabase(ctx, autoCreate); err != nil {
+		return err
+	}
+
 	if err := service.createOrGetCollection(ctx, NewsCollectionName); err != nil {
 		return fmt.Errorf("Collection setup failed : %w", err)
 	}
@@ -164,10 +218,10 @@ func (service *ChromaDBService) createOrGetCollection(ctx context.Context, colle
 	}
 
 	var description string
-	switch collectionName {
-	case NewsCollectionName:
+	switch {
+	case strings.HasPrefix(collectionName, NewsCollectionName):
 		description = "News Articles Collection"
-	case VideosCollectionName:
+	case strings.HasPrefix(collectionName, VideosCollectionName):
 		description = "Video Articles Collection"
 	default:
 		description = "Generic collection"
@@ -198,6 +252,10 @@ func (service *ChromaDBService) StoreVideos(ctx context.Context, videos []models
 	if len(videos) == 0 {
 		return fmt.Errorf("no videos to store")
 	}
+	// a replayed workflow leaves the store as the recorded run found it
+	if replaying(ctx) {
+		return nil
+	}
 
 	startTime := time.Now()
 
@@ -247,10 +305,11 @@ func (service *ChromaDBService) StoreVideos(ctx context.Context, videos []models
 		Embeddings: embeddings,
 	}
 
-	if err := service.addToCollection(ctx, VideosCollectionName, addRequest); err != nil {
+	if err := service.addInBatches(ctx, VideosCollectionName, addRequest); err != nil {
 		service.logger.LogService("chromadb", "store_videos", time.Since(startTime),
 			map[string]interface{}{
 				"videos_count": len(videos),
+				"stored_count": StoredCount(err, len(videos)),
 			}, err)
 		return fmt.Errorf("Failed to store videos: %w", err)
 	}
@@ -264,11 +323,17 @@ func (service *ChromaDBService) StoreVideos(ctx context.Context, videos []models
 
 }
 
-func (service *ChromaDBService) SearchSimilarVideos(ctx context.Context, queryEmbedding []float64, topK int,
+// SearchSimilarVideos returns up to topK videos, dropping any below minSimilarity so weak matches never reach the relevancy step
+func (service *ChromaDBService) SearchSimilarVideos(ctx context.Context, queryEmbedding []float64, topK int, minSimilarity float64,
 	filters map[string]interface{}) ([]VideoSearchResult, error) {
 	if len(queryEmbedding) == 0 {
 		return nil, fmt.Errorf("queryEmbedding is empty")
 	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "video_similarity_search", "", func(ctx context.Context) ([]VideoSearchResult, error) {
+			return service.SearchSimilarVideos(ctx, queryEmbedding, topK, minSimilarity, filters)
+		})
+	}
 
 	if topK <= 0 {
 		topK = DefaultTopK
@@ -285,7 +350,7 @@ func (service *ChromaDBService) SearchSimilarVideos(ctx context.Context, queryEm
 	queryRequest := QueryRequest{
 		QueryEmbeddings: [][]float64{queryEmbedding},
 		NResults:        topK,
-		Include:         []string{"documents", "metadatas", "distances"},
+		Include:         service.queryInclude,
 	}
 
 	if len(filters) > 0 {
@@ -300,31 +365,42 @@ func (service *ChromaDBService) SearchSimilarVideos(ctx context.Context, queryEm
 		return nil, fmt.Errorf("video search query failed: %w", err)
 	}
 
-	results := service.convertToVideoSearchResults(queryResponse)
+	results, dropped := service.convertToVideoSearchResults(queryResponse, minSimilarity)
 
 	service.logger.LogService("chromadb", "search_similar_videos", time.Since(startTime), map[string]interface{}{
-		"top_k":         topK,
-		"results_count": len(results),
-		"collection":    VideosCollectionName,
+		"top_k":          topK,
+		"results_count":  len(results),
+		"min_similarity": minSimilarity,
+		"below_min":      dropped,
+		"collection":     VideosCollectionName,
 	}, nil)
 
 	return results, nil
 
 }
 
-func (service *ChromaDBService) convertToVideoSearchResults(queryResponse *QueryResponse) []VideoSearchResult {
+func (service *ChromaDBService) convertToVideoSearchResults(queryResponse *QueryResponse, minSimilarity float64) ([]VideoSearchResult, int) {
 	var results []VideoSearchResult
+	dropped := 0
 
 	if len(queryResponse.IDs) == 0 || len(queryResponse.IDs[0]) == 0 {
-		return results
+		return results, dropped
 	}
 
 	ids := queryResponse.IDs[0]
-	documents := queryResponse.Documents[0]
 	distances := queryResponse.Distances[0]
 	metadatas := queryResponse.Metadatas[0]
 
 	for i := 0; i < len(ids); i++ {
+		similarity := 1.0 - distances[i]
+		if similarity < 0 {
+			similarity = 0
+		}
+		if similarity < minSimilarity {
+			dropped++
+			continue
+		}
+
 		metadata := metadatas[i]
 
 		publishedAt := time.Now()
@@ -346,26 +422,23 @@ func (service *ChromaDBService) convertToVideoSearchResults(queryResponse *Query
 		}
 
 		video := models.YouTubeVideo{
-			ID:             getString(metadata, "id"),
-			Title:          getString(metadata, "title"),
-			Description:    documents[i],
-			ChannelID:      getString(metadata, "channel_id"),
-			Channel:        getString(metadata, "channel"),
-			ThumbnailURL:   getString(metadata, "thumbnail_url"),
-			PublishedAt:    publishedAt,
-			URL:            getString(metadata, "url"),
-			Tags:           tags,
-			ViewCount:      getString(metadata, "view_count"),
-			LikeCount:      getString(metadata, "like_count"),
-			CommentCount:   getString(metadata, "comment_count"),
-			Duration:       getString(metadata, "duration"),
-			SourceType:     getString(metadata, "source_type"),
-			RelevancyScore: relevancyScore,
-		}
-
-		similarity := 1.0 - distances[i]
-		if similarity < 0 {
-			similarity = 0
+			ID:           getString(metadata, "id"),
+			Title:        getString(metadata, "title"),
+			Description:  queryDescription(queryResponse, i, metadata),
+			ChannelID:    getString(metadata, "channel_id"),
+			Channel:      getString(metadata, "channel"),
+			ThumbnailURL: getString(metadata, "thumbnail_url"),
+			PublishedAt:  publishedAt,
+			URL:          getString(metadata, "url"),
+			Tags:         tags,
+			ViewCount:    getString(metadata, "view_count"),
+			LikeCount:    getString(metadata, "like_count"),
+			CommentCount: getString(metadata, "comment_count"),
+			Duration:     getString(metadata, "duration"),
+			SourceType:   getString(metadata, "source_type"),
+
+			DurationSeconds: videoDurationSeconds(getString(metadata, "duration")),
+			RelevancyScore:  relevancyScore,
 		}
 
 		results = append(results, VideoSearchResult{
@@ -376,14 +449,14 @@ func (service *ChromaDBService) convertToVideoSearchResults(queryResponse *Query
 
 	}
 
-	return results
+	return results, dropped
 }
 
 func (service *ChromaDBService) SearchVideosByChannel(ctx context.Context, queryEmbedding []float64, channel string, topK int) ([]VideoSearchResult, error) {
 	filters := map[string]interface{}{
 		"channel": channel,
 	}
-	return service.SearchSimilarVideos(ctx, queryEmbedding, topK, filters)
+	return service.SearchSimilarVideos(ctx, queryEmbedding, topK, service.minSimilarity, filters)
 }
 
 func (service *ChromaDBService) SearchRecentVideos(ctx context.Context, queryEmbedding []float64, hoursBack int, topK int) ([]VideoSearchResult, error) {
@@ -393,7 +466,7 @@ func (service *ChromaDBService) SearchRecentVideos(ctx context.Context, queryEmb
 			"$gte": cutoffTime.Format(time.RFC3339),
 		},
 	}
-	return service.SearchSimilarVideos(ctx, queryEmbedding, topK, filters)
+	return service.SearchSimilarVideos(ctx, queryEmbedding, topK, service.minSimilarity, filters)
 }
 
 func (service *ChromaDBService) DeleteVideos(ctx context.Context, videoIDs []string) error {
@@ -424,7 +497,7 @@ func (service *ChromaDBService) DeleteVideos(ctx context.Context, videoIDs []str
 		return fmt.Errorf("failed to marshal delete request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("Failed to create delete videos request: %w", err)
 	}
@@ -537,6 +610,9 @@ func (service *ChromaDBService) StoreArticles(ctx context.Context, articles []mo
 	if len(articles) == 0 {
 		return fmt.Errorf("no articles to store")
 	}
+	if replaying(ctx) {
+		return nil
+	}
 
 	startTime := time.Now()
 
@@ -559,25 +635,16 @@ func (service *ChromaDBService) StoreArticles(ctx context.Context, articles []mo
 			articleID = fmt.Sprintf("article_%d_%d", time.Now().Unix(), i)
 		}
 
-		metadatas[i] = map[string]interface{}{
-			"id":              articleID,
-			"title":           article.Title,
-			"url":             article.URL,
-			"source":          article.Source,
-			"author":          article.Author,
-			"published_at":    article.PublishedAt.Format(time.RFC3339),
-			"description":     article.Description,
-			"Content":         article.Content,
-			"image_url":       article.ImageURL,
-			"category":        article.Category,
-			"relevance_score": article.RelevanceScore,
-			"stored_at":       time.Now().Format(time.RFC3339),
-		}
+		metadatas[i] = service.articleMetadata(article, articleID)
 
 		ids[i] = articleID
 
 	}
 
+	for i, article := range articles {
+		service.putArticleContent(ctx, ids[i], article.Content)
+	}
+
 	addRequest := AddRequest{
 		Documents:  documents,
 		Metadatas:  metadatas,
@@ -585,10 +652,11 @@ func (service *ChromaDBService) StoreArticles(ctx context.Context, articles []mo
 		Embeddings: embeddings,
 	}
 
-	if err := service.addToCollection(ctx, NewsCollectionName, addRequest); err != nil {
+	if err := service.addInBatches(ctx, NewsCollectionName, addRequest); err != nil {
 		service.logger.LogService("chromadb", "store_articles", time.Since(startTime),
 			map[string]interface{}{
 				"articles_count": len(articles),
+				"stored_count":   StoredCount(err, len(articles)),
 			}, err)
 
 		return fmt.Errorf("Failed to store articles: %w", err)
@@ -603,6 +671,135 @@ func (service *ChromaDBService) StoreArticles(ctx context.Context, articles []mo
 
 }
 
+// SetKnownTenants sets which tenants get their own collections, collections of any other tenant are refused
+func (service *ChromaDBService) SetKnownTenants(knownTenant func(tenantID string) bool) {
+	service.knownTenant = knownTenant
+}
+
+// SetEmbeddingProvider sets the provider RefreshArticle re-embeds scraped content with
+func (service *ChromaDBService) SetEmbeddingProvider(provider EmbeddingProvider) {
+	service.embeddings = provider
+}
+
+// RefreshArticle re-embeds an article with its richer scraped content and replaces its stored document and vector
+func (service *ChromaDBService) RefreshArticle(ctx context.Context, article models.NewsArticle) error {
+	if article.ID == "" {
+		return fmt.Errorf("article id is required for refresh")
+	}
+	if replaying(ctx) {
+		return nil
+	}
+	if service.embeddings == nil {
+		return fmt.Errorf("no embedding provider set for refresh")
+	}
+
+	startTime := time.Now()
+
+	embedding, err := service.embeddings.GenerateNewsEmbedding(ctx, articleEmbeddingText(article))
+	if err != nil {
+		return fmt.Errorf("Failed to re-embed article: %w", err)
+	}
+
+	document := fmt.Sprintf("%s . %s ", article.Title, article.Description)
+	if article.Content != "" {
+		document = fmt.Sprintf("%s . %s . %s", article.Title, article.Description, article.Content)
+	}
+
+	service.putArticleContent(ctx, article.ID, article.Content)
+
+	upsertRequest := AddRequest{
+		Documents:  []string{document},
+		Metadatas:  []map[string]interface{}{service.articleMetadata(article, article.ID)},
+		IDs:        []string{article.ID},
+		Embeddings: [][]float64{embedding},
+	}
+
+	if err := service.upsertToCollection(ctx, NewsCollectionName, upsertRequest); err != nil {
+		service.logger.LogService("chromadb", "refresh_article", time.Since(startTime), map[string]interface{}{
+			"article_id": article.ID,
+		}, err)
+		return fmt.Errorf("Failed to refresh article: %w", err)
+	}
+
+	service.logger.LogService("chromadb", "refresh_article", time.Since(startTime), map[string]interface{}{
+		"article_id":     article.ID,
+		"content_length": len(article.Content),
+		"collection":     NewsCollectionName,
+	}, nil)
+
+	return nil
+}
+
+// putArticleContent writes the body to the content store, a failure only costs the body on later searches
+func (service *ChromaDBService) putArticleContent(ctx context.Context, articleID, content string) {
+	if service.contentStore == nil || content == "" {
+		return
+	}
+	if err := service.contentStore.PutArticleContent(ctx, articleID, content); err != nil {
+		service.logger.WithError(err).Warn("Failed to store article content", "article_id", articleID)
+	}
+}
+
+func (service *ChromaDBService) articleMetadata(article models.NewsArticle, articleID string) map[string]interface{} {
+	// full scraped bodies bloat every add request and the store, only a preview is kept
+	content := article.Content
+	if len(content) > service.metadataContentLimit {
+		content = truncateUTF8(content, service.metadataContentLimit)
+	}
+
+	metadata := map[string]interface{}{
+		"id":              articleID,
+		"title":           article.Title,
+		"url":             article.URL,
+		"source":          article.Source,
+		"author":          article.Author,
+		"published_at":    article.PublishedAt.Format(time.RFC3339),
+		"description":     article.Description,
+		"content":         content,
+		"image_url":       article.ImageURL,
+		"category":        article.Category,
+		"relevance_score": article.RelevanceScore,
+		"stored_at":       time.Now().Format(time.RFC3339),
+	}
+
+	if service.contentStore != nil && article.Content != "" {
+		metadata["content"] = ""
+		metadata["content_ref"] = articleID
+		metadata["content_length"] = len(article.Content)
+	}
+
+	return metadata
+}
+
+// loadArticleContent fills in bodies kept in the content store for results that only carry a reference
+func (service *ChromaDBService) loadArticleContent(ctx context.Context, results []SearchResult) {
+	if service.contentStore == nil {
+		return
+	}
+
+	var refs []string
+	for _, result := range results {
+		if result.contentRef != "" {
+			refs = append(refs, result.contentRef)
+		}
+	}
+	if len(refs) == 0 {
+		return
+	}
+
+	contents, err := service.contentStore.GetArticleContents(ctx, refs)
+	if err != nil {
+		service.logger.WithError(err).Warn("Failed to load article content, returning metadata only", "refs", len(refs))
+		return
+	}
+
+	for i := range results {
+		if content, ok := contents[results[i].contentRef]; ok {
+			results[i].Document.Content = content
+		}
+	}
+}
+
 func (service *ChromaDBService) addToCollection(ctx context.Context, collectionName string, addRequest AddRequest) error {
 	// Get collection ID first
 	collectionID, err := service.getCollectionID(ctx, collectionName)
@@ -632,10 +829,46 @@ func (service *ChromaDBService) addToCollection(ctx context.Context, collectionN
 	return nil
 }
 
-func (service *ChromaDBService) SearchSimilarArticles(ctx context.Context, queryEmbedding []float64, topK int, filters map[string]interface{}) ([]SearchResult, error) {
+// upsertToCollection adds new ids and overwrites existing ones in place
+func (service *ChromaDBService) upsertToCollection(ctx context.Context, collectionName string, upsertRequest AddRequest) error {
+	collectionID, err := service.getCollectionID(ctx, collectionName)
+	if err != nil {
+		return fmt.Errorf("Failed to get collection ID: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections/%s/upsert", service.baseURL, service.tenant, service.database, collectionID)
+	jsonData, err := json.Marshal(upsertRequest)
+	if err != nil {
+		return fmt.Errorf("Failed to marshall upsert request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("Failed to create upsert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := service.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed upsert request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Failed upsert request: status %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// SearchSimilarArticles returns up to topK articles, dropping any below minSimilarity. An empty result means nothing
+// stored is close enough and callers should fall back to freshly fetched content.
+func (service *ChromaDBService) SearchSimilarArticles(ctx context.Context, queryEmbedding []float64, topK int, minSimilarity float64, filters map[string]interface{}) ([]SearchResult, error) {
 	if len(queryEmbedding) == 0 {
 		return nil, fmt.Errorf("query_embedding cannot be empty")
 	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "article_similarity_search", "", func(ctx context.Context) ([]SearchResult, error) {
+			return service.SearchSimilarArticles(ctx, queryEmbedding, topK, minSimilarity, filters)
+		})
+	}
 
 	if topK <= 0 {
 		topK = DefaultTopK
@@ -652,7 +885,7 @@ func (service *ChromaDBService) SearchSimilarArticles(ctx context.Context, query
 	queryRequest := QueryRequest{
 		QueryEmbeddings: [][]float64{queryEmbedding},
 		NResults:        topK,
-		Include:         []string{"documents", "metadatas", "distances"},
+		Include:         service.queryInclude,
 	}
 
 	if len(filters) > 0 {
@@ -667,76 +900,92 @@ func (service *ChromaDBService) SearchSimilarArticles(ctx context.Context, query
 		return nil, fmt.Errorf("search query failed: %w", err)
 	}
 
-	results := service.convertToSearchResults(queryResponse)
-
-	fmt.Println("Semantically similar articles result :")
-	fmt.Println(results)
+	results, dropped := service.convertToSearchResults(queryResponse, minSimilarity)
+	service.loadArticleContent(ctx, results)
 
 	service.logger.LogService("chromadb", "search_similar_articles", time.Since(startTime), map[string]interface{}{
-		"top_k":         topK,
-		"results_count": len(results),
-		"collection":    NewsCollectionName,
+		"top_k":          topK,
+		"results_count":  len(results),
+		"min_similarity": minSimilarity,
+		"below_min":      dropped,
+		"collection":     NewsCollectionName,
 	}, nil)
 
 	return results, nil
 
 }
 
-func (service *ChromaDBService) convertToSearchResults(queryResponse *QueryResponse) []SearchResult {
+func (service *ChromaDBService) convertToSearchResults(queryResponse *QueryResponse, minSimilarity float64) ([]SearchResult, int) {
 	var results []SearchResult
+	dropped := 0
 
 	if len(queryResponse.IDs) == 0 || len(queryResponse.IDs[0]) == 0 {
-		return results
+		return results, dropped
 	}
 
 	ids := queryResponse.IDs[0]
-	documents := queryResponse.Documents[0]
 	distances := queryResponse.Distances[0]
 	metadatas := queryResponse.Metadatas[0]
 
 	for i := 0; i < len(ids); i++ {
-		metadata := metadatas[i]
-
-		publishedAt := time.Now()
-		if publishedAtStr, ok := metadata["published_at"].(string); ok {
-			if parsed, err := time.Parse(time.RFC3339, publishedAtStr); err == nil {
-				publishedAt = parsed
-			}
-		}
-
-		relevanceScore := 0.0
-		if score, ok := metadata["relevance_score"].(float64); ok {
-			relevanceScore = score
-		}
-
-		article := models.NewsArticle{
-			ID:             getString(metadata, "id"),
-			Title:          getString(metadata, "title"),
-			URL:            getString(metadata, "url"),
-			Source:         getString(metadata, "source"),
-			Author:         getString(metadata, "author"),
-			ImageURL:       getString(metadata, "image_url"),
-			Content:        getString(metadata, "content"),
-			PublishedAt:    publishedAt,
-			Description:    documents[i],
-			Category:       getString(metadata, "category"),
-			RelevanceScore: relevanceScore,
-		}
-
 		similarity := 1.0 - distances[i]
 		if similarity < 0 {
 			similarity = 0
 		}
+		if similarity < minSimilarity {
+			dropped++
+			continue
+		}
+
+		metadata := metadatas[i]
 
 		results = append(results, SearchResult{
-			Document:   article,
+			Document:   articleFromMetadata(metadata, queryDescription(queryResponse, i, metadata)),
+			contentRef: getString(metadata, "content_ref"),
 			Similarity: similarity,
 			Distance:   distances[i],
 		})
 
 	}
 
-	return results
+	return results, dropped
+}
+
+func articleFromMetadata(metadata map[string]interface{}, document string) models.NewsArticle {
+	publishedAt := time.Now()
+	if publishedAtStr, ok := metadata["published_at"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, publishedAtStr); err == nil {
+			publishedAt = parsed
+		}
+	}
+
+	relevanceScore := 0.0
+	if score, ok := metadata["relevance_score"].(float64); ok {
+		relevanceScore = score
+	}
+
+	return models.NewsArticle{
+		ID:             getString(metadata, "id"),
+		Title:          getString(metadata, "title"),
+		URL:            getString(metadata, "url"),
+		Source:         getString(metadata, "source"),
+		Author:         getString(metadata, "author"),
+		ImageURL:       getString(metadata, "image_url"),
+		Content:        getString(metadata, "content"),
+		PublishedAt:    publishedAt,
+		Description:    document,
+		Category:       getString(metadata, "category"),
+		RelevanceScore: relevanceScore,
+	}
+}
+
+// queryDescription is the stored document of a query result, or the description kept in its metadata when the
+// query left documents out
+func queryDescription(queryResponse *QueryResponse, i int, metadata map[string]interface{}) string {
+	if len(queryResponse.Documents) > 0 && i < len(queryResponse.Documents[0]) {
+		return queryResponse.Documents[0][i]
+	}
+	return getString(metadata, "description")
 }
 
 func getString(metadata map[string]interface{}, key string) string {
@@ -746,7 +995,31 @@ func getString(metadata map[string]interface{}, key string) string {
 	return ""
 }
 
+// getCollectionID resolves a collection to its id, scoped to the request's tenant when tenant collections are on
+// and to the embedding fallback while the request's vectors come from it. Scoped collections are created the first
+// time they are used, for configured tenants only, so a made up tenant id can neither read another tenant's vectors
+// nor add collections.
 func (service *ChromaDBService) getCollectionID(ctx context.Context, collectionName string) (string, error) {
+	scopedName := service.scopedCollectionName(ctx, collectionName)
+	if scopedName == collectionName {
+		return service.lookupCollectionID(ctx, collectionName)
+	}
+	tenantID := collectionTenantFromContext(ctx)
+	if service.tenantCollections && tenantID != "" && (service.knownTenant == nil || !service.knownTenant(tenantID)) {
+		return "", fmt.Errorf("unknown tenant %q has no collections", tenantID)
+	}
+
+	collectionID, err := service.lookupCollectionID(ctx, scopedName)
+	if err == nil {
+		return collectionID, nil
+	}
+	if err := service.createOrGetCollection(ctx, scopedName); err != nil {
+		return "", fmt.Errorf("Failed to set up collection %s: %w", scopedName, err)
+	}
+	return service.lookupCollectionID(ctx, scopedName)
+}
+
+func (service *ChromaDBService) lookupCollectionID(ctx context.Context, collectionName string) (string, error) {
 	url := fmt.Sprintf("%s/api/v2/tenants/%s/databases/%s/collections", service.baseURL, service.tenant, service.database)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -809,7 +1082,7 @@ func (service *ChromaDBService) DeleteArticles(ctx context.Context, articlesIDs
 		return fmt.Errorf("failed to marshal delete request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("Failed to create delete articles request: %w", err)
 	}
@@ -839,9 +1112,10 @@ func (service *ChromaDBService) DeleteArticles(ctx context.Context, articlesIDs
 
 func (service *ChromaDBService) SearchByCategory(ctx context.Context, queryEmbedding []float64, category string, topK int) ([]SearchResult, error) {
 	filters := map[string]interface{}{
-		"category": category,
+		// stored categories are lower case, see ArticleCategorizer
+		"category": strings.ToLower(category),
 	}
-	return service.SearchSimilarArticles(ctx, queryEmbedding, topK, filters)
+	return service.SearchSimilarArticles(ctx, queryEmbedding, topK, service.minSimilarity, filters)
 }
 
 func (service *ChromaDBService) SearchRecentArticles(ctx context.Context, queryEmbedding []float64, hoursBack int, topK int) ([]SearchResult, error) {
@@ -851,14 +1125,14 @@ func (service *ChromaDBService) SearchRecentArticles(ctx context.Context, queryE
 			"$gte": cutoffTime.Format(time.RFC3339),
 		},
 	}
-	return service.SearchSimilarArticles(ctx, queryEmbedding, topK, filters)
+	return service.SearchSimilarArticles(ctx, queryEmbedding, topK, service.minSimilarity, filters)
 }
 
 func (cdb *ChromaDBService) SearchBySource(ctx context.Context, queryEmbedding []float64, source string, topK int) ([]SearchResult, error) {
 	filters := map[string]interface{}{
 		"source": source,
 	}
-	return cdb.SearchSimilarArticles(ctx, queryEmbedding, topK, filters)
+	return cdb.SearchSimilarArticles(ctx, queryEmbedding, topK, cdb.minSimilarity, filters)
 }
 
 func (service *ChromaDBService) queryCollection(ctx context.Context, collectionName string, queryRequest QueryRequest) (*QueryResponse, error) {
@@ -879,6 +1153,9 @@ func (service *ChromaDBService) queryCollection(ctx context.Context, collectionN
 	if queryRequest.Where != nil {
 		v2Request["where"] = queryRequest.Where
 	}
+	if queryRequest.WhereDocument != nil {
+		v2Request["where_document"] = queryRequest.WhereDocument
+	}
 
 	jsonData, err := json.Marshal(v2Request)
 	if err != nil {
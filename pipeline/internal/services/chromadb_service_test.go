@@ -0,0 +1,160 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRefreshArticleReplacesTheStoredDocument(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	embeddings := &fakeEmbeddings{}
+	service.SetEmbeddingProvider(embeddings)
+	ctx := context.Background()
+
+	article := newTestCorpus("wildfire", 1, 0).Articles[0]
+	article.Content = ""
+	if err := service.StoreArticles(ctx, []models.NewsArticle{article}, [][]float64{fakeEmbedding(article.Title)}); err != nil {
+		t.Fatalf("StoreArticles() error = %v", err)
+	}
+
+	article.Content = "Crews contained the wildfire overnight after winds dropped."
+	if err := service.RefreshArticle(ctx, article); err != nil {
+		t.Fatalf("RefreshArticle() error = %v", err)
+	}
+
+	upserts := chroma.writes(NewsCollectionName, true)
+	if len(upserts) != 1 {
+		t.Fatalf("upserts = %d, want 1", len(upserts))
+	}
+	upsert := upserts[0]
+	if !reflect.DeepEqual(upsert.IDs, []string{article.ID}) {
+		t.Errorf("upserted ids = %v, want the stored article id %s", upsert.IDs, article.ID)
+	}
+	if !strings.Contains(upsert.Documents[0], article.Content) {
+		t.Errorf("upserted document = %q, want the scraped content", upsert.Documents[0])
+	}
+	if want := fakeEmbedding(articleEmbeddingText(article)); !reflect.DeepEqual(upsert.Embeddings[0], want) {
+		t.Error("upserted vector was not embedded from the scraped content")
+	}
+	if texts := embeddings.embeddedTexts(); len(texts) != 1 || !strings.Contains(texts[0], article.Content) {
+		t.Errorf("embedded texts = %q, want one embedding of the scraped content", texts)
+	}
+}
+
+func TestRefreshArticleNeedsAnEmbeddingProvider(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	article := newTestCorpus("wildfire", 1, 0).Articles[0]
+
+	if err := service.RefreshArticle(context.Background(), article); err == nil {
+		t.Error("RefreshArticle() without an embedding provider succeeded")
+	}
+	if upserts := chroma.writes(NewsCollectionName, true); len(upserts) != 0 {
+		t.Errorf("upserts = %d, want none", len(upserts))
+	}
+}
+
+// rankedQueryResponse answers a query with one result per distance, ids are numbered from 1
+func rankedQueryResponse(distances ...float64) QueryResponse {
+	response := QueryResponse{IDs: [][]string{{}}, Documents: [][]string{{}}, Metadatas: [][]map[string]interface{}{{}}, Distances: [][]float64{distances}}
+	for i := range distances {
+		id := fmt.Sprintf("doc-%d", i+1)
+		response.IDs[0] = append(response.IDs[0], id)
+		response.Documents[0] = append(response.Documents[0], "document "+id)
+		response.Metadatas[0] = append(response.Metadatas[0], map[string]interface{}{"id": id, "title": "title " + id, "url": "https://example.com/" + id})
+	}
+	return response
+}
+
+func TestSearchSimilarArticlesDropsResultsBelowTheFloor(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	chroma.queryResponses[NewsCollectionName] = rankedQueryResponse(0.2, 0.6, 0.85, 1.4)
+
+	results, err := service.SearchSimilarArticles(context.Background(), fakeEmbedding("elections"), 10, 0.3, nil)
+	if err != nil {
+		t.Fatalf("SearchSimilarArticles() error = %v", err)
+	}
+
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Document.ID)
+		if result.Similarity < 0.3 {
+			t.Errorf("result %s similarity %.2f is below the floor", result.Document.ID, result.Similarity)
+		}
+	}
+	if !reflect.DeepEqual(ids, []string{"doc-1", "doc-2"}) {
+		t.Errorf("result ids = %v, want only the two results at or above 0.3 similarity", ids)
+	}
+}
+
+func TestSearchSimilarVideosReturnsNothingWhenEveryResultIsWeak(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	chroma.queryResponses[VideosCollectionName] = rankedQueryResponse(0.8, 0.9)
+
+	results, err := service.SearchSimilarVideos(context.Background(), fakeEmbedding("elections"), 10, 0.3, nil)
+	if err != nil {
+		t.Fatalf("SearchSimilarVideos() error = %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("results = %+v, want none above the floor", results)
+	}
+
+	// a zero floor keeps the old behaviour of returning every match
+	if results, _ := service.SearchSimilarVideos(context.Background(), fakeEmbedding("elections"), 10, 0, nil); len(results) != 2 {
+		t.Errorf("results without a floor = %d, want 2", len(results))
+	}
+}
+
+func TestArticleContentIsStoredOutsideChromaAndLoadedOnSearch(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	redis, redisService := newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second})
+	service.SetContentStore(NewRedisContentStore(redisService, time.Hour))
+	ctx := context.Background()
+
+	article := newTestCorpus("wildfire", 1, 0).Articles[0]
+	article.Content = strings.Repeat("Crews contained the wildfire overnight after winds dropped. ", 20)
+	if err := service.StoreArticles(ctx, []models.NewsArticle{article}, [][]float64{fakeEmbedding(article.Title)}); err != nil {
+		t.Fatalf("StoreArticles() error = %v", err)
+	}
+
+	metadata := chroma.writes(NewsCollectionName, false)[0].Metadatas[0]
+	if metadata["content"] != "" || metadata["content_ref"] != article.ID {
+		t.Errorf("stored metadata content = %q ref = %v, want only a reference to %s", metadata["content"], metadata["content_ref"], article.ID)
+	}
+	key := "article:" + article.ID + ":content"
+	if keys := redis.Keys(key); len(keys) != 1 {
+		t.Fatalf("redis keys = %v, want the article content under %s", keys, key)
+	}
+	if ttl := redis.TTL(key); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("content ttl = %s, want the configured hour", ttl)
+	}
+
+	results, err := service.SearchSimilarArticles(ctx, fakeEmbedding(article.Title), 5, 0, nil)
+	if err != nil {
+		t.Fatalf("SearchSimilarArticles() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Document.Content != article.Content {
+		t.Errorf("search results = %+v, want the article with its content loaded from redis", results)
+	}
+}
+
+func TestArticleContentStaysInMetadataWithoutAContentStore(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	service.metadataContentLimit = 1000
+
+	article := newTestCorpus("wildfire", 1, 0).Articles[0]
+	article.Content = "Crews contained the wildfire overnight after winds dropped."
+	if err := service.StoreArticles(context.Background(), []models.NewsArticle{article}, [][]float64{fakeEmbedding(article.Title)}); err != nil {
+		t.Fatalf("StoreArticles() error = %v", err)
+	}
+
+	metadata := chroma.writes(NewsCollectionName, false)[0].Metadatas[0]
+	if _, hasRef := metadata["content_ref"]; hasRef || metadata["content"] != article.Content {
+		t.Errorf("stored metadata = %v, want the content inline and no reference", metadata)
+	}
+}
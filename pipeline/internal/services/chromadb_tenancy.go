@@ -0,0 +1,149 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type collectionTenantKey struct{}
+
+// WithCollectionTenant attaches the application tenant whose collections chroma reads and writes. This is the
+// caller's tenant, not the chroma tenant the service is configured with, which all application tenants share.
+func WithCollectionTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, collectionTenantKey{}, strings.TrimSpace(tenantID))
+}
+
+func collectionTenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(collectionTenantKey{}).(string)
+	return tenantID
+}
+
+// scopedCollectionName suffixes the collection with the request's tenant when tenant collections are on, requests
+// without a tenant keep using the shared collection. Vectors of the embedding fallback get their own collections
+// on top, they are never stored next to or queried against the primary's.
+func (service *ChromaDBService) scopedCollectionName(ctx context.Context, collectionName string) string {
+	if tenantID := collectionTenantFromContext(ctx); service.tenantCollections && tenantID != "" {
+		collectionName += "__" + collectionNameSegment(tenantID)
+	}
+	if _, fallback := embeddingSpaceFromContext(ctx).state(); fallback != "" {
+		collectionName += "__" + collectionNameSegment(fallback)
+	}
+	return collectionName
+}
+
+// collectionNameSegment makes a tenant id safe for a chroma collection name, which only allows letters, digits,
+// dots, dashes and underscores. Ids that had to be changed get a hash so "a.b" and "a/b" stay apart.
+func collectionNameSegment(tenantID string) string {
+	segment := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, tenantID)
+	if len(segment) > 64 {
+		segment = segment[:64]
+	}
+	if segment == tenantID {
+		return segment
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(tenantID))
+	return fmt.Sprintf("%s-%08x", segment, hash.Sum32())
+}
+
+// ensureTenantAndDatabase checks the configured tenant and database exist before any collection is touched,
+// otherwise every store and query would fail later with an unhelpful status code. Missing ones are created
+// when autoCreate is set.
+func (service *ChromaDBService) ensureTenantAndDatabase(ctx context.Context, autoCreate bool) error {
+	tenantURL := fmt.Sprintf("%s/api/v2/tenants/%s", service.baseURL, url.PathEscape(service.tenant))
+	exists, err := service.chromaResourceExists(ctx, tenantURL)
+	if err != nil {
+		return fmt.Errorf("Failed to look up ChromaDB tenant %q: %w", service.tenant, err)
+	}
+	if !exists {
+		if !autoCreate {
+			return fmt.Errorf("ChromaDB tenant %q does not exist, create it or set CHROMA_AUTO_CREATE=true", service.tenant)
+		}
+		if err := service.createChromaResource(ctx, fmt.Sprintf("%s/api/v2/tenants", service.baseURL), service.tenant); err != nil {
+			return fmt.Errorf("Failed to create ChromaDB tenant %q: %w", service.tenant, err)
+		}
+		service.logger.Info("Created ChromaDB tenant", "tenant", service.tenant)
+	}
+
+	databasesURL := fmt.Sprintf("%s/databases", tenantURL)
+	exists, err = service.chromaResourceExists(ctx, fmt.Sprintf("%s/%s", databasesURL, url.PathEscape(service.database)))
+	if err != nil {
+		return fmt.Errorf("Failed to look up ChromaDB database %q: %w", service.database, err)
+	}
+	if !exists {
+		if !autoCreate {
+			return fmt.Errorf("ChromaDB database %q does not exist in tenant %q, create it or set CHROMA_AUTO_CREATE=true",
+				service.database, service.tenant)
+		}
+		if err := service.createChromaResource(ctx, databasesURL, service.database); err != nil {
+			return fmt.Errorf("Failed to create ChromaDB database %q: %w", service.database, err)
+		}
+		service.logger.Info("Created ChromaDB database", "tenant", service.tenant, "database", service.database)
+	}
+
+	return nil
+}
+
+// chromaResourceExists reports whether a tenant or database lookup succeeds. Some chroma versions answer a
+// missing resource with a 500 carrying a NotFoundError rather than a 404, both count as missing.
+func (service *ChromaDBService) chromaResourceExists(ctx context.Context, resourceURL string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("Failed to create lookup request: %w", err)
+	}
+
+	resp, err := service.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("Failed lookup request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return true, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode == http.StatusNotFound || strings.Contains(string(body), "NotFound") {
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid status code: %d", resp.StatusCode)
+}
+
+func (service *ChromaDBService) createChromaResource(ctx context.Context, collectionURL, name string) error {
+	jsonData, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return fmt.Errorf("Failed to marshall create request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, collectionURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("Failed to build create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := service.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed create request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("invalid status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeChromaTenancy serves ChromaDB's v2 tenant and database endpoints, missing resources are answered the way
+// the configured chroma version does
+type fakeChromaTenancy struct {
+	mu        sync.Mutex
+	tenants   map[string]bool
+	databases map[string]bool // "tenant/database"
+	created   []string
+	// older chroma versions answer a missing resource with a 500 NotFoundError instead of a 404
+	notFoundAs500 bool
+}
+
+func newFakeChromaTenancy(t *testing.T, tenants, databases []string) (*fakeChromaTenancy, *ChromaDBService) {
+	t.Helper()
+	fake := &fakeChromaTenancy{tenants: make(map[string]bool), databases: make(map[string]bool)}
+	for _, tenant := range tenants {
+		fake.tenants[tenant] = true
+	}
+	for _, database := range databases {
+		fake.databases[database] = true
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse fake chroma url: %v", err)
+	}
+
+	return fake, &ChromaDBService{
+		client: server.Client(), baseURL: baseURL, logger: newTestLogger(t),
+		tenant: "news_corp", database: "articles",
+	}
+}
+
+func (fake *fakeChromaTenancy) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v2/tenants"), "/")
+	notFound := func() {
+		if fake.notFoundAs500 {
+			http.Error(w, `{"error": "NotFoundError", "message": "not found"}`, http.StatusInternalServerError)
+			return
+		}
+		http.NotFound(w, r)
+	}
+
+	if r.Method == http.MethodPost {
+		var request struct {
+			Name string `json:"name"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		switch {
+		case len(parts) == 1:
+			fake.tenants[request.Name] = true
+			fake.created = append(fake.created, "tenant "+request.Name)
+		case len(parts) == 3 && parts[2] == "databases" && fake.tenants[parts[1]]:
+			fake.databases[parts[1]+"/"+request.Name] = true
+			fake.created = append(fake.created, "database "+parts[1]+"/"+request.Name)
+		default:
+			notFound()
+			return
+		}
+		w.Write([]byte("{}"))
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && fake.tenants[parts[1]]:
+		json.NewEncoder(w).Encode(map[string]string{"name": parts[1]})
+	case len(parts) == 4 && parts[2] == "databases" && fake.databases[parts[1]+"/"+parts[3]]:
+		json.NewEncoder(w).Encode(map[string]string{"name": parts[3], "tenant": parts[1]})
+	default:
+		notFound()
+	}
+}
+
+func (fake *fakeChromaTenancy) createdResources() []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return append([]string(nil), fake.created...)
+}
+
+func TestEnsureTenantAndDatabase(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		tenants       []string
+		databases     []string
+		autoCreate    bool
+		notFoundAs500 bool
+		wantCreated   []string
+		wantErr       string
+	}{
+		{name: "both exist", tenants: []string{"news_corp"}, databases: []string{"news_corp/articles"}},
+		{name: "missing database is created", tenants: []string{"news_corp"}, autoCreate: true,
+			wantCreated: []string{"database news_corp/articles"}},
+		{name: "missing database reported as a server error is created", tenants: []string{"news_corp"}, autoCreate: true,
+			notFoundAs500: true, wantCreated: []string{"database news_corp/articles"}},
+		{name: "missing tenant and database are created", autoCreate: true,
+			wantCreated: []string{"tenant news_corp", "database news_corp/articles"}},
+		{name: "missing database fails fast", tenants: []string{"news_corp"},
+			wantErr: `ChromaDB database "articles" does not exist in tenant "news_corp", create it or set CHROMA_AUTO_CREATE=true`},
+		{name: "missing tenant fails fast", databases: []string{"news_corp/articles"},
+			wantErr: `ChromaDB tenant "news_corp" does not exist, create it or set CHROMA_AUTO_CREATE=true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fake, service := newFakeChromaTenancy(t, tt.tenants, tt.databases)
+			fake.notFoundAs500 = tt.notFoundAs500
+
+			err := service.ensureTenantAndDatabase(context.Background(), tt.autoCreate)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ensureTenantAndDatabase() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("ensureTenantAndDatabase() error = %v", err)
+			}
+
+			if created := fake.createdResources(); strings.Join(created, ", ") != strings.Join(tt.wantCreated, ", ") {
+				t.Errorf("created %v, want %v", created, tt.wantCreated)
+			}
+		})
+	}
+}
+
+func TestEnsureTenantAndDatabaseSurfacesServerErrors(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "InternalError"}`, http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+	baseURL, _ := url.Parse(server.URL)
+	service := &ChromaDBService{client: server.Client(), baseURL: baseURL, logger: newTestLogger(t), tenant: "news_corp", database: "articles"}
+
+	// an outage is not a missing tenant, nothing may be created on its strength
+	err := service.ensureTenantAndDatabase(context.Background(), true)
+	if err == nil || !strings.Contains(err.Error(), `Failed to look up ChromaDB tenant "news_corp"`) {
+		t.Errorf("ensureTenantAndDatabase() error = %v, want the failed tenant lookup", err)
+	}
+}
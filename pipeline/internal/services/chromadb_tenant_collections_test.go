@@ -0,0 +1,146 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"testing"
+)
+
+func fnvHex(value string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(value))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+func TestCollectionNameSegment(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		tenantID string
+		want     string
+	}{
+		{tenantID: "acme", want: "acme"},
+		{tenantID: "acme_news-2", want: "acme_news-2"},
+		{tenantID: "a.b", want: "a-b-" + fnvHex("a.b")},
+		{tenantID: "a/b", want: "a-b-" + fnvHex("a/b")},
+	}
+	for _, tt := range tests {
+		if got := collectionNameSegment(tt.tenantID); got != tt.want {
+			t.Errorf("collectionNameSegment(%q) = %q, want %q", tt.tenantID, got, tt.want)
+		}
+	}
+	if collectionNameSegment("a.b") == collectionNameSegment("a/b") {
+		t.Error("tenants that differ only in punctuation share a collection")
+	}
+}
+
+func TestScopedCollectionName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name              string
+		tenantCollections bool
+		tenantID          string
+		want              string
+	}{
+		{name: "off", tenantID: "acme", want: NewsCollectionName},
+		{name: "on", tenantCollections: true, tenantID: "acme", want: NewsCollectionName + "__acme"},
+		{name: "request without a tenant", tenantCollections: true, tenantID: "  ", want: NewsCollectionName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ChromaDBService{tenantCollections: tt.tenantCollections}
+			ctx := WithCollectionTenant(context.Background(), tt.tenantID)
+			if got := service.scopedCollectionName(ctx, NewsCollectionName); got != tt.want {
+				t.Errorf("scopedCollectionName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantsStoreAndSearchTheirOwnCollections(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	service.tenantCollections = true
+	service.SetKnownTenants(func(tenantID string) bool { return tenantID == "acme" || tenantID == "globex" })
+	acme := WithCollectionTenant(context.Background(), "acme")
+	globex := WithCollectionTenant(context.Background(), "globex")
+	embedding := fakeEmbedding("merger talks")
+
+	store := func(ctx context.Context, id string) {
+		t.Helper()
+		article := models.NewsArticle{ID: id, Title: "Merger talks " + id, URL: "https://news.example.com/" + id, Description: "merger talks"}
+		if err := service.StoreArticles(ctx, []models.NewsArticle{article}, [][]float64{embedding}); err != nil {
+			t.Fatalf("StoreArticles(%s) error = %v", id, err)
+		}
+	}
+	store(acme, "acme-article")
+	store(globex, "globex-article")
+
+	chroma.mu.Lock()
+	created := slices.Clone(chroma.created)
+	acmeStored, globexStored := len(chroma.added[NewsCollectionName+"__acme"]), len(chroma.added[NewsCollectionName+"__globex"])
+	sharedStored := len(chroma.added[NewsCollectionName])
+	chroma.mu.Unlock()
+
+	if !slices.Equal(created, []string{NewsCollectionName + "__acme", NewsCollectionName + "__globex"}) {
+		t.Errorf("created collections %v, want one per tenant", created)
+	}
+	if acmeStored != 1 || globexStored != 1 || sharedStored != 0 {
+		t.Errorf("stored acme %d, globex %d, shared %d, want each tenant's article in its own collection", acmeStored, globexStored, sharedStored)
+	}
+
+	results, err := service.SearchSimilarArticles(globex, embedding, 10, 0, nil)
+	if err != nil {
+		t.Fatalf("SearchSimilarArticles() error = %v", err)
+	}
+	var ids []string
+	for _, result := range results {
+		ids = append(ids, result.Document.ID)
+	}
+	if !slices.Equal(ids, []string{"globex-article"}) {
+		t.Errorf("globex search found %v, want only its own article", ids)
+	}
+}
+
+func TestUnknownTenantsGetNoCollections(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	service.tenantCollections = true
+	service.SetKnownTenants(func(tenantID string) bool { return tenantID == "acme" })
+	unknown := WithCollectionTenant(context.Background(), "initech")
+
+	article := models.NewsArticle{ID: "initech-article", Title: "Merger talks", URL: "https://news.example.com/initech", Description: "merger talks"}
+	if err := service.StoreArticles(unknown, []models.NewsArticle{article}, [][]float64{fakeEmbedding("merger talks")}); err == nil {
+		t.Error("StoreArticles() stored the articles of an unknown tenant")
+	}
+	if _, err := service.SearchSimilarArticles(unknown, fakeEmbedding("merger talks"), 10, 0, nil); err == nil {
+		t.Error("SearchSimilarArticles() searched for an unknown tenant")
+	}
+
+	chroma.mu.Lock()
+	defer chroma.mu.Unlock()
+	if len(chroma.created) != 0 || len(chroma.added) != 0 {
+		t.Errorf("created %v and stored into %d collections for an unknown tenant, want nothing", chroma.created, len(chroma.added))
+	}
+}
+
+func TestWorkflowStoresIntoTheRequestTenantsCollections(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"TENANT_IDS": "acme"}), "elections", models.IntentNewNewsQuery)
+	workflow.orchestrator.chromaDBService.tenantCollections = true
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", TenantID: "acme", WorkflowID: "workflow-tenant", Query: "what is the latest on elections",
+	})
+	if err != nil || response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("ExecuteWorkflow() = %v, %v", response, err)
+	}
+
+	workflow.chroma.mu.Lock()
+	defer workflow.chroma.mu.Unlock()
+	if len(workflow.chroma.added[NewsCollectionName+"__acme"]) == 0 || len(workflow.chroma.added[VideosCollectionName+"__acme"]) == 0 {
+		t.Errorf("created %v, want the fetched articles and videos stored in acme's collections", workflow.chroma.created)
+	}
+	if len(workflow.chroma.added[NewsCollectionName]) != 0 || len(workflow.chroma.added[VideosCollectionName]) != 0 {
+		t.Error("acme's sources were stored in the shared collections")
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// the summarization prompt shows at most this many articles and videos, citation numbers follow the same caps
+const (
+	summaryArticleLimit = 5
+	summaryVideoLimit   = 8
+)
+
+// matches runs of "[1]" and "[1, 3]" markers with the space before them, three digits at most so bracketed
+// years are left alone
+var (
+	citationMarkerPattern = regexp.MustCompile(`\s?(?:\[\d{1,3}(?:\s*,\s*\d{1,3})*\])+`)
+	citationNumberPattern = regexp.MustCompile(`\[([^\]]+)\]`)
+)
+
+const citationPersonaGuard = `
+IMPORTANT - INLINE CITATIONS:
+The content above cites its sources with numbered markers like [1] or [2, 4].
+- Keep every marker next to the claim it supports, exactly as written
+- Do not renumber, merge, add or remove markers
+`
+
+// citationsEnabled reports whether the summary cites its sources inline, the user's preference overrides the configured default
+func (workflowExecutor *WorkflowExecutor) citationsEnabled() bool {
+	if citations := workflowExecutor.workflowCtx.ConversationContext.UserPreferences.InlineCitations; citations != nil {
+		return *citations
+	}
+	return workflowExecutor.orchestrator.config.Workflow.InlineCitations
+}
+
+// numberedCitationSources numbers the sources in the order the summarization prompt presents them,
+// articles first and then videos
+func numberedCitationSources(articles []models.NewsArticle, videos []models.YouTubeVideo) []models.ResponseCitation {
+	articles = articles[:min(len(articles), summaryArticleLimit)]
+	videos = videos[:min(len(videos), summaryVideoLimit)]
+
+	citations := make([]models.ResponseCitation, 0, len(articles)+len(videos))
+	for _, article := range articles {
+		citations = append(citations, models.ResponseCitation{
+			Index:  len(citations) + 1,
+			Type:   "article",
+			Title:  article.Title,
+			URL:    article.URL,
+			Source: article.Source,
+		})
+	}
+	for _, video := range videos {
+		citations = append(citations, models.ResponseCitation{
+			Index:  len(citations) + 1,
+			Type:   "video",
+			Title:  video.Title,
+			URL:    video.URL,
+			Source: video.Channel,
+		})
+	}
+	return citations
+}
+
+// resolveCitations drops marker numbers outside 1..sourceCount, a marker left without a valid number is removed
+// entirely, it returns the cleaned text and how many numbers were dropped
+func resolveCitations(text string, sourceCount int) (string, int) {
+	dropped := 0
+	resolved := rewriteCitations(text, func(number int) (int, bool) {
+		if number < 1 || number > sourceCount {
+			dropped++
+			return 0, false
+		}
+		return number, true
+	})
+	return resolved, dropped
+}
+
+// rewriteCitations passes every marker number through rewrite, numbers it rejects are removed along with
+// markers and marker runs left empty
+func rewriteCitations(text string, rewrite func(number int) (int, bool)) string {
+	return citationMarkerPattern.ReplaceAllStringFunc(text, func(run string) string {
+		leading := run[:strings.Index(run, "[")]
+
+		var markers strings.Builder
+		for _, match := range citationNumberPattern.FindAllStringSubmatch(run, -1) {
+			var kept []string
+			for _, part := range strings.Split(match[1], ",") {
+				number, _ := strconv.Atoi(strings.TrimSpace(part))
+				if number, ok := rewrite(number); ok {
+					kept = append(kept, strconv.Itoa(number))
+				}
+			}
+			if len(kept) > 0 {
+				markers.WriteString("[" + strings.Join(kept, ", ") + "]")
+			}
+		}
+
+		if markers.Len() == 0 {
+			return ""
+		}
+		return leading + markers.String()
+	})
+}
+
+// resolveResponseCitations re-checks the markers after the persona rewrite, which may have mangled them
+func (workflowExecutor *WorkflowExecutor) resolveResponseCitations() {
+	citations, ok := workflowExecutor.workflowCtx.Metadata["citations"].([]models.ResponseCitation)
+	if !ok {
+		return
+	}
+
+	response, dropped := resolveCitations(workflowExecutor.workflowCtx.Response, len(citations))
+	if dropped == 0 {
+		return
+	}
+
+	workflowExecutor.workflowCtx.Response = response
+	previous, _ := workflowExecutor.workflowCtx.Metadata["dangling_citations"].(int)
+	workflowExecutor.workflowCtx.Metadata["dangling_citations"] = previous + dropped
+	workflowExecutor.logger.Warn("Dropped dangling citation markers from the response",
+		"workflow_id", workflowExecutor.workflowCtx.ID, "dropped", dropped)
+}
+
+// renumberCitations rewrites marker numbers through mapping, numbers without an entry are kept as they are
+func renumberCitations(text string, mapping map[int]int) string {
+	return rewriteCitations(text, func(number int) (int, bool) {
+		if mapped, ok := mapping[number]; ok {
+			return mapped, true
+		}
+		return number, true
+	})
+}
+
+// mergeCitations appends a part's numbered sources to the combined list, sources already listed keep their
+// number, and returns the part's summary renumbered to the combined list
+func mergeCitations(combined []models.ResponseCitation, part []models.ResponseCitation, summary string) ([]models.ResponseCitation, string) {
+	mapping := make(map[int]int, len(part))
+	for _, citation := range part {
+		local := citation.Index
+		index := slices.IndexFunc(combined, func(existing models.ResponseCitation) bool { return existing.URL == citation.URL })
+		if index < 0 {
+			citation.Index, index = len(combined)+1, len(combined)
+			combined = append(combined, citation)
+		}
+		mapping[local] = combined[index].Index
+	}
+	return combined, renumberCitations(summary, mapping)
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResolveCitations(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		text        string
+		want        string
+		wantDropped int
+	}{
+		{name: "valid markers", text: "Prices rose [1]. Rates held [2, 3].", want: "Prices rose [1]. Rates held [2, 3]."},
+		{name: "dangling marker removed", text: "Prices rose [7]. Rates held [2].", want: "Prices rose. Rates held [2].", wantDropped: 1},
+		{name: "dangling number in a list", text: "Rates held [2, 9, 3].", want: "Rates held [2, 3].", wantDropped: 1},
+		{name: "zero is not a source", text: "Rates held [0].", want: "Rates held.", wantDropped: 1},
+		{name: "adjacent markers", text: "Rates held [1][8][3].", want: "Rates held [1][3].", wantDropped: 1},
+		{name: "years are not citations", text: "Unlike [2024], rates held [1].", want: "Unlike [2024], rates held [1]."},
+		{name: "links are not citations", text: "See [the report](https://example.com) [2].", want: "See [the report](https://example.com) [2]."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, dropped := resolveCitations(tt.text, 3)
+			if got != tt.want || dropped != tt.wantDropped {
+				t.Errorf("resolveCitations() = %q, %d, want %q, %d", got, dropped, tt.want, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestNumberedCitationSourcesFollowThePromptOrder(t *testing.T) {
+	t.Parallel()
+	var articles []models.NewsArticle
+	for i := 1; i <= 7; i++ {
+		articles = append(articles, models.NewsArticle{Title: fmt.Sprintf("Article %d", i), URL: fmt.Sprintf("https://news.example.com/%d", i), Source: "Example News"})
+	}
+	var videos []models.YouTubeVideo
+	for i := 1; i <= 10; i++ {
+		videos = append(videos, models.YouTubeVideo{Title: fmt.Sprintf("Video %d", i), URL: fmt.Sprintf("https://youtube.com/watch?v=%d", i), Channel: "Example TV"})
+	}
+
+	citations := numberedCitationSources(articles, videos)
+
+	if len(citations) != summaryArticleLimit+summaryVideoLimit {
+		t.Fatalf("%d citations, want the %d articles and %d videos the prompt shows", len(citations), summaryArticleLimit, summaryVideoLimit)
+	}
+	for i, citation := range citations {
+		if citation.Index != i+1 {
+			t.Errorf("citation %d has index %d", i, citation.Index)
+		}
+	}
+	if first := citations[summaryArticleLimit]; first.Type != "video" || first.Title != "Video 1" || first.Source != "Example TV" {
+		t.Errorf("citation %d = %+v, want the first video after the articles", summaryArticleLimit+1, first)
+	}
+}
+
+func TestMergeCitationsRenumbersEachPart(t *testing.T) {
+	t.Parallel()
+	shared := models.ResponseCitation{Index: 1, Type: "article", Title: "Budget passes", URL: "https://news.example.com/budget"}
+	first := []models.ResponseCitation{shared, {Index: 2, Type: "article", Title: "Rates held", URL: "https://news.example.com/rates"}}
+	second := []models.ResponseCitation{
+		{Index: 1, Type: "article", Title: "Launch delayed", URL: "https://news.example.com/launch"},
+		{Index: 2, Type: "article", Title: "Budget passes", URL: "https://news.example.com/budget"},
+	}
+
+	combined, firstSummary := mergeCitations(nil, first, "The budget passed [1] and rates held [2].")
+	combined, secondSummary := mergeCitations(combined, second, "The launch slipped [1], the budget [2] [1, 2].")
+
+	if firstSummary != "The budget passed [1] and rates held [2]." {
+		t.Errorf("first summary = %q, want it unchanged", firstSummary)
+	}
+	if secondSummary != "The launch slipped [3], the budget [1] [3, 1]." {
+		t.Errorf("second summary = %q, want its numbers moved onto the combined list", secondSummary)
+	}
+	if len(combined) != 3 || combined[2].Index != 3 || combined[2].Title != "Launch delayed" {
+		t.Errorf("combined = %+v, want the shared source listed once", combined)
+	}
+}
+
+func TestCitationsEnabled(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name       string
+		configured string
+		preference *bool
+		want       bool
+	}{
+		{name: "off by default", configured: "false"},
+		{name: "configured", configured: "true", want: true},
+		{name: "opted in", configured: "false", preference: &on, want: true},
+		{name: "opted out", configured: "true", preference: &off},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := newTestExecutor(t, newTestOrchestrator(t, loadTestConfig(t, map[string]string{"INLINE_CITATIONS": tt.configured})),
+				models.WorkflowRequest{UserID: "user-1", Query: "rates", UserPreferences: models.UserPreferences{InlineCitations: tt.preference}})
+			if got := executor.citationsEnabled(); got != tt.want {
+				t.Errorf("citationsEnabled() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+var responseCitationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+func TestSummaryCitationsMapToTheNumberedSources(t *testing.T) {
+	tests := []struct {
+		name        string
+		personality string
+		persona     string
+		want        string
+	}{
+		{name: "no persona", personality: models.NoPersona, want: "Turnout was high [1]. Polls tightened [2]. Results are due soon."},
+		{name: "persona invents a marker", personality: "calm-anchor", persona: "Good evening. Turnout was high [1] and a late poll [31] tightened [2].",
+			want: "Good evening. Turnout was high [1] and a late poll tightened [2]."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			on := true
+			workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+			workflow.answerAgent("Multimedia News Synthesizer", func(call fakeGeminiCall) string {
+				return "Turnout was high [1]. Polls tightened [2, 40]. Results are due soon [99]."
+			})
+			workflow.answerAgent("Content Personalizer", func(call fakeGeminiCall) string { return tt.persona })
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-citations", Query: "who is ahead in the elections",
+				UserPreferences: models.UserPreferences{NewsPersonality: tt.personality, InlineCitations: &on},
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			if response.Message != tt.want {
+				t.Errorf("message = %q, want %q", response.Message, tt.want)
+			}
+			if len(response.Citations) == 0 {
+				t.Fatal("response has no numbered sources")
+			}
+			for _, match := range responseCitationMarker.FindAllStringSubmatch(response.Message, -1) {
+				if number, _ := strconv.Atoi(match[1]); number < 1 || number > len(response.Citations) {
+					t.Errorf("marker [%d] has no source among %d", number, len(response.Citations))
+				}
+			}
+			for i, citation := range response.Citations {
+				if citation.Index != i+1 || citation.URL == "" {
+					t.Errorf("citation %d = %+v, want index %d with a url", i, citation, i+1)
+				}
+			}
+
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") &&
+					(!strings.Contains(call.Prompt, "Article 1 [1]:") || !strings.Contains(call.Prompt, "INLINE CITATIONS")) {
+					t.Errorf("summary prompt does not number its sources:\n%s", call.Prompt)
+				}
+				if strings.Contains(call.SystemPrompt, "Content Personalizer") && !strings.Contains(call.Prompt, "Keep every marker next to the claim") {
+					t.Error("persona prompt does not ask to keep the markers")
+				}
+			}
+		})
+	}
+}
+
+func TestCitationsAreOffByDefault(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-no-citations")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Citations != nil {
+		t.Errorf("citations = %+v, want none without opting in", response.Citations)
+	}
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") && strings.Contains(call.Prompt, "INLINE CITATIONS") {
+			t.Error("summary prompt asks for citations without opting in")
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"time"
+)
+
+// CompactCollection removes duplicate documents from a news or video collection, tenant selects a tenant's own
+// collection when tenant collections are on
+func (orchestrator *Orchestrator) CompactCollection(ctx context.Context, collectionName, tenant string, dryRun bool) (*models.CompactionReport, error) {
+	if tenant != "" {
+		ctx = WithCollectionTenant(ctx, tenant)
+	}
+	return orchestrator.chromaDBService.CompactCollection(ctx, collectionName, dryRun)
+}
+
+// RunCompaction compacts the shared news and video collections once per compaction interval until ctx ends
+func (orchestrator *Orchestrator) RunCompaction(ctx context.Context) {
+	interval := orchestrator.config.Etc.ChromaCompactionInterval
+	orchestrator.logger.Info("Collection compaction started", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			orchestrator.logger.Info("Collection compaction stopped")
+			return
+		case <-ticker.C:
+			for _, collectionName := range []string{NewsCollectionName, VideosCollectionName} {
+				if _, err := orchestrator.chromaDBService.CompactCollection(ctx, collectionName, false); err != nil {
+					orchestrator.logger.WithError(err).Warn("Scheduled collection compaction failed", "collection", collectionName)
+				}
+			}
+		}
+	}
+}
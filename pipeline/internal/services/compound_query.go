@@ -0,0 +1,271 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// compoundSubQueries returns the distinct news questions the classifier split the query into, capped at the
+// configured maximum. A query that is not compound, or compound handling being off, returns nil.
+func (workflowExecutor *WorkflowExecutor) compoundSubQueries(intentResult *IntentClassificationResult) []string {
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+	if !workflowConfig.CompoundQueries || intentResult == nil {
+		return nil
+	}
+	// the sections would break a machine readable summary
+	if models.SummaryFormat(workflowExecutor.workflowCtx.ConversationContext.UserPreferences.SummaryFormat) == models.SummaryFormatJSON {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(intentResult.SubQueries))
+	var subQueries []string
+	for _, subQuery := range intentResult.SubQueries {
+		subQuery = strings.TrimSpace(subQuery)
+		key := strings.ToLower(subQuery)
+		if subQuery == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		subQueries = append(subQueries, subQuery)
+	}
+
+	if len(subQueries) < 2 {
+		return nil
+	}
+	if len(subQueries) > workflowConfig.MaxSubQueries {
+		workflowExecutor.logger.Warn("Compound query has more parts than allowed, dropping the rest",
+			"sub_queries", len(subQueries),
+			"max_sub_queries", workflowConfig.MaxSubQueries)
+		subQueries = subQueries[:workflowConfig.MaxSubQueries]
+	}
+	return subQueries
+}
+
+// subQueryResult is what one part of a compound query produced
+type subQueryResult struct {
+	query    string
+	workflow *models.WorkflowContext
+	err      error
+}
+
+// executeCompoundNews answers each part of a compound query on its own, query processing, fetch, relevancy,
+// scraping and a summary per part with bounded concurrency, then joins the summaries into one sectioned summary.
+// Parts that fail are left out, the workflow only fails when every part did.
+func (workflowExecutor *WorkflowExecutor) executeCompoundNews(ctx context.Context, subQueries []string) error {
+	startTime := time.Now()
+	workflowCtx := workflowExecutor.workflowCtx
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "compound_query", models.AgentStatusProcessing,
+		fmt.Sprintf("Splitting your question into %d parts", len(subQueries))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish compound query update")
+	}
+
+	results := make([]subQueryResult, len(subQueries))
+	semaphore := make(chan struct{}, workflowExecutor.orchestrator.config.Workflow.SubQueryConcurrency)
+	var wg sync.WaitGroup
+
+	for i, subQuery := range subQueries {
+		wg.Add(1)
+		go func(i int, subQuery string) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results[i] = subQueryResult{query: subQuery, err: ctx.Err()}
+				return
+			}
+
+			subExecutor := workflowExecutor.subQueryExecutor(subQuery)
+			results[i] = subQueryResult{query: subQuery, workflow: subExecutor.workflowCtx, err: subExecutor.answerSubQuery(ctx)}
+		}(i, subQuery)
+	}
+	wg.Wait()
+
+	sections, err := workflowExecutor.mergeSubQueryResults(results)
+
+	workflowCtx.Metadata["sub_queries"] = subQueries
+	workflowExecutor.recordAgentExecution("compound_query", time.Since(startTime),
+		map[string]any{"sub_queries": subQueries},
+		map[string]any{"sections": sections, "articles": len(workflowCtx.Articles), "videos": len(workflowCtx.Videos)}, err)
+	if err != nil {
+		return err
+	}
+
+	workflowCtx.UpdateAgentStats("compound_query", models.AgentStats{
+		Name:      "compound_query",
+		Duration:  time.Since(startTime),
+		Status:    "completed",
+		StartTime: startTime,
+		EndTime:   time.Now(),
+	})
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "compound_query", models.AgentStatusCompleted,
+		fmt.Sprintf("Answered %d of %d parts", sections, len(subQueries))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish compound query completion update")
+	}
+	return nil
+}
+
+// subQueryExecutor runs one part of a compound query against its own workflow context. It shares the workflow
+// ID so updates reach the same stream, and the conversation context and request overrides of the whole query.
+func (workflowExecutor *WorkflowExecutor) subQueryExecutor(subQuery string) *WorkflowExecutor {
+	parent := workflowExecutor.workflowCtx
+
+	subCtx := &models.WorkflowContext{
+		ID:                  parent.ID,
+		UserID:              parent.UserID,
+		TenantID:            parent.TenantID,
+		SessionID:           parent.SessionID,
+		RequestID:           parent.RequestID,
+		OriginalQuery:       subQuery,
+		Status:              parent.Status,
+		StartTime:           time.Now(),
+		Intent:              string(models.IntentNewNewsQuery),
+		IntentConfidence:    parent.IntentConfidence,
+		ConversationContext: parent.ConversationContext,
+		AgentExecutions:     []models.AgentExecution{},
+		Metadata:            make(map[string]any),
+		RequestMetadata:     parent.RequestMetadata,
+		Stateless:           parent.Stateless,
+		ProcessingStats: models.ProcessingStats{
+			AgentStats:          make(map[string]models.AgentStats),
+			AgentExecutionTimes: make(map[string]time.Duration),
+		},
+	}
+
+	return &WorkflowExecutor{
+		orchestrator: workflowExecutor.orchestrator,
+		workflowCtx:  subCtx,
+		logger:       workflowExecutor.logger,
+	}
+}
+
+// answerSubQuery is the news workflow up to the summary for a single part of a compound query
+func (workflowExecutor *WorkflowExecutor) answerSubQuery(ctx context.Context) error {
+	if err := workflowExecutor.executeSequentialQueryProcessing(ctx, &IntentClassificationResult{Intent: string(models.IntentNewNewsQuery)}); err != nil {
+		return err
+	}
+	if err := workflowExecutor.fetchStoreAndSearchArticlesAndVideos(ctx); err != nil {
+		return err
+	}
+
+	if scraped, _ := workflowExecutor.workflowCtx.Metadata["scraped_with_relevancy"].(bool); !scraped {
+		if err := workflowExecutor.traceAgent(ctx, "scraper", workflowExecutor.enhanceArticlesWithFullContent); err != nil {
+			workflowExecutor.logger.WithError(err).Error("Getting full article content failed, proceeding without it")
+		}
+	}
+
+	return workflowExecutor.traceAgent(ctx, "summarizer", workflowExecutor.generateSummary)
+}
+
+// mergeSubQueryResults joins the parts' summaries into "## <part>" sections in the order they were asked and
+// folds their articles, videos, keywords, stats and agent trace into the workflow. It returns the number of
+// sections written, or the first part's error when none succeeded.
+func (workflowExecutor *WorkflowExecutor) mergeSubQueryResults(results []subQueryResult) (int, error) {
+	workflowCtx := workflowExecutor.workflowCtx
+
+	var summary strings.Builder
+	var firstErr error
+	sections := 0
+	seenArticles := make(map[string]bool)
+	seenVideos := make(map[string]bool)
+	subQueryMetadata := make([]map[string]any, 0, len(results))
+	var citations []models.ResponseCitation
+
+	for _, result := range results {
+		if result.workflow != nil {
+			workflowCtx.AgentExecutions = append(workflowCtx.AgentExecutions, result.workflow.AgentExecutions...)
+			mergeProcessingStats(&workflowCtx.ProcessingStats, result.workflow.ProcessingStats)
+		}
+
+		entry := map[string]any{"query": result.query}
+		if result.err != nil || strings.TrimSpace(result.workflow.Summary) == "" {
+			if result.err == nil {
+				result.err = fmt.Errorf("no summary for %q", result.query)
+			}
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			workflowExecutor.logger.WithError(result.err).Warn("Compound query part failed, leaving it out", "sub_query", result.query)
+			entry["error"] = result.err.Error()
+			subQueryMetadata = append(subQueryMetadata, entry)
+			continue
+		}
+
+		sections++
+		partSummary := result.workflow.Summary
+		if partCitations, ok := result.workflow.Metadata["citations"].([]models.ResponseCitation); ok {
+			citations, partSummary = mergeCitations(citations, partCitations, partSummary)
+		}
+		fmt.Fprintf(&summary, "## %s\n\n%s\n\n", result.query, strings.TrimSpace(partSummary))
+		workflowCtx.AddKeywords(result.workflow.Keywords)
+		unavailable, _ := result.workflow.Metadata["unavailable_content"].([]string)
+		for _, contentType := range unavailable {
+			workflowExecutor.markContentUnavailable(contentType)
+		}
+
+		for _, article := range result.workflow.Articles {
+			if !seenArticles[article.URL] {
+				seenArticles[article.URL] = true
+				workflowCtx.Articles = append(workflowCtx.Articles, article)
+			}
+		}
+		for _, video := range result.workflow.Videos {
+			if !seenVideos[video.URL] {
+				seenVideos[video.URL] = true
+				workflowCtx.Videos = append(workflowCtx.Videos, video)
+			}
+		}
+
+		entry["enhanced_query"] = result.workflow.EnhancedQuery
+		entry["keywords"] = result.workflow.Keywords
+		entry["articles"] = len(result.workflow.Articles)
+		entry["videos"] = len(result.workflow.Videos)
+		subQueryMetadata = append(subQueryMetadata, entry)
+	}
+
+	workflowCtx.Metadata["sub_query_results"] = subQueryMetadata
+	if sections == 0 {
+		return 0, fmt.Errorf("every part of the compound query failed: %w", firstErr)
+	}
+
+	if citations != nil {
+		workflowCtx.Metadata["citations"] = citations
+	}
+	workflowCtx.Summary = strings.TrimSpace(summary.String())
+	return sections, nil
+}
+
+// mergeProcessingStats adds a part's counters to the workflow's, agent stats keep the latest part's entry
+func mergeProcessingStats(total *models.ProcessingStats, part models.ProcessingStats) {
+	total.ArticlesFound += part.ArticlesFound
+	total.VideosFound += part.VideosFound
+	total.ArticlesFiltered += part.ArticlesFiltered
+	total.ArticlesSummarized += part.ArticlesSummarized
+	total.VideosSummarized += part.VideosSummarized
+	total.VideosFiltered += part.VideosFiltered
+	total.APICallsCount += part.APICallsCount
+	total.EmbeddingsCount += part.EmbeddingsCount
+	total.EmbeddingDuration += part.EmbeddingDuration
+	total.CacheHitsCount += part.CacheHitsCount
+	total.ScrapeAttempts += part.ScrapeAttempts
+	total.ArticlesScraped += part.ArticlesScraped
+	total.ScrapesSkipped += part.ScrapesSkipped
+	total.VideoFallbacksGenerated += part.VideoFallbacksGenerated
+	total.VideoFallbacksSkipped += part.VideoFallbacksSkipped
+	total.Fallbacks = append(total.Fallbacks, part.Fallbacks...)
+	total.SummaryTruncated = total.SummaryTruncated || part.SummaryTruncated
+
+	for name, stats := range part.AgentStats {
+		total.AgentStats[name] = stats
+	}
+	for name, duration := range part.AgentExecutionTimes {
+		total.AgentExecutionTimes[name] = duration
+	}
+}
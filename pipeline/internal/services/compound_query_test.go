@@ -0,0 +1,138 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCompoundSubQueries(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		format     models.SummaryFormat
+		subQueries []string
+		want       []string
+	}{
+		{name: "off", subQueries: []string{"inflation", "the SpaceX launch"}},
+		{name: "split", enabled: true, subQueries: []string{"inflation", "the SpaceX launch"}, want: []string{"inflation", "the SpaceX launch"}},
+		{name: "duplicates and blanks dropped", enabled: true, subQueries: []string{" inflation ", "", "Inflation", "the SpaceX launch"},
+			want: []string{"inflation", "the SpaceX launch"}},
+		{name: "a single story is not compound", enabled: true, subQueries: []string{"inflation", "INFLATION"}},
+		{name: "capped at the maximum", enabled: true, subQueries: []string{"inflation", "the SpaceX launch", "the budget", "the elections"},
+			want: []string{"inflation", "the SpaceX launch", "the budget"}},
+		{name: "json summaries stay whole", enabled: true, format: models.SummaryFormatJSON, subQueries: []string{"inflation", "the SpaceX launch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{"COMPOUND_QUERIES": fmt.Sprint(tt.enabled), "MAX_SUB_QUERIES": "3"})
+			executor := newTestExecutor(t, newTestOrchestrator(t, cfg), models.WorkflowRequest{
+				UserID: "user-1", Query: "several stories",
+				UserPreferences: models.UserPreferences{SummaryFormat: string(tt.format)},
+			})
+
+			got := executor.compoundSubQueries(&IntentClassificationResult{Intent: string(models.IntentNewNewsQuery), SubQueries: tt.subQueries})
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("compoundSubQueries() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newCompoundWorkflow classifies every query as the given parts and has each part's summary name its part
+func newCompoundWorkflow(t *testing.T, env map[string]string, subQueries ...string) *testWorkflow {
+	t.Helper()
+	cfg := loadTestConfig(t, env)
+	cfg.Gemini.MaxRetries = 1
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+
+	quoted := make([]string, len(subQueries))
+	for i, subQuery := range subQueries {
+		quoted[i] = fmt.Sprintf("%q", subQuery)
+	}
+	workflow.answerAgent("intent classifier", func(call fakeGeminiCall) string {
+		return fmt.Sprintf(`{"intent": "%s", "confidence": 0.95, "reasoning": "two stories", "sub_queries": [%s]}`,
+			models.IntentNewNewsQuery, strings.Join(quoted, ", "))
+	})
+	workflow.answerAgent("Multimedia News Synthesizer", func(call fakeGeminiCall) string {
+		for _, subQuery := range subQueries {
+			if strings.Contains(call.Prompt, fmt.Sprintf("%q", subQuery)) {
+				return "The latest on " + subQuery + "."
+			}
+		}
+		return "The whole query in one summary."
+	})
+	return workflow
+}
+
+func compoundRequest(workflowID string) *models.WorkflowRequest {
+	return &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: workflowID,
+		Query:           "what's happening with inflation and also tell me about the SpaceX launch",
+		UserPreferences: models.UserPreferences{NewsPersonality: models.NoPersona},
+	}
+}
+
+func TestCompoundQueryIsAnsweredInSections(t *testing.T) {
+	workflow := newCompoundWorkflow(t, map[string]string{"COMPOUND_QUERIES": "true"}, "inflation", "the SpaceX launch")
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), compoundRequest("workflow-compound"))
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	want := "## inflation\n\nThe latest on inflation.\n\n## the SpaceX launch\n\nThe latest on the SpaceX launch."
+	if response.Message != want {
+		t.Errorf("message =\n%s\nwant\n%s", response.Message, want)
+	}
+	summaries := 0
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") {
+			summaries++
+		}
+	}
+	if summaries != 2 {
+		t.Errorf("summarizer ran %d times, want once per part", summaries)
+	}
+	var compound bool
+	for _, execution := range response.AgentExecutions {
+		compound = compound || execution.AgentName == "compound_query"
+	}
+	if !compound {
+		t.Error("no compound_query execution recorded")
+	}
+	if len(response.Sources) == 0 {
+		t.Error("compound answer has no sources")
+	}
+}
+
+func TestCompoundQueryOffUsesOneSummary(t *testing.T) {
+	workflow := newCompoundWorkflow(t, nil, "inflation", "the SpaceX launch")
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), compoundRequest("workflow-compound-off"))
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Message != "The whole query in one summary." {
+		t.Errorf("message = %q, want the single pipeline's summary", response.Message)
+	}
+}
+
+func TestCompoundQueryLeavesOutAFailedPart(t *testing.T) {
+	workflow := newCompoundWorkflow(t, map[string]string{"COMPOUND_QUERIES": "true"}, "inflation", "the SpaceX launch")
+	workflow.gemini.fails = func(call fakeGeminiCall) bool {
+		return strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") && strings.Contains(call.Prompt, `"the SpaceX launch"`)
+	}
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), compoundRequest("workflow-compound-partial"))
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Message != "## inflation\n\nThe latest on inflation." {
+		t.Errorf("message = %q, want only the part that was answered", response.Message)
+	}
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"strings"
+)
+
+const (
+	ContentTypeArticles = "articles"
+	ContentTypeVideos   = "videos"
+)
+
+var unavailableContentNotes = map[string]string{
+	ContentTypeArticles: "Note: News articles could not be fetched for this answer, it is based on video coverage only.",
+	ContentTypeVideos:   "Note: Videos could not be fetched for this answer, it is based on news articles only.",
+}
+
+// markContentUnavailable records a content type whose fetch failed while the other one still has results
+func (workflowExecutor *WorkflowExecutor) markContentUnavailable(contentType string) {
+	unavailable, _ := workflowExecutor.workflowCtx.Metadata["unavailable_content"].([]string)
+	for _, existing := range unavailable {
+		if existing == contentType {
+			return
+		}
+	}
+	workflowExecutor.workflowCtx.Metadata["unavailable_content"] = append(unavailable, contentType)
+}
+
+// noteUnavailableContent tells the reader which content type the answer had to do without. Like the uncertainty
+// note it runs after the persona and before translation, JSON summaries carry it in the response field only.
+func (workflowExecutor *WorkflowExecutor) noteUnavailableContent() {
+	unavailable, _ := workflowExecutor.workflowCtx.Metadata["unavailable_content"].([]string)
+	if len(unavailable) != 1 {
+		return
+	}
+	if models.SummaryFormat(workflowExecutor.workflowCtx.ConversationContext.UserPreferences.SummaryFormat) == models.SummaryFormatJSON {
+		return
+	}
+
+	workflowExecutor.workflowCtx.Response = strings.TrimRight(workflowExecutor.workflowCtx.Response, "\n") + "\n\n" +
+		unavailableContentNotes[unavailable[0]]
+}
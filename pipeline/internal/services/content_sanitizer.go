@@ -0,0 +1,85 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"regexp"
+	"strings"
+)
+
+const (
+	untrustedContentStart = "<<<UNTRUSTED_SOURCE_CONTENT>>>"
+	untrustedContentEnd   = "<<<END_UNTRUSTED_SOURCE_CONTENT>>>"
+)
+
+// injectionPatterns match phrases that try to steer the model rather than report news
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)ignore\s+(all\s+)?(the\s+)?(previous|prior|above|earlier)\s+(instructions|prompts|rules|directions)`),
+	regexp.MustCompile(`(?i)disregard\s+(all\s+)?(the\s+)?(previous|prior|above|earlier|your)\s+(instructions|prompts|rules|directions)`),
+	regexp.MustCompile(`(?i)forget\s+(all\s+)?(your|the|previous)\s+(instructions|rules|training)`),
+	regexp.MustCompile(`(?i)you\s+are\s+now\s+(a|an|in)\s+`),
+	regexp.MustCompile(`(?i)new\s+(system\s+)?instructions\s*:`),
+	regexp.MustCompile(`(?i)(^|\n)\s*(system|assistant)\s*:`),
+	regexp.MustCompile(`(?i)(reveal|print|output|repeat)\s+(your|the)\s+(system\s+)?prompt`),
+	regexp.MustCompile(`(?i)respond\s+only\s+with\s+`),
+	regexp.MustCompile(`(?i)</?(system|instructions?|prompt)>`),
+}
+
+// ContentSanitizer neutralises prompt injection attempts in untrusted web content before it reaches a prompt
+type ContentSanitizer struct {
+	enabled   bool
+	threshold int
+}
+
+func NewContentSanitizer(safetyConfig config.SafetyConfig) *ContentSanitizer {
+	return &ContentSanitizer{
+		enabled:   safetyConfig.InjectionDetection,
+		threshold: safetyConfig.InjectionThreshold,
+	}
+}
+
+// stripUntrustedMarkers removes our own delimiters until none are left, removing one can join the text around it
+// into another, and returns how many were removed
+func stripUntrustedMarkers(text string) (string, int) {
+	removed := 0
+	for {
+		found := strings.Count(text, untrustedContentStart) + strings.Count(text, untrustedContentEnd)
+		if found == 0 {
+			return text, removed
+		}
+		removed += found
+		text = strings.ReplaceAll(text, untrustedContentStart, "")
+		text = strings.ReplaceAll(text, untrustedContentEnd, "")
+	}
+}
+
+// Sanitize strips known injection phrases and our own delimiters, returning the cleaned text and the number of hits.
+// A delimiter in the content is an injection attempt and counts as a hit.
+func (sanitizer *ContentSanitizer) Sanitize(text string) (string, int) {
+	// content must never be able to close the untrusted block early
+	text, markers := stripUntrustedMarkers(text)
+
+	if !sanitizer.enabled {
+		return text, 0
+	}
+
+	hits := markers
+	for _, pattern := range injectionPatterns {
+		text = pattern.ReplaceAllStringFunc(text, func(string) string {
+			hits++
+			return "[removed]"
+		})
+	}
+
+	return text, hits
+}
+
+// IsSuspicious reports whether the number of hits should down-weight the source
+func (sanitizer *ContentSanitizer) IsSuspicious(hits int) bool {
+	return sanitizer.enabled && hits >= sanitizer.threshold
+}
+
+// WrapUntrusted sanitizes text and fences it so the prompt can tell data from instructions
+func (sanitizer *ContentSanitizer) WrapUntrusted(text string) (string, int) {
+	cleaned, hits := sanitizer.Sanitize(text)
+	return untrustedContentStart + "\n" + cleaned + "\n" + untrustedContentEnd, hits
+}
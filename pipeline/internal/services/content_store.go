@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ContentStoreMetadata = "metadata"
+	ContentStoreRedis    = "redis"
+)
+
+// ContentStore keeps full article bodies outside the vector store, chroma metadata only carries a reference
+type ContentStore interface {
+	PutArticleContent(ctx context.Context, articleID, content string) error
+	GetArticleContents(ctx context.Context, articleIDs []string) (map[string]string, error)
+}
+
+type redisContentStore struct {
+	redis *RedisService
+	ttl   time.Duration
+}
+
+func NewRedisContentStore(redis *RedisService, ttl time.Duration) ContentStore {
+	return &redisContentStore{redis: redis, ttl: ttl}
+}
+
+func (store *redisContentStore) PutArticleContent(ctx context.Context, articleID, content string) error {
+	return store.redis.StoreArticleContent(ctx, articleID, content, store.ttl)
+}
+
+func (store *redisContentStore) GetArticleContents(ctx context.Context, articleIDs []string) (map[string]string, error) {
+	return store.redis.GetArticleContents(ctx, articleIDs)
+}
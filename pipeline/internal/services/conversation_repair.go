@@ -0,0 +1,101 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// decodeExchanges parses the stored exchanges list, oldest first. A list over maxBytes or one that does not
+// parse is recovered element by element instead of failing the context load: only the last maxBytes are read,
+// elements that do not decode are skipped by resynchronising on the next object, and the most recent exchanges
+// that fit the budget are kept. repaired reports whether anything was dropped.
+func decodeExchanges(raw string, maxBytes int) (exchanges []models.ConversationExchange, repaired bool) {
+	if raw == "" {
+		return []models.ConversationExchange{}, false
+	}
+	if len(raw) <= maxBytes {
+		if err := json.Unmarshal([]byte(raw), &exchanges); err == nil {
+			return exchanges, false
+		}
+	}
+
+	data := []byte(raw)
+	offset := 0
+	if len(data) > maxBytes {
+		offset = len(data) - maxBytes
+		next := nextExchangeStart(data, offset)
+		if next < 0 {
+			return []models.ConversationExchange{}, true
+		}
+		offset = next
+	} else if start := bytes.IndexByte(data, '['); start >= 0 {
+		offset = start + 1
+	}
+
+	type sizedExchange struct {
+		exchange models.ConversationExchange
+		size     int
+	}
+	var recovered []sizedExchange
+
+	for offset < len(data) {
+		offset = skipExchangeSeparators(data, offset)
+		if offset >= len(data) || data[offset] == ']' {
+			break
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data[offset:]))
+		var exchange models.ConversationExchange
+		if err := decoder.Decode(&exchange); err != nil || exchange.UserQuery == "" {
+			next := nextExchangeStart(data, offset+1)
+			if next < 0 {
+				break
+			}
+			offset = next
+			continue
+		}
+
+		size := int(decoder.InputOffset())
+		recovered = append(recovered, sizedExchange{exchange: exchange, size: size})
+		offset += size
+	}
+
+	// newest last, so the budget is spent from the end
+	budget := maxBytes
+	keepFrom := len(recovered)
+	for keepFrom > 0 && recovered[keepFrom-1].size <= budget {
+		budget -= recovered[keepFrom-1].size
+		keepFrom--
+	}
+
+	exchanges = make([]models.ConversationExchange, 0, len(recovered)-keepFrom)
+	for _, entry := range recovered[keepFrom:] {
+		exchanges = append(exchanges, entry.exchange)
+	}
+	return exchanges, true
+}
+
+// nextExchangeStart finds the next element boundary ("{" following a comma or the opening bracket) from offset
+func nextExchangeStart(data []byte, offset int) int {
+	for offset < len(data) {
+		index := bytes.IndexByte(data[offset:], '{')
+		if index < 0 {
+			return -1
+		}
+		position := offset + index
+		if previous := strings.TrimRight(string(data[max(0, position-8):position]), " \t\r\n"); strings.HasSuffix(previous, ",") || strings.HasSuffix(previous, "[") {
+			return position
+		}
+		offset = position + 1
+	}
+	return -1
+}
+
+func skipExchangeSeparators(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(", \t\r\n", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
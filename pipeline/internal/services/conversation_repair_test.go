@@ -0,0 +1,102 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storedExchanges renders exchanges the way StoreConversationContext writes them, one per query
+func storedExchanges(t *testing.T, queries ...string) string {
+	t.Helper()
+	exchanges := make([]models.ConversationExchange, 0, len(queries))
+	for _, query := range queries {
+		exchanges = append(exchanges, models.ConversationExchange{ID: "exchange-" + query, UserQuery: query, AIResponse: "answer to " + query})
+	}
+	data, err := json.Marshal(exchanges)
+	if err != nil {
+		t.Fatalf("failed to marshal exchanges: %v", err)
+	}
+	return string(data)
+}
+
+func exchangeQueries(exchanges []models.ConversationExchange) []string {
+	queries := []string{}
+	for _, exchange := range exchanges {
+		queries = append(queries, exchange.UserQuery)
+	}
+	return queries
+}
+
+func TestDecodeExchanges(t *testing.T) {
+	intact := storedExchanges(t, "q1", "q2", "q3")
+	oneExchange := len(storedExchanges(t, "q1")) - 2
+
+	tests := []struct {
+		name         string
+		raw          string
+		maxBytes     int
+		want         []string
+		wantRepaired bool
+	}{
+		{name: "nothing stored", raw: "", maxBytes: 1024, want: []string{}},
+		{name: "intact", raw: intact, maxBytes: 1024, want: []string{"q1", "q2", "q3"}},
+		{name: "corrupt exchange skipped", maxBytes: 1024, want: []string{"q1", "q3"}, wantRepaired: true,
+			raw: strings.Replace(intact, `"answer to q2"`, `5`, 1)},
+		{name: "truncated write", raw: intact[:len(intact)-20], maxBytes: 1024, want: []string{"q1", "q2"}, wantRepaired: true},
+		{name: "oversized keeps the recent exchanges", raw: intact, maxBytes: 2*oneExchange + 10, want: []string{"q2", "q3"}, wantRepaired: true},
+		{name: "not a list", raw: "not json", maxBytes: 1024, want: []string{}, wantRepaired: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchanges, repaired := decodeExchanges(tt.raw, tt.maxBytes)
+			if got := exchangeQueries(exchanges); !reflect.DeepEqual(got, tt.want) || repaired != tt.wantRepaired {
+				t.Errorf("decodeExchanges() = %v, %t, want %v, %t", got, repaired, tt.want, tt.wantRepaired)
+			}
+		})
+	}
+}
+
+func TestOversizedConversationContextLoadsItsRecentExchanges(t *testing.T) {
+	var queries []string
+	for i := 1; i <= 200; i++ {
+		queries = append(queries, fmt.Sprintf("question %d", i))
+	}
+	stored := storedExchanges(t, queries...)
+	// a partial write left the newest exchange cut off mid-answer
+	stored = stored[:len(stored)-10]
+
+	_, redisService := newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second, MaxContextBytes: 1024})
+	ctx := context.Background()
+	err := redisService.memory.HSet(ctx, "user:user-1:conversation_context", map[string]interface{}{
+		"exchanges": stored, "current_topics": `["elections"]`,
+	}).Err()
+	if err != nil {
+		t.Fatalf("HSet() error = %v", err)
+	}
+
+	conversation, err := redisService.GetConversationContext(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("GetConversationContext() error = %v, want the context repaired", err)
+	}
+
+	got := exchangeQueries(conversation.Exchanges)
+	if len(got) == 0 || len(got) >= 199 {
+		t.Fatalf("kept %d exchanges, want the recent ones within 1024 bytes", len(got))
+	}
+	if last := got[len(got)-1]; last != "question 199" {
+		t.Errorf("newest exchange = %q, want the last complete one", last)
+	}
+	if data, _ := json.Marshal(conversation.Exchanges); len(data) > 1024 {
+		t.Errorf("kept %d bytes of exchanges, want at most 1024", len(data))
+	}
+	if !reflect.DeepEqual(conversation.CurrentTopics, []string{"elections"}) {
+		t.Errorf("current topics = %v, want the rest of the context loaded as stored", conversation.CurrentTopics)
+	}
+}
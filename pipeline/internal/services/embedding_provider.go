@@ -0,0 +1,320 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+const (
+	EmbeddingFallbackNone   = "none"
+	EmbeddingFallbackGemini = "gemini"
+)
+
+// the gemini embedding API takes at most this many texts per call
+const geminiEmbeddingBatchSize = 100
+
+// EmbeddingProvider turns queries, articles and videos into vectors for the chroma collections
+type EmbeddingProvider interface {
+	GenerateQueryEmbedding(ctx context.Context, text string) ([]float64, error)
+	GenerateNewsEmbedding(ctx context.Context, text string) ([]float64, error)
+	BatchGenerateNewsEmbeddings(ctx context.Context, texts []string) ([][]float64, error)
+	BatchGenerateVideoEmbeddings(ctx context.Context, texts []string) ([][]float64, error)
+	HealthCheck(ctx context.Context) error
+}
+
+// GeminiEmbeddingService embeds through the Gemini API, its output is cut to the configured dimension. The
+// vectors are in a different space than Ollama's even at the same dimension, they are kept in their own collections.
+type GeminiEmbeddingService struct {
+	client *genai.Client
+	config config.EmbeddingConfig
+	logger *logger.Logger
+}
+
+// NewGeminiEmbeddingService shares the Gemini client the text generation already set up
+func NewGeminiEmbeddingService(geminiService *GeminiService, config config.EmbeddingConfig, log *logger.Logger) *GeminiEmbeddingService {
+	log.Info("Gemini embedding service initialized", "model", config.FallbackModel, "dimensions", config.Dimensions)
+	return &GeminiEmbeddingService{client: geminiService.client, config: config, logger: log}
+}
+
+func (service *GeminiEmbeddingService) GenerateQueryEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if text == "" {
+		return nil, fmt.Errorf("text cannot be empty")
+	}
+	embeddings, err := service.embed(ctx, []string{text}, "RETRIEVAL_QUERY")
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (service *GeminiEmbeddingService) GenerateNewsEmbedding(ctx context.Context, text string) ([]float64, error) {
+	if text == "" {
+		return nil, fmt.Errorf("text cannot be empty")
+	}
+	embeddings, err := service.embed(ctx, []string{text}, "RETRIEVAL_DOCUMENT")
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (service *GeminiEmbeddingService) BatchGenerateNewsEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return service.embed(ctx, texts, "RETRIEVAL_DOCUMENT")
+}
+
+func (service *GeminiEmbeddingService) BatchGenerateVideoEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return service.embed(ctx, texts, "RETRIEVAL_DOCUMENT")
+}
+
+func (service *GeminiEmbeddingService) HealthCheck(ctx context.Context) error {
+	if _, err := service.GenerateQueryEmbedding(ctx, "health check"); err != nil {
+		return fmt.Errorf("Gemini embedding health check failed: %w", err)
+	}
+	return nil
+}
+
+func (service *GeminiEmbeddingService) embed(ctx context.Context, texts []string, taskType string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return [][]float64{}, nil
+	}
+	startTime := time.Now()
+
+	dimensions := int32(service.config.Dimensions)
+	embedConfig := &genai.EmbedContentConfig{TaskType: taskType}
+	if dimensions > 0 {
+		embedConfig.OutputDimensionality = &dimensions
+	}
+
+	embeddings := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += geminiEmbeddingBatchSize {
+		batch := texts[start:min(start+geminiEmbeddingBatchSize, len(texts))]
+		contents := make([]*genai.Content, len(batch))
+		for i, text := range batch {
+			contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+		}
+
+		resp, err := service.client.Models.EmbedContent(ctx, service.config.FallbackModel, contents, embedConfig)
+		if err != nil {
+			service.logger.LogService("gemini", "generate_embeddings", time.Since(startTime), map[string]interface{}{
+				"batch_size": len(texts),
+				"task_type":  taskType,
+			}, err)
+			return nil, models.WrapExternalError("GEMINI", err)
+		}
+		if len(resp.Embeddings) != len(batch) {
+			return nil, fmt.Errorf("gemini returned %d embeddings for %d texts", len(resp.Embeddings), len(batch))
+		}
+
+		for _, embedding := range resp.Embeddings {
+			values := make([]float64, len(embedding.Values))
+			for i, value := range embedding.Values {
+				values[i] = float64(value)
+			}
+			embeddings = append(embeddings, values)
+		}
+	}
+
+	service.logger.LogService("gemini", "generate_embeddings", time.Since(startTime), map[string]interface{}{
+		"batch_size": len(texts),
+		"task_type":  taskType,
+		"model":      service.config.FallbackModel,
+	}, nil)
+	return embeddings, nil
+}
+
+// FallbackEmbeddingProvider embeds with the primary provider and switches to the fallback when a call fails.
+// After FailureThreshold failures in a row the primary's circuit opens and calls go straight to the fallback
+// for the cooldown, then the primary is tried again. A fallback vector of the wrong dimension is rejected,
+// chroma would fail the write.
+//
+// The two providers embed into different spaces, comparing their vectors is meaningless even when the dimensions
+// match. A context carrying WithEmbeddingSpace is pinned to the provider of its first embedding, later calls use
+// that provider only and chroma keeps the fallback's vectors in their own collections.
+type FallbackEmbeddingProvider struct {
+	primary  EmbeddingProvider
+	fallback EmbeddingProvider
+	config   config.EmbeddingConfig
+	logger   *logger.Logger
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func NewFallbackEmbeddingProvider(primary, fallback EmbeddingProvider, config config.EmbeddingConfig, log *logger.Logger) *FallbackEmbeddingProvider {
+	log.Info("Embedding fallback enabled",
+		"fallback", config.Fallback,
+		"failure_threshold", config.FailureThreshold,
+		"cooldown", config.Cooldown)
+	return &FallbackEmbeddingProvider{primary: primary, fallback: fallback, config: config, logger: log}
+}
+
+func (provider *FallbackEmbeddingProvider) GenerateQueryEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return embedWithFallback(ctx, provider, func(embedder EmbeddingProvider) ([][]float64, error) {
+		embedding, err := embedder.GenerateQueryEmbedding(ctx, text)
+		return [][]float64{embedding}, err
+	}, firstEmbedding)
+}
+
+func (provider *FallbackEmbeddingProvider) GenerateNewsEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return embedWithFallback(ctx, provider, func(embedder EmbeddingProvider) ([][]float64, error) {
+		embedding, err := embedder.GenerateNewsEmbedding(ctx, text)
+		return [][]float64{embedding}, err
+	}, firstEmbedding)
+}
+
+func (provider *FallbackEmbeddingProvider) BatchGenerateNewsEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return embedWithFallback(ctx, provider, func(embedder EmbeddingProvider) ([][]float64, error) {
+		return embedder.BatchGenerateNewsEmbeddings(ctx, texts)
+	}, allEmbeddings)
+}
+
+func (provider *FallbackEmbeddingProvider) BatchGenerateVideoEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return embedWithFallback(ctx, provider, func(embedder EmbeddingProvider) ([][]float64, error) {
+		return embedder.BatchGenerateVideoEmbeddings(ctx, texts)
+	}, allEmbeddings)
+}
+
+// HealthCheck passes while either provider can embed, the workflow keeps its vector search either way
+func (provider *FallbackEmbeddingProvider) HealthCheck(ctx context.Context) error {
+	primaryErr := provider.primary.HealthCheck(ctx)
+	if primaryErr == nil {
+		return nil
+	}
+	if err := provider.fallback.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("primary embedding provider unhealthy (%v) and fallback unhealthy: %w", primaryErr, err)
+	}
+	return nil
+}
+
+// CircuitOpen reports whether calls currently skip the primary provider
+func (provider *FallbackEmbeddingProvider) CircuitOpen() bool {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	return time.Now().Before(provider.openUntil)
+}
+
+func firstEmbedding(embeddings [][]float64) []float64 { return embeddings[0] }
+
+func allEmbeddings(embeddings [][]float64) [][]float64 { return embeddings }
+
+func embedWithFallback[T any](ctx context.Context, provider *FallbackEmbeddingProvider, embed func(EmbeddingProvider) ([][]float64, error), result func([][]float64) T) (T, error) {
+	var zero T
+	space := embeddingSpaceFromContext(ctx)
+	pinned, fallbackName := space.state()
+
+	// a context pinned to the primary cannot switch, its earlier vectors would no longer compare
+	if fallbackName == "" && (pinned || !provider.CircuitOpen()) {
+		embeddings, err := embed(provider.primary)
+		if err == nil {
+			provider.recordPrimary(nil)
+			if !space.pin("") {
+				return zero, fmt.Errorf("embedding space already pinned to the %s fallback", provider.config.Fallback)
+			}
+			return result(embeddings), nil
+		}
+		provider.recordPrimary(err)
+		if pinned {
+			return zero, fmt.Errorf("primary embedding failed after earlier vectors came from it: %w", err)
+		}
+	}
+
+	embeddings, err := embed(provider.fallback)
+	if err != nil {
+		return zero, fmt.Errorf("fallback embedding failed: %w", err)
+	}
+	if err := provider.checkDimensions(embeddings); err != nil {
+		return zero, err
+	}
+	if !space.pin(provider.config.Fallback) {
+		return zero, fmt.Errorf("embedding space already pinned to the primary provider")
+	}
+	return result(embeddings), nil
+}
+
+type embeddingSpaceKey struct{}
+
+// embeddingSpace records which provider a workflow's vectors come from, it is pinned by the first embedding
+type embeddingSpace struct {
+	mu     sync.Mutex
+	pinned bool
+	// name of the fallback provider when the vectors come from it, empty for the primary
+	fallback string
+}
+
+// WithEmbeddingSpace lets the fallback provider pin the context to one provider, so every vector a workflow
+// stores, queries with or compares comes from the same space
+func WithEmbeddingSpace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, embeddingSpaceKey{}, &embeddingSpace{})
+}
+
+// embeddingSpaceFromContext returns nil for contexts without a space, the nil space is never pinned
+func embeddingSpaceFromContext(ctx context.Context) *embeddingSpace {
+	space, _ := ctx.Value(embeddingSpaceKey{}).(*embeddingSpace)
+	return space
+}
+
+func (space *embeddingSpace) state() (pinned bool, fallback string) {
+	if space == nil {
+		return false, ""
+	}
+	space.mu.Lock()
+	defer space.mu.Unlock()
+	return space.pinned, space.fallback
+}
+
+// pin records the provider of the first embedding and reports whether fallback matches the pinned one
+func (space *embeddingSpace) pin(fallback string) bool {
+	if space == nil {
+		return true
+	}
+	space.mu.Lock()
+	defer space.mu.Unlock()
+	if !space.pinned {
+		space.pinned = true
+		space.fallback = fallback
+	}
+	return space.fallback == fallback
+}
+
+func (provider *FallbackEmbeddingProvider) recordPrimary(err error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if err == nil {
+		provider.failures = 0
+		return
+	}
+
+	provider.failures++
+	if provider.failures >= provider.config.FailureThreshold {
+		provider.openUntil = time.Now().Add(provider.config.Cooldown)
+		provider.failures = 0
+		provider.logger.WithError(err).Warn("Primary embedding provider circuit opened, using fallback",
+			"fallback", provider.config.Fallback,
+			"until", provider.openUntil)
+		return
+	}
+	provider.logger.WithError(err).Warn("Primary embedding provider failed, using fallback",
+		"fallback", provider.config.Fallback,
+		"consecutive_failures", provider.failures)
+}
+
+func (provider *FallbackEmbeddingProvider) checkDimensions(embeddings [][]float64) error {
+	if provider.config.Dimensions <= 0 {
+		return nil
+	}
+	for _, embedding := range embeddings {
+		if len(embedding) != provider.config.Dimensions {
+			return fmt.Errorf("fallback embedding has dimension %d, collections expect %d", len(embedding), provider.config.Dimensions)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// stubEmbeddings answers every call with a vector of the given dimension, or fails while down is set
+type stubEmbeddings struct {
+	dimensions int
+	down       atomic.Bool
+	calls      atomic.Int64
+}
+
+func (stub *stubEmbeddings) embed(count int) ([][]float64, error) {
+	stub.calls.Add(1)
+	if stub.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	embeddings := make([][]float64, count)
+	for i := range embeddings {
+		embeddings[i] = make([]float64, stub.dimensions)
+	}
+	return embeddings, nil
+}
+
+func (stub *stubEmbeddings) GenerateQueryEmbedding(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := stub.embed(1)
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (stub *stubEmbeddings) GenerateNewsEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return stub.GenerateQueryEmbedding(ctx, text)
+}
+
+func (stub *stubEmbeddings) BatchGenerateNewsEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return stub.embed(len(texts))
+}
+
+func (stub *stubEmbeddings) BatchGenerateVideoEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return stub.embed(len(texts))
+}
+
+func (stub *stubEmbeddings) HealthCheck(ctx context.Context) error {
+	_, err := stub.embed(1)
+	return err
+}
+
+var testEmbeddingConfig = config.EmbeddingConfig{Fallback: EmbeddingFallbackGemini, Dimensions: 4, FailureThreshold: 2, Cooldown: time.Minute}
+
+func TestEmbeddingFallbackTakesOverWhileThePrimaryFails(t *testing.T) {
+	t.Parallel()
+	primary, fallback := &stubEmbeddings{dimensions: 4}, &stubEmbeddings{dimensions: 4}
+	provider := NewFallbackEmbeddingProvider(primary, fallback, testEmbeddingConfig, newTestLogger(t))
+	ctx := context.Background()
+
+	if _, err := provider.GenerateQueryEmbedding(ctx, "rates"); err != nil || fallback.calls.Load() != 0 {
+		t.Fatalf("healthy primary: error = %v, fallback calls = %d, want the primary used", err, fallback.calls.Load())
+	}
+
+	primary.down.Store(true)
+	if _, err := provider.BatchGenerateNewsEmbeddings(ctx, []string{"a", "b"}); err != nil {
+		t.Fatalf("BatchGenerateNewsEmbeddings() error = %v, want the fallback's vectors", err)
+	}
+	if provider.CircuitOpen() {
+		t.Error("circuit opened after one failure, want it to wait for the threshold")
+	}
+	if _, err := provider.GenerateNewsEmbedding(ctx, "rates"); err != nil {
+		t.Fatalf("GenerateNewsEmbedding() error = %v", err)
+	}
+	if !provider.CircuitOpen() {
+		t.Fatal("circuit still closed after two failures in a row")
+	}
+
+	// while the circuit is open the primary is not even tried
+	primaryCalls := primary.calls.Load()
+	if _, err := provider.BatchGenerateVideoEmbeddings(ctx, []string{"a"}); err != nil {
+		t.Fatalf("BatchGenerateVideoEmbeddings() error = %v", err)
+	}
+	if primary.calls.Load() != primaryCalls {
+		t.Error("primary was called while its circuit was open")
+	}
+	if fallback.calls.Load() != 3 {
+		t.Errorf("fallback served %d calls, want 3", fallback.calls.Load())
+	}
+}
+
+func TestEmbeddingFallbackSuccessResetsTheFailureCount(t *testing.T) {
+	t.Parallel()
+	primary, fallback := &stubEmbeddings{dimensions: 4}, &stubEmbeddings{dimensions: 4}
+	provider := NewFallbackEmbeddingProvider(primary, fallback, testEmbeddingConfig, newTestLogger(t))
+	ctx := context.Background()
+
+	for _, down := range []bool{true, false, true} {
+		primary.down.Store(down)
+		if _, err := provider.GenerateQueryEmbedding(ctx, "rates"); err != nil {
+			t.Fatalf("GenerateQueryEmbedding() error = %v", err)
+		}
+	}
+	if provider.CircuitOpen() {
+		t.Error("circuit opened on failures that were not consecutive")
+	}
+}
+
+func TestEmbeddingFallbackRejectsOtherDimensions(t *testing.T) {
+	t.Parallel()
+	primary, fallback := &stubEmbeddings{dimensions: 4}, &stubEmbeddings{dimensions: 3}
+	primary.down.Store(true)
+	provider := NewFallbackEmbeddingProvider(primary, fallback, testEmbeddingConfig, newTestLogger(t))
+
+	if _, err := provider.GenerateQueryEmbedding(context.Background(), "rates"); err == nil || !strings.Contains(err.Error(), "dimension 3") {
+		t.Errorf("GenerateQueryEmbedding() error = %v, want the 3 dimension vector rejected", err)
+	}
+}
+
+func TestEmbeddingFallbackHealthCheck(t *testing.T) {
+	t.Parallel()
+	primary, fallback := &stubEmbeddings{dimensions: 4}, &stubEmbeddings{dimensions: 4}
+	provider := NewFallbackEmbeddingProvider(primary, fallback, testEmbeddingConfig, newTestLogger(t))
+
+	primary.down.Store(true)
+	if err := provider.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() error = %v, want healthy while the fallback can embed", err)
+	}
+	fallback.down.Store(true)
+	if err := provider.HealthCheck(context.Background()); err == nil {
+		t.Error("HealthCheck() passed with both providers down")
+	}
+}
+
+// newFakeGeminiEmbeddings returns a gemini embedding service whose API answers every text with a vector
+// of the requested dimensionality
+func newFakeGeminiEmbeddings(t *testing.T, embeddingConfig config.EmbeddingConfig) (*GeminiEmbeddingService, *atomic.Int64) {
+	t.Helper()
+	var texts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Requests []struct {
+				OutputDimensionality int `json:"outputDimensionality"`
+			} `json:"requests"`
+		}
+		if !strings.HasSuffix(r.URL.Path, ":batchEmbedContents") || json.NewDecoder(r.Body).Decode(&request) != nil {
+			http.Error(w, "unexpected request "+r.URL.Path, http.StatusBadRequest)
+			return
+		}
+		texts.Add(int64(len(request.Requests)))
+
+		embeddings := make([]map[string][]float32, len(request.Requests))
+		for i, embed := range request.Requests {
+			embeddings[i] = map[string][]float32{"values": make([]float32, embed.OutputDimensionality)}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey: "test-key", Backend: genai.BackendGeminiAPI, HTTPClient: server.Client(), HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("failed to create gemini client: %v", err)
+	}
+	return NewGeminiEmbeddingService(&GeminiService{client: client}, embeddingConfig, newTestLogger(t)), &texts
+}
+
+func TestOllamaFailureTriggersTheGeminiFallback(t *testing.T) {
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(ollamaServer.Close)
+	ollama, err := NewOllamaService(config.OllamaConfig{BaseURL: ollamaServer.URL, EmbeddingModel: "nomic-embed-text",
+		Timeout: time.Second, MaxRetries: 1}, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewOllamaService() error = %v", err)
+	}
+
+	embeddingConfig := testEmbeddingConfig
+	embeddingConfig.FallbackModel = "gemini-embedding-001"
+	gemini, texts := newFakeGeminiEmbeddings(t, embeddingConfig)
+	provider := NewFallbackEmbeddingProvider(ollama, gemini, embeddingConfig, newTestLogger(t))
+
+	embedding, err := provider.GenerateQueryEmbedding(context.Background(), "latest on rates")
+	if err != nil {
+		t.Fatalf("GenerateQueryEmbedding() error = %v", err)
+	}
+	if len(embedding) != 4 {
+		t.Errorf("embedding has %d dimensions, want the collections' 4", len(embedding))
+	}
+
+	embeddings, err := provider.BatchGenerateNewsEmbeddings(context.Background(), []string{"one", "two", "three"})
+	if err != nil || len(embeddings) != 3 {
+		t.Fatalf("BatchGenerateNewsEmbeddings() = %d embeddings, %v, want 3 from gemini", len(embeddings), err)
+	}
+	if got := texts.Load(); got != 4 {
+		t.Errorf("gemini embedded %d texts, want 4", got)
+	}
+}
+
+func TestEmbeddingSpaceStaysWithTheFirstProvider(t *testing.T) {
+	t.Parallel()
+	primary, fallback := &stubEmbeddings{dimensions: 4}, &stubEmbeddings{dimensions: 4}
+	provider := NewFallbackEmbeddingProvider(primary, fallback, testEmbeddingConfig, newTestLogger(t))
+
+	// a workflow that started on the fallback keeps using it once the primary recovers
+	onFallback := WithEmbeddingSpace(context.Background())
+	primary.down.Store(true)
+	if _, err := provider.GenerateQueryEmbedding(onFallback, "rates"); err != nil {
+		t.Fatalf("GenerateQueryEmbedding() error = %v", err)
+	}
+	primary.down.Store(false)
+	primaryCalls := primary.calls.Load()
+	if _, err := provider.BatchGenerateNewsEmbeddings(onFallback, []string{"a", "b"}); err != nil {
+		t.Fatalf("BatchGenerateNewsEmbeddings() error = %v", err)
+	}
+	if primary.calls.Load() != primaryCalls || fallback.calls.Load() != 2 {
+		t.Errorf("article vectors came from the primary after the query was embedded by the fallback")
+	}
+
+	// a workflow that started on the primary fails rather than mixing in fallback vectors
+	onPrimary := WithEmbeddingSpace(context.Background())
+	if _, err := provider.GenerateQueryEmbedding(onPrimary, "rates"); err != nil {
+		t.Fatalf("GenerateQueryEmbedding() error = %v", err)
+	}
+	primary.down.Store(true)
+	fallbackCalls := fallback.calls.Load()
+	if _, err := provider.BatchGenerateNewsEmbeddings(onPrimary, []string{"a"}); err == nil {
+		t.Error("BatchGenerateNewsEmbeddings() switched to the fallback after the query was embedded by the primary")
+	}
+	if fallback.calls.Load() != fallbackCalls {
+		t.Error("the fallback was called for a workflow pinned to the primary")
+	}
+}
+
+func TestFallbackVectorsUseTheirOwnCollections(t *testing.T) {
+	chroma, service := newFakeChroma(t)
+	embedding := fakeEmbedding("merger talks")
+	onPrimary := WithEmbeddingSpace(context.Background())
+	embeddingSpaceFromContext(onPrimary).pin("")
+	onFallback := WithEmbeddingSpace(context.Background())
+	embeddingSpaceFromContext(onFallback).pin(EmbeddingFallbackGemini)
+
+	store := func(ctx context.Context, id string) {
+		t.Helper()
+		article := models.NewsArticle{ID: id, Title: "Merger talks " + id, URL: "https://news.example.com/" + id, Description: "merger talks"}
+		if err := service.StoreArticles(ctx, []models.NewsArticle{article}, [][]float64{embedding}); err != nil {
+			t.Fatalf("StoreArticles(%s) error = %v", id, err)
+		}
+	}
+	store(onPrimary, "ollama-article")
+	store(onFallback, "gemini-article")
+
+	chroma.mu.Lock()
+	primaryStored, fallbackStored := len(chroma.added[NewsCollectionName]), len(chroma.added[NewsCollectionName+"__gemini"])
+	chroma.mu.Unlock()
+	if primaryStored != 1 || fallbackStored != 1 {
+		t.Errorf("stored %d in the primary and %d in the fallback collection, want one each", primaryStored, fallbackStored)
+	}
+
+	for ctx, want := range map[context.Context]string{onPrimary: "ollama-article", onFallback: "gemini-article"} {
+		results, err := service.SearchSimilarArticles(ctx, embedding, 10, 0, nil)
+		if err != nil {
+			t.Fatalf("SearchSimilarArticles() error = %v", err)
+		}
+		if len(results) != 1 || results[0].Document.ID != want {
+			t.Errorf("search found %d results, want only %s", len(results), want)
+		}
+	}
+}
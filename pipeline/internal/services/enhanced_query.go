@@ -0,0 +1,88 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	EnhancedQueryEmpty        = "empty"
+	EnhancedQueryTooShort     = "too_short"
+	EnhancedQueryLostEntities = "lost_entities"
+)
+
+// queryEntities returns the lower case names in a query: capitalized words and acronyms, except a capitalized
+// first word which is usually just the start of the sentence
+func queryEntities(query string) []string {
+	var entities []string
+	for i, token := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		runes := []rune(token)
+		if len(runes) < 2 || !unicode.IsUpper(runes[0]) {
+			continue
+		}
+		if i == 0 && strings.ToUpper(token) != token {
+			continue
+		}
+		entities = append(entities, strings.ToLower(token))
+	}
+	return entities
+}
+
+// degenerateEnhancement explains why an enhanced query would search worse than the original, "" when it is usable.
+// An enhancement is rejected when it is empty, shorter than minWords, or, with keepEntities, names none of the
+// entities of the original query.
+func degenerateEnhancement(original, enhanced string, minWords int, keepEntities bool) string {
+	enhanced = strings.TrimSpace(enhanced)
+	if enhanced == "" {
+		return EnhancedQueryEmpty
+	}
+	if len(strings.Fields(enhanced)) < minWords {
+		return EnhancedQueryTooShort
+	}
+
+	if keepEntities {
+		entities := queryEntities(original)
+		if len(entities) == 0 {
+			return ""
+		}
+		lowerEnhanced := strings.ToLower(enhanced)
+		for _, entity := range entities {
+			if strings.Contains(lowerEnhanced, entity) {
+				return ""
+			}
+		}
+		return EnhancedQueryLostEntities
+	}
+
+	return ""
+}
+
+// acceptEnhancedQuery checks an enhanced query against the configured rules, a rejected one is logged and recorded
+// under "enhanced_query_rejected" and the caller keeps the query it had
+func (workflowExecutor *WorkflowExecutor) acceptEnhancedQuery(enhanced, agent string) bool {
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+	if !workflowConfig.EnhancedQueryValidation {
+		return enhanced != ""
+	}
+
+	reason := degenerateEnhancement(workflowExecutor.workflowCtx.OriginalQuery, enhanced,
+		workflowConfig.EnhancedQueryMinWords, workflowConfig.EnhancedQueryKeepEntities)
+	if reason == "" {
+		return true
+	}
+
+	workflowExecutor.workflowCtx.Metadata["enhanced_query_rejected"] = map[string]any{
+		"agent":          agent,
+		"reason":         reason,
+		"enhanced_query": enhanced,
+	}
+	workflowExecutor.logger.Warn("Rejected degenerate enhanced query, keeping the original",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"agent", agent,
+		"reason", reason,
+		"original_query", workflowExecutor.workflowCtx.OriginalQuery,
+		"enhanced_query", enhanced)
+	return false
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestQueryEntities(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "what did Tesla announce in Berlin", want: []string{"tesla", "berlin"}},
+		{query: "Tesla earnings", want: nil},
+		{query: "NASA launch delayed", want: []string{"nasa"}},
+		{query: "latest news on the budget", want: nil},
+	}
+	for _, tt := range tests {
+		if got := queryEntities(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("queryEntities(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDegenerateEnhancement(t *testing.T) {
+	t.Parallel()
+	const original = "what did Tesla announce in Berlin"
+	tests := []struct {
+		name         string
+		original     string
+		enhanced     string
+		minWords     int
+		keepEntities bool
+		want         string
+	}{
+		{name: "usable", original: original, enhanced: "Tesla Berlin gigafactory announcement", minWords: 2, keepEntities: true},
+		{name: "empty", original: original, enhanced: "  ", minWords: 2, keepEntities: true, want: EnhancedQueryEmpty},
+		{name: "single word", original: original, enhanced: "Tesla", minWords: 2, keepEntities: true, want: EnhancedQueryTooShort},
+		{name: "entities lost", original: original, enhanced: "electric car factory news", minWords: 2, keepEntities: true, want: EnhancedQueryLostEntities},
+		{name: "one entity is enough", original: original, enhanced: "Berlin factory news", minWords: 2, keepEntities: true},
+		{name: "entity check off", original: original, enhanced: "electric car factory news", minWords: 2},
+		{name: "original without entities", original: "latest budget news", enhanced: "government spending plan", minWords: 2, keepEntities: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := degenerateEnhancement(tt.original, tt.enhanced, tt.minWords, tt.keepEntities); got != tt.want {
+				t.Errorf("degenerateEnhancement(%q) = %q, want %q", tt.enhanced, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDegenerateEnhancedQueryKeepsTheOriginal(t *testing.T) {
+	const query = "what did Tesla announce in Berlin"
+	tests := []struct {
+		name     string
+		env      map[string]string
+		enhanced string
+		// empty when rejected, the searches fall back to the original query
+		wantQuery  string
+		wantReason string
+	}{
+		{name: "usable", enhanced: "Tesla Berlin factory announcement", wantQuery: "Tesla Berlin factory announcement"},
+		{name: "too short", enhanced: "news", wantReason: EnhancedQueryTooShort},
+		{name: "entities lost", enhanced: "electric car factory news", wantReason: EnhancedQueryLostEntities},
+		{name: "validation off", env: map[string]string{"ENHANCED_QUERY_VALIDATION": "false"}, enhanced: "news", wantQuery: "news"},
+		{name: "fewer words required", env: map[string]string{"ENHANCED_QUERY_MIN_WORDS": "1"}, enhanced: "Tesla", wantQuery: "Tesla"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, tt.env), "Tesla", models.IntentNewNewsQuery)
+			workflow.answerAgent("Query Expansion and Keyword Extraction", func(call fakeGeminiCall) string {
+				return fmt.Sprintf(`{"enhanced_query": %q, "keywords": ["tesla", "berlin"]}`, tt.enhanced)
+			})
+			executor := newTestExecutor(t, workflow.orchestrator, models.WorkflowRequest{UserID: "user-1", Query: query})
+
+			if err := executor.enhanceAndExtractKeywords(context.Background(), query); err != nil {
+				t.Fatalf("enhanceAndExtractKeywords() error = %v", err)
+			}
+
+			if got := executor.workflowCtx.EnhancedQuery; got != tt.wantQuery {
+				t.Errorf("enhanced query = %q, want %q", got, tt.wantQuery)
+			}
+			rejected, _ := executor.workflowCtx.Metadata["enhanced_query_rejected"].(map[string]any)
+			if reason, _ := rejected["reason"].(string); reason != tt.wantReason {
+				t.Errorf("rejection reason = %q, want %q", reason, tt.wantReason)
+			}
+			// the keywords are still used when the enhanced query is not
+			if !reflect.DeepEqual(executor.workflowCtx.Keywords, []string{"tesla", "berlin"}) {
+				t.Errorf("keywords = %v, want the extracted ones", executor.workflowCtx.Keywords)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+)
+
+// eventListener receives a workflow's typed events in process, next to their delivery to the update stream
+type eventListener struct {
+	updates chan *models.AgentUpdate
+	done    chan struct{}
+}
+
+// ChunkedResponsesEnabled reports whether the execute endpoint may stream its result as JSON lines
+func (orchestrator *Orchestrator) ChunkedResponsesEnabled() bool {
+	return orchestrator.config.Workflow.ChunkedResponses
+}
+
+// SubscribeWorkflowEvents taps the typed events of a workflow that has not started yet. The workflow waits for the
+// listener to take each event, so it must keep reading until the workflow returns or call unsubscribe.
+func (orchestrator *Orchestrator) SubscribeWorkflowEvents(workflowID string) (<-chan *models.AgentUpdate, func()) {
+	listener := &eventListener{
+		updates: make(chan *models.AgentUpdate, 16),
+		done:    make(chan struct{}),
+	}
+	orchestrator.eventListeners.Store(workflowID, listener)
+
+	return listener.updates, func() {
+		orchestrator.eventListeners.Delete(workflowID)
+		close(listener.done)
+	}
+}
+
+// notifyEventListener hands a typed event to the workflow's in process listener, if any
+func (orchestrator *Orchestrator) notifyEventListener(ctx context.Context, workflowID string, update *models.AgentUpdate) {
+	value, ok := orchestrator.eventListeners.Load(workflowID)
+	if !ok {
+		return
+	}
+
+	listener := value.(*eventListener)
+	select {
+	case listener.updates <- update:
+	case <-listener.done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestEventListenerReceivesTheTypedEventsInOrder(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	events, unsubscribe := workflow.orchestrator.SubscribeWorkflowEvents("workflow-listened")
+	defer unsubscribe()
+
+	type outcome struct {
+		response *models.WorkflowResponse
+		err      error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+			UserID: "user-1", WorkflowID: "workflow-listened", Query: "what is the latest on elections",
+			Metadata: map[string]any{"typed_events": true},
+		})
+		done <- outcome{response: response, err: err}
+	}()
+
+	var sequence []models.EventType
+	received := func(update *models.AgentUpdate) {
+		if len(sequence) == 0 || sequence[len(sequence)-1] != update.Event {
+			sequence = append(sequence, update.Event)
+		}
+	}
+	var result outcome
+	for finished := false; !finished; {
+		select {
+		case update := <-events:
+			received(update)
+		case result = <-done:
+			// the last events may still be buffered when the workflow returns
+			for len(events) > 0 {
+				received(<-events)
+			}
+			finished = true
+		case <-time.After(10 * time.Second):
+			t.Fatal("workflow did not finish while its events were read")
+		}
+	}
+	if result.err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", result.err)
+	}
+
+	want := []models.EventType{models.EventIntentClassified, models.EventKeywordsExtracted, models.EventArticlesFound,
+		models.EventSummaryChunk, models.EventSources, models.EventComplete}
+	if !slices.Equal(sequence, want) {
+		t.Errorf("listener received %v, want %v", sequence, want)
+	}
+
+	// the listener saw what the response's own updates carry
+	var published []models.EventType
+	for _, update := range result.response.Updates {
+		if update.Event != "" && (len(published) == 0 || published[len(published)-1] != update.Event) {
+			published = append(published, update.Event)
+		}
+	}
+	if !slices.Equal(published, sequence) {
+		t.Errorf("response updates carry %v, listener received %v", published, sequence)
+	}
+}
+
+func TestUnsubscribedListenerDoesNotHoldTheWorkflow(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	_, unsubscribe := workflow.orchestrator.SubscribeWorkflowEvents("workflow-abandoned")
+	// the client went away before reading a single event
+	unsubscribe()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+			UserID: "user-1", WorkflowID: "workflow-abandoned", Query: "what is the latest on elections",
+			Metadata: map[string]any{"typed_events": true},
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ExecuteWorkflow() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("workflow blocked on a listener nobody reads")
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/pkg/redistest"
+	"testing"
+)
+
+// newFakeRedis returns a redis service whose streams and memory connections are both served by an in-process fake
+func newFakeRedis(t *testing.T, redisConfig config.RedisConfig) (*redistest.Server, *RedisService) {
+	t.Helper()
+	server := redistest.NewServer(t)
+
+	redisConfig.StreamsURL = server.URL()
+	redisConfig.MemoryURL = server.URL()
+	service, err := NewRedisService(redisConfig, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewRedisService() error = %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return server, service
+}
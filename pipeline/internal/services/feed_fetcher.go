@@ -0,0 +1,348 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxFeedSize caps how much of a feed is read, full-text feeds can be large but not unbounded
+const maxFeedSize = 10 << 20
+
+// FeedContentFetcher looks article text up in publisher RSS, Atom or JSON feeds. Feeds carry the
+// text the publisher chose to syndicate, which is cleaner than scraped HTML and does not trip bot
+// protection. Parsed feeds are cached so articles from the same publisher share one download.
+type FeedContentFetcher struct {
+	feeds    map[string]string // domain -> feed url
+	client   *http.Client
+	cacheTTL time.Duration
+	logger   *logger.Logger
+
+	mu    sync.Mutex
+	cache map[string]cachedFeed
+}
+
+type cachedFeed struct {
+	items     []feedItem
+	fetchedAt time.Time
+}
+
+// feedItem is one entry normalised across the three feed formats
+type feedItem struct {
+	Links       []string
+	Title       string
+	Content     string // HTML or text, whichever the feed carries
+	Description string
+	Author      string
+	PublishedAt time.Time
+}
+
+func NewFeedContentFetcher(feeds map[string]string, timeout, cacheTTL time.Duration, log *logger.Logger) *FeedContentFetcher {
+	normalized := make(map[string]string, len(feeds))
+	for domain, feedURL := range feeds {
+		normalized[strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")] = strings.TrimSpace(feedURL)
+	}
+
+	return &FeedContentFetcher{
+		feeds:    normalized,
+		client:   &http.Client{Timeout: timeout, Transport: tracing.Transport(nil)},
+		cacheTTL: cacheTTL,
+		logger:   log,
+		cache:    make(map[string]cachedFeed),
+	}
+}
+
+// Lookup returns the article's content from its publisher's feed, false when the domain has no
+// configured feed, the feed is unavailable or the article is not in it
+func (fetcher *FeedContentFetcher) Lookup(ctx context.Context, articleURL string) (*ScrapedContent, bool) {
+	feedURL := fetcher.feedFor(articleURL)
+	if feedURL == "" {
+		return nil, false
+	}
+
+	items, err := fetcher.items(ctx, feedURL)
+	if err != nil {
+		fetcher.logger.WithError(err).Debug("Feed unavailable, falling back to scraping", "feed_url", feedURL, "url", articleURL)
+		return nil, false
+	}
+
+	item, found := matchFeedItem(items, articleURL)
+	if !found {
+		return nil, false
+	}
+
+	text := feedText(item.Content)
+	if text == "" {
+		return nil, false
+	}
+
+	return &ScrapedContent{
+		URL:         articleURL,
+		Title:       item.Title,
+		Content:     text,
+		Description: feedText(item.Description),
+		Author:      item.Author,
+		PublishedAt: item.PublishedAt,
+		Tags:        []string{},
+		Metadata:    map[string]string{"source": "feed", "feed_url": feedURL},
+		ScrapedAt:   time.Now(),
+		Success:     true,
+	}, true
+}
+
+func (fetcher *FeedContentFetcher) feedFor(articleURL string) string {
+	parsed, err := url.Parse(articleURL)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	for host != "" {
+		if feedURL, ok := fetcher.feeds[host]; ok {
+			return feedURL
+		}
+		// news.example.com falls back to the example.com feed
+		_, parent, found := strings.Cut(host, ".")
+		if !found || !strings.Contains(parent, ".") {
+			return ""
+		}
+		host = parent
+	}
+	return ""
+}
+
+func (fetcher *FeedContentFetcher) items(ctx context.Context, feedURL string) ([]feedItem, error) {
+	fetcher.mu.Lock()
+	cached, ok := fetcher.cache[feedURL]
+	fetcher.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < fetcher.cacheTTL {
+		return cached.items, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/json;q=0.9, */*;q=0.8")
+
+	resp, err := fetcher.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := parseFeed(body)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher.mu.Lock()
+	fetcher.cache[feedURL] = cachedFeed{items: items, fetchedAt: time.Now()}
+	fetcher.mu.Unlock()
+
+	return items, nil
+}
+
+type rssDocument struct {
+	Items []struct {
+		Link        string `xml:"link"`
+		GUID        string `xml:"guid"`
+		Title       string `xml:"title"`
+		Description string `xml:"description"`
+		Encoded     string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		Author      string `xml:"author"`
+		PubDate     string `xml:"pubDate"`
+	} `xml:"channel>item"`
+}
+
+type atomDocument struct {
+	Entries []struct {
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		ID        string `xml:"id"`
+		Title     string `xml:"title"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Author    string `xml:"author>name"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+type jsonFeedDocument struct {
+	Items []struct {
+		ID            string `json:"id"`
+		URL           string `json:"url"`
+		Title         string `json:"title"`
+		ContentHTML   string `json:"content_html"`
+		ContentText   string `json:"content_text"`
+		Summary       string `json:"summary"`
+		DatePublished string `json:"date_published"`
+		Authors       []struct {
+			Name string `json:"name"`
+		} `json:"authors"`
+	} `json:"items"`
+}
+
+// parseFeed reads RSS 2.0 (content:encoded preferred over description), Atom or JSON Feed
+func parseFeed(body []byte) ([]feedItem, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("empty feed")
+	}
+
+	if trimmed[0] == '{' {
+		var doc jsonFeedDocument
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, fmt.Errorf("invalid json feed: %w", err)
+		}
+		items := make([]feedItem, 0, len(doc.Items))
+		for _, entry := range doc.Items {
+			item := feedItem{
+				Links:       []string{entry.URL, entry.ID},
+				Title:       entry.Title,
+				Content:     firstNonEmpty(entry.ContentHTML, entry.ContentText),
+				Description: entry.Summary,
+				PublishedAt: parseFeedTime(entry.DatePublished),
+			}
+			if len(entry.Authors) > 0 {
+				item.Author = entry.Authors[0].Name
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	var rss rssDocument
+	if err := xml.Unmarshal(trimmed, &rss); err == nil && len(rss.Items) > 0 {
+		items := make([]feedItem, 0, len(rss.Items))
+		for _, entry := range rss.Items {
+			items = append(items, feedItem{
+				Links:       []string{entry.Link, entry.GUID},
+				Title:       entry.Title,
+				Content:     entry.Encoded,
+				Description: entry.Description,
+				Author:      firstNonEmpty(entry.Creator, entry.Author),
+				PublishedAt: parseFeedTime(entry.PubDate),
+			})
+		}
+		return items, nil
+	}
+
+	var atom atomDocument
+	if err := xml.Unmarshal(trimmed, &atom); err != nil {
+		return nil, fmt.Errorf("unrecognised feed format: %w", err)
+	}
+	items := make([]feedItem, 0, len(atom.Entries))
+	for _, entry := range atom.Entries {
+		links := []string{entry.ID}
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				links = append(links, link.Href)
+			}
+		}
+		items = append(items, feedItem{
+			Links:       links,
+			Title:       entry.Title,
+			Content:     entry.Content,
+			Description: entry.Summary,
+			Author:      entry.Author,
+			PublishedAt: parseFeedTime(firstNonEmpty(entry.Published, entry.Updated)),
+		})
+	}
+	return items, nil
+}
+
+func matchFeedItem(items []feedItem, articleURL string) (feedItem, bool) {
+	target := normalizeFeedLink(articleURL)
+	if target == "" {
+		return feedItem{}, false
+	}
+
+	for _, item := range items {
+		for _, link := range item.Links {
+			if normalizeFeedLink(link) == target {
+				return item, true
+			}
+		}
+	}
+	return feedItem{}, false
+}
+
+// normalizeFeedLink compares links without scheme, www, query string, fragment or trailing slash,
+// feeds often add tracking parameters that the news api url does not have
+func normalizeFeedLink(link string) string {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	return host + strings.TrimSuffix(parsed.EscapedPath(), "/")
+}
+
+// feedText turns feed HTML into plain paragraphs, plain text passes through
+func feedText(content string) string {
+	content = strings.TrimSpace(content)
+	if content == "" || !strings.Contains(content, "<") {
+		return content
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(content))
+	if err != nil {
+		return ""
+	}
+
+	var paragraphs []string
+	doc.Find("p").Each(func(_ int, selection *goquery.Selection) {
+		if text := strings.Join(strings.Fields(selection.Text()), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	})
+	if len(paragraphs) == 0 {
+		return strings.Join(strings.Fields(doc.Text()), " ")
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+func parseFeedTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
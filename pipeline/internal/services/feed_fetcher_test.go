@@ -0,0 +1,154 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sampleRSSFeed syndicates the budget story in full and only a teaser of the flood story, %[1]s is the publisher url
+const sampleRSSFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Example News</title>
+    <item>
+      <title>Parliament passes the budget</title>
+      <link>%[1]s/politics/budget-vote/?utm_source=rss</link>
+      <guid isPermaLink="false">story-1</guid>
+      <description>The budget passed after a late-night vote.</description>
+      <content:encoded><![CDATA[<p>Parliament passed the budget after a late-night vote that ran past midnight, with the governing coalition holding together despite weeks of public disagreement over fuel subsidies.</p><p>The finance minister said the package would cut the deficit to four percent of output by next year while protecting spending on schools and hospitals.</p>]]></content:encoded>
+      <dc:creator>Asha Rao</dc:creator>
+      <pubDate>Fri, 16 Oct 2026 21:30:00 +0000</pubDate>
+    </item>
+    <item>
+      <title>Flood warning for the coast</title>
+      <link>%[1]s/weather/flood-warning</link>
+      <description>Residents told to prepare.</description>
+      <content:encoded><![CDATA[<p>Residents told to prepare.</p>]]></content:encoded>
+    </item>
+  </channel>
+</rss>`
+
+// newPublisherFeed serves sampleRSSFeed for a publisher at publisherURL and counts the downloads
+func newPublisherFeed(t *testing.T, publisherURL string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var downloads atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads.Add(1)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(strings.ReplaceAll(sampleRSSFeed, "%[1]s", publisherURL)))
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestFeedLookupExtractsTheArticleText(t *testing.T) {
+	feed, downloads := newPublisherFeed(t, "https://www.example.com")
+	fetcher := NewFeedContentFetcher(map[string]string{"example.com": feed.URL}, 5*time.Second, time.Minute, newTestLogger(t))
+
+	content, found := fetcher.Lookup(context.Background(), "https://news.example.com/politics/budget-vote")
+	if found {
+		t.Fatalf("Lookup() matched an article on a subdomain the feed does not link to: %+v", content)
+	}
+
+	content, found = fetcher.Lookup(context.Background(), "http://example.com/politics/budget-vote")
+	if !found {
+		t.Fatal("Lookup() did not find the syndicated article")
+	}
+	if content.Title != "Parliament passes the budget" || content.Author != "Asha Rao" || content.Metadata["source"] != "feed" {
+		t.Errorf("content = %q by %q from %q", content.Title, content.Author, content.Metadata["source"])
+	}
+	paragraphs := strings.Split(content.Content, "\n\n")
+	if len(paragraphs) != 2 || !strings.HasPrefix(paragraphs[1], "The finance minister said") || strings.Contains(content.Content, "<p>") {
+		t.Errorf("content = %q, want the two paragraphs as plain text", content.Content)
+	}
+	if want := time.Date(2026, 10, 16, 21, 30, 0, 0, time.UTC); !content.PublishedAt.Equal(want) {
+		t.Errorf("PublishedAt = %v, want %v", content.PublishedAt, want)
+	}
+
+	if _, found := fetcher.Lookup(context.Background(), "https://example.com/sports/final"); found {
+		t.Error("Lookup() found an article missing from the feed")
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("feed downloads = %d, want one download shared by every lookup", got)
+	}
+}
+
+func TestParseFeedReadsAtomAndJSONFeeds(t *testing.T) {
+	atom := `<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>tag:example.com,2026:1</id>
+		<link rel="alternate" href="https://example.com/a/1"/><title>Atom story</title>
+		<content type="html">&lt;p&gt;Atom body&lt;/p&gt;</content><author><name>Lee</name></author>
+		<published>2026-10-16T10:00:00Z</published></entry></feed>`
+	jsonFeed := `{"version": "https://jsonfeed.org/version/1.1", "items": [{"id": "1", "url": "https://example.com/j/1",
+		"title": "JSON story", "content_text": "JSON body", "authors": [{"name": "Kim"}]}]}`
+
+	for name, body := range map[string]string{"atom": atom, "json": jsonFeed} {
+		items, err := parseFeed([]byte(body))
+		if err != nil || len(items) != 1 {
+			t.Fatalf("%s: parseFeed() = %d items, %v", name, len(items), err)
+		}
+		if item := items[0]; item.Title == "" || item.Author == "" || feedText(item.Content) == "" || len(item.Links) < 2 {
+			t.Errorf("%s: item = %+v, want title, author, content and links", name, item)
+		}
+	}
+}
+
+func newFeedFirstScraper(t *testing.T, publisher *httptest.Server, feedURL string) *ScraperService {
+	t.Helper()
+	host, err := url.Parse(publisher.URL)
+	if err != nil {
+		t.Fatalf("failed to parse publisher url: %v", err)
+	}
+	scraper, err := NewScraperService(config.ScraperConfig{Timeout: 10 * time.Second, RetryAttempts: 1, MinContentLength: 200,
+		FeedFirst: true, FeedSources: map[string]string{host.Hostname(): feedURL}, FeedCacheTTL: time.Minute},
+		nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	return scraper
+}
+
+func TestScrapeUsesTheFeedBeforeThePage(t *testing.T) {
+	t.Parallel()
+	page, publisher := newPublisherPage(t, "budget vote", true)
+	feed, _ := newPublisherFeed(t, publisher.URL)
+	scraper := newFeedFirstScraper(t, publisher, feed.URL)
+
+	content, err := scraper.ScrapeURL(context.Background(), publisher.URL+"/politics/budget-vote")
+	if err != nil {
+		t.Fatalf("ScrapeURL() error = %v", err)
+	}
+
+	if !content.Success || content.Metadata["source"] != "feed" || !strings.HasPrefix(content.Content, "Parliament passed the budget") {
+		t.Errorf("scrape = %v %q, want the feed text", content.Metadata, content.Content)
+	}
+	if requests := page.conditionalHeaders(); len(requests) != 0 {
+		t.Errorf("publisher page requested %d times, want the feed to spare the scrape", len(requests))
+	}
+}
+
+func TestScrapeFallsBackToThePageOnAFeedMiss(t *testing.T) {
+	t.Parallel()
+	page, publisher := newPublisherPage(t, "flood warning", true)
+	feed, _ := newPublisherFeed(t, publisher.URL)
+	scraper := newFeedFirstScraper(t, publisher, feed.URL)
+
+	// the feed only carries a teaser of this story, which is too short to use
+	content, err := scraper.ScrapeURL(context.Background(), publisher.URL+"/weather/flood-warning")
+	if err != nil {
+		t.Fatalf("ScrapeURL() error = %v", err)
+	}
+
+	if !content.Success || content.Metadata["source"] == "feed" || !strings.Contains(content.Content, "The full story of the flood warning") {
+		t.Errorf("scrape = %v %q, want the scraped page", content.Metadata, content.Content)
+	}
+	if requests := page.conditionalHeaders(); len(requests) != 1 {
+		t.Errorf("publisher page requested %d times, want one scrape", len(requests))
+	}
+}
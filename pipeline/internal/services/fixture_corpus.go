@@ -0,0 +1,130 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// FixtureCorpus is a frozen set of articles and videos the news and YouTube services serve in evaluation mode,
+// so the same queries can be replayed against the same sources to compare prompt and pipeline changes
+type FixtureCorpus struct {
+	Articles []models.NewsArticle  `json:"articles"`
+	Videos   []models.YouTubeVideo `json:"videos"`
+	// video id to transcript, videos without one fall back to their description as they would live
+	Transcripts        map[string]string `json:"transcripts,omitempty"`
+	ChannelSubscribers map[string]int64  `json:"channel_subscribers,omitempty"`
+}
+
+// LoadFixtureCorpus reads a corpus from a JSON file
+func LoadFixtureCorpus(path string) (*FixtureCorpus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture corpus: %w", err)
+	}
+
+	var corpus FixtureCorpus
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture corpus %s: %w", path, err)
+	}
+	if len(corpus.Articles) == 0 && len(corpus.Videos) == 0 {
+		return nil, fmt.Errorf("fixture corpus %s has no articles or videos", path)
+	}
+
+	for i := range corpus.Videos {
+		if corpus.Videos[i].DurationSeconds == 0 {
+			corpus.Videos[i].DurationSeconds = videoDurationSeconds(corpus.Videos[i].Duration)
+		}
+	}
+	return &corpus, nil
+}
+
+// fixtureTerms splits keywords or a search query into lowercase match terms, dropping the search operators
+func fixtureTerms(values ...string) []string {
+	var terms []string
+	for _, value := range values {
+		value = strings.NewReplacer(`"`, " ", "(", " ", ")", " ", "+", " ").Replace(value)
+		for _, term := range strings.Fields(strings.ToLower(value)) {
+			if term == "or" || term == "and" || term == "not" {
+				continue
+			}
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// matchFixtures ranks the items mentioning at least one term by how many terms they mention, ties keep
+// corpus order so a run is reproducible
+func matchFixtures[T any](items []T, terms []string, limit int, text func(T) string) []T {
+	type match struct {
+		index int
+		hits  int
+	}
+
+	var matches []match
+	for i, item := range items {
+		haystack := strings.ToLower(text(item))
+		hits := 0
+		for _, term := range terms {
+			if strings.Contains(haystack, term) {
+				hits++
+			}
+		}
+		if hits > 0 {
+			matches = append(matches, match{index: i, hits: hits})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool { return matches[a].hits > matches[b].hits })
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	result := make([]T, len(matches))
+	for i, m := range matches {
+		result[i] = items[m.index]
+	}
+	return result
+}
+
+func (corpus *FixtureCorpus) searchArticles(terms []string, limit int) []models.NewsArticle {
+	return matchFixtures(corpus.Articles, terms, limit, func(article models.NewsArticle) string {
+		return article.Title + " " + article.Description + " " + article.Content
+	})
+}
+
+func (corpus *FixtureCorpus) searchVideos(terms []string, limit int) []models.YouTubeVideo {
+	return matchFixtures(corpus.Videos, terms, limit, func(video models.YouTubeVideo) string {
+		return video.Title + " " + video.Description + " " + strings.Join(video.Tags, " ")
+	})
+}
+
+func (corpus *FixtureCorpus) videoDetails(videoIDs []string) []models.YouTubeVideo {
+	var videos []models.YouTubeVideo
+	for _, video := range corpus.Videos {
+		for _, id := range videoIDs {
+			if video.ID == id {
+				videos = append(videos, video)
+				break
+			}
+		}
+	}
+	return videos
+}
+
+// NewFixtureNewsService serves searches from the corpus instead of NewsAPI, no API key is needed
+func NewFixtureNewsService(corpus *FixtureCorpus, logger *logger.Logger) *NewsService {
+	logger.Info("NewsService serving the fixture corpus", "articles", len(corpus.Articles))
+	return &NewsService{logger: logger, fixtures: corpus}
+}
+
+// NewFixtureYouTubeService serves searches, details and transcripts from the corpus instead of the YouTube API
+func NewFixtureYouTubeService(corpus *FixtureCorpus, logger *logger.Logger) *YouTubeService {
+	logger.Info("YouTube Service serving the fixture corpus", "videos", len(corpus.Videos), "transcripts", len(corpus.Transcripts))
+	return &YouTubeService{logger: logger, fixtures: corpus}
+}
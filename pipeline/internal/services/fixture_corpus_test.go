@@ -0,0 +1,136 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFixtureCorpus saves the corpus as the JSON file evaluation mode loads
+func writeFixtureCorpus(t *testing.T, corpus *FixtureCorpus) string {
+	t.Helper()
+	data, err := json.Marshal(corpus)
+	if err != nil {
+		t.Fatalf("failed to marshal corpus: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "corpus.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write corpus: %v", err)
+	}
+	return path
+}
+
+func TestLoadFixtureCorpus(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), wantErr: "failed to read"},
+		{name: "malformed json", path: write("malformed.json", `{"articles": [`), wantErr: "failed to parse"},
+		{name: "empty corpus", path: write("empty.json", `{"articles": [], "videos": []}`), wantErr: "no articles or videos"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadFixtureCorpus(tt.path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadFixtureCorpus() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	corpus, err := LoadFixtureCorpus(write("videos.json", `{"videos": [{"id": "video-1", "duration": "PT5M30S"}]}`))
+	if err != nil {
+		t.Fatalf("LoadFixtureCorpus() error = %v", err)
+	}
+	if seconds := corpus.Videos[0].DurationSeconds; seconds != 330 {
+		t.Errorf("duration seconds = %d, want them parsed on load", seconds)
+	}
+}
+
+func TestFixtureSearchRanksByMatchedTerms(t *testing.T) {
+	t.Parallel()
+	corpus := &FixtureCorpus{Articles: []models.NewsArticle{
+		{ID: "budget", Title: "Budget vote delayed"},
+		{ID: "weather", Title: "Storm warning"},
+		{ID: "budget-vote", Title: "Parliament budget vote tonight"},
+		{ID: "vote", Title: "Vote count"},
+	}}
+
+	got := corpus.searchArticles(fixtureTerms(`("budget" OR parliament) AND vote`), 0)
+	ids := make([]string, len(got))
+	for i, article := range got {
+		ids[i] = article.ID
+	}
+	if want := []string{"budget-vote", "budget", "vote"}; !slices.Equal(ids, want) {
+		t.Errorf("searchArticles() = %v, want %v", ids, want)
+	}
+	if limited := corpus.searchArticles(fixtureTerms("vote"), 2); len(limited) != 2 {
+		t.Errorf("searchArticles() returned %d articles, want the limit of 2", len(limited))
+	}
+}
+
+func TestFixtureCorpusWorkflowIsGroundedInTheCorpus(t *testing.T) {
+	corpus := newTestCorpus("elections", 3, 1)
+	corpus.Articles = append(corpus.Articles, models.NewsArticle{
+		ID: "cricket", Title: "Cricket final tonight", Description: "The cricket final", URL: "https://news.example.com/cricket",
+		Source: "Sports Desk", Content: strings.Repeat("Cricket details. ", 20),
+	})
+	path := writeFixtureCorpus(t, corpus)
+
+	loaded, err := LoadFixtureCorpus(path)
+	if err != nil {
+		t.Fatalf("LoadFixtureCorpus() error = %v", err)
+	}
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"EVAL_FIXTURES_PATH": path}), "elections", models.IntentNewNewsQuery)
+	log := newTestLogger(t)
+	workflow.orchestrator.newsService = NewFixtureNewsService(loaded, log)
+	workflow.orchestrator.youtubeService = NewFixtureYouTubeService(loaded, log)
+
+	response, err := workflow.run("workflow-fixtures")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if response.Status != string(models.WorkflowStatusCompleted) || strings.TrimSpace(response.Message) == "" {
+		t.Fatalf("response = %s %q, want a completed answer", response.Status, response.Message)
+	}
+
+	// every source is an elections fixture, nothing was fetched live or matched off topic
+	var corpusURLs []string
+	for _, article := range corpus.Articles[:3] {
+		corpusURLs = append(corpusURLs, article.URL)
+	}
+	corpusURLs = append(corpusURLs, corpus.Videos[0].URL)
+	if len(response.Sources) == 0 {
+		t.Fatal("response has no sources")
+	}
+	for _, source := range response.Sources {
+		if !slices.Contains(corpusURLs, source.URL) {
+			t.Errorf("source %s is not an elections fixture", source.URL)
+		}
+	}
+
+	// the summary is written from the fixture text
+	var summarized bool
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") {
+			summarized = strings.Contains(call.Prompt, "elections story 0") && strings.Contains(call.Prompt, "Details about elections.")
+		}
+	}
+	if !summarized {
+		t.Error("the summarizer was not given the fixture articles")
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// defaultScrapeHeaders are sent with every scrape, a domain profile or the request itself may override them
+var defaultScrapeHeaders = map[string]string{
+	"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+	"Accept-Language":           "en-US,en;q=0.5",
+	"Accept-Encoding":           "gzip, deflate, br",
+	"DNT":                       "1",
+	"Connection":                "keep-alive",
+	"Upgrade-Insecure-Requests": "1",
+	"Sec-Fetch-Dest":            "document",
+	"Sec-Fetch-Mode":            "navigate",
+	"Sec-Fetch-Site":            "none",
+	"Cache-Control":             "max-age=0",
+}
+
+// applyScrapeHeaders sets the default headers, then the profile of the host's domain, then the request headers,
+// so a publisher can be given a consent cookie or Referer without every caller knowing about it
+func (service *ScraperService) applyScrapeHeaders(headers *http.Header, host string, requestHeaders map[string]string) {
+	for name, value := range defaultScrapeHeaders {
+		headers.Set(name, value)
+	}
+
+	domain := profileDomain(host, service.config.DomainHeaders, service.config.DomainCookies)
+	if domain != "" {
+		for name, value := range service.config.DomainHeaders[domain] {
+			headers.Set(name, value)
+		}
+		if cookie := cookieHeader(service.config.DomainCookies[domain]); cookie != "" {
+			headers.Set("Cookie", cookie)
+		}
+	}
+
+	for name, value := range requestHeaders {
+		headers.Set(name, value)
+	}
+}
+
+// profileDomain finds the most specific configured domain covering host, news.example.com falls back to example.com
+func profileDomain(host string, profiles ...map[string]map[string]string) string {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	if colon := strings.LastIndexByte(host, ':'); colon >= 0 {
+		host = host[:colon]
+	}
+
+	for candidate := host; candidate != ""; {
+		for _, profile := range profiles {
+			if _, ok := profile[candidate]; ok {
+				return candidate
+			}
+		}
+
+		dot := strings.IndexByte(candidate, '.')
+		if dot < 0 || !strings.Contains(candidate[dot+1:], ".") {
+			break
+		}
+		candidate = candidate[dot+1:]
+	}
+	return ""
+}
+
+func cookieHeader(cookies map[string]string) string {
+	if len(cookies) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+cookies[name])
+	}
+	return strings.Join(pairs, "; ")
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestProfileDomain(t *testing.T) {
+	t.Parallel()
+	headers := map[string]map[string]string{"example.com": {"Referer": "https://www.google.com/"}}
+	cookies := map[string]map[string]string{"news.example.org": {"consent": "yes"}}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "example.com", want: "example.com"},
+		{host: "www.Example.com", want: "example.com"},
+		{host: "news.example.com:8443", want: "example.com"},
+		{host: "news.example.org", want: "news.example.org"},
+		{host: "sport.example.org", want: ""},
+		{host: "example.net", want: ""},
+	}
+	for _, tt := range tests {
+		if got := profileDomain(tt.host, headers, cookies); got != tt.want {
+			t.Errorf("profileDomain(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestScrapeSendsTheDomainProfileAndRequestHeaders(t *testing.T) {
+	var mu sync.Mutex
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = r.Header.Clone()
+		mu.Unlock()
+		w.Write([]byte("<html><head><title>Rates held</title></head><body><p>" +
+			"The central bank kept its benchmark rate unchanged on Thursday, citing easing inflation and steady hiring.</p></body></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	// httptest listens on 127.0.0.1, which is the profile's domain here
+	cfg := loadTestConfig(t, map[string]string{
+		"SCRAPER_DOMAIN_HEADERS":     "127.0.0.1:Referer=https://www.google.com/,Accept-Language=de-DE",
+		"SCRAPER_DOMAIN_COOKIES":     "127.0.0.1:euconsent=granted,gdpr=1;example.org:ignored=1",
+		"SCRAPER_MIN_CONTENT_LENGTH": "60", "SCRAPER_RETRY_ATTEMPTS": "1",
+	})
+	scraper, err := NewScraperService(cfg.Scraper, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+
+	result, err := scraper.ScrapeMultipleURLs(context.Background(), &ScrapingRequest{
+		URLs: []string{server.URL + "/rates"}, MaxConcurrency: 1,
+		Headers: map[string]string{"Accept-Language": "fr-FR", "X-Requested-By": "pipeline"},
+	})
+	if err != nil || len(result.SuccessfulScrapes) != 1 {
+		t.Fatalf("ScrapeMultipleURLs() = %+v, %v, want one successful scrape", result, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for name, want := range map[string]string{
+		"Referer":         "https://www.google.com/",
+		"Cookie":          "euconsent=granted; gdpr=1",
+		"Accept-Language": "fr-FR",
+		"X-Requested-By":  "pipeline",
+		"Sec-Fetch-Mode":  "navigate",
+	} {
+		if got := received.Get(name); got != want {
+			t.Errorf("header %s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestScrapeWithoutAMatchingProfileSendsOnlyTheDefaults(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Write([]byte("<html><body><p>" +
+			"The central bank kept its benchmark rate unchanged on Thursday, citing easing inflation and steady hiring.</p></body></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := loadTestConfig(t, map[string]string{
+		"SCRAPER_DOMAIN_COOKIES":     "example.org:euconsent=granted",
+		"SCRAPER_MIN_CONTENT_LENGTH": "60", "SCRAPER_RETRY_ATTEMPTS": "1",
+	})
+	scraper, err := NewScraperService(cfg.Scraper, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	if _, err := scraper.ScrapeURL(context.Background(), server.URL+"/rates"); err != nil {
+		t.Fatalf("ScrapeURL() error = %v", err)
+	}
+
+	if cookie := received.Get("Cookie"); cookie != "" {
+		t.Errorf("Cookie = %q, want none for a host without a profile", cookie)
+	}
+	if language := received.Get("Accept-Language"); language != defaultScrapeHeaders["Accept-Language"] {
+		t.Errorf("Accept-Language = %q, want the default", language)
+	}
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/pkg/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+const (
+	ScrapeMethodStatic   = "static"
+	ScrapeMethodHeadless = "headless"
+)
+
+// PageRenderer returns a page's HTML after its JavaScript has run
+type PageRenderer interface {
+	Render(ctx context.Context, targetURL string) (string, error)
+}
+
+// httpPageRenderer asks a headless browser service to render the page, it posts {"url": ...} and reads back
+// the rendered HTML, which is what browserless' /content endpoint and most chrome render proxies accept
+type httpPageRenderer struct {
+	endpoint string
+	client   *http.Client
+	maxBytes int
+}
+
+func NewHTTPPageRenderer(endpoint string, timeout time.Duration, maxBytes int) PageRenderer {
+	return &httpPageRenderer{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout, Transport: tracing.Transport(nil)},
+		maxBytes: maxBytes,
+	}
+}
+
+func (renderer *httpPageRenderer) Render(ctx context.Context, targetURL string) (string, error) {
+	body, err := json.Marshal(map[string]string{"url": targetURL})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal render request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, renderer.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create render request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := renderer.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("render request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("renderer returned status %d", resp.StatusCode)
+	}
+
+	var reader io.Reader = resp.Body
+	if renderer.maxBytes > 0 {
+		reader = io.LimitReader(resp.Body, int64(renderer.maxBytes))
+	}
+	rendered, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read rendered page: %w", err)
+	}
+	return string(rendered), nil
+}
+
+// SetPageRenderer swaps the renderer used by the headless fallback, nil turns the fallback off
+func (service *ScraperService) SetPageRenderer(renderer PageRenderer) {
+	service.renderer = renderer
+	if renderer != nil && service.renderSlots == nil {
+		// a renderer set on a scraper configured without the fallback still renders one page at a time
+		service.renderSlots = make(chan struct{}, max(1, service.config.HeadlessMaxConcurrency))
+	}
+}
+
+// needsHeadless reports whether a static scrape that came up short is worth rendering in a browser
+func (service *ScraperService) needsHeadless(content *ScrapedContent, host string) bool {
+	if service.renderer == nil || content.ContentLevel == ScrapeContentFull {
+		return false
+	}
+	return domainListed(host, service.config.HeadlessDomains)
+}
+
+// domainListed matches host and its parent domains against the list, so example.com covers news.example.com
+func domainListed(host string, domains []string) bool {
+	host = strings.TrimPrefix(strings.ToLower(host), "www.")
+	if colon := strings.LastIndexByte(host, ':'); colon >= 0 {
+		host = host[:colon]
+	}
+
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+		if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderFallback renders the page in the headless browser and extracts it the same way as a static scrape.
+// The rendered result replaces the static one only when it carries more article text, a failed render keeps the static result.
+func (service *ScraperService) renderFallback(ctx context.Context, content *ScrapedContent, pageURL *url.URL) *ScrapedContent {
+	startTime := time.Now()
+
+	select {
+	case service.renderSlots <- struct{}{}:
+		defer func() { <-service.renderSlots }()
+	case <-ctx.Done():
+		return content
+	}
+
+	renderCtx, cancel := context.WithTimeout(ctx, service.config.HeadlessTimeout)
+	defer cancel()
+
+	rendered, err := service.renderer.Render(renderCtx, pageURL.String())
+	if err != nil {
+		service.logger.Warn("Headless render failed, keeping static scrape", "url", pageURL.String(), "error", err)
+		return content
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(rendered))
+	if err != nil {
+		service.logger.Warn("Rendered page could not be parsed", "url", pageURL.String(), "error", err)
+		return content
+	}
+
+	root := doc.Find("html").First()
+	if len(root.Nodes) == 0 {
+		return content
+	}
+
+	response := &colly.Response{Request: &colly.Request{URL: pageURL}, Body: []byte(rendered)}
+	element := colly.NewHTMLElementFromSelectionNode(response, root, root.Nodes[0], 0)
+
+	renderedContent := &ScrapedContent{
+		URL:       content.URL,
+		ScrapedAt: time.Now(),
+		Metadata:  make(map[string]string, len(content.Metadata)+2),
+		Tags:      []string{},
+	}
+	for key, value := range content.Metadata {
+		renderedContent.Metadata[key] = value
+	}
+	delete(renderedContent.Metadata, "error_type")
+
+	service.populateFromHTML(renderedContent, element)
+	renderedContent.Content = service.cleanContent(renderedContent.Content)
+	renderedContent.Description = service.cleanContent(renderedContent.Description)
+	renderedContent.Title = strings.TrimSpace(renderedContent.Title)
+	renderedContent.ContentLevel = service.contentLevel(renderedContent)
+	renderedContent.Success = renderedContent.ContentLevel == ScrapeContentFull
+
+	service.logger.LogService("scraper", "scraper_url_headless", time.Since(startTime), map[string]interface{}{
+		"url":            pageURL.String(),
+		"static_length":  len(content.Content),
+		"content_length": len(renderedContent.Content),
+		"content_level":  renderedContent.ContentLevel,
+	}, nil)
+
+	if len(renderedContent.Content) <= len(content.Content) {
+		return content
+	}
+
+	renderedContent.Metadata["scrape_method"] = ScrapeMethodHeadless
+	if !renderedContent.Success {
+		renderedContent.Error = fmt.Sprintf("Article content below minimum length of %d characters", service.config.MinContentLength)
+	}
+	return renderedContent
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stubRenderer hands back a fixed page as the rendered html and counts the pages it was asked for
+type stubRenderer struct {
+	mu   sync.Mutex
+	html string
+	err  error
+	urls []string
+}
+
+func (renderer *stubRenderer) Render(ctx context.Context, targetURL string) (string, error) {
+	renderer.mu.Lock()
+	defer renderer.mu.Unlock()
+	renderer.urls = append(renderer.urls, targetURL)
+	return renderer.html, renderer.err
+}
+
+func (renderer *stubRenderer) rendered() []string {
+	renderer.mu.Lock()
+	defer renderer.mu.Unlock()
+	return append([]string(nil), renderer.urls...)
+}
+
+const (
+	// what a single page app serves before its javascript runs
+	spaShellPage = `<html><head><title>Rates held</title></head><body><div id="root"></div><script src="/app.js"></script></body></html>`
+	renderedPage = `<html><head><title>Rates held</title></head><body><article>` +
+		`<p>The central bank kept its benchmark rate unchanged on Thursday, citing easing inflation and steady hiring across the economy.</p>` +
+		`<p>Policymakers signalled that cuts could follow later in the year if price growth keeps slowing toward the target.</p>` +
+		`</article></body></html>`
+)
+
+func TestDomainListed(t *testing.T) {
+	t.Parallel()
+	domains := []string{"spa-news.com", " WWW.Example.org "}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "spa-news.com", want: true},
+		{host: "www.spa-news.com:443", want: true},
+		{host: "live.spa-news.com", want: true},
+		{host: "example.org", want: true},
+		{host: "notspa-news.com", want: false},
+		{host: "spa-news.com.evil.net", want: false},
+	}
+	for _, tt := range tests {
+		if got := domainListed(tt.host, domains); got != tt.want {
+			t.Errorf("domainListed(%q) = %t, want %t", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestHeadlessFallback(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		page        string
+		domains     []string
+		renderErr   error
+		wantRenders int
+		wantMethod  string
+		wantSuccess bool
+	}{
+		{name: "short static scrape is rendered", page: spaShellPage, domains: []string{"127.0.0.1"},
+			wantRenders: 1, wantMethod: ScrapeMethodHeadless, wantSuccess: true},
+		{name: "unlisted domain is not rendered", page: spaShellPage, domains: []string{"spa-news.com"},
+			wantMethod: ScrapeMethodStatic},
+		{name: "full static scrape is not rendered", page: renderedPage, domains: []string{"127.0.0.1"},
+			wantMethod: ScrapeMethodStatic, wantSuccess: true},
+		{name: "failed render keeps the static scrape", page: spaShellPage, domains: []string{"127.0.0.1"}, renderErr: errors.New("browser crashed"),
+			wantRenders: 1, wantMethod: ScrapeMethodStatic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := newArticlePage(t, tt.page)
+			scraper, err := NewScraperService(config.ScraperConfig{
+				Timeout: 10 * time.Second, RetryAttempts: 1, MinContentLength: 150, HeadlessDomains: tt.domains, HeadlessTimeout: 5 * time.Second,
+			}, nil, newTestLogger(t))
+			if err != nil {
+				t.Fatalf("NewScraperService() error = %v", err)
+			}
+			renderer := &stubRenderer{html: renderedPage, err: tt.renderErr}
+			scraper.SetPageRenderer(renderer)
+
+			content, err := scraper.ScrapeURL(context.Background(), server.URL+"/rates")
+			if err != nil && tt.wantSuccess {
+				t.Fatalf("ScrapeURL() error = %v", err)
+			}
+
+			if renders := len(renderer.rendered()); renders != tt.wantRenders {
+				t.Errorf("rendered %d pages, want %d", renders, tt.wantRenders)
+			}
+			if content.Metadata["scrape_method"] != tt.wantMethod || content.Success != tt.wantSuccess {
+				t.Errorf("scrape = %s success %t, want %s success %t", content.Metadata["scrape_method"], content.Success, tt.wantMethod, tt.wantSuccess)
+			}
+			if tt.wantMethod == ScrapeMethodHeadless && !strings.Contains(content.Content, "Policymakers signalled") {
+				t.Errorf("content = %q, want the rendered article text", content.Content)
+			}
+		})
+	}
+}
+
+func TestHTTPPageRendererPostsTheURL(t *testing.T) {
+	t.Parallel()
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			URL string `json:"url"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		requested = request.URL
+		if request.URL == "https://spa-news.com/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(renderedPage))
+	}))
+	t.Cleanup(server.Close)
+	renderer := NewHTTPPageRenderer(server.URL, 5*time.Second, 64)
+
+	html, err := renderer.Render(context.Background(), "https://spa-news.com/rates")
+	if err != nil || requested != "https://spa-news.com/rates" {
+		t.Fatalf("Render() = %v, renderer asked for %q, want https://spa-news.com/rates", err, requested)
+	}
+	if html != renderedPage[:64] {
+		t.Errorf("Render() = %q, want the page cut at 64 bytes", html)
+	}
+	if _, err := renderer.Render(context.Background(), "https://spa-news.com/broken"); err == nil {
+		t.Error("Render() succeeded against a failing renderer")
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	HeadlineModeOff      = "off"
+	HeadlineModeExtract  = "extract"
+	HeadlineModeGenerate = "generate"
+)
+
+const (
+	// headlines longer than this are cut at a word boundary
+	maxHeadlineChars = 80
+	// a chitchat label keeps only this many words of the query
+	chitchatLabelWords = 6
+)
+
+var (
+	markdownHeadingLine = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+	sentenceEnd         = regexp.MustCompile(`[.!?](\s|$)`)
+)
+
+// GenerateHeadline asks for a one line title for a finished answer, for conversation lists and notifications
+func (service *GeminiService) GenerateHeadline(ctx context.Context, query, intent, response string) (string, error) {
+	start := time.Now()
+
+	prompt := service.prompts.Render("headline", map[string]any{
+		"Query":    query,
+		"Intent":   intent,
+		"Response": truncateUTF8(response, 4000),
+		"MaxChars": maxHeadlineChars,
+	})
+
+	req := &GenerationRequest{
+		Prompt:          prompt,
+		Temperature:     &[]float32{0.2}[0],
+		SystemRole:      "You are a news editor writing short, accurate headlines.",
+		MaxTokens:       64,
+		DisableThinking: true,
+	}
+	service.applyAgentSampling("headline", req)
+
+	resp, err := service.GenerateContent(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("Headline generation failed: %w", err)
+	}
+
+	headline := cleanHeadline(resp.Content)
+	if headline == "" {
+		return "", fmt.Errorf("Headline generation returned empty content")
+	}
+
+	service.logger.LogAgent("", "headline", "generate_headline", time.Since(start), map[string]interface{}{
+		"headline_chars": len(headline),
+		"tokens_used":    resp.TokensUsed,
+	}, nil)
+
+	return headline, nil
+}
+
+// extractHeadline derives a headline without a model call. News answers use their first heading or first full
+// sentence and fall back to the top article's title, chitchat gets a short label from the query.
+func extractHeadline(intent, query, response string, articles []models.NewsArticle) string {
+	if intent == string(models.IntentChitChat) {
+		words := strings.Fields(stripMarkdown(query, false))
+		if len(words) > chitchatLabelWords {
+			return cleanHeadline(strings.Join(words[:chitchatLabelWords], " ") + "…")
+		}
+		return cleanHeadline(strings.Join(words, " "))
+	}
+
+	if match := markdownHeadingLine.FindStringSubmatch(response); match != nil {
+		if headline := cleanHeadline(match[1]); headline != "" {
+			return headline
+		}
+	}
+
+	for _, paragraph := range strings.Split(stripMarkdown(response, false), "\n") {
+		sentence := strings.TrimSpace(paragraph)
+		if loc := sentenceEnd.FindStringIndex(sentence); loc != nil {
+			sentence = sentence[:loc[0]]
+		}
+		// greetings like "Hey there!" say nothing about the story
+		if len(strings.Fields(sentence)) >= 4 {
+			return cleanHeadline(sentence)
+		}
+	}
+
+	if len(articles) > 0 {
+		return cleanHeadline(articles[0].Title)
+	}
+	return ""
+}
+
+// cleanHeadline keeps the first line without markdown, quotes or a trailing period and bounds its length
+func cleanHeadline(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	text = stripMarkdown(text, false)
+	text = strings.Trim(text, "\"'“”‘’` ")
+	text = strings.TrimRight(text, ".:;, ")
+
+	if len([]rune(text)) <= maxHeadlineChars {
+		return text
+	}
+	runes := []rune(text)[:maxHeadlineChars-1]
+	if cut := strings.LastIndexByte(string(runes), ' '); cut > maxHeadlineChars/2 {
+		return strings.TrimRight(string(runes)[:cut], ".,;: ") + "…"
+	}
+	return string(runes) + "…"
+}
+
+// headlineMode reports how this request's headline is produced, a request's "headline" turns on extraction
+// when the configured mode is off
+func (orchestrator *Orchestrator) headlineMode(workflowCtx *models.WorkflowContext) string {
+	mode := orchestrator.config.Workflow.HeadlineMode
+	if mode == HeadlineModeOff && workflowCtx.RequestBool("headline") {
+		return HeadlineModeExtract
+	}
+	return mode
+}
+
+// generateHeadline stores a headline for the finished answer under Metadata["headline"]. Generation falls back to
+// extraction when the model call fails, a missing headline never fails the workflow.
+func (workflowExecutor *WorkflowExecutor) generateHeadline(ctx context.Context) {
+	mode := workflowExecutor.orchestrator.headlineMode(workflowExecutor.workflowCtx)
+	if mode == HeadlineModeOff || workflowExecutor.workflowCtx.Response == "" {
+		return
+	}
+
+	startTime := time.Now()
+	workflowCtx := workflowExecutor.workflowCtx
+
+	var headline string
+	var err error
+	if mode == HeadlineModeGenerate {
+		headline, err = workflowExecutor.orchestrator.geminiService.GenerateHeadline(ctx, workflowCtx.OriginalQuery, workflowCtx.Intent, workflowCtx.Response)
+		if err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Headline generation failed, extracting from the response")
+		} else {
+			workflowCtx.ProcessingStats.APICallsCount++
+		}
+	}
+	if headline == "" {
+		headline = extractHeadline(workflowCtx.Intent, workflowCtx.OriginalQuery, workflowCtx.Response, workflowCtx.Articles)
+	}
+
+	workflowExecutor.recordAgentExecution("headline", time.Since(startTime),
+		map[string]any{"mode": mode},
+		map[string]any{"headline": headline}, err)
+
+	if headline != "" {
+		workflowCtx.Metadata["headline"] = headline
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCleanHeadline(t *testing.T) {
+	t.Parallel()
+	long := "Central bank holds rates steady as inflation cools and hiring slows across most of the economy this quarter"
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "markdown and quotes", text: `"**Rates held steady.**"`, want: "Rates held steady"},
+		{name: "first line only", text: "Rates held steady\nThe bank said more", want: "Rates held steady"},
+		{name: "curly quotes", text: "“Flood warning lifted”", want: "Flood warning lifted"},
+		{name: "cut at a word", text: long, want: "Central bank holds rates steady as inflation cools and hiring slows across…"},
+		{name: "cut without spaces", text: strings.Repeat("a", 100), want: strings.Repeat("a", maxHeadlineChars-1) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cleanHeadline(tt.text)
+			if got != tt.want {
+				t.Errorf("cleanHeadline() = %q, want %q", got, tt.want)
+			}
+			if length := utf8.RuneCountInString(got); length > maxHeadlineChars {
+				t.Errorf("cleanHeadline() is %d characters, want at most %d", length, maxHeadlineChars)
+			}
+		})
+	}
+}
+
+func TestExtractHeadline(t *testing.T) {
+	t.Parallel()
+	articles := []models.NewsArticle{{Title: "Parliament passes the budget"}}
+	tests := []struct {
+		name     string
+		intent   models.Intent
+		query    string
+		response string
+		want     string
+	}{
+		{name: "chitchat label", intent: models.IntentChitChat, query: "hey how are you doing on this fine morning",
+			response: "Doing well, thanks for asking!", want: "hey how are you doing on…"},
+		{name: "short chitchat", intent: models.IntentChitChat, query: "thanks!", response: "Any time.", want: "thanks!"},
+		{name: "first heading", intent: models.IntentNewNewsQuery, query: "budget",
+			response: "Hi!\n\n## Budget passes after late vote\n\nThe parliament approved it.", want: "Budget passes after late vote"},
+		{name: "first sentence after a greeting", intent: models.IntentNewNewsQuery, query: "budget",
+			response: "Hey there!\n\nThe parliament passed the budget on Friday. It now goes to the senate.", want: "The parliament passed the budget on Friday"},
+		{name: "top article title", intent: models.IntentNewNewsQuery, query: "budget", response: "Big news!", want: "Parliament passes the budget"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractHeadline(string(tt.intent), tt.query, tt.response, articles); got != tt.want {
+				t.Errorf("extractHeadline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflowHeadlineModes(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		metadata     map[string]any
+		failModel    bool
+		want         string
+		wantGenerate bool
+	}{
+		{name: "off by default", mode: HeadlineModeOff},
+		{name: "extracted from the answer", mode: HeadlineModeExtract, want: "Here is what is happening with elections"},
+		{name: "requested while off", mode: HeadlineModeOff, metadata: map[string]any{"headline": true}, want: "Here is what is happening with elections"},
+		{name: "generated", mode: HeadlineModeGenerate, want: "Opposition leads in final election polls", wantGenerate: true},
+		{name: "failed generation is extracted", mode: HeadlineModeGenerate, failModel: true, want: "Here is what is happening with elections", wantGenerate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{"HEADLINE_MODE": tt.mode})
+			cfg.Gemini.MaxRetries = 1
+			workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+			workflow.answerAgent("news editor writing short", func(call fakeGeminiCall) string {
+				return `"Opposition leads in final election polls."`
+			})
+			if tt.failModel {
+				workflow.failAgent("news editor writing short")
+			}
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-headline", Query: "what is the latest on elections", Metadata: tt.metadata,
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			if response.Title != tt.want {
+				t.Errorf("Title = %q, want %q", response.Title, tt.want)
+			}
+			if length := utf8.RuneCountInString(response.Title); length > maxHeadlineChars {
+				t.Errorf("Title is %d characters, want at most %d", length, maxHeadlineChars)
+			}
+			generated := false
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "news editor writing short") {
+					generated = true
+					if !strings.Contains(call.Prompt, "Here is what is happening with elections") {
+						t.Errorf("headline prompt is missing the answer:\n%s", call.Prompt)
+					}
+				}
+			}
+			if generated != tt.wantGenerate {
+				t.Errorf("headline model called %t, want %t", generated, tt.wantGenerate)
+			}
+		})
+	}
+}
+
+func TestChitchatHeadlineIsAShortLabel(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"HEADLINE_MODE": HeadlineModeExtract}), "weekend", models.IntentChitChat)
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-chitchat-headline", Query: "any fun plans for the long weekend ahead of us",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Title != "any fun plans for the long…" {
+		t.Errorf("Title = %q, want the start of the query", response.Title)
+	}
+}
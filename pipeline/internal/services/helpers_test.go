@@ -0,0 +1,776 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func newTestLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+	log, err := logger.New(config.LogConfig{Level: "error", Format: "json"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	return log
+}
+
+// newTestOrchestrator wires an orchestrator without any backing services, tests attach the fakes they need
+func newTestOrchestrator(t *testing.T, cfg config.Config) *Orchestrator {
+	t.Helper()
+	if cfg.Workflow.MaxConcurrency <= 0 {
+		cfg.Workflow.MaxConcurrency = 4
+	}
+	orchestrator := NewOrchestrator(nil, nil, nil, nil, nil, nil, nil, cfg, newTestLogger(t))
+	orchestrator.SetEmbeddingProvider(&fakeEmbeddings{})
+	return orchestrator
+}
+
+// newTestExecutor starts a stateless workflow for the request, its updates land in the workflow's ring buffer
+func newTestExecutor(t *testing.T, orchestrator *Orchestrator, req models.WorkflowRequest) *WorkflowExecutor {
+	t.Helper()
+	workflowCtx := models.NewWorkflowContext(req, "test-request")
+	workflowCtx.Stateless = true
+	orchestrator.updateBuffers.Store(workflowCtx.ID, newUpdateRing(statelessUpdateBufferSize))
+	t.Cleanup(func() { orchestrator.updateBuffers.Delete(workflowCtx.ID) })
+
+	return &WorkflowExecutor{
+		orchestrator: orchestrator,
+		workflowCtx:  workflowCtx,
+		logger:       orchestrator.logger,
+	}
+}
+
+// fakeEmbeddings hashes words into a small vector, texts sharing words end up close to each other
+type fakeEmbeddings struct {
+	mu    sync.Mutex
+	texts []string
+	err   error
+}
+
+const fakeEmbeddingDimensions = 32
+
+func fakeEmbedding(text string) []float64 {
+	vector := make([]float64, fakeEmbeddingDimensions)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		hash := fnv.New32a()
+		hash.Write([]byte(strings.Trim(word, ".,:;!?-\"'")))
+		vector[hash.Sum32()%fakeEmbeddingDimensions]++
+	}
+	return vector
+}
+
+func (provider *fakeEmbeddings) embed(texts ...string) ([][]float64, error) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if provider.err != nil {
+		return nil, provider.err
+	}
+	provider.texts = append(provider.texts, texts...)
+
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		embeddings[i] = fakeEmbedding(text)
+	}
+	return embeddings, nil
+}
+
+func (provider *fakeEmbeddings) embeddedTexts() []string {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	return append([]string(nil), provider.texts...)
+}
+
+func (provider *fakeEmbeddings) GenerateQueryEmbedding(ctx context.Context, text string) ([]float64, error) {
+	embeddings, err := provider.embed(text)
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (provider *fakeEmbeddings) GenerateNewsEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return provider.GenerateQueryEmbedding(ctx, text)
+}
+
+func (provider *fakeEmbeddings) BatchGenerateNewsEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return provider.embed(texts...)
+}
+
+func (provider *fakeEmbeddings) BatchGenerateVideoEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	return provider.embed(texts...)
+}
+
+func (provider *fakeEmbeddings) HealthCheck(ctx context.Context) error {
+	return provider.err
+}
+
+// fakeChroma serves the ChromaDB v2 collection endpoints the service uses and keeps what was written to it,
+// queries return the stored documents nearest to the query embedding
+type fakeChroma struct {
+	mu       sync.Mutex
+	added    map[string][]AddRequest
+	upserted map[string][]AddRequest
+	// returned by every query of the named collection instead of the stored documents
+	queryResponses map[string]QueryResponse
+	// matching add requests are answered with a server error and not stored
+	rejects func(collection string, request AddRequest) bool
+	// query and get request bodies as received, keyed by operation
+	requests map[string][]map[string]interface{}
+	// collections created next to the news and video ones, in creation order
+	created []string
+	// ids removed by delete requests, keyed by collection
+	deleted map[string]map[string]bool
+}
+
+func newFakeChroma(t *testing.T) (*fakeChroma, *ChromaDBService) {
+	t.Helper()
+	chroma := &fakeChroma{
+		added:          make(map[string][]AddRequest),
+		upserted:       make(map[string][]AddRequest),
+		queryResponses: make(map[string]QueryResponse),
+		requests:       make(map[string][]map[string]interface{}),
+		deleted:        make(map[string]map[string]bool),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(chroma.serveHTTP))
+	t.Cleanup(server.Close)
+
+	baseURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse fake chroma url: %v", err)
+	}
+
+	return chroma, &ChromaDBService{
+		client:           server.Client(),
+		baseURL:          baseURL,
+		logger:           newTestLogger(t),
+		tenant:           "default_tenant",
+		database:         "default_database",
+		batchSize:        100,
+		batchConcurrency: 1,
+		queryInclude:     []string{"metadatas", "distances"},
+	}
+}
+
+func (chroma *fakeChroma) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	chroma.mu.Lock()
+	defer chroma.mu.Unlock()
+
+	path := r.URL.Path
+	if r.Method == http.MethodGet && strings.HasSuffix(path, "/collections") {
+		collections := []map[string]string{
+			{"name": NewsCollectionName, "id": NewsCollectionName},
+			{"name": VideosCollectionName, "id": VideosCollectionName},
+		}
+		for _, name := range chroma.created {
+			collections = append(collections, map[string]string{"name": name, "id": name})
+		}
+		json.NewEncoder(w).Encode(collections)
+		return
+	}
+	if r.Method == http.MethodPost && strings.HasSuffix(path, "/collections") {
+		var request struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Name == "" {
+			http.Error(w, "collection name required", http.StatusBadRequest)
+			return
+		}
+		if !slices.Contains(chroma.created, request.Name) {
+			chroma.created = append(chroma.created, request.Name)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"name": request.Name, "id": request.Name})
+		return
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	collection, operation := parts[len(parts)-2], parts[len(parts)-1]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if operation == "query" || operation == "get" {
+		var request map[string]interface{}
+		json.Unmarshal(body, &request)
+		chroma.requests[operation] = append(chroma.requests[operation], request)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	switch operation {
+	case "add", "upsert":
+		var request AddRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if chroma.rejects != nil && chroma.rejects(collection, request) {
+			http.Error(w, "payload too large", http.StatusInternalServerError)
+			return
+		}
+		if operation == "add" {
+			chroma.added[collection] = append(chroma.added[collection], request)
+		} else {
+			chroma.upserted[collection] = append(chroma.upserted[collection], request)
+		}
+		w.WriteHeader(http.StatusCreated)
+	case "query":
+		if response, exists := chroma.queryResponses[collection]; exists {
+			json.NewEncoder(w).Encode(response)
+			return
+		}
+		var request QueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(chroma.nearest(collection, request))
+	case "get":
+		var request GetRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response := GetResponse{IDs: []string{}, Documents: []string{}, Metadatas: []map[string]interface{}{}}
+		documents := chroma.stored(collection, request.Where, request.WhereDocument)
+		for _, document := range documents[min(request.Offset, len(documents)):] {
+			if request.Limit > 0 && len(response.IDs) == request.Limit {
+				break
+			}
+			response.IDs = append(response.IDs, document.id)
+			response.Documents = append(response.Documents, document.document)
+			response.Metadatas = append(response.Metadatas, document.metadata)
+		}
+		json.NewEncoder(w).Encode(response)
+	case "delete":
+		var request struct {
+			IDs []string `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if chroma.deleted[collection] == nil {
+			chroma.deleted[collection] = make(map[string]bool)
+		}
+		for _, id := range request.IDs {
+			chroma.deleted[collection][id] = true
+		}
+		w.Write([]byte("{}"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+type fakeChromaDocument struct {
+	id        string
+	document  string
+	metadata  map[string]interface{}
+	embedding []float64
+	distance  float64
+}
+
+// stored returns the collection's documents matching the where and where_document filters in first write order,
+// later writes of an id replace earlier ones and deleted ids are left out
+func (chroma *fakeChroma) stored(collection string, where, whereDocument map[string]interface{}) []fakeChromaDocument {
+	latest := make(map[string]fakeChromaDocument)
+	var order []string
+	for _, writes := range [][]AddRequest{chroma.added[collection], chroma.upserted[collection]} {
+		for _, write := range writes {
+			for i, id := range write.IDs {
+				if _, exists := latest[id]; !exists {
+					order = append(order, id)
+				}
+				document := fakeChromaDocument{id: id}
+				if i < len(write.Documents) {
+					document.document = write.Documents[i]
+				}
+				if i < len(write.Metadatas) {
+					document.metadata = write.Metadatas[i]
+				}
+				if i < len(write.Embeddings) {
+					document.embedding = write.Embeddings[i]
+				}
+				latest[id] = document
+			}
+		}
+	}
+
+	documents := make([]fakeChromaDocument, 0, len(order))
+	for _, id := range order {
+		document := latest[id]
+		if chroma.deleted[collection][id] {
+			continue
+		}
+		if matchesWhere(where, document.metadata) && matchesWhereDocument(whereDocument, document.document) {
+			documents = append(documents, document)
+		}
+	}
+	return documents
+}
+
+// matchesWhere evaluates the subset of chroma's metadata filter the service sends: $and, $or, plain equality
+// and the comparison operators, strings compare lexically
+func matchesWhere(where map[string]interface{}, metadata map[string]interface{}) bool {
+	for key, condition := range where {
+		switch key {
+		case "$and", "$or":
+			clauses, _ := condition.([]interface{})
+			matched := 0
+			for _, clause := range clauses {
+				if clause, ok := clause.(map[string]interface{}); ok && matchesWhere(clause, metadata) {
+					matched++
+				}
+			}
+			if (key == "$and" && matched != len(clauses)) || (key == "$or" && matched == 0) {
+				return false
+			}
+			continue
+		}
+
+		operators, ok := condition.(map[string]interface{})
+		if !ok {
+			operators = map[string]interface{}{"$eq": condition}
+		}
+		value := fmt.Sprint(metadata[key])
+		for operator, operand := range operators {
+			compared := strings.Compare(value, fmt.Sprint(operand))
+			if left, err := strconv.ParseFloat(value, 64); err == nil {
+				if right, ok := operand.(float64); ok {
+					compared = cmp.Compare(left, right)
+				}
+			}
+			var holds bool
+			switch operator {
+			case "$eq":
+				holds = compared == 0
+			case "$ne":
+				holds = compared != 0
+			case "$gt":
+				holds = compared > 0
+			case "$gte":
+				holds = compared >= 0
+			case "$lt":
+				holds = compared < 0
+			case "$lte":
+				holds = compared <= 0
+			}
+			if !holds {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesWhereDocument evaluates $contains, $not_contains, $and and $or against the document text, case sensitively
+func matchesWhereDocument(whereDocument map[string]interface{}, document string) bool {
+	for key, condition := range whereDocument {
+		switch key {
+		case "$contains":
+			if !strings.Contains(document, fmt.Sprint(condition)) {
+				return false
+			}
+		case "$not_contains":
+			if strings.Contains(document, fmt.Sprint(condition)) {
+				return false
+			}
+		case "$and", "$or":
+			clauses, _ := condition.([]interface{})
+			matched := 0
+			for _, clause := range clauses {
+				if clause, ok := clause.(map[string]interface{}); ok && matchesWhereDocument(clause, document) {
+					matched++
+				}
+			}
+			if (key == "$and" && matched != len(clauses)) || (key == "$or" && matched == 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// nearest ranks the collection's stored documents matching the request's filters by cosine distance to the
+// first query embedding
+func (chroma *fakeChroma) nearest(collection string, request QueryRequest) QueryResponse {
+	documents := chroma.stored(collection, request.Where, request.WhereDocument)
+	for i := range documents {
+		documents[i].distance = 1
+		if len(documents[i].embedding) > 0 && len(request.QueryEmbeddings) > 0 {
+			documents[i].distance = 1 - cosineSimilarity(request.QueryEmbeddings[0], documents[i].embedding)
+		}
+	}
+
+	sort.SliceStable(documents, func(i, j int) bool { return documents[i].distance < documents[j].distance })
+	if request.NResults > 0 && len(documents) > request.NResults {
+		documents = documents[:request.NResults]
+	}
+
+	// like chroma, documents are only returned when the query includes them
+	response := QueryResponse{IDs: [][]string{{}}, Metadatas: [][]map[string]interface{}{{}}, Distances: [][]float64{{}}}
+	if slices.Contains(request.Include, "documents") {
+		response.Documents = [][]string{{}}
+	}
+	for _, document := range documents {
+		response.IDs[0] = append(response.IDs[0], document.id)
+		if response.Documents != nil {
+			response.Documents[0] = append(response.Documents[0], document.document)
+		}
+		response.Metadatas[0] = append(response.Metadatas[0], document.metadata)
+		response.Distances[0] = append(response.Distances[0], document.distance)
+	}
+	return response
+}
+
+// received returns the bodies of the query or get requests the fake served
+func (chroma *fakeChroma) received(operation string) []map[string]interface{} {
+	chroma.mu.Lock()
+	defer chroma.mu.Unlock()
+	return append([]map[string]interface{}(nil), chroma.requests[operation]...)
+}
+
+func (chroma *fakeChroma) writes(collection string, upserts bool) []AddRequest {
+	chroma.mu.Lock()
+	defer chroma.mu.Unlock()
+	if upserts {
+		return append([]AddRequest(nil), chroma.upserted[collection]...)
+	}
+	return append([]AddRequest(nil), chroma.added[collection]...)
+}
+
+// newTestCorpus builds a corpus of articles and videos that all mention topic, with transcripts for every video
+func newTestCorpus(topic string, articles, videos int) *FixtureCorpus {
+	corpus := &FixtureCorpus{Transcripts: make(map[string]string)}
+	for i := 0; i < articles; i++ {
+		corpus.Articles = append(corpus.Articles, models.NewsArticle{
+			ID:          fmt.Sprintf("article-%d", i),
+			Title:       fmt.Sprintf("%s story %d", topic, i),
+			Description: fmt.Sprintf("Coverage of %s number %d", topic, i),
+			Content:     strings.Repeat(fmt.Sprintf("Details about %s. ", topic), 20),
+			URL:         fmt.Sprintf("https://news.example.com/%d", i),
+			Source:      "Example News",
+			PublishedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+		})
+	}
+	for i := 0; i < videos; i++ {
+		id := fmt.Sprintf("video-%d", i)
+		corpus.Videos = append(corpus.Videos, models.YouTubeVideo{
+			ID:          id,
+			Title:       fmt.Sprintf("%s explained %d", topic, i),
+			Description: fmt.Sprintf("A video about %s", topic),
+			URL:         "https://www.youtube.com/watch?v=" + id,
+			Channel:     "Example Channel",
+			PublishedAt: time.Now().Add(-time.Duration(i) * time.Hour),
+		})
+		corpus.Transcripts[id] = strings.Repeat(fmt.Sprintf("Talking about %s. ", topic), 20)
+	}
+	return corpus
+}
+
+// fakeGeminiCall is one generateContent request as the fake Gemini API received it
+type fakeGeminiCall struct {
+	Model            string
+	SystemPrompt     string
+	Prompt           string
+	GenerationConfig genai.GenerationConfig
+}
+
+// fakeGemini answers generateContent requests with whatever respond returns for them
+type fakeGemini struct {
+	mu      sync.Mutex
+	calls   []fakeGeminiCall
+	respond func(call fakeGeminiCall) string
+	// fails rejects the calls it matches with a server error, nil accepts every call
+	fails func(call fakeGeminiCall) bool
+}
+
+func newFakeGemini(t *testing.T, cfg config.GeminiConfig, respond func(call fakeGeminiCall) string) (*fakeGemini, *GeminiService) {
+	t.Helper()
+	gemini := &fakeGemini{respond: respond}
+	server := httptest.NewServer(http.HandlerFunc(gemini.serveHTTP))
+	t.Cleanup(server.Close)
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  server.Client(),
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("failed to create gemini client: %v", err)
+	}
+
+	log := newTestLogger(t)
+	prompts, err := NewPromptRegistry("", log)
+	if err != nil {
+		t.Fatalf("failed to load prompts: %v", err)
+	}
+
+	if cfg.Model == "" {
+		cfg.Model = "gemini-test"
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 1
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxConcurrency == 0 {
+		cfg.MaxConcurrency = 8
+	}
+
+	return gemini, &GeminiService{
+		client:       client,
+		config:       cfg,
+		logger:       log,
+		prompts:      prompts,
+		semaphore:    make(chan struct{}, cfg.MaxConcurrency),
+		agentConfigs: models.ApplyAgentModels(models.DefaultAgentConfigs(), cfg.AgentModels),
+	}
+}
+
+func (gemini *fakeGemini) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Contents          []*genai.Content       `json:"contents"`
+		SystemInstruction *genai.Content         `json:"systemInstruction"`
+		GenerationConfig  genai.GenerationConfig `json:"generationConfig"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	call := fakeGeminiCall{
+		Model:            strings.TrimSuffix(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], ":generateContent"),
+		GenerationConfig: request.GenerationConfig,
+	}
+	for _, content := range request.Contents {
+		for _, part := range content.Parts {
+			call.Prompt += part.Text
+		}
+	}
+	if request.SystemInstruction != nil {
+		for _, part := range request.SystemInstruction.Parts {
+			call.SystemPrompt += part.Text
+		}
+	}
+
+	gemini.mu.Lock()
+	gemini.calls = append(gemini.calls, call)
+	gemini.mu.Unlock()
+
+	if gemini.fails != nil && gemini.fails(call) {
+		http.Error(w, `{"error": {"code": 400, "message": "rejected by test", "status": "INVALID_ARGUMENT"}}`, http.StatusBadRequest)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"candidates": []map[string]any{{
+			"content":      map[string]any{"role": "model", "parts": []map[string]string{{"text": gemini.respond(call)}}},
+			"finishReason": "STOP",
+		}},
+		"usageMetadata": map[string]int{"promptTokenCount": len(call.Prompt) / 4, "candidatesTokenCount": 10, "totalTokenCount": len(call.Prompt)/4 + 10},
+	})
+}
+
+func (gemini *fakeGemini) received() []fakeGeminiCall {
+	gemini.mu.Lock()
+	defer gemini.mu.Unlock()
+	return append([]fakeGeminiCall(nil), gemini.calls...)
+}
+
+// loadTestConfig loads the server's default configuration, env holds the overrides a test needs
+func loadTestConfig(t *testing.T, env map[string]string) config.Config {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile(".env", nil, 0o600); err != nil {
+		t.Fatalf("failed to write .env: %v", err)
+	}
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	t.Setenv("NEWS_API_KEY", "test-key")
+	t.Setenv("LOG_LEVEL", "error")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	return *cfg
+}
+
+// newUnreachableRedis returns a redis service whose servers refuse every connection
+func newUnreachableRedis(t *testing.T, redisConfig config.RedisConfig) *RedisService {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	redisConfig.StreamsURL = "redis://" + address
+	redisConfig.MemoryURL = "redis://" + address
+	redisConfig.AllowStateless = true
+	redisConfig.DialTimeout = 100 * time.Millisecond
+
+	service, err := NewRedisService(redisConfig, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewRedisService() error = %v", err)
+	}
+	return service
+}
+
+// testWorkflow runs whole workflows against fakes: redis is unreachable, news and videos come from the corpus
+// and chroma, embeddings and gemini are served in process
+type testWorkflow struct {
+	orchestrator *Orchestrator
+	gemini       *fakeGemini
+	chroma       *fakeChroma
+	corpus       *FixtureCorpus
+	topic        string
+}
+
+func newTestWorkflow(t *testing.T, cfg config.Config, topic string, intent models.Intent) *testWorkflow {
+	t.Helper()
+	// fixture articles are never scraped
+	cfg.Eval.FixturesPath = "in-memory"
+
+	workflow := &testWorkflow{corpus: newTestCorpus(topic, 5, 2), topic: topic}
+	var chromaDBService *ChromaDBService
+	workflow.chroma, chromaDBService = newFakeChroma(t)
+	var geminiService *GeminiService
+	workflow.gemini, geminiService = newFakeGemini(t, cfg.Gemini, answerWorkflowCall(topic, intent))
+
+	log := newTestLogger(t)
+	workflow.orchestrator = NewOrchestrator(newUnreachableRedis(t, cfg.Redis), geminiService,
+		NewFixtureYouTubeService(workflow.corpus, log), nil, chromaDBService,
+		NewFixtureNewsService(workflow.corpus, log), nil, cfg, log)
+	workflow.orchestrator.SetEmbeddingProvider(&fakeEmbeddings{})
+	return workflow
+}
+
+// run asks user-1's news query about the workflow's topic
+func (workflow *testWorkflow) run(workflowID string) (*models.WorkflowResponse, error) {
+	return workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: workflowID, Query: "what is the latest on " + workflow.topic,
+	})
+}
+
+// waitUntil polls condition for up to five seconds and fails the test if it never holds
+func waitUntil(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !condition(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+	}
+}
+
+// answerAgent replaces the answer of the agent whose system prompt contains marker
+func (workflow *testWorkflow) answerAgent(marker string, answer func(call fakeGeminiCall) string) {
+	fallback := workflow.gemini.respond
+	workflow.gemini.respond = func(call fakeGeminiCall) string {
+		if strings.Contains(call.SystemPrompt, marker) {
+			return answer(call)
+		}
+		return fallback(call)
+	}
+}
+
+// failAgent makes every call of the agent whose system prompt contains marker fail
+func (workflow *testWorkflow) failAgent(marker string) {
+	workflow.gemini.fails = func(call fakeGeminiCall) bool { return strings.Contains(call.SystemPrompt, marker) }
+}
+
+// holdAgent keeps the agent whose system prompt contains marker from answering until the returned release is called
+func (workflow *testWorkflow) holdAgent(t *testing.T, marker string) (release func()) {
+	t.Helper()
+	held := make(chan struct{})
+	var once sync.Once
+	release = func() { once.Do(func() { close(held) }) }
+	t.Cleanup(release)
+
+	answer := workflow.gemini.respond
+	workflow.gemini.respond = func(call fakeGeminiCall) string {
+		if strings.Contains(call.SystemPrompt, marker) {
+			<-held
+		}
+		return answer(call)
+	}
+	return release
+}
+
+var (
+	promptArticleID = regexp.MustCompile(`"id": (\d+)`)
+	promptVideoID   = regexp.MustCompile(`VIDEO (\d+):`)
+)
+
+// answerWorkflowCall gives every agent a minimal valid answer, the classifier picks intent and the keywords are the topic
+func answerWorkflowCall(topic string, intent models.Intent) func(call fakeGeminiCall) string {
+	relevant := func(pattern *regexp.Regexp, prompt, key string) string {
+		// the prompt's format example repeats an id, each source is rated once
+		var items []string
+		seen := make(map[string]bool)
+		for _, match := range pattern.FindAllStringSubmatch(prompt, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				items = append(items, fmt.Sprintf(`{"id": %s, "relevance_score": 0.9}`, match[1]))
+			}
+		}
+		return fmt.Sprintf(`{"%s": [%s]}`, key, strings.Join(items, ", "))
+	}
+
+	return func(call fakeGeminiCall) string {
+		switch {
+		case strings.Contains(call.SystemPrompt, "intent classifier"):
+			return fmt.Sprintf(`{"intent": "%s", "confidence": 0.95, "reasoning": "test classification"}`, intent)
+		case strings.Contains(call.SystemPrompt, "Query Expansion and Keyword Extraction"):
+			return fmt.Sprintf(`{"enhanced_query": "latest %s news", "keywords": ["%s"]}`, topic, topic)
+		case strings.Contains(call.SystemPrompt, "Query Expansion"):
+			return fmt.Sprintf("ENHANCED_QUERY: latest %s news", topic)
+		case strings.Contains(call.SystemPrompt, "Keyword Extractor"):
+			return topic
+		case strings.Contains(call.SystemPrompt, "news relevancy"):
+			return relevant(promptArticleID, call.Prompt, "relevant_articles")
+		case strings.Contains(call.SystemPrompt, "video relevancy"):
+			return relevant(promptVideoID, call.Prompt, "relevant_videos")
+		case strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer"):
+			return fmt.Sprintf("The %s story in brief, drawn from the articles and videos.", topic)
+		case strings.Contains(call.SystemPrompt, "Content Personalizer"):
+			return fmt.Sprintf("Here is what is happening with %s.", topic)
+		default:
+			return fmt.Sprintf("A short answer about %s.", topic)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDowngradeWeakFollowUp(t *testing.T) {
+	tests := []struct {
+		name       string
+		confidence float64
+		entities   []string
+		want       models.Intent
+	}{
+		{name: "confident follow up stays", confidence: 0.9, want: models.IntentFollowUpDiscussion},
+		{name: "at the threshold stays", confidence: 0.7, want: models.IntentFollowUpDiscussion},
+		{name: "weak with entities becomes news", confidence: 0.55, entities: []string{"Tesla"}, want: models.IntentNewNewsQuery},
+		{name: "weak without entities becomes chitchat", confidence: 0.55, want: models.IntentChitChat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Workflow.FollowUpMinConfidence = 0.7
+			executor := newTestExecutor(t, newTestOrchestrator(t, cfg), models.WorkflowRequest{Query: "what about it"})
+
+			intentResult := &IntentClassificationResult{
+				Intent:               string(models.IntentFollowUpDiscussion),
+				Confidence:           tt.confidence,
+				Entities:             tt.entities,
+				ReferencedTopic:      "earlier topic",
+				ReferencedExchangeID: "exchange-1",
+			}
+			executor.downgradeWeakFollowUp(intentResult)
+
+			if intentResult.Intent != string(tt.want) {
+				t.Fatalf("intent = %s, want %s", intentResult.Intent, tt.want)
+			}
+			downgraded := tt.want != models.IntentFollowUpDiscussion
+			if downgraded != (intentResult.ReferencedTopic == "") {
+				t.Errorf("referenced topic = %q, a downgrade must drop it and only a downgrade", intentResult.ReferencedTopic)
+			}
+			if downgraded && !strings.Contains(intentResult.Reasoning, "follow-up downgrade") {
+				t.Errorf("reasoning %q does not record the downgrade", intentResult.Reasoning)
+			}
+		})
+	}
+}
+
+func TestBreakIntentTie(t *testing.T) {
+	candidates := func(first, second models.Intent) []IntentScore {
+		return []IntentScore{{Intent: string(first), Score: 0.5}, {Intent: string(second), Score: 0.45}}
+	}
+
+	tests := []struct {
+		name       string
+		result     IntentClassificationResult
+		hasHistory bool
+		want       models.Intent
+		wantScore  float64
+		applied    bool
+	}{
+		{
+			name:      "confident classification is kept",
+			result:    IntentClassificationResult{Intent: string(models.IntentChitChat), Confidence: 0.9, Candidates: candidates(models.IntentChitChat, models.IntentNewNewsQuery)},
+			want:      models.IntentChitChat,
+			wantScore: 0.9,
+		},
+		{
+			name: "clear winner is kept",
+			result: IntentClassificationResult{Intent: string(models.IntentChitChat), Confidence: 0.5,
+				Candidates: []IntentScore{{Intent: string(models.IntentChitChat), Score: 0.8}, {Intent: string(models.IntentNewNewsQuery), Score: 0.2}}},
+			want:      models.IntentChitChat,
+			wantScore: 0.5,
+		},
+		{
+			name: "entities prefer news",
+			result: IntentClassificationResult{Intent: string(models.IntentChitChat), Confidence: 0.5, Entities: []string{"NASA"},
+				Candidates: candidates(models.IntentChitChat, models.IntentNewNewsQuery)},
+			want:      models.IntentNewNewsQuery,
+			wantScore: 0.45,
+			applied:   true,
+		},
+		{
+			name:      "follow up without history loses",
+			result:    IntentClassificationResult{Intent: string(models.IntentFollowUpDiscussion), Confidence: 0.5, Candidates: candidates(models.IntentFollowUpDiscussion, models.IntentChitChat)},
+			want:      models.IntentChitChat,
+			wantScore: 0.45,
+			applied:   true,
+		},
+		{
+			name:       "follow up with history keeps the top candidate",
+			result:     IntentClassificationResult{Intent: string(models.IntentFollowUpDiscussion), Confidence: 0.5, Candidates: candidates(models.IntentFollowUpDiscussion, models.IntentChitChat)},
+			hasHistory: true,
+			want:       models.IntentFollowUpDiscussion,
+			wantScore:  0.5,
+			applied:    true,
+		},
+		{
+			name: "unknown candidates are dropped",
+			result: IntentClassificationResult{Intent: string(models.IntentChitChat), Confidence: 0.5,
+				Candidates: []IntentScore{{Intent: "EXECUTE_TRADE", Score: 0.52}, {Intent: string(models.IntentChitChat), Score: 0.5}, {Intent: string(models.IntentNewNewsQuery), Score: 0.2}}},
+			want:      models.IntentChitChat,
+			wantScore: 0.5,
+		},
+		{
+			name: "a single known candidate is no tie",
+			result: IntentClassificationResult{Intent: string(models.IntentChitChat), Confidence: 0.5,
+				Candidates: []IntentScore{{Intent: "", Score: 0.5}, {Intent: string(models.IntentChitChat), Score: 0.45}}},
+			want:      models.IntentChitChat,
+			wantScore: 0.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chosen, reason := breakIntentTie(&tt.result, 0.6, 0.1, tt.hasHistory)
+			if chosen.Intent != string(tt.want) || chosen.Score != tt.wantScore {
+				t.Errorf("chosen = %s %.2f, want %s %.2f", chosen.Intent, chosen.Score, tt.want, tt.wantScore)
+			}
+			if (reason != "") != tt.applied {
+				t.Errorf("reason = %q, tie-break applied should be %v", reason, tt.applied)
+			}
+		})
+	}
+}
+
+func TestTieBreakConfidenceDecidesTheFollowUpDowngrade(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Workflow.IntentTieThreshold = 0.6
+	cfg.Workflow.IntentTieMargin = 0.1
+	cfg.Workflow.FollowUpMinConfidence = 0.55
+	executor := newTestExecutor(t, newTestOrchestrator(t, cfg), models.WorkflowRequest{Query: "what about it"})
+	executor.workflowCtx.ConversationContext.Exchanges = []models.ConversationExchange{{Timestamp: time.Now()}}
+
+	// the reported confidence belongs to the news intent, the follow up the tie-break picks scored lower
+	intentResult := &IntentClassificationResult{
+		Intent:     string(models.IntentNewNewsQuery),
+		Confidence: 0.58,
+		Candidates: []IntentScore{
+			{Intent: "SELL_EVERYTHING", Score: 0.9},
+			{Intent: string(models.IntentFollowUpDiscussion), Score: 0.5},
+			{Intent: string(models.IntentNewNewsQuery), Score: 0.45},
+		},
+	}
+	executor.resolveAmbiguousIntent(intentResult)
+	if intentResult.Intent != string(models.IntentFollowUpDiscussion) || intentResult.Confidence != 0.5 {
+		t.Fatalf("tie-break = %s %.2f, want the follow up with its own score", intentResult.Intent, intentResult.Confidence)
+	}
+
+	executor.downgradeWeakFollowUp(intentResult)
+	if intentResult.Intent != string(models.IntentChitChat) {
+		t.Errorf("intent = %s, want the weak follow up downgraded to chitchat", intentResult.Intent)
+	}
+}
+
+func TestClassifyIntentWithContextReturnsRankedCandidates(t *testing.T) {
+	gemini, service := newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+		return `{"intent": "CHITCHAT", "confidence": 0.5, "reasoning": "casual phrasing",
+			"candidates": [{"intent": "CHITCHAT", "score": 0.5}, {"intent": "NEW_NEWS_QUERY", "score": 0.45}],
+			"entities": ["Tesla"]}`
+	})
+
+	result, err := service.ClassifyIntentWithContext(context.Background(), "what's up with Tesla?", nil)
+	if err != nil {
+		t.Fatalf("ClassifyIntentWithContext() error = %v", err)
+	}
+
+	if len(result.Candidates) != 2 || result.Candidates[1].Intent != string(models.IntentNewNewsQuery) || result.Candidates[1].Score != 0.45 {
+		t.Errorf("candidates = %+v, want chitchat then news", result.Candidates)
+	}
+	if len(result.Entities) != 1 || result.Entities[0] != "Tesla" {
+		t.Errorf("entities = %v, want [Tesla]", result.Entities)
+	}
+	if calls := gemini.received(); len(calls) != 1 || !strings.Contains(calls[0].Prompt, `"candidates"`) {
+		t.Error("classification prompt does not ask for ranked candidates")
+	}
+}
+
+func TestNearTieWithEntitiesRunsTheNewsWorkflow(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "Tesla", models.IntentChitChat)
+	workflow.answerAgent("intent classifier", func(call fakeGeminiCall) string {
+		return `{"intent": "CHITCHAT", "confidence": 0.5, "reasoning": "casual phrasing",
+			"candidates": [{"intent": "CHITCHAT", "score": 0.5}, {"intent": "NEW_NEWS_QUERY", "score": 0.45}],
+			"entities": ["Tesla"]}`
+	})
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-tie", Query: "what's up with Tesla?", Metadata: map[string]interface{}{"explain": true},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Explanation == nil || response.Explanation.Intent != string(models.IntentNewNewsQuery) {
+		t.Fatalf("explanation = %+v, want the tie broken towards a news query", response.Explanation)
+	}
+	if !strings.Contains(response.Explanation.Reasoning, "tie-break") {
+		t.Errorf("reasoning %q does not record the tie-break", response.Explanation.Reasoning)
+	}
+	if len(response.Sources) == 0 {
+		t.Error("the news workflow did not run, the response has no sources")
+	}
+}
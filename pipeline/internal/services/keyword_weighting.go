@@ -0,0 +1,143 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// keywords are searched as extracted
+	KeywordWeightingOff = "off"
+	// keywords are searched strongest first
+	KeywordWeightingOrder = "order"
+	// articles must mention one of the entity keywords and one of the rest, phrases are matched exactly
+	KeywordWeightingBoost = "boost"
+)
+
+// keywords scoring at least this are treated as entities when building a boosted query
+const entityKeywordWeight = 2.0
+
+// words that on their own say little about which story a query is after
+var genericKeywordTerms = map[string]bool{
+	"news": true, "latest": true, "update": true, "updates": true, "today": true, "report": true, "reports": true,
+	"rates": true, "rate": true, "market": true, "markets": true, "price": true, "prices": true, "policy": true,
+	"government": true, "economy": true, "business": true, "technology": true, "world": true, "people": true,
+	"issue": true, "issues": true, "change": true, "changes": true, "growth": true, "crisis": true, "deal": true,
+	"plan": true, "plans": true, "new": true, "big": true, "top": true, "recent": true, "analysis": true,
+}
+
+// WeightedKeyword is a search keyword with how much it should count towards a match
+type WeightedKeyword struct {
+	Term   string  `json:"term"`
+	Weight float64 `json:"weight"`
+}
+
+// weighKeywords scores keywords by entity-ness and specificity and orders them strongest first, ties keep
+// the extraction order. Named entities and acronyms ("Federal Reserve", "FOMC") score highest, longer
+// phrases and words beat short ones and common news words ("rates", "market") score lowest.
+func weighKeywords(keywords []string) []WeightedKeyword {
+	weighted := make([]WeightedKeyword, 0, len(keywords))
+	for _, keyword := range keywords {
+		if term := strings.TrimSpace(keyword); term != "" {
+			weighted = append(weighted, WeightedKeyword{Term: term, Weight: keywordWeight(term)})
+		}
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].Weight > weighted[j].Weight
+	})
+	return weighted
+}
+
+func keywordWeight(term string) float64 {
+	words := strings.Fields(term)
+	weight := 1.0
+
+	if isEntityKeyword(words) {
+		weight += 1.5
+	}
+	weight += 0.25 * float64(min(len(words)-1, 2))
+	if len([]rune(term)) >= 8 {
+		weight += 0.25
+	}
+
+	generic := 0
+	for _, word := range words {
+		if genericKeywordTerms[strings.ToLower(word)] {
+			generic++
+		}
+	}
+	if generic == len(words) {
+		weight -= 0.75
+	} else if generic > 0 {
+		weight -= 0.25
+	}
+
+	return weight
+}
+
+// isEntityKeyword reports whether every significant word is capitalized or an acronym, as in "Xi Jinping" or "EU AI Act"
+func isEntityKeyword(words []string) bool {
+	significant := 0
+	for _, word := range words {
+		runes := []rune(word)
+		if len(runes) <= 3 && strings.ToLower(word) == word {
+			// "of", "and", "the" inside a name
+			continue
+		}
+		significant++
+		if !unicode.IsUpper(runes[0]) && !unicode.IsDigit(runes[0]) {
+			return false
+		}
+	}
+	return significant > 0
+}
+
+// buildKeywordQuery joins the keywords into a news search query for the weighting mode
+func buildKeywordQuery(keywords []string, mode string) string {
+	switch mode {
+	case KeywordWeightingOrder:
+		return strings.Join(keywordTerms(weighKeywords(keywords)), " OR ")
+	case KeywordWeightingBoost:
+		return boostedKeywordQuery(weighKeywords(keywords))
+	default:
+		return strings.Join(keywords, " OR ")
+	}
+}
+
+// boostedKeywordQuery requires an entity keyword alongside any other keyword, so "rates" alone no longer matches
+// a Federal Reserve query. When the keywords are all entities or none are it falls back to the ordered OR query.
+func boostedKeywordQuery(weighted []WeightedKeyword) string {
+	var entities, others []string
+	for _, keyword := range weighted {
+		term := quoteKeyword(keyword.Term)
+		if keyword.Weight >= entityKeywordWeight {
+			entities = append(entities, term)
+		} else {
+			others = append(others, term)
+		}
+	}
+
+	if len(entities) == 0 || len(others) == 0 {
+		return strings.Join(append(entities, others...), " OR ")
+	}
+	return "(" + strings.Join(entities, " OR ") + ") AND (" + strings.Join(others, " OR ") + ")"
+}
+
+func keywordTerms(weighted []WeightedKeyword) []string {
+	terms := make([]string, len(weighted))
+	for i, keyword := range weighted {
+		terms[i] = keyword.Term
+	}
+	return terms
+}
+
+// quoteKeyword makes a multi word keyword an exact phrase match
+func quoteKeyword(term string) string {
+	term = strings.ReplaceAll(term, `"`, "")
+	if strings.ContainsAny(term, " \t") {
+		return `"` + term + `"`
+	}
+	return term
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestWeighKeywordsRanksEntitiesAboveGenericTerms(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		keywords []string
+		want     []string
+	}{
+		{name: "central bank query", keywords: []string{"rates", "interest rates", "Federal Reserve", "FOMC"},
+			want: []string{"Federal Reserve", "FOMC", "interest rates", "rates"}},
+		{name: "names with lowercase joiners", keywords: []string{"market", "Bank of England", "inflation"},
+			want: []string{"Bank of England", "inflation", "market"}},
+		{name: "ties keep the extraction order", keywords: []string{"drought", "harvest", "Kenya"},
+			want: []string{"Kenya", "drought", "harvest"}},
+		{name: "blank keywords are dropped", keywords: []string{" ", "Tesla", ""}, want: []string{"Tesla"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keywordTerms(weighKeywords(tt.keywords)); !slices.Equal(got, tt.want) {
+				t.Errorf("weighKeywords(%q) = %q, want %q", tt.keywords, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildKeywordQuery(t *testing.T) {
+	t.Parallel()
+	keywords := []string{"rates", "Federal Reserve", "inflation"}
+	tests := []struct {
+		mode     string
+		keywords []string
+		want     string
+	}{
+		{mode: KeywordWeightingOff, keywords: keywords, want: "rates OR Federal Reserve OR inflation"},
+		{mode: KeywordWeightingOrder, keywords: keywords, want: "Federal Reserve OR inflation OR rates"},
+		{mode: KeywordWeightingBoost, keywords: keywords, want: `("Federal Reserve") AND (inflation OR rates)`},
+		// without an entity to require the boosted query degrades to the ordered one, phrases are matched exactly
+		{mode: KeywordWeightingBoost, keywords: []string{"rates", "inflation"}, want: "inflation OR rates"},
+		{mode: KeywordWeightingBoost, keywords: []string{`the "Fed" chair`, "Jerome Powell"}, want: `("Jerome Powell") AND ("the Fed chair")`},
+	}
+
+	for _, tt := range tests {
+		if got := buildKeywordQuery(tt.keywords, tt.mode); got != tt.want {
+			t.Errorf("buildKeywordQuery(%q, %s) = %s, want %s", tt.keywords, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestSearchByKeywordsSendsTheWeightedQuery(t *testing.T) {
+	api, service := newFakeNewsAPI(t, map[string][]APIArticles{
+		"everything": {apiArticle("Fed holds rates", "https://example.com/fed")},
+	})
+	service.config.KeywordWeighting = KeywordWeightingBoost
+
+	if _, err := service.SearchByKeywords(context.Background(), []string{"rates", "Federal Reserve"}, 10); err != nil {
+		t.Fatalf("SearchByKeywords() error = %v", err)
+	}
+
+	served := api.served("everything")
+	if len(served) == 0 {
+		t.Fatal("no search reached the news api")
+	}
+	if query := served[0].Get("q"); query != `("Federal Reserve") AND (rates)` {
+		t.Errorf("q = %s, want the entity required alongside the generic term", query)
+	}
+}
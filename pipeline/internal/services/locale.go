@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SearchLocale is the user's region and timezone, carried on the context so search and summarization can localize
+type SearchLocale struct {
+	Region   string // ISO 3166-1 alpha-2, lower case
+	Timezone string // IANA name, e.g. Asia/Kolkata
+}
+
+type searchLocaleKey struct{}
+
+func WithSearchLocale(ctx context.Context, locale SearchLocale) context.Context {
+	locale.Region = strings.ToLower(locale.Region)
+	return context.WithValue(ctx, searchLocaleKey{}, locale)
+}
+
+func searchLocaleFromContext(ctx context.Context) SearchLocale {
+	locale, _ := ctx.Value(searchLocaleKey{}).(SearchLocale)
+	return locale
+}
+
+// Location resolves the timezone, falling back to UTC when unset or unknown
+func (locale SearchLocale) Location() *time.Location {
+	if locale.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(locale.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// localeInstruction is the summarizer prompt section asking for local relevance and local dates
+func localeInstruction(locale SearchLocale) string {
+	if locale.Region == "" && locale.Timezone == "" {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("\n**USER LOCALE**\n")
+	if locale.Region != "" {
+		builder.WriteString(fmt.Sprintf("- The user is located in region %q (ISO 3166-1), when the query is ambiguous (elections, markets, weather, sports) interpret it for this region first and highlight local relevance\n", strings.ToUpper(locale.Region)))
+	}
+	if locale.Timezone != "" {
+		builder.WriteString(fmt.Sprintf("- Express dates and times in the user's timezone (%s)\n", locale.Location()))
+	}
+	return builder.String()
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MediaLink groups the videos that cover the same story as an article
+type MediaLink struct {
+	ArticleID    string   `json:"article_id"`
+	ArticleTitle string   `json:"article_title"`
+	VideoIDs     []string `json:"video_ids"`
+	VideoTitles  []string `json:"video_titles"`
+	Score        float64  `json:"score"` // best article/video similarity in the group
+	// 1-based positions matching the summarizer prompt's numbering, the prompt refers to sources by these
+	// rather than repeating their untrusted titles
+	articleNumber int
+	videoNumbers  []int
+}
+
+var linkStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "that": true, "this": true,
+	"are": true, "was": true, "were": true, "has": true, "have": true, "will": true, "after": true,
+	"over": true, "into": true, "about": true, "news": true, "live": true, "latest": true, "update": true,
+	"updates": true, "video": true, "watch": true, "breaking": true, "says": true, "new": true,
+}
+
+// linkTerms returns the significant lower case words of a text, and separately the capitalized
+// words which stand in for named entities
+func linkTerms(text string) (words map[string]bool, entities map[string]bool) {
+	words = make(map[string]bool)
+	entities = make(map[string]bool)
+
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		lower := strings.ToLower(token)
+		if len([]rune(lower)) < 3 || linkStopwords[lower] {
+			continue
+		}
+		words[lower] = true
+		if unicode.IsUpper([]rune(token)[0]) {
+			entities[lower] = true
+		}
+	}
+
+	return words, entities
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for term := range a {
+		if b[term] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// storySimilarity weighs title overlap against named entity overlap across title and description
+func storySimilarity(article models.NewsArticle, video models.YouTubeVideo) float64 {
+	articleTitle, _ := linkTerms(article.Title)
+	videoTitle, _ := linkTerms(video.Title)
+	_, articleEntities := linkTerms(article.Title + " " + article.Description)
+	_, videoEntities := linkTerms(video.Title + " " + video.Description)
+
+	return 0.6*jaccard(articleTitle, videoTitle) + 0.4*jaccard(articleEntities, videoEntities)
+}
+
+// linkArticlesAndVideos attaches each video to the article it most resembles when the similarity clears
+// the threshold, videos without a match stay independent sources
+func linkArticlesAndVideos(articles []models.NewsArticle, videos []models.YouTubeVideo, threshold float64) []MediaLink {
+	groups := make(map[int]*MediaLink)
+	var order []int
+
+	for videoIndex, video := range videos {
+		bestIndex, bestScore := -1, 0.0
+		for i, article := range articles {
+			if score := storySimilarity(article, video); score > bestScore {
+				bestIndex, bestScore = i, score
+			}
+		}
+		if bestIndex < 0 || bestScore < threshold {
+			continue
+		}
+
+		group, exists := groups[bestIndex]
+		if !exists {
+			group = &MediaLink{ArticleID: articles[bestIndex].ID, ArticleTitle: articles[bestIndex].Title, articleNumber: bestIndex + 1}
+			groups[bestIndex] = group
+			order = append(order, bestIndex)
+		}
+		group.VideoIDs = append(group.VideoIDs, video.ID)
+		group.VideoTitles = append(group.VideoTitles, video.Title)
+		group.videoNumbers = append(group.videoNumbers, videoIndex+1)
+		group.Score = max(group.Score, bestScore)
+	}
+
+	links := make([]MediaLink, 0, len(order))
+	for _, index := range order {
+		links = append(links, *groups[index])
+	}
+	return links
+}
+
+// mediaLinksInstruction is the summarizer prompt section naming the article/video pairs that cover one story
+func mediaLinksInstruction(links []MediaLink) string {
+	if len(links) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("\n**CROSS-MEDIA LINKS**\n")
+	builder.WriteString("These articles and videos cover the same story. Cross-reference them as one story, do not repeat the same facts or count them as independent confirmations:\n")
+	for i, link := range links {
+		videoNumbers := make([]string, len(link.videoNumbers))
+		for j, number := range link.videoNumbers {
+			videoNumbers[j] = fmt.Sprintf("Video %d", number)
+		}
+		builder.WriteString(fmt.Sprintf("%d. Article %d <-> %s\n", i+1, link.articleNumber, strings.Join(videoNumbers, ", ")))
+	}
+	return builder.String()
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+)
+
+// NearDuplicateGroup records the article kept for a cluster of near-identical articles and the ones dropped
+type NearDuplicateGroup struct {
+	KeptURL     string   `json:"kept_url"`
+	DroppedURLs []string `json:"dropped_urls"`
+}
+
+// collapseNearDuplicates clusters articles whose embeddings are at least threshold apart in cosine similarity,
+// keeping one representative per cluster in the position of the cluster's first article. Articles without an
+// embedding are never clustered. preferred reports whether a should represent a cluster over b.
+func collapseNearDuplicates(articles []models.NewsArticle, embeddings [][]float64, threshold float64,
+	preferred func(a, b models.NewsArticle) bool) ([]models.NewsArticle, []NearDuplicateGroup) {
+	if threshold <= 0 || len(articles) < 2 {
+		return articles, nil
+	}
+
+	// union find over the similar pairs, syndicated copies chain together even when the
+	// first and last reprint drifted slightly further apart
+	parent := make([]int, len(articles))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range articles {
+		if len(embeddings[i]) == 0 {
+			continue
+		}
+		for j := i + 1; j < len(articles); j++ {
+			if len(embeddings[j]) == 0 {
+				continue
+			}
+			if cosineSimilarity(embeddings[i], embeddings[j]) >= threshold {
+				if rootI, rootJ := find(i), find(j); rootI != rootJ {
+					// the earlier article stays the root so clusters keep their first position
+					parent[max(rootI, rootJ)] = min(rootI, rootJ)
+				}
+			}
+		}
+	}
+
+	representative := make(map[int]int)
+	for i := range articles {
+		root := find(i)
+		current, ok := representative[root]
+		if !ok || preferred(articles[i], articles[current]) {
+			representative[root] = i
+		}
+	}
+
+	kept := make([]models.NewsArticle, 0, len(representative))
+	groups := make(map[int]*NearDuplicateGroup)
+	var order []int
+	for i := range articles {
+		root := find(i)
+		if i == root {
+			kept = append(kept, articles[representative[root]])
+		}
+		if representative[root] == i {
+			continue
+		}
+
+		group, ok := groups[root]
+		if !ok {
+			group = &NearDuplicateGroup{KeptURL: articles[representative[root]].URL}
+			groups[root] = group
+			order = append(order, root)
+		}
+		group.DroppedURLs = append(group.DroppedURLs, articles[i].URL)
+	}
+
+	collapsed := make([]NearDuplicateGroup, 0, len(order))
+	for _, root := range order {
+		collapsed = append(collapsed, *groups[root])
+	}
+
+	return kept, collapsed
+}
+
+// moreAuthoritative prefers trusted sources, then the article with more content, then the more relevant one
+func moreAuthoritative(trustedSources []string) func(a, b models.NewsArticle) bool {
+	return func(a, b models.NewsArticle) bool {
+		if trustedA, trustedB := isTrustedSource(a, trustedSources), isTrustedSource(b, trustedSources); trustedA != trustedB {
+			return trustedA
+		}
+		if len(a.Content) != len(b.Content) {
+			return len(a.Content) > len(b.Content)
+		}
+		return a.RelevanceScore > b.RelevanceScore
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestCollapseNearDuplicatesKeepsTheMostAuthoritativeCopy(t *testing.T) {
+	articles := []models.NewsArticle{
+		{URL: "https://wire.example.com/a", Source: "Wire", Content: "short"},
+		{URL: "https://other.example.com/b", Source: "Other"},
+		{URL: "https://trusted.example.org/a", Source: "Trusted", Content: "x"},
+		{URL: "https://reprint.example.com/a", Source: "Reprint", Content: "much longer content"},
+	}
+	syndicated := []float64{1, 0, 0}
+	embeddings := [][]float64{syndicated, {0, 1, 0}, {0.99, 0.01, 0}, syndicated}
+
+	kept, groups := collapseNearDuplicates(articles, embeddings, 0.95, moreAuthoritative([]string{"trusted.example.org"}))
+
+	wantKept := []string{"https://trusted.example.org/a", "https://other.example.com/b"}
+	var gotKept []string
+	for _, article := range kept {
+		gotKept = append(gotKept, article.URL)
+	}
+	if !reflect.DeepEqual(gotKept, wantKept) {
+		t.Errorf("kept = %v, want %v", gotKept, wantKept)
+	}
+
+	wantGroups := []NearDuplicateGroup{{
+		KeptURL:     "https://trusted.example.org/a",
+		DroppedURLs: []string{"https://wire.example.com/a", "https://reprint.example.com/a"},
+	}}
+	if !reflect.DeepEqual(groups, wantGroups) {
+		t.Errorf("groups = %+v, want %+v", groups, wantGroups)
+	}
+}
+
+func TestCollapseNearDuplicatesLeavesArticlesWithoutEmbeddings(t *testing.T) {
+	articles := []models.NewsArticle{{URL: "a"}, {URL: "b"}}
+
+	kept, groups := collapseNearDuplicates(articles, [][]float64{nil, nil}, 0.5, moreAuthoritative(nil))
+	if len(kept) != 2 || len(groups) != 0 {
+		t.Errorf("kept %d articles in %d groups, want both articles untouched", len(kept), len(groups))
+	}
+}
+
+func TestWorkflowCollapsesNearDuplicatesAfterStorage(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Workflow.NearDuplicateThreshold = 0.95
+	orchestrator := newTestOrchestrator(t, cfg)
+	_, chromaDBService := newFakeChroma(t)
+	orchestrator.chromaDBService = chromaDBService
+
+	articles := newTestCorpus("strike", 3, 0).Articles
+	articles[1].Title, articles[1].Description = articles[0].Title, articles[0].Description
+	embeddings := make([][]float64, len(articles))
+	for i, article := range articles {
+		embeddings[i] = fakeEmbedding(article.Title + " " + article.Description)
+	}
+
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "strike"})
+	executor.workflowCtx.Metadata["fresh_articles"] = articles
+	executor.workflowCtx.Metadata["fresh_article_embeddings"] = embeddings
+	if err := executor.storeFreshArticlesAndVideos(context.Background()); err != nil {
+		t.Fatalf("storeFreshArticlesAndVideos() error = %v", err)
+	}
+
+	executor.workflowCtx.Articles = articles
+	executor.collapseNearDuplicates()
+
+	if got := len(executor.workflowCtx.Articles); got != 2 {
+		t.Fatalf("articles after collapse = %d, want 2", got)
+	}
+	groups, _ := executor.workflowCtx.Metadata["near_duplicates"].([]NearDuplicateGroup)
+	if len(groups) != 1 || !reflect.DeepEqual(groups[0].DroppedURLs, []string{articles[1].URL}) {
+		t.Errorf("near_duplicates = %+v, want %s dropped", groups, articles[1].URL)
+	}
+}
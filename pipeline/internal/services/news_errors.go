@@ -0,0 +1,140 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// a bad, disabled or missing API key, retrying or rephrasing the query cannot help
+	NewsErrorPermanent = "permanent"
+	// the request itself was rejected, a differently shaped search may still work
+	NewsErrorInvalidRequest = "invalid_request"
+	// rate limit or daily quota, worth retrying after a pause
+	NewsErrorRateLimited = "rate_limited"
+	// network failures and server errors, worth retrying
+	NewsErrorTransient = "transient"
+)
+
+// NewsAPI error codes, https://newsapi.org/docs/errors
+var newsAPIErrorClasses = map[string]string{
+	"apiKeyDisabled":     NewsErrorPermanent,
+	"apiKeyInvalid":      NewsErrorPermanent,
+	"apiKeyMissing":      NewsErrorPermanent,
+	"apiKeyExhausted":    NewsErrorRateLimited,
+	"rateLimited":        NewsErrorRateLimited,
+	"parameterInvalid":   NewsErrorInvalidRequest,
+	"parametersMissing":  NewsErrorInvalidRequest,
+	"sourcesTooMany":     NewsErrorInvalidRequest,
+	"sourceDoesNotExist": NewsErrorInvalidRequest,
+	"unexpectedError":    NewsErrorTransient,
+}
+
+// classifyNewsAPIError picks the class from the NewsAPI error code, falling back to the HTTP status
+func classifyNewsAPIError(statusCode int, code string) string {
+	if class, ok := newsAPIErrorClasses[code]; ok {
+		return class
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return NewsErrorPermanent
+	case statusCode == http.StatusTooManyRequests:
+		return NewsErrorRateLimited
+	case statusCode >= 500:
+		return NewsErrorTransient
+	case statusCode >= 400:
+		return NewsErrorInvalidRequest
+	default:
+		return NewsErrorTransient
+	}
+}
+
+// newsAPIError turns a failed NewsAPI call into an AppError carrying its class under the "news_error_class" metadata
+func newsAPIError(class string, statusCode int, code, message string, retryAfter time.Duration) *models.AppError {
+	var appErr *models.AppError
+	switch class {
+	case NewsErrorPermanent:
+		appErr = models.NewUnauthorizedError("NEWSAPI_AUTH", "NewsAPI rejected the API key")
+	case NewsErrorInvalidRequest:
+		appErr = models.NewValidationError("NEWSAPI_INVALID_REQUEST", "NewsAPI rejected the request", message)
+	case NewsErrorRateLimited:
+		appErr = models.NewRateLimitError("NEWSAPI_RATE_LIMIT", "NewsAPI rate limit Exceeded", retryAfter)
+	default:
+		appErr = models.NewExternalError("NEWSAPI_ERROR", "NewsAPI request failed")
+	}
+
+	if appErr.Details == "" {
+		appErr.Details = fmt.Sprintf("%d %s %s", statusCode, code, message)
+	}
+	return appErr.WithMetadata("news_error_class", class).WithMetadata("status_code", statusCode)
+}
+
+// NewsErrorClass reports how a news search failed, errors that did not come from a NewsAPI call count as transient
+func NewsErrorClass(err error) string {
+	var appErr *models.AppError
+	if errors.As(err, &appErr) {
+		if class, ok := appErr.Metadata["news_error_class"].(string); ok {
+			return class
+		}
+	}
+	return NewsErrorTransient
+}
+
+// IsPermanentNewsError reports whether the news API cannot serve any search until someone fixes its key
+func IsPermanentNewsError(err error) bool {
+	return err != nil && NewsErrorClass(err) == NewsErrorPermanent
+}
+
+// retryAfterHeader reads a Retry-After given in seconds, NewsAPI does not send dates
+func retryAfterHeader(resp *http.Response, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// checkAuthCircuit fails fast while a rejected API key is known to be dead
+func (service *NewsService) checkAuthCircuit() error {
+	service.authMu.Lock()
+	defer service.authMu.Unlock()
+
+	if time.Now().Before(service.authFailedUntil) {
+		return newsAPIError(NewsErrorPermanent, http.StatusUnauthorized, "apiKeyInvalid",
+			fmt.Sprintf("API key rejected, not retrying until %s", service.authFailedUntil.Format(time.RFC3339)), 0)
+	}
+	return nil
+}
+
+// tripAuthCircuit stops calling NewsAPI for the cooldown and raises the alert, every search would fail the same way
+func (service *NewsService) tripAuthCircuit(err error) {
+	service.authMu.Lock()
+	service.authFailedUntil = time.Now().Add(service.config.NewsAuthCooldown)
+	service.authMu.Unlock()
+
+	service.logger.WithError(err).Error("NewsAPI rejected the API key, news search disabled until the key is fixed",
+		"alert", true,
+		"retry_at", service.authFailedUntil)
+}
+
+// waitNewsRetry sleeps before the next attempt, linear backoff for transient errors and the server's hint for rate limits
+func (service *NewsService) waitNewsRetry(ctx context.Context, attempt int, err error) error {
+	delay := service.config.NewsRetryDelay * time.Duration(attempt)
+
+	var appErr *models.AppError
+	if errors.As(err, &appErr) && appErr.RetryAfter != nil && NewsErrorClass(err) == NewsErrorRateLimited {
+		delay = *appErr.RetryAfter
+	}
+
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
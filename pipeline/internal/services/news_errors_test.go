@@ -0,0 +1,181 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassifyNewsAPIError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		status int
+		code   string
+		want   string
+	}{
+		{status: http.StatusUnauthorized, code: "apiKeyInvalid", want: NewsErrorPermanent},
+		{status: http.StatusUnauthorized, code: "apiKeyDisabled", want: NewsErrorPermanent},
+		{status: http.StatusUnauthorized, want: NewsErrorPermanent},
+		{status: http.StatusForbidden, want: NewsErrorPermanent},
+		{status: http.StatusTooManyRequests, code: "rateLimited", want: NewsErrorRateLimited},
+		{status: http.StatusTooManyRequests, want: NewsErrorRateLimited},
+		// the developer plan's daily quota is reported on a 429 under its own code
+		{status: http.StatusTooManyRequests, code: "apiKeyExhausted", want: NewsErrorRateLimited},
+		{status: http.StatusBadRequest, code: "parameterInvalid", want: NewsErrorInvalidRequest},
+		{status: http.StatusBadRequest, code: "sourceDoesNotExist", want: NewsErrorInvalidRequest},
+		{status: http.StatusUpgradeRequired, want: NewsErrorInvalidRequest},
+		{status: http.StatusInternalServerError, code: "unexpectedError", want: NewsErrorTransient},
+		{status: http.StatusBadGateway, want: NewsErrorTransient},
+		{status: http.StatusServiceUnavailable, want: NewsErrorTransient},
+		// the code wins over the status it came with
+		{status: http.StatusOK, code: "apiKeyMissing", want: NewsErrorPermanent},
+	}
+	for _, tt := range tests {
+		if got := classifyNewsAPIError(tt.status, tt.code); got != tt.want {
+			t.Errorf("classifyNewsAPIError(%d, %q) = %s, want %s", tt.status, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestNewsErrorClassOfOtherErrors(t *testing.T) {
+	t.Parallel()
+	wrapped := fmt.Errorf("News Search Failed: %w", newsAPIError(NewsErrorPermanent, http.StatusUnauthorized, "apiKeyInvalid", "", 0))
+	if class := NewsErrorClass(wrapped); class != NewsErrorPermanent || !IsPermanentNewsError(wrapped) {
+		t.Errorf("NewsErrorClass(wrapped) = %s, want the class to survive wrapping", class)
+	}
+	if class := NewsErrorClass(errors.New("connection reset")); class != NewsErrorTransient {
+		t.Errorf("NewsErrorClass(plain error) = %s, want transient", class)
+	}
+	if IsPermanentNewsError(nil) {
+		t.Error("IsPermanentNewsError(nil) = true")
+	}
+}
+
+// failingNewsAPI answers every NewsAPI request with the same error and counts the requests per endpoint
+type failingNewsAPI struct {
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func newFailingNewsAPI(t *testing.T, status int, code string, header http.Header) (*failingNewsAPI, *NewsService) {
+	t.Helper()
+	api := &failingNewsAPI{requests: make(map[string]int)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.requests[r.URL.Path[len("/v2/"):]]++
+		api.mu.Unlock()
+
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"status": "error", "code": %q, "message": "rejected by test"}`, code)
+	}))
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse fake news api url: %v", err)
+	}
+	service, err := NewNewsService(config.EtcConfig{
+		NewsApiKey: "test-key", NewsMaxRetries: 2, NewsRetryDelay: time.Millisecond,
+		NewsMaxRetryWait: 2 * time.Second, NewsAuthCooldown: time.Minute,
+	}, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewNewsService() error = %v", err)
+	}
+	service.client = &http.Client{Transport: redirectTransport{target: target}}
+	return api, service
+}
+
+func (api *failingNewsAPI) served(endpoint string) int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return api.requests[endpoint]
+}
+
+func TestNewsSearchRetriesOnlyTransientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		code      string
+		header    http.Header
+		wantClass string
+		wantCalls int
+	}{
+		{name: "bad key fails fast", status: http.StatusUnauthorized, code: "apiKeyInvalid", wantClass: NewsErrorPermanent, wantCalls: 1},
+		{name: "invalid request is not retried", status: http.StatusBadRequest, code: "parameterInvalid", wantClass: NewsErrorInvalidRequest, wantCalls: 1},
+		{name: "server errors are retried", status: http.StatusInternalServerError, code: "unexpectedError", wantClass: NewsErrorTransient, wantCalls: 3},
+		{name: "short rate limit is waited out", status: http.StatusTooManyRequests, code: "rateLimited",
+			header: http.Header{"Retry-After": {"1"}}, wantClass: NewsErrorRateLimited, wantCalls: 3},
+		// without a hint the wait defaults to an hour, far past the longest wait worth holding a workflow for
+		{name: "long rate limit is not waited out", status: http.StatusTooManyRequests, code: "rateLimited",
+			wantClass: NewsErrorRateLimited, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			api, service := newFailingNewsAPI(t, tt.status, tt.code, tt.header)
+			if tt.header != nil {
+				service.config.NewsRetryDelay = time.Hour
+			}
+
+			_, err := service.SearchByKeywords(context.Background(), []string{"elections"}, 10)
+			if class := NewsErrorClass(err); err == nil || class != tt.wantClass {
+				t.Fatalf("SearchByKeywords() error = %v, class %s, want %s", err, class, tt.wantClass)
+			}
+			if calls := api.served("everything"); calls != tt.wantCalls {
+				t.Errorf("NewsAPI received %d calls, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRejectedKeyStopsNewsCallsForTheCooldown(t *testing.T) {
+	api, service := newFailingNewsAPI(t, http.StatusUnauthorized, "apiKeyInvalid", nil)
+
+	if _, err := service.SearchByKeywords(context.Background(), []string{"elections"}, 10); !IsPermanentNewsError(err) {
+		t.Fatalf("SearchByKeywords() error = %v, want a permanent error", err)
+	}
+	if _, err := service.SearchRecentNews(context.Background(), "elections", 24, 10); !IsPermanentNewsError(err) {
+		t.Fatalf("SearchRecentNews() error = %v, want the auth circuit's permanent error", err)
+	}
+	if calls := api.served("everything"); calls != 1 {
+		t.Errorf("NewsAPI received %d calls, want none after the key was rejected", calls)
+	}
+	var appErr *models.AppError
+	if _, err := service.SearchByKeywords(context.Background(), []string{"rates"}, 10); !errors.As(err, &appErr) || appErr.Type != models.ErrorTypeUnauthorized {
+		t.Errorf("SearchByKeywords() error = %v, want an unauthorized error", err)
+	}
+}
+
+func TestDeadNewsKeySkipsTheFallbackSearches(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	api, service := newFailingNewsAPI(t, http.StatusUnauthorized, "apiKeyInvalid", nil)
+	workflow.orchestrator.newsService = service
+
+	executor := newTestExecutor(t, workflow.orchestrator, models.WorkflowRequest{UserID: "user-1", Query: "what is the latest on elections"})
+	executor.workflowCtx.Keywords = []string{"elections"}
+	executor.workflowCtx.EnhancedQuery = "latest elections news"
+
+	// the videos still answer the query, so the workflow carries on without articles
+	if err := executor.fetchStoreAndSearchArticlesAndVideos(context.Background()); err != nil {
+		t.Fatalf("fetchStoreAndSearchArticlesAndVideos() error = %v, want the videos kept", err)
+	}
+	// neither the recent news search nor a broadened search reached the API after the key was rejected
+	if calls := api.served("everything"); calls != 1 {
+		t.Errorf("NewsAPI received %d calls, want only the first search", calls)
+	}
+	if class := executor.workflowCtx.Metadata["news_error_class"]; class != NewsErrorPermanent {
+		t.Errorf("news_error_class = %v, want %s", class, NewsErrorPermanent)
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"Infiya-ai-pipeline/internal/config"
 	"Infiya-ai-pipeline/internal/models"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,6 +22,12 @@ type NewsService struct {
 	apiKey string
 	logger *logger.Logger
 	config config.EtcConfig
+	// set in evaluation mode, searches are then answered from the corpus without calling NewsAPI
+	fixtures *FixtureCorpus
+
+	// a rejected API key stops all calls until this time
+	authMu          sync.Mutex
+	authFailedUntil time.Time
 }
 
 type NewsAPIResponse struct {
@@ -57,9 +66,12 @@ type SearchRequest struct {
 	To             *time.Time
 	PageSize       int
 	Page           int
+	// everything has no country filter, so a country blends in that country's matching top headlines
+	Country string
 }
 
 type HeadlinesRequest struct {
+	Query    string
 	Country  string
 	Category string
 	Sources  []string
@@ -80,12 +92,12 @@ func NewNewsService(config config.EtcConfig, logger *logger.Logger) (*NewsServic
 
 	client := &http.Client{
 		Timeout: time.Second * 30,
-		Transport: &http.Transport{
+		Transport: tracing.Transport(&http.Transport{
 			MaxIdleConns:        10,
 			MaxIdleConnsPerHost: 5,
 			DisableCompression:  false,
 			IdleConnTimeout:     time.Second * 30,
-		},
+		}),
 	}
 
 	service := &NewsService{
@@ -95,37 +107,28 @@ func NewNewsService(config config.EtcConfig, logger *logger.Logger) (*NewsServic
 		config: config,
 	}
 
-	if err := service.TestConnection(); err != nil {
-		return nil, fmt.Errorf("NewsAPI connection test failed: %w", err)
-	}
-
 	logger.Info("NewsService successfully created and Initialized", "base_url", NewsAPIBaseURL, "rate_limit", "1000 requests/day (free tier)")
 
 	return service, nil
 }
 
-func (service *NewsService) TestConnection() error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1000)
-	defer cancel()
-
-	_, err := service.GetTopHeadlines(ctx, &HeadlinesRequest{
-		Country:  "us",
-		PageSize: 1,
-	})
-
-	if err != nil {
-		return fmt.Errorf("NewsAPI test connection failed: %w", err)
-	}
-
-	service.logger.Info("NewsAPI test connection succeeded")
-	return nil
-
-}
-
 func (service *NewsService) SearchEverything(ctx context.Context, req *SearchRequest) ([]models.NewsArticle, error) {
 	if req == nil {
 		return nil, fmt.Errorf("SearchRequest is required")
 	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "news_search", req.Query, func(ctx context.Context) ([]models.NewsArticle, error) {
+			return service.SearchEverything(ctx, req)
+		})
+	}
+
+	if service.fixtures != nil {
+		terms := fixtureTerms(req.Keywords...)
+		if len(terms) == 0 {
+			terms = fixtureTerms(req.Query)
+		}
+		return service.fixtures.searchArticles(terms, req.PageSize), nil
+	}
 
 	startTime := time.Now()
 
@@ -153,6 +156,9 @@ func (service *NewsService) SearchEverything(ctx context.Context, req *SearchReq
 	}
 
 	result := service.convertToDesiredFormat(articles)
+	if req.Country != "" {
+		result = service.blendLocalHeadlines(ctx, req, result)
+	}
 	service.logger.Info(result)
 	service.logger.LogService("news_api", "search_everything", time.Since(startTime), map[string]interface{}{
 		"query":          req.Query,
@@ -241,6 +247,21 @@ func (service *NewsService) GetTopHeadlines(ctx context.Context, request *Headli
 	if request == nil {
 		request = &HeadlinesRequest{}
 	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "top_headlines", request.Query+"|"+request.Category, func(ctx context.Context) ([]models.NewsArticle, error) {
+			return service.GetTopHeadlines(ctx, request)
+		})
+	}
+	if service.fixtures != nil {
+		if request.Query != "" {
+			return service.fixtures.searchArticles(fixtureTerms(request.Query), request.PageSize), nil
+		}
+		articles := service.fixtures.Articles
+		if request.PageSize > 0 && len(articles) > request.PageSize {
+			articles = articles[:request.PageSize]
+		}
+		return articles, nil
+	}
 	startTime := time.Now()
 
 	service.logger.LogService("news_api", "get_top_headlines", 0, map[string]interface{}{
@@ -279,11 +300,12 @@ func (service *NewsService) SearchByKeywords(ctx context.Context, keywords []str
 
 	req := &SearchRequest{
 		Keywords: keywords,
-		Query:    strings.Join(keywords, " OR "),
+		Query:    buildKeywordQuery(keywords, service.config.KeywordWeighting),
 		PageSize: maxResults,
 		Page:     1,
 		SortBy:   "relevancy",
 		Language: "en",
+		Country:  searchLocaleFromContext(ctx).Region,
 	}
 
 	return service.SearchEverything(ctx, req)
@@ -300,17 +322,55 @@ func (service *NewsService) SearchRecentNews(ctx context.Context, query string,
 	req := &SearchRequest{
 		Query:    query,
 		From:     &from,
-		PageSize: MaxPageSize,
+		PageSize: maxResults,
 		Page:     1,
 		SortBy:   "publishedAt",
 		Language: "en",
+		Country:  searchLocaleFromContext(ctx).Region,
 	}
 
 	return service.SearchEverything(ctx, req)
 
 }
 
+// blendLocalHeadlines puts the country's matching top headlines ahead of the global results, deduplicated by url
+func (service *NewsService) blendLocalHeadlines(ctx context.Context, req *SearchRequest, articles []models.NewsArticle) []models.NewsArticle {
+	query := req.Query
+	if len(req.Keywords) > 0 {
+		query = req.Keywords[0]
+	}
+
+	local, err := service.GetTopHeadlines(ctx, &HeadlinesRequest{
+		Query:    query,
+		Country:  req.Country,
+		PageSize: min(req.PageSize, 20),
+	})
+	if err != nil {
+		service.logger.WithError(err).Warn("Local headlines search failed, using global results", "country", req.Country)
+		return articles
+	}
+
+	seen := make(map[string]bool, len(local)+len(articles))
+	blended := make([]models.NewsArticle, 0, len(local)+len(articles))
+	for _, article := range append(local, articles...) {
+		if seen[article.URL] {
+			continue
+		}
+		seen[article.URL] = true
+		blended = append(blended, article)
+	}
+
+	if req.PageSize > 0 && len(blended) > req.PageSize {
+		blended = blended[:req.PageSize]
+	}
+	return blended
+}
+
 func (service *NewsService) HealthCheck(ctx context.Context) error {
+	if service.fixtures != nil {
+		return nil
+	}
+
 	testCtx, cancel := context.WithTimeout(ctx, 1000*time.Second)
 	defer cancel()
 
@@ -326,7 +386,44 @@ func (service *NewsService) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// makeAPIRequest calls NewsAPI, retrying rate limits and transient failures. A rejected key fails at once and
+// trips the auth circuit, a rate limit asking for a longer wait than NewsMaxRetryWait is not waited out.
 func (service *NewsService) makeAPIRequest(ctx context.Context, endpoint string, params url.Values) ([]APIArticles, error) {
+	if err := service.checkAuthCircuit(); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		articles, err := service.doAPIRequest(ctx, endpoint, params)
+		if err == nil {
+			return articles, nil
+		}
+
+		class := NewsErrorClass(err)
+		if class == NewsErrorPermanent {
+			service.tripAuthCircuit(err)
+			return nil, err
+		}
+		if class == NewsErrorInvalidRequest || attempt > service.config.NewsMaxRetries {
+			return nil, err
+		}
+
+		var appErr *models.AppError
+		if errors.As(err, &appErr) && appErr.RetryAfter != nil && class == NewsErrorRateLimited && *appErr.RetryAfter > service.config.NewsMaxRetryWait {
+			return nil, err
+		}
+
+		service.logger.WithError(err).Warn("NewsAPI request failed, retrying",
+			"endpoint", endpoint,
+			"attempt", attempt,
+			"error_class", class)
+		if err := service.waitNewsRetry(ctx, attempt, err); err != nil {
+			return nil, fmt.Errorf("news api retry cancelled: %w", err)
+		}
+	}
+}
+
+func (service *NewsService) doAPIRequest(ctx context.Context, endpoint string, params url.Values) ([]APIArticles, error) {
 	fullURL := fmt.Sprintf("%s/%s?%s", NewsAPIBaseURL, endpoint, params.Encode())
 
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
@@ -338,22 +435,22 @@ func (service *NewsService) makeAPIRequest(ctx context.Context, endpoint string,
 
 	resp, err := service.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("news api request execution failed: %w", err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("news api request execution failed: %w", err)
+		}
+		return nil, newsAPIError(NewsErrorTransient, 0, "", err.Error(), 0).WithCause(err)
 	}
 	defer resp.Body.Close()
 
 	var apiResponse NewsAPIResponse
-	err = json.NewDecoder(resp.Body).Decode(&apiResponse)
-	if err != nil {
-		return nil, fmt.Errorf("news api response decoding failed: %w", err)
-	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&apiResponse)
 
-	if apiResponse.Status != "ok" {
-		return nil, fmt.Errorf("news api error: %s - %s", apiResponse.Code, apiResponse.Message)
+	if resp.StatusCode != http.StatusOK || (decodeErr == nil && apiResponse.Status != "ok") {
+		class := classifyNewsAPIError(resp.StatusCode, apiResponse.Code)
+		return nil, newsAPIError(class, resp.StatusCode, apiResponse.Code, apiResponse.Message, retryAfterHeader(resp, 60*time.Minute))
 	}
-
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, models.NewRateLimitError("NEWSAPI_RATE_LIMIT", "NewsAPI rate limit Exceeded", 60*time.Minute)
+	if decodeErr != nil {
+		return nil, newsAPIError(NewsErrorTransient, resp.StatusCode, "", "response decoding failed", 0).WithCause(decodeErr)
 	}
 
 	return apiResponse.Articles, nil
@@ -415,6 +512,9 @@ func (service *NewsService) BuildHeadlinesParams(request *HeadlinesRequest) url.
 	params := url.Values{}
 	params.Set("apiKey", service.apiKey)
 
+	if request.Query != "" {
+		params.Set("q", request.Query)
+	}
 	if request.Country != "" {
 		params.Set("country", request.Country)
 	}
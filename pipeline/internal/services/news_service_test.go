@@ -0,0 +1,124 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// fakeNewsAPI answers NewsAPI endpoints with canned articles and keeps the query of every request it served
+type fakeNewsAPI struct {
+	mu       sync.Mutex
+	requests map[string][]url.Values
+	articles map[string][]APIArticles
+}
+
+// newFakeNewsAPI returns a news service whose calls to NewsAPI are served by the fake, articles are keyed by endpoint
+func newFakeNewsAPI(t *testing.T, articles map[string][]APIArticles) (*fakeNewsAPI, *NewsService) {
+	t.Helper()
+	api := &fakeNewsAPI{requests: make(map[string][]url.Values), articles: articles}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := r.URL.Path[len("/v2/"):]
+		api.mu.Lock()
+		api.requests[endpoint] = append(api.requests[endpoint], r.URL.Query())
+		articles := api.articles[endpoint]
+		api.mu.Unlock()
+
+		json.NewEncoder(w).Encode(NewsAPIResponse{Status: "ok", TotalResults: len(articles), Articles: articles})
+	}))
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse fake news api url: %v", err)
+	}
+	service, err := NewNewsService(config.EtcConfig{NewsApiKey: "test-key"}, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewNewsService() error = %v", err)
+	}
+	service.client = &http.Client{Transport: redirectTransport{target: target}}
+	return api, service
+}
+
+// publish adds articles to what the endpoint serves from the next request on
+func (api *fakeNewsAPI) publish(endpoint string, articles ...APIArticles) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.articles[endpoint] = append(api.articles[endpoint], articles...)
+}
+
+func (api *fakeNewsAPI) served(endpoint string) []url.Values {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]url.Values(nil), api.requests[endpoint]...)
+}
+
+// redirectTransport sends every request to target, keeping its path and query
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (transport redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = transport.target.Scheme, transport.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func apiArticle(title, articleURL string) APIArticles {
+	return APIArticles{Title: title, URL: articleURL, Description: title, PublishedAt: "2026-10-01T10:00:00Z", Source: APISource{Name: "Wire"}}
+}
+
+func TestSearchBlendsTheUsersRegionalHeadlines(t *testing.T) {
+	api, service := newFakeNewsAPI(t, map[string][]APIArticles{
+		"everything": {
+			apiArticle("US election results", "https://example.com/us"),
+			apiArticle("Election turnout across states", "https://example.com/shared"),
+		},
+		"top-headlines": {
+			apiArticle("Lok Sabha election results", "https://example.in/results"),
+			apiArticle("Election turnout across states", "https://example.com/shared"),
+		},
+	})
+	ctx := WithSearchLocale(context.Background(), SearchLocale{Region: "IN"})
+
+	articles, err := service.SearchByKeywords(ctx, []string{"election results"}, 10)
+	if err != nil {
+		t.Fatalf("SearchByKeywords() error = %v", err)
+	}
+
+	headlines := api.served("top-headlines")
+	if len(headlines) != 1 || headlines[0].Get("country") != "in" || headlines[0].Get("q") != "election results" {
+		t.Fatalf("top headlines requests = %v, want one for country in matching the keywords", headlines)
+	}
+	var urls []string
+	for _, article := range articles {
+		urls = append(urls, article.URL)
+	}
+	want := []string{"https://example.in/results", "https://example.com/shared", "https://example.com/us"}
+	if len(urls) != len(want) {
+		t.Fatalf("article urls = %v, want %v", urls, want)
+	}
+	for i := range want {
+		if urls[i] != want[i] {
+			t.Fatalf("article urls = %v, want the regional headlines first and no duplicates %v", urls, want)
+		}
+	}
+}
+
+func TestSearchWithoutRegionStaysGlobal(t *testing.T) {
+	api, service := newFakeNewsAPI(t, map[string][]APIArticles{
+		"everything": {apiArticle("US election results", "https://example.com/us")},
+	})
+
+	if _, err := service.SearchByKeywords(context.Background(), []string{"election results"}, 10); err != nil {
+		t.Fatalf("SearchByKeywords() error = %v", err)
+	}
+	if headlines := api.served("top-headlines"); len(headlines) != 0 {
+		t.Errorf("top headlines requests = %v, want none without a region", headlines)
+	}
+}
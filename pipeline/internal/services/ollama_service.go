@@ -4,6 +4,7 @@ import (
 	"Infiya-ai-pipeline/internal/config"
 	"Infiya-ai-pipeline/internal/models"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -65,12 +66,12 @@ func NewOllamaService(config config.OllamaConfig, log *logger.Logger) (*OllamaSe
 
 	client := &http.Client{
 		Timeout: config.Timeout,
-		Transport: &http.Transport{
+		Transport: tracing.Transport(&http.Transport{
 			MaxIdleConns:        10,
 			IdleConnTimeout:     30 * time.Second,
 			DisableCompression:  false,
 			MaxIdleConnsPerHost: 5,
-		},
+		}),
 	}
 
 	service := &OllamaService{
@@ -336,6 +337,7 @@ func (service *OllamaService) makeEmbeddingRequest(ctx context.Context, request
 	if len(response.Embedding) == 0 {
 		return nil, fmt.Errorf("empty embedding response")
 	}
+	recordEmbedding(ctx, request.Model)
 
 	return response.Embedding, nil
 }
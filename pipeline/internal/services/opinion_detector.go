@@ -0,0 +1,107 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	OpinionPolicyKeep       = "keep"
+	OpinionPolicyDownweight = "downweight"
+	OpinionPolicyExclude    = "exclude"
+)
+
+// opinionPathSegments are URL path sections publishers file editorials and columns under
+var opinionPathSegments = map[string]bool{
+	"opinion": true, "opinions": true, "editorial": true, "editorials": true, "op-ed": true, "oped": true,
+	"commentary": true, "column": true, "columns": true, "columnists": true, "blog": true, "blogs": true,
+	"perspective": true, "perspectives": true, "letters": true, "voices": true, "comment": true, "comment-is-free": true,
+}
+
+// labels publishers put in front of or behind opinion headlines
+var (
+	opinionTitlePrefixes = []string{"opinion:", "op-ed:", "editorial:", "column:", "commentary:", "letters:", "view:"}
+	opinionTitleSuffixes = []string{"| opinion", "- opinion", "| editorial", "| column"}
+)
+
+// IsValidOpinionPolicy reports whether policy is one of the supported opinion policies
+func IsValidOpinionPolicy(policy string) bool {
+	return policy == OpinionPolicyKeep || policy == OpinionPolicyDownweight || policy == OpinionPolicyExclude
+}
+
+// DetectArticleType tags editorials, columns and blog posts as opinion from the URL section, the
+// category and headline labels, everything else is news
+func DetectArticleType(article models.NewsArticle) string {
+	if parsed, err := url.Parse(article.URL); err == nil {
+		for _, segment := range strings.Split(strings.ToLower(parsed.Path), "/") {
+			if opinionPathSegments[segment] {
+				return models.ArticleTypeOpinion
+			}
+		}
+		if strings.HasPrefix(strings.ToLower(parsed.Hostname()), "blog.") {
+			return models.ArticleTypeOpinion
+		}
+	}
+
+	if opinionPathSegments[strings.ToLower(strings.TrimSpace(article.Category))] {
+		return models.ArticleTypeOpinion
+	}
+
+	title := strings.ToLower(strings.TrimSpace(article.Title))
+	for _, prefix := range opinionTitlePrefixes {
+		if strings.HasPrefix(title, prefix) {
+			return models.ArticleTypeOpinion
+		}
+	}
+	for _, suffix := range opinionTitleSuffixes {
+		if strings.HasSuffix(title, suffix) {
+			return models.ArticleTypeOpinion
+		}
+	}
+
+	return models.ArticleTypeNews
+}
+
+// applyOpinionPolicy tags every article and then drops or down-weights the opinion ones. Down-weighted
+// articles sort after news so the summarizer's article cap cuts them first. Exclusion keeps the opinion
+// articles when nothing else is left, an opinion piece beats an empty answer.
+func applyOpinionPolicy(articles []models.NewsArticle, policy string, weight float64) ([]models.NewsArticle, int) {
+	opinions := 0
+	for i := range articles {
+		articles[i].ArticleType = DetectArticleType(articles[i])
+		if articles[i].ArticleType == models.ArticleTypeOpinion {
+			opinions++
+		}
+	}
+
+	if opinions == 0 {
+		return articles, 0
+	}
+
+	switch policy {
+	case OpinionPolicyExclude:
+		if opinions == len(articles) {
+			return articles, opinions
+		}
+		news := make([]models.NewsArticle, 0, len(articles)-opinions)
+		for _, article := range articles {
+			if article.ArticleType != models.ArticleTypeOpinion {
+				news = append(news, article)
+			}
+		}
+		return news, opinions
+	case OpinionPolicyDownweight:
+		for i := range articles {
+			if articles[i].ArticleType == models.ArticleTypeOpinion {
+				articles[i].RelevanceScore *= weight
+			}
+		}
+		sort.SliceStable(articles, func(i, j int) bool {
+			return articles[i].ArticleType != models.ArticleTypeOpinion && articles[j].ArticleType == models.ArticleTypeOpinion
+		})
+	}
+
+	return articles, opinions
+}
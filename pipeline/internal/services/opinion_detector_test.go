@@ -0,0 +1,116 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDetectArticleType(t *testing.T) {
+	tests := []struct {
+		name    string
+		article models.NewsArticle
+		want    string
+	}{
+		{name: "opinion section", article: models.NewsArticle{URL: "https://example.com/opinion/2026/10/rates-are-too-high"}, want: models.ArticleTypeOpinion},
+		{name: "editorial section", article: models.NewsArticle{URL: "https://example.com/Editorials/budget"}, want: models.ArticleTypeOpinion},
+		{name: "blog host", article: models.NewsArticle{URL: "https://blog.example.com/markets"}, want: models.ArticleTypeOpinion},
+		{name: "category", article: models.NewsArticle{URL: "https://example.com/a/1", Category: "Commentary"}, want: models.ArticleTypeOpinion},
+		{name: "headline label", article: models.NewsArticle{URL: "https://example.com/a/2", Title: "Opinion: The budget misses the point"}, want: models.ArticleTypeOpinion},
+		{name: "headline suffix", article: models.NewsArticle{URL: "https://example.com/a/3", Title: "Why rates must fall | Opinion"}, want: models.ArticleTypeOpinion},
+		{name: "news", article: models.NewsArticle{URL: "https://example.com/business/rates-held", Title: "Central bank holds rates"}, want: models.ArticleTypeNews},
+		{name: "opinion inside a word", article: models.NewsArticle{URL: "https://example.com/news/opinion-polls-tighten", Title: "Opinion polls tighten"}, want: models.ArticleTypeNews},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectArticleType(tt.article); got != tt.want {
+				t.Errorf("DetectArticleType() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func opinionMix() []models.NewsArticle {
+	return []models.NewsArticle{
+		{Title: "Rates are too high", URL: "https://example.com/opinion/rates", RelevanceScore: 0.9},
+		{Title: "Central bank holds rates", URL: "https://example.com/business/rates", RelevanceScore: 0.6},
+		{Title: "Markets react to the decision", URL: "https://example.com/markets/react", RelevanceScore: 0.5},
+	}
+}
+
+func articleTitles(articles []models.NewsArticle) []string {
+	titles := make([]string, len(articles))
+	for i, article := range articles {
+		titles[i] = article.Title
+	}
+	return titles
+}
+
+func TestOpinionArticlesAreDownWeightedByDefault(t *testing.T) {
+	cfg := loadTestConfig(t, nil)
+	executor := newTestExecutor(t, newTestOrchestrator(t, cfg), models.WorkflowRequest{Query: "rates"})
+	executor.workflowCtx.Articles = opinionMix()
+
+	executor.applyOpinionPolicy()
+
+	articles := executor.workflowCtx.Articles
+	want := []string{"Central bank holds rates", "Markets react to the decision", "Rates are too high"}
+	if got := articleTitles(articles); !slices.Equal(got, want) {
+		t.Fatalf("article order = %v, want the opinion piece last %v", got, want)
+	}
+	opinion := articles[2]
+	if opinion.ArticleType != models.ArticleTypeOpinion || opinion.RelevanceScore != 0.9*cfg.Workflow.OpinionWeight {
+		t.Errorf("opinion article = %s with relevance %.2f, want an opinion tag and relevance scaled by %.2f", opinion.ArticleType, opinion.RelevanceScore, cfg.Workflow.OpinionWeight)
+	}
+	if articles[0].ArticleType != models.ArticleTypeNews || articles[0].RelevanceScore != 0.6 {
+		t.Errorf("news article = %s with relevance %.2f, want it untouched", articles[0].ArticleType, articles[0].RelevanceScore)
+	}
+	if executor.workflowCtx.Metadata["opinion_articles"] != 1 {
+		t.Errorf("opinion_articles metadata = %v, want 1", executor.workflowCtx.Metadata["opinion_articles"])
+	}
+
+	sources := buildResponseSources(articles, nil, time.Now(), SourceSortRelevance)
+	for _, source := range sources {
+		if source.Title == opinion.Title && source.ArticleType != models.ArticleTypeOpinion {
+			t.Errorf("response source %q has article type %q, want it labelled as opinion", source.Title, source.ArticleType)
+		}
+	}
+}
+
+func TestOpinionPolicyCanBeOverriddenPerRequest(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Workflow.OpinionPolicy, cfg.Workflow.OpinionWeight = OpinionPolicyDownweight, 0.5
+	orchestrator := newTestOrchestrator(t, cfg)
+
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{policy: OpinionPolicyExclude, want: []string{"Central bank holds rates", "Markets react to the decision"}},
+		{policy: OpinionPolicyKeep, want: []string{"Rates are too high", "Central bank holds rates", "Markets react to the decision"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "rates", Metadata: map[string]any{"opinion_policy": tt.policy}})
+			executor.workflowCtx.Articles = opinionMix()
+
+			executor.applyOpinionPolicy()
+
+			if got := articleTitles(executor.workflowCtx.Articles); !slices.Equal(got, tt.want) {
+				t.Errorf("articles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExcludingOpinionKeepsArticlesWhenNothingElseIsLeft(t *testing.T) {
+	articles := opinionMix()[:1]
+
+	kept, opinions := applyOpinionPolicy(articles, OpinionPolicyExclude, 0.5)
+
+	if len(kept) != 1 || opinions != 1 {
+		t.Errorf("applyOpinionPolicy() kept %d of %d opinion articles, want the only article kept", len(kept), opinions)
+	}
+}
@@ -4,26 +4,70 @@ import (
 	"Infiya-ai-pipeline/internal/config"
 	"Infiya-ai-pipeline/internal/models"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 	"context"
+	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Orchestrator struct {
-	redisService    *RedisService
-	geminiService   *GeminiService
-	youtubeService  *YouTubeService
-	ollamaService   *OllamaService
+	redisService   *RedisService
+	geminiService  *GeminiService
+	youtubeService *YouTubeService
+	// ollama unless a fallback provider wraps it
+	embeddings      EmbeddingProvider
 	chromaDBService *ChromaDBService
 	newsService     *NewsService
 	scraperService  *ScraperService
 	config          config.Config
 	logger          *logger.Logger
 	agentConfigs    map[string]models.AgentConfig
+	personaPolicy   *PersonaPolicy
+	sanitizer       *ContentSanitizer
+	categorizer     *ArticleCategorizer
+	costCalculator  *CostCalculator
+	// nil unless a durable result store is configured
+	resultStore     ResultStore
 	activeWorkflows sync.Map
-	startTime       time.Time
+	updateBuffers   sync.Map
+	// workflow id -> *eventListener, typed events of workflows streamed back over the execute request
+	eventListeners sync.Map
+	// workflow id -> *workflowControl, lets ops cancel and inspect in-flight workflows
+	workflowControls sync.Map
+	emptyResults     atomic.Int64
+	// quality score aggregates, the sum is kept in thousandths so it fits an atomic int
+	qualityScored   atomic.Int64
+	qualityLow      atomic.Int64
+	qualityMilliSum atomic.Int64
+	// cost aggregates, dollars are kept in millionths so they fit an atomic int
+	costMicroUSD atomic.Int64
+	inputTokens  atomic.Int64
+	outputTokens atomic.Int64
+	// false until the startup health gate passes, the readiness probe reports 503 meanwhile
+	ready atomic.Bool
+	// admission control, a slot per running workflow with a bounded number of callers waiting
+	workflowSlots     chan struct{}
+	workflowsInFlight atomic.Int64
+	workflowsQueued   atomic.Int64
+	workflowsRejected atomic.Int64
+	// set on shutdown, new workflows are rejected while in-flight ones finish
+	draining atomic.Bool
+	// closed when draining starts, wakes the workflows queued for a slot
+	drainCh   chan struct{}
+	startTime time.Time
+
+	// shared by every subscription callback, built on first use
+	callbackClientOnce sync.Once
+	callbackHTTPClient *http.Client
 }
 
 type WorkflowExecutor struct {
@@ -34,12 +78,22 @@ type WorkflowExecutor struct {
 
 // IntentClassificationResult Enhanced Intent Classification Result
 type IntentClassificationResult struct {
-	Intent               string  `json:"intent"`
-	Confidence           float64 `json:"confidence"`
-	Reasoning            string  `json:"reasoning"`
-	ReferencedTopic      string  `json:"referenced_topic"`
-	EnhancedQuery        string  `json:"enhanced_query"`
-	ReferencedExchangeID string  `json:"referenced_exchange_id"`
+	Intent               string        `json:"intent"`
+	Confidence           float64       `json:"confidence"`
+	Reasoning            string        `json:"reasoning"`
+	ReferencedTopic      string        `json:"referenced_topic"`
+	EnhancedQuery        string        `json:"enhanced_query"`
+	ReferencedExchangeID string        `json:"referenced_exchange_id"`
+	Candidates           []IntentScore `json:"candidates,omitempty"`
+	Entities             []string      `json:"entities,omitempty"`
+	// the separate news questions of a query asking about unrelated stories at once, empty otherwise
+	SubQueries []string `json:"sub_queries,omitempty"`
+}
+
+// IntentScore is one ranked alternative returned by the classifier
+type IntentScore struct {
+	Intent string  `json:"intent"`
+	Score  float64 `json:"score"`
 }
 
 var (
@@ -71,6 +125,10 @@ var (
 	}
 )
 
+// errWorkflowDeadline is the cause of the workflow's own deadline, it tells a timeout apart from the caller's
+// context ending first
+var errWorkflowDeadline = errors.New("workflow deadline exceeded")
+
 func NewOrchestrator(
 	redisService *RedisService,
 	geminiService *GeminiService,
@@ -86,16 +144,26 @@ func NewOrchestrator(
 		redisService:    redisService,
 		geminiService:   geminiService,
 		youtubeService:  youtubeService,
-		ollamaService:   ollamaService,
+		embeddings:      recordingEmbeddings{ollamaService},
 		chromaDBService: chromaDBService,
 		newsService:     newsService,
 		scraperService:  scraperService,
 		config:          config,
 		logger:          logger,
-		agentConfigs:    models.DefaultAgentConfigs(),
+		agentConfigs:    models.ApplyAgentModels(models.DefaultAgentConfigs(), config.Gemini.AgentModels),
+		personaPolicy:   NewPersonaPolicy(config.Tenants),
+		sanitizer:       NewContentSanitizer(config.Safety),
+		categorizer:     NewArticleCategorizer(config.Categories),
+		costCalculator:  NewCostCalculator(config.Pricing, config.Ollama.EmbeddingModel),
 		activeWorkflows: sync.Map{},
+		workflowSlots:   make(chan struct{}, config.Workflow.MaxConcurrency),
+		drainCh:         make(chan struct{}),
 		startTime:       time.Now(),
 	}
+	if chromaDBService != nil {
+		chromaDBService.SetEmbeddingProvider(orchestrator.embeddings)
+		chromaDBService.SetKnownTenants(config.Tenants.IsKnown)
+	}
 
 	logger.Info("Enhanced Conversational Orchestrator Initialized Successfully",
 		"agents_configured", len(orchestrator.agentConfigs),
@@ -106,18 +174,50 @@ func NewOrchestrator(
 	return orchestrator
 }
 
-func (orchestrator *Orchestrator) ExecuteWorkflow(ctx context.Context, req *models.WorkflowRequest) (*models.WorkflowResponse, error) {
+func (orchestrator *Orchestrator) executeWorkflow(ctx context.Context, req *models.WorkflowRequest) (*models.WorkflowResponse, error) {
 	startTime := time.Now()
 	requestID := models.GenerateRequestID()
 
+	release, err := orchestrator.admitWorkflow(ctx)
+	if err != nil {
+		orchestrator.logger.WithError(err).Warn("Workflow not admitted",
+			"workflow_id", req.WorkflowID,
+			"in_flight", orchestrator.workflowsInFlight.Load(),
+			"queued", orchestrator.workflowsQueued.Load())
+		return nil, err
+	}
+	defer release()
+
 	orchestrator.logger.LogWorkflow(req.WorkflowID, req.UserID, "workflow_started", 0, nil)
 
 	workflowCtx := models.NewWorkflowContext(*req, requestID)
 
+	ctx = tracing.WithRequestID(ctx, requestID)
+	ctx, span := tracing.StartSpan(ctx, "workflow.execute",
+		attribute.String("workflow_id", workflowCtx.ID),
+		attribute.String("tenant_id", workflowCtx.TenantID))
+	defer span.End()
+
+	// every agent derives its context from this one, so the whole pipeline shares a single budget
+	deadlineCtx, cancel := context.WithTimeoutCause(ctx, orchestrator.config.Workflow.Deadline, errWorkflowDeadline)
+	defer cancel()
+
+	// the control is registered first, an active workflow can always be cancelled through it
+	control := &workflowControl{cancel: cancel, intent: workflowCtx.Intent, status: workflowCtx.Status}
+	orchestrator.workflowControls.Store(workflowCtx.ID, control)
+	defer orchestrator.workflowControls.Delete(workflowCtx.ID)
+
 	orchestrator.activeWorkflows.Store(workflowCtx.ID, workflowCtx)
 	defer orchestrator.activeWorkflows.Delete(workflowCtx.ID)
 
-	if err := orchestrator.redisService.StoreWorkflowState(ctx, workflowCtx); err != nil {
+	if !orchestrator.redisService.IsAvailable(ctx) {
+		workflowCtx.Stateless = true
+		orchestrator.updateBuffers.Store(workflowCtx.ID, newUpdateRing(statelessUpdateBufferSize))
+		defer orchestrator.updateBuffers.Delete(workflowCtx.ID)
+		orchestrator.logger.Warn("Redis unavailable, running workflow in stateless mode", "workflow_id", workflowCtx.ID)
+	}
+
+	if err := orchestrator.storeWorkflowState(ctx, workflowCtx); err != nil {
 		orchestrator.logger.WithError(err).Error("Failed to store initial workflow state")
 	}
 
@@ -131,24 +231,90 @@ func (orchestrator *Orchestrator) ExecuteWorkflow(ctx context.Context, req *mode
 		logger:       orchestrator.logger,
 	}
 
-	var err error
+	usageMeter := NewUsageMeter()
+	deadlineCtx = WithUsageMeter(deadlineCtx, usageMeter)
+
+	overrides, err := ParseGenerationOverrides(req.Metadata)
+	if err != nil {
+		orchestrator.logger.WithError(err).Warn("Ignoring invalid generation overrides", "workflow_id", workflowCtx.ID)
+	} else if overrides != nil {
+		orchestrator.logger.Info("Applying generation overrides", "workflow_id", workflowCtx.ID, "overrides", overrides)
+		workflowCtx.Metadata["generation_overrides"] = overrides
+		deadlineCtx = WithGenerationOverrides(deadlineCtx, overrides)
+	}
+
+	deadlineCtx = WithCollectionTenant(deadlineCtx, workflowCtx.TenantID)
+	deadlineCtx = WithEmbeddingSpace(deadlineCtx)
+
+	preferences := workflowCtx.ConversationContext.UserPreferences
+	if preferences.Region != "" || preferences.Timezone != "" {
+		deadlineCtx = WithSearchLocale(deadlineCtx, SearchLocale{Region: preferences.Region, Timezone: preferences.Timezone})
+	}
+
+	// in translate mode the answer is written in the source language and translated once at the end
+	if orchestrator.config.Workflow.LanguageMode == LanguageModeGenerate && needsTranslation(preferences.Language) {
+		deadlineCtx = WithResponseLanguage(deadlineCtx, preferences.Language)
+	}
+
+	customInstruction, removed := SanitizeCustomInstruction(combineCustomInstructions(
+		orchestrator.config.Tenants.CustomInstructions[workflowCtx.TenantID], preferences.CustomInstructions))
+	if removed > 0 {
+		orchestrator.logger.Warn("Removed unsafe phrases from custom instructions", "workflow_id", workflowCtx.ID, "removed", removed)
+		workflowCtx.Metadata["custom_instruction_removed_phrases"] = removed
+	}
+	if customInstruction != "" {
+		workflowCtx.Metadata["custom_instruction_applied"] = true
+		deadlineCtx = WithCustomInstruction(deadlineCtx, customInstruction)
+	}
+
 	switch {
 	case workflowCtx.Status == models.WorkflowStatusPending:
-		err = executor.executeConversationalPipeline(ctx)
+		err = executor.executeConversationalPipeline(deadlineCtx)
 	default:
 		err = fmt.Errorf("invalid Workflow Status: %s", workflowCtx.Status)
 	}
 
+	orchestrator.recordUsage(workflowCtx, usageMeter)
+
 	duration := time.Since(startTime)
+	span.SetAttributes(attribute.String("intent", workflowCtx.Intent))
+	if score := workflowCtx.ProcessingStats.QualityScore; score != nil {
+		span.SetAttributes(attribute.Float64("quality_score", *score))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	// a pipeline that finished despite the deadline completed, a parent deadline that ended it first is a failure
+	if err != nil && errors.Is(context.Cause(deadlineCtx), errWorkflowDeadline) {
+		return orchestrator.handleWorkflowTimeout(ctx, workflowCtx, requestID, duration), nil
+	}
+
+	if control.cancelled.Load() {
+		workflowCtx.MarkCancelled()
+		orchestrator.trackProgress(workflowCtx)
+		orchestrator.logger.LogWorkflow(workflowCtx.ID, workflowCtx.UserID, "workflow_cancelled", duration, nil)
+
+		if err := orchestrator.publishWorkflowUpdate(ctx, workflowCtx, models.UpdateTypeWorkflowError, "Workflow cancelled"); err != nil {
+			orchestrator.logger.WithError(err).Error("Failed to publish workflow cancelled update")
+		}
+		if err := orchestrator.storeWorkflowState(ctx, workflowCtx); err != nil {
+			orchestrator.logger.WithError(err).Error("Failed to store cancelled workflow state")
+		}
+
+		return orchestrator.finalizeResponse(models.NewWorkflowResponse(workflowCtx.ID, requestID, string(models.WorkflowStatusCancelled), "Workflow cancelled"), workflowCtx), nil
+	}
+
 	if err != nil {
 		workflowCtx.MarkFailed()
+		orchestrator.trackProgress(workflowCtx)
 		orchestrator.logger.LogWorkflow(workflowCtx.ID, workflowCtx.UserID, "workflow_failed", duration, err)
 
 		if err := orchestrator.publishWorkflowUpdate(ctx, workflowCtx, models.UpdateTypeWorkflowError, fmt.Sprintf("Workflow failed: %s", err.Error())); err != nil {
 			orchestrator.logger.WithError(err).Error("Failed to publish workflow error update")
 		}
 
-		return models.NewWorkflowResponse(workflowCtx.ID, requestID, "failed", err.Error()), err
+		return orchestrator.finalizeResponse(models.NewWorkflowResponse(workflowCtx.ID, requestID, "failed", err.Error()), workflowCtx), err
 	}
 
 	// Store conversation exchange after successful completion
@@ -157,13 +323,22 @@ func (orchestrator *Orchestrator) ExecuteWorkflow(ctx context.Context, req *mode
 		// Don't fail the workflow, just log the error
 	}
 
+	executor.resolveResponseCitations()
+	executor.generateHeadline(ctx)
+
+	// memory keeps the markdown answer, only the delivered response is rendered for the client
+	workflowCtx.Response = FormatOutput(workflowCtx.Response, workflowCtx.ConversationContext.UserPreferences.OutputFormat)
+
 	workflowCtx.MarkCompleted()
+	orchestrator.trackProgress(workflowCtx)
 	orchestrator.logger.LogWorkflow(workflowCtx.ID, workflowCtx.UserID, "workflow_completed", duration, nil)
 
-	if err := orchestrator.redisService.StoreWorkflowState(ctx, workflowCtx); err != nil {
+	if err := orchestrator.storeWorkflowState(ctx, workflowCtx); err != nil {
 		orchestrator.logger.WithError(err).Error("Failed to store final workflow state")
 	}
 
+	orchestrator.publishCompletionEvents(ctx, workflowCtx, duration)
+
 	// Send workflow completion with the actual response
 	finalMessage := workflowCtx.Response
 	if finalMessage == "" {
@@ -183,21 +358,185 @@ func (orchestrator *Orchestrator) ExecuteWorkflow(ctx context.Context, req *mode
 	)
 
 	response.TotalTime = &totalTimeMs
+	response = orchestrator.finalizeResponse(response, workflowCtx)
+	orchestrator.persistResult(ctx, workflowCtx, response)
+	orchestrator.offloadSources(ctx, workflowCtx, response)
 	return response, nil
 }
 
+// SetResultStore enables keeping completed workflow results past the workflow state TTL
+func (orchestrator *Orchestrator) SetResultStore(store ResultStore) {
+	orchestrator.resultStore = store
+}
+
+// SetEmbeddingProvider replaces Ollama as the source of every query, article and video embedding
+func (orchestrator *Orchestrator) SetEmbeddingProvider(provider EmbeddingProvider) {
+	orchestrator.embeddings = recordingEmbeddings{provider}
+	if orchestrator.chromaDBService != nil {
+		orchestrator.chromaDBService.SetEmbeddingProvider(orchestrator.embeddings)
+	}
+}
+
+// persistResult copies a completed workflow into the result store, failures only cost later retrieval
+func (orchestrator *Orchestrator) persistResult(ctx context.Context, workflowCtx *models.WorkflowContext, response *models.WorkflowResponse) {
+	if orchestrator.resultStore == nil {
+		return
+	}
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := orchestrator.resultStore.PutResult(storeCtx, newWorkflowResult(workflowCtx, response)); err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to persist workflow result", "workflow_id", workflowCtx.ID)
+	}
+}
+
+// GetWorkflowResult returns the persisted result of a completed workflow, nil when none is kept
+func (orchestrator *Orchestrator) GetWorkflowResult(ctx context.Context, workflowID string) (*models.WorkflowResult, error) {
+	if orchestrator.resultStore == nil {
+		return nil, nil
+	}
+	return orchestrator.resultStore.GetResult(ctx, workflowID)
+}
+
+// handleWorkflowTimeout marks the workflow as timed out and returns whatever partial response was produced
+func (orchestrator *Orchestrator) handleWorkflowTimeout(ctx context.Context, workflowCtx *models.WorkflowContext, requestID string, duration time.Duration) *models.WorkflowResponse {
+	workflowCtx.MarkTimedOut()
+	orchestrator.trackProgress(workflowCtx)
+	orchestrator.logger.LogWorkflow(workflowCtx.ID, workflowCtx.UserID, "workflow_timeout", duration,
+		fmt.Errorf("workflow exceeded deadline of %s", orchestrator.config.Workflow.Deadline))
+
+	// the request context may be gone as well, so reporting uses a short detached context
+	reportCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := orchestrator.storeWorkflowState(reportCtx, workflowCtx); err != nil {
+		orchestrator.logger.WithError(err).Error("Failed to store timed out workflow state")
+	}
+
+	partialResponse := workflowCtx.Response
+	if partialResponse == "" {
+		partialResponse = workflowCtx.Summary
+	}
+
+	message := fmt.Sprintf("Workflow timed out after %s", orchestrator.config.Workflow.Deadline)
+	if err := orchestrator.publishWorkflowUpdate(reportCtx, workflowCtx, models.UpdateTypeWorkflowTimeout, message); err != nil {
+		orchestrator.logger.WithError(err).Error("Failed to publish workflow timeout update")
+	}
+
+	if partialResponse == "" {
+		partialResponse = message
+	}
+
+	totalTimeMs := float64(duration.Milliseconds())
+	response := models.NewWorkflowResponse(workflowCtx.ID, requestID, string(models.WorkflowStatusTimeout), partialResponse)
+	response.TotalTime = &totalTimeMs
+	return orchestrator.finalizeResponse(response, workflowCtx)
+}
+
+// storeWorkflowState persists workflow state unless the workflow is running without redis
+func (orchestrator *Orchestrator) storeWorkflowState(ctx context.Context, workflowCtx *models.WorkflowContext) error {
+	if workflowCtx.Stateless {
+		return nil
+	}
+	return orchestrator.redisService.StoreWorkflowState(ctx, workflowCtx)
+}
+
+// finalizeResponse attaches the agent execution trace, and the explanation and intermediate outputs when requested, and for stateless workflows flags the response
+// and attaches the buffered updates clients could not stream
+func (orchestrator *Orchestrator) finalizeResponse(response *models.WorkflowResponse, workflowCtx *models.WorkflowContext) *models.WorkflowResponse {
+	response.AgentExecutions = workflowCtx.AgentExecutions
+	if headline, ok := workflowCtx.Metadata["headline"].(string); ok {
+		response.Title = headline
+	}
+	response.Repeat, _ = workflowCtx.Metadata["repeat_query"].(bool)
+	response.UnavailableContent, _ = workflowCtx.Metadata["unavailable_content"].([]string)
+	response.Citations, _ = workflowCtx.Metadata["citations"].([]models.ResponseCitation)
+	response.TrendingSuggestions, _ = workflowCtx.Metadata["trending_suggestions"].([]string)
+	if workflowCtx.RequestBool("explain") {
+		response.Explanation = workflowCtx.Explain()
+	}
+	if workflowCtx.RequestBool("include_intermediate") {
+		response.Intermediate = workflowCtx.Intermediate()
+	}
+	if orchestrator.config.Pricing.ExposeCost {
+		cost := workflowCtx.ProcessingStats.EstimatedCostUSD
+		response.EstimatedCostUSD = &cost
+	}
+	if reasons, ok := workflowCtx.Metadata["uncertainty"].([]string); ok {
+		response.Uncertainty = reasons
+	}
+	sourceSort := orchestrator.config.Workflow.SourceSort
+	if workflowCtx.RequestBool("articles_only_response") {
+		// integrators render their own UI from the list, so it always comes back best match first
+		response.ResponseType = models.ResponseTypeArticlesOnly
+		sourceSort = SourceSortRelevance
+	}
+	if len(workflowCtx.Articles) > 0 || len(workflowCtx.Videos) > 0 {
+		response.Sources = buildResponseSources(workflowCtx.Articles, workflowCtx.Videos, time.Now(), sourceSort)
+		if orchestrator.selectionReasonsEnabled(workflowCtx) {
+			orchestrator.attachSelectionReasons(response.Sources, workflowCtx)
+		}
+		response.Sentiment = AggregateSentiment(workflowCtx.Articles)
+	}
+
+	if !workflowCtx.Stateless {
+		return response
+	}
+
+	response.Stateless = true
+	if buffer, ok := orchestrator.updateBuffers.Load(workflowCtx.ID); ok {
+		response.Updates = buffer.(*updateRing).snapshot()
+	}
+	return response
+}
+
+// deliverUpdate publishes to the user's redis stream, or buffers in memory when the workflow is stateless
+func (orchestrator *Orchestrator) deliverUpdate(ctx context.Context, workflowCtx *models.WorkflowContext, update *models.AgentUpdate) error {
+	if !workflowCtx.Stateless {
+		return orchestrator.redisService.PublishAgentUpdate(ctx, workflowCtx.UserID, update)
+	}
+
+	if buffer, ok := orchestrator.updateBuffers.Load(workflowCtx.ID); ok {
+		buffer.(*updateRing).add(update)
+	}
+	return nil
+}
+
 // Enhanced conversational pipeline
 func (workflowExecutor *WorkflowExecutor) executeConversationalPipeline(ctx context.Context) error {
 	// 1. Load conversation context (enhanced memory agent)
-	if err := workflowExecutor.executeEnhancedMemoryAgent(ctx); err != nil {
+	if err := workflowExecutor.traceAgent(ctx, "memory", workflowExecutor.executeEnhancedMemoryAgent); err != nil {
 		return fmt.Errorf("Enhanced Memory Agent failed: %w", err)
 	}
 
+	// callers asking for the article list only always want a news search, there is nothing to classify
+	if workflowExecutor.workflowCtx.RequestBool("articles_only_response") {
+		return workflowExecutor.executeArticlesOnlyWorkflow(ctx)
+	}
+
+	if workflowExecutor.answerRepeatedQuery(ctx) {
+		return nil
+	}
+
 	// 2. Enhanced intent classification with conversation history
-	intentResult, err := workflowExecutor.executeEnhancedIntentClassifier(ctx)
+	var intentResult *IntentClassificationResult
+	err := workflowExecutor.traceAgent(ctx, "classifier", func(ctx context.Context) error {
+		var classifyErr error
+		intentResult, classifyErr = workflowExecutor.executeEnhancedIntentClassifier(ctx)
+		return classifyErr
+	})
 	if err != nil {
 		return fmt.Errorf("Enhanced Intent Classifier failed: %w", err)
 	}
+	workflowExecutor.orchestrator.publishEvent(ctx, workflowExecutor.workflowCtx, models.EventIntentClassified, models.IntentClassifiedPayload{
+		Intent:          intentResult.Intent,
+		Confidence:      intentResult.Confidence,
+		IsFollowUp:      workflowExecutor.workflowCtx.IsFollowUp,
+		ReferencedTopic: workflowExecutor.workflowCtx.ReferencedTopic,
+	})
+
+	ctx = workflowExecutor.applyWorkflowProfile(ctx, models.Intent(intentResult.Intent))
 
 	// 3. Route based on enhanced intent classification
 	switch models.Intent(intentResult.Intent) {
@@ -210,6 +549,7 @@ func (workflowExecutor *WorkflowExecutor) executeConversationalPipeline(ctx cont
 	default:
 		// Default to chitchat for unknown intents
 		workflowExecutor.workflowCtx.SetIntent(string(models.IntentChitChat))
+		workflowExecutor.orchestrator.trackProgress(workflowExecutor.workflowCtx)
 		return workflowExecutor.executeChitChatWorkflow(ctx, &IntentClassificationResult{
 			Intent:     string(models.IntentChitChat),
 			Confidence: 0.5,
@@ -218,6 +558,25 @@ func (workflowExecutor *WorkflowExecutor) executeConversationalPipeline(ctx cont
 	}
 }
 
+// applyWorkflowProfile fills the personality from the workflow type's profile when the user left it blank
+// and hands the profile's sampling to the calls that write the response
+func (workflowExecutor *WorkflowExecutor) applyWorkflowProfile(ctx context.Context, intent models.Intent) context.Context {
+	name := workflowProfileName(intent)
+	profile, ok := workflowExecutor.orchestrator.config.Workflow.Profiles[name]
+	if !ok {
+		return ctx
+	}
+
+	preferences := &workflowExecutor.workflowCtx.ConversationContext.UserPreferences
+	if preferences.NewsPersonality == "" && profile.Personality != "" {
+		preferences.NewsPersonality = profile.Personality
+		workflowExecutor.workflowCtx.Metadata["profile_personality"] = profile.Personality
+	}
+	workflowExecutor.workflowCtx.Metadata["workflow_profile"] = name
+
+	return WithWorkflowProfile(ctx, profile)
+}
+
 // Enhanced memory agent that retrieves full conversation context
 func (workflowExecutor *WorkflowExecutor) executeEnhancedMemoryAgent(ctx context.Context) error {
 	startTime := time.Now()
@@ -226,8 +585,12 @@ func (workflowExecutor *WorkflowExecutor) executeEnhancedMemoryAgent(ctx context
 		workflowExecutor.logger.WithError(err).Error("Failed to publish memory agent update")
 	}
 
-	// Retrieve full conversation context including exchanges
-	conversationContext, err := workflowExecutor.orchestrator.redisService.GetConversationContext(ctx, workflowExecutor.workflowCtx.UserID)
+	// Retrieve full conversation context including exchanges, stateless workflows are always treated as a first turn
+	var conversationContext *models.ConversationContext
+	err := fmt.Errorf("redis unavailable, running stateless")
+	if !workflowExecutor.workflowCtx.Stateless {
+		conversationContext, err = workflowExecutor.orchestrator.redisService.GetConversationContext(ctx, workflowExecutor.workflowCtx.UserID)
+	}
 	if err != nil {
 		workflowExecutor.logger.WithError(err).Error("Failed to get conversation context, initializing new context")
 
@@ -249,9 +612,23 @@ func (workflowExecutor *WorkflowExecutor) executeEnhancedMemoryAgent(ctx context
 		}
 	}
 
+	// a user coming back after a long gap starts a new session, so "tell me more" can't pick up yesterday's topic
+	now := time.Now()
+	sessionReset := conversationContext.IsSessionIdle(now, workflowExecutor.orchestrator.config.Workflow.SessionIdleTimeout)
+	if sessionReset {
+		workflowExecutor.logger.Info("Conversation idle past session timeout, starting new session",
+			"workflow_id", workflowExecutor.workflowCtx.ID,
+			"user_id", workflowExecutor.workflowCtx.UserID,
+			"last_active", conversationContext.LastActiveTime,
+			"previous_session_start", conversationContext.SessionStartTime)
+		workflowExecutor.workflowCtx.Metadata["session_reset"] = true
+		workflowExecutor.workflowCtx.Metadata["previous_session_start"] = conversationContext.SessionStartTime
+		conversationContext.StartNewSession(now)
+	}
+
 	// Update user preferences and activity time
 	conversationContext.UserPreferences = workflowExecutor.workflowCtx.ConversationContext.UserPreferences
-	conversationContext.LastActiveTime = time.Now()
+	conversationContext.LastActiveTime = now
 	workflowExecutor.workflowCtx.ConversationContext = *conversationContext
 
 	duration := time.Since(startTime)
@@ -262,6 +639,9 @@ func (workflowExecutor *WorkflowExecutor) executeEnhancedMemoryAgent(ctx context
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("memory", duration,
+		map[string]any{"stateless": workflowExecutor.workflowCtx.Stateless},
+		map[string]any{"exchanges": len(conversationContext.Exchanges), "topics": len(conversationContext.CurrentTopics), "session_reset": sessionReset}, nil)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "memory", models.AgentStatusCompleted,
 		fmt.Sprintf("Loaded context: %d exchanges, %d topics",
@@ -311,11 +691,17 @@ func (workflowExecutor *WorkflowExecutor) executeEnhancedIntentClassifier(ctx co
 			Confidence: confidence,
 			Reasoning:  "Fallback classification used",
 		}
+		workflowExecutor.workflowCtx.RecordFallback("intent_classification")
 	}
 
+	workflowExecutor.resolveAmbiguousIntent(intentResult)
+	workflowExecutor.downgradeWeakFollowUp(intentResult)
+
 	// Update workflow context
 	workflowExecutor.workflowCtx.SetIntent(intentResult.Intent)
+	workflowExecutor.orchestrator.trackProgress(workflowExecutor.workflowCtx)
 	workflowExecutor.workflowCtx.IntentConfidence = intentResult.Confidence
+	workflowExecutor.workflowCtx.IntentReasoning = intentResult.Reasoning
 
 	// Handle follow-up marking
 	if intentResult.Intent == string(models.IntentFollowUpDiscussion) {
@@ -324,7 +710,11 @@ func (workflowExecutor *WorkflowExecutor) executeEnhancedIntentClassifier(ctx co
 
 	// If enhanced query provided, update context
 	if intentResult.EnhancedQuery != "" {
-		workflowExecutor.workflowCtx.SetEnhancedQuery(intentResult.EnhancedQuery)
+		if workflowExecutor.acceptEnhancedQuery(intentResult.EnhancedQuery, "classifier") {
+			workflowExecutor.workflowCtx.SetEnhancedQuery(intentResult.EnhancedQuery)
+		} else {
+			intentResult.EnhancedQuery = ""
+		}
 	}
 
 	duration := time.Since(startTime)
@@ -336,6 +726,9 @@ func (workflowExecutor *WorkflowExecutor) executeEnhancedIntentClassifier(ctx co
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("classifier", duration,
+		map[string]any{"query_length": len(workflowExecutor.workflowCtx.OriginalQuery), "history_exchanges": len(recentExchanges)},
+		map[string]any{"intent": intentResult.Intent, "confidence": intentResult.Confidence, "candidates": len(intentResult.Candidates)}, nil)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "classifier", models.AgentStatusCompleted,
 		fmt.Sprintf("Intent: %s (confidence: %.2f) - %s",
@@ -346,10 +739,128 @@ func (workflowExecutor *WorkflowExecutor) executeEnhancedIntentClassifier(ctx co
 	return intentResult, nil
 }
 
+// resolveAmbiguousIntent re-evaluates low confidence classifications when the runner up intent is close
+func (workflowExecutor *WorkflowExecutor) resolveAmbiguousIntent(intentResult *IntentClassificationResult) {
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+	hasHistory := workflowExecutor.workflowCtx.ConversationContext.HasPreviousExchanges()
+
+	chosen, reason := breakIntentTie(intentResult, workflowConfig.IntentTieThreshold, workflowConfig.IntentTieMargin, hasHistory)
+	if reason == "" {
+		return
+	}
+
+	workflowExecutor.logger.Info("Intent tie-break applied",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"original_intent", intentResult.Intent,
+		"resolved_intent", chosen.Intent,
+		"confidence", intentResult.Confidence,
+		"resolved_confidence", chosen.Score,
+		"candidates", intentResult.Candidates,
+		"reason", reason)
+
+	// the follow up downgrade reads the confidence, it has to be the resolved intent's own score
+	intentResult.Confidence = chosen.Score
+	if chosen.Intent != intentResult.Intent {
+		intentResult.Intent = chosen.Intent
+		intentResult.Reasoning = fmt.Sprintf("%s (tie-break: %s)", intentResult.Reasoning, reason)
+	}
+}
+
+// downgradeWeakFollowUp re-routes follow ups the classifier was unsure about, so a new thread does not drag in old topics
+func (workflowExecutor *WorkflowExecutor) downgradeWeakFollowUp(intentResult *IntentClassificationResult) {
+	minConfidence := workflowExecutor.orchestrator.config.Workflow.FollowUpMinConfidence
+
+	intent, reason := rerouteWeakFollowUp(intentResult, minConfidence)
+	if reason == "" {
+		return
+	}
+
+	workflowExecutor.logger.Info("Low confidence follow-up downgraded",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"resolved_intent", intent,
+		"confidence", intentResult.Confidence,
+		"min_confidence", minConfidence,
+		"referenced_topic", intentResult.ReferencedTopic,
+		"entities", intentResult.Entities)
+
+	intentResult.Intent = intent
+	intentResult.ReferencedTopic = ""
+	intentResult.ReferencedExchangeID = ""
+	intentResult.Reasoning = fmt.Sprintf("%s (follow-up downgrade: %s)", intentResult.Reasoning, reason)
+}
+
+func rerouteWeakFollowUp(intentResult *IntentClassificationResult, minConfidence float64) (string, string) {
+	if intentResult.Intent != string(models.IntentFollowUpDiscussion) || intentResult.Confidence >= minConfidence {
+		return intentResult.Intent, ""
+	}
+
+	if len(intentResult.Entities) > 0 {
+		return string(models.IntentNewNewsQuery), fmt.Sprintf("confidence %.2f below %.2f, named entities %v suggest a new news query", intentResult.Confidence, minConfidence, intentResult.Entities)
+	}
+	return string(models.IntentChitChat), fmt.Sprintf("confidence %.2f below %.2f and no named entities, treating as chitchat", intentResult.Confidence, minConfidence)
+}
+
+// breakIntentTie returns the candidate to use and the reason for the decision, reason is empty when no tie-break
+// applies and the classification is returned as it is. Candidates come from the model, unknown intents are dropped.
+func breakIntentTie(intentResult *IntentClassificationResult, threshold, margin float64, hasHistory bool) (IntentScore, string) {
+	unchanged := IntentScore{Intent: intentResult.Intent, Score: intentResult.Confidence}
+	if intentResult.Confidence >= threshold {
+		return unchanged, ""
+	}
+
+	candidates := slices.DeleteFunc(slices.Clone(intentResult.Candidates), func(candidate IntentScore) bool {
+		return !isKnownIntent(candidate.Intent)
+	})
+	if len(candidates) < 2 {
+		return unchanged, ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	top, second := candidates[0], candidates[1]
+	if top.Score-second.Score > margin {
+		return unchanged, ""
+	}
+
+	pick := func(intent models.Intent) (IntentScore, bool) {
+		switch string(intent) {
+		case top.Intent:
+			return top, true
+		case second.Intent:
+			return second, true
+		}
+		return IntentScore{}, false
+	}
+
+	if news, ok := pick(models.IntentNewNewsQuery); ok && len(intentResult.Entities) > 0 {
+		return news, fmt.Sprintf("near tie with named entities %v, preferring news", intentResult.Entities)
+	}
+	if followUp, ok := pick(models.IntentFollowUpDiscussion); ok && !hasHistory {
+		other := top
+		if followUp == top {
+			other = second
+		}
+		return other, "near tie but no conversation history to follow up on"
+	}
+	return top, "near tie, keeping highest scored candidate"
+}
+
+// isKnownIntent reports whether the intent is one the orchestrator routes
+func isKnownIntent(intent string) bool {
+	switch models.Intent(intent) {
+	case models.IntentNewNewsQuery, models.IntentFollowUpDiscussion, models.IntentChitChat:
+		return true
+	}
+	return false
+}
+
 func (workflowExecutor *WorkflowExecutor) executeFollowUpDiscussionWorkflow(ctx context.Context, intentResult *IntentClassificationResult) error {
 	workflowExecutor.logger.LogWorkflow(workflowExecutor.workflowCtx.ID, workflowExecutor.workflowCtx.UserID, "follow_up_workflow_started", 0, nil)
 
-	if err := workflowExecutor.generateContextualResponse(ctx, intentResult); err != nil {
+	if err := workflowExecutor.traceAgent(ctx, "chitchat", func(ctx context.Context) error {
+		return workflowExecutor.generateContextualResponse(ctx, intentResult)
+	}); err != nil {
 		return fmt.Errorf("Failed to generate contextual response: %w", err)
 	}
 
@@ -360,9 +871,10 @@ func (workflowExecutor *WorkflowExecutor) executeFollowUpDiscussionWorkflow(ctx
 func (workflowExecutor *WorkflowExecutor) executeChitChatWorkflow(ctx context.Context, intentResult *IntentClassificationResult) error {
 	workflowExecutor.logger.LogWorkflow(workflowExecutor.workflowCtx.ID, workflowExecutor.workflowCtx.UserID, "chitchat_workflow_started", 0, nil)
 
-	if err := workflowExecutor.generateChitChatResponse(ctx); err != nil {
+	if err := workflowExecutor.traceAgent(ctx, "chitchat", workflowExecutor.generateChitChatResponse); err != nil {
 		return fmt.Errorf("Failed to generate chitchat response: %w", err)
 	}
+	workflowExecutor.attachTrendingSuggestions(ctx)
 
 	return nil
 }
@@ -370,36 +882,231 @@ func (workflowExecutor *WorkflowExecutor) executeChitChatWorkflow(ctx context.Co
 func (workflowExecutor *WorkflowExecutor) executeNewsWorkflow(ctx context.Context, intentResult *IntentClassificationResult) error {
 	workflowExecutor.logger.LogWorkflow(workflowExecutor.workflowCtx.ID, workflowExecutor.workflowCtx.UserID, "news_workflow_started", 0, nil)
 
+	if subQueries := workflowExecutor.compoundSubQueries(intentResult); subQueries != nil {
+		if err := workflowExecutor.traceAgent(ctx, "compound_query", func(ctx context.Context) error {
+			return workflowExecutor.executeCompoundNews(ctx, subQueries)
+		}); err != nil {
+			return fmt.Errorf("compound query failed: %w", err)
+		}
+		workflowExecutor.publishArticlesFound(ctx)
+		return workflowExecutor.finishNewsResponse(ctx)
+	}
+
 	if err := workflowExecutor.executeSequentialQueryProcessing(ctx, intentResult); err != nil {
 		workflowExecutor.logger.WithError(err).Error("Failed to execute sequential query processing")
 		return err
 	}
+	workflowExecutor.orchestrator.publishEvent(ctx, workflowExecutor.workflowCtx, models.EventKeywordsExtracted, models.KeywordsExtractedPayload{
+		Keywords:      workflowExecutor.workflowCtx.Keywords,
+		EnhancedQuery: workflowExecutor.workflowCtx.EnhancedQuery,
+	})
 
 	if err := workflowExecutor.fetchStoreAndSearchArticlesAndVideos(ctx); err != nil {
 		workflowExecutor.logger.WithError(err).Error("Failed to fetch store and search articles")
 		return err
 	}
+	workflowExecutor.publishArticlesFound(ctx)
+
+	// with parallel scraping the relevancy agent already scraped its articles while videos were rated
+	if scraped, _ := workflowExecutor.workflowCtx.Metadata["scraped_with_relevancy"].(bool); !scraped {
+		if err := workflowExecutor.traceAgent(ctx, "scraper", workflowExecutor.enhanceArticlesWithFullContent); err != nil {
+			workflowExecutor.logger.WithError(err).Error("Getting full article content failed, proceeding without it")
+		}
+	}
+
+	if workflowExecutor.orchestrator.config.Workflow.QuoteExtraction {
+		workflowExecutor.extractArticleQuotes()
+	}
 
-	if err := workflowExecutor.enhanceArticlesWithFullContent(ctx); err != nil {
-		workflowExecutor.logger.WithError(err).Error("Getting full article content failed, proceeding without it")
+	if workflowExecutor.workflowCtx.ConversationContext.UserPreferences.IncludeSentiment {
+		if err := workflowExecutor.traceAgent(ctx, "sentiment", workflowExecutor.analyzeSentiment); err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Sentiment analysis failed, proceeding without it")
+		}
 	}
 
-	if err := workflowExecutor.generateSummary(ctx); err != nil {
+	if workflowExecutor.orchestrator.config.Workflow.CondenseArticles {
+		if err := workflowExecutor.traceAgent(ctx, "condenser", workflowExecutor.condenseLongArticles); err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Article condensation failed, summarizing full articles")
+		}
+	}
+
+	if err := workflowExecutor.traceAgent(ctx, "summarizer", workflowExecutor.generateSummary); err != nil {
 		return fmt.Errorf("summary generation failed: %w", err)
 	}
+	return workflowExecutor.finishNewsResponse(ctx)
+}
+
+// finishNewsResponse turns the summary into the response, scoring it and applying the persona and translation
+func (workflowExecutor *WorkflowExecutor) finishNewsResponse(ctx context.Context) error {
+	workflowExecutor.scoreQuality()
+
+	// machine readable summaries go out untouched, a persona rewrite would break the JSON
+	if models.SummaryFormat(workflowExecutor.workflowCtx.ConversationContext.UserPreferences.SummaryFormat) == models.SummaryFormatJSON {
+		workflowExecutor.workflowCtx.Response = workflowExecutor.workflowCtx.Summary
+		return nil
+	}
 
-	if err := workflowExecutor.ApplyPersonality(ctx); err != nil {
+	// the neutral summary is the answer, no tone, emoji or editorializing and one gemini call fewer
+	if workflowExecutor.skipPersona() {
+		workflowExecutor.workflowCtx.Response = workflowExecutor.workflowCtx.Summary
+		workflowExecutor.workflowCtx.Metadata["persona_skipped"] = true
+	} else if err := workflowExecutor.traceAgent(ctx, "persona", workflowExecutor.ApplyPersonality); err != nil {
 		workflowExecutor.logger.WithError(err).Warn("personality application failed, using base summary: %w", err)
 		workflowExecutor.workflowCtx.Response = workflowExecutor.workflowCtx.Summary
 	}
+	workflowExecutor.annotateUncertainty()
+	workflowExecutor.noteUnavailableContent()
+
+	if workflowExecutor.shouldTranslateResponse() {
+		if err := workflowExecutor.traceAgent(ctx, "translator", workflowExecutor.translateResponse); err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Response translation failed, returning untranslated response")
+			workflowExecutor.workflowCtx.RecordFallback("translation")
+		}
+	}
+
+	return nil
+}
+
+// shouldTranslateResponse is true in translate mode when the user reads a language other than the sources'.
+// The no results notice is already localized and is left alone.
+func (workflowExecutor *WorkflowExecutor) shouldTranslateResponse() bool {
+	if workflowExecutor.orchestrator.config.Workflow.LanguageMode != LanguageModeTranslate {
+		return false
+	}
+	if emptyResult, _ := workflowExecutor.workflowCtx.Metadata["empty_result"].(bool); emptyResult {
+		return false
+	}
+	return needsTranslation(workflowExecutor.workflowCtx.ConversationContext.UserPreferences.Language)
+}
+
+// translateResponse replaces the final response with its translation into the user's language
+func (workflowExecutor *WorkflowExecutor) translateResponse(ctx context.Context) error {
+	startTime := time.Now()
+	language := workflowExecutor.workflowCtx.ConversationContext.UserPreferences.Language
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "translator", models.AgentStatusProcessing,
+		fmt.Sprintf("Translating response into %s", languageName(language))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish translator update")
+	}
+
+	original := workflowExecutor.workflowCtx.Response
+	translated, err := workflowExecutor.orchestrator.geminiService.TranslateResponse(ctx, original, language)
+	if err != nil {
+		workflowExecutor.recordAgentExecution("translator", time.Since(startTime), nil, nil, err)
+		return fmt.Errorf("response translation failed: %w", err)
+	}
+
+	workflowExecutor.workflowCtx.Response = translated
+	workflowExecutor.workflowCtx.Metadata["translated_to"] = language
+	workflowExecutor.workflowCtx.ProcessingStats.APICallsCount++
+
+	duration := time.Since(startTime)
+	workflowExecutor.workflowCtx.UpdateAgentStats("translator", models.AgentStats{
+		Name:      "translator",
+		Duration:  duration,
+		Status:    string(models.AgentStatusCompleted),
+		StartTime: startTime,
+		EndTime:   time.Now(),
+	})
+	workflowExecutor.recordAgentExecution("translator", duration,
+		map[string]any{"language": language, "response_length": len(original)},
+		map[string]any{"translated_length": len(translated)}, nil)
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "translator", models.AgentStatusCompleted,
+		fmt.Sprintf("Translated response into %s", languageName(language))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish translator completion")
+	}
+
+	return nil
+}
+
+// executeArticlesOnlyWorkflow runs the news workflow up to the relevancy agent and returns the ranked sources,
+// skipping the scraper, summarizer and persona
+func (workflowExecutor *WorkflowExecutor) executeArticlesOnlyWorkflow(ctx context.Context) error {
+	workflowExecutor.logger.LogWorkflow(workflowExecutor.workflowCtx.ID, workflowExecutor.workflowCtx.UserID, "articles_only_workflow_started", 0, nil)
+
+	intentResult := &IntentClassificationResult{
+		Intent:     string(models.IntentNewNewsQuery),
+		Confidence: 1.0,
+		Reasoning:  "Articles only response requested",
+	}
+	workflowExecutor.workflowCtx.SetIntent(intentResult.Intent)
+	workflowExecutor.orchestrator.trackProgress(workflowExecutor.workflowCtx)
+	workflowExecutor.workflowCtx.IntentConfidence = intentResult.Confidence
+	workflowExecutor.workflowCtx.IntentReasoning = intentResult.Reasoning
+
+	if err := workflowExecutor.executeSequentialQueryProcessing(ctx, intentResult); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to execute sequential query processing")
+		return err
+	}
+	workflowExecutor.orchestrator.publishEvent(ctx, workflowExecutor.workflowCtx, models.EventKeywordsExtracted, models.KeywordsExtractedPayload{
+		Keywords:      workflowExecutor.workflowCtx.Keywords,
+		EnhancedQuery: workflowExecutor.workflowCtx.EnhancedQuery,
+	})
+
+	if err := workflowExecutor.fetchStoreAndSearchArticlesAndVideos(ctx); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to fetch store and search articles")
+		return err
+	}
+	workflowExecutor.publishArticlesFound(ctx)
+
+	workflowExecutor.workflowCtx.Response = fmt.Sprintf("Found %d relevant articles and %d relevant videos",
+		len(workflowExecutor.workflowCtx.Articles), len(workflowExecutor.workflowCtx.Videos))
 
 	return nil
 }
 
+// extractArticleQuotes attaches the attributed direct quotes found in each article's scraped content
+func (workflowExecutor *WorkflowExecutor) extractArticleQuotes() {
+	limit := workflowExecutor.orchestrator.config.Workflow.MaxQuotesPerArticle
+	total := 0
+
+	for i := range workflowExecutor.workflowCtx.Articles {
+		article := &workflowExecutor.workflowCtx.Articles[i]
+		article.Quotes = ExtractQuotes(article.Content, limit)
+		total += len(article.Quotes)
+	}
+
+	workflowExecutor.workflowCtx.Metadata["quotes_extracted"] = total
+	workflowExecutor.logger.Debug("Extracted article quotes",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"articles", len(workflowExecutor.workflowCtx.Articles),
+		"quotes", total)
+}
+
+// scoreQuality rates the answer from the signals the news workflow left behind and feeds the aggregate metrics
+func (workflowExecutor *WorkflowExecutor) scoreQuality() {
+	qualityConfig := workflowExecutor.orchestrator.config.Quality
+	signals := collectQualitySignals(workflowExecutor.workflowCtx, qualityConfig)
+	score := ComputeQualityScore(signals, qualityConfig)
+
+	workflowExecutor.workflowCtx.ProcessingStats.QualityScore = &score
+	workflowExecutor.workflowCtx.Metadata["quality_signals"] = signals
+
+	workflowExecutor.orchestrator.qualityScored.Add(1)
+	workflowExecutor.orchestrator.qualityMilliSum.Add(int64(score * 1000))
+	if score < qualityConfig.LowThreshold {
+		workflowExecutor.orchestrator.qualityLow.Add(1)
+		workflowExecutor.logger.Warn("Low quality workflow",
+			"workflow_id", workflowExecutor.workflowCtx.ID,
+			"quality_score", score,
+			"fallbacks", workflowExecutor.workflowCtx.ProcessingStats.Fallbacks,
+			"signals", signals)
+	}
+}
+
 // Store conversation exchange after workflow completion
 func (workflowExecutor *WorkflowExecutor) storeConversationExchange(ctx context.Context) error {
+	// a repeated query was answered from the previous exchange, storing it again would only duplicate it
+	if repeat, _ := workflowExecutor.workflowCtx.Metadata["repeat_query"].(bool); repeat {
+		return nil
+	}
+
 	// Extract key topics and entities from the conversation
 	// For now, use simple extraction - could be enhanced with AI later
+	if workflowExecutor.orchestrator.config.Workflow.TopicDrift {
+		workflowExecutor.trackTopicDrift(ctx)
+	}
 	keyTopics := workflowExecutor.workflowCtx.ConversationContext.CurrentTopics
 	keyEntities := []string{} // TODO: Extract entities from query/response
 	keywords := workflowExecutor.workflowCtx.Keywords
@@ -412,8 +1119,16 @@ func (workflowExecutor *WorkflowExecutor) storeConversationExchange(ctx context.
 		keyTopics,
 		keyEntities,
 		keywords,
+		models.ExchangeRetention{
+			MaxExchanges: workflowExecutor.orchestrator.config.Workflow.MaxStoredExchanges,
+			FoldEvicted:  workflowExecutor.orchestrator.config.Workflow.FoldEvictedExchanges,
+		},
 	)
 
+	if workflowExecutor.workflowCtx.Stateless {
+		return nil
+	}
+
 	// Store updated conversation context
 	return workflowExecutor.orchestrator.redisService.UpdateConversationContext(
 		ctx,
@@ -422,20 +1137,7 @@ func (workflowExecutor *WorkflowExecutor) storeConversationExchange(ctx context.
 }
 
 // Updated progress calculation for new workflow types
-func calculateAgentProgress(workflowType string, agentName string, status models.AgentStatus) float64 {
-	var agents []string
-
-	switch workflowType {
-	case string(models.IntentNewNewsQuery):
-		agents = newsWorkflowAgents
-	case string(models.IntentFollowUpDiscussion):
-		agents = followUpWorkflowAgents
-	case string(models.IntentChitChat):
-		agents = chitchatWorkflowAgents
-	default:
-		return 0.0
-	}
-
+func calculateAgentProgress(agents []string, agentName string, status models.AgentStatus) float64 {
 	agentIndex := -1
 	for i, agent := range agents {
 		if agent == agentName {
@@ -463,8 +1165,33 @@ func calculateAgentProgress(workflowType string, agentName string, status models
 	}
 }
 
+// traceAgent runs one agent step inside its own span so per agent latency shows up in the trace
+func (workflowExecutor *WorkflowExecutor) traceAgent(ctx context.Context, agentName string, run func(ctx context.Context) error) error {
+	ctx, span := tracing.StartSpan(ctx, "agent."+agentName,
+		attribute.String("agent", agentName),
+		attribute.String("workflow_id", workflowExecutor.workflowCtx.ID))
+	err := run(ctx)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// recordAgentExecution appends an execution record for the trace, inputs and outputs must hold
+// counts and other non-sensitive values only
+func (workflowExecutor *WorkflowExecutor) recordAgentExecution(agentName string, duration time.Duration, input, output map[string]any, err error) {
+	workflowExecutor.workflowCtx.AddAgentExecution(agentName, duration, "success", input, output, err)
+}
+
 func (workflowExecutor *WorkflowExecutor) publishAgentUpdate(ctx context.Context, agentName string, status models.AgentStatus, message string) error {
-	progress := calculateAgentProgress(workflowExecutor.workflowCtx.Intent, agentName, status)
+	if control, ok := workflowExecutor.orchestrator.workflowControls.Load(workflowExecutor.workflowCtx.ID); ok {
+		control.(*workflowControl).setCurrentAgent(agentName)
+	}
+
+	if !publishesAgentStatus(workflowExecutor.updateVerbosity(), status) {
+		return nil
+	}
+
+	agentSequence := workflowExecutor.agentSequence()
+	progress := calculateAgentProgress(agentSequence, agentName, status)
 
 	update := &models.AgentUpdate{
 		WorkflowID: workflowExecutor.workflowCtx.ID,
@@ -479,14 +1206,14 @@ func (workflowExecutor *WorkflowExecutor) publishAgentUpdate(ctx context.Context
 	}
 
 	update.Data["workflow_type"] = workflowExecutor.workflowCtx.Intent
-	update.Data["agent_sequence"] = getAgentSequence(workflowExecutor.workflowCtx.Intent)
-	update.Data["total_agents"] = getTotalAgents(workflowExecutor.workflowCtx.Intent)
+	update.Data["agent_sequence"] = agentSequence
+	update.Data["total_agents"] = len(agentSequence)
 	update.Data["is_follow_up"] = workflowExecutor.workflowCtx.IsFollowUp
 	if workflowExecutor.workflowCtx.ReferencedTopic != "" {
 		update.Data["referenced_topic"] = workflowExecutor.workflowCtx.ReferencedTopic
 	}
 
-	return workflowExecutor.orchestrator.redisService.PublishAgentUpdate(ctx, workflowExecutor.workflowCtx.UserID, update)
+	return workflowExecutor.orchestrator.deliverUpdate(ctx, workflowExecutor.workflowCtx, update)
 }
 
 func getAgentSequence(workflowType string) []string {
@@ -502,8 +1229,32 @@ func getAgentSequence(workflowType string) []string {
 	}
 }
 
-func getTotalAgents(workflowType string) int {
-	return len(getAgentSequence(workflowType))
+// rateArticleRelevancy runs the relevancy agent once, or over overlapping windows in ensemble mode
+func (workflowExecutor *WorkflowExecutor) rateArticleRelevancy(ctx context.Context, articles []models.NewsArticle, contextMap map[string]interface{}) ([]models.NewsArticle, error) {
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+	if workflowExecutor.relevancyMode() != RelevancyModeEnsemble {
+		return workflowExecutor.orchestrator.geminiService.GetRelevantArticles(ctx, articles, contextMap)
+	}
+
+	return workflowExecutor.orchestrator.geminiService.GetRelevantArticlesEnsemble(ctx, articles, contextMap, RelevancyEnsemble{
+		WindowSize: workflowConfig.RelevancyWindowSize,
+		Overlap:    workflowConfig.RelevancyWindowOverlap,
+		Combine:    workflowConfig.RelevancyCombine,
+	})
+}
+
+// agentSequence is the workflow's agent sequence without the agents this request skips,
+// so progress still reaches 1 on the last agent that actually runs
+func (workflowExecutor *WorkflowExecutor) agentSequence() []string {
+	sequence := getAgentSequence(workflowExecutor.workflowCtx.Intent)
+	if !workflowExecutor.skipPersona() {
+		return sequence
+	}
+	return slices.DeleteFunc(slices.Clone(sequence), func(agent string) bool { return agent == "persona" })
+}
+
+func (workflowExecutor *WorkflowExecutor) skipPersona() bool {
+	return workflowExecutor.workflowCtx.ConversationContext.UserPreferences.NewsPersonality == models.NoPersona
 }
 
 func (orchestrator *Orchestrator) publishWorkflowUpdate(ctx context.Context, workflowCtx *models.WorkflowContext, updateType models.UpdateType, message string) error {
@@ -517,7 +1268,7 @@ func (orchestrator *Orchestrator) publishWorkflowUpdate(ctx context.Context, wor
 		Timestamp:  time.Now(),
 	}
 
-	return orchestrator.redisService.PublishAgentUpdate(ctx, workflowCtx.UserID, update)
+	return orchestrator.deliverUpdate(ctx, workflowCtx, update)
 }
 
 func (workflowExecutor *WorkflowExecutor) executeMainPipeline(ctx context.Context) error {
@@ -532,7 +1283,24 @@ func (workflowExecutor *WorkflowExecutor) executeSequentialQueryProcessing(ctx c
 		queryToProcess = intentResult.EnhancedQuery
 	}
 
-	if err := workflowExecutor.enhanceQueryWithContext(ctx, queryToProcess); err != nil {
+	if workflowExecutor.orchestrator.config.Workflow.CombinedQueryProcessing {
+		err := workflowExecutor.traceAgent(ctx, "query_processor", func(ctx context.Context) error {
+			return workflowExecutor.enhanceAndExtractKeywords(ctx, queryToProcess)
+		})
+		if err == nil {
+			workflowExecutor.blendRecentKeywords(intentResult)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("query processing failed: %w", err)
+		}
+		workflowExecutor.logger.WithError(err).Warn("Combined query processing failed, falling back to separate calls",
+			"workflow_id", workflowExecutor.workflowCtx.ID)
+	}
+
+	if err := workflowExecutor.traceAgent(ctx, "query_enhancer", func(ctx context.Context) error {
+		return workflowExecutor.enhanceQueryWithContext(ctx, queryToProcess)
+	}); err != nil {
 		workflowExecutor.logger.WithError(err).Error("Query enhancement failed, using original query")
 		// Continue with original query
 	}
@@ -542,13 +1310,59 @@ func (workflowExecutor *WorkflowExecutor) executeSequentialQueryProcessing(ctx c
 		enhancedQuery = queryToProcess
 	}
 
-	if err := workflowExecutor.extractKeywordsFromEnhancedQuery(ctx, enhancedQuery); err != nil {
+	if err := workflowExecutor.traceAgent(ctx, "keyword_extractor", func(ctx context.Context) error {
+		return workflowExecutor.extractKeywordsFromEnhancedQuery(ctx, enhancedQuery)
+	}); err != nil {
 		return fmt.Errorf("keyword extraction failed: %w", err)
 	}
 
+	workflowExecutor.blendRecentKeywords(intentResult)
+
 	return nil
 }
 
+// strictSourcesOnly resolves the user's preference against the configured default
+func (workflowExecutor *WorkflowExecutor) strictSourcesOnly() bool {
+	if strict := workflowExecutor.workflowCtx.ConversationContext.UserPreferences.StrictSourcesOnly; strict != nil {
+		return *strict
+	}
+	return workflowExecutor.orchestrator.config.Workflow.StrictSourcesOnly
+}
+
+// blendRecentKeywords keeps the subject of a continued topic in the search set ("more on that"),
+// queries that do not reference an earlier exchange start from a clean keyword set
+func (workflowExecutor *WorkflowExecutor) blendRecentKeywords(intentResult *IntentClassificationResult) {
+	recent := recentKeywordsToBlend(intentResult, workflowExecutor.workflowCtx.ConversationContext.RecentKeywords,
+		workflowExecutor.orchestrator.config.Workflow.RecentKeywordBlend)
+	if len(recent) == 0 {
+		return
+	}
+
+	added := workflowExecutor.workflowCtx.AddKeywords(recent)
+	workflowExecutor.logger.Debug("Blended recent keywords into search",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"referenced_topic", intentResult.ReferencedTopic,
+		"blended", added)
+}
+
+// recentKeywordsToBlend picks up to limit of the most recent conversation keywords, only for queries tied to a prior topic
+func recentKeywordsToBlend(intentResult *IntentClassificationResult, recentKeywords []string, limit int) []string {
+	if limit <= 0 || intentResult == nil {
+		return nil
+	}
+	if intentResult.ReferencedTopic == "" && intentResult.ReferencedExchangeID == "" {
+		return nil
+	}
+
+	blend := make([]string, 0, limit)
+	for i := len(recentKeywords) - 1; i >= 0 && len(blend) < limit; i-- {
+		if keyword := strings.TrimSpace(recentKeywords[i]); keyword != "" {
+			blend = append(blend, keyword)
+		}
+	}
+	return blend
+}
+
 func (workflowExecutor *WorkflowExecutor) generateContextualResponse(ctx context.Context, intentResult *IntentClassificationResult) error {
 	startTime := time.Now()
 
@@ -575,6 +1389,7 @@ func (workflowExecutor *WorkflowExecutor) generateContextualResponse(ctx context
 		contextMap,
 	)
 	if err != nil {
+		workflowExecutor.recordAgentExecution("chitchat", time.Since(startTime), nil, nil, err)
 		return fmt.Errorf("contextual response generation failed: %w", err)
 	}
 
@@ -589,6 +1404,9 @@ func (workflowExecutor *WorkflowExecutor) generateContextualResponse(ctx context
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("chitchat", duration,
+		map[string]any{"relevant_exchanges": len(relevantExchanges), "follow_up": true},
+		map[string]any{"response_length": len(response)}, nil)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "chitchat", models.AgentStatusCompleted,
 		fmt.Sprintf("Generated contextual response (%d chars) referencing: %s", len(response), intentResult.ReferencedTopic)); err != nil {
@@ -615,10 +1433,11 @@ func (workflowExecutor *WorkflowExecutor) enhanceQueryWithContext(ctx context.Co
 
 	enhancement, err := workflowExecutor.orchestrator.geminiService.EnhanceQueryForSearch(ctx, queryToProcess, contextMap)
 	if err != nil {
+		workflowExecutor.recordAgentExecution("query_enhancer", time.Since(startTime), nil, nil, err)
 		return fmt.Errorf("query enhancement failed: %w", err)
 	}
 
-	if enhancement.EnhancedQuery != "" {
+	if workflowExecutor.acceptEnhancedQuery(enhancement.EnhancedQuery, "query_enhancer") {
 		workflowExecutor.workflowCtx.SetEnhancedQuery(enhancement.EnhancedQuery)
 		workflowExecutor.workflowCtx.Metadata["original_query"] = workflowExecutor.workflowCtx.OriginalQuery
 		workflowExecutor.workflowCtx.Metadata["enhanced_query"] = enhancement.EnhancedQuery
@@ -634,6 +1453,9 @@ func (workflowExecutor *WorkflowExecutor) enhanceQueryWithContext(ctx context.Co
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("query_enhancer", duration,
+		map[string]any{"query_length": len(queryToProcess)},
+		map[string]any{"enhanced_query_length": len(workflowExecutor.workflowCtx.EnhancedQuery)}, nil)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "query_enhancer", models.AgentStatusCompleted,
 		fmt.Sprintf("Enhanced Query: %s", enhancement.EnhancedQuery)); err != nil {
@@ -643,6 +1465,61 @@ func (workflowExecutor *WorkflowExecutor) enhanceQueryWithContext(ctx context.Co
 	return nil
 }
 
+// enhanceAndExtractKeywords does query enhancement and keyword extraction in one gemini call, reporting
+// progress under both agent names so clients tracking the news workflow agents see the usual steps
+func (workflowExecutor *WorkflowExecutor) enhanceAndExtractKeywords(ctx context.Context, queryToProcess string) error {
+	startTime := time.Now()
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "query_enhancer", models.AgentStatusProcessing, "Enhancing Query and Extracting Keywords"); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish query enhancer update")
+	}
+
+	contextMap := map[string]interface{}{
+		"intent":               workflowExecutor.workflowCtx.Intent,
+		"conversation_context": workflowExecutor.workflowCtx.ConversationContext,
+		"user_preferences":     workflowExecutor.workflowCtx.ConversationContext.UserPreferences,
+	}
+
+	result, err := workflowExecutor.orchestrator.geminiService.EnhanceAndExtractKeywords(ctx, queryToProcess, contextMap)
+	if err != nil {
+		workflowExecutor.recordAgentExecution("query_processor", time.Since(startTime), nil, nil, err)
+		return fmt.Errorf("combined query processing failed: %w", err)
+	}
+
+	if workflowExecutor.acceptEnhancedQuery(result.EnhancedQuery, "query_processor") {
+		workflowExecutor.workflowCtx.SetEnhancedQuery(result.EnhancedQuery)
+		workflowExecutor.workflowCtx.Metadata["original_query"] = workflowExecutor.workflowCtx.OriginalQuery
+		workflowExecutor.workflowCtx.Metadata["enhanced_query"] = result.EnhancedQuery
+	}
+	workflowExecutor.workflowCtx.AddKeywords(result.Keywords)
+	workflowExecutor.workflowCtx.ProcessingStats.APICallsCount++
+
+	duration := time.Since(startTime)
+	for _, agentName := range []string{"query_enhancer", "keyword_extractor"} {
+		workflowExecutor.workflowCtx.UpdateAgentStats(agentName, models.AgentStats{
+			Name:      agentName,
+			Duration:  duration,
+			Status:    string(models.AgentStatusCompleted),
+			StartTime: startTime,
+			EndTime:   time.Now(),
+		})
+	}
+	workflowExecutor.recordAgentExecution("query_processor", duration,
+		map[string]any{"query_length": len(queryToProcess)},
+		map[string]any{"enhanced_query_length": len(result.EnhancedQuery), "keywords": len(result.Keywords)}, nil)
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "query_enhancer", models.AgentStatusCompleted,
+		fmt.Sprintf("Enhanced Query: %s", result.EnhancedQuery)); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish query enhancer completion update")
+	}
+	if err := workflowExecutor.publishAgentUpdate(ctx, "keyword_extractor", models.AgentStatusCompleted,
+		fmt.Sprintf("Extracted %d keywords from enhanced query", len(result.Keywords))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish keyword extractor completion update")
+	}
+
+	return nil
+}
+
 // Enhanced keyword extraction using enhanced query
 func (workflowExecutor *WorkflowExecutor) extractKeywordsFromEnhancedQuery(ctx context.Context, queryToProcess string) error {
 	startTime := time.Now()
@@ -661,6 +1538,7 @@ func (workflowExecutor *WorkflowExecutor) extractKeywordsFromEnhancedQuery(ctx c
 
 	keywords, err := workflowExecutor.orchestrator.geminiService.ExtractKeyWords(ctx, queryToProcess, contextMap)
 	if err != nil {
+		workflowExecutor.recordAgentExecution("keyword_extractor", time.Since(startTime), nil, nil, err)
 		return fmt.Errorf("keyword extraction failed: %w", err)
 	}
 
@@ -675,6 +1553,9 @@ func (workflowExecutor *WorkflowExecutor) extractKeywordsFromEnhancedQuery(ctx c
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("keyword_extractor", duration,
+		map[string]any{"query_length": len(queryToProcess)},
+		map[string]any{"keywords": len(keywords)}, nil)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "keyword_extractor", models.AgentStatusCompleted,
 		fmt.Sprintf("Extracted %d keywords from enhanced query", len(keywords))); err != nil {
@@ -692,13 +1573,27 @@ func (workflowExecutor *WorkflowExecutor) ApplyPersonality(ctx context.Context)
 		workflowExecutor.logger.WithError(err).Error("Failed to publish persona update")
 	}
 
-	personality := workflowExecutor.workflowCtx.ConversationContext.UserPreferences.NewsPersonality
+	requestedPersonality := workflowExecutor.workflowCtx.ConversationContext.UserPreferences.NewsPersonality
+	personality, remapped := workflowExecutor.orchestrator.personaPolicy.ResolvePersona(workflowExecutor.workflowCtx.TenantID, requestedPersonality)
+	if remapped {
+		workflowExecutor.logger.Info("Persona not allowed for tenant, using tenant default",
+			"workflow_id", workflowExecutor.workflowCtx.ID,
+			"tenant_id", workflowExecutor.workflowCtx.TenantID,
+			"requested_persona", requestedPersonality,
+			"persona", personality)
+	}
 
 	// Use original query for persona application
 	originalQuery := workflowExecutor.workflowCtx.OriginalQuery
 
-	personalizedResponse, err := workflowExecutor.orchestrator.geminiService.AddPersonalityToResponse(ctx, originalQuery, workflowExecutor.workflowCtx.Summary, personality)
+	personalize := workflowExecutor.orchestrator.geminiService.AddPersonalityToResponse
+	if emptyResult, _ := workflowExecutor.workflowCtx.Metadata["empty_result"].(bool); emptyResult {
+		personalize = workflowExecutor.orchestrator.geminiService.AddPersonalityToEmptyResult
+	}
+
+	personalizedResponse, err := personalize(ctx, originalQuery, workflowExecutor.workflowCtx.Summary, personality)
 	if err != nil {
+		workflowExecutor.recordAgentExecution("persona", time.Since(startTime), nil, nil, err)
 		return fmt.Errorf("personality application failed: %w", err)
 	}
 
@@ -713,6 +1608,9 @@ func (workflowExecutor *WorkflowExecutor) ApplyPersonality(ctx context.Context)
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("persona", duration,
+		map[string]any{"persona": personality, "summary_length": len(workflowExecutor.workflowCtx.Summary)},
+		map[string]any{"response_length": len(personalizedResponse)}, nil)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "persona", models.AgentStatusCompleted,
 		fmt.Sprintf("Applied %s personality", personality)); err != nil {
@@ -722,7 +1620,151 @@ func (workflowExecutor *WorkflowExecutor) ApplyPersonality(ctx context.Context)
 	return nil
 }
 
-// Updated: Generate summary with enhanced context
+// collapseNearDuplicates keeps one article per syndicated story, matched on the embeddings computed for the
+// fresh articles. Articles that came from the vector store have no embedding at hand and are left alone.
+func (workflowExecutor *WorkflowExecutor) collapseNearDuplicates() {
+	threshold := workflowExecutor.orchestrator.config.Workflow.NearDuplicateThreshold
+	articles := workflowExecutor.workflowCtx.Articles
+	if threshold <= 0 || len(articles) < 2 {
+		return
+	}
+
+	freshArticles, _ := workflowExecutor.workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle)
+	freshEmbeddings, _ := workflowExecutor.workflowCtx.Metadata["fresh_article_embeddings"].([][]float64)
+	if len(freshArticles) != len(freshEmbeddings) {
+		return
+	}
+
+	embeddingsByURL := make(map[string][]float64, len(freshArticles))
+	for i, article := range freshArticles {
+		embeddingsByURL[article.URL] = freshEmbeddings[i]
+	}
+
+	embeddings := make([][]float64, len(articles))
+	for i, article := range articles {
+		embeddings[i] = embeddingsByURL[article.URL]
+	}
+
+	kept, groups := collapseNearDuplicates(articles, embeddings, threshold,
+		moreAuthoritative(workflowExecutor.orchestrator.config.Quality.TrustedSources))
+	if len(groups) == 0 {
+		return
+	}
+
+	workflowExecutor.workflowCtx.Articles = kept
+	workflowExecutor.workflowCtx.Metadata["near_duplicates"] = groups
+	workflowExecutor.logger.Debug("Collapsed near duplicate articles",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"clusters", len(groups),
+		"removed", len(articles)-len(kept))
+}
+
+// applyOpinionPolicy tags opinion articles and excludes or down-weights them, the request's "opinion_policy" overrides the config
+func (workflowExecutor *WorkflowExecutor) applyOpinionPolicy() {
+	policy := workflowExecutor.orchestrator.config.Workflow.OpinionPolicy
+	if override, ok := workflowExecutor.workflowCtx.RequestString("opinion_policy"); ok {
+		policy = override
+	}
+
+	before := len(workflowExecutor.workflowCtx.Articles)
+	articles, opinions := applyOpinionPolicy(workflowExecutor.workflowCtx.Articles, policy, workflowExecutor.orchestrator.config.Workflow.OpinionWeight)
+	workflowExecutor.workflowCtx.Articles = articles
+
+	if opinions == 0 {
+		return
+	}
+	workflowExecutor.workflowCtx.Metadata["opinion_articles"] = opinions
+	workflowExecutor.logger.Debug("Applied opinion policy",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"policy", policy,
+		"opinion_articles", opinions,
+		"removed", before-len(articles))
+}
+
+// downWeightSuspiciousArticles halves the relevance of articles carrying injection phrases and moves them last,
+// so the summarizer's per prompt article cap drops them first
+func (workflowExecutor *WorkflowExecutor) downWeightSuspiciousArticles() {
+	articles := workflowExecutor.workflowCtx.Articles
+	var suspiciousIDs []string
+
+	for i := range articles {
+		_, hits := workflowExecutor.orchestrator.sanitizer.Sanitize(articles[i].Title + "\n" + articles[i].Description + "\n" + articles[i].Content)
+		if workflowExecutor.orchestrator.sanitizer.IsSuspicious(hits) {
+			articles[i].RelevanceScore = articles[i].RelevanceScore / 2
+			suspiciousIDs = append(suspiciousIDs, articles[i].ID)
+			workflowExecutor.logger.Warn("Possible prompt injection in article content",
+				"workflow_id", workflowExecutor.workflowCtx.ID,
+				"article_id", articles[i].ID,
+				"url", articles[i].URL,
+				"hits", hits)
+		}
+	}
+
+	if len(suspiciousIDs) == 0 {
+		return
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		return !slices.Contains(suspiciousIDs, articles[i].ID) && slices.Contains(suspiciousIDs, articles[j].ID)
+	})
+	workflowExecutor.workflowCtx.Metadata["suspicious_articles"] = suspiciousIDs
+}
+
+// analyzeSentiment rates the tone of the most relevant articles in one gemini call
+func (workflowExecutor *WorkflowExecutor) analyzeSentiment(ctx context.Context) error {
+	startTime := time.Now()
+
+	articles := workflowExecutor.workflowCtx.Articles
+	if limit := workflowExecutor.orchestrator.config.Workflow.SentimentMaxArticles; len(articles) > limit {
+		articles = articles[:limit]
+	}
+	if len(articles) == 0 {
+		return nil
+	}
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "sentiment", models.AgentStatusProcessing, "Analyzing Article Sentiment"); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish sentiment update")
+	}
+
+	sentiments, err := workflowExecutor.orchestrator.geminiService.AnalyzeSentiment(ctx, workflowExecutor.workflowCtx.OriginalQuery, articles)
+	if err != nil {
+		workflowExecutor.recordAgentExecution("sentiment", time.Since(startTime), nil, nil, err)
+		return err
+	}
+	workflowExecutor.workflowCtx.ProcessingStats.APICallsCount++
+
+	rated := 0
+	for i, sentiment := range sentiments {
+		if sentiment != nil {
+			articles[i].Sentiment = sentiment
+			rated++
+		}
+	}
+
+	if summary := AggregateSentiment(workflowExecutor.workflowCtx.Articles); summary != nil {
+		workflowExecutor.workflowCtx.Metadata["sentiment"] = summary
+	}
+
+	duration := time.Since(startTime)
+	workflowExecutor.workflowCtx.UpdateAgentStats("sentiment", models.AgentStats{
+		Name:      "sentiment",
+		Duration:  duration,
+		Status:    string(models.AgentStatusCompleted),
+		StartTime: startTime,
+		EndTime:   time.Now(),
+	})
+	workflowExecutor.recordAgentExecution("sentiment", duration,
+		map[string]any{"articles": len(articles)},
+		map[string]any{"rated": rated}, nil)
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "sentiment", models.AgentStatusCompleted,
+		fmt.Sprintf("Rated sentiment of %d articles", rated)); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish sentiment completion update")
+	}
+
+	return nil
+}
+
 func (workflowExecutor *WorkflowExecutor) generateSummary(ctx context.Context) error {
 	startTime := time.Now()
 
@@ -730,25 +1772,45 @@ func (workflowExecutor *WorkflowExecutor) generateSummary(ctx context.Context) e
 		workflowExecutor.logger.WithError(err).Error("Failed to publish summarizer update")
 	}
 
-	// Prepare articles content
+	workflowExecutor.collapseNearDuplicates()
+	workflowExecutor.applyOpinionPolicy()
+	workflowExecutor.downWeightSuspiciousArticles()
+
+	location := searchLocaleFromContext(ctx).Location()
+
+	// Prepare articles content, everything the publisher supplied is untrusted and gets fenced
 	articlesContents := make([]string, len(workflowExecutor.workflowCtx.Articles))
 	for i, article := range workflowExecutor.workflowCtx.Articles {
-		content := fmt.Sprintf("**ARTICLE**\nTitle: %s\nSource: %s\nDescription: %s", article.Title, article.Source, article.Description)
+		sourceText := fmt.Sprintf("Title: %s\nSource: %s\nDescription: %s", article.Title, article.Source, article.Description)
 		if article.Content != "" {
-			content += fmt.Sprintf("\nContent: %s", article.Content)
+			sourceText += fmt.Sprintf("\nContent:\n%s", article.Content)
+		}
+		fencedSource, _ := workflowExecutor.orchestrator.sanitizer.WrapUntrusted(sourceText)
+		content := "**ARTICLE**\n" + fencedSource
+		if article.ArticleType == models.ArticleTypeOpinion {
+			content += "\nType: Opinion/Editorial (the author's view, not straight reporting)"
+		}
+		if article.Sentiment != nil {
+			content += fmt.Sprintf("\nSentiment: %s (score %.2f, magnitude %.2f)", article.Sentiment.Label, article.Sentiment.Score, article.Sentiment.Magnitude)
+		}
+		if len(article.Quotes) > 0 {
+			fencedQuotes, _ := workflowExecutor.orchestrator.sanitizer.WrapUntrusted(quotesPromptSection(article.Quotes))
+			content += "\n" + fencedQuotes
 		}
 		if !article.PublishedAt.IsZero() {
-			content += fmt.Sprintf("\nPublished: %s", article.PublishedAt.Format("2006-01-02"))
+			content += fmt.Sprintf("\nPublished: %s", article.PublishedAt.In(location).Format("2006-01-02 15:04 MST"))
 		}
 		articlesContents[i] = content
 	}
 
-	// Prepare videos content
+	// Prepare videos content, the uploader's text is fenced like an article's
 	videosContents := make([]string, len(workflowExecutor.workflowCtx.Videos))
 	for i, video := range workflowExecutor.workflowCtx.Videos {
-		content := fmt.Sprintf("**VIDEO**\nTitle: %s\nChannel: %s\nDescription: %s", video.Title, video.Channel, video.Description)
+		fencedSource, _ := workflowExecutor.orchestrator.sanitizer.WrapUntrusted(
+			fmt.Sprintf("Title: %s\nChannel: %s\nDescription: %s", video.Title, video.Channel, video.Description))
+		content := "**VIDEO**\n" + fencedSource
 		if !video.PublishedAt.IsZero() {
-			content += fmt.Sprintf("\nPublished: %s", video.PublishedAt.Format("2006-01-02"))
+			content += fmt.Sprintf("\nPublished: %s", video.PublishedAt.In(location).Format("2006-01-02 15:04 MST"))
 		}
 		if video.Duration != "" {
 			content += fmt.Sprintf("\nDuration: %s", video.Duration)
@@ -770,9 +1832,56 @@ func (workflowExecutor *WorkflowExecutor) generateSummary(ctx context.Context) e
 	// Use original query for summarization
 	originalQuery := workflowExecutor.workflowCtx.OriginalQuery
 
-	summary, err := workflowExecutor.orchestrator.geminiService.SummarizeContent(ctx, originalQuery, allContents)
-	if err != nil {
-		return fmt.Errorf("summary generation failed: %w", err)
+	summaryFormat := workflowExecutor.workflowCtx.ConversationContext.UserPreferences.SummaryFormat
+
+	var summary string
+	if len(allContents) == 0 {
+		// nothing to summarize, answer honestly in the user's language and let the persona step set the tone
+		summary = EmptyResultSummary(workflowExecutor.workflowCtx.ConversationContext.UserPreferences.Language, summaryFormat)
+		workflowExecutor.workflowCtx.Metadata["empty_result"] = true
+		workflowExecutor.orchestrator.emptyResults.Add(1)
+		workflowExecutor.logger.Info("No articles or videos found, using empty result notice",
+			"workflow_id", workflowExecutor.workflowCtx.ID,
+			"language", workflowExecutor.workflowCtx.ConversationContext.UserPreferences.Language)
+	} else {
+		var err error
+		links := linkArticlesAndVideos(workflowExecutor.workflowCtx.Articles, workflowExecutor.workflowCtx.Videos,
+			workflowExecutor.orchestrator.config.Workflow.MediaLinkThreshold)
+		if len(links) > 0 {
+			workflowExecutor.workflowCtx.Metadata["media_links"] = links
+		}
+
+		citations := workflowExecutor.citationsEnabled()
+		summary, err = workflowExecutor.orchestrator.geminiService.SummarizeContent(ctx, originalQuery, allContents, summaryFormat, links,
+			workflowExecutor.strictSourcesOnly(), citations)
+		if errors.Is(err, ErrSummaryTruncated) {
+			// a cut off summary still beats none, keep it and let the quality score reflect it
+			workflowExecutor.logger.Warn("Summary hit the token limit", "workflow_id", workflowExecutor.workflowCtx.ID)
+			workflowExecutor.workflowCtx.ProcessingStats.SummaryTruncated = true
+			err = nil
+		}
+		if err != nil {
+			workflowExecutor.recordAgentExecution("summarizer", time.Since(startTime), nil, nil, err)
+			return fmt.Errorf("summary generation failed: %w", err)
+		}
+
+		if citations {
+			sources := numberedCitationSources(workflowExecutor.workflowCtx.Articles, workflowExecutor.workflowCtx.Videos)
+			var dropped int
+			summary, dropped = resolveCitations(summary, len(sources))
+			workflowExecutor.workflowCtx.Metadata["citations"] = sources
+			if dropped > 0 {
+				workflowExecutor.workflowCtx.Metadata["dangling_citations"] = dropped
+				workflowExecutor.logger.Warn("Dropped dangling citation markers from the summary",
+					"workflow_id", workflowExecutor.workflowCtx.ID, "dropped", dropped)
+			}
+		}
+	}
+
+	if models.SummaryFormat(summaryFormat) == models.SummaryFormatJSON {
+		if structured, err := ParseStructuredSummary(summary); err == nil {
+			workflowExecutor.workflowCtx.Metadata["structured_summary"] = structured
+		}
 	}
 
 	workflowExecutor.workflowCtx.Summary = summary
@@ -791,6 +1900,9 @@ func (workflowExecutor *WorkflowExecutor) generateSummary(ctx context.Context) e
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("summarizer", duration,
+		map[string]any{"articles": len(workflowExecutor.workflowCtx.Articles), "videos": len(workflowExecutor.workflowCtx.Videos), "format": summaryFormat},
+		map[string]any{"summary_length": len(summary)}, nil)
 
 	// Create status message based on content processed
 	var statusMessage string
@@ -831,6 +1943,7 @@ func (workflowExecutor *WorkflowExecutor) generateChitChatResponse(ctx context.C
 
 	response, err := workflowExecutor.orchestrator.geminiService.GenerateChitChatResponse(ctx, workflowExecutor.workflowCtx.OriginalQuery, contextMap)
 	if err != nil {
+		workflowExecutor.recordAgentExecution("chitchat", time.Since(startTime), nil, nil, err)
 		return fmt.Errorf("chitchat generation failed: %w", err)
 	}
 
@@ -845,6 +1958,9 @@ func (workflowExecutor *WorkflowExecutor) generateChitChatResponse(ctx context.C
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("chitchat", duration,
+		map[string]any{"query_length": len(workflowExecutor.workflowCtx.OriginalQuery)},
+		map[string]any{"response_length": len(response)}, nil)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "chitchat", models.AgentStatusCompleted,
 		fmt.Sprintf("Generated conversational response (%d chars)", len(response))); err != nil {
@@ -857,21 +1973,149 @@ func (workflowExecutor *WorkflowExecutor) generateChitChatResponse(ctx context.C
 // Keep existing methods but update them to use enhanced context
 
 func (workflowExecutor *WorkflowExecutor) enhanceArticlesWithFullContent(ctx context.Context) error {
-	startTime := time.Now()
+	articles, outcome := workflowExecutor.scrapeArticles(ctx, workflowExecutor.workflowCtx.Articles)
+	workflowExecutor.workflowCtx.Articles = articles
+	workflowExecutor.recordScrape(ctx, outcome)
+	return nil
+}
+
+// scrapeOutcome is what a scrape pass did, kept off the workflow until recordScrape so the pass can run
+// alongside other agents
+type scrapeOutcome struct {
+	startTime time.Time
+	endTime   time.Time
+	attempted int
+	skipped   int
+	enriched  []models.NewsArticle
+}
+
+// scrapeArticles fills in the full content of the given articles and returns them without touching the workflow,
+// so it can run inside the relevancy agent while video relevancy is still in flight. The caller records the
+// outcome once nothing else runs.
+func (workflowExecutor *WorkflowExecutor) scrapeArticles(ctx context.Context, relevantArticles []models.NewsArticle) ([]models.NewsArticle, scrapeOutcome) {
+	outcome := scrapeOutcome{startTime: time.Now()}
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "scrapper", models.AgentStatusProcessing, "Getting articles with full content"); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish scrapper update")
+	}
+
+	if len(relevantArticles) == 0 {
+		workflowExecutor.logger.Info("No articles to scrape")
+		return relevantArticles, scrapeOutcome{}
+	}
+
+	gated := workflowExecutor.scrapeGate(relevantArticles)
+	skipped := len(relevantArticles) - len(gated)
+	// content embeddings already scraped these, their text is in place
+	if alreadyScraped, _ := workflowExecutor.workflowCtx.Metadata["embedding_scraped_articles"].(map[string]bool); len(alreadyScraped) > 0 {
+		gated = slices.DeleteFunc(gated, func(index int) bool { return alreadyScraped[relevantArticles[index].ID] })
+	}
+	articlesToScrape := make([]models.NewsArticle, len(gated))
+	for i, index := range gated {
+		articlesToScrape[i] = relevantArticles[index]
+	}
+	workflowExecutor.logger.Info("Scrapping articles for full content", "articles_to_scrape", len(articlesToScrape), "skipped_by_gate", skipped)
+
+	previousContentLength := make(map[string]int, len(articlesToScrape))
+	for _, article := range articlesToScrape {
+		previousContentLength[article.ID] = len(article.Content)
+	}
+
+	if len(articlesToScrape) > 0 {
+		articlesToScrape = workflowExecutor.scrapeContent(ctx, articlesToScrape)
+	}
+
+	for _, article := range articlesToScrape {
+		if article.ID != "" && len(article.Content) > previousContentLength[article.ID] {
+			outcome.enriched = append(outcome.enriched, article)
+		}
+	}
+	outcome.attempted = len(articlesToScrape)
+	outcome.skipped = skipped
+	outcome.endTime = time.Now()
+
+	fullContentCount := 0
+	for _, article := range articlesToScrape {
+		if article.Content != "" {
+			fullContentCount++
+		}
+	}
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "scrapper", models.AgentStatusCompleted,
+		fmt.Sprintf("Enhanced %d articles with full content (attempted %d)", fullContentCount, len(articlesToScrape))); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish scrapper completion update")
+	}
+
+	articles := slices.Clone(relevantArticles)
+	for i, index := range gated {
+		articles[index] = articlesToScrape[i]
+	}
+	return articles, outcome
+}
+
+// recordScrape writes a scrape pass to the workflow's stats and refreshes the vectors of the articles it enriched
+func (workflowExecutor *WorkflowExecutor) recordScrape(ctx context.Context, outcome scrapeOutcome) {
+	if outcome.endTime.IsZero() {
+		return
+	}
+
+	workflowExecutor.refreshEnrichedArticleVectors(ctx, outcome.enriched)
+	workflowExecutor.workflowCtx.ProcessingStats.ScrapeAttempts = outcome.attempted
+	workflowExecutor.workflowCtx.ProcessingStats.ArticlesScraped = len(outcome.enriched)
+	workflowExecutor.workflowCtx.ProcessingStats.ScrapesSkipped = outcome.skipped
+
+	duration := outcome.endTime.Sub(outcome.startTime)
+	workflowExecutor.workflowCtx.UpdateAgentStats("scrapper", models.AgentStats{
+		Name:      "scrapper",
+		Duration:  duration,
+		Status:    string(models.AgentStatusCompleted),
+		StartTime: outcome.startTime,
+		EndTime:   outcome.endTime,
+	})
+	workflowExecutor.recordAgentExecution("scrapper", duration,
+		map[string]any{"urls": outcome.attempted, "skipped_by_gate": outcome.skipped},
+		map[string]any{"enriched_articles": len(outcome.enriched)}, nil)
+}
 
-	if err := workflowExecutor.publishAgentUpdate(ctx, "scrapper", models.AgentStatusProcessing, "Getting articles with full content"); err != nil {
-		workflowExecutor.logger.WithError(err).Error("Failed to publish scrapper update")
+// scrapedArticleIDs is the set of article ids a scrape pass covered, articles without an id are left out
+func scrapedArticleIDs(articles []models.NewsArticle) map[string]bool {
+	ids := make(map[string]bool, len(articles))
+	for _, article := range articles {
+		if article.ID != "" {
+			ids[article.ID] = true
+		}
 	}
+	return ids
+}
 
-	relevantArticles := workflowExecutor.workflowCtx.Articles
-	if len(relevantArticles) == 0 {
-		workflowExecutor.logger.Info("No articles to scrape")
+// scrapeGate picks which relevant articles are worth a scrape: those rated at least the minimum relevance,
+// and of those only the most relevant up to the maximum. It returns their indexes in article order.
+// Against the fixture corpus nothing is scraped, the fixtures already carry the text the evaluation is pinned to.
+func (workflowExecutor *WorkflowExecutor) scrapeGate(articles []models.NewsArticle) []int {
+	if workflowExecutor.orchestrator.config.Eval.Enabled() {
 		return nil
 	}
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
 
-	articlesToScrape := relevantArticles
-	workflowExecutor.logger.Info("Scrapping articles for full content", "articles_to_scrape", len(articlesToScrape))
+	var gated []int
+	for i, article := range articles {
+		if article.RelevanceScore >= workflowConfig.ScrapeMinRelevance {
+			gated = append(gated, i)
+		}
+	}
+
+	if workflowConfig.ScrapeMaxArticles > 0 && len(gated) > workflowConfig.ScrapeMaxArticles {
+		sort.SliceStable(gated, func(a, b int) bool {
+			return articles[gated[a]].RelevanceScore > articles[gated[b]].RelevanceScore
+		})
+		gated = gated[:workflowConfig.ScrapeMaxArticles]
+		sort.Ints(gated)
+	}
+	return gated
+}
 
+// scrapeContent fetches the full text of each article in place, per article when the batch scrape fails
+func (workflowExecutor *WorkflowExecutor) scrapeContent(ctx context.Context, articlesToScrape []models.NewsArticle) []models.NewsArticle {
 	urls := make([]string, len(articlesToScrape))
 	for i, article := range articlesToScrape {
 		urls[i] = article.URL
@@ -911,30 +2155,45 @@ func (workflowExecutor *WorkflowExecutor) enhanceArticlesWithFullContent(ctx con
 		}
 	}
 
-	workflowExecutor.workflowCtx.Articles = articlesToScrape
+	return articlesToScrape
+}
 
-	duration := time.Since(startTime)
-	workflowExecutor.workflowCtx.UpdateAgentStats("scrapper", models.AgentStats{
-		Name:      "scrapper",
-		Duration:  duration,
-		Status:    string(models.AgentStatusCompleted),
-		StartTime: startTime,
-		EndTime:   time.Now(),
-	})
+// refreshEnrichedArticleVectors re-embeds articles that gained scraped content so the stored vectors reflect it.
+// It runs in the background, the response does not depend on it.
+func (workflowExecutor *WorkflowExecutor) refreshEnrichedArticleVectors(ctx context.Context, articles []models.NewsArticle) {
+	storedCount, _ := workflowExecutor.workflowCtx.Metadata["stored_articles_count"].(int)
+	if len(articles) == 0 || storedCount == 0 {
+		return
+	}
 
-	fullContentCount := 0
-	for _, article := range articlesToScrape {
-		if article.Content != "" {
-			fullContentCount++
+	refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Minute)
+
+	go func() {
+		defer cancel()
+
+		refreshed := 0
+		for _, article := range articles {
+			if err := workflowExecutor.orchestrator.chromaDBService.RefreshArticle(refreshCtx, article); err != nil {
+				workflowExecutor.logger.WithError(err).Warn("Failed to refresh enriched article vector", "article_id", article.ID)
+				continue
+			}
+			refreshed++
 		}
-	}
 
-	if err := workflowExecutor.publishAgentUpdate(ctx, "scrapper", models.AgentStatusCompleted,
-		fmt.Sprintf("Enhanced %d articles with full content (attempted %d)", fullContentCount, len(articlesToScrape))); err != nil {
-		workflowExecutor.logger.WithError(err).Error("Failed to publish scrapper completion update")
-	}
+		workflowExecutor.logger.Info("Refreshed stored vectors for enriched articles",
+			"workflow_id", workflowExecutor.workflowCtx.ID,
+			"refreshed", refreshed,
+			"attempted", len(articles))
+	}()
+}
 
-	return nil
+// articleEmbeddingText builds the embedding input, capped so long articles stay within the embedding model context
+func articleEmbeddingText(article models.NewsArticle) string {
+	text := fmt.Sprintf("%s - %s", article.Title, article.Description)
+	if article.Content != "" {
+		text = fmt.Sprintf("%s - %s", text, article.Content)
+	}
+	return safeTruncate(text, 8000)
 }
 
 // Updated: Use enhanced query for news fetching
@@ -945,9 +2204,13 @@ func (workflowExecutor *WorkflowExecutor) fetchArticlesAndVideos(ctx context.Con
 		workflowExecutor.logger.WithError(err).Error("Failed to publish news_fetch update")
 	}
 
+	limits := workflowExecutor.fetchLimits()
+	workflowExecutor.workflowCtx.Metadata["fetch_limits"] = limits
+
 	var freshArticles []models.NewsArticle
 	var freshVideos []models.YouTubeVideo
 	var articleErr, videoErr error
+	var videosFiltered int
 
 	// Use sync.WaitGroup for parallel execution
 	var wg sync.WaitGroup
@@ -958,19 +2221,20 @@ func (workflowExecutor *WorkflowExecutor) fetchArticlesAndVideos(ctx context.Con
 		defer wg.Done()
 
 		if len(workflowExecutor.workflowCtx.Keywords) > 0 {
-			freshArticles, articleErr = workflowExecutor.orchestrator.newsService.SearchByKeywords(ctx, workflowExecutor.workflowCtx.Keywords, 100)
+			freshArticles, articleErr = workflowExecutor.orchestrator.newsService.SearchByKeywords(ctx, workflowExecutor.workflowCtx.Keywords, limits.MaxArticles)
 			if articleErr != nil {
 				workflowExecutor.logger.WithError(articleErr).Error("Keyword Search Failed, trying recent news")
 			}
 		}
 
-		if len(freshArticles) == 0 {
+		// a rejected key or an exhausted quota fails the recent news search the same way, so it is not tried
+		if len(freshArticles) == 0 && NewsErrorClass(articleErr) != NewsErrorPermanent && NewsErrorClass(articleErr) != NewsErrorRateLimited {
 			queryForNews := workflowExecutor.workflowCtx.EnhancedQuery
 			if queryForNews == "" {
 				queryForNews = workflowExecutor.workflowCtx.OriginalQuery
 			}
 
-			freshArticles, articleErr = workflowExecutor.orchestrator.newsService.SearchRecentNews(ctx, queryForNews, 48, 15)
+			freshArticles, articleErr = workflowExecutor.orchestrator.newsService.SearchRecentNews(ctx, queryForNews, 48, limits.RecentMaxArticles)
 			if articleErr != nil {
 				workflowExecutor.logger.WithError(articleErr).Error("Recent News Search Failed")
 			}
@@ -984,21 +2248,36 @@ func (workflowExecutor *WorkflowExecutor) fetchArticlesAndVideos(ctx context.Con
 			queryForVideos = workflowExecutor.workflowCtx.OriginalQuery
 		}
 
+		if limits.MaxVideos == 0 {
+			freshVideos = []models.YouTubeVideo{}
+			return
+		}
+
 		if len(workflowExecutor.workflowCtx.Keywords) > 0 {
-			freshVideos, videoErr = workflowExecutor.orchestrator.youtubeService.SearchNewsVideos(ctx, workflowExecutor.workflowCtx.Keywords, 8)
+			freshVideos, videoErr = workflowExecutor.orchestrator.youtubeService.SearchNewsVideos(ctx, workflowExecutor.workflowCtx.Keywords, limits.MaxVideos)
 			if videoErr != nil {
 				workflowExecutor.logger.WithError(videoErr).Error("YouTube keyword search failed, trying query-based search")
 			}
 		}
 
+		if len(freshVideos) == 0 && !errors.Is(videoErr, ErrYouTubeQuotaExceeded) {
+			freshVideos, videoErr = workflowExecutor.orchestrator.youtubeService.SearchVideosByQuery(ctx, queryForVideos, limits.MaxVideos)
+		}
+
+		if errors.Is(videoErr, ErrYouTubeQuotaExceeded) && workflowExecutor.orchestrator.config.Youtube.QuotaFallback {
+			freshVideos, videoErr = workflowExecutor.cachedVideosForQuery(ctx, queryForVideos, limits.MaxVideos)
+		}
+
 		if len(freshVideos) == 0 {
-			freshVideos, videoErr = workflowExecutor.orchestrator.youtubeService.SearchVideosByQuery(ctx, queryForVideos, 8)
 			if videoErr != nil {
 				workflowExecutor.logger.WithError(videoErr).Warn("YouTube search failed completely")
 				freshVideos = []models.YouTubeVideo{} // Empty but continue
 			}
 		}
 
+		// shorts and tiny channels are dropped before any transcript is fetched for them
+		freshVideos, videosFiltered = workflowExecutor.filterVideos(ctx, freshVideos)
+
 		if len(freshVideos) > 0 {
 			enhancedVideos, err := workflowExecutor.enhanceVideosWithTranscripts(ctx, freshVideos)
 			if err != nil {
@@ -1012,9 +2291,24 @@ func (workflowExecutor *WorkflowExecutor) fetchArticlesAndVideos(ctx context.Con
 
 	wg.Wait()
 
+	partialContent := workflowExecutor.orchestrator.config.Workflow.PartialContent
 	if articleErr != nil && len(freshArticles) == 0 {
-		return fmt.Errorf("News Search Failed: %w", articleErr)
+		workflowExecutor.workflowCtx.Metadata["news_error_class"] = NewsErrorClass(articleErr)
+		if !partialContent || len(freshVideos) == 0 {
+			workflowExecutor.recordAgentExecution("news_fetch", time.Since(startTime), nil, nil, articleErr)
+			return fmt.Errorf("News Search Failed: %w", articleErr)
+		}
+		workflowExecutor.logger.WithError(articleErr).Warn("News search failed, continuing with videos only",
+			"workflow_id", workflowExecutor.workflowCtx.ID, "videos", len(freshVideos))
+		workflowExecutor.markContentUnavailable(ContentTypeArticles)
 	}
+	if partialContent && videoErr != nil && len(freshVideos) == 0 && len(freshArticles) > 0 {
+		workflowExecutor.markContentUnavailable(ContentTypeVideos)
+	}
+
+	// categories are stored alongside the embeddings so category filtered search works later
+	categoryCounts := workflowExecutor.orchestrator.categorizer.CategorizeAll(freshArticles)
+	workflowExecutor.logger.Debug("Articles categorized", "categories", categoryCounts)
 
 	workflowExecutor.workflowCtx.Articles = freshArticles
 	workflowExecutor.workflowCtx.Videos = freshVideos
@@ -1022,6 +2316,9 @@ func (workflowExecutor *WorkflowExecutor) fetchArticlesAndVideos(ctx context.Con
 	workflowExecutor.workflowCtx.Metadata["fresh_videos"] = freshVideos
 	workflowExecutor.workflowCtx.Metadata["articles_count"] = len(freshArticles)
 	workflowExecutor.workflowCtx.Metadata["videos_count"] = len(freshVideos)
+	if videosFiltered > 0 {
+		workflowExecutor.workflowCtx.Metadata["videos_filtered"] = videosFiltered
+	}
 
 	workflowExecutor.workflowCtx.ProcessingStats.ArticlesFound = len(freshArticles)
 	workflowExecutor.workflowCtx.ProcessingStats.VideosFound = len(freshVideos)
@@ -1034,6 +2331,9 @@ func (workflowExecutor *WorkflowExecutor) fetchArticlesAndVideos(ctx context.Con
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("news_fetch", duration,
+		map[string]any{"keywords": len(workflowExecutor.workflowCtx.Keywords), "max_articles": limits.MaxArticles, "max_videos": limits.MaxVideos},
+		map[string]any{"articles": len(freshArticles), "videos": len(freshVideos)}, nil)
 
 	if videoErr != nil {
 		workflowExecutor.logger.WithError(videoErr).Warn("YouTube video fetch had issues, continuing with articles only")
@@ -1047,6 +2347,49 @@ func (workflowExecutor *WorkflowExecutor) fetchArticlesAndVideos(ctx context.Con
 	return nil
 }
 
+// cachedVideosForQuery serves previously stored videos from the vector store when YouTube cannot be queried
+func (workflowExecutor *WorkflowExecutor) cachedVideosForQuery(ctx context.Context, query string, maxVideos int) ([]models.YouTubeVideo, error) {
+	queryEmbedding, err := workflowExecutor.orchestrator.embeddings.GenerateQueryEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("cached video fallback embedding failed: %w", err)
+	}
+
+	results, err := workflowExecutor.orchestrator.chromaDBService.SearchSimilarVideos(ctx, queryEmbedding, maxVideos,
+		workflowExecutor.orchestrator.config.Etc.ChromaMinSimilarity, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cached video fallback search failed: %w", err)
+	}
+
+	videos := make([]models.YouTubeVideo, 0, len(results))
+	for _, result := range results {
+		videos = append(videos, result.VideoDocument)
+	}
+
+	workflowExecutor.workflowCtx.Metadata["videos_from_cache"] = true
+	workflowExecutor.logger.Info("YouTube quota exhausted, using cached videos",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"cached_videos", len(videos))
+
+	return videos, nil
+}
+
+// fetchLimits resolves the workflow profile's fetch limits with any per request "max_articles" / "max_videos" overrides
+func (workflowExecutor *WorkflowExecutor) fetchLimits() config.FetchLimits {
+	profile := workflowProfileName(models.Intent(workflowExecutor.workflowCtx.Intent))
+	limits := workflowExecutor.orchestrator.config.Workflow.FetchLimitsFor(profile)
+
+	if maxArticles, ok := workflowExecutor.workflowCtx.RequestInt("max_articles"); ok && maxArticles > 0 {
+		limits.MaxArticles = min(maxArticles, MaxPageSize)
+		limits.RecentMaxArticles = min(limits.RecentMaxArticles, limits.MaxArticles)
+	}
+
+	if maxVideos, ok := workflowExecutor.workflowCtx.RequestInt("max_videos"); ok && maxVideos >= 0 {
+		limits.MaxVideos = min(maxVideos, MaxYouTubeResults)
+	}
+
+	return limits
+}
+
 func (workflowExecutor *WorkflowExecutor) enhanceVideosWithTranscripts(ctx context.Context, videos []models.YouTubeVideo) ([]models.YouTubeVideo, error) {
 	if len(videos) == 0 {
 		return videos, nil
@@ -1058,6 +2401,7 @@ func (workflowExecutor *WorkflowExecutor) enhanceVideosWithTranscripts(ctx conte
 
 	enhancedVideos := make([]models.YouTubeVideo, 0, len(videos))
 	successCount := 0
+	cachedCount := 0
 
 	for i, video := range videos {
 		workflowExecutor.logger.Info("Fetching transcript for video",
@@ -1065,7 +2409,10 @@ func (workflowExecutor *WorkflowExecutor) enhanceVideosWithTranscripts(ctx conte
 			"title", video.Title,
 			"progress", fmt.Sprintf("%d/%d", i+1, len(videos)))
 
-		transcipt, err := workflowExecutor.orchestrator.youtubeService.GetVideoTranscript(ctx, video.ID)
+		transcipt, cached, err := workflowExecutor.videoTranscript(ctx, video.ID)
+		if cached {
+			cachedCount++
+		}
 		if err != nil {
 			workflowExecutor.logger.Warn("Failed to get transcript, using description as fallback",
 				"video_id", video.ID,
@@ -1093,7 +2440,9 @@ func (workflowExecutor *WorkflowExecutor) enhanceVideosWithTranscripts(ctx conte
 	}
 
 	workflowExecutor.logger.Info("Video enhancement completed", "total_videos", len(videos),
-		"transcripts_found", successCount, "fallback_used", len(videos)-successCount)
+		"transcripts_found", successCount, "transcripts_cached", cachedCount, "fallback_used", len(videos)-successCount,
+		"fallbacks_generated", workflowExecutor.workflowCtx.ProcessingStats.VideoFallbacksGenerated,
+		"fallbacks_skipped", workflowExecutor.workflowCtx.ProcessingStats.VideoFallbacksSkipped)
 
 	statusMessage := fmt.Sprintf("Enhanced %d videos (%d with transcripts, %d with fallback)", len(enhancedVideos), successCount, len(videos)-successCount)
 	if err := workflowExecutor.publishAgentUpdate(ctx, "video_enhancer", models.AgentStatusCompleted, statusMessage); err != nil {
@@ -1104,11 +2453,49 @@ func (workflowExecutor *WorkflowExecutor) enhanceVideosWithTranscripts(ctx conte
 
 }
 
+// videoTranscript serves the transcript from the cache when an earlier workflow already fetched it, and caches
+// freshly fetched ones. Transcripts rarely change, so the saved caption API calls are worth a long TTL.
+func (workflowExecutor *WorkflowExecutor) videoTranscript(ctx context.Context, videoID string) (string, bool, error) {
+	ttl := workflowExecutor.orchestrator.config.Youtube.TranscriptCacheTTL
+	useCache := ttl > 0 && !workflowExecutor.workflowCtx.Stateless
+	redisService := workflowExecutor.orchestrator.redisService
+
+	if useCache {
+		transcript, found, err := redisService.GetTranscript(ctx, videoID, TranscriptLanguage)
+		if err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Failed to read transcript cache", "video_id", videoID)
+		} else if found {
+			return transcript, true, nil
+		}
+	}
+
+	transcript, err := workflowExecutor.orchestrator.youtubeService.GetVideoTranscript(ctx, videoID)
+	if err != nil {
+		return "", false, err
+	}
+
+	if useCache && transcript != "" {
+		if err := redisService.StoreTranscript(ctx, videoID, TranscriptLanguage, transcript, ttl); err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Failed to store transcript cache", "video_id", videoID)
+		}
+	}
+	return transcript, false, nil
+}
+
+// generateFallbackContent stands in for a missing transcript. Long descriptions are used as they are, short ones get
+// a generated summary until the workflow's MaxFallbackGenerations are used up.
 func (workflowExecutor *WorkflowExecutor) generateFallbackContent(ctx context.Context, video models.YouTubeVideo) string {
 	if len(video.Description) > 200 {
 		return video.Description
 	}
 
+	stats := &workflowExecutor.workflowCtx.ProcessingStats
+	if stats.VideoFallbacksGenerated >= workflowExecutor.orchestrator.config.Youtube.MaxFallbackGenerations {
+		stats.VideoFallbacksSkipped++
+		return video.Description
+	}
+	stats.VideoFallbacksGenerated++
+
 	prompt := fmt.Sprintf(`Based on this YouTube video:
 			Title: %s
 			Channel: %s
@@ -1145,17 +2532,15 @@ func (workflowExecutor *WorkflowExecutor) generateNewsAndVideoEmbeddings(ctx con
 		workflowExecutor.logger.WithError(err).Error("Failed to publish embedding generation update")
 	}
 
-	// Get fresh articles
-	freshArticles, ok := workflowExecutor.workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle)
-	if !ok || len(freshArticles) == 0 {
-		return fmt.Errorf("No fresh articles found for embedding generation")
-	}
-
-	// Get fresh videos
+	// Get fresh articles and videos, with partial content either one may be empty but not both
+	freshArticles, _ := workflowExecutor.workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle)
 	freshVideos, ok := workflowExecutor.workflowCtx.Metadata["fresh_videos"].([]models.YouTubeVideo)
 	if !ok {
 		freshVideos = []models.YouTubeVideo{} // Continue with empty videos if none found
 	}
+	if len(freshArticles) == 0 && (!workflowExecutor.orchestrator.config.Workflow.PartialContent || len(freshVideos) == 0) {
+		return fmt.Errorf("No fresh articles found for embedding generation")
+	}
 
 	// Use enhanced query for embedding
 	queryForEmbedding := workflowExecutor.workflowCtx.EnhancedQuery
@@ -1164,19 +2549,33 @@ func (workflowExecutor *WorkflowExecutor) generateNewsAndVideoEmbeddings(ctx con
 	}
 
 	// Generate query embedding
-	queryEmbedding, err := workflowExecutor.orchestrator.ollamaService.GenerateQueryEmbedding(ctx, queryForEmbedding)
+	queryEmbedding, err := workflowExecutor.orchestrator.embeddings.GenerateQueryEmbedding(ctx, queryForEmbedding)
 	if err != nil {
+		workflowExecutor.recordAgentExecution("embedding_generation", time.Since(startTime), nil, nil, err)
 		return fmt.Errorf("Failed to generate user query embedding: %w", err)
 	}
 
 	// Generate article embeddings
-	articleTexts := make([]string, len(freshArticles))
-	for i, article := range freshArticles {
-		articleTexts[i] = fmt.Sprintf("%s - %s", article.Title, article.Description)
+	var articleEmbeddings [][]float64
+	if len(freshArticles) == 0 {
+		articleEmbeddings = [][]float64{}
+	} else if workflowExecutor.orchestrator.config.Workflow.EmbeddingInput == EmbeddingInputContent {
+		// the fixture corpus is never scraped, its articles already carry their text
+		if !workflowExecutor.orchestrator.config.Eval.Enabled() {
+			freshArticles = workflowExecutor.scrapeContent(ctx, freshArticles)
+			workflowExecutor.workflowCtx.Metadata["fresh_articles"] = freshArticles
+			workflowExecutor.workflowCtx.Metadata["embedding_scraped_articles"] = scrapedArticleIDs(freshArticles)
+		}
+		articleEmbeddings, err = workflowExecutor.generateContentEmbeddings(ctx, freshArticles)
+	} else {
+		articleTexts := make([]string, len(freshArticles))
+		for i, article := range freshArticles {
+			articleTexts[i] = fmt.Sprintf("%s - %s", article.Title, article.Description)
+		}
+		articleEmbeddings, err = workflowExecutor.orchestrator.embeddings.BatchGenerateNewsEmbeddings(ctx, articleTexts)
 	}
-
-	articleEmbeddings, err := workflowExecutor.orchestrator.ollamaService.BatchGenerateNewsEmbeddings(ctx, articleTexts)
 	if err != nil {
+		workflowExecutor.recordAgentExecution("embedding_generation", time.Since(startTime), nil, nil, err)
 		return fmt.Errorf("article embeddings generation failed: %w", err)
 	}
 
@@ -1188,7 +2587,7 @@ func (workflowExecutor *WorkflowExecutor) generateNewsAndVideoEmbeddings(ctx con
 			videoTexts[i] = fmt.Sprintf("%s - %s", video.Title, video.Description)
 		}
 
-		videoEmbeddings, err = workflowExecutor.orchestrator.ollamaService.BatchGenerateVideoEmbeddings(ctx, videoTexts)
+		videoEmbeddings, err = workflowExecutor.orchestrator.embeddings.BatchGenerateVideoEmbeddings(ctx, videoTexts)
 		if err != nil {
 			workflowExecutor.logger.WithError(err).Warn("Video embeddings generation failed, continuing without videos")
 			videoEmbeddings = [][]float64{}
@@ -1210,6 +2609,9 @@ func (workflowExecutor *WorkflowExecutor) generateNewsAndVideoEmbeddings(ctx con
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("embedding_generation", duration,
+		map[string]any{"articles": len(freshArticles), "videos": len(freshVideos)},
+		map[string]any{"article_embeddings": len(articleEmbeddings), "video_embeddings": len(videoEmbeddings)}, nil)
 
 	statusMessage := fmt.Sprintf("Generated Embeddings for %d articles and %d videos", len(articleEmbeddings), len(videoEmbeddings))
 	if err := workflowExecutor.publishAgentUpdate(ctx, "embedding_generation", models.AgentStatusCompleted, statusMessage); err != nil {
@@ -1219,6 +2621,35 @@ func (workflowExecutor *WorkflowExecutor) generateNewsAndVideoEmbeddings(ctx con
 	return nil
 }
 
+// generateContentEmbeddings embeds every chunk of every article in one batch and mean-pools them per article
+func (workflowExecutor *WorkflowExecutor) generateContentEmbeddings(ctx context.Context, articles []models.NewsArticle) ([][]float64, error) {
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+
+	var texts []string
+	offsets := make([]int, len(articles)+1)
+	for i, article := range articles {
+		texts = append(texts, articleEmbeddingChunks(article, workflowConfig.EmbeddingParagraphs, workflowConfig.EmbeddingChunkChars)...)
+		offsets[i+1] = len(texts)
+	}
+
+	chunkEmbeddings, err := workflowExecutor.orchestrator.embeddings.BatchGenerateNewsEmbeddings(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([][]float64, len(articles))
+	for i := range articles {
+		embeddings[i] = meanPool(chunkEmbeddings[offsets[i]:offsets[i+1]])
+	}
+
+	workflowExecutor.logger.Info("Generated content embeddings",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"articles", len(articles),
+		"chunks", len(texts))
+
+	return embeddings, nil
+}
+
 func (workflowExecutor *WorkflowExecutor) KeywordExtractionAndQueryEnhancement(ctx context.Context) error {
 	workflowExecutor.logger.Warn("KeywordExtractionAndQueryEnhancement is deprecated, use executeSequentialQueryProcessing instead")
 	return workflowExecutor.executeSequentialQueryProcessing(ctx, &IntentClassificationResult{
@@ -1262,8 +2693,9 @@ func (workflowExecutor *WorkflowExecutor) storeFreshArticlesAndVideos(ctx contex
 
 	freshVideoEmbeddings, videoEmbeddingsExist := workflowExecutor.workflowCtx.Metadata["fresh_video_embeddings"].([][]float64)
 
-	workflowExecutor.workflowCtx.Metadata["fresh_article_embeddings"] = len(freshArticles)
-	workflowExecutor.workflowCtx.Metadata["fresh_video_embeddings"] = len(freshVideos)
+	// the embeddings stay in place for the pre-filter and near duplicate collapse that run after storage
+	workflowExecutor.workflowCtx.Metadata["fresh_article_embedding_count"] = len(freshArticles)
+	workflowExecutor.workflowCtx.Metadata["fresh_video_embedding_count"] = len(freshVideos)
 
 	if !videoEmbeddingsExist {
 		freshVideoEmbeddings = [][]float64{}
@@ -1279,9 +2711,7 @@ func (workflowExecutor *WorkflowExecutor) storeFreshArticlesAndVideos(ctx contex
 		defer wg.Done()
 		if len(freshArticles) > 0 && len(freshArticleEmbeddings) > 0 {
 			articleErr = workflowExecutor.orchestrator.chromaDBService.StoreArticles(ctx, freshArticles, freshArticleEmbeddings)
-			if articleErr == nil {
-				articlesStored = len(freshArticles)
-			}
+			articlesStored = StoredCount(articleErr, len(freshArticles))
 		}
 	}()
 
@@ -1290,16 +2720,22 @@ func (workflowExecutor *WorkflowExecutor) storeFreshArticlesAndVideos(ctx contex
 		go func() {
 			defer wg.Done()
 			videoErr = workflowExecutor.orchestrator.chromaDBService.StoreVideos(ctx, freshVideos, freshVideoEmbeddings)
-			if videoErr == nil {
-				videosStored = len(freshVideos)
-			}
+			videosStored = StoredCount(videoErr, len(freshVideos))
 		}()
 	}
 
 	wg.Wait()
 
-	// Handle errors
+	// Handle errors, a partial store still leaves most of the articles searchable
+	var partialStore *BatchStoreError
+	if errors.As(articleErr, &partialStore) && articlesStored > 0 {
+		workflowExecutor.logger.WithError(articleErr).Warn("Some article chunks failed to store, continuing",
+			"stored", partialStore.Stored, "failed", len(partialStore.FailedIDs))
+		articleErr = nil
+	}
+
 	if articleErr != nil {
+		workflowExecutor.recordAgentExecution("vector_storage", time.Since(startTime), nil, nil, articleErr)
 		return fmt.Errorf("Failed to store fresh articles in ChromaDB: %w", articleErr)
 	}
 
@@ -1319,6 +2755,9 @@ func (workflowExecutor *WorkflowExecutor) storeFreshArticlesAndVideos(ctx contex
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
+	workflowExecutor.recordAgentExecution("vector_storage", duration,
+		map[string]any{"articles": len(freshArticles), "videos": len(freshVideos)},
+		map[string]any{"articles_stored": articlesStored, "videos_stored": videosStored}, nil)
 
 	// Create status message based on what was stored
 	var statusMessage string
@@ -1360,22 +2799,25 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 		return fmt.Errorf("Query Embedding not found in metadata")
 	}
 
+	minSimilarity := workflowExecutor.orchestrator.config.Etc.ChromaMinSimilarity
+
 	var wg sync.WaitGroup
 	var articleSearchResults []SearchResult
 	var videoSearchResults []VideoSearchResult
 	var articleSearchErr, videoSearchErr error
+	var articleSearchMode string
 
 	wg.Add(2)
 
 	go func() {
 		defer wg.Done()
-		articleSearchResults, articleSearchErr = workflowExecutor.orchestrator.chromaDBService.SearchSimilarArticles(ctx, queryEmbedding, 20, nil)
+		articleSearchResults, articleSearchMode, articleSearchErr = workflowExecutor.searchStoredArticles(ctx, queryEmbedding, minSimilarity)
 	}()
 
 	// Search videos
 	go func() {
 		defer wg.Done()
-		videoSearchResults, videoSearchErr = workflowExecutor.orchestrator.chromaDBService.SearchSimilarVideos(ctx, queryEmbedding, 10, nil)
+		videoSearchResults, videoSearchErr = workflowExecutor.orchestrator.chromaDBService.SearchSimilarVideos(ctx, queryEmbedding, 10, minSimilarity, nil)
 		if videoSearchErr != nil {
 			// Log warning but don't fail the entire operation
 			workflowExecutor.logger.WithError(videoSearchErr).Warn("Video semantic search failed, continuing with articles only")
@@ -1387,8 +2829,10 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 	wg.Wait()
 
 	if articleSearchErr != nil {
+		workflowExecutor.recordAgentExecution("relevancy_agent", time.Since(startTime), nil, nil, articleSearchErr)
 		return fmt.Errorf("ChromaDB Article Semantic Search Failed: %w", articleSearchErr)
 	}
+	workflowExecutor.workflowCtx.Metadata["article_search_mode"] = articleSearchMode
 
 	// Extract articles from search results
 	var semanticallySimilarArticles []models.NewsArticle
@@ -1417,19 +2861,58 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 	var relevantArticles []models.NewsArticle
 	var relevantVideos []models.YouTubeVideo
 
+	freshArticles, _ := workflowExecutor.workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle)
+	freshArticles = workflowExecutor.preFilterArticlesBySimilarity(freshArticles, queryEmbedding)
+
 	wg.Add(2)
 	var Err error
+	var relevancyEmpty, scrapedEarly bool
+	var earlyScrape scrapeOutcome
+	var articlesCapped, videosCapped int
+	parallelScrape := workflowExecutor.orchestrator.config.Workflow.ParallelScrape &&
+		!workflowExecutor.workflowCtx.RequestBool("articles_only_response")
+	// the vector search results are ranked in Go, no relevancy prompt is sent
+	embeddingRelevancy := workflowExecutor.relevancyMode() == RelevancyModeEmbedding
 
 	go func() {
 		defer wg.Done()
-		freshArticles, _ := workflowExecutor.workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle)
 
-		moreArticles, err := workflowExecutor.orchestrator.geminiService.GetRelevantArticles(ctx, freshArticles, contextMap)
+		var moreArticles []models.NewsArticle
+		var err error
+		if embeddingRelevancy {
+			moreArticles = workflowExecutor.rankArticlesByEmbedding(articleSearchResults)
+		} else {
+			moreArticles, err = workflowExecutor.rateArticleRelevancy(ctx, freshArticles, contextMap)
+		}
 		if err == nil {
 			relevantArticles = append(relevantArticles, moreArticles...)
 		} else {
 			Err = err
 		}
+
+		// neither the relevancy call nor the semantic search found anything on topic, the query may be too narrow
+		relevancyEmpty = (Err == nil && len(relevantArticles) == 0) || (Err != nil && len(semanticallySimilarArticles) == 0)
+
+		if Err != nil {
+			relevantArticles = semanticallySimilarArticles
+			// nothing stored cleared the similarity floor, the fresh articles are the best we have
+			if len(relevantArticles) == 0 {
+				relevantArticles = freshArticles
+			}
+		}
+
+		// capped before the early scrape so articles dropped for diversity are never fetched
+		relevantArticles, articlesCapped = diversifyArticles(relevantArticles, workflowExecutor.orchestrator.config.Workflow.MaxArticlesPerSource)
+
+		// the articles are final, scrape them while video relevancy is still running. An empty pass is
+		// about to be broadened and retried, scraping its fallback articles would be wasted.
+		if parallelScrape && !relevancyEmpty && len(relevantArticles) > 0 {
+			scrapedEarly = true
+			_ = workflowExecutor.traceAgent(ctx, "scraper", func(ctx context.Context) error {
+				relevantArticles, earlyScrape = workflowExecutor.scrapeArticles(ctx, relevantArticles)
+				return nil
+			})
+		}
 	}()
 
 	// Process videos for relevance
@@ -1441,6 +2924,11 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 			"videos_count", len(freshVideos),
 			"has_transcripts", workflowExecutor.countVideosWithTranscripts(freshVideos))
 
+		if embeddingRelevancy {
+			relevantVideos = workflowExecutor.rankVideosByEmbedding(videoSearchResults)
+			return
+		}
+
 		if len(freshVideos) > 0 {
 			moreVideos, err := workflowExecutor.orchestrator.geminiService.GetRelevantVideos(ctx, freshVideos, contextMap)
 			if err == nil {
@@ -1452,10 +2940,19 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 	}()
 
 	wg.Wait()
+	workflowExecutor.recordScrape(ctx, earlyScrape)
+
+	relevantVideos, videosCapped = diversifyVideos(relevantVideos, workflowExecutor.orchestrator.config.Workflow.MaxVideosPerChannel)
+	if articlesCapped > 0 || videosCapped > 0 {
+		workflowExecutor.workflowCtx.Metadata["diversity_dropped"] = map[string]int{"articles": articlesCapped, "videos": videosCapped}
+	}
+
+	workflowExecutor.workflowCtx.Metadata["relevancy_empty"] = relevancyEmpty
+	workflowExecutor.workflowCtx.Metadata["scraped_with_relevancy"] = scrapedEarly
 
 	if Err != nil {
 		workflowExecutor.logger.WithError(Err).Warn("Article relevance evaluation failed, using semantic search results")
-		relevantArticles = semanticallySimilarArticles
+		workflowExecutor.workflowCtx.RecordFallback("relevancy_llm")
 	}
 
 	// Store results in workflow context
@@ -1465,7 +2962,9 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 	workflowExecutor.workflowCtx.Metadata["relevant_videos"] = relevantVideos
 
 	// Update processing stats
-	workflowExecutor.workflowCtx.ProcessingStats.APICallsCount++
+	if !embeddingRelevancy {
+		workflowExecutor.workflowCtx.ProcessingStats.APICallsCount++
+	}
 	workflowExecutor.workflowCtx.ProcessingStats.ArticlesFiltered = len(relevantArticles)
 	workflowExecutor.workflowCtx.ProcessingStats.VideosFiltered = len(relevantVideos)
 
@@ -1477,15 +2976,12 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 		StartTime: startTime,
 		EndTime:   time.Now(),
 	})
-
-	var statusMessage string
-
-	if err := workflowExecutor.publishAgentUpdate(ctx, "relevancy_agent", models.AgentStatusCompleted, statusMessage); err != nil {
-		workflowExecutor.logger.WithError(err).Error("Failed to publish relevancy agent completion update")
-	}
+	workflowExecutor.recordAgentExecution("relevancy_agent", duration,
+		map[string]any{"candidate_articles": len(semanticallySimilarArticles), "candidate_videos": len(semanticallySimilarVideos)},
+		map[string]any{"articles": len(relevantArticles), "videos": len(relevantVideos), "llm_fallback": Err != nil, "embedding_only": embeddingRelevancy}, nil)
 
 	transcriptCount := workflowExecutor.countVideosWithTranscripts(relevantVideos)
-	statusMessage = fmt.Sprintf("Selected %d relevant articles and %d relevant videos (%d with transcripts)",
+	statusMessage := fmt.Sprintf("Selected %d relevant articles and %d relevant videos (%d with transcripts)",
 		len(relevantArticles), len(relevantVideos), transcriptCount)
 
 	if err := workflowExecutor.publishAgentUpdate(ctx, "relevancy_agent", models.AgentStatusCompleted, statusMessage); err != nil {
@@ -1495,6 +2991,54 @@ func (workflowExecutor *WorkflowExecutor) getRelevantArticlesAndVideos(ctx conte
 	return nil
 }
 
+// searchStoredArticles runs the vector search, narrowed to keyword and recency matches in hybrid mode. Hybrid
+// falls back to the plain vector search when the filter leaves nothing so a strict keyword never empties the pool.
+func (workflowExecutor *WorkflowExecutor) searchStoredArticles(ctx context.Context, queryEmbedding []float64, minSimilarity float64) ([]SearchResult, string, error) {
+	chromaDBService := workflowExecutor.orchestrator.chromaDBService
+	etcConfig := workflowExecutor.orchestrator.config.Etc
+
+	if etcConfig.ChromaHybridSearch && len(workflowExecutor.workflowCtx.Keywords) > 0 {
+		filter := ArticleMetadataFilter{Keywords: workflowExecutor.workflowCtx.Keywords}
+		if etcConfig.ChromaHybridLookback > 0 {
+			filter.Since = time.Now().Add(-etcConfig.ChromaHybridLookback)
+		}
+
+		results, err := chromaDBService.SearchArticlesHybrid(ctx, queryEmbedding, filter, 20, minSimilarity)
+		if err == nil && len(results) > 0 {
+			return results, "hybrid", nil
+		}
+		if err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Hybrid article search failed, falling back to vector search",
+				"workflow_id", workflowExecutor.workflowCtx.ID)
+		}
+	}
+
+	results, err := chromaDBService.SearchSimilarArticles(ctx, queryEmbedding, 20, minSimilarity, nil)
+	return results, "vector", err
+}
+
+// preFilterArticlesBySimilarity keeps only the closest articles to the query so large fetches stay cheap in the relevancy prompt
+func (workflowExecutor *WorkflowExecutor) preFilterArticlesBySimilarity(articles []models.NewsArticle, queryEmbedding []float64) []models.NewsArticle {
+	limit := workflowExecutor.fetchLimits().RelevancyCandidates
+	if limit <= 0 || len(articles) <= limit {
+		return articles
+	}
+
+	embeddings, ok := workflowExecutor.workflowCtx.Metadata["fresh_article_embeddings"].([][]float64)
+	if !ok || len(embeddings) != len(articles) {
+		return articles
+	}
+
+	filtered := topArticlesBySimilarity(articles, embeddings, queryEmbedding, limit)
+
+	workflowExecutor.logger.Info("Pre-filtered articles by cosine similarity",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"before", len(articles),
+		"after", len(filtered))
+
+	return filtered
+}
+
 func (workflowExecutor *WorkflowExecutor) fallbackToFreshArticles(ctx context.Context) {
 	freshArticles, ok := workflowExecutor.workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle)
 
@@ -1517,23 +3061,204 @@ func (workflowExecutor *WorkflowExecutor) fallbackToFreshArticles(ctx context.Co
 	}
 
 	workflowExecutor.workflowCtx.Articles = fallbackArticles
+	workflowExecutor.workflowCtx.RecordFallback("vector_search")
 	workflowExecutor.logger.Warn("Using fallback articles due to vector search failure", "article_count", len(fallbackArticles))
 }
 
+// fetchStoreAndSearchArticlesAndVideos runs the fetch, embed, store and relevancy steps, retrying with a
+// broadened query while relevancy comes back empty and broaden attempts remain. Only the first pass can fail the
+// workflow, a broadened pass that fails for any reason puts the earlier results back.
 func (workflowExecutor *WorkflowExecutor) fetchStoreAndSearchArticlesAndVideos(ctx context.Context) error {
-	if err := workflowExecutor.fetchArticlesAndVideos(ctx); err != nil {
+	maxAttempts := workflowExecutor.orchestrator.config.Workflow.MaxBroadenAttempts
+
+	var snapshot *searchSnapshot
+	for attempt := 0; ; attempt++ {
+		if err := workflowExecutor.fetchStoreAndSearchOnce(ctx); err != nil {
+			if attempt == 0 {
+				return err
+			}
+			workflowExecutor.logger.WithError(err).Warn("Broadened news search failed, keeping earlier results")
+			snapshot.restore(workflowExecutor.workflowCtx)
+			workflowExecutor.recordFailedBroadening(attempt, err)
+			return nil
+		}
+
+		if empty, _ := workflowExecutor.workflowCtx.Metadata["relevancy_empty"].(bool); !empty || attempt >= maxAttempts || ctx.Err() != nil {
+			return nil
+		}
+
+		snapshot = takeSearchSnapshot(workflowExecutor.workflowCtx)
+		if err := workflowExecutor.broadenQuery(ctx, attempt+1); err != nil {
+			// the results of the narrow search stay in place
+			workflowExecutor.logger.WithError(err).Warn("Query broadening failed, keeping original results")
+			return nil
+		}
+	}
+}
+
+// searchStateKeys are the metadata entries a search pass writes, a failed broadened pass must not leave them
+// half replaced
+var searchStateKeys = []string{
+	"fresh_articles", "fresh_videos", "fresh_article_embeddings", "fresh_article_embedding_count",
+	"fresh_video_embedding_count", "query_embeddings", "embedding_scraped_articles", "relevant_articles",
+	"relevant_videos", "relevancy_empty", "scraped_with_relevancy", "articles_count", "videos_count",
+	"videos_filtered", "videos_from_cache", "stored_articles_count", "stored_videos_count", "diversity_dropped",
+	"article_search_mode", "fetch_limits", "news_error_class", "enhanced_query", "query_broadened",
+}
+
+// searchSnapshot is the search state of the last successful pass
+type searchSnapshot struct {
+	articles      []models.NewsArticle
+	videos        []models.YouTubeVideo
+	keywords      []string
+	enhancedQuery string
+	stats         models.ProcessingStats
+	metadata      map[string]any
+}
+
+func takeSearchSnapshot(workflowCtx *models.WorkflowContext) *searchSnapshot {
+	snapshot := &searchSnapshot{
+		articles:      workflowCtx.Articles,
+		videos:        workflowCtx.Videos,
+		keywords:      slices.Clone(workflowCtx.Keywords),
+		enhancedQuery: workflowCtx.EnhancedQuery,
+		stats:         workflowCtx.ProcessingStats,
+		metadata:      make(map[string]any, len(searchStateKeys)),
+	}
+	for _, key := range searchStateKeys {
+		if value, exists := workflowCtx.Metadata[key]; exists {
+			snapshot.metadata[key] = value
+		}
+	}
+	return snapshot
+}
+
+// restore puts the snapshot's search state back, the calls and cost of the failed pass stay counted
+func (snapshot *searchSnapshot) restore(workflowCtx *models.WorkflowContext) {
+	workflowCtx.Articles = snapshot.articles
+	workflowCtx.Videos = snapshot.videos
+	workflowCtx.Keywords = snapshot.keywords
+	workflowCtx.SetEnhancedQuery(snapshot.enhancedQuery)
+
+	stats := &workflowCtx.ProcessingStats
+	stats.ArticlesFound, stats.VideosFound = snapshot.stats.ArticlesFound, snapshot.stats.VideosFound
+	stats.ArticlesFiltered, stats.VideosFiltered = snapshot.stats.ArticlesFiltered, snapshot.stats.VideosFiltered
+	stats.EmbeddingsCount = snapshot.stats.EmbeddingsCount
+	stats.ScrapeAttempts, stats.ArticlesScraped = snapshot.stats.ScrapeAttempts, snapshot.stats.ArticlesScraped
+	stats.ScrapesSkipped = snapshot.stats.ScrapesSkipped
+
+	for _, key := range searchStateKeys {
+		if value, exists := snapshot.metadata[key]; exists {
+			workflowCtx.Metadata[key] = value
+		} else {
+			delete(workflowCtx.Metadata, key)
+		}
+	}
+}
+
+// recordFailedBroadening marks the broadening attempt whose search failed in "query_broadening"
+func (workflowExecutor *WorkflowExecutor) recordFailedBroadening(attempt int, err error) {
+	attempts, _ := workflowExecutor.workflowCtx.Metadata["query_broadening"].([]map[string]interface{})
+	if len(attempts) > 0 && attempts[len(attempts)-1]["attempt"] == attempt {
+		attempts[len(attempts)-1]["error"] = err.Error()
+		attempts[len(attempts)-1]["kept_earlier_results"] = true
+		return
+	}
+	workflowExecutor.workflowCtx.Metadata["query_broadening"] = append(attempts, map[string]interface{}{
+		"attempt":              attempt,
+		"error":                err.Error(),
+		"kept_earlier_results": true,
+	})
+}
+
+// broadenQuery re-runs query enhancement telling the model the current keywords found nothing relevant,
+// then extracts fresh keywords for the next fetch. Each attempt is recorded under "query_broadening".
+func (workflowExecutor *WorkflowExecutor) broadenQuery(ctx context.Context, attempt int) error {
+	startTime := time.Now()
+	previousKeywords := append([]string(nil), workflowExecutor.workflowCtx.Keywords...)
+	previousQuery := workflowExecutor.workflowCtx.EnhancedQuery
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "query_enhancer", models.AgentStatusProcessing,
+		"No relevant articles found, broadening the search"); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish query enhancer update")
+	}
+
+	contextMap := map[string]interface{}{
+		"intent":                workflowExecutor.workflowCtx.Intent,
+		"conversation_context":  workflowExecutor.workflowCtx.ConversationContext,
+		"user_preferences":      workflowExecutor.workflowCtx.ConversationContext.UserPreferences,
+		"broaden_from_keywords": previousKeywords,
+	}
+
+	enhancement, err := workflowExecutor.orchestrator.geminiService.EnhanceQueryForSearch(ctx, workflowExecutor.workflowCtx.OriginalQuery, contextMap)
+	if err != nil {
+		workflowExecutor.recordAgentExecution("query_broadener", time.Since(startTime), nil, nil, err)
+		return fmt.Errorf("query broadening failed: %w", err)
+	}
+	if enhancement.EnhancedQuery == "" || strings.EqualFold(enhancement.EnhancedQuery, previousQuery) {
+		workflowExecutor.recordAgentExecution("query_broadener", time.Since(startTime), nil, nil, nil)
+		return fmt.Errorf("broadened query is unchanged")
+	}
+	if !workflowExecutor.acceptEnhancedQuery(enhancement.EnhancedQuery, "query_broadener") {
+		workflowExecutor.recordAgentExecution("query_broadener", time.Since(startTime), nil, nil, nil)
+		return fmt.Errorf("broadened query is degenerate")
+	}
+	workflowExecutor.workflowCtx.ProcessingStats.APICallsCount++
+
+	workflowExecutor.workflowCtx.SetEnhancedQuery(enhancement.EnhancedQuery)
+	workflowExecutor.workflowCtx.Metadata["enhanced_query"] = enhancement.EnhancedQuery
+	workflowExecutor.workflowCtx.Keywords = nil
+
+	if err := workflowExecutor.extractKeywordsFromEnhancedQuery(ctx, enhancement.EnhancedQuery); err != nil {
+		workflowExecutor.workflowCtx.Keywords = previousKeywords
+		return err
+	}
+
+	attempts, _ := workflowExecutor.workflowCtx.Metadata["query_broadening"].([]map[string]interface{})
+	workflowExecutor.workflowCtx.Metadata["query_broadening"] = append(attempts, map[string]interface{}{
+		"attempt":           attempt,
+		"previous_query":    previousQuery,
+		"previous_keywords": previousKeywords,
+		"broadened_query":   enhancement.EnhancedQuery,
+		"keywords":          workflowExecutor.workflowCtx.Keywords,
+	})
+	workflowExecutor.workflowCtx.Metadata["query_broadened"] = true
+
+	workflowExecutor.recordAgentExecution("query_broadener", time.Since(startTime),
+		map[string]any{"attempt": attempt, "previous_keywords": len(previousKeywords)},
+		map[string]any{"keywords": len(workflowExecutor.workflowCtx.Keywords)}, nil)
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "query_enhancer", models.AgentStatusCompleted,
+		fmt.Sprintf("Broadened Query: %s", enhancement.EnhancedQuery)); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish query enhancer completion update")
+	}
+
+	workflowExecutor.logger.Info("Retrying news search with broadened query",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"attempt", attempt,
+		"previous_keywords", previousKeywords,
+		"keywords", workflowExecutor.workflowCtx.Keywords)
+
+	return nil
+}
+
+func (workflowExecutor *WorkflowExecutor) fetchStoreAndSearchOnce(ctx context.Context) error {
+	delete(workflowExecutor.workflowCtx.Metadata, "relevancy_empty")
+	delete(workflowExecutor.workflowCtx.Metadata, "scraped_with_relevancy")
+
+	if err := workflowExecutor.traceAgent(ctx, "news_fetch", workflowExecutor.fetchArticlesAndVideos); err != nil {
 		return fmt.Errorf("Fetching Fresh News Articles failed: %w", err)
 	}
 
-	if err := workflowExecutor.generateNewsAndVideoEmbeddings(ctx); err != nil {
+	if err := workflowExecutor.traceAgent(ctx, "embedding_generation", workflowExecutor.generateNewsAndVideoEmbeddings); err != nil {
 		return fmt.Errorf("Generate Fresh News Embeddings failed: %w", err)
 	}
 
-	if err := workflowExecutor.storeFreshArticlesAndVideos(ctx); err != nil {
+	if err := workflowExecutor.traceAgent(ctx, "vector_storage", workflowExecutor.storeFreshArticlesAndVideos); err != nil {
 		workflowExecutor.logger.WithError(err).Warn("Failed to store Fresh News Articles, proceeding without it")
 	}
 
-	if err := workflowExecutor.getRelevantArticlesAndVideos(ctx); err != nil {
+	if err := workflowExecutor.traceAgent(ctx, "relevancy_agent", workflowExecutor.getRelevantArticlesAndVideos); err != nil {
 		workflowExecutor.logger.WithError(err).Warn("Vector Search On ChromaDB failed, using fresh Articles only")
 		workflowExecutor.fallbackToFreshArticles(ctx)
 	}
@@ -1551,6 +3276,21 @@ func (orchestrator *Orchestrator) GetWorkflowStatus(workflowID string) (*models.
 	return orchestrator.redisService.GetWorkflowState(ctx, workflowID)
 }
 
+// YouTubeQuotaStatus reports the YouTube API quota circuit state for health checks
+func (orchestrator *Orchestrator) YouTubeQuotaStatus() string {
+	return orchestrator.youtubeService.QuotaStatus()
+}
+
+// AllowedPersonas lists the personas available to a tenant
+func (orchestrator *Orchestrator) AllowedPersonas(tenantID string) []string {
+	return orchestrator.personaPolicy.AllowedPersonas(tenantID)
+}
+
+// DefaultPersona returns the persona used for a tenant when none is requested
+func (orchestrator *Orchestrator) DefaultPersona(tenantID string) string {
+	return orchestrator.personaPolicy.DefaultPersona(tenantID)
+}
+
 func (orchestrator *Orchestrator) GetActiveWorkflowsCount() int {
 	count := 0
 	orchestrator.activeWorkflows.Range(func(_, _ interface{}) bool {
@@ -1560,24 +3300,56 @@ func (orchestrator *Orchestrator) GetActiveWorkflowsCount() int {
 	return count
 }
 
+// CancelWorkflow stops an in-flight workflow by cancelling its context, the workflow then winds down and reports itself cancelled
 func (orchestrator *Orchestrator) CancelWorkflow(workflowID string) error {
 	if workflow, exists := orchestrator.activeWorkflows.Load(workflowID); exists {
 		workflowCtx := workflow.(*models.WorkflowContext)
-		workflowCtx.MarkFailed()
-		orchestrator.activeWorkflows.Delete(workflowID)
 
-		orchestrator.logger.LogWorkflow(workflowID, workflowCtx.UserID, "workflow_cancelled", 0, nil)
+		if control, ok := orchestrator.workflowControls.Load(workflowID); ok {
+			control.(*workflowControl).cancelled.Store(true)
+			control.(*workflowControl).cancel()
+		} else {
+			workflowCtx.MarkCancelled()
+			orchestrator.activeWorkflows.Delete(workflowID)
+		}
+
+		orchestrator.logger.LogWorkflow(workflowID, workflowCtx.UserID, "workflow_cancel_requested", time.Since(workflowCtx.StartTime), nil)
 		return nil
 	}
 
 	return fmt.Errorf("workflow %s not found or not active", workflowID)
 }
 
+// DependencyChecks lists the health checks of every backing service, marking the ones startup cannot proceed without
+func (orchestrator *Orchestrator) DependencyChecks() []DependencyCheck {
+	checks := []DependencyCheck{
+		{Name: "redis", Check: orchestrator.redisService.HealthCheck},
+		{Name: "gemini", Check: orchestrator.geminiService.HealthCheck},
+		{Name: "ollama", Check: orchestrator.embeddings.HealthCheck},
+		{Name: "chromadb", Check: orchestrator.chromaDBService.HealthCheck},
+		{Name: "news", Check: orchestrator.newsService.HealthCheck},
+		{Name: "scraper", Check: orchestrator.scraperService.HealthCheck},
+	}
+
+	for i := range checks {
+		checks[i].Critical = orchestrator.config.Startup.IsCritical(checks[i].Name)
+	}
+	return checks
+}
+
+func (orchestrator *Orchestrator) MarkReady() {
+	orchestrator.ready.Store(true)
+}
+
+func (orchestrator *Orchestrator) IsReady() bool {
+	return orchestrator.ready.Load()
+}
+
 func (orchestrator *Orchestrator) HealthCheck(ctx context.Context) error {
 	services := map[string]func() error{
 		"redis":    func() error { return orchestrator.redisService.HealthCheck(ctx) },
 		"gemini":   func() error { return orchestrator.geminiService.HealthCheck(ctx) },
-		"ollama":   func() error { return orchestrator.ollamaService.HealthCheck(ctx) },
+		"ollama":   func() error { return orchestrator.embeddings.HealthCheck(ctx) },
 		"chromadb": func() error { return orchestrator.chromaDBService.HealthCheck(ctx) },
 		"news":     func() error { return orchestrator.newsService.HealthCheck(ctx) },
 		"scrapper": func() error { return orchestrator.scraperService.HealthCheck(ctx) },
@@ -1592,6 +3364,68 @@ func (orchestrator *Orchestrator) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// PreviewClassification runs only the intent classifier, seeing the same number of recent exchanges as a workflow would
+func (orchestrator *Orchestrator) PreviewClassification(ctx context.Context, query string, history []models.ConversationExchange) (*IntentClassificationResult, error) {
+	if len(history) > 3 {
+		history = history[len(history)-3:]
+	}
+	return orchestrator.geminiService.ClassifyIntentWithContext(ctx, query, history)
+}
+
+// recordUsage prices the workflow's gemini and embedding calls into its stats and the running totals
+func (orchestrator *Orchestrator) recordUsage(workflowCtx *models.WorkflowContext, meter *UsageMeter) {
+	usage := meter.Snapshot()
+	stats := &workflowCtx.ProcessingStats
+
+	stats.InputTokens, stats.OutputTokens, stats.EmbeddingRequests = 0, 0, 0
+	for model, modelUsage := range usage {
+		if model == orchestrator.config.Ollama.EmbeddingModel {
+			stats.EmbeddingRequests += modelUsage.Calls
+			continue
+		}
+		stats.InputTokens += modelUsage.InputTokens
+		stats.OutputTokens += modelUsage.OutputTokens
+	}
+	stats.TokensUsed = stats.InputTokens + stats.OutputTokens
+	stats.EstimatedCostUSD = orchestrator.costCalculator.Cost(usage)
+
+	orchestrator.costMicroUSD.Add(int64(stats.EstimatedCostUSD * 1e6))
+	orchestrator.inputTokens.Add(int64(stats.InputTokens))
+	orchestrator.outputTokens.Add(int64(stats.OutputTokens))
+
+	orchestrator.logger.Info("Workflow usage recorded",
+		"workflow_id", workflowCtx.ID,
+		"input_tokens", stats.InputTokens,
+		"output_tokens", stats.OutputTokens,
+		"embedding_requests", stats.EmbeddingRequests,
+		"estimated_cost_usd", stats.EstimatedCostUSD)
+}
+
+// costStats summarises token usage and estimated spend since startup
+func (orchestrator *Orchestrator) costStats() map[string]interface{} {
+	return map[string]interface{}{
+		"estimated_cost_usd": float64(orchestrator.costMicroUSD.Load()) / 1e6,
+		"input_tokens":       orchestrator.inputTokens.Load(),
+		"output_tokens":      orchestrator.outputTokens.Load(),
+	}
+}
+
+// qualityStats summarises quality scores since startup
+func (orchestrator *Orchestrator) qualityStats() map[string]interface{} {
+	scored := orchestrator.qualityScored.Load()
+	average := 0.0
+	if scored > 0 {
+		average = float64(orchestrator.qualityMilliSum.Load()) / 1000 / float64(scored)
+	}
+
+	return map[string]interface{}{
+		"scored_workflows": scored,
+		"average_score":    average,
+		"low_quality":      orchestrator.qualityLow.Load(),
+		"low_threshold":    orchestrator.config.Quality.LowThreshold,
+	}
+}
+
 func (orchestrator *Orchestrator) GetStats() map[string]interface{} {
 	uptime := time.Since(orchestrator.startTime)
 
@@ -1600,6 +3434,11 @@ func (orchestrator *Orchestrator) GetStats() map[string]interface{} {
 		"version":             "2.0",
 		"uptime_seconds":      uptime.Seconds(),
 		"active_workflows":    orchestrator.GetActiveWorkflowsCount(),
+		"empty_result_count":  orchestrator.emptyResults.Load(),
+		"quality":             orchestrator.qualityStats(),
+		"cost":                orchestrator.costStats(),
+		"gemini_concurrency":  orchestrator.geminiService.ConcurrencyStats(),
+		"workflow_admission":  orchestrator.AdmissionStats(),
 		"agent_configs":       len(orchestrator.agentConfigs),
 		"supported_workflows": []string{"news", "chitchat", "follow_up_discussion"},
 		"news_agents":         newsWorkflowAgents,
@@ -0,0 +1,1027 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestFetchArticlesAndVideosPassesConfiguredLimits(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Workflow.FetchLimits = map[string]config.FetchLimits{
+		"news":      {MaxArticles: 4, RecentMaxArticles: 3, MaxVideos: 2},
+		"follow_up": {MaxArticles: 2, RecentMaxArticles: 1, MaxVideos: 0},
+	}
+	orchestrator := newTestOrchestrator(t, cfg)
+	corpus := newTestCorpus("elections", 10, 5)
+	orchestrator.newsService = NewFixtureNewsService(corpus, orchestrator.logger)
+	orchestrator.youtubeService = NewFixtureYouTubeService(corpus, orchestrator.logger)
+
+	tests := []struct {
+		name         string
+		intent       models.Intent
+		metadata     map[string]interface{}
+		keywords     []string
+		wantArticles int
+		wantVideos   int
+	}{
+		{name: "keyword search uses max articles", keywords: []string{"elections"}, wantArticles: 4, wantVideos: 2},
+		{name: "recent news uses its own limit", wantArticles: 3, wantVideos: 2},
+		{name: "request overrides", keywords: []string{"elections"},
+			metadata: map[string]interface{}{"max_articles": 6, "max_videos": 1}, wantArticles: 6, wantVideos: 1},
+		{name: "videos can be turned off", keywords: []string{"elections"},
+			metadata: map[string]interface{}{"max_videos": 0}, wantArticles: 4, wantVideos: 0},
+		{name: "the workflow profile has its own limits", intent: models.IntentFollowUpDiscussion, keywords: []string{"elections"},
+			wantArticles: 2, wantVideos: 0},
+		{name: "profiles without limits use the news limits", intent: models.IntentChitChat, keywords: []string{"elections"},
+			wantArticles: 4, wantVideos: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "elections", Metadata: tt.metadata})
+			executor.workflowCtx.Keywords = tt.keywords
+			executor.workflowCtx.SetIntent(string(tt.intent))
+
+			if err := executor.fetchArticlesAndVideos(context.Background()); err != nil {
+				t.Fatalf("fetchArticlesAndVideos() error = %v", err)
+			}
+			if got := len(executor.workflowCtx.Articles); got != tt.wantArticles {
+				t.Errorf("articles = %d, want %d", got, tt.wantArticles)
+			}
+			if got := len(executor.workflowCtx.Videos); got != tt.wantVideos {
+				t.Errorf("videos = %d, want %d", got, tt.wantVideos)
+			}
+		})
+	}
+}
+
+func TestFetchLimitsAreKeyedByWorkflowProfile(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{
+		"NEWS_FETCH_MAX_ARTICLES": "50",
+		"WORKFLOW_FETCH_LIMITS":   "follow_up:max_articles=10,max_videos=0,relevancy_candidates=x",
+	})
+
+	if got, want := cfg.Workflow.FetchLimitsFor("follow_up"), (config.FetchLimits{MaxArticles: 10, MaxVideos: 0, RecentMaxArticles: 15, RelevancyCandidates: 30}); got != want {
+		t.Errorf("follow_up limits = %+v, want %+v", got, want)
+	}
+	if got, want := cfg.Workflow.FetchLimitsFor("chitchat"), (config.FetchLimits{MaxArticles: 50, MaxVideos: 8, RecentMaxArticles: 15, RelevancyCandidates: 30}); got != want {
+		t.Errorf("chitchat limits = %+v, want the news limits %+v", got, want)
+	}
+}
+
+func TestStoredEmbeddingsStayAvailableToThePreFilter(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Workflow.FetchLimits = map[string]config.FetchLimits{"news": {RelevancyCandidates: 2}}
+	orchestrator := newTestOrchestrator(t, cfg)
+	chroma, chromaDBService := newFakeChroma(t)
+	orchestrator.chromaDBService = chromaDBService
+
+	articles := newTestCorpus("markets", 4, 0).Articles
+	articles[2].Title, articles[2].Description = "rate cut decision", "central bank rate cut"
+	articles[3].Title, articles[3].Description = "rate cut reaction", "markets react to the rate cut"
+	embeddings := make([][]float64, len(articles))
+	for i, article := range articles {
+		embeddings[i] = fakeEmbedding(article.Title + " " + article.Description)
+	}
+
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "rate cut"})
+	executor.workflowCtx.Metadata["fresh_articles"] = articles
+	executor.workflowCtx.Metadata["fresh_article_embeddings"] = embeddings
+
+	if err := executor.storeFreshArticlesAndVideos(context.Background()); err != nil {
+		t.Fatalf("storeFreshArticlesAndVideos() error = %v", err)
+	}
+	if writes := chroma.writes(NewsCollectionName, false); len(writes) != 1 || len(writes[0].IDs) != len(articles) {
+		t.Fatalf("stored %v, want one add of %d articles", writes, len(articles))
+	}
+	if got := executor.workflowCtx.Metadata["fresh_article_embedding_count"]; got != len(articles) {
+		t.Errorf("fresh_article_embedding_count = %v, want %d", got, len(articles))
+	}
+
+	filtered := executor.preFilterArticlesBySimilarity(articles, fakeEmbedding("rate cut"))
+	if len(filtered) != 2 {
+		t.Fatalf("pre-filter kept %d articles, want 2", len(filtered))
+	}
+	for _, article := range filtered {
+		if article.ID != "article-2" && article.ID != "article-3" {
+			t.Errorf("pre-filter kept %s, want the rate cut articles", article.ID)
+		}
+	}
+}
+
+func TestParallelScrapeStartsBeforeVideoRelevancyFinishes(t *testing.T) {
+	scrapeStarted := make(chan struct{})
+	var startOnce sync.Once
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startOnce.Do(func() { close(scrapeStarted) })
+		w.Write([]byte("<html><body><article><p>" + strings.Repeat("The full story of the budget vote. ", 40) + "</p></article></body></html>"))
+	}))
+	defer page.Close()
+
+	cfg := config.Config{}
+	cfg.Workflow.ParallelScrape = true
+	cfg.Scraper = config.ScraperConfig{Timeout: 5 * time.Second, RetryAttempts: 1}
+	orchestrator := newTestOrchestrator(t, cfg)
+	_, orchestrator.chromaDBService = newFakeChroma(t)
+	scraper, err := NewScraperService(cfg.Scraper, nil, orchestrator.logger)
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	orchestrator.scraperService = scraper
+
+	// video relevancy only answers once the scrape reached the page, or gives up and reports it never did
+	var scrapedDuringVideoRelevancy atomic.Bool
+	_, orchestrator.geminiService = newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+		if strings.Contains(call.SystemPrompt, "video relevancy") {
+			select {
+			case <-scrapeStarted:
+				scrapedDuringVideoRelevancy.Store(true)
+			case <-time.After(5 * time.Second):
+			}
+			return `{"relevant_videos": []}`
+		}
+		return "not json, the fallback selection keeps the articles"
+	})
+
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "budget vote"})
+	article := newTestCorpus("budget vote", 1, 0).Articles[0]
+	article.URL, article.Content = page.URL+"/budget", ""
+	executor.workflowCtx.Metadata["query_embeddings"] = fakeEmbedding("budget vote")
+	executor.workflowCtx.Metadata["fresh_articles"] = []models.NewsArticle{article}
+	executor.workflowCtx.Videos = newTestCorpus("budget vote", 0, 1).Videos
+
+	if err := executor.getRelevantArticlesAndVideos(context.Background()); err != nil {
+		t.Fatalf("getRelevantArticlesAndVideos() error = %v", err)
+	}
+
+	if !scrapedDuringVideoRelevancy.Load() {
+		t.Error("scraper did not start before video relevancy finished")
+	}
+	if scraped, _ := executor.workflowCtx.Metadata["scraped_with_relevancy"].(bool); !scraped {
+		t.Error("scraped_with_relevancy = false, want true")
+	}
+	if len(executor.workflowCtx.Articles) != 1 || !strings.Contains(executor.workflowCtx.Articles[0].Content, "budget vote") {
+		t.Errorf("articles = %+v, want the scraped article", executor.workflowCtx.Articles)
+	}
+	if executor.workflowCtx.ProcessingStats.ScrapeAttempts != 1 {
+		t.Errorf("ScrapeAttempts = %d, want 1", executor.workflowCtx.ProcessingStats.ScrapeAttempts)
+	}
+	if _, ok := executor.workflowCtx.ProcessingStats.AgentStats["scrapper"]; !ok {
+		t.Error("scrapper agent stats were not recorded")
+	}
+}
+
+func TestEnhanceArticlesRefreshesEnrichedVectors(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><body><article><p>" + strings.Repeat("Flood waters receded across the valley. ", 40) + "</p></article></body></html>"))
+	}))
+	defer page.Close()
+
+	cfg := config.Config{}
+	cfg.Scraper = config.ScraperConfig{Timeout: 5 * time.Second, RetryAttempts: 1}
+	orchestrator := newTestOrchestrator(t, cfg)
+	chroma, chromaDBService := newFakeChroma(t)
+	chromaDBService.SetEmbeddingProvider(&fakeEmbeddings{})
+	orchestrator.chromaDBService = chromaDBService
+	scraper, err := NewScraperService(cfg.Scraper, nil, orchestrator.logger)
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	orchestrator.scraperService = scraper
+
+	article := newTestCorpus("flood", 1, 0).Articles[0]
+	article.URL, article.Content = page.URL+"/flood", ""
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "flood"})
+	executor.workflowCtx.Articles = []models.NewsArticle{article}
+	executor.workflowCtx.Metadata["stored_articles_count"] = 1
+
+	if err := executor.enhanceArticlesWithFullContent(context.Background()); err != nil {
+		t.Fatalf("enhanceArticlesWithFullContent() error = %v", err)
+	}
+	if executor.workflowCtx.ProcessingStats.ArticlesScraped != 1 {
+		t.Fatalf("ArticlesScraped = %d, want 1", executor.workflowCtx.ProcessingStats.ArticlesScraped)
+	}
+
+	// the refresh runs in the background
+	deadline := time.Now().Add(5 * time.Second)
+	for len(chroma.writes(NewsCollectionName, true)) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	upserts := chroma.writes(NewsCollectionName, true)
+	if len(upserts) != 1 || upserts[0].IDs[0] != article.ID || !strings.Contains(upserts[0].Documents[0], "Flood waters receded") {
+		t.Errorf("upserts = %+v, want the scraped article refreshed", upserts)
+	}
+}
+
+func TestNewsWorkflowTracesEveryAgent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "wildfire", models.IntentNewNewsQuery)
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-1", Query: "what is happening with the wildfire",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	root, ok := spans["workflow.execute"]
+	if !ok {
+		t.Fatal("no workflow.execute span was recorded")
+	}
+
+	for _, agent := range []string{"memory", "classifier", "query_enhancer", "keyword_extractor", "news_fetch",
+		"embedding_generation", "vector_storage", "relevancy_agent", "scraper", "summarizer", "persona"} {
+		span, ok := spans["agent."+agent]
+		if !ok {
+			t.Errorf("no span for agent %s", agent)
+			continue
+		}
+		if span.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("agent %s span is not part of the workflow trace", agent)
+		}
+		if !hasSpanAttribute(span, "request_id", response.RequestID) {
+			t.Errorf("agent %s span does not carry request id %s", agent, response.RequestID)
+		}
+	}
+}
+
+func hasSpanAttribute(span sdktrace.ReadOnlySpan, key, value string) bool {
+	for _, attribute := range span.Attributes() {
+		if string(attribute.Key) == key && attribute.Value.AsString() == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWorkflowCompletesStatelesslyWhenRedisIsDown(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-stateless", Query: "who is ahead in the elections",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Status != "completed" || response.Message == "" {
+		t.Fatalf("response = %s %q, want a completed answer", response.Status, response.Message)
+	}
+	if !response.Stateless {
+		t.Error("response is not flagged stateless")
+	}
+	if len(response.Sources) == 0 {
+		t.Error("stateless workflow returned no sources")
+	}
+
+	// every update the client could not stream comes back with the response, workflow milestones included
+	agents := make(map[string]bool)
+	for _, update := range response.Updates {
+		agents[update.AgentName] = true
+	}
+	for _, want := range []string{string(models.UpdateTypeWorkflowStarted), "memory", "classifier", "summarizer",
+		string(models.UpdateTypeWorkflowCompleted)} {
+		if !agents[want] {
+			t.Errorf("buffered updates are missing %s", want)
+		}
+	}
+}
+
+func TestWorkflowDeadlineTimesOutTheWorkflow(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"WORKFLOW_DEADLINE": "300ms"})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	// the summarizer never answers in time and its failure ends the pipeline
+	workflow.holdAgent(t, "Multimedia News Synthesizer")
+
+	started := time.Now()
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-deadline", Query: "who is ahead in the elections",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("ExecuteWorkflow() took %s, want it bounded by the deadline", elapsed)
+	}
+	if response.Status != string(models.WorkflowStatusTimeout) {
+		t.Fatalf("status = %s, want %s", response.Status, models.WorkflowStatusTimeout)
+	}
+	if response.Message != "Workflow timed out after 300ms" {
+		t.Errorf("message = %q, want the timeout notice while nothing was written", response.Message)
+	}
+
+	var timedOut bool
+	for _, update := range response.Updates {
+		timedOut = timedOut || update.AgentName == string(models.UpdateTypeWorkflowTimeout)
+	}
+	if !timedOut {
+		t.Error("buffered updates are missing workflow_timeout")
+	}
+}
+
+func TestWorkflowThatFinishesPastTheDeadlineCompletes(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"WORKFLOW_DEADLINE": "300ms"})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	// the persona runs out of time, the pipeline answers with the summary it already wrote
+	workflow.holdAgent(t, "Content Personalizer")
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-deadline", Query: "who is ahead in the elections",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("status = %s, want %s", response.Status, models.WorkflowStatusCompleted)
+	}
+	if !strings.Contains(response.Message, "elections story in brief") {
+		t.Errorf("message = %q, want the summary written before the deadline", response.Message)
+	}
+}
+
+func TestCallerDeadlineIsNotAWorkflowTimeout(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	workflow.holdAgent(t, "Multimedia News Synthesizer")
+
+	// the caller's deadline is shorter than the workflow's own
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	response, err := workflow.orchestrator.ExecuteWorkflow(ctx, &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-caller-deadline", Query: "who is ahead in the elections",
+	})
+	if err == nil {
+		t.Fatal("ExecuteWorkflow() succeeded after the caller's deadline ended the pipeline")
+	}
+	if response.Status != string(models.WorkflowStatusFailed) {
+		t.Errorf("status = %s, want %s", response.Status, models.WorkflowStatusFailed)
+	}
+}
+
+func TestNewsWorkflowRecordsAgentExecutions(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-executions")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	executions := make(map[string]models.AgentExecution)
+	for _, execution := range response.AgentExecutions {
+		executions[execution.AgentName] = execution
+	}
+	for _, agent := range []string{"memory", "classifier", "query_enhancer", "keyword_extractor", "news_fetch",
+		"embedding_generation", "vector_storage", "relevancy_agent", "scrapper", "summarizer", "persona"} {
+		execution, ok := executions[agent]
+		if !ok {
+			t.Errorf("no execution recorded for agent %s", agent)
+			continue
+		}
+		if execution.Status != "success" || execution.ErrorMessage != "" {
+			t.Errorf("agent %s status = %s %q, want success", agent, execution.Status, execution.ErrorMessage)
+		}
+	}
+
+	// inputs and outputs are counts, the query itself never leaves in the trace
+	if got := executions["keyword_extractor"].Output["keywords"]; got == nil || got == 0 {
+		t.Errorf("keyword_extractor output = %v, want the keyword count", executions["keyword_extractor"].Output)
+	}
+	for _, execution := range response.AgentExecutions {
+		for _, value := range execution.Input {
+			if text, ok := value.(string); ok && strings.Contains(text, "what is the latest") {
+				t.Errorf("agent %s input leaks the query: %v", execution.AgentName, execution.Input)
+			}
+		}
+	}
+}
+
+func TestFailedAgentExecutionIsRecordedAsAnError(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"GEMINI_MAX_RETRIES": "1"})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	workflow.failAgent("Content Personalizer")
+
+	// the workflow falls back to the summary, the trace still shows the persona agent failed
+	response, err := workflow.run("workflow-failed-execution")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	var persona *models.AgentExecution
+	for i := range response.AgentExecutions {
+		if response.AgentExecutions[i].AgentName == "persona" {
+			persona = &response.AgentExecutions[i]
+		}
+	}
+	if persona == nil {
+		t.Fatal("no execution recorded for the failed persona agent")
+	}
+	if persona.Status != "error" || persona.ErrorMessage == "" {
+		t.Errorf("persona status = %s %q, want error with its message", persona.Status, persona.ErrorMessage)
+	}
+}
+
+func TestExplainModeReturnsTheClassifierReasoning(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-explain", Query: "who is ahead in the elections",
+		Metadata: map[string]interface{}{"explain": true},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	explanation := response.Explanation
+	if explanation == nil {
+		t.Fatal("explain mode returned no explanation")
+	}
+	if explanation.Intent != string(models.IntentNewNewsQuery) || explanation.Confidence != 0.95 {
+		t.Errorf("explanation intent = %s %.2f, want %s 0.95", explanation.Intent, explanation.Confidence, models.IntentNewNewsQuery)
+	}
+	if explanation.Reasoning != "test classification" {
+		t.Errorf("explanation reasoning = %q, want the classifier's", explanation.Reasoning)
+	}
+	if explanation.ReferencedTopic != "" || explanation.ReferencedExchangeID != "" {
+		t.Errorf("new news query references %q %q, want no prior exchange", explanation.ReferencedTopic, explanation.ReferencedExchangeID)
+	}
+	if len(explanation.AgentSequence) < 2 || explanation.AgentSequence[0] != "memory" || explanation.AgentSequence[1] != "classifier" {
+		t.Errorf("agent sequence = %v, want it to start with memory and classifier", explanation.AgentSequence)
+	}
+	if !slices.Contains(explanation.AgentSequence, "summarizer") {
+		t.Errorf("agent sequence = %v, want the summarizer", explanation.AgentSequence)
+	}
+}
+
+func TestExplainModeIsOffByDefault(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-no-explain")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if response.Explanation != nil {
+		t.Errorf("explanation = %+v, want none without explain mode", response.Explanation)
+	}
+}
+
+func TestExplainModeNamesTheReferencedExchange(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentFollowUpDiscussion)
+	workflow.answerAgent("intent classifier", func(fakeGeminiCall) string {
+		return `{"intent": "FOLLOW_UP_DISCUSSION", "confidence": 0.9, "reasoning": "asks about the last answer",
+			"referenced_topic": "elections", "referenced_exchange_id": "exchange-7"}`
+	})
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-explain-follow-up", Query: "why does that matter",
+		Metadata: map[string]interface{}{"explain": true},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	explanation := response.Explanation
+	if explanation == nil {
+		t.Fatal("explain mode returned no explanation")
+	}
+	if explanation.ReferencedTopic != "elections" || explanation.ReferencedExchangeID != "exchange-7" {
+		t.Errorf("explanation references %q %q, want elections exchange-7", explanation.ReferencedTopic, explanation.ReferencedExchangeID)
+	}
+	if explanation.Reasoning != "asks about the last answer" {
+		t.Errorf("explanation reasoning = %q, want the classifier's", explanation.Reasoning)
+	}
+}
+
+func TestIntermediateOutputsAreReturnedOnRequest(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-intermediate", Query: "what is the latest on elections",
+		Metadata: map[string]interface{}{"include_intermediate": true},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	intermediate := response.Intermediate
+	if intermediate == nil {
+		t.Fatal("include_intermediate returned no intermediate outputs")
+	}
+	if intermediate.EnhancedQuery == "" || len(intermediate.Keywords) == 0 {
+		t.Errorf("query expansion = %q %v, want the enhanced query and keywords", intermediate.EnhancedQuery, intermediate.Keywords)
+	}
+	if intermediate.ArticlesFound != len(workflow.corpus.Articles) || intermediate.ArticlesFiltered == 0 ||
+		intermediate.ArticlesFiltered > intermediate.ArticlesFound {
+		t.Errorf("articles found %d filtered %d, want %d found and some of them kept", intermediate.ArticlesFound,
+			intermediate.ArticlesFiltered, len(workflow.corpus.Articles))
+	}
+	if intermediate.Summary != "The elections story in brief, drawn from the articles and videos." {
+		t.Errorf("summary = %q, want the summarizer's text before the persona rewrite", intermediate.Summary)
+	}
+	if intermediate.Response != response.Message || intermediate.Response == intermediate.Summary {
+		t.Errorf("response = %q, want the persona's answer %q", intermediate.Response, response.Message)
+	}
+}
+
+func TestIntermediateOutputsAreOffByDefault(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-no-intermediate")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if response.Intermediate != nil {
+		t.Errorf("intermediate = %+v, want none unless requested", response.Intermediate)
+	}
+}
+
+func TestNoPersonaReturnsTheRawSummary(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-no-persona", Query: "who is ahead in the elections",
+		UserPreferences: models.UserPreferences{NewsPersonality: models.NoPersona},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Message != "The elections story in brief, drawn from the articles and videos." {
+		t.Errorf("message = %q, want the summarizer's text untouched", response.Message)
+	}
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Content Personalizer") {
+			t.Error("persona agent was called for a request without a persona")
+		}
+	}
+	for _, execution := range response.AgentExecutions {
+		if execution.AgentName == "persona" {
+			t.Errorf("persona execution recorded: %+v", execution)
+		}
+	}
+
+	// progress is measured against the agents that run, so the summarizer finishing completes it
+	var summarized bool
+	for _, update := range response.Updates {
+		if sequence, ok := update.Data["agent_sequence"].([]string); ok && slices.Contains(sequence, "persona") {
+			t.Errorf("%s update lists persona in its agent sequence %v", update.AgentName, sequence)
+		}
+		if update.AgentName == "summarizer" && update.Status == models.AgentStatusCompleted {
+			summarized = true
+			if update.Progress != 1 {
+				t.Errorf("summarizer completed at progress %v, want 1", update.Progress)
+			}
+		}
+	}
+	if !summarized {
+		t.Error("buffered updates are missing the summarizer's completion")
+	}
+}
+
+func TestEmptyResultNoticeIsLocalizedAndPersonalized(t *testing.T) {
+	orchestrator := newTestOrchestrator(t, config.Config{})
+	var gemini *fakeGemini
+	gemini, orchestrator.geminiService = newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+		return "¡Vaya! No encontré noticias sobre esto, prueba con otra pregunta."
+	})
+
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "noticias del eclipse"})
+	executor.workflowCtx.ConversationContext.UserPreferences.Language = "es"
+	executor.workflowCtx.ConversationContext.UserPreferences.NewsPersonality = "friendly-explainer"
+
+	if err := executor.generateSummary(context.Background()); err != nil {
+		t.Fatalf("generateSummary() error = %v", err)
+	}
+	if err := executor.ApplyPersonality(context.Background()); err != nil {
+		t.Fatalf("ApplyPersonality() error = %v", err)
+	}
+
+	// nothing to summarize, so the only gemini call is the persona restyling the spanish notice
+	calls := gemini.received()
+	if len(calls) != 1 {
+		t.Fatalf("gemini received %d calls, want only the persona call", len(calls))
+	}
+	if !strings.Contains(calls[0].Prompt, "No encontré artículos ni videos") {
+		t.Errorf("persona prompt does not carry the spanish notice:\n%s", calls[0].Prompt)
+	}
+	if !strings.Contains(calls[0].Prompt, "Do NOT add, invent or imply any news") {
+		t.Error("persona prompt is missing the no results guard")
+	}
+	if executor.workflowCtx.Response != "¡Vaya! No encontré noticias sobre esto, prueba con otra pregunta." {
+		t.Errorf("response = %q, want the persona's notice", executor.workflowCtx.Response)
+	}
+	if count := orchestrator.GetStats()["empty_result_count"]; count != int64(1) {
+		t.Errorf("empty_result_count = %v, want 1", count)
+	}
+}
+
+func TestWorkflowThreadsTheUsersLocaleToTheSummarizer(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	_, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-locale", Query: "election results",
+		UserPreferences: models.UserPreferences{Region: "IN", Timezone: "Asia/Kolkata"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") {
+			if !strings.Contains(call.Prompt, `region "IN"`) || !strings.Contains(call.Prompt, "IST") {
+				t.Errorf("summarizer prompt lacks the indian locale or local publish times:\n%s", call.Prompt)
+			}
+			return
+		}
+	}
+	t.Fatal("the summarizer was never called")
+}
+
+func TestFetchedArticlesAreStoredWithTheirCategory(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "election", models.IntentNewNewsQuery)
+
+	if _, err := workflow.run("workflow-categories"); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	var stored int
+	for _, writes := range [][]AddRequest{workflow.chroma.writes(NewsCollectionName, false), workflow.chroma.writes(NewsCollectionName, true)} {
+		for _, write := range writes {
+			for i, metadata := range write.Metadatas {
+				stored++
+				if metadata["category"] != "politics" {
+					t.Errorf("article %s stored with category %v, want politics", write.IDs[i], metadata["category"])
+				}
+			}
+		}
+	}
+	if stored == 0 {
+		t.Fatal("no articles were stored")
+	}
+}
+
+func TestFreshArticlesAreUsedWhenNothingStoredClearsTheSimilarityFloor(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Etc.ChromaMinSimilarity = 0.3
+	orchestrator := newTestOrchestrator(t, cfg)
+	var chroma *fakeChroma
+	chroma, orchestrator.chromaDBService = newFakeChroma(t)
+	chroma.queryResponses[NewsCollectionName] = rankedQueryResponse(0.9, 0.95)
+	chroma.queryResponses[VideosCollectionName] = rankedQueryResponse(0.9)
+
+	var gemini *fakeGemini
+	gemini, orchestrator.geminiService = newFakeGemini(t, config.GeminiConfig{}, func(fakeGeminiCall) string {
+		return `{"relevant_videos": []}`
+	})
+	gemini.fails = func(call fakeGeminiCall) bool { return strings.Contains(call.SystemPrompt, "news relevancy") }
+
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "budget vote"})
+	fresh := newTestCorpus("budget vote", 2, 0).Articles
+	executor.workflowCtx.Metadata["query_embeddings"] = fakeEmbedding("budget vote")
+	executor.workflowCtx.Metadata["fresh_articles"] = fresh
+
+	if err := executor.getRelevantArticlesAndVideos(context.Background()); err != nil {
+		t.Fatalf("getRelevantArticlesAndVideos() error = %v", err)
+	}
+
+	var ids []string
+	for _, article := range executor.workflowCtx.Articles {
+		ids = append(ids, article.ID)
+	}
+	if len(ids) != len(fresh) || ids[0] != fresh[0].ID || ids[1] != fresh[1].ID {
+		t.Errorf("articles = %v, want the fresh articles instead of the weak stored matches", ids)
+	}
+}
+
+func TestFollowUpSearchesBlendInRecentKeywords(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Workflow.RecentKeywordBlend = 3
+	orchestrator := newTestOrchestrator(t, cfg)
+	recent := []string{"tariffs", "Budget", "inflation", "rate cut"}
+
+	tests := []struct {
+		name   string
+		intent *IntentClassificationResult
+		want   []string
+	}{
+		{name: "follow-up on a topic", intent: &IntentClassificationResult{Intent: "NEWS", ReferencedTopic: "the budget"},
+			want: []string{"budget", "rate cut", "inflation"}},
+		{name: "follow-up on an exchange", intent: &IntentClassificationResult{Intent: "NEWS", ReferencedExchangeID: "exchange-3"},
+			want: []string{"budget", "rate cut", "inflation"}},
+		{name: "new question", intent: &IntentClassificationResult{Intent: "NEWS"},
+			want: []string{"budget"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "more on the budget"})
+			executor.workflowCtx.Keywords = []string{"budget"}
+			executor.workflowCtx.ConversationContext.RecentKeywords = recent
+
+			executor.blendRecentKeywords(tt.intent)
+
+			if !slices.Equal(executor.workflowCtx.Keywords, tt.want) {
+				t.Errorf("keywords = %v, want %v", executor.workflowCtx.Keywords, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictSourcesPreferenceOverridesTheConfiguredDefault(t *testing.T) {
+	strict, lenient := true, false
+	tests := []struct {
+		name       string
+		configured bool
+		preference *bool
+		want       bool
+	}{
+		{name: "configured default", configured: true, want: true},
+		{name: "user opts out", configured: true, preference: &lenient, want: false},
+		{name: "user opts in", configured: false, preference: &strict, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Workflow.StrictSourcesOnly = tt.configured
+			executor := newTestExecutor(t, newTestOrchestrator(t, cfg), models.WorkflowRequest{Query: "rates"})
+			executor.workflowCtx.ConversationContext.UserPreferences.StrictSourcesOnly = tt.preference
+
+			if got := executor.strictSourcesOnly(); got != tt.want {
+				t.Errorf("strictSourcesOnly() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombinedQueryProcessingToggle(t *testing.T) {
+	respond := func(call fakeGeminiCall) string {
+		switch {
+		case strings.Contains(call.SystemPrompt, "Query Expansion and Keyword Extraction"):
+			return `{"enhanced_query": "central bank interest rate decision", "keywords": ["central bank", "interest rates"]}`
+		case strings.Contains(call.SystemPrompt, "Query Expansion"):
+			return "ENHANCED_QUERY: central bank interest rate decision"
+		default:
+			return "central bank, interest rates"
+		}
+	}
+	tests := []struct {
+		name      string
+		combined  bool
+		wantCalls int
+	}{
+		{name: "combined", combined: true, wantCalls: 1},
+		{name: "separate", combined: false, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Workflow.CombinedQueryProcessing = tt.combined
+			orchestrator := newTestOrchestrator(t, cfg)
+			var gemini *fakeGemini
+			gemini, orchestrator.geminiService = newFakeGemini(t, config.GeminiConfig{}, respond)
+			executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "what did the rbi do"})
+
+			if err := executor.executeSequentialQueryProcessing(context.Background(), &IntentClassificationResult{Intent: "NEWS"}); err != nil {
+				t.Fatalf("executeSequentialQueryProcessing() error = %v", err)
+			}
+
+			if calls := gemini.received(); len(calls) != tt.wantCalls {
+				t.Errorf("gemini calls = %d, want %d", len(calls), tt.wantCalls)
+			}
+			if executor.workflowCtx.EnhancedQuery != "central bank interest rate decision" {
+				t.Errorf("enhanced query = %q", executor.workflowCtx.EnhancedQuery)
+			}
+			if !slices.Equal(executor.workflowCtx.Keywords, []string{"central bank", "interest rates"}) {
+				t.Errorf("keywords = %v", executor.workflowCtx.Keywords)
+			}
+		})
+	}
+}
+
+func TestCombinedQueryProcessingFallsBackToSeparateCalls(t *testing.T) {
+	cfg := config.Config{}
+	cfg.Workflow.CombinedQueryProcessing = true
+	orchestrator := newTestOrchestrator(t, cfg)
+	var gemini *fakeGemini
+	gemini, orchestrator.geminiService = newFakeGemini(t, config.GeminiConfig{}, func(call fakeGeminiCall) string {
+		switch {
+		case strings.Contains(call.SystemPrompt, "Query Expansion and Keyword Extraction"):
+			return `{"enhanced_query": "central bank interest rate decision", "keywords": []}`
+		case strings.Contains(call.SystemPrompt, "Query Expansion"):
+			return "ENHANCED_QUERY: central bank interest rate decision"
+		default:
+			return "central bank, interest rates"
+		}
+	})
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "what did the rbi do"})
+
+	if err := executor.executeSequentialQueryProcessing(context.Background(), &IntentClassificationResult{Intent: "NEWS"}); err != nil {
+		t.Fatalf("executeSequentialQueryProcessing() error = %v", err)
+	}
+
+	if calls := gemini.received(); len(calls) != 3 {
+		t.Errorf("gemini calls = %d, want the combined call then the two separate calls", len(calls))
+	}
+	if !slices.Equal(executor.workflowCtx.Keywords, []string{"central bank", "interest rates"}) {
+		t.Errorf("keywords = %v, want the separately extracted keywords", executor.workflowCtx.Keywords)
+	}
+}
+
+func TestIdleConversationStartsANewSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		idle      time.Duration
+		wantReset bool
+	}{
+		{name: "returning the next day", idle: 20 * time.Hour, wantReset: true},
+		{name: "within the session", idle: time.Hour, wantReset: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Workflow.SessionIdleTimeout = 6 * time.Hour
+			orchestrator := newTestOrchestrator(t, cfg)
+			_, orchestrator.redisService = newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second, MaxContextBytes: 1 << 20})
+
+			lastActive := time.Now().Add(-tt.idle).Truncate(time.Second)
+			stored := &models.ConversationContext{
+				UserID:              "user-1",
+				Exchanges:           []models.ConversationExchange{{ID: "exchange-1", Timestamp: lastActive, UserQuery: "budget vote"}},
+				TotalExchanges:      1,
+				CurrentTopics:       []string{"budget"},
+				RecentKeywords:      []string{"budget vote"},
+				LastReferencedTopic: "budget",
+				LastQuery:           "budget vote",
+				ContextSummary:      "- asked about the budget vote",
+				SessionStartTime:    lastActive.Add(-time.Hour),
+				LastActiveTime:      lastActive,
+			}
+			if err := orchestrator.redisService.StoreConversationContext(context.Background(), "user-1", stored); err != nil {
+				t.Fatalf("StoreConversationContext() error = %v", err)
+			}
+
+			executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{UserID: "user-1", Query: "tell me more"})
+			executor.workflowCtx.Stateless = false
+			executor.workflowCtx.ConversationContext.UserPreferences = models.UserPreferences{NewsPersonality: "calm-anchor", Language: "es"}
+
+			if err := executor.executeEnhancedMemoryAgent(context.Background()); err != nil {
+				t.Fatalf("executeEnhancedMemoryAgent() error = %v", err)
+			}
+
+			conversation := executor.workflowCtx.ConversationContext
+			if reset, _ := executor.workflowCtx.Metadata["session_reset"].(bool); reset != tt.wantReset {
+				t.Fatalf("session_reset = %t, want %t", reset, tt.wantReset)
+			}
+			if conversation.UserPreferences.NewsPersonality != "calm-anchor" || conversation.UserPreferences.Language != "es" {
+				t.Errorf("preferences = %+v, want them kept", conversation.UserPreferences)
+			}
+			if len(conversation.Exchanges) != 1 || conversation.ContextSummary != stored.ContextSummary {
+				t.Errorf("long-term history = %d exchanges and summary %q, want it kept", len(conversation.Exchanges), conversation.ContextSummary)
+			}
+
+			if !tt.wantReset {
+				if len(conversation.CurrentTopics) != 1 || conversation.LastReferencedTopic != "budget" || !conversation.HasPreviousExchanges() {
+					t.Errorf("session state = %v %q, want the active session kept", conversation.CurrentTopics, conversation.LastReferencedTopic)
+				}
+				return
+			}
+			if len(conversation.CurrentTopics) != 0 || len(conversation.RecentKeywords) != 0 || conversation.LastReferencedTopic != "" || conversation.LastQuery != "" {
+				t.Errorf("session state = %v %v %q %q, want it cleared", conversation.CurrentTopics, conversation.RecentKeywords, conversation.LastReferencedTopic, conversation.LastQuery)
+			}
+			if conversation.HasPreviousExchanges() || conversation.GetLastExchange() != nil {
+				t.Error("exchanges from the previous session still count as recent")
+			}
+			if previous, _ := executor.workflowCtx.Metadata["previous_session_start"].(time.Time); !previous.Equal(stored.SessionStartTime) {
+				t.Errorf("previous_session_start = %v, want %v", previous, stored.SessionStartTime)
+			}
+		})
+	}
+}
+
+func TestFailedBroadenedSearchKeepsTheNarrowResults(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"MAX_BROADEN_ATTEMPTS": "1"})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	embeddings := &fakeEmbeddings{}
+	workflow.orchestrator.SetEmbeddingProvider(embeddings)
+	workflow.answerAgent("news relevancy", func(fakeGeminiCall) string { return `{"relevant_articles": []}` })
+	workflow.answerAgent("Query Expansion", func(call fakeGeminiCall) string {
+		if strings.Contains(call.SystemPrompt+call.Prompt, "BROADEN THE SEARCH") {
+			// ollama goes down between the narrow and the broadened pass
+			embeddings.mu.Lock()
+			embeddings.err = errors.New("ollama unavailable")
+			embeddings.mu.Unlock()
+			return "ENHANCED_QUERY: elections news"
+		}
+		return "ENHANCED_QUERY: latest elections news"
+	})
+
+	response, err := workflow.run("workflow-broaden-fails")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v, want the narrow results kept", err)
+	}
+	if response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("status = %s, want completed", response.Status)
+	}
+
+	// the search state is the narrow pass's, not a mix of both passes
+	embeddings.mu.Lock()
+	embeddings.err = nil
+	embeddings.mu.Unlock()
+	executor := newTestExecutor(t, workflow.orchestrator, models.WorkflowRequest{UserID: "user-1", Query: "what is the latest on elections"})
+	executor.workflowCtx.Intent = string(models.IntentNewNewsQuery)
+	executor.workflowCtx.SetEnhancedQuery("latest elections news")
+	executor.workflowCtx.Keywords = []string{"elections"}
+	if err := executor.fetchStoreAndSearchArticlesAndVideos(context.Background()); err != nil {
+		t.Fatalf("fetchStoreAndSearchArticlesAndVideos() error = %v, want the narrow results kept", err)
+	}
+
+	workflowCtx := executor.workflowCtx
+	if workflowCtx.EnhancedQuery != "latest elections news" || !slices.Equal(workflowCtx.Keywords, []string{"elections"}) {
+		t.Errorf("query = %q %v, want the narrow query back", workflowCtx.EnhancedQuery, workflowCtx.Keywords)
+	}
+	if empty, _ := workflowCtx.Metadata["relevancy_empty"].(bool); !empty {
+		t.Error("relevancy_empty is gone, want the narrow pass's outcome restored")
+	}
+	if fresh, _ := workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle); len(fresh) == 0 {
+		t.Error("fresh_articles is empty, want the narrow pass's articles")
+	}
+	if embedded, _ := workflowCtx.Metadata["fresh_article_embeddings"].([][]float64); len(embedded) == 0 {
+		t.Error("fresh_article_embeddings is empty, want the narrow pass's embeddings")
+	}
+	if _, broadened := workflowCtx.Metadata["query_broadened"]; broadened {
+		t.Error("query_broadened is set, want the narrow search state")
+	}
+	attempts, _ := workflowCtx.Metadata["query_broadening"].([]map[string]interface{})
+	if len(attempts) != 1 || attempts[0]["error"] == nil {
+		t.Errorf("query_broadening = %v, want the failed attempt recorded", attempts)
+	}
+}
+
+func TestEmptyRelevancyRetriesOnceWithABroadenedQuery(t *testing.T) {
+	tests := []struct {
+		attempts     string
+		wantBroadens int
+	}{
+		{attempts: "1", wantBroadens: 1},
+		{attempts: "0", wantBroadens: 0},
+	}
+	for _, tt := range tests {
+		t.Run("max attempts "+tt.attempts, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{"MAX_BROADEN_ATTEMPTS": tt.attempts})
+			workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+			// nothing the search finds is ever relevant, so every attempt comes back empty
+			workflow.answerAgent("news relevancy", func(fakeGeminiCall) string { return `{"relevant_articles": []}` })
+			workflow.answerAgent("Query Expansion", func(call fakeGeminiCall) string {
+				if strings.Contains(call.SystemPrompt+call.Prompt, "BROADEN THE SEARCH") {
+					return "ENHANCED_QUERY: elections news"
+				}
+				return "ENHANCED_QUERY: latest elections news"
+			})
+
+			response, err := workflow.run("workflow-broaden-" + tt.attempts)
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var broadenPrompts int
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt+call.Prompt, "BROADEN THE SEARCH") {
+					broadenPrompts++
+					if !strings.Contains(call.SystemPrompt+call.Prompt, `keywords "elections"`) {
+						t.Error("broadening prompt does not name the keywords that found nothing")
+					}
+				}
+			}
+			executions := make(map[string]int)
+			for _, execution := range response.AgentExecutions {
+				executions[execution.AgentName]++
+			}
+			if broadenPrompts != tt.wantBroadens || executions["query_broadener"] != tt.wantBroadens {
+				t.Errorf("broadened %d times with %d query_broadener executions, want %d",
+					broadenPrompts, executions["query_broadener"], tt.wantBroadens)
+			}
+			if executions["news_fetch"] != tt.wantBroadens+1 {
+				t.Errorf("news fetched %d times, want %d", executions["news_fetch"], tt.wantBroadens+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownCodeFence  = regexp.MustCompile("(?m)^[ \\t]*```[a-zA-Z0-9_-]*[ \\t]*$")
+	markdownHeading    = regexp.MustCompile(`(?m)^[ \t]{0,3}#{1,6}[ \t]+`)
+	markdownRule       = regexp.MustCompile(`(?m)^[ \t]*([-*_][ \t]*){3,}$`)
+	markdownBlockquote = regexp.MustCompile(`(?m)^[ \t]*>[ \t]?`)
+	markdownBullet     = regexp.MustCompile(`(?m)^([ \t]*)[-*+•][ \t]+`)
+	markdownImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	markdownBold       = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	markdownItalicStar = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	markdownItalicBar  = regexp.MustCompile(`(^|[^\w])_(\S(?:[^_]*?\S)?)_([^\w]|$)`)
+	markdownStrike     = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	markdownInlineCode = regexp.MustCompile("`([^`]*)`")
+	extraBlankLines    = regexp.MustCompile(`\n{3,}`)
+	paragraphSplit     = regexp.MustCompile(`\n\s*\n`)
+)
+
+// FormatOutput renders a markdown response in the client's requested format, markdown is returned untouched
+func FormatOutput(text string, format string) string {
+	switch models.OutputFormat(format) {
+	case models.OutputFormatPlaintext:
+		return stripMarkdown(text, true)
+	case models.OutputFormatSSML:
+		return toSSML(text)
+	default:
+		return text
+	}
+}
+
+// stripMarkdown removes markdown syntax and emoji, keepURLs leaves link targets readable for text clients
+func stripMarkdown(text string, keepURLs bool) string {
+	text = markdownCodeFence.ReplaceAllString(text, "")
+	text = markdownRule.ReplaceAllString(text, "")
+	text = markdownHeading.ReplaceAllString(text, "")
+	text = markdownBlockquote.ReplaceAllString(text, "")
+	text = markdownBullet.ReplaceAllString(text, "$1")
+	text = markdownImage.ReplaceAllString(text, "$1")
+	if keepURLs {
+		text = markdownLink.ReplaceAllString(text, "$1 ($2)")
+	} else {
+		text = markdownLink.ReplaceAllString(text, "$1")
+	}
+	text = markdownBold.ReplaceAllString(text, "$2")
+	text = markdownItalicStar.ReplaceAllString(text, "$1")
+	text = markdownItalicBar.ReplaceAllString(text, "$1$2$3")
+	text = markdownStrike.ReplaceAllString(text, "$1")
+	text = markdownInlineCode.ReplaceAllString(text, "$1")
+	text = stripEmoji(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	text = extraBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text)
+}
+
+func stripEmoji(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, transport, flags
+			r >= 0x2600 && r <= 0x27BF, // misc symbols and dingbats
+			r >= 0x2B00 && r <= 0x2BFF, // arrows and stars
+			r == 0xFE0F, r == 0x200D:   // variation selector and zero width joiner
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// toSSML turns the response into speech markup, one <p> per paragraph with pauses between lines
+func toSSML(text string) string {
+	plain := stripMarkdown(text, false)
+
+	var builder strings.Builder
+	builder.WriteString("<speak>")
+	for _, paragraph := range paragraphSplit.Split(plain, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		builder.WriteString("<p>")
+		for i, line := range strings.Split(paragraph, "\n") {
+			if i > 0 {
+				builder.WriteString(`<break time="300ms"/>`)
+			}
+			builder.WriteString(escapeSSML(line))
+		}
+		builder.WriteString(`</p><break time="600ms"/>`)
+	}
+	builder.WriteString("</speak>")
+
+	return builder.String()
+}
+
+var ssmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func escapeSSML(text string) string {
+	return ssmlEscaper.Replace(text)
+}
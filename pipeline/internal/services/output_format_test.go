@@ -0,0 +1,78 @@
+package services
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const markdownResponse = `## 🗳️ Election Results
+
+**Modi wins a third term** as the _BJP-led_ alliance crosses the majority mark.
+
+- Counting ended on *Tuesday*
+- Turnout was ` + "`66%`" + `
+  * Read the [full results](https://example.com/results?a=1&b=2)
+
+> "A historic mandate," said the party's ~~president~~ chairman.
+
+---
+Stay tuned for more! 🚀`
+
+func TestPlaintextOutputHasNoMarkdown(t *testing.T) {
+	plain := FormatOutput(markdownResponse, "plaintext")
+
+	for _, syntax := range []string{"**", "##", "- ", "* ", "_BJP", "`", "](", "~~", "> ", "---", "🗳", "🚀"} {
+		if strings.Contains(plain, syntax) {
+			t.Errorf("plaintext output contains %q:\n%s", syntax, plain)
+		}
+	}
+	for _, want := range []string{"Election Results", "Modi wins a third term", "BJP-led", "66%", "full results (https://example.com/results?a=1&b=2)", "president chairman"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("plaintext output is missing %q:\n%s", want, plain)
+		}
+	}
+}
+
+func TestSSMLOutputIsWellFormed(t *testing.T) {
+	ssml := FormatOutput(markdownResponse, "ssml")
+
+	if !strings.HasPrefix(ssml, "<speak>") || !strings.HasSuffix(ssml, "</speak>") {
+		t.Fatalf("ssml output is not wrapped in <speak>:\n%s", ssml)
+	}
+	decoder := xml.NewDecoder(strings.NewReader(ssml))
+	paragraphs, breaks := 0, 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ssml output is not well-formed XML: %v\n%s", err, ssml)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			switch start.Name.Local {
+			case "p":
+				paragraphs++
+			case "break":
+				breaks++
+			}
+		}
+	}
+	if paragraphs < 4 || breaks < paragraphs {
+		t.Errorf("ssml output has %d paragraphs and %d breaks, want one paragraph per block with pauses:\n%s", paragraphs, breaks, ssml)
+	}
+	if strings.Contains(ssml, "https://") || strings.Contains(ssml, "**") {
+		t.Errorf("ssml output reads out link targets or markdown:\n%s", ssml)
+	}
+}
+
+func TestMarkdownOutputIsUntouched(t *testing.T) {
+	for _, format := range []string{"", "markdown"} {
+		if got := FormatOutput(markdownResponse, format); got != markdownResponse {
+			t.Errorf("FormatOutput(%q) changed the response", format)
+		}
+	}
+}
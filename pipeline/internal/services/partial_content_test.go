@@ -0,0 +1,91 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWorkflowAnswersFromTheContentTypeThatWasFetched(t *testing.T) {
+	tests := []struct {
+		name            string
+		failArticles    bool
+		failVideos      bool
+		wantUnavailable []string
+		wantNote        string
+		// the summary prompt still shows the content type that was fetched
+		wantInPrompt string
+	}{
+		{name: "articles failed", failArticles: true, wantUnavailable: []string{ContentTypeArticles},
+			wantNote: unavailableContentNotes[ContentTypeArticles], wantInPrompt: "Video 1"},
+		{name: "videos failed", failVideos: true, wantUnavailable: []string{ContentTypeVideos},
+			wantNote: unavailableContentNotes[ContentTypeVideos], wantInPrompt: "Article 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"CHROMA_MIN_SIMILARITY": "0"}), "elections", models.IntentNewNewsQuery)
+			if tt.failArticles {
+				_, workflow.orchestrator.newsService = newFailingNewsAPI(t, http.StatusUnauthorized, "apiKeyInvalid", nil)
+			}
+			if tt.failVideos {
+				workflow.orchestrator.youtubeService, _ = newQuotaExhaustedYouTube(t, http.StatusInternalServerError, "backendError")
+			}
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-partial", Query: "what is the latest on elections",
+				UserPreferences: models.UserPreferences{NewsPersonality: models.NoPersona},
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v, want an answer from the content that was fetched", err)
+			}
+
+			if !reflect.DeepEqual(response.UnavailableContent, tt.wantUnavailable) {
+				t.Errorf("unavailable content = %v, want %v", response.UnavailableContent, tt.wantUnavailable)
+			}
+			if !strings.HasPrefix(response.Message, "The elections story in brief") || !strings.HasSuffix(response.Message, "\n\n"+tt.wantNote) {
+				t.Errorf("message = %q, want the summary followed by %q", response.Message, tt.wantNote)
+			}
+			summarized := false
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") {
+					summarized = strings.Contains(call.Prompt, tt.wantInPrompt)
+				}
+			}
+			if !summarized {
+				t.Errorf("summary prompt does not carry %q", tt.wantInPrompt)
+			}
+		})
+	}
+}
+
+func TestWorkflowFailsWithoutAnyContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		failVideos bool
+	}{
+		{name: "partial content disabled", env: map[string]string{"PARTIAL_CONTENT_RESULTS": "false"}},
+		{name: "both failed", failVideos: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, tt.env), "elections", models.IntentNewNewsQuery)
+			_, workflow.orchestrator.newsService = newFailingNewsAPI(t, http.StatusUnauthorized, "apiKeyInvalid", nil)
+			if tt.failVideos {
+				workflow.orchestrator.youtubeService, _ = newQuotaExhaustedYouTube(t, http.StatusInternalServerError, "backendError")
+			}
+
+			if _, err := workflow.run("workflow-no-content"); err == nil {
+				t.Fatal("ExecuteWorkflow() succeeded without the articles")
+			}
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") {
+					t.Fatal("the workflow summarized without any content")
+				}
+			}
+		})
+	}
+}
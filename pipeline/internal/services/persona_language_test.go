@@ -0,0 +1,101 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPersonaLanguageInstruction(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		native   bool
+		language string
+		want     []string
+		wantNot  []string
+	}{
+		{name: "english needs no section", native: true, language: "en"},
+		{name: "no language needs no section", native: true, language: ""},
+		{name: "hindi", native: true, language: "hi",
+			want: []string{"**RESPONSE LANGUAGE: Hindi**", languageDirectives["hi"], "native Hindi speaker"}},
+		{name: "spanish code in any case", native: true, language: "ES",
+			want: []string{"**RESPONSE LANGUAGE: Spanish**", languageDirectives["es"]}},
+		{name: "a language without a directive", native: true, language: "sw",
+			want: []string{"Write the entire response in sw."}},
+		{name: "native off uses the plain section", language: "hi",
+			want: []string{"**RESPONSE LANGUAGE**", "Write the entire response in Hindi"}, wantNot: []string{languageDirectives["hi"]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &GeminiService{config: config.GeminiConfig{NativePersonaLanguage: tt.native}}
+			got := service.personaLanguageInstruction(tt.language)
+
+			if len(tt.want) == 0 && got != "" {
+				t.Errorf("personaLanguageInstruction(%q) = %q, want no section", tt.language, got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("personaLanguageInstruction(%q) is missing %q:\n%s", tt.language, want, got)
+				}
+			}
+			for _, unwanted := range tt.wantNot {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("personaLanguageInstruction(%q) contains %q", tt.language, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestEveryPersonaPromptCarriesTheLanguage(t *testing.T) {
+	_, service := newFakeGemini(t, config.GeminiConfig{NativePersonaLanguage: true}, nil)
+	builders := map[string]func(query, response, language string) string{
+		"calm anchor":            service.buildCalmAnchorPrompt,
+		"friendly explainer":     service.buildFriendlyExplainerPrompt,
+		"investigative reporter": service.buildInvestigativeReporterPrompt,
+		"youthful trendspotter":  service.buildYouthfulTrendspotterPrompt,
+		"global correspondent":   service.buildGlobalCorrespondentPrompt,
+		"ai analyst":             service.buildAIAnalystPrompt,
+		"default":                service.buildDefaultPersonaPrompt,
+	}
+	for name, build := range builders {
+		hindi := build("who won", "The summary.", "hi")
+		if !strings.Contains(hindi, languageDirectives["hi"]) || !strings.Contains(hindi, "The summary.") {
+			t.Errorf("%s prompt for a hindi user is missing the hindi directive or the summary", name)
+		}
+		if english := build("who won", "The summary.", "en"); strings.Contains(english, "RESPONSE LANGUAGE") {
+			t.Errorf("%s prompt for an english user has a language section", name)
+		}
+	}
+}
+
+func TestPersonaIsAskedForTheUsersLanguage(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"LANGUAGE_MODE": LanguageModeGenerate}), "elections", models.IntentNewNewsQuery)
+
+	if _, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-persona-language", Query: "who is ahead in the elections",
+		UserPreferences: models.UserPreferences{Language: "es", NewsPersonality: "calm-anchor"},
+	}); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	var personaPrompt string
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Content Personalizer") {
+			personaPrompt = call.Prompt
+		}
+	}
+	if personaPrompt == "" {
+		t.Fatal("persona agent was not called")
+	}
+	if !strings.Contains(personaPrompt, languageDirectives["es"]) || !strings.Contains(personaPrompt, "evening news broadcast") {
+		t.Errorf("calm anchor prompt is missing the spanish directive or the anchor's voice:\n%s", personaPrompt)
+	}
+	// the language section is rendered once, not again after the prompt
+	if count := strings.Count(personaPrompt, "RESPONSE LANGUAGE"); count != 1 {
+		t.Errorf("persona prompt has %d language sections, want 1", count)
+	}
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"slices"
+)
+
+// PersonaPolicy restricts which personas a tenant may use and what a disallowed persona falls back to
+type PersonaPolicy struct {
+	tenants map[string]config.TenantPersonas
+}
+
+func NewPersonaPolicy(tenantConfig config.TenantConfig) *PersonaPolicy {
+	tenants := make(map[string]config.TenantPersonas, len(tenantConfig.Personas))
+	for tenant, personas := range tenantConfig.Personas {
+		// unknown personas in the allowlist are dropped so they can never reach the prompt builders
+		var allowed []string
+		for _, persona := range personas.Allowed {
+			if slices.Contains(models.AvailablePersonas, persona) {
+				allowed = append(allowed, persona)
+			}
+		}
+		personas.Allowed = allowed
+		tenants[tenant] = personas
+	}
+
+	return &PersonaPolicy{tenants: tenants}
+}
+
+// AllowedPersonas returns the personas the tenant may use, every persona when the tenant has no allowlist
+func (policy *PersonaPolicy) AllowedPersonas(tenantID string) []string {
+	tenantPersonas, exists := policy.tenants[tenantID]
+	if !exists || len(tenantPersonas.Allowed) == 0 {
+		return slices.Clone(models.AvailablePersonas)
+	}
+	return slices.Clone(tenantPersonas.Allowed)
+}
+
+// DefaultPersona returns the tenant default, falling back to the first allowed persona
+func (policy *PersonaPolicy) DefaultPersona(tenantID string) string {
+	allowed := policy.AllowedPersonas(tenantID)
+
+	if tenantPersonas, exists := policy.tenants[tenantID]; exists && slices.Contains(allowed, tenantPersonas.Default) {
+		return tenantPersonas.Default
+	}
+
+	if slices.Contains(allowed, models.DefaultPersona) {
+		return models.DefaultPersona
+	}
+
+	return allowed[0]
+}
+
+// ResolvePersona maps a requested persona onto one the tenant allows, reporting whether it was remapped
+func (policy *PersonaPolicy) ResolvePersona(tenantID, persona string) (string, bool) {
+	if persona != "" && slices.Contains(policy.AllowedPersonas(tenantID), persona) {
+		return persona, false
+	}
+
+	return policy.DefaultPersona(tenantID), persona != ""
+}
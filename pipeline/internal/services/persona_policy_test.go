@@ -0,0 +1,78 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPersonaPolicyResolvesPersonasPerTenant(t *testing.T) {
+	policy := NewPersonaPolicy(config.TenantConfig{Personas: map[string]config.TenantPersonas{
+		"corp":    {Allowed: []string{"calm-anchor", "ai-analyst", "made-up-persona"}, Default: "ai-analyst"},
+		"nodflt":  {Allowed: []string{"global-correspondent", "calm-anchor"}},
+		"badflt":  {Allowed: []string{"calm-anchor"}, Default: "youthful-trendspotter"},
+		"allowed": {Default: "calm-anchor"},
+	}})
+
+	tests := []struct {
+		name         string
+		tenant       string
+		persona      string
+		want         string
+		wantRemapped bool
+	}{
+		{name: "allowed persona is kept", tenant: "corp", persona: "calm-anchor", want: "calm-anchor"},
+		{name: "disallowed persona maps to the tenant default", tenant: "corp", persona: "youthful-trendspotter", want: "ai-analyst", wantRemapped: true},
+		{name: "unset persona takes the default without a remap", tenant: "corp", want: "ai-analyst"},
+		{name: "unknown allowlist entries are dropped", tenant: "corp", persona: "made-up-persona", want: "ai-analyst", wantRemapped: true},
+		{name: "no tenant default falls back to the first allowed", tenant: "nodflt", persona: "ai-analyst", want: "global-correspondent", wantRemapped: true},
+		{name: "a disallowed default is ignored", tenant: "badflt", want: "calm-anchor"},
+		{name: "tenant without an allowlist may use every persona", tenant: "allowed", persona: "youthful-trendspotter", want: "youthful-trendspotter"},
+		{name: "unknown tenant uses the global default", tenant: "other", want: models.DefaultPersona},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			persona, remapped := policy.ResolvePersona(tt.tenant, tt.persona)
+			if persona != tt.want || remapped != tt.wantRemapped {
+				t.Errorf("ResolvePersona(%q, %q) = %s, %v, want %s, %v", tt.tenant, tt.persona, persona, remapped, tt.want, tt.wantRemapped)
+			}
+		})
+	}
+
+	if allowed := policy.AllowedPersonas("corp"); !slices.Equal(allowed, []string{"calm-anchor", "ai-analyst"}) {
+		t.Errorf("AllowedPersonas(corp) = %v", allowed)
+	}
+	if allowed := policy.AllowedPersonas("other"); !slices.Equal(allowed, models.AvailablePersonas) {
+		t.Errorf("AllowedPersonas(other) = %v, want every persona", allowed)
+	}
+}
+
+func TestDisallowedPersonaIsRemappedInTheWorkflow(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{
+		"TENANT_PERSONA_ALLOWLIST": "corp:calm-anchor,ai-analyst",
+		"TENANT_DEFAULT_PERSONAS":  "corp:calm-anchor",
+	})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-persona", Query: "who is ahead in the elections", TenantID: "corp",
+		UserPreferences: models.UserPreferences{NewsPersonality: "youthful-trendspotter"},
+	})
+	if err != nil || response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("ExecuteWorkflow() = %v, %v", response, err)
+	}
+
+	var personaPrompt string
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Content Personalizer") {
+			personaPrompt = call.Prompt
+		}
+	}
+	if !strings.Contains(personaPrompt, "evening news anchor") || strings.Contains(personaPrompt, "Gen-Z") {
+		t.Errorf("persona prompt was not built for the tenant default calm-anchor:\n%s", personaPrompt)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// lists such as topics and keywords name at most this many entries
+	maxPromptContextItems = 8
+	// a single field's value is cut at this many characters
+	maxPromptContextValueChars = 300
+	// earlier answers quoted back to the model are cut at this many characters
+	maxPromptPreviousResponseChars = 1500
+)
+
+// promptContextLabels are the line labels of each renderable field
+var promptContextLabels = map[string]string{
+	"intent":           "Intent",
+	"topics":           "Recent topics",
+	"keywords":         "Recent keywords",
+	"previous_query":   "Previous query",
+	"referenced_topic": "Referenced topic",
+	"summary":          "Conversation so far",
+	"preferences":      "Preferences",
+}
+
+// renderPromptContext renders the conversation details a prompt needs as short labelled lines instead of
+// interpolating the raw context map. Only the configured fields are rendered, in config.PromptContextFieldNames
+// order, lists and long values are cut and the whole snippet stays under maxChars. Unknown keys are ignored.
+func renderPromptContext(context map[string]interface{}, fields []string, maxChars int) string {
+	values := promptContextValues(context)
+
+	var builder strings.Builder
+	for _, field := range config.PromptContextFieldNames {
+		if len(fields) > 0 && !slices.Contains(fields, field) {
+			continue
+		}
+		value := strings.Join(strings.Fields(values[field]), " ")
+		if value == "" {
+			continue
+		}
+		value = truncateForPrompt(value, maxPromptContextValueChars)
+
+		line := fmt.Sprintf("- %s: %s\n", promptContextLabels[field], value)
+		if maxChars > 0 && builder.Len()+len(line) > maxChars {
+			break
+		}
+		builder.WriteString(line)
+	}
+
+	if builder.Len() == 0 {
+		return "None"
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// promptContextValues flattens the context maps the agents build, the full conversation context or the
+// individual keys, into one value per renderable field
+func promptContextValues(context map[string]interface{}) map[string]string {
+	values := map[string]string{}
+
+	if convCtx, ok := context["conversation_context"].(models.ConversationContext); ok {
+		values["topics"] = joinPromptList(convCtx.CurrentTopics)
+		values["keywords"] = joinPromptList(convCtx.RecentKeywords)
+		values["previous_query"] = convCtx.LastQuery
+		values["summary"] = convCtx.LastSummary
+		if values["summary"] == "" {
+			values["summary"] = convCtx.ContextSummary
+		}
+		values["preferences"] = renderPromptPreferences(convCtx.UserPreferences)
+	}
+
+	if intent, ok := context["intent"].(string); ok {
+		values["intent"] = intent
+	}
+	if topics, ok := context["recent_topics"].([]string); ok {
+		values["topics"] = joinPromptList(topics)
+	}
+	if keywords, ok := context["recent_keywords"].([]string); ok {
+		values["keywords"] = joinPromptList(keywords)
+	}
+	if topic, ok := context["referenced_topic"].(string); ok {
+		values["referenced_topic"] = topic
+	}
+	if summary, ok := context["last_summary"].(string); ok && summary != "" {
+		values["summary"] = summary
+	}
+	if prefs, ok := context["user_preferences"].(models.UserPreferences); ok {
+		values["preferences"] = renderPromptPreferences(prefs)
+	} else if topics, ok := context["preferred_user_topics"].([]string); ok && len(topics) > 0 {
+		values["preferences"] = "favorite topics " + joinPromptList(topics)
+	}
+
+	return values
+}
+
+// renderPromptPreferences keeps the preferences that shape what to search for and how to answer
+func renderPromptPreferences(prefs models.UserPreferences) string {
+	var parts []string
+	if len(prefs.FavouriteTopics) > 0 {
+		parts = append(parts, "favorite topics "+joinPromptList(prefs.FavouriteTopics))
+	}
+	if prefs.NewsPersonality != "" {
+		parts = append(parts, "news style "+prefs.NewsPersonality)
+	}
+	if prefs.Region != "" {
+		parts = append(parts, "region "+prefs.Region)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// truncateForPrompt cuts long text quoted into a prompt and marks the cut
+func truncateForPrompt(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return truncateUTF8(text, limit) + "…"
+}
+
+func joinPromptList(items []string) string {
+	if len(items) > maxPromptContextItems {
+		items = items[len(items)-maxPromptContextItems:]
+	}
+	return strings.Join(items, ", ")
+}
+
+// promptContext renders a context map with the configured fields and size bound
+func (service *GeminiService) promptContext(context map[string]interface{}) string {
+	return renderPromptContext(context, service.config.PromptContextFields, service.config.PromptContextMaxChars)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// bloatedConversation is a long running conversation whose full dump would swamp a prompt
+func bloatedConversation() models.ConversationContext {
+	var topics []string
+	for i := 1; i <= 12; i++ {
+		topics = append(topics, fmt.Sprintf("topic-%d", i))
+	}
+	return models.ConversationContext{
+		SessionID: "session-secret", UserID: "user-secret",
+		Exchanges: []models.ConversationExchange{
+			{UserQuery: "what happened in the budget vote", AIResponse: strings.Repeat("An earlier very long answer. ", 200)},
+		},
+		CurrentTopics:   topics,
+		RecentKeywords:  []string{"budget", "parliament"},
+		TopicCentroid:   []float64{0.12345, 0.6789},
+		LastQuery:       "what happened in the budget vote",
+		LastResponse:    strings.Repeat("The last full answer. ", 100),
+		LastSummary:     strings.Repeat("The user follows the budget debate. ", 20),
+		UserPreferences: models.UserPreferences{FavouriteTopics: []string{"politics"}, NewsPersonality: "calm-anchor", Region: "IN"},
+	}
+}
+
+func TestRenderPromptContext(t *testing.T) {
+	t.Parallel()
+	full := map[string]interface{}{"intent": "follow_up_discussion", "conversation_context": bloatedConversation()}
+
+	tests := []struct {
+		name     string
+		context  map[string]interface{}
+		fields   []string
+		maxChars int
+		want     string
+	}{
+		{
+			name: "selected fields only", context: full, fields: []string{"keywords", "intent"}, maxChars: 800,
+			want: "- Intent: follow_up_discussion\n- Recent keywords: budget, parliament",
+		},
+		{
+			name: "lists keep the latest entries", context: full, fields: []string{"topics"}, maxChars: 800,
+			want: "- Recent topics: topic-5, topic-6, topic-7, topic-8, topic-9, topic-10, topic-11, topic-12",
+		},
+		{
+			name: "individual keys", fields: nil, maxChars: 800,
+			context: map[string]interface{}{
+				"recent_topics": []string{"rates"}, "referenced_topic": "the Fed chair",
+				"preferred_user_topics": []string{"economy"}, "unrelated": map[string]int{"ignored": 1},
+			},
+			want: "- Recent topics: rates\n- Referenced topic: the Fed chair\n- Preferences: favorite topics economy",
+		},
+		{
+			name: "the bound drops whole lines", context: full, fields: []string{"intent", "summary"}, maxChars: 60,
+			want: "- Intent: follow_up_discussion",
+		},
+		{name: "nothing to render", context: map[string]interface{}{"unrelated": "value"}, maxChars: 800, want: "None"},
+		{name: "no context", context: nil, maxChars: 800, want: "None"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderPromptContext(tt.context, tt.fields, tt.maxChars); got != tt.want {
+				t.Errorf("renderPromptContext() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderedPromptContextIsCompact(t *testing.T) {
+	t.Parallel()
+	rendered := renderPromptContext(map[string]interface{}{"conversation_context": bloatedConversation()}, nil, 800)
+
+	if len(rendered) > 800 {
+		t.Errorf("rendered context is %d characters, want at most 800", len(rendered))
+	}
+	for _, leaked := range []string{"session-secret", "user-secret", "0.12345", "An earlier very long answer", "The last full answer", "map[", "{"} {
+		if strings.Contains(rendered, leaked) {
+			t.Errorf("rendered context contains %q:\n%s", leaked, rendered)
+		}
+	}
+	for _, line := range strings.Split(rendered, "\n") {
+		if !strings.HasPrefix(line, "- ") {
+			t.Errorf("rendered line %q is not a labelled field", line)
+		}
+		if len(line) > len("- Conversation so far: ")+maxPromptContextValueChars+len("…") {
+			t.Errorf("rendered line is %d characters, want each value cut at %d", len(line), maxPromptContextValueChars)
+		}
+	}
+	if !strings.Contains(rendered, "- Preferences: favorite topics politics, news style calm-anchor, region IN") {
+		t.Errorf("rendered context is missing the preferences:\n%s", rendered)
+	}
+}
+
+func TestPromptsCarryTheRenderedContext(t *testing.T) {
+	_, service := newFakeGemini(t, config.GeminiConfig{PromptContextMaxChars: 800}, nil)
+	conversation := bloatedConversation()
+	context := map[string]interface{}{"conversation_context": conversation, "recent_topics": conversation.CurrentTopics}
+
+	prompts := map[string]string{
+		"keyword extraction":  service.buildKeywordExtractionPrompt("and the senate?", context),
+		"intent":              service.buildIntentClassificationPrompt("and the senate?", context),
+		"contextual response": service.buildContextualResponsePrompt("and the senate?", conversation.Exchanges, "budget", conversation.UserPreferences, context),
+	}
+	for name, prompt := range prompts {
+		if !strings.Contains(prompt, "- Recent topics: topic-5") {
+			t.Errorf("%s prompt is missing the rendered topics", name)
+		}
+		for _, leaked := range []string{"session-secret", "0.12345", "map["} {
+			if strings.Contains(prompt, leaked) {
+				t.Errorf("%s prompt contains the raw context %q", name, leaked)
+			}
+		}
+	}
+
+	// the earlier answer quoted back in a follow up is cut
+	if count := strings.Count(prompts["contextual response"], "An earlier very long answer"); count == 0 || count*len("An earlier very long answer. ") > maxPromptPreviousResponseChars {
+		t.Errorf("contextual response quotes the previous answer %d times, want it cut at %d characters", count, maxPromptPreviousResponseChars)
+	}
+}
+
+func TestHistoryPromptsCutEarlierAnswers(t *testing.T) {
+	_, service := newFakeGemini(t, config.GeminiConfig{PromptContextMaxChars: 800}, nil)
+	var history []models.ConversationExchange
+	for i := 0; i < 6; i++ {
+		history = append(history, models.ConversationExchange{
+			UserQuery:  fmt.Sprintf("question %d", i),
+			AIResponse: strings.Repeat("An earlier very long answer. ", 200),
+		})
+	}
+
+	prompts := map[string]struct {
+		prompt    string
+		exchanges int
+	}{
+		"classification": {service.buildEnhancedClassificationPrompt("and the senate?", history), 3},
+		"chitchat":       {service.buildEnhancedChitchatPrompt("thanks!", map[string]interface{}{}, history), 5},
+	}
+	for name, tt := range prompts {
+		// every quoted answer is cut, not just the latest
+		limit := tt.exchanges * maxPromptPreviousResponseChars
+		if quoted := strings.Count(tt.prompt, "An earlier very long answer. ") * len("An earlier very long answer. "); quoted == 0 || quoted > limit {
+			t.Errorf("%s prompt quotes %d characters of earlier answers, want at most %d", name, quoted, limit)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	// the same four characters per token estimate used for cost reporting when usage metadata is missing
+	promptCharsPerToken = 4
+	promptTrimMarker    = "\n\n[... content trimmed to fit the model's context window ...]\n\n"
+)
+
+// ErrPromptTooLarge is returned instead of sending a prompt that cannot fit the model's context window
+var ErrPromptTooLarge = models.NewValidationError("PROMPT_TOO_LARGE", "Prompt exceeds the model context window", "")
+
+func estimatePromptTokens(req *GenerationRequest) int {
+	return (len(req.SystemRole) + len(req.Context) + len(req.Prompt) + promptCharsPerToken - 1) / promptCharsPerToken
+}
+
+// guardPromptSize checks a request against the prompt budget before it is sent. An oversized request loses its
+// supplementary context first and then, with the "middle" strategy of its agent, the middle of its prompt. The
+// caller's request is left untouched, a trimmed copy is returned.
+func (service *GeminiService) guardPromptSize(req *GenerationRequest) (*GenerationRequest, error) {
+	if service.config.ContextWindowTokens <= 0 {
+		return req, nil
+	}
+
+	budget := int(float64(service.config.ContextWindowTokens) * service.config.PromptBudgetFraction)
+	estimated := estimatePromptTokens(req)
+	if estimated <= budget {
+		return req, nil
+	}
+
+	strategy := service.config.PromptTrimStrategies[req.Agent]
+	if strategy != "middle" {
+		return nil, fmt.Errorf("%w: %s prompt is about %d tokens, the budget is %d", ErrPromptTooLarge, promptAgentName(req), estimated, budget)
+	}
+
+	budgetChars := budget * promptCharsPerToken
+	trimmed := *req
+	if fixed := len(trimmed.SystemRole) + len(trimmed.Prompt); fixed < budgetChars {
+		trimmed.Context = truncateUTF8(trimmed.Context, budgetChars-fixed)
+	} else {
+		trimmed.Context = ""
+	}
+
+	// the system role is never trimmed, what is left of the budget after it goes to the prompt
+	promptChars := budgetChars - len(trimmed.SystemRole) - len(trimmed.Context)
+	if promptChars <= len(promptTrimMarker)*2 {
+		return nil, fmt.Errorf("%w: %s system role alone exceeds the budget of %d tokens", ErrPromptTooLarge, promptAgentName(req), budget)
+	}
+	if len(trimmed.Prompt) > promptChars {
+		trimmed.Prompt = trimMiddle(trimmed.Prompt, promptChars)
+	}
+
+	service.logger.Warn("Trimmed oversized prompt to fit the context window",
+		"agent", promptAgentName(req),
+		"estimated_tokens", estimated,
+		"budget_tokens", budget,
+		"trimmed_tokens", estimatePromptTokens(&trimmed),
+	)
+	return &trimmed, nil
+}
+
+// trimMiddle cuts text down to limit bytes by removing its middle, keeping three quarters of the room for the
+// opening instructions and content and the rest for the closing output format
+func trimMiddle(text string, limit int) string {
+	room := limit - len(promptTrimMarker)
+	tailLength := room / 4
+	head := truncateUTF8(text, room-tailLength)
+
+	tailStart := len(text) - tailLength
+	for tailStart < len(text) && !utf8.RuneStart(text[tailStart]) {
+		tailStart++
+	}
+	return head + promptTrimMarker + text[tailStart:]
+}
+
+func promptAgentName(req *GenerationRequest) string {
+	if req.Agent == "" {
+		return "unnamed agent"
+	}
+	return req.Agent
+}
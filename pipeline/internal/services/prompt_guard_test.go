@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// newGuardedGemini returns a fake Gemini whose prompt budget is 500 tokens, 2000 characters
+func newGuardedGemini(t *testing.T) (*fakeGemini, *GeminiService) {
+	t.Helper()
+	cfg := loadTestConfig(t, map[string]string{"GEMINI_CONTEXT_WINDOW_TOKENS": "1000", "GEMINI_PROMPT_BUDGET_FRACTION": "0.5"})
+	cfg.Gemini.MaxRetries = 3
+	return newFakeGemini(t, cfg.Gemini, func(call fakeGeminiCall) string { return "ok" })
+}
+
+// oversizedPrompt opens with instructions, has body characters of content and closes with the output format
+func oversizedPrompt(body int) string {
+	return "INSTRUCTIONS: summarize the articles.\n" + strings.Repeat("article text ", body/13) + "\nOUTPUT FORMAT: three bullet points."
+}
+
+func TestGuardPromptSize(t *testing.T) {
+	_, service := newGuardedGemini(t)
+	budget := 500
+
+	tests := []struct {
+		name    string
+		req     GenerationRequest
+		wantErr bool
+		// the prompt itself is sent as it is
+		wantUntouched bool
+		wantContext   bool
+	}{
+		{name: "fits", req: GenerationRequest{Agent: "summarizer", SystemRole: "You summarize.", Prompt: oversizedPrompt(500), Context: "recent topics"},
+			wantUntouched: true, wantContext: true},
+		{name: "context trimmed before the prompt", req: GenerationRequest{Agent: "summarizer", SystemRole: "You summarize.", Prompt: oversizedPrompt(1500),
+			Context: strings.Repeat("earlier exchange ", 100)}, wantUntouched: true, wantContext: true},
+		{name: "middle trimmed", req: GenerationRequest{Agent: "summarizer", SystemRole: "You summarize.", Prompt: oversizedPrompt(8000)}},
+		{name: "agent without a strategy", req: GenerationRequest{Agent: "persona", Prompt: oversizedPrompt(8000)}, wantErr: true},
+		{name: "unnamed agent", req: GenerationRequest{Prompt: oversizedPrompt(8000)}, wantErr: true},
+		{name: "system role alone too large", req: GenerationRequest{Agent: "summarizer", SystemRole: strings.Repeat("rule ", 500), Prompt: "hi"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.req
+			guarded, err := service.guardPromptSize(&tt.req)
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrPromptTooLarge) || guarded != nil {
+					t.Fatalf("guardPromptSize() = %v, %v, want ErrPromptTooLarge", guarded, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("guardPromptSize() error = %v", err)
+			}
+			if tt.req != original {
+				t.Error("guardPromptSize() changed the caller's request")
+			}
+			if tokens := estimatePromptTokens(guarded); tokens > budget {
+				t.Errorf("guarded prompt is about %d tokens, want at most %d", tokens, budget)
+			}
+			if tt.wantUntouched && guarded.Prompt != tt.req.Prompt {
+				t.Error("a prompt within the budget was changed")
+			}
+			if !tt.wantUntouched && guarded.Prompt != tt.req.Prompt && !strings.Contains(guarded.Prompt, promptTrimMarker) {
+				t.Error("trimmed prompt does not say content was cut")
+			}
+			if !strings.HasPrefix(guarded.Prompt, "INSTRUCTIONS:") || !strings.HasSuffix(guarded.Prompt, "OUTPUT FORMAT: three bullet points.") {
+				t.Error("trimming lost the opening instructions or the closing output format")
+			}
+			if guarded.SystemRole != tt.req.SystemRole {
+				t.Error("the system role was trimmed")
+			}
+			if hasContext := guarded.Context != ""; hasContext != tt.wantContext || (tt.wantContext && !strings.HasPrefix(tt.req.Context, guarded.Context)) {
+				t.Errorf("context = %q, want it kept %t as a prefix of the original", guarded.Context, tt.wantContext)
+			}
+		})
+	}
+}
+
+func TestOversizedPromptIsNeverSent(t *testing.T) {
+	gemini, service := newGuardedGemini(t)
+	ctx := context.Background()
+
+	if _, err := service.GenerateContent(ctx, &GenerationRequest{Agent: "persona", Prompt: oversizedPrompt(8000), MaxTokens: 100}); !errors.Is(err, ErrPromptTooLarge) {
+		t.Fatalf("GenerateContent() error = %v, want ErrPromptTooLarge", err)
+	}
+	// rejected before sending and not retried
+	if calls := gemini.received(); len(calls) != 0 {
+		t.Fatalf("gemini received %d calls for a rejected prompt", len(calls))
+	}
+
+	response, err := service.GenerateContent(ctx, &GenerationRequest{Agent: "summarizer", Prompt: oversizedPrompt(8000), MaxTokens: 100})
+	if err != nil || response.Content != "ok" {
+		t.Fatalf("GenerateContent() = %v, %v, want the trimmed prompt answered", response, err)
+	}
+	calls := gemini.received()
+	if len(calls) != 1 {
+		t.Fatalf("gemini received %d calls, want the trimmed prompt once", len(calls))
+	}
+	if sent := calls[0].Prompt; len(sent) > 2000 || !strings.Contains(sent, promptTrimMarker) || !strings.HasSuffix(sent, "OUTPUT FORMAT: three bullet points.") {
+		t.Errorf("gemini received a %d character prompt, want the trimmed one", len(sent))
+	}
+}
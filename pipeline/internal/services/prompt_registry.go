@@ -0,0 +1,113 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts/*.tmpl
+var defaultPromptTemplates embed.FS
+
+// PromptRegistry renders the agent prompts from named text/template files. The embedded set is always
+// loaded, templates found in the override directory replace them by name.
+type PromptRegistry struct {
+	defaults  map[string]*template.Template
+	overrides map[string]*template.Template
+	logger    *logger.Logger
+}
+
+func NewPromptRegistry(overrideDir string, log *logger.Logger) (*PromptRegistry, error) {
+	registry := &PromptRegistry{
+		defaults:  make(map[string]*template.Template),
+		overrides: make(map[string]*template.Template),
+		logger:    log,
+	}
+
+	entries, err := fs.Glob(defaultPromptTemplates, "prompts/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedded prompt templates: %w", err)
+	}
+
+	for _, entry := range entries {
+		source, err := defaultPromptTemplates.ReadFile(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded prompt template %s: %w", entry, err)
+		}
+
+		name := strings.TrimSuffix(filepath.Base(entry), ".tmpl")
+		parsed, err := parsePromptTemplate(name, string(source))
+		if err != nil {
+			return nil, fmt.Errorf("embedded prompt template %s is invalid: %w", name, err)
+		}
+		registry.defaults[name] = parsed
+	}
+
+	if overrideDir != "" {
+		registry.loadOverrides(overrideDir)
+	}
+
+	log.Info("Prompt registry initialized",
+		"templates", len(registry.defaults),
+		"overrides", len(registry.overrides),
+		"override_dir", overrideDir)
+
+	return registry, nil
+}
+
+func parsePromptTemplate(name, source string) (*template.Template, error) {
+	// a placeholder the caller does not supply is an error so a broken override falls back to the default
+	return template.New(name).Option("missingkey=error").Parse(source)
+}
+
+// loadOverrides replaces defaults with same named templates from the directory, skipping unknown and malformed files
+func (registry *PromptRegistry) loadOverrides(dir string) {
+	for name := range registry.defaults {
+		path := filepath.Join(dir, name+".tmpl")
+
+		source, err := os.ReadFile(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				registry.logger.WithError(err).Warn("Failed to read prompt override, using default", "template", name, "path", path)
+			}
+			continue
+		}
+
+		parsed, err := parsePromptTemplate(name, string(source))
+		if err != nil {
+			registry.logger.WithError(err).Warn("Malformed prompt override, using default", "template", name, "path", path)
+			continue
+		}
+		registry.overrides[name] = parsed
+	}
+}
+
+// Render executes the named template, an override that fails to execute falls back to the embedded default
+func (registry *PromptRegistry) Render(name string, data map[string]any) string {
+	if override, ok := registry.overrides[name]; ok {
+		var builder strings.Builder
+		if err := override.Execute(&builder, data); err == nil {
+			return builder.String()
+		} else {
+			registry.logger.WithError(err).Warn("Prompt override failed to render, using default", "template", name)
+		}
+	}
+
+	defaultTemplate, ok := registry.defaults[name]
+	if !ok {
+		registry.logger.Error("Unknown prompt template", "template", name)
+		return ""
+	}
+
+	var builder strings.Builder
+	if err := defaultTemplate.Execute(&builder, data); err != nil {
+		registry.logger.WithError(err).Error("Default prompt template failed to render", "template", name)
+		return ""
+	}
+	return builder.String()
+}
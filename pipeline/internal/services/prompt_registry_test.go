@@ -0,0 +1,83 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newOverriddenPromptRegistry writes the override templates to a directory and loads a registry from it
+func newOverriddenPromptRegistry(t *testing.T, overrides map[string]string) *PromptRegistry {
+	t.Helper()
+	dir := t.TempDir()
+	for name, source := range overrides {
+		if err := os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte(source), 0o600); err != nil {
+			t.Fatalf("failed to write prompt override %s: %v", name, err)
+		}
+	}
+
+	registry, err := NewPromptRegistry(dir, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewPromptRegistry() error = %v", err)
+	}
+	return registry
+}
+
+func TestPromptOverrideReplacesTheEmbeddedTemplate(t *testing.T) {
+	gemini, service := newFakeGemini(t, config.GeminiConfig{}, func(fakeGeminiCall) string { return "elections" })
+	service.prompts = newOverriddenPromptRegistry(t, map[string]string{
+		"keyword_extraction": "TUNED KEYWORDS PROMPT for {{.Query}}",
+	})
+
+	if _, err := service.ExtractKeyWords(context.Background(), "election results", nil); err != nil {
+		t.Fatalf("ExtractKeyWords() error = %v", err)
+	}
+
+	calls := gemini.received()
+	if len(calls) != 1 || calls[0].Prompt != "TUNED KEYWORDS PROMPT for election results" {
+		t.Errorf("keyword extraction prompt = %q, want the override rendered", calls[0].Prompt)
+	}
+}
+
+func TestBrokenPromptOverridesFallBackToTheDefault(t *testing.T) {
+	defaults, err := NewPromptRegistry("", newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewPromptRegistry() error = %v", err)
+	}
+	data := map[string]any{"Query": "election results", "Context": ""}
+	want := defaults.Render("keyword_extraction", data)
+	if !strings.Contains(want, "election results") {
+		t.Fatalf("default keyword extraction prompt = %q, want the query rendered", want)
+	}
+
+	tests := []struct {
+		name     string
+		override string
+	}{
+		{name: "does not parse", override: "Keywords for {{.Query"},
+		{name: "references an unknown placeholder", override: "Keywords for {{.Query}} in {{.Region}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newOverriddenPromptRegistry(t, map[string]string{"keyword_extraction": tt.override})
+
+			if got := registry.Render("keyword_extraction", data); got != want {
+				t.Errorf("Render() = %q, want the embedded default", got)
+			}
+		})
+	}
+}
+
+func TestPromptOverridesOnlyReplaceKnownTemplates(t *testing.T) {
+	registry := newOverriddenPromptRegistry(t, map[string]string{"not_an_agent": "unused {{.Query}}"})
+
+	if len(registry.overrides) != 0 {
+		t.Errorf("overrides = %v, want unknown templates ignored", registry.overrides)
+	}
+	if got := registry.Render("not_an_agent", map[string]any{"Query": "x"}); got != "" {
+		t.Errorf("Render() of an unknown template = %q, want empty", got)
+	}
+}
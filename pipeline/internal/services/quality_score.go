@@ -0,0 +1,122 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"math"
+	"net/url"
+	"strings"
+)
+
+// QualitySignals are the per workflow inputs to the quality score, each normalised to 0..1
+type QualitySignals struct {
+	Coverage    float64 `json:"coverage"`
+	Relevance   float64 `json:"relevance"`
+	NoFallback  float64 `json:"no_fallback"`
+	Scraping    float64 `json:"scraping"`
+	Complete    float64 `json:"complete"`
+	Credibility float64 `json:"credibility"`
+	// false when no trusted sources are configured, the credibility weight is then left out
+	HasCredibility bool `json:"has_credibility"`
+}
+
+// collectQualitySignals reads the signals off a finished news workflow
+func collectQualitySignals(workflowCtx *models.WorkflowContext, qualityConfig config.QualityConfig) QualitySignals {
+	stats := workflowCtx.ProcessingStats
+	articles := workflowCtx.Articles
+	videos := workflowCtx.Videos
+
+	signals := QualitySignals{NoFallback: 1, Scraping: 1, Complete: 1}
+
+	// videos back an answer less than articles do, they count half towards coverage
+	coverage := (float64(len(articles)) + 0.5*float64(len(videos))) / float64(qualityConfig.ArticleTarget)
+	signals.Coverage = math.Min(coverage, 1)
+
+	scored, total := 0, 0.0
+	for _, article := range articles {
+		total += article.RelevanceScore
+		scored++
+	}
+	for _, video := range videos {
+		total += video.RelevancyScore
+		scored++
+	}
+	if scored > 0 {
+		signals.Relevance = clampUnit(total / float64(scored))
+	}
+
+	if len(stats.Fallbacks) > 0 {
+		signals.NoFallback = 0
+	}
+	if stats.ScrapeAttempts > 0 {
+		signals.Scraping = float64(stats.ArticlesScraped) / float64(stats.ScrapeAttempts)
+	}
+	if stats.SummaryTruncated {
+		signals.Complete = 0
+	}
+
+	if len(qualityConfig.TrustedSources) > 0 && len(articles) > 0 {
+		signals.HasCredibility = true
+		trusted := 0
+		for _, article := range articles {
+			if isTrustedSource(article, qualityConfig.TrustedSources) {
+				trusted++
+			}
+		}
+		signals.Credibility = float64(trusted) / float64(len(articles))
+	}
+
+	return signals
+}
+
+// ComputeQualityScore is the weighted mean of the signals, an answer with no sources at all scores 0
+func ComputeQualityScore(signals QualitySignals, qualityConfig config.QualityConfig) float64 {
+	if signals.Coverage == 0 {
+		return 0
+	}
+
+	weighted := []struct{ value, weight float64 }{
+		{signals.Coverage, qualityConfig.CoverageWeight},
+		{signals.Relevance, qualityConfig.RelevanceWeight},
+		{signals.NoFallback, qualityConfig.FallbackWeight},
+		{signals.Scraping, qualityConfig.ScrapingWeight},
+		{signals.Complete, qualityConfig.TruncationWeight},
+	}
+	if signals.HasCredibility {
+		weighted = append(weighted, struct{ value, weight float64 }{signals.Credibility, qualityConfig.CredibilityWeight})
+	}
+
+	var sum, weights float64
+	for _, signal := range weighted {
+		sum += signal.value * signal.weight
+		weights += signal.weight
+	}
+	if weights == 0 {
+		return 0
+	}
+
+	return math.Round(clampUnit(sum/weights)*1000) / 1000
+}
+
+// isTrustedSource matches the configured entries against the source name and the article's domain
+func isTrustedSource(article models.NewsArticle, trustedSources []string) bool {
+	host := ""
+	if parsed, err := url.Parse(article.URL); err == nil {
+		host = strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	}
+
+	for _, trusted := range trustedSources {
+		trusted = strings.ToLower(strings.TrimSpace(trusted))
+		if trusted == "" {
+			continue
+		}
+		if strings.EqualFold(article.Source, trusted) || host == trusted || strings.HasSuffix(host, "."+trusted) {
+			return true
+		}
+	}
+	return false
+}
+
+func clampUnit(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func qualityArticles(n int, relevance float64, trusted int) []models.NewsArticle {
+	articles := make([]models.NewsArticle, n)
+	for i := range articles {
+		host := "unknown-blog.net"
+		if i < trusted {
+			host = "www.reuters.com"
+		}
+		articles[i] = models.NewsArticle{Title: fmt.Sprintf("story %d", i), URL: fmt.Sprintf("https://%s/story-%d", host, i), RelevanceScore: relevance}
+	}
+	return articles
+}
+
+func TestQualityScoreOfHighQualityAndDegradedRuns(t *testing.T) {
+	qualityConfig := loadTestConfig(t, map[string]string{"QUALITY_TRUSTED_SOURCES": "reuters.com"}).Quality
+
+	tests := []struct {
+		name   string
+		stats  models.ProcessingStats
+		setup  func(*models.WorkflowContext)
+		want   float64
+		wantLo bool
+	}{
+		{
+			name:  "high quality",
+			stats: models.ProcessingStats{ScrapeAttempts: 5, ArticlesScraped: 5},
+			setup: func(wc *models.WorkflowContext) { wc.Articles = qualityArticles(5, 0.9, 5) },
+			// every signal is full except relevance at 0.9
+			want: 0.975,
+		},
+		{
+			name:  "degraded",
+			stats: models.ProcessingStats{ScrapeAttempts: 2, ArticlesScraped: 1, Fallbacks: []string{"relevancy_llm"}, SummaryTruncated: true},
+			setup: func(wc *models.WorkflowContext) { wc.Articles = qualityArticles(2, 0.5, 1) },
+			// coverage 0.4, relevance 0.5, fallback 0, scraping 0.5, truncated 0, credibility 0.5
+			want:   0.35,
+			wantLo: true,
+		},
+		{
+			name:   "no sources",
+			setup:  func(wc *models.WorkflowContext) {},
+			want:   0,
+			wantLo: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflowCtx := models.NewWorkflowContext(models.WorkflowRequest{Query: "rates"}, "test-request")
+			workflowCtx.ProcessingStats = tt.stats
+			tt.setup(workflowCtx)
+
+			score := ComputeQualityScore(collectQualitySignals(workflowCtx, qualityConfig), qualityConfig)
+
+			if math.Abs(score-tt.want) > 1e-9 {
+				t.Errorf("quality score = %.3f, want %.3f", score, tt.want)
+			}
+			if low := score < qualityConfig.LowThreshold; low != tt.wantLo {
+				t.Errorf("score %.3f below the %.2f threshold = %t, want %t", score, qualityConfig.LowThreshold, low, tt.wantLo)
+			}
+		})
+	}
+}
+
+func TestQualityScoreSkipsCredibilityWithoutTrustedSources(t *testing.T) {
+	qualityConfig := loadTestConfig(t, nil).Quality
+	workflowCtx := models.NewWorkflowContext(models.WorkflowRequest{Query: "rates"}, "test-request")
+	workflowCtx.Articles = qualityArticles(5, 1, 0)
+
+	signals := collectQualitySignals(workflowCtx, qualityConfig)
+
+	if signals.HasCredibility {
+		t.Error("credibility signal collected without trusted sources")
+	}
+	if score := ComputeQualityScore(signals, qualityConfig); score != 1 {
+		t.Errorf("quality score = %.3f, want 1 with the credibility weight left out", score)
+	}
+}
+
+func TestQualityScoreFeedsTheWorkflowStats(t *testing.T) {
+	cfg := loadTestConfig(t, nil)
+	orchestrator := newTestOrchestrator(t, cfg)
+
+	for _, relevance := range []float64{1, 0} {
+		executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "rates"})
+		executor.workflowCtx.Articles = qualityArticles(1, relevance, 0)
+		executor.workflowCtx.RecordFallback("vector_search")
+		executor.scoreQuality()
+
+		if executor.workflowCtx.ProcessingStats.QualityScore == nil {
+			t.Fatal("quality score was not stored in the processing stats")
+		}
+	}
+
+	stats := orchestrator.qualityStats()
+	if stats["scored_workflows"] != int64(2) || stats["low_quality"] != int64(1) {
+		t.Errorf("quality stats = %v, want 2 scored with 1 below the threshold", stats)
+	}
+}
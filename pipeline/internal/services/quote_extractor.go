@@ -0,0 +1,174 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// quotes shorter than this are usually scare quotes or titles, longer ones are whole passages
+const (
+	minQuoteWords  = 4
+	maxQuoteLength = 400
+)
+
+// how far either side of a quote the attribution is looked for
+const attributionWindow = 120
+
+const speakerName = `((?:[A-Z][\p{L}'.-]*\s+){0,3}[A-Z][\p{L}'.-]*)`
+
+const speechVerbs = `(?:said|says|told [\p{L}]+|added|wrote|explained|stated|noted|argued|warned|insisted|declared|posted|tweeted)`
+
+var (
+	// ` said Jane Doe` or `, Jane Doe said` right after the closing quote
+	attributionAfterVerbFirst = regexp.MustCompile(`^[,.]?\s*` + speechVerbs + `\s+` + speakerName)
+	attributionAfterNameFirst = regexp.MustCompile(`^[,.]?\s*` + speakerName + `\s+` + speechVerbs + `\b`)
+	// `Jane Doe said: ` or `According to Jane Doe, ` right before the opening quote
+	attributionBeforeNameFirst = regexp.MustCompile(speakerName + `\s+` + speechVerbs + `\s*[,:]?\s*$`)
+	attributionBeforeAccording = regexp.MustCompile(`[Aa]ccording to\s+` + speakerName + `\s*,\s*$`)
+)
+
+// words that open a capitalised phrase but never name a speaker
+var nonSpeakerWords = map[string]bool{
+	"he": true, "she": true, "they": true, "it": true, "we": true, "i": true, "you": true, "the": true,
+	"a": true, "an": true, "this": true, "that": true, "officials": true, "critics": true, "sources": true,
+	"experts": true, "analysts": true, "people": true, "some": true, "many": true, "others": true,
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true, "friday": true, "saturday": true,
+	"sunday": true, "yesterday": true, "today": true,
+}
+
+// quoteOpeners pairs every opening quotation mark with the mark that closes it
+var quoteOpeners = map[rune]rune{
+	'"': '"',
+	'“': '”',
+	'„': '“',
+	'«': '»',
+}
+
+type quotedSpan struct {
+	text       string
+	start, end int // byte offsets of the opening and closing marks
+}
+
+// ExtractQuotes returns up to limit direct quotations from text that are attributed to a named speaker.
+// Quotes nested inside a quote stay part of the outer one, unattributed quoted phrases are dropped.
+func ExtractQuotes(text string, limit int) []models.ArticleQuote {
+	if limit <= 0 || text == "" {
+		return nil
+	}
+
+	var quotes []models.ArticleQuote
+	seen := make(map[string]bool)
+
+	for _, span := range findQuotedSpans(text) {
+		// a comma before the closing mark belongs to the surrounding sentence
+		quote := strings.TrimRight(strings.Join(strings.Fields(span.text), " "), ",")
+		if len(strings.Fields(quote)) < minQuoteWords || len(quote) > maxQuoteLength {
+			continue
+		}
+
+		speaker := quoteSpeaker(text, span)
+		if speaker == "" {
+			continue
+		}
+
+		key := strings.ToLower(quote)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		quotes = append(quotes, models.ArticleQuote{Text: quote, Speaker: speaker})
+		if len(quotes) >= limit {
+			break
+		}
+	}
+
+	return quotes
+}
+
+// findQuotedSpans returns the outermost quoted passages, tracking nesting so a quote within a quote does not
+// end the outer one. Open quotes are abandoned at paragraph breaks.
+func findQuotedSpans(text string) []quotedSpan {
+	var spans []quotedSpan
+	var closers []rune
+	start := 0
+
+	for offset, r := range text {
+		if r == '\n' {
+			closers = closers[:0]
+			continue
+		}
+
+		if depth := len(closers); depth > 0 && r == closers[depth-1] {
+			closers = closers[:depth-1]
+			if len(closers) == 0 {
+				_, openerLength := utf8.DecodeRuneInString(text[start:])
+				spans = append(spans, quotedSpan{text: text[start+openerLength : offset], start: start, end: offset + utf8.RuneLen(r)})
+			}
+			continue
+		}
+
+		if closer, ok := quoteOpeners[r]; ok {
+			if len(closers) == 0 {
+				start = offset
+			}
+			closers = append(closers, closer)
+		}
+	}
+
+	return spans
+}
+
+// quoteSpeaker looks for a speech verb and a name just after the quote, then just before it
+func quoteSpeaker(text string, span quotedSpan) string {
+	after := text[span.end:min(len(text), span.end+attributionWindow)]
+	if newline := strings.IndexByte(after, '\n'); newline >= 0 {
+		after = after[:newline]
+	}
+	for _, pattern := range []*regexp.Regexp{attributionAfterVerbFirst, attributionAfterNameFirst} {
+		if match := pattern.FindStringSubmatch(after); match != nil {
+			if speaker := cleanSpeaker(match[1]); speaker != "" {
+				return speaker
+			}
+		}
+	}
+
+	before := text[max(0, span.start-attributionWindow):span.start]
+	if newline := strings.LastIndexByte(before, '\n'); newline >= 0 {
+		before = before[newline+1:]
+	}
+	for _, pattern := range []*regexp.Regexp{attributionBeforeNameFirst, attributionBeforeAccording} {
+		if match := pattern.FindStringSubmatch(before); match != nil {
+			if speaker := cleanSpeaker(match[1]); speaker != "" {
+				return speaker
+			}
+		}
+	}
+
+	return ""
+}
+
+func cleanSpeaker(name string) string {
+	name = strings.TrimRight(strings.TrimSpace(name), ".,")
+	words := strings.Fields(name)
+	if len(words) == 0 || nonSpeakerWords[strings.ToLower(words[0])] {
+		return ""
+	}
+	return name
+}
+
+// quotesPromptSection lists an article's quotes for the summarizer so it can cite them verbatim
+func quotesPromptSection(quotes []models.ArticleQuote) string {
+	if len(quotes) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Quotes (verbatim, cite exactly as written with the speaker):")
+	for _, quote := range quotes {
+		builder.WriteString("\n- \"" + quote.Text + "\" - " + quote.Speaker)
+	}
+	return builder.String()
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// quotedArticle mixes attributed quotes in straight, curly, nested and german marks with quoted phrases nobody is credited with
+const quotedArticle = `The budget passed late on Tuesday after a week of talks.
+"We will not raise income taxes this year," said Finance Minister Jane Doe.
+“The vote was closer than anyone in the chamber expected,” Tom Ellis told reporters.
+According to Maria Lopez, “turnout in the northern districts was the highest in decades.”
+"He said “no deal” twice before the committee agreed to the final text," said Ana Ruiz.
+„Wir werden diesen Haushalt sorgfältig prüfen“, sagte niemand, but Klaus Weber said „the details still matter a great deal to us“.
+The so-called "peace dividend" never arrived.
+"We are still counting the ballots in several districts," officials said.
+Critics called the plan "a gift to the wealthy few at everyone else's expense".
+"We will not raise income taxes this year," Jane Doe said again on Wednesday.`
+
+func TestExtractQuotesKeepsOnlyAttributedQuotes(t *testing.T) {
+	quotes := ExtractQuotes(quotedArticle, 10)
+
+	want := []models.ArticleQuote{
+		{Text: "We will not raise income taxes this year", Speaker: "Finance Minister Jane Doe"},
+		{Text: "The vote was closer than anyone in the chamber expected", Speaker: "Tom Ellis"},
+		{Text: "turnout in the northern districts was the highest in decades.", Speaker: "Maria Lopez"},
+		{Text: "He said “no deal” twice before the committee agreed to the final text", Speaker: "Ana Ruiz"},
+		{Text: "the details still matter a great deal to us", Speaker: "Klaus Weber"},
+	}
+	if !slices.Equal(quotes, want) {
+		t.Errorf("ExtractQuotes() =\n%q\nwant\n%q", quotes, want)
+	}
+}
+
+func TestExtractQuotesRespectsTheLimit(t *testing.T) {
+	if quotes := ExtractQuotes(quotedArticle, 2); len(quotes) != 2 {
+		t.Errorf("%d quotes, want the limit of 2", len(quotes))
+	}
+	if quotes := ExtractQuotes(quotedArticle, 0); quotes != nil {
+		t.Errorf("ExtractQuotes() with no limit = %q, want none", quotes)
+	}
+}
+
+func TestSummarizerSeesExtractedQuotesWhenEnabled(t *testing.T) {
+	for _, enabled := range []string{"true", "false"} {
+		t.Run("enabled "+enabled, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"QUOTE_EXTRACTION_ENABLED": enabled}), "elections", models.IntentNewNewsQuery)
+			for i := range workflow.corpus.Articles {
+				workflow.corpus.Articles[i].Content = quotedArticle
+			}
+
+			if _, err := workflow.run("workflow-quotes"); err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var summaryPrompt string
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") {
+					summaryPrompt = call.Prompt
+				}
+			}
+			if summaryPrompt == "" {
+				t.Fatal("the summarizer was never called")
+			}
+			const quoteLine = `- "We will not raise income taxes this year" - Finance Minister Jane Doe`
+			if cited := strings.Contains(summaryPrompt, quoteLine); cited != (enabled == "true") {
+				t.Errorf("summary prompt lists the quote = %t, want %s", cited, enabled)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/redis/go-redis/v9"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -17,6 +18,12 @@ type RedisService struct {
 	memory  *redis.Client
 	logger  *logger.Logger
 	config  config.RedisConfig
+
+	healthMu      sync.Mutex
+	available     bool
+	lastHealthRun time.Time
+	// a health check is running, callers meanwhile get the last known state
+	probing bool
 }
 
 func NewRedisService(config config.RedisConfig, log *logger.Logger) (*RedisService, error) {
@@ -44,7 +51,13 @@ func NewRedisService(config config.RedisConfig, log *logger.Logger) (*RedisServi
 	}
 
 	if err := service.testConnection(); err != nil {
-		return nil, fmt.Errorf("connection to Redis failed: %w", err)
+		if !config.AllowStateless {
+			return nil, fmt.Errorf("connection to Redis failed: %w", err)
+		}
+		log.WithError(err).Warn("Redis unreachable at startup, workflows will run in stateless mode until it recovers")
+	} else {
+		service.available = true
+		service.lastHealthRun = time.Now()
 	}
 
 	log.Info("Enhanced Conversational Redis Service Initialized Successfully",
@@ -99,8 +112,16 @@ func configureRedisOptions(opt *redis.Options, cfg config.RedisConfig) {
 	opt.DialTimeout = cfg.DialTimeout
 }
 
+func agentUpdatesStream(userID string) string {
+	return fmt.Sprintf("user:%s:agent_updates", userID)
+}
+
 func (service *RedisService) PublishAgentUpdate(ctx context.Context, userID string, update *models.AgentUpdate) error {
-	streamName := fmt.Sprintf("user:%s:agent_updates", userID)
+	// the user's client never asked for a replay, its updates stay off their stream
+	if replaying(ctx) {
+		return nil
+	}
+	streamName := agentUpdatesStream(userID)
 
 	updateData := map[string]interface{}{
 		"type":        "agent_update",
@@ -131,6 +152,11 @@ func (service *RedisService) PublishAgentUpdate(ctx context.Context, userID stri
 		updateData["error"] = update.Error
 	}
 
+	if update.Event != "" {
+		updateData["type"] = "event"
+		updateData["event"] = string(update.Event)
+	}
+
 	result, err := service.streams.XAdd(ctx, &redis.XAddArgs{
 		Stream: streamName,
 		Values: updateData,
@@ -157,8 +183,76 @@ func (service *RedisService) PublishAgentUpdate(ctx context.Context, userID stri
 	return nil
 }
 
+// ReadAgentUpdates returns updates published to the user's stream after lastID, blocking up to block for new ones.
+// The returned id is the position to resume from, it equals lastID when nothing arrived.
+func (service *RedisService) ReadAgentUpdates(ctx context.Context, userID, lastID string, block time.Duration) ([]*models.AgentUpdate, string, error) {
+	streams, err := service.streams.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{agentUpdatesStream(userID), lastID},
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, lastID, nil
+		}
+		return nil, lastID, models.NewExternalError("REDIS_READ_FAILED", "Failed to read agent updates").WithCause(err)
+	}
+
+	var updates []*models.AgentUpdate
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			lastID = message.ID
+			updates = append(updates, agentUpdateFromStream(message.Values))
+		}
+	}
+
+	return updates, lastID, nil
+}
+
+// agentUpdateFromStream is the inverse of the field layout written by PublishAgentUpdate
+func agentUpdateFromStream(values map[string]interface{}) *models.AgentUpdate {
+	field := func(key string) string {
+		value, _ := values[key].(string)
+		return value
+	}
+
+	update := &models.AgentUpdate{
+		WorkflowID: field("workflow_id"),
+		RequestID:  field("request_id"),
+		AgentName:  field("agent_name"),
+		Event:      models.EventType(field("event")),
+		Status:     models.AgentStatus(field("status")),
+		Message:    field("message"),
+		Error:      field("error"),
+		Retryable:  field("retryable") == "1" || field("retryable") == "true",
+	}
+
+	if progress, err := strconv.ParseFloat(field("progress"), 64); err == nil {
+		update.Progress = progress
+	}
+	if timestamp, err := time.Parse(time.RFC3339, field("timestamp")); err == nil {
+		update.Timestamp = timestamp
+	}
+	if processingMs, err := strconv.ParseInt(field("processing_time"), 10, 64); err == nil {
+		update.ProcessingTime = time.Duration(processingMs) * time.Millisecond
+	}
+	if data := field("data"); data != "" {
+		if err := json.Unmarshal([]byte(data), &update.Data); err != nil {
+			update.Data = nil
+		}
+	}
+
+	return update
+}
+
 // Enhanced: Get conversation context with full conversation exchanges
 func (service *RedisService) GetConversationContext(ctx context.Context, userID string) (*models.ConversationContext, error) {
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "conversation_context", userID, func(ctx context.Context) (*models.ConversationContext, error) {
+			return service.GetConversationContext(ctx, userID)
+		})
+	}
+
 	key := fmt.Sprintf("user:%s:conversation_context", userID)
 	startTime := time.Now()
 
@@ -207,15 +301,30 @@ func (service *RedisService) GetConversationContext(ctx context.Context, userID
 		UpdatedAt: time.Now(),
 	}
 
-	// Parse enhanced conversation fields
-	if err := parseJSONField(data, "exchanges", &context.Exchanges); err != nil {
-		service.logger.WithError(err).Warn("Failed to parse conversation exchanges")
+	// Parse enhanced conversation fields, an oversized or corrupt exchange list is cut back to its recent exchanges
+	exchanges, repaired := decodeExchanges(data["exchanges"], service.config.MaxContextBytes)
+	context.Exchanges = exchanges
+	if repaired {
+		service.logger.Warn("Repaired conversation context exchanges",
+			"event", "conversation_context_repaired",
+			"user_id", userID,
+			"stored_bytes", len(data["exchanges"]),
+			"max_bytes", service.config.MaxContextBytes,
+			"kept_exchanges", len(exchanges))
 	}
 
 	if err := parseJSONField(data, "current_topics", &context.CurrentTopics); err != nil {
 		service.logger.WithError(err).Warn("Failed to parse current_topics")
 	}
 
+	if err := parseJSONField(data, "tracked_topics", &context.TrackedTopics); err != nil {
+		service.logger.WithError(err).Warn("Failed to parse tracked_topics")
+	}
+
+	if err := parseJSONField(data, "topic_centroid", &context.TopicCentroid); err != nil {
+		service.logger.WithError(err).Warn("Failed to parse topic_centroid")
+	}
+
 	if err := parseJSONField(data, "recent_keywords", &context.RecentKeywords); err != nil {
 		service.logger.WithError(err).Warn("Failed to parse recent_keywords")
 	}
@@ -294,6 +403,14 @@ func (service *RedisService) StoreConversationContext(ctx context.Context, userI
 		data["current_topics"] = string(topicsJSON)
 	}
 
+	if trackedJSON, err := json.Marshal(conversationContext.TrackedTopics); err == nil {
+		data["tracked_topics"] = string(trackedJSON)
+	}
+
+	if centroidJSON, err := json.Marshal(conversationContext.TopicCentroid); err == nil {
+		data["topic_centroid"] = string(centroidJSON)
+	}
+
 	if keywordsJSON, err := json.Marshal(conversationContext.RecentKeywords); err == nil {
 		data["recent_keywords"] = string(keywordsJSON)
 	}
@@ -345,6 +462,10 @@ func (service *RedisService) StoreConversationContext(ctx context.Context, userI
 
 // Enhanced: Update conversation context (alias for backward compatibility)
 func (service *RedisService) UpdateConversationContext(ctx context.Context, conversationContext *models.ConversationContext) error {
+	// a replayed workflow must not add its exchange to the user's real conversation
+	if replaying(ctx) {
+		return nil
+	}
 	return service.StoreConversationContext(ctx, conversationContext.UserID, conversationContext)
 }
 
@@ -435,6 +556,218 @@ func (service *RedisService) GetWorkflowState(ctx context.Context, workflowID st
 	return &workflowContext, nil
 }
 
+func workflowResultKey(workflowID string) string {
+	return fmt.Sprintf("workflow:%s:result", workflowID)
+}
+
+// StoreWorkflowResult keeps a completed workflow's result under its own key, outliving the workflow state
+func (service *RedisService) StoreWorkflowResult(ctx context.Context, result *models.WorkflowResult, ttl time.Duration) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return models.NewInternalError("SERIALIZATION_FAILED", "Failed to serialize workflow result").WithCause(err)
+	}
+
+	if err := service.memory.Set(ctx, workflowResultKey(result.WorkflowID), resultJSON, ttl).Err(); err != nil {
+		return models.NewExternalError("REDIS_STORE_FAILED", "Failed to store workflow result").WithCause(err)
+	}
+	return nil
+}
+
+// GetWorkflowResult returns nil without an error when no result is stored
+func (service *RedisService) GetWorkflowResult(ctx context.Context, workflowID string) (*models.WorkflowResult, error) {
+	resultJSON, err := service.memory.Get(ctx, workflowResultKey(workflowID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, models.NewExternalError("REDIS_GET_FAILED", "Failed to get workflow result").WithCause(err)
+	}
+
+	var result models.WorkflowResult
+	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
+		return nil, models.NewInternalError("DESERIALIZATION_FAILED", "Failed to deserialize workflow result").WithCause(err)
+	}
+	return &result, nil
+}
+
+func workflowSourcesKey(workflowID string) string {
+	return fmt.Sprintf("workflow:%s:sources", workflowID)
+}
+
+// StoreWorkflowSources keeps the sources a response referenced by URL instead of carrying them inline
+func (service *RedisService) StoreWorkflowSources(ctx context.Context, sources *models.WorkflowSources, ttl time.Duration) error {
+	sourcesJSON, err := json.Marshal(sources)
+	if err != nil {
+		return models.NewInternalError("SERIALIZATION_FAILED", "Failed to serialize workflow sources").WithCause(err)
+	}
+
+	if err := service.memory.Set(ctx, workflowSourcesKey(sources.WorkflowID), sourcesJSON, ttl).Err(); err != nil {
+		return models.NewExternalError("REDIS_STORE_FAILED", "Failed to store workflow sources").WithCause(err)
+	}
+	return nil
+}
+
+// GetWorkflowSources returns nil without an error when no sources are stored
+func (service *RedisService) GetWorkflowSources(ctx context.Context, workflowID string) (*models.WorkflowSources, error) {
+	sourcesJSON, err := service.memory.Get(ctx, workflowSourcesKey(workflowID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, models.NewExternalError("REDIS_GET_FAILED", "Failed to get workflow sources").WithCause(err)
+	}
+
+	var sources models.WorkflowSources
+	if err := json.Unmarshal([]byte(sourcesJSON), &sources); err != nil {
+		return nil, models.NewInternalError("DESERIALIZATION_FAILED", "Failed to deserialize workflow sources").WithCause(err)
+	}
+	return &sources, nil
+}
+
+// GetScrapeCache returns the cached scrape for a url, a miss returns nil without an error
+func (service *RedisService) GetScrapeCache(ctx context.Context, targetURL string) (*ScrapeCacheEntry, error) {
+	key := fmt.Sprintf("scrape:%s:content", hashContent([]byte(targetURL)))
+
+	entryJSON, err := service.memory.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, models.NewExternalError("REDIS_GET_FAILED", "Failed to get scrape cache").WithCause(err)
+	}
+
+	var entry ScrapeCacheEntry
+	if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+		return nil, models.NewInternalError("DESERIALIZATION_FAILED", "Failed to deserialize scrape cache").WithCause(err)
+	}
+
+	return &entry, nil
+}
+
+func (service *RedisService) StoreScrapeCache(ctx context.Context, entry *ScrapeCacheEntry, ttl time.Duration) error {
+	key := fmt.Sprintf("scrape:%s:content", hashContent([]byte(entry.Content.URL)))
+
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return models.NewInternalError("SERIALIZATION_FAILED", "Failed to serialize scrape cache").WithCause(err)
+	}
+
+	if err := service.memory.Set(ctx, key, entryJSON, ttl).Err(); err != nil {
+		return models.NewExternalError("REDIS_STORE_FAILED", "Failed to store scrape cache").WithCause(err)
+	}
+
+	return nil
+}
+
+func transcriptKey(videoID, language string) string {
+	return fmt.Sprintf("transcript:%s:%s", videoID, language)
+}
+
+// GetTranscript returns the cached transcript of a video, a miss returns false without an error
+func (service *RedisService) GetTranscript(ctx context.Context, videoID, language string) (string, bool, error) {
+	transcript, err := service.memory.Get(ctx, transcriptKey(videoID, language)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", false, nil
+		}
+		return "", false, models.NewExternalError("REDIS_GET_FAILED", "Failed to get transcript").WithCause(err)
+	}
+	return transcript, true, nil
+}
+
+func (service *RedisService) StoreTranscript(ctx context.Context, videoID, language, transcript string, ttl time.Duration) error {
+	if err := service.memory.Set(ctx, transcriptKey(videoID, language), transcript, ttl).Err(); err != nil {
+		return models.NewExternalError("REDIS_STORE_FAILED", "Failed to store transcript").WithCause(err)
+	}
+	return nil
+}
+
+func trendingHeadlinesKey(topic string) string {
+	return fmt.Sprintf("trending:%s:headlines", topic)
+}
+
+// GetTrendingHeadlines returns the cached top headlines of a topic, a miss returns false without an error
+func (service *RedisService) GetTrendingHeadlines(ctx context.Context, topic string) ([]string, bool, error) {
+	headlinesJSON, err := service.memory.Get(ctx, trendingHeadlinesKey(topic)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, models.NewExternalError("REDIS_GET_FAILED", "Failed to get trending headlines").WithCause(err)
+	}
+
+	var headlines []string
+	if err := json.Unmarshal([]byte(headlinesJSON), &headlines); err != nil {
+		return nil, false, models.NewInternalError("DESERIALIZATION_FAILED", "Failed to deserialize trending headlines").WithCause(err)
+	}
+	return headlines, true, nil
+}
+
+func (service *RedisService) StoreTrendingHeadlines(ctx context.Context, topic string, headlines []string, ttl time.Duration) error {
+	headlinesJSON, err := json.Marshal(headlines)
+	if err != nil {
+		return models.NewInternalError("SERIALIZATION_FAILED", "Failed to serialize trending headlines").WithCause(err)
+	}
+	if err := service.memory.Set(ctx, trendingHeadlinesKey(topic), headlinesJSON, ttl).Err(); err != nil {
+		return models.NewExternalError("REDIS_STORE_FAILED", "Failed to store trending headlines").WithCause(err)
+	}
+	return nil
+}
+
+// ClaimTrendingSuggestion reserves a user's trending suggestion, false while the previous one is younger than interval
+func (service *RedisService) ClaimTrendingSuggestion(ctx context.Context, userID string, interval time.Duration) (bool, error) {
+	if interval <= 0 {
+		return true, nil
+	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "trending_claim", userID, func(ctx context.Context) (bool, error) {
+			return service.ClaimTrendingSuggestion(ctx, userID, interval)
+		})
+	}
+	claimed, err := service.memory.SetNX(ctx, fmt.Sprintf("user:%s:trending_suggested", userID), time.Now().Format(time.RFC3339), interval).Result()
+	if err != nil {
+		return false, models.NewExternalError("REDIS_STORE_FAILED", "Failed to claim trending suggestion").WithCause(err)
+	}
+	return claimed, nil
+}
+
+func articleContentKey(articleID string) string {
+	return fmt.Sprintf("article:%s:content", articleID)
+}
+
+func (service *RedisService) StoreArticleContent(ctx context.Context, articleID, content string, ttl time.Duration) error {
+	if err := service.memory.Set(ctx, articleContentKey(articleID), content, ttl).Err(); err != nil {
+		return models.NewExternalError("REDIS_STORE_FAILED", "Failed to store article content").WithCause(err)
+	}
+	return nil
+}
+
+// GetArticleContents fetches stored bodies in one round trip, ids without content are left out of the result
+func (service *RedisService) GetArticleContents(ctx context.Context, articleIDs []string) (map[string]string, error) {
+	contents := make(map[string]string, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return contents, nil
+	}
+
+	keys := make([]string, len(articleIDs))
+	for i, articleID := range articleIDs {
+		keys[i] = articleContentKey(articleID)
+	}
+
+	values, err := service.memory.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, models.NewExternalError("REDIS_GET_FAILED", "Failed to get article content").WithCause(err)
+	}
+
+	for i, value := range values {
+		if content, ok := value.(string); ok {
+			contents[articleIDs[i]] = content
+		}
+	}
+
+	return contents, nil
+}
+
 func (service *RedisService) HealthCheck(ctx context.Context) error {
 	if err := service.memory.Ping(ctx).Err(); err != nil {
 		return fmt.Errorf("Memory Connection Unhealthy: %w", err)
@@ -447,6 +780,41 @@ func (service *RedisService) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// IsAvailable reports whether redis answered its last health check, re-checking at most once per HealthCheckInterval.
+// The check runs outside the lock and only one at a time, every other caller gets the last known state meanwhile.
+func (service *RedisService) IsAvailable(ctx context.Context) bool {
+	service.healthMu.Lock()
+	fresh := !service.lastHealthRun.IsZero() && time.Since(service.lastHealthRun) < service.config.HealthCheckInterval
+	if fresh || service.probing {
+		available := service.available
+		service.healthMu.Unlock()
+		return available
+	}
+	service.probing = true
+	service.healthMu.Unlock()
+
+	// the result is shared with every caller, so the caller going away must not fail it
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	err := service.HealthCheck(checkCtx)
+
+	service.healthMu.Lock()
+	defer service.healthMu.Unlock()
+
+	service.probing = false
+	wasAvailable := service.available
+	service.available = err == nil
+	service.lastHealthRun = time.Now()
+
+	if err != nil && wasAvailable {
+		service.logger.WithError(err).Warn("Redis became unavailable, switching to stateless mode")
+	} else if err == nil && !wasAvailable {
+		service.logger.Info("Redis is reachable again, leaving stateless mode")
+	}
+
+	return service.available
+}
+
 // New: Get conversation statistics for monitoring
 func (service *RedisService) GetConversationStats(ctx context.Context, userID string) (map[string]interface{}, error) {
 	key := fmt.Sprintf("user:%s:conversation_context", userID)
@@ -479,3 +847,106 @@ func (service *RedisService) GetConversationStats(ctx context.Context, userID st
 
 	return stats, nil
 }
+
+const allSubscriptionsKey = "subscriptions"
+
+func subscriptionKey(subscriptionID string) string {
+	return fmt.Sprintf("subscription:%s", subscriptionID)
+}
+
+func userSubscriptionsKey(userID string) string {
+	return fmt.Sprintf("user:%s:subscriptions", userID)
+}
+
+// StoreSubscription saves a subscription and indexes it globally for the poller and per user for listing
+func (service *RedisService) StoreSubscription(ctx context.Context, subscription *models.Subscription) error {
+	subscriptionJSON, err := json.Marshal(subscription)
+	if err != nil {
+		return models.NewInternalError("SERIALIZATION_FAILED", "Failed to serialize subscription").WithCause(err)
+	}
+
+	pipe := service.memory.TxPipeline()
+	pipe.Set(ctx, subscriptionKey(subscription.ID), subscriptionJSON, 0)
+	pipe.SAdd(ctx, allSubscriptionsKey, subscription.ID)
+	pipe.SAdd(ctx, userSubscriptionsKey(subscription.UserID), subscription.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return models.NewExternalError("REDIS_STORE_FAILED", "Failed to store subscription").WithCause(err)
+	}
+
+	return nil
+}
+
+// GetSubscription returns nil without an error when the subscription does not exist
+func (service *RedisService) GetSubscription(ctx context.Context, subscriptionID string) (*models.Subscription, error) {
+	subscriptionJSON, err := service.memory.Get(ctx, subscriptionKey(subscriptionID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, models.NewExternalError("REDIS_GET_FAILED", "Failed to get subscription").WithCause(err)
+	}
+
+	var subscription models.Subscription
+	if err := json.Unmarshal([]byte(subscriptionJSON), &subscription); err != nil {
+		return nil, models.NewInternalError("DESERIALIZATION_FAILED", "Failed to deserialize subscription").WithCause(err)
+	}
+
+	return &subscription, nil
+}
+
+// ListSubscriptions returns every subscription, or only the user's when userID is set
+func (service *RedisService) ListSubscriptions(ctx context.Context, userID string) ([]*models.Subscription, error) {
+	indexKey := allSubscriptionsKey
+	if userID != "" {
+		indexKey = userSubscriptionsKey(userID)
+	}
+
+	subscriptionIDs, err := service.memory.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, models.NewExternalError("REDIS_GET_FAILED", "Failed to list subscriptions").WithCause(err)
+	}
+	if len(subscriptionIDs) == 0 {
+		return []*models.Subscription{}, nil
+	}
+
+	keys := make([]string, len(subscriptionIDs))
+	for i, subscriptionID := range subscriptionIDs {
+		keys[i] = subscriptionKey(subscriptionID)
+	}
+
+	values, err := service.memory.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, models.NewExternalError("REDIS_GET_FAILED", "Failed to get subscriptions").WithCause(err)
+	}
+
+	subscriptions := make([]*models.Subscription, 0, len(values))
+	for i, value := range values {
+		subscriptionJSON, ok := value.(string)
+		if !ok {
+			// the record expired or was deleted between the two reads, drop the stale index entry
+			service.memory.SRem(ctx, indexKey, subscriptionIDs[i])
+			continue
+		}
+
+		var subscription models.Subscription
+		if err := json.Unmarshal([]byte(subscriptionJSON), &subscription); err != nil {
+			service.logger.WithError(err).Warn("Skipping unreadable subscription", "subscription_id", subscriptionIDs[i])
+			continue
+		}
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	return subscriptions, nil
+}
+
+func (service *RedisService) DeleteSubscription(ctx context.Context, subscription *models.Subscription) error {
+	pipe := service.memory.TxPipeline()
+	pipe.Del(ctx, subscriptionKey(subscription.ID))
+	pipe.SRem(ctx, allSubscriptionsKey, subscription.ID)
+	pipe.SRem(ctx, userSubscriptionsKey(subscription.UserID), subscription.ID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return models.NewExternalError("REDIS_DELETE_FAILED", "Failed to delete subscription").WithCause(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// newSilentRedis returns the address of a server that accepts connections and never answers, and a count of them
+func newSilentRedis(t *testing.T) (string, *atomic.Int64) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+
+	var accepted atomic.Int64
+	connections := make(chan net.Conn, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			connections <- conn
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		close(connections)
+		for conn := range connections {
+			conn.Close()
+		}
+	})
+	return listener.Addr().String(), &accepted
+}
+
+func TestRedisAvailabilityIsNotHeldUpByARunningProbe(t *testing.T) {
+	addr, accepted := newSilentRedis(t)
+	options := &redis.Options{Addr: addr, DialTimeout: time.Second, ReadTimeout: 5 * time.Second}
+	service := &RedisService{
+		streams:   redis.NewClient(options),
+		memory:    redis.NewClient(options),
+		logger:    newTestLogger(t),
+		config:    config.RedisConfig{HealthCheckInterval: time.Minute},
+		available: true,
+	}
+	t.Cleanup(func() { service.Close() })
+
+	probed := make(chan bool, 1)
+	go func() { probed <- service.IsAvailable(context.Background()) }()
+	waitUntil(t, "the probe is waiting on redis", func() bool { return accepted.Load() > 0 })
+
+	started := time.Now()
+	for i := 0; i < 5; i++ {
+		if !service.IsAvailable(context.Background()) {
+			t.Error("IsAvailable() = false during the probe, want the last known state")
+		}
+	}
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Errorf("IsAvailable() took %v while a probe was running, want the cached state right away", elapsed)
+	}
+	if count := accepted.Load(); count != 1 {
+		t.Errorf("%d connections during the probe, want a single probe in flight", count)
+	}
+
+	if <-probed {
+		t.Error("a probe that got no answer reported redis available")
+	}
+	if service.IsAvailable(context.Background()) {
+		t.Error("IsAvailable() = true after the failed probe")
+	}
+}
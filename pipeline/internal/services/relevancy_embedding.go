@@ -0,0 +1,85 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// RelevancyModeEmbedding ranks the vector search results in Go by similarity, recency and source credibility,
+// no gemini relevancy call is made
+const RelevancyModeEmbedding = "embedding"
+
+// IsValidRelevancyMode reports whether mode is one of the supported relevancy modes
+func IsValidRelevancyMode(mode string) bool {
+	return mode == RelevancyModeSingle || mode == RelevancyModeEnsemble || mode == RelevancyModeEmbedding
+}
+
+// relevancyMode is the configured relevancy mode unless the request's "relevancy_mode" overrides it
+func (workflowExecutor *WorkflowExecutor) relevancyMode() string {
+	if override, ok := workflowExecutor.workflowCtx.RequestString("relevancy_mode"); ok {
+		return override
+	}
+	return workflowExecutor.orchestrator.config.Workflow.RelevancyMode
+}
+
+// embeddingRelevance blends the similarity to the query with how recent the item is, halving every half life,
+// and whether it comes from a trusted source
+func (workflowExecutor *WorkflowExecutor) embeddingRelevance(similarity float64, publishedAt time.Time, trusted bool, now time.Time) float64 {
+	weights := workflowExecutor.orchestrator.config.Workflow.EmbeddingRelevancy
+
+	recency := 0.0
+	if !publishedAt.IsZero() {
+		age := max(now.Sub(publishedAt), 0)
+		recency = math.Pow(0.5, age.Hours()/weights.RecencyHalfLife.Hours())
+	}
+	credibility := 0.0
+	if trusted {
+		credibility = 1
+	}
+
+	return weights.SimilarityWeight*similarity + weights.RecencyWeight*recency + weights.CredibilityWeight*credibility
+}
+
+// rankArticlesByEmbedding scores the vector search results, drops those below the minimum score and keeps the
+// best MaxResults, their blended score becomes the relevance score
+func (workflowExecutor *WorkflowExecutor) rankArticlesByEmbedding(results []SearchResult) []models.NewsArticle {
+	weights := workflowExecutor.orchestrator.config.Workflow.EmbeddingRelevancy
+	trustedSources := workflowExecutor.orchestrator.config.Quality.TrustedSources
+	now := time.Now()
+
+	articles := make([]models.NewsArticle, 0, len(results))
+	for _, result := range results {
+		article := result.Document
+		article.RelevanceScore = workflowExecutor.embeddingRelevance(result.Similarity, article.PublishedAt, isTrustedSource(article, trustedSources), now)
+		if article.RelevanceScore >= weights.MinScore {
+			articles = append(articles, article)
+		}
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].RelevanceScore > articles[j].RelevanceScore
+	})
+	return articles[:min(len(articles), weights.MaxResults)]
+}
+
+// rankVideosByEmbedding is rankArticlesByEmbedding for videos, channels carry no credibility signal
+func (workflowExecutor *WorkflowExecutor) rankVideosByEmbedding(results []VideoSearchResult) []models.YouTubeVideo {
+	weights := workflowExecutor.orchestrator.config.Workflow.EmbeddingRelevancy
+	now := time.Now()
+
+	videos := make([]models.YouTubeVideo, 0, len(results))
+	for _, result := range results {
+		video := result.VideoDocument
+		video.RelevancyScore = workflowExecutor.embeddingRelevance(result.Similarity, video.PublishedAt, false, now)
+		if video.RelevancyScore >= weights.MinScore {
+			videos = append(videos, video)
+		}
+	}
+
+	sort.SliceStable(videos, func(i, j int) bool {
+		return videos[i].RelevancyScore > videos[j].RelevancyScore
+	})
+	return videos[:min(len(videos), weights.MaxResults)]
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newEmbeddingRelevancyExecutor(t *testing.T, env map[string]string) *WorkflowExecutor {
+	t.Helper()
+	cfg := loadTestConfig(t, env)
+	return newTestExecutor(t, newTestOrchestrator(t, cfg), models.WorkflowRequest{UserID: "user-1", Query: "elections"})
+}
+
+func TestEmbeddingRelevance(t *testing.T) {
+	// similarity 0.7, recency 0.2 and credibility 0.1 with a 48 hour half life by default
+	executor := newEmbeddingRelevancyExecutor(t, nil)
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		similarity  float64
+		publishedAt time.Time
+		trusted     bool
+		want        float64
+	}{
+		{name: "published now", similarity: 1, publishedAt: now, want: 0.9},
+		{name: "one half life old", similarity: 1, publishedAt: now.Add(-48 * time.Hour), want: 0.8},
+		{name: "trusted", similarity: 0.5, publishedAt: now.Add(-96 * time.Hour), trusted: true, want: 0.35 + 0.05 + 0.1},
+		{name: "no date", similarity: 0.5, want: 0.35},
+		{name: "dated in the future", similarity: 0, publishedAt: now.Add(time.Hour), want: 0.2},
+	}
+	for _, tt := range tests {
+		if got := executor.embeddingRelevance(tt.similarity, tt.publishedAt, tt.trusted, now); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: embeddingRelevance() = %.4f, want %.4f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRankByEmbedding(t *testing.T) {
+	executor := newEmbeddingRelevancyExecutor(t, map[string]string{
+		"EMBEDDING_RELEVANCY_MAX_RESULTS": "3", "EMBEDDING_RELEVANCY_MIN_SCORE": "0.4", "QUALITY_TRUSTED_SOURCES": "trusted.example.com",
+	})
+	now := time.Now()
+	result := func(id string, similarity float64, host string) SearchResult {
+		return SearchResult{Similarity: similarity, Document: models.NewsArticle{ID: id, URL: "https://" + host + "/" + id, PublishedAt: now}}
+	}
+
+	articles := executor.rankArticlesByEmbedding([]SearchResult{
+		result("weak", 0.2, "news.example.com"),
+		result("good", 0.8, "news.example.com"),
+		// a trusted source lifts a slightly weaker match above an untrusted one
+		result("trusted", 0.75, "trusted.example.com"),
+		result("best", 0.95, "news.example.com"),
+		result("fair", 0.6, "news.example.com"),
+	})
+	var ids []string
+	for i, article := range articles {
+		ids = append(ids, article.ID)
+		if i > 0 && article.RelevanceScore > articles[i-1].RelevanceScore {
+			t.Errorf("%s ranks below a weaker match", article.ID)
+		}
+	}
+	if got := strings.Join(ids, ","); got != "best,trusted,good" {
+		t.Errorf("ranked articles = %s, want best,trusted,good", got)
+	}
+
+	videos := executor.rankVideosByEmbedding([]VideoSearchResult{
+		{Similarity: 0.2, VideoDocument: models.YouTubeVideo{ID: "dropped", PublishedAt: now}},
+		{Similarity: 0.5, VideoDocument: models.YouTubeVideo{ID: "second", PublishedAt: now}},
+		{Similarity: 0.9, VideoDocument: models.YouTubeVideo{ID: "first", PublishedAt: now}},
+	})
+	if len(videos) != 2 || videos[0].ID != "first" || videos[1].ID != "second" {
+		t.Errorf("ranked videos = %+v, want first then second with the weak match dropped", videos)
+	}
+	if math.Abs(videos[0].RelevancyScore-(0.7*0.9+0.2)) > 1e-6 {
+		t.Errorf("video relevancy score = %.4f, want the blended score", videos[0].RelevancyScore)
+	}
+}
+
+func TestEmbeddingRelevancySkipsTheRelevancyModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		metadata map[string]any
+	}{
+		{name: "configured", env: map[string]string{"RELEVANCY_MODE": RelevancyModeEmbedding}},
+		{name: "requested", metadata: map[string]any{"relevancy_mode": RelevancyModeEmbedding}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the fake embeddings are bags of words, the corpus barely resembles the query
+			env := map[string]string{"EMBEDDING_RELEVANCY_MIN_SCORE": "0", "CHROMA_MIN_SIMILARITY": "0"}
+			for key, value := range tt.env {
+				env[key] = value
+			}
+			workflow := newTestWorkflow(t, loadTestConfig(t, env), "elections", models.IntentNewNewsQuery)
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-embedding-relevancy", Query: "what is the latest on elections", Metadata: tt.metadata,
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			summarized := false
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "news relevancy") || strings.Contains(call.SystemPrompt, "video relevancy") {
+					t.Errorf("embedding relevancy sent a relevancy prompt: %s", call.SystemPrompt)
+				}
+				summarized = summarized || strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer")
+			}
+			if !summarized {
+				t.Error("the summarizer did not run")
+			}
+
+			var articles []models.ResponseSource
+			for _, source := range response.Sources {
+				if source.Type == "article" {
+					articles = append(articles, source)
+				}
+			}
+			if len(articles) == 0 {
+				t.Fatal("no articles were ranked")
+			}
+			for _, execution := range response.AgentExecutions {
+				if execution.AgentName == "relevancy_agent" && execution.Output["embedding_only"] != true {
+					t.Errorf("relevancy execution output = %v, want embedding_only", execution.Output)
+				}
+			}
+		})
+	}
+}
+
+func TestIsValidRelevancyMode(t *testing.T) {
+	t.Parallel()
+	for mode, want := range map[string]bool{"single": true, "ensemble": true, "embedding": true, "": false, "llm": false} {
+		if got := IsValidRelevancyMode(mode); got != want {
+			t.Errorf("IsValidRelevancyMode(%q) = %t, want %t", mode, got, want)
+		}
+	}
+}
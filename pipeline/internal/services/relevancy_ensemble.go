@@ -0,0 +1,166 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	RelevancyModeSingle   = "single"
+	RelevancyModeEnsemble = "ensemble"
+
+	RelevancyCombineMax     = "max"
+	RelevancyCombineAverage = "average"
+)
+
+// RelevancyEnsemble rates articles in overlapping windows so borderline articles are judged more than once
+type RelevancyEnsemble struct {
+	WindowSize int
+	Overlap    int
+	// "max" keeps an article's best score, "average" counts windows that left it out as a zero
+	Combine string
+}
+
+type relevancyWindow struct {
+	start, end int
+}
+
+// windows covers [0, total) with windows of WindowSize sharing Overlap articles with the previous one
+func (ensemble RelevancyEnsemble) windows(total int) []relevancyWindow {
+	step := max(1, ensemble.WindowSize-ensemble.Overlap)
+
+	var windows []relevancyWindow
+	for start := 0; start < total; start += step {
+		end := min(start+ensemble.WindowSize, total)
+		windows = append(windows, relevancyWindow{start: start, end: end})
+		if end == total {
+			break
+		}
+	}
+	return windows
+}
+
+// combine merges the per window scores of every article, keyed by index into the full article set.
+// Articles no window selected are left out.
+func (ensemble RelevancyEnsemble) combine(windows []relevancyWindow, windowScores []map[int]float64) map[int]float64 {
+	selected := make(map[int]float64)
+	appearances := make(map[int]int)
+
+	for i, window := range windows {
+		if windowScores[i] == nil {
+			// a failed window says nothing about its articles
+			continue
+		}
+		for index := window.start; index < window.end; index++ {
+			appearances[index]++
+		}
+		for index, score := range windowScores[i] {
+			if ensemble.Combine == RelevancyCombineAverage {
+				selected[index] += score
+			} else if existing, ok := selected[index]; !ok || score > existing {
+				selected[index] = score
+			}
+		}
+	}
+
+	if ensemble.Combine == RelevancyCombineAverage {
+		for index := range selected {
+			selected[index] /= float64(appearances[index])
+		}
+	}
+	return selected
+}
+
+// GetRelevantArticlesEnsemble runs the relevancy agent over overlapping windows of the articles and merges the
+// scores per article, trading extra calls for steadier rankings. Results are ordered by merged score.
+func (service *GeminiService) GetRelevantArticlesEnsemble(ctx context.Context, articles []models.NewsArticle, context map[string]interface{}, ensemble RelevancyEnsemble) ([]models.NewsArticle, error) {
+	if len(articles) <= ensemble.WindowSize {
+		return service.GetRelevantArticles(ctx, articles, context)
+	}
+
+	startTime := time.Now()
+	windows := ensemble.windows(len(articles))
+	windowScores := make([]map[int]float64, len(windows))
+	errs := make([]error, len(windows))
+
+	var wg sync.WaitGroup
+	for i, window := range windows {
+		wg.Add(1)
+		go func(i int, window relevancyWindow) {
+			defer wg.Done()
+			windowScores[i], errs[i] = service.scoreRelevancyWindow(ctx, articles, window, context)
+		}(i, window)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == len(windows) {
+		return nil, fmt.Errorf("ensemble relevancy failed in every window: %w", errs[0])
+	}
+
+	scores := ensemble.combine(windows, windowScores)
+	indexes := make([]int, 0, len(scores))
+	for index := range scores {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(a, b int) bool {
+		if scores[indexes[a]] != scores[indexes[b]] {
+			return scores[indexes[a]] > scores[indexes[b]]
+		}
+		return indexes[a] < indexes[b]
+	})
+
+	relevantArticles := make([]models.NewsArticle, 0, len(indexes))
+	for _, index := range indexes {
+		article := articles[index]
+		article.RelevanceScore = scores[index]
+		relevantArticles = append(relevantArticles, article)
+	}
+
+	service.logger.LogService("gemini", "get_relevant_articles_ensemble", time.Since(startTime), map[string]interface{}{
+		"articles_input":    len(articles),
+		"articles_relevant": len(relevantArticles),
+		"windows":           len(windows),
+		"failed_windows":    failed,
+		"combine":           ensemble.Combine,
+	}, nil)
+
+	return relevantArticles, nil
+}
+
+// scoreRelevancyWindow rates one window, mapping the window local ids in the response back to article indexes
+func (service *GeminiService) scoreRelevancyWindow(ctx context.Context, articles []models.NewsArticle, window relevancyWindow, context map[string]interface{}) (map[int]float64, error) {
+	windowArticles := articles[window.start:window.end]
+
+	resp, err := service.GenerateContent(ctx, service.relevancyRequest(windowArticles, context))
+	if err != nil {
+		return nil, fmt.Errorf("relevancy window %d-%d failed: %w", window.start, window.end, err)
+	}
+
+	parsed, err := decodeRelevancyResponse(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("relevancy window %d-%d unparseable: %w", window.start, window.end, err)
+	}
+
+	scores := make(map[int]float64, len(parsed.RelevantArticles))
+	for _, item := range parsed.RelevantArticles {
+		// ids outside the window cannot be mapped back reliably and are dropped
+		if item.ID < 0 || item.ID >= len(windowArticles) {
+			continue
+		}
+		global := window.start + item.ID
+		if existing, ok := scores[global]; !ok || item.RelevanceScore > existing {
+			scores[global] = item.RelevanceScore
+		}
+	}
+	return scores, nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRelevancyEnsembleWindows(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		ensemble RelevancyEnsemble
+		total    int
+		want     []relevancyWindow
+	}{
+		{name: "overlapping", ensemble: RelevancyEnsemble{WindowSize: 4, Overlap: 2}, total: 10,
+			want: []relevancyWindow{{0, 4}, {2, 6}, {4, 8}, {6, 10}}},
+		{name: "last window is short", ensemble: RelevancyEnsemble{WindowSize: 4, Overlap: 1}, total: 8,
+			want: []relevancyWindow{{0, 4}, {3, 7}, {6, 8}}},
+		{name: "no overlap", ensemble: RelevancyEnsemble{WindowSize: 5}, total: 10,
+			want: []relevancyWindow{{0, 5}, {5, 10}}},
+		{name: "fits one window", ensemble: RelevancyEnsemble{WindowSize: 15, Overlap: 5}, total: 3,
+			want: []relevancyWindow{{0, 3}}},
+	}
+	for _, tt := range tests {
+		if got := tt.ensemble.windows(tt.total); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: windows(%d) = %v, want %v", tt.name, tt.total, got, tt.want)
+		}
+	}
+}
+
+// ensembleArticles are rated in windows [0, 4) and [2, 6), article 2 and 3 appear in both
+func ensembleArticles() []models.NewsArticle {
+	articles := make([]models.NewsArticle, 6)
+	for i := range articles {
+		articles[i] = models.NewsArticle{ID: fmt.Sprintf("article-%d", i), Title: fmt.Sprintf("rates story %d", i)}
+	}
+	return articles
+}
+
+// answerEnsembleWindows scores by window local id, the first window is the one listing story 0
+func answerEnsembleWindows(call fakeGeminiCall) string {
+	if strings.Contains(call.Prompt, "rates story 0") {
+		// articles 0, 2 and 3
+		return `{"relevant_articles": [{"id": 0, "relevance_score": 0.9}, {"id": 2, "relevance_score": 0.6}, {"id": 3, "relevance_score": 0.4}]}`
+	}
+	// articles 3 and 5, and an id outside the window that cannot be mapped back
+	return `{"relevant_articles": [{"id": 1, "relevance_score": 0.8}, {"id": 3, "relevance_score": 0.7}, {"id": 9, "relevance_score": 1}]}`
+}
+
+func TestEnsembleRelevancyMergesScoresAcrossWindows(t *testing.T) {
+	tests := []struct {
+		combine string
+		want    []string
+		scores  []float64
+	}{
+		{combine: RelevancyCombineMax, want: []string{"article-0", "article-3", "article-5", "article-2"}, scores: []float64{0.9, 0.8, 0.7, 0.6}},
+		// an article a window it appeared in left out counts that window as a zero
+		{combine: RelevancyCombineAverage, want: []string{"article-0", "article-5", "article-3", "article-2"}, scores: []float64{0.9, 0.7, 0.6, 0.3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.combine, func(t *testing.T) {
+			gemini, service := newFakeGemini(t, loadTestConfig(t, nil).Gemini, answerEnsembleWindows)
+
+			relevant, err := service.GetRelevantArticlesEnsemble(context.Background(), ensembleArticles(), map[string]interface{}{},
+				RelevancyEnsemble{WindowSize: 4, Overlap: 2, Combine: tt.combine})
+			if err != nil {
+				t.Fatalf("GetRelevantArticlesEnsemble() error = %v", err)
+			}
+
+			if calls := len(gemini.received()); calls != 2 {
+				t.Errorf("gemini received %d calls, want one per window", calls)
+			}
+			var ids []string
+			for i, article := range relevant {
+				ids = append(ids, article.ID)
+				if i < len(tt.scores) && math.Abs(article.RelevanceScore-tt.scores[i]) > 1e-9 {
+					t.Errorf("%s scored %v, want %v", article.ID, article.RelevanceScore, tt.scores[i])
+				}
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("relevant articles = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsembleRelevancyIgnoresAFailedWindow(t *testing.T) {
+	gemini, service := newFakeGemini(t, loadTestConfig(t, map[string]string{"GEMINI_MAX_RETRIES": "1"}).Gemini, answerEnsembleWindows)
+	gemini.fails = func(call fakeGeminiCall) bool { return !strings.Contains(call.Prompt, "rates story 0") }
+
+	relevant, err := service.GetRelevantArticlesEnsemble(context.Background(), ensembleArticles(), map[string]interface{}{},
+		RelevancyEnsemble{WindowSize: 4, Overlap: 2, Combine: RelevancyCombineAverage})
+	if err != nil {
+		t.Fatalf("GetRelevantArticlesEnsemble() error = %v", err)
+	}
+
+	// the failed window neither contributes scores nor drags the averages of the articles it held down
+	got := make(map[string]float64)
+	for _, article := range relevant {
+		got[article.ID] = article.RelevanceScore
+	}
+	want := map[string]float64{"article-0": 0.9, "article-2": 0.6, "article-3": 0.4}
+	if len(got) != len(want) {
+		t.Errorf("relevant articles = %v, want %v", got, want)
+	}
+	for id, score := range want {
+		if math.Abs(got[id]-score) > 1e-9 {
+			t.Errorf("%s scored %v, want %v", id, got[id], score)
+		}
+	}
+}
+
+func TestEnsembleRelevancyFailsWhenEveryWindowFails(t *testing.T) {
+	gemini, service := newFakeGemini(t, loadTestConfig(t, map[string]string{"GEMINI_MAX_RETRIES": "1"}).Gemini, answerEnsembleWindows)
+	gemini.fails = func(fakeGeminiCall) bool { return true }
+
+	if _, err := service.GetRelevantArticlesEnsemble(context.Background(), ensembleArticles(), map[string]interface{}{},
+		RelevancyEnsemble{WindowSize: 4, Overlap: 2, Combine: RelevancyCombineMax}); err == nil {
+		t.Error("GetRelevantArticlesEnsemble() succeeded with every window failing")
+	}
+}
+
+func TestEnsembleModeRatesTheWorkflowsArticlesInWindows(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{
+		"RELEVANCY_MODE": "ensemble", "RELEVANCY_WINDOW_SIZE": "3", "RELEVANCY_WINDOW_OVERLAP": "1",
+	})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+
+	if _, err := workflow.run("workflow-ensemble"); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	// five articles in windows of three sharing one
+	var windows int
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "news relevancy") {
+			windows++
+		}
+	}
+	if windows != 2 {
+		t.Errorf("article relevancy ran %d times, want once per window", windows)
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// normalizeRepeatQuery folds case, whitespace and trailing punctuation so a resubmitted query compares equal
+func normalizeRepeatQuery(query string) string {
+	query = strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	return strings.TrimRightFunc(query, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+}
+
+// repeatedAnswer returns the previous answer when the query repeats the last one within the repeat window, an
+// accidental double submit then costs no pipeline run. A new session or an empty previous answer never repeats.
+func (workflowExecutor *WorkflowExecutor) repeatedAnswer(now time.Time) (string, bool) {
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+	conversation := workflowExecutor.workflowCtx.ConversationContext
+	if !workflowConfig.RepeatQueryShortCircuit || len(conversation.Exchanges) == 0 {
+		return "", false
+	}
+	if reset, _ := workflowExecutor.workflowCtx.Metadata["session_reset"].(bool); reset {
+		return "", false
+	}
+
+	last := conversation.Exchanges[len(conversation.Exchanges)-1]
+	if now.Sub(last.Timestamp) > workflowConfig.RepeatQueryWindow {
+		return "", false
+	}
+	if normalizeRepeatQuery(workflowExecutor.workflowCtx.OriginalQuery) != normalizeRepeatQuery(conversation.LastQuery) {
+		return "", false
+	}
+
+	answer := conversation.LastResponse
+	if strings.TrimSpace(answer) == "" {
+		answer = conversation.LastSummary
+	}
+	return answer, strings.TrimSpace(answer) != ""
+}
+
+// answerRepeatedQuery serves the previous answer for a repeated query, flagged under Metadata["repeat_query"] so
+// the exchange is not stored a second time
+func (workflowExecutor *WorkflowExecutor) answerRepeatedQuery(ctx context.Context) bool {
+	answer, ok := workflowExecutor.repeatedAnswer(time.Now())
+	if !ok {
+		return false
+	}
+
+	workflowCtx := workflowExecutor.workflowCtx
+	workflowExecutor.logger.Info("Query repeats the previous one, returning the previous answer",
+		"workflow_id", workflowCtx.ID,
+		"user_id", workflowCtx.UserID)
+
+	workflowCtx.Response = answer
+	workflowCtx.SetIntent(workflowCtx.ConversationContext.LastIntent)
+	workflowExecutor.orchestrator.trackProgress(workflowCtx)
+	workflowCtx.Metadata["repeat_query"] = true
+	workflowExecutor.recordAgentExecution("repeat_query", 0,
+		map[string]any{"query": workflowCtx.OriginalQuery},
+		map[string]any{"response_length": len(answer)}, nil)
+
+	if err := workflowExecutor.publishAgentUpdate(ctx, "memory", models.AgentStatusCompleted, "Same question as before, returning the previous answer"); err != nil {
+		workflowExecutor.logger.WithError(err).Error("Failed to publish repeat query update")
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNormalizeRepeatQuery(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"What is the latest on elections?":     "what is the latest on elections",
+		"  what is   the latest on elections ": "what is the latest on elections",
+		"WHAT IS THE LATEST ON ELECTIONS?!":    "what is the latest on elections",
+		"is it U.S. or U.K.?":                  "is it u.s. or u.k",
+	}
+	for query, want := range tests {
+		if got := normalizeRepeatQuery(query); got != want {
+			t.Errorf("normalizeRepeatQuery(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestRepeatedAnswer(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		enabled      bool
+		query        string
+		asked        time.Time
+		response     string
+		summary      string
+		sessionReset bool
+		noExchanges  bool
+		want         string
+	}{
+		{name: "double submit", enabled: true, query: "What's new in the elections?", asked: now.Add(-5 * time.Second),
+			response: "The previous answer.", want: "The previous answer."},
+		{name: "off", query: "What's new in the elections", asked: now.Add(-5 * time.Second), response: "The previous answer."},
+		{name: "outside the window", enabled: true, query: "What's new in the elections", asked: now.Add(-time.Minute), response: "The previous answer."},
+		{name: "a different query", enabled: true, query: "What's new in the markets", asked: now.Add(-5 * time.Second), response: "The previous answer."},
+		{name: "new session", enabled: true, query: "What's new in the elections", asked: now.Add(-5 * time.Second),
+			response: "The previous answer.", sessionReset: true},
+		{name: "first query", enabled: true, query: "What's new in the elections", noExchanges: true, response: "The previous answer."},
+		{name: "summary when the response is empty", enabled: true, query: "What's new in the elections", asked: now.Add(-5 * time.Second),
+			summary: "The previous summary.", want: "The previous summary."},
+		{name: "nothing to repeat", enabled: true, query: "What's new in the elections", asked: now.Add(-5 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{"REPEAT_QUERY_SHORT_CIRCUIT": fmt.Sprint(tt.enabled), "REPEAT_QUERY_WINDOW": "30s"})
+			executor := newTestExecutor(t, newTestOrchestrator(t, cfg), models.WorkflowRequest{UserID: "user-1", Query: tt.query})
+			conversation := &executor.workflowCtx.ConversationContext
+			conversation.LastQuery = "what's new in the elections"
+			conversation.LastResponse = tt.response
+			conversation.LastSummary = tt.summary
+			if !tt.noExchanges {
+				conversation.Exchanges = []models.ConversationExchange{{UserQuery: conversation.LastQuery, Timestamp: tt.asked}}
+			}
+			if tt.sessionReset {
+				executor.workflowCtx.Metadata["session_reset"] = true
+			}
+
+			answer, ok := executor.repeatedAnswer(now)
+			if answer != tt.want || ok != (tt.want != "") {
+				t.Errorf("repeatedAnswer() = %q, %t, want %q", answer, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoubleSubmittedQueryReturnsThePreviousAnswer(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantRepeat bool
+	}{
+		{name: "short circuited", enabled: true, wantRepeat: true},
+		{name: "off", enabled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"REPEAT_QUERY_SHORT_CIRCUIT": fmt.Sprint(tt.enabled)}),
+				"elections", models.IntentNewNewsQuery)
+			_, redisService := newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second, MaxContextBytes: 1 << 20})
+			workflow.orchestrator.redisService = redisService
+
+			first, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-first", Query: "What is the latest on elections?",
+			})
+			if err != nil {
+				t.Fatalf("first ExecuteWorkflow() error = %v", err)
+			}
+			callsAfterFirst := len(workflow.gemini.received())
+
+			second, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-second", Query: "what is the latest on elections",
+			})
+			if err != nil {
+				t.Fatalf("second ExecuteWorkflow() error = %v", err)
+			}
+
+			if second.Repeat != tt.wantRepeat || first.Repeat {
+				t.Errorf("repeat flags = %t then %t, want false then %t", first.Repeat, second.Repeat, tt.wantRepeat)
+			}
+			newCalls := len(workflow.gemini.received()) - callsAfterFirst
+			conversation, err := redisService.GetConversationContext(context.Background(), "user-1")
+			if err != nil {
+				t.Fatalf("GetConversationContext() error = %v", err)
+			}
+			if !tt.wantRepeat {
+				if newCalls == 0 {
+					t.Error("the repeated query did not run the pipeline with the short circuit off")
+				}
+				return
+			}
+			if second.Message != first.Message {
+				t.Errorf("repeated message = %q, want the previous %q", second.Message, first.Message)
+			}
+			if newCalls != 0 {
+				t.Errorf("the repeated query made %d model calls, want none", newCalls)
+			}
+			if len(conversation.Exchanges) != 1 {
+				t.Errorf("conversation has %d exchanges, want the repeat left out", len(conversation.Exchanges))
+			}
+		})
+	}
+}
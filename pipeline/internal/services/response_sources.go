@@ -0,0 +1,94 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"sort"
+	"time"
+)
+
+const (
+	SourceSortRelevance = "relevance"
+	SourceSortFreshness = "freshness"
+)
+
+// relevanceBand buckets scores so small score differences do not override freshness in relevance-first ordering
+func relevanceBand(score float64) int {
+	switch {
+	case score >= 0.75:
+		return 2
+	case score >= 0.4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// buildResponseSources lists the articles and videos behind an answer with their freshness tier, in display order
+func buildResponseSources(articles []models.NewsArticle, videos []models.YouTubeVideo, now time.Time, order string) []models.ResponseSource {
+	sources := make([]models.ResponseSource, 0, len(articles)+len(videos))
+
+	for _, article := range articles {
+		source := newResponseSource("article", article.Title, article.URL, article.Source, article.PublishedAt, article.RelevanceScore, now)
+		source.ArticleType = article.ArticleType
+		source.Sentiment = article.Sentiment
+		sources = append(sources, source)
+	}
+	for _, video := range videos {
+		source := newResponseSource("video", video.Title, video.URL, video.Channel, video.PublishedAt, video.RelevancyScore, now)
+		source.Duration = formatVideoDuration(video.DurationSeconds)
+		sources = append(sources, source)
+	}
+
+	sortResponseSources(sources, order)
+	return sources
+}
+
+func newResponseSource(sourceType, title, url, source string, publishedAt time.Time, relevance float64, now time.Time) models.ResponseSource {
+	responseSource := models.ResponseSource{
+		Type:           sourceType,
+		Title:          title,
+		URL:            url,
+		Source:         source,
+		Freshness:      models.ComputeFreshnessTier(publishedAt, now),
+		RelevanceScore: relevance,
+	}
+	if responseSource.Freshness != models.FreshnessUndated {
+		published := publishedAt
+		responseSource.PublishedAt = &published
+	}
+	return responseSource
+}
+
+// sortResponseSources orders by relevance band then freshness, or by freshness tier then relevance.
+// Undated sources always sort after dated ones within the same group.
+func sortResponseSources(sources []models.ResponseSource, order string) {
+	sort.SliceStable(sources, func(i, j int) bool {
+		a, b := sources[i], sources[j]
+		aFresh, bFresh := models.FreshnessRank[a.Freshness], models.FreshnessRank[b.Freshness]
+
+		if order == SourceSortFreshness {
+			if aFresh != bFresh {
+				return aFresh < bFresh
+			}
+			if a.RelevanceScore != b.RelevanceScore {
+				return a.RelevanceScore > b.RelevanceScore
+			}
+			return newerSource(a, b)
+		}
+
+		if aBand, bBand := relevanceBand(a.RelevanceScore), relevanceBand(b.RelevanceScore); aBand != bBand {
+			return aBand > bBand
+		}
+		if aFresh != bFresh {
+			return aFresh < bFresh
+		}
+		return newerSource(a, b)
+	})
+}
+
+func newerSource(a, b models.ResponseSource) bool {
+	if a.PublishedAt == nil || b.PublishedAt == nil {
+		return a.PublishedAt != nil && b.PublishedAt == nil
+	}
+	return a.PublishedAt.After(*b.PublishedAt)
+}
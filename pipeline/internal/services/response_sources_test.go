@@ -0,0 +1,61 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func freshnessSources(now time.Time) ([]models.NewsArticle, []models.YouTubeVideo) {
+	articles := []models.NewsArticle{
+		{Title: "old but relevant", PublishedAt: now.Add(-10 * 24 * time.Hour), RelevanceScore: 0.9},
+		{Title: "undated", RelevanceScore: 0.95},
+		{Title: "breaking but weak", PublishedAt: now.Add(-10 * time.Minute), RelevanceScore: 0.3},
+		{Title: "today and relevant", PublishedAt: now.Add(-5 * time.Hour), RelevanceScore: 0.8},
+	}
+	videos := []models.YouTubeVideo{
+		{Title: "video this week", PublishedAt: now.Add(-3 * 24 * time.Hour), RelevancyScore: 0.5},
+	}
+	return articles, videos
+}
+
+func sourceTitles(sources []models.ResponseSource) []string {
+	titles := make([]string, len(sources))
+	for i, source := range sources {
+		titles[i] = source.Title
+	}
+	return titles
+}
+
+func TestResponseSourcesSortRelevanceFirst(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	articles, videos := freshnessSources(now)
+
+	sources := buildResponseSources(articles, videos, now, SourceSortRelevance)
+
+	want := []string{"today and relevant", "old but relevant", "undated", "video this week", "breaking but weak"}
+	if got := sourceTitles(sources); !slices.Equal(got, want) {
+		t.Errorf("source order = %v, want %v", got, want)
+	}
+	for _, source := range sources {
+		if source.Title == "undated" && (source.Freshness != models.FreshnessUndated || source.PublishedAt != nil) {
+			t.Errorf("undated source = %+v, want the undated tier and no publish time", source)
+		}
+		if source.Title == "video this week" && (source.Type != "video" || source.Freshness != models.FreshnessThisWeek) {
+			t.Errorf("video source = %+v, want a this_week video", source)
+		}
+	}
+}
+
+func TestResponseSourcesSortFreshnessFirst(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	articles, videos := freshnessSources(now)
+
+	sources := buildResponseSources(articles, videos, now, SourceSortFreshness)
+
+	want := []string{"breaking but weak", "today and relevant", "video this week", "old but relevant", "undated"}
+	if got := sourceTitles(sources); !slices.Equal(got, want) {
+		t.Errorf("source order = %v, want %v", got, want)
+	}
+}
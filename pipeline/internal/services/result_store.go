@@ -0,0 +1,194 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	ResultStoreNone  = "none"
+	ResultStoreRedis = "redis"
+	ResultStoreFile  = "file"
+)
+
+// how often a put sweeps the file store for expired results, results nobody reads again still leave on time
+const resultPruneInterval = time.Minute
+
+// workflow ids become file names, anything outside this set is refused rather than escaped
+var resultIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+
+// ResultStore keeps completed workflow results past the active state TTL. GetResult returns nil without an
+// error when nothing is stored or the result has passed its retention.
+type ResultStore interface {
+	PutResult(ctx context.Context, result *models.WorkflowResult) error
+	GetResult(ctx context.Context, workflowID string) (*models.WorkflowResult, error)
+}
+
+type redisResultStore struct {
+	redis     *RedisService
+	retention time.Duration
+}
+
+// NewRedisResultStore keeps results in redis under their own key with the retention as TTL
+func NewRedisResultStore(redis *RedisService, retention time.Duration) ResultStore {
+	return &redisResultStore{redis: redis, retention: retention}
+}
+
+func (store *redisResultStore) PutResult(ctx context.Context, result *models.WorkflowResult) error {
+	return store.redis.StoreWorkflowResult(ctx, result, store.retention)
+}
+
+func (store *redisResultStore) GetResult(ctx context.Context, workflowID string) (*models.WorkflowResult, error) {
+	return store.redis.GetWorkflowResult(ctx, workflowID)
+}
+
+type fileResultStore struct {
+	dir       string
+	retention time.Duration
+
+	pruneMutex sync.Mutex
+	lastPrune  time.Time
+}
+
+// NewFileResultStore keeps one JSON file per result in dir, expired files are removed on startup, when read and
+// by a sweep at most once a minute as results are stored
+func NewFileResultStore(dir string, retention time.Duration) (ResultStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create result store directory: %w", err)
+	}
+
+	store := &fileResultStore{dir: dir, retention: retention, lastPrune: time.Now()}
+	store.prune()
+	return store, nil
+}
+
+func (store *fileResultStore) path(workflowID string) (string, error) {
+	if !resultIDPattern.MatchString(workflowID) {
+		return "", fmt.Errorf("invalid workflow id for result store: %q", workflowID)
+	}
+	return filepath.Join(store.dir, workflowID+".json"), nil
+}
+
+func (store *fileResultStore) PutResult(ctx context.Context, result *models.WorkflowResult) error {
+	path, err := store.path(result.WorkflowID)
+	if err != nil {
+		return err
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to serialize workflow result: %w", err)
+	}
+
+	// write then rename so a reader never sees a half written result
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, resultJSON, 0o644); err != nil {
+		return fmt.Errorf("failed to write workflow result: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write workflow result: %w", err)
+	}
+
+	store.pruneIfDue()
+	return nil
+}
+
+func (store *fileResultStore) GetResult(ctx context.Context, workflowID string) (*models.WorkflowResult, error) {
+	path, err := store.path(workflowID)
+	if err != nil {
+		return nil, nil
+	}
+
+	resultJSON, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow result: %w", err)
+	}
+
+	var result models.WorkflowResult
+	if err := json.Unmarshal(resultJSON, &result); err != nil {
+		return nil, fmt.Errorf("failed to deserialize workflow result: %w", err)
+	}
+
+	if store.expired(result.StoredAt) {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &result, nil
+}
+
+func (store *fileResultStore) expired(storedAt time.Time) bool {
+	return store.retention > 0 && time.Since(storedAt) > store.retention
+}
+
+// pruneIfDue prunes when the last sweep is older than resultPruneInterval, concurrent puts never sweep twice
+func (store *fileResultStore) pruneIfDue() {
+	store.pruneMutex.Lock()
+	if time.Since(store.lastPrune) < resultPruneInterval {
+		store.pruneMutex.Unlock()
+		return
+	}
+	store.lastPrune = time.Now()
+	store.pruneMutex.Unlock()
+
+	store.prune()
+}
+
+// prune drops result files older than the retention, judged by modification time so nothing has to be parsed
+func (store *fileResultStore) prune() {
+	if store.retention <= 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err == nil && store.expired(info.ModTime()) {
+			os.Remove(filepath.Join(store.dir, entry.Name()))
+		}
+	}
+}
+
+// newWorkflowResult snapshots what a client needs to render a finished workflow
+func newWorkflowResult(workflowCtx *models.WorkflowContext, response *models.WorkflowResponse) *models.WorkflowResult {
+	result := &models.WorkflowResult{
+		WorkflowID:   workflowCtx.ID,
+		RequestID:    workflowCtx.RequestID,
+		UserID:       workflowCtx.UserID,
+		TenantID:     workflowCtx.TenantID,
+		Query:        workflowCtx.OriginalQuery,
+		Status:       response.Status,
+		Intent:       workflowCtx.Intent,
+		ResponseType: response.ResponseType,
+		Response:     response.Message,
+		Summary:      workflowCtx.Summary,
+		Sources:      response.Sources,
+		QualityScore: workflowCtx.ProcessingStats.QualityScore,
+		CompletedAt:  time.Now(),
+		StoredAt:     time.Now(),
+	}
+	if workflowCtx.EndTime != nil {
+		result.CompletedAt = *workflowCtx.EndTime
+	}
+	if response.TotalTime != nil {
+		result.TotalTime = *response.TotalTime
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompletedWorkflowResultOutlivesTheWorkflowState(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	redis, redisService := newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second, MaxContextBytes: 1 << 20})
+	workflow.orchestrator.redisService = redisService
+	workflow.orchestrator.SetResultStore(NewRedisResultStore(redisService, 30*24*time.Hour))
+
+	response, err := workflow.run("workflow-durable")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if response.Stateless {
+		t.Fatal("workflow ran statelessly, want its state kept in redis")
+	}
+
+	// the next morning the workflow state is gone and only the persisted result is left
+	redis.Advance(7 * time.Hour)
+	ctx := context.Background()
+	if state, err := workflow.orchestrator.GetWorkflowStatus("workflow-durable"); err == nil && state != nil {
+		t.Fatal("workflow state is still stored past its TTL")
+	}
+
+	result, err := workflow.orchestrator.GetWorkflowResult(ctx, "workflow-durable")
+	if err != nil || result == nil {
+		t.Fatalf("GetWorkflowResult() = %v, %v, want the persisted result", result, err)
+	}
+	if result.Status != response.Status || result.Response != response.Message || result.UserID != "user-1" {
+		t.Errorf("result = %s %q for %s, want the completed response for user-1", result.Status, result.Response, result.UserID)
+	}
+	if len(result.Sources) == 0 || len(result.Sources) != len(response.Sources) {
+		t.Errorf("result has %d sources, want the response's %d", len(result.Sources), len(response.Sources))
+	}
+
+	redis.Advance(30 * 24 * time.Hour)
+	if result, err := workflow.orchestrator.GetWorkflowResult(ctx, "workflow-durable"); err != nil || result != nil {
+		t.Errorf("GetWorkflowResult() past the retention = %v, %v, want nothing", result, err)
+	}
+}
+
+func TestFileResultStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileResultStore(dir, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileResultStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	stored := &models.WorkflowResult{WorkflowID: "workflow-1", Status: "completed", Response: "The budget passed.", StoredAt: time.Now()}
+	if err := store.PutResult(ctx, stored); err != nil {
+		t.Fatalf("PutResult() error = %v", err)
+	}
+	result, err := store.GetResult(ctx, "workflow-1")
+	if err != nil || result == nil || result.Response != stored.Response {
+		t.Fatalf("GetResult() = %+v, %v, want the stored result", result, err)
+	}
+
+	if result, err := store.GetResult(ctx, "workflow-missing"); err != nil || result != nil {
+		t.Errorf("GetResult() of an unknown workflow = %+v, %v, want nothing", result, err)
+	}
+	// workflow ids become file names, nothing outside the store directory is ever read or written
+	if err := store.PutResult(ctx, &models.WorkflowResult{WorkflowID: "../escape"}); err == nil {
+		t.Error("PutResult() accepted a workflow id that leaves the store directory")
+	}
+	if result, err := store.GetResult(ctx, "../workflow-1"); err != nil || result != nil {
+		t.Errorf("GetResult() with a path id = %+v, %v, want nothing", result, err)
+	}
+
+	expired := &models.WorkflowResult{WorkflowID: "workflow-old", Status: "completed", StoredAt: time.Now().Add(-48 * time.Hour)}
+	if err := store.PutResult(ctx, expired); err != nil {
+		t.Fatalf("PutResult() error = %v", err)
+	}
+	if result, err := store.GetResult(ctx, "workflow-old"); err != nil || result != nil {
+		t.Errorf("GetResult() past the retention = %+v, %v, want nothing", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "workflow-old.json")); !os.IsNotExist(err) {
+		t.Errorf("expired result file is still on disk: %v", err)
+	}
+}
+
+func TestFileResultStorePrunesExpiredFilesOnStartup(t *testing.T) {
+	dir := t.TempDir()
+	stale, fresh := filepath.Join(dir, "workflow-stale.json"), filepath.Join(dir, "workflow-fresh.json")
+	for _, path := range []string{stale, fresh} {
+		if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	if _, err := NewFileResultStore(dir, 24*time.Hour); err != nil {
+		t.Fatalf("NewFileResultStore() error = %v", err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("the stale result survived startup")
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("the fresh result was pruned: %v", err)
+	}
+}
+
+func TestFileResultStorePrunesExpiredFilesWhileRunning(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileResultStore(dir, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileResultStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// stored yesterday before last and never read again
+	stale := filepath.Join(dir, "workflow-stale.json")
+	if err := os.WriteFile(stale, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	if err := store.PutResult(ctx, &models.WorkflowResult{WorkflowID: "workflow-1", StoredAt: time.Now()}); err != nil {
+		t.Fatalf("PutResult() error = %v", err)
+	}
+	if _, err := os.Stat(stale); err != nil {
+		t.Fatalf("the stale result was pruned before the sweep was due: %v", err)
+	}
+
+	store.(*fileResultStore).lastPrune = time.Now().Add(-resultPruneInterval)
+	if err := store.PutResult(ctx, &models.WorkflowResult{WorkflowID: "workflow-2", StoredAt: time.Now()}); err != nil {
+		t.Fatalf("PutResult() error = %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("the stale result survived the sweep")
+	}
+	for _, id := range []string{"workflow-1", "workflow-2"} {
+		if result, err := store.GetResult(ctx, id); err != nil || result == nil {
+			t.Errorf("GetResult(%s) = %v, %v, want the fresh result", id, result, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// ScrapeCacheEntry is the last successful scrape of a url along with the validators needed to revalidate it
+type ScrapeCacheEntry struct {
+	Content      ScrapedContent `json:"content"`
+	ContentHash  string         `json:"content_hash"`
+	ETag         string         `json:"etag,omitempty"`
+	LastModified string         `json:"last_modified,omitempty"`
+	CachedAt     time.Time      `json:"cached_at"`
+}
+
+func hashContent(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadCachedScrape returns the cached entry for a url, or nil when caching is disabled, missing or too old
+func (service *ScraperService) loadCachedScrape(ctx context.Context, targetURL string) *ScrapeCacheEntry {
+	if service.cache == nil || service.config.CacheMaxAge <= 0 {
+		return nil
+	}
+
+	entry, err := service.cache.GetScrapeCache(ctx, targetURL)
+	if err != nil {
+		service.logger.WithError(err).Warn("Failed to read scrape cache", "url", targetURL)
+		return nil
+	}
+	if entry == nil || time.Since(entry.CachedAt) > service.config.CacheMaxAge {
+		return nil
+	}
+
+	return entry
+}
+
+func (service *ScraperService) storeCachedScrape(ctx context.Context, entry *ScrapeCacheEntry) {
+	if service.cache == nil || service.config.CacheMaxAge <= 0 {
+		return
+	}
+
+	entry.CachedAt = time.Now()
+	if err := service.cache.StoreScrapeCache(ctx, entry, service.config.CacheMaxAge); err != nil {
+		service.logger.WithError(err).Warn("Failed to store scrape cache", "url", entry.Content.URL)
+	}
+}
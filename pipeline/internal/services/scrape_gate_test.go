@@ -0,0 +1,131 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestScrapeGate(t *testing.T) {
+	t.Parallel()
+	articles := []models.NewsArticle{{RelevanceScore: 0.9}, {RelevanceScore: 0.3}, {RelevanceScore: 0.6}, {RelevanceScore: 0.8}}
+	tests := []struct {
+		name         string
+		minRelevance float64
+		maxArticles  int
+		want         []int
+	}{
+		{name: "no gate", want: []int{0, 1, 2, 3}},
+		{name: "min relevance", minRelevance: 0.5, want: []int{0, 2, 3}},
+		{name: "max articles keeps the most relevant in article order", maxArticles: 2, want: []int{0, 3}},
+		{name: "both", minRelevance: 0.7, maxArticles: 5, want: []int{0, 3}},
+		{name: "nothing passes", minRelevance: 0.95},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{Workflow: config.WorkflowConfig{ScrapeMinRelevance: tt.minRelevance, ScrapeMaxArticles: tt.maxArticles}}
+			executor := &WorkflowExecutor{orchestrator: &Orchestrator{config: cfg}}
+			if got := executor.scrapeGate(articles); !slices.Equal(got, tt.want) {
+				t.Errorf("scrapeGate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLowRelevanceArticlesAreNotScraped(t *testing.T) {
+	var mu sync.Mutex
+	var scraped []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		scraped = append(scraped, r.URL.Path)
+		mu.Unlock()
+		w.Write([]byte("<html><body><article><p>" + strings.Repeat("The full story behind the budget vote. ", 40) + "</p></article></body></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := loadTestConfig(t, map[string]string{"SCRAPE_MIN_RELEVANCE": "0.5", "SCRAPER_RETRY_ATTEMPTS": "1"})
+	orchestrator := newTestOrchestrator(t, cfg)
+	scraper, err := NewScraperService(cfg.Scraper, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	orchestrator.scraperService = scraper
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "budget vote"})
+
+	articles := []models.NewsArticle{
+		{ID: "strong", URL: server.URL + "/strong", Description: "Budget passes", RelevanceScore: 0.9},
+		{ID: "weak", URL: server.URL + "/weak", Description: "Weather this weekend", Content: "Weather this weekend", RelevanceScore: 0.2},
+	}
+	got, outcome := executor.scrapeArticles(context.Background(), articles)
+
+	if !slices.Equal(scraped, []string{"/strong"}) {
+		t.Errorf("scraped %v, want only the article above the gate", scraped)
+	}
+	if outcome.attempted != 1 || outcome.skipped != 1 {
+		t.Errorf("attempted %d and skipped %d, want 1 each", outcome.attempted, outcome.skipped)
+	}
+	if len(got) != 2 || got[0].ID != "strong" || got[1].ID != "weak" {
+		t.Fatalf("articles = %+v, want both back in their order", got)
+	}
+	if !strings.Contains(got[0].Content, "full story behind the budget vote") {
+		t.Errorf("strong article content = %q, want the scraped text", got[0].Content)
+	}
+	if got[1].Content != "Weather this weekend" {
+		t.Errorf("weak article content = %q, want its description kept", got[1].Content)
+	}
+}
+
+func TestContentEmbeddingScrapesAreNotRepeated(t *testing.T) {
+	var mu sync.Mutex
+	scraped := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		scraped[r.URL.Path]++
+		mu.Unlock()
+		w.Write([]byte("<html><body><article><p>" + strings.Repeat("The full story behind the budget vote. ", 40) + "</p></article></body></html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := loadTestConfig(t, map[string]string{"EMBEDDING_INPUT": EmbeddingInputContent, "SCRAPER_RETRY_ATTEMPTS": "1"})
+	orchestrator := newTestOrchestrator(t, cfg)
+	scraper, err := NewScraperService(cfg.Scraper, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	orchestrator.scraperService = scraper
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "budget vote"})
+
+	fresh := []models.NewsArticle{
+		{ID: "budget", URL: server.URL + "/budget", Title: "Budget passes", RelevanceScore: 0.9},
+		{ID: "vote", URL: server.URL + "/vote", Title: "How the vote went", RelevanceScore: 0.8},
+	}
+	executor.workflowCtx.Metadata["fresh_articles"] = fresh
+	if err := executor.generateNewsAndVideoEmbeddings(context.Background()); err != nil {
+		t.Fatalf("generateNewsAndVideoEmbeddings() error = %v", err)
+	}
+
+	// one article was fetched before relevancy, the other only turns up in the scraper step
+	relevant, _ := executor.workflowCtx.Metadata["fresh_articles"].([]models.NewsArticle)
+	relevant = append(relevant[:1], models.NewsArticle{ID: "stored", URL: server.URL + "/stored", Title: "Earlier budget story", RelevanceScore: 0.7})
+	got, outcome := executor.scrapeArticles(context.Background(), relevant)
+
+	if want := map[string]int{"/budget": 1, "/vote": 1, "/stored": 1}; !maps.Equal(scraped, want) {
+		t.Errorf("scraped %v, want every article fetched once", scraped)
+	}
+	if outcome.attempted != 1 || outcome.skipped != 0 {
+		t.Errorf("attempted %d and skipped %d, want only the stored article attempted", outcome.attempted, outcome.skipped)
+	}
+	for _, article := range got {
+		if !strings.Contains(article.Content, "full story behind the budget vote") {
+			t.Errorf("article %s content = %q, want the scraped text", article.ID, article.Content)
+		}
+	}
+}
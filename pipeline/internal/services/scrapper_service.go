@@ -4,8 +4,10 @@ import (
 	"Infiya-ai-pipeline/internal/config"
 	"Infiya-ai-pipeline/internal/models"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/debug"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type ScraperService struct {
@@ -24,6 +27,12 @@ type ScraperService struct {
 	mu          sync.RWMutex
 	userAgents  []string
 	uaIndex     int
+	cache       *RedisService
+	// nil unless feed-first fetching is enabled with at least one feed configured
+	feeds *FeedContentFetcher
+	// nil unless the headless fallback is enabled, renderSlots bounds how many pages render at once
+	renderer    PageRenderer
+	renderSlots chan struct{}
 }
 
 type ScrapedContent struct {
@@ -39,8 +48,18 @@ type ScrapedContent struct {
 	ScrapedAt   time.Time         `json:"scraped_at"`
 	Success     bool              `json:"success"`
 	Error       string            `json:"error"`
+	// ContentLevel tells a full article apart from a page that only yielded metadata
+	ContentLevel ScrapeContentLevel `json:"content_level"`
 }
 
+type ScrapeContentLevel string
+
+const (
+	ScrapeContentFull         ScrapeContentLevel = "full"
+	ScrapeContentMetadataOnly ScrapeContentLevel = "metadata_only"
+	ScrapeContentNone         ScrapeContentLevel = "none"
+)
+
 type ScrapingRequest struct {
 	URLs            []string          `json:"urls"`
 	MaxConcurrency  int               `json:"max_concurrency"`
@@ -59,7 +78,7 @@ type ScrapingResult struct {
 	Duration          time.Duration    `json:"duration"`
 }
 
-func NewScraperService(config config.ScraperConfig, logger *logger.Logger) (*ScraperService, error) {
+func NewScraperService(config config.ScraperConfig, cache *RedisService, logger *logger.Logger) (*ScraperService, error) {
 	collector := colly.NewCollector(
 		colly.Debugger(&debug.LogDebugger{}),
 		colly.UserAgent("Infiya-AI-News-Assistant/1.0 (+https://infiya-ai.com/bot)"),
@@ -73,6 +92,14 @@ func NewScraperService(config config.ScraperConfig, logger *logger.Logger) (*Scr
 	})
 
 	collector.SetRequestTimeout(60 * time.Second)
+	if cache != nil && config.CacheMaxAge > 0 {
+		// clones share the visited set, without revisits a url scraped by an earlier workflow is never revalidated
+		collector.AllowURLRevisit = true
+	}
+	if config.MaxBodyBytes > 0 {
+		// colly truncates the body past this, clones made per scrape inherit it
+		collector.MaxBodySize = config.MaxBodyBytes
+	}
 
 	userAgents := []string{
 		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
@@ -90,6 +117,16 @@ func NewScraperService(config config.ScraperConfig, logger *logger.Logger) (*Scr
 		rateLimiter: make(chan struct{}, 5),
 		userAgents:  userAgents,
 		uaIndex:     0,
+		cache:       cache,
+	}
+
+	if config.FeedFirst && len(config.FeedSources) > 0 {
+		service.feeds = NewFeedContentFetcher(config.FeedSources, config.Timeout, config.FeedCacheTTL, logger)
+	}
+
+	if config.HeadlessFallback {
+		service.renderer = NewHTTPPageRenderer(config.HeadlessRendererURL, config.HeadlessTimeout, config.MaxBodyBytes)
+		service.renderSlots = make(chan struct{}, config.HeadlessMaxConcurrency)
 	}
 
 	service.setupCallbacks()
@@ -97,12 +134,45 @@ func NewScraperService(config config.ScraperConfig, logger *logger.Logger) (*Scr
 		"rate_limit", "5 concurrent requests",
 		"delay", "3 seconds between requests",
 		"timeout", "60 seconds",
-		"content_extraction", "p-tag-focused")
+		"content_extraction", "p-tag-focused",
+		"cache_max_age", config.CacheMaxAge,
+		"feed_sources", len(config.FeedSources),
+		"feed_first", service.feeds != nil,
+		"header_profiles", len(config.DomainHeaders)+len(config.DomainCookies),
+		"headless_fallback", service.renderer != nil,
+		"headless_domains", len(config.HeadlessDomains))
 
 	return service, nil
 }
 
 func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string) (*ScrapedContent, error) {
+	return service.ScrapeURLWithHeaders(ctx, targetURL, nil)
+}
+
+// ScrapeURLWithHeaders scrapes with extra request headers, these win over the domain's header profile and the defaults
+func (service *ScraperService) ScrapeURLWithHeaders(ctx context.Context, targetURL string, headers map[string]string) (*ScrapedContent, error) {
+	// colly does not carry our context, so the scrape gets an explicit span instead of transport instrumentation
+	ctx, span := tracing.StartSpan(ctx, "scraper.scrape", attribute.String("url.full", targetURL))
+
+	if service.feeds != nil {
+		// a feed that only syndicates a teaser is not worth more than a scrape
+		if content, found := service.feeds.Lookup(ctx, targetURL); found && service.contentLevel(content) == ScrapeContentFull {
+			content.ContentLevel = ScrapeContentFull
+			span.SetAttributes(attribute.Bool("scrape.success", true), attribute.Bool("scrape.from_feed", true))
+			tracing.EndSpan(span, nil)
+			return content, nil
+		}
+	}
+
+	content, err := service.scrapeURL(ctx, targetURL, headers)
+	if err == nil && content != nil {
+		span.SetAttributes(attribute.Bool("scrape.success", content.Success))
+	}
+	tracing.EndSpan(span, err)
+	return content, err
+}
+
+func (service *ScraperService) scrapeURL(ctx context.Context, targetURL string, headers map[string]string) (*ScrapedContent, error) {
 	startTime := time.Now()
 
 	content := &ScrapedContent{
@@ -145,11 +215,15 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 		return content, models.NewTimeoutError("SCRAPER_TIMEOUT", "Rate limiter timeout").WithCause(ctx.Err())
 	}
 
+	cached := service.loadCachedScrape(ctx, targetURL)
+
 	c := service.collector.Clone()
 	var scrapingError error
 	var httpStatusCode int
 	var responseSize int
 	var contentProcessed bool
+	var notModified bool
+	var contentHash, etag, lastModified string
 
 	c.OnRequest(func(r *colly.Request) {
 		service.mu.Lock()
@@ -158,16 +232,16 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 		service.mu.Unlock()
 
 		r.Headers.Set("User-Agent", userAgent)
-		r.Headers.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
-		r.Headers.Set("Accept-Language", "en-US,en;q=0.5")
-		r.Headers.Set("Accept-Encoding", "gzip, deflate, br")
-		r.Headers.Set("DNT", "1")
-		r.Headers.Set("Connection", "keep-alive")
-		r.Headers.Set("Upgrade-Insecure-Requests", "1")
-		r.Headers.Set("Sec-Fetch-Dest", "document")
-		r.Headers.Set("Sec-Fetch-Mode", "navigate")
-		r.Headers.Set("Sec-Fetch-Site", "none")
-		r.Headers.Set("Cache-Control", "max-age=0")
+		service.applyScrapeHeaders(r.Headers, r.URL.Host, headers)
+
+		if cached != nil {
+			if cached.ETag != "" {
+				r.Headers.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				r.Headers.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
 
 		service.logger.Debug("P-tag scraper request sent",
 			"url", r.URL.String(),
@@ -187,6 +261,13 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 		content.Metadata["status_code"] = fmt.Sprintf("%d", r.StatusCode)
 		content.Metadata["content_type"] = r.Headers.Get("Content-Type")
 		content.Metadata["response_size"] = fmt.Sprintf("%d", responseSize)
+
+		contentHash = hashContent(r.Body)
+		etag = r.Headers.Get("ETag")
+		lastModified = r.Headers.Get("Last-Modified")
+		if cached != nil && cached.ContentHash == contentHash {
+			notModified = true
+		}
 	})
 
 	c.OnHTML("html", func(e *colly.HTMLElement) {
@@ -195,33 +276,23 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 			return
 		}
 
+		// body is identical to the cached scrape, skip parsing and reuse it
+		if notModified {
+			return
+		}
+
 		contentProcessed = true
 		service.logger.Debug("Processing HTML content with P-tag extraction",
 			"url", targetURL,
 			"html_length", len(e.Text))
 
-		// P-TAG FOCUSED EXTRACTION - This is the key change!
-		content.Content = service.extractArticleContentFromParagraphs(e)
-		content.Title = service.extractTitle(e)
-		content.Description = service.extractDescription(e)
-		content.Author = service.extractAuthor(e)
-		content.PublishedAt = service.extractPublishedDate(e)
-		content.ImageURL = service.extractMainImage(e)
-		content.Tags = service.extractTags(e)
-		content.Metadata["lang"] = e.Attr("lang")
-		content.Metadata["charset"] = service.extractCharset(e)
-
-		hasTitle := strings.TrimSpace(content.Title) != ""
-		hasContent := strings.TrimSpace(content.Content) != ""
-		hasDescription := strings.TrimSpace(content.Description) != ""
-
-		content.Success = hasTitle || hasContent || hasDescription
+		service.populateFromHTML(content, e)
 
 		service.logger.Info("P-tag extraction results",
 			"url", targetURL,
-			"has_title", hasTitle,
-			"has_content", hasContent,
-			"has_description", hasDescription,
+			"has_title", strings.TrimSpace(content.Title) != "",
+			"has_content", strings.TrimSpace(content.Content) != "",
+			"has_description", strings.TrimSpace(content.Description) != "",
 			"title", safeTruncate(content.Title, 50),
 			"content_length", len(content.Content),
 			"paragraph_count", strings.Count(content.Content, "\n\n")+1,
@@ -229,6 +300,12 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 	})
 
 	c.OnError(func(r *colly.Response, err error) {
+		if r != nil && r.StatusCode == http.StatusNotModified && cached != nil {
+			httpStatusCode = r.StatusCode
+			notModified = true
+			return
+		}
+
 		scrapingError = err
 		if r != nil {
 			httpStatusCode = r.StatusCode
@@ -259,7 +336,7 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 		}()
 
 		err := c.Visit(targetURL)
-		if err != nil {
+		if err != nil && !notModified {
 			scrapingError = err
 			content.Error = err.Error()
 			service.logger.Error("Visit failed", "url", targetURL, "error", err)
@@ -276,6 +353,28 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 		return content, models.NewTimeoutError("SCRAPER_TIMEOUT", "Scraping request timed out").WithCause(ctx.Err())
 	}
 
+	if notModified {
+		reused := cached.Content
+		reused.ScrapedAt = time.Now()
+		reused.Metadata = make(map[string]string, len(cached.Content.Metadata)+1)
+		for k, v := range cached.Content.Metadata {
+			reused.Metadata[k] = v
+		}
+		reused.Metadata["cache"] = "revalidated"
+		reused.Metadata["status_code"] = fmt.Sprintf("%d", httpStatusCode)
+
+		// keep the entry fresh so the next request keeps revalidating rather than refetching
+		service.storeCachedScrape(ctx, cached)
+
+		service.logger.LogService("scraper", "scraper_url_cached", time.Since(startTime), map[string]interface{}{
+			"url":            targetURL,
+			"status_code":    httpStatusCode,
+			"content_length": len(reused.Content),
+		}, nil)
+
+		return &reused, nil
+	}
+
 	if !contentProcessed && scrapingError == nil {
 		service.logger.Warn("No HTML content processed", "url", targetURL, "status", httpStatusCode)
 		content.Error = fmt.Sprintf("No HTML content found (HTTP %d)", httpStatusCode)
@@ -286,6 +385,29 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 	content.Description = service.cleanContent(content.Description)
 	content.Title = strings.TrimSpace(content.Title)
 
+	content.ContentLevel = service.contentLevel(content)
+	content.Metadata["scrape_method"] = ScrapeMethodStatic
+	if service.needsHeadless(content, parsedURL.Host) {
+		// single page apps render their article text with javascript, which colly never runs
+		if rendered := service.renderFallback(ctx, content, parsedURL); rendered != content {
+			content = rendered
+			scrapingError = nil
+		}
+	}
+	if content.Success && content.ContentLevel != ScrapeContentFull {
+		content.Success = false
+		content.Error = fmt.Sprintf("Article content below minimum length of %d characters", service.config.MinContentLength)
+	}
+
+	if content.Success && scrapingError == nil {
+		service.storeCachedScrape(ctx, &ScrapeCacheEntry{
+			Content:      *content,
+			ContentHash:  contentHash,
+			ETag:         etag,
+			LastModified: lastModified,
+		})
+	}
+
 	duration := time.Since(startTime)
 	service.logger.LogService("scraper", "scraper_url_ptag", duration, map[string]interface{}{
 		"url":            targetURL,
@@ -303,6 +425,26 @@ func (service *ScraperService) ScrapeURL(ctx context.Context, targetURL string)
 
 // ================ P-TAG FOCUSED CONTENT EXTRACTION ================
 
+// populateFromHTML fills the content from the page's html element, shared by static scrapes and rendered pages
+func (service *ScraperService) populateFromHTML(content *ScrapedContent, e *colly.HTMLElement) {
+	// P-TAG FOCUSED EXTRACTION - This is the key change!
+	content.Content = service.extractArticleContentFromParagraphs(e)
+	content.Title = service.extractTitle(e)
+	content.Description = service.extractDescription(e)
+	content.Author = service.extractAuthor(e)
+	content.PublishedAt = service.extractPublishedDate(e)
+	content.ImageURL = service.extractMainImage(e)
+	content.Tags = service.extractTags(e)
+	content.Metadata["lang"] = e.Attr("lang")
+	content.Metadata["charset"] = service.extractCharset(e)
+
+	hasTitle := strings.TrimSpace(content.Title) != ""
+	hasContent := strings.TrimSpace(content.Content) != ""
+	hasDescription := strings.TrimSpace(content.Description) != ""
+
+	content.Success = hasTitle || hasContent || hasDescription
+}
+
 // extractArticleContentFromParagraphs - NEW METHOD focuses specifically on P tags
 func (service *ScraperService) extractArticleContentFromParagraphs(e *colly.HTMLElement) string {
 	var validParagraphs []string
@@ -340,6 +482,14 @@ func (service *ScraperService) extractArticleContentFromParagraphs(e *colly.HTML
 		}
 	}
 
+	if service.config.TrimBoilerplate {
+		before := len(validParagraphs)
+		validParagraphs = trimBoilerplateParagraphs(validParagraphs, service.config.BoilerplateMaxParagraphs)
+		if trimmed := before - len(validParagraphs); trimmed > 0 {
+			service.logger.Debug("Trimmed boilerplate paragraphs", "trimmed", trimmed)
+		}
+	}
+
 	content := strings.Join(validParagraphs, "\n\n")
 	content = service.cleanContent(content)
 
@@ -408,12 +558,18 @@ func (service *ScraperService) isNoiseText(text string) bool {
 		"click here", "sign up", "log in", "contact us",
 		"trending now", "most popular", "you might also like",
 	}
+	noisePatterns = append(noisePatterns, extendedNoisePhrases...)
 
 	for _, pattern := range noisePatterns {
 		if strings.Contains(lowerText, pattern) {
 			return true
 		}
 	}
+	for _, phrase := range service.config.NoisePhrases {
+		if phrase = strings.ToLower(strings.TrimSpace(phrase)); phrase != "" && strings.Contains(lowerText, phrase) {
+			return true
+		}
+	}
 
 	return false
 }
@@ -471,6 +627,11 @@ func (service *ScraperService) ScrapeMultipleURLs(ctx context.Context, req *Scra
 	if req == nil {
 		return nil, fmt.Errorf("Scraping request cannot be nil")
 	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "scrape_urls", hashTexts(req.URLs), func(ctx context.Context) (*ScrapingResult, error) {
+			return service.ScrapeMultipleURLs(ctx, req)
+		})
+	}
 	if len(req.URLs) == 0 {
 		return nil, fmt.Errorf("Scraping Request URLs cannot be empty")
 	}
@@ -533,7 +694,7 @@ func (service *ScraperService) ScrapeMultipleURLs(ctx context.Context, req *Scra
 
 			service.logger.Debug("Scraping URL with P-tag extraction", "url", url, "index", index)
 
-			content, err := service.ScrapeURL(requestCtx, url)
+			content, err := service.ScrapeURLWithHeaders(requestCtx, url, req.Headers)
 			mu.Lock()
 			defer mu.Unlock()
 			if content == nil {
@@ -579,6 +740,11 @@ func (service *ScraperService) ScrapeNewsArticle(ctx context.Context, article *m
 	if article == nil {
 		return nil, fmt.Errorf("Article cannot be nil")
 	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "scrape_article", article.URL, func(ctx context.Context) (*models.NewsArticle, error) {
+			return service.ScrapeNewsArticle(ctx, article)
+		})
+	}
 
 	if article.URL == "" {
 		return article, fmt.Errorf("Article URL cannot be empty")
@@ -809,19 +975,23 @@ func (service *ScraperService) setupCallbacks() {
 		service.mu.Unlock()
 
 		r.Headers.Set("User-Agent", userAgent)
-		r.Headers.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8")
-		r.Headers.Set("Accept-Language", "en-US,en;q=0.5")
-		r.Headers.Set("Accept-Encoding", "gzip, deflate, br")
-		r.Headers.Set("DNT", "1")
-		r.Headers.Set("Connection", "keep-alive")
-		r.Headers.Set("Upgrade-Insecure-Requests", "1")
-		r.Headers.Set("Sec-Fetch-Dest", "document")
-		r.Headers.Set("Sec-Fetch-Mode", "navigate")
-		r.Headers.Set("Sec-Fetch-Site", "none")
-		r.Headers.Set("Cache-Control", "max-age=0")
+		service.applyScrapeHeaders(r.Headers, r.URL.Host, nil)
 	})
 }
 
+// contentLevel grades a scrape by whether it produced enough article text to be worth summarizing
+func (service *ScraperService) contentLevel(content *ScrapedContent) ScrapeContentLevel {
+	text := strings.TrimSpace(content.Content)
+	if text != "" && len(text) >= service.config.MinContentLength {
+		return ScrapeContentFull
+	}
+
+	if text != "" || content.Title != "" || strings.TrimSpace(content.Description) != "" {
+		return ScrapeContentMetadataOnly
+	}
+	return ScrapeContentNone
+}
+
 func (service *ScraperService) HealthCheck(ctx context.Context) error {
 	testURL := "https://httpbin.org/html"
 
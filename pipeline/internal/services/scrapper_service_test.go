@@ -0,0 +1,237 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/pkg/redistest"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// publisherPage serves one article page, conditional requests that match its etag get a 304
+type publisherPage struct {
+	mu             sync.Mutex
+	body           string
+	honorsETag     bool
+	ifNoneMatch    []string
+	notModifiedHit int
+}
+
+func newPublisherPage(t *testing.T, story string, honorsETag bool) (*publisherPage, *httptest.Server) {
+	t.Helper()
+	page := &publisherPage{
+		body:       "<html><head><title>" + story + "</title></head><body><article><p>" + strings.Repeat("The full story of the "+story+". ", 40) + "</p></article></body></html>",
+		honorsETag: honorsETag,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page.mu.Lock()
+		defer page.mu.Unlock()
+		page.ifNoneMatch = append(page.ifNoneMatch, r.Header.Get("If-None-Match"))
+
+		w.Header().Set("ETag", `"v1"`)
+		if page.honorsETag && r.Header.Get("If-None-Match") == `"v1"` {
+			page.notModifiedHit++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(page.body))
+	}))
+	t.Cleanup(server.Close)
+	return page, server
+}
+
+func (page *publisherPage) conditionalHeaders() []string {
+	page.mu.Lock()
+	defer page.mu.Unlock()
+	return append([]string(nil), page.ifNoneMatch...)
+}
+
+func newCachingScraper(t *testing.T) (*redistest.Server, *ScraperService) {
+	t.Helper()
+	redis, redisService := newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second})
+	scraper, err := NewScraperService(config.ScraperConfig{Timeout: 10 * time.Second, RetryAttempts: 1, CacheMaxAge: time.Hour},
+		redisService, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	return redis, scraper
+}
+
+func TestScrapeReusesCachedContentOnNotModified(t *testing.T) {
+	t.Parallel()
+	page, server := newPublisherPage(t, "budget vote", true)
+	_, scraper := newCachingScraper(t)
+
+	first, err := scraper.ScrapeURL(context.Background(), server.URL+"/budget")
+	if err != nil || !first.Success {
+		t.Fatalf("first ScrapeURL() = %+v, %v, want a successful scrape", first, err)
+	}
+
+	second, err := scraper.ScrapeURL(context.Background(), server.URL+"/budget")
+	if err != nil {
+		t.Fatalf("second ScrapeURL() error = %v", err)
+	}
+
+	if headers := page.conditionalHeaders(); len(headers) != 2 || headers[0] != "" || headers[1] != `"v1"` {
+		t.Errorf("If-None-Match per request = %q, want none then the cached etag", headers)
+	}
+	if page.notModifiedHit != 1 {
+		t.Fatalf("publisher answered %d requests with 304, want 1", page.notModifiedHit)
+	}
+	if !second.Success || second.Content != first.Content || second.Title != first.Title {
+		t.Errorf("second scrape = %q %q, want the cached %q %q", second.Title, second.Content, first.Title, first.Content)
+	}
+	if second.Metadata["cache"] != "revalidated" || second.Metadata["status_code"] != "304" {
+		t.Errorf("second scrape metadata = %v, want a revalidated 304", second.Metadata)
+	}
+}
+
+func TestScrapeReusesCachedContentWhenTheBodyIsUnchanged(t *testing.T) {
+	t.Parallel()
+	// the publisher ignores conditional requests and sends the same page again
+	_, server := newPublisherPage(t, "flood warning", false)
+	_, scraper := newCachingScraper(t)
+
+	first, err := scraper.ScrapeURL(context.Background(), server.URL+"/flood")
+	if err != nil || !first.Success {
+		t.Fatalf("first ScrapeURL() = %+v, %v, want a successful scrape", first, err)
+	}
+	second, err := scraper.ScrapeURL(context.Background(), server.URL+"/flood")
+	if err != nil {
+		t.Fatalf("second ScrapeURL() error = %v", err)
+	}
+
+	if second.Metadata["cache"] != "revalidated" || second.Content != first.Content {
+		t.Errorf("second scrape = %v %q, want the cached content reused", second.Metadata, second.Content)
+	}
+}
+
+func TestScrapeCacheExpiresAfterTheMaxAge(t *testing.T) {
+	t.Parallel()
+	page, server := newPublisherPage(t, "rate decision", true)
+	redis, scraper := newCachingScraper(t)
+
+	if _, err := scraper.ScrapeURL(context.Background(), server.URL+"/rates"); err != nil {
+		t.Fatalf("first ScrapeURL() error = %v", err)
+	}
+	keys := redis.Keys("scrape:")
+	if len(keys) != 1 {
+		t.Fatalf("cached keys = %v, want the scraped page", keys)
+	}
+	if ttl := redis.TTL(keys[0]); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("cache ttl = %s, want the one hour max age", ttl)
+	}
+
+	redis.Advance(time.Hour + time.Minute)
+	second, err := scraper.ScrapeURL(context.Background(), server.URL+"/rates")
+	if err != nil {
+		t.Fatalf("second ScrapeURL() error = %v", err)
+	}
+
+	if headers := page.conditionalHeaders(); len(headers) != 2 || headers[1] != "" {
+		t.Errorf("If-None-Match per request = %q, want no revalidation of the expired entry", headers)
+	}
+	if _, cached := second.Metadata["cache"]; cached || !second.Success {
+		t.Errorf("second scrape = %v, want a fresh scrape", second.Metadata)
+	}
+}
+
+func newArticlePage(t *testing.T, html string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(html))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTitleOnlyPageIsNotASuccessfulScrape(t *testing.T) {
+	cfg := loadTestConfig(t, nil)
+	scraper, err := NewScraperService(cfg.Scraper, nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	server := newArticlePage(t, "<html><head><title>Budget vote delayed</title></head><body><p>Subscribe to read.</p></body></html>")
+
+	content, err := scraper.ScrapeURL(context.Background(), server.URL+"/budget")
+	if err != nil {
+		t.Fatalf("ScrapeURL() error = %v", err)
+	}
+
+	if content.Success {
+		t.Errorf("title only page scraped successfully with content %q", content.Content)
+	}
+	if content.ContentLevel != ScrapeContentMetadataOnly {
+		t.Errorf("ContentLevel = %s, want %s", content.ContentLevel, ScrapeContentMetadataOnly)
+	}
+	if content.Title != "Budget vote delayed" || !strings.Contains(content.Error, "minimum length of 200") {
+		t.Errorf("content = %q %q, want the title kept and the default minimum named", content.Title, content.Error)
+	}
+}
+
+func TestMinContentLengthIsConfigurable(t *testing.T) {
+	t.Parallel()
+	scraper, err := NewScraperService(config.ScraperConfig{Timeout: 10 * time.Second, RetryAttempts: 1, MinContentLength: 60},
+		nil, newTestLogger(t))
+	if err != nil {
+		t.Fatalf("NewScraperService() error = %v", err)
+	}
+	server := newArticlePage(t, "<html><head><title>Rates held</title></head><body><article><p>The central bank held rates at five percent and signalled one cut before the end of the year.</p></article></body></html>")
+
+	content, err := scraper.ScrapeURL(context.Background(), server.URL+"/rates")
+	if err != nil {
+		t.Fatalf("ScrapeURL() error = %v", err)
+	}
+
+	if !content.Success || content.ContentLevel != ScrapeContentFull {
+		t.Errorf("scrape = %t %s %q, want a full article above the lowered minimum", content.Success, content.ContentLevel, content.Error)
+	}
+}
+
+func TestScrapeTruncatesPagesOverTheBodyLimit(t *testing.T) {
+	var html strings.Builder
+	html.WriteString("<html><head><title>Rates held</title></head><body><article>")
+	html.WriteString("<p>The central bank kept its benchmark rate unchanged on Thursday, citing easing inflation and steady hiring across most sectors of the economy.</p>")
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&html, "<p>Analysts expect the committee to revisit the decision at meeting number %d later in the year.</p>", i)
+	}
+	html.WriteString("<p>The final paragraph of a very long page sits here.</p></article></body></html>")
+	server := newArticlePage(t, html.String())
+
+	tests := []struct {
+		name     string
+		maxBytes string
+		wantTail bool
+	}{
+		{name: "capped", maxBytes: "4096"},
+		{name: "uncapped", maxBytes: "0", wantTail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{"SCRAPER_MAX_BODY_BYTES": tt.maxBytes, "SCRAPER_RETRY_ATTEMPTS": "1"})
+			scraper, err := NewScraperService(cfg.Scraper, nil, newTestLogger(t))
+			if err != nil {
+				t.Fatalf("NewScraperService() error = %v", err)
+			}
+
+			content, err := scraper.ScrapeURL(context.Background(), server.URL+"/rates")
+			if err != nil || !content.Success {
+				t.Fatalf("ScrapeURL() = %+v, %v, want a successful scrape of the start of the page", content, err)
+			}
+			if !strings.Contains(content.Content, "benchmark rate unchanged") {
+				t.Errorf("content lost the opening paragraph")
+			}
+			if got := strings.Contains(content.Content, "final paragraph of a very long page"); got != tt.wantTail {
+				t.Errorf("content has the end of the page %t, want %t", got, tt.wantTail)
+			}
+			if !tt.wantTail && len(content.Content) > 4096 {
+				t.Errorf("content is %d bytes, want it bounded by the 4096 byte body limit", len(content.Content))
+			}
+		})
+	}
+}
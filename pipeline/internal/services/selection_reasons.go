@@ -0,0 +1,76 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"strings"
+)
+
+// at most this many matched keywords are named in a reason
+const maxReasonKeywords = 3
+
+// selectionReason explains in one line why a source was picked, from the search keywords its text mentions,
+// whether it comes from a trusted outlet and, when no keyword matched, the relevance it was rated
+func selectionReason(text string, keywords []string, relevance float64, trusted bool) string {
+	haystack := strings.ToLower(text)
+
+	var matched []string
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || !strings.Contains(haystack, strings.ToLower(keyword)) {
+			continue
+		}
+		matched = append(matched, keyword)
+		if len(matched) == maxReasonKeywords {
+			break
+		}
+	}
+
+	var phrases []string
+	if len(matched) > 0 {
+		phrases = append(phrases, "mentions "+strings.Join(matched, ", "))
+	} else if relevance > 0 {
+		phrases = append(phrases, fmt.Sprintf("was rated %.0f%% relevant to the question", relevance*100))
+	}
+	if trusted {
+		phrases = append(phrases, "is from a trusted source")
+	}
+	if len(phrases) == 0 {
+		return ""
+	}
+	return "Selected because it " + strings.Join(phrases, " and ")
+}
+
+// selectionReasonsEnabled reports whether sources carry a selection reason, a request's "selection_reasons" turns it on
+func (orchestrator *Orchestrator) selectionReasonsEnabled(workflowCtx *models.WorkflowContext) bool {
+	return orchestrator.config.Workflow.SelectionReasons || workflowCtx.RequestBool("selection_reasons")
+}
+
+// attachSelectionReasons fills each source's reason from the article or video it was built from, matched by URL
+func (orchestrator *Orchestrator) attachSelectionReasons(sources []models.ResponseSource, workflowCtx *models.WorkflowContext) {
+	trustedSources := orchestrator.config.Quality.TrustedSources
+
+	articles := make(map[string]models.NewsArticle, len(workflowCtx.Articles))
+	for _, article := range workflowCtx.Articles {
+		articles[article.URL] = article
+	}
+	videos := make(map[string]models.YouTubeVideo, len(workflowCtx.Videos))
+	for _, video := range workflowCtx.Videos {
+		videos[video.URL] = video
+	}
+
+	for i := range sources {
+		switch sources[i].Type {
+		case "article":
+			if article, ok := articles[sources[i].URL]; ok {
+				text := article.Title + " " + article.Description + " " + article.Content
+				sources[i].Reason = selectionReason(text, workflowCtx.Keywords, article.RelevanceScore, isTrustedSource(article, trustedSources))
+			}
+		case "video":
+			if video, ok := videos[sources[i].URL]; ok {
+				text := video.Title + " " + video.Description + " " + strings.Join(video.Tags, " ")
+				sources[i].Reason = selectionReason(text, workflowCtx.Keywords, video.RelevancyScore, false)
+			}
+		}
+	}
+}
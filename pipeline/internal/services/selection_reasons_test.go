@@ -0,0 +1,79 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"testing"
+)
+
+func TestSelectionReason(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		text      string
+		keywords  []string
+		relevance float64
+		trusted   bool
+		want      string
+	}{
+		{name: "matched keywords", text: "The Federal Reserve held interest rates", keywords: []string{"federal reserve", "rates", "inflation"},
+			want: "Selected because it mentions federal reserve, rates"},
+		{name: "at most three keywords", text: "a b c d", keywords: []string{"a", "b", "c", "d"},
+			want: "Selected because it mentions a, b, c"},
+		{name: "trusted source", text: "Rates on hold", keywords: []string{"rates"}, trusted: true,
+			want: "Selected because it mentions rates and is from a trusted source"},
+		{name: "relevance when nothing matched", text: "Central bank holds steady", keywords: []string{"fed"}, relevance: 0.82,
+			want: "Selected because it was rated 82% relevant to the question"},
+		{name: "nothing to say", text: "Central bank holds steady", keywords: []string{"fed", " "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectionReason(tt.text, tt.keywords, tt.relevance, tt.trusted); got != tt.want {
+				t.Errorf("selectionReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectionReasonsPopulateWhenEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		metadata    map[string]any
+		wantReasons bool
+	}{
+		{name: "off by default"},
+		{name: "enabled", env: map[string]string{"SELECTION_REASONS": "true", "QUALITY_TRUSTED_SOURCES": "Example News"}, wantReasons: true},
+		{name: "requested", metadata: map[string]any{"selection_reasons": true}, wantReasons: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, tt.env), "elections", models.IntentNewNewsQuery)
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-reasons", Query: "what is the latest on elections", Metadata: tt.metadata,
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+			if len(response.Sources) == 0 {
+				t.Fatal("response has no sources")
+			}
+
+			for _, source := range response.Sources {
+				want := ""
+				if tt.wantReasons {
+					want = "Selected because it mentions elections"
+					if source.Type == "article" && tt.env["QUALITY_TRUSTED_SOURCES"] != "" {
+						want += " and is from a trusted source"
+					}
+				}
+				if source.Reason != want {
+					t.Errorf("%s %s reason = %q, want %q", source.Type, source.URL, source.Reason, want)
+				}
+			}
+		})
+	}
+}
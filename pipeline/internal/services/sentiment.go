@@ -0,0 +1,194 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// scores within this distance of zero are reported as neutral
+const neutralSentimentBand = 0.2
+
+// sentimentExcerptLength bounds how much article content goes into the sentiment prompt per article
+const sentimentExcerptLength = 600
+
+type sentimentRating struct {
+	Index     int     `json:"index"`
+	Label     string  `json:"label"`
+	Score     float64 `json:"score"`
+	Magnitude float64 `json:"magnitude"`
+}
+
+// AnalyzeSentiment rates every article in one call, the result is aligned with articles and holds nil
+// for the ones the model skipped
+func (service *GeminiService) AnalyzeSentiment(ctx context.Context, query string, articles []models.NewsArticle) ([]*models.ArticleSentiment, error) {
+	start := time.Now()
+
+	if len(articles) == 0 {
+		return nil, nil
+	}
+
+	prompt := service.buildSentimentPrompt(query, articles)
+
+	req := &GenerationRequest{
+		Prompt:          prompt,
+		Temperature:     &[]float32{0.1}[0],
+		SystemRole:      "You are an expert news sentiment analyst. Rate articles and return only the specified JSON format.",
+		MaxTokens:       2048,
+		DisableThinking: true,
+		ResponseFormat:  "application/json",
+	}
+	service.applyAgentSampling("sentiment", req)
+
+	resp, err := service.GenerateContent(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("Sentiment Analysis failed: %w", err)
+	}
+
+	sentiments, err := parseSentimentResponse(resp.Content, len(articles))
+	if err != nil {
+		return nil, fmt.Errorf("Sentiment Analysis response invalid: %w", err)
+	}
+
+	service.logger.LogAgent("", "sentiment", "analyze_sentiment", time.Since(start), map[string]interface{}{
+		"articles":    len(articles),
+		"tokens_used": resp.TokensUsed,
+	}, nil)
+
+	return sentiments, nil
+}
+
+func (service *GeminiService) buildSentimentPrompt(query string, articles []models.NewsArticle) string {
+	var articlesText strings.Builder
+	for i, article := range articles {
+		fmt.Fprintf(&articlesText, "Article %d:\nTitle: %s\nSource: %s\nDescription: %s\n", i+1, article.Title, article.Source, article.Description)
+		if article.Content != "" {
+			excerpt := []rune(article.Content)
+			if len(excerpt) > sentimentExcerptLength {
+				excerpt = excerpt[:sentimentExcerptLength]
+			}
+			fmt.Fprintf(&articlesText, "Excerpt: %s\n", string(excerpt))
+		}
+		articlesText.WriteString("\n")
+	}
+
+	return service.prompts.Render("sentiment_analysis", map[string]any{
+		"Query":    query,
+		"Articles": articlesText.String(),
+	})
+}
+
+// parseSentimentResponse maps the 1-based indexes of the model's ratings back onto the articles,
+// ignoring ratings for indexes that do not exist and normalising scores and labels
+func parseSentimentResponse(content string, articleCount int) ([]*models.ArticleSentiment, error) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+
+	var ratings []sentimentRating
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &ratings); err != nil {
+		return nil, err
+	}
+
+	sentiments := make([]*models.ArticleSentiment, articleCount)
+	for _, rating := range ratings {
+		if rating.Index < 1 || rating.Index > articleCount {
+			continue
+		}
+		sentiments[rating.Index-1] = normalizeSentiment(rating)
+	}
+
+	return sentiments, nil
+}
+
+// normalizeSentiment clamps the ranges and derives the label from the score when the model's label is missing or unknown
+func normalizeSentiment(rating sentimentRating) *models.ArticleSentiment {
+	score := math.Max(-1, math.Min(1, rating.Score))
+
+	label := strings.ToLower(strings.TrimSpace(rating.Label))
+	switch label {
+	case models.SentimentPositive, models.SentimentNeutral, models.SentimentNegative:
+	default:
+		label = sentimentLabel(score)
+	}
+
+	return &models.ArticleSentiment{
+		Label:     label,
+		Score:     score,
+		Magnitude: clampUnit(rating.Magnitude),
+	}
+}
+
+func sentimentLabel(score float64) string {
+	switch {
+	case score >= neutralSentimentBand:
+		return models.SentimentPositive
+	case score <= -neutralSentimentBand:
+		return models.SentimentNegative
+	default:
+		return models.SentimentNeutral
+	}
+}
+
+// AggregateSentiment averages the rated articles overall and per source, nil when none were rated
+func AggregateSentiment(articles []models.NewsArticle) *models.SentimentSummary {
+	summary := &models.SentimentSummary{Counts: make(map[string]int)}
+
+	type sourceTotals struct {
+		score float64
+		count int
+	}
+	sources := make(map[string]*sourceTotals)
+
+	totalScore, totalMagnitude := 0.0, 0.0
+	for _, article := range articles {
+		if article.Sentiment == nil {
+			continue
+		}
+
+		summary.ArticleCount++
+		summary.Counts[article.Sentiment.Label]++
+		totalScore += article.Sentiment.Score
+		totalMagnitude += article.Sentiment.Magnitude
+
+		totals, ok := sources[article.Source]
+		if !ok {
+			totals = &sourceTotals{}
+			sources[article.Source] = totals
+		}
+		totals.score += article.Sentiment.Score
+		totals.count++
+	}
+
+	if summary.ArticleCount == 0 {
+		return nil
+	}
+
+	summary.Score = totalScore / float64(summary.ArticleCount)
+	summary.Magnitude = totalMagnitude / float64(summary.ArticleCount)
+	summary.Label = sentimentLabel(summary.Score)
+
+	for source, totals := range sources {
+		score := totals.score / float64(totals.count)
+		summary.BySource = append(summary.BySource, models.SourceSentiment{
+			Source:       source,
+			Label:        sentimentLabel(score),
+			Score:        score,
+			ArticleCount: totals.count,
+		})
+	}
+	sort.Slice(summary.BySource, func(i, j int) bool {
+		if summary.BySource[i].ArticleCount != summary.BySource[j].ArticleCount {
+			return summary.BySource[i].ArticleCount > summary.BySource[j].ArticleCount
+		}
+		return summary.BySource[i].Source < summary.BySource[j].Source
+	})
+
+	return summary
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseSentimentResponseAlignsRatingsWithArticles(t *testing.T) {
+	content := "```json\n" + `[
+		{"index": 2, "label": "negative", "score": -0.6, "magnitude": 0.7},
+		{"index": 3, "label": "upbeat", "score": 1.8, "magnitude": 1.4},
+		{"index": 9, "label": "positive", "score": 0.5, "magnitude": 0.5}
+	]` + "\n```"
+
+	sentiments, err := parseSentimentResponse(content, 3)
+	if err != nil {
+		t.Fatalf("parseSentimentResponse() error = %v", err)
+	}
+
+	if len(sentiments) != 3 || sentiments[0] != nil {
+		t.Fatalf("sentiments = %v, want three with the unrated first article nil", sentiments)
+	}
+	if got := *sentiments[1]; got != (models.ArticleSentiment{Label: models.SentimentNegative, Score: -0.6, Magnitude: 0.7}) {
+		t.Errorf("article 2 = %+v, want the rating as given", got)
+	}
+	// out of range scores are clamped and an unknown label is derived from the score
+	if got := *sentiments[2]; got != (models.ArticleSentiment{Label: models.SentimentPositive, Score: 1, Magnitude: 1}) {
+		t.Errorf("article 3 = %+v, want a clamped positive rating", got)
+	}
+}
+
+func TestAggregateSentiment(t *testing.T) {
+	rated := func(source string, score, magnitude float64) models.NewsArticle {
+		return models.NewsArticle{Source: source, Sentiment: &models.ArticleSentiment{
+			Label: sentimentLabel(score), Score: score, Magnitude: magnitude}}
+	}
+	articles := []models.NewsArticle{
+		rated("Reuters", 0.8, 0.6),
+		rated("Reuters", 0.4, 0.2),
+		rated("BBC", -0.6, 0.8),
+		rated("AP", 0.0, 0.0),
+		{Source: "AP"},
+	}
+
+	summary := AggregateSentiment(articles)
+	if summary == nil {
+		t.Fatal("AggregateSentiment() = nil, want a summary")
+	}
+
+	if summary.ArticleCount != 4 {
+		t.Errorf("article count = %d, want the 4 rated articles", summary.ArticleCount)
+	}
+	if math.Abs(summary.Score-0.15) > 1e-9 || math.Abs(summary.Magnitude-0.4) > 1e-9 {
+		t.Errorf("score %.3f magnitude %.3f, want 0.15 and 0.4", summary.Score, summary.Magnitude)
+	}
+	if summary.Label != models.SentimentNeutral {
+		t.Errorf("label = %s, want neutral inside the neutral band", summary.Label)
+	}
+	if summary.Counts[models.SentimentPositive] != 2 || summary.Counts[models.SentimentNegative] != 1 || summary.Counts[models.SentimentNeutral] != 1 {
+		t.Errorf("counts = %v, want 2 positive, 1 negative and 1 neutral", summary.Counts)
+	}
+
+	want := []models.SourceSentiment{
+		{Source: "Reuters", Label: models.SentimentPositive, Score: 0.6, ArticleCount: 2},
+		{Source: "AP", Label: models.SentimentNeutral, Score: 0, ArticleCount: 1},
+		{Source: "BBC", Label: models.SentimentNegative, Score: -0.6, ArticleCount: 1},
+	}
+	if len(summary.BySource) != len(want) {
+		t.Fatalf("by source = %+v, want %+v", summary.BySource, want)
+	}
+	for i, source := range summary.BySource {
+		if source.Source != want[i].Source || source.Label != want[i].Label || source.ArticleCount != want[i].ArticleCount ||
+			math.Abs(source.Score-want[i].Score) > 1e-9 {
+			t.Errorf("by source[%d] = %+v, want %+v", i, source, want[i])
+		}
+	}
+
+	if summary := AggregateSentiment([]models.NewsArticle{{Source: "AP"}}); summary != nil {
+		t.Errorf("AggregateSentiment() without ratings = %+v, want nil", summary)
+	}
+}
+
+var sentimentPromptArticle = regexp.MustCompile(`(?m)^Article (\d+):`)
+
+func TestSentimentIsRatedOnlyWhenRequested(t *testing.T) {
+	for _, include := range []bool{true, false} {
+		t.Run(fmt.Sprintf("include %t", include), func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+			// odd articles read positive, even ones negative
+			workflow.answerAgent("sentiment analyst", func(call fakeGeminiCall) string {
+				var ratings []string
+				for _, match := range sentimentPromptArticle.FindAllStringSubmatch(call.Prompt, -1) {
+					score := 0.6
+					if len(ratings)%2 == 1 {
+						score = -0.4
+					}
+					ratings = append(ratings, fmt.Sprintf(`{"index": %s, "label": "%s", "score": %.1f, "magnitude": 0.5}`,
+						match[1], sentimentLabel(score), score))
+				}
+				return "[" + strings.Join(ratings, ", ") + "]"
+			})
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-sentiment", Query: "who is ahead in the elections",
+				UserPreferences: models.UserPreferences{IncludeSentiment: include},
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var sentimentCalls int
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "sentiment analyst") {
+					sentimentCalls++
+				}
+			}
+			if !include {
+				if sentimentCalls != 0 || response.Sentiment != nil {
+					t.Errorf("%d sentiment calls and summary %+v without opting in, want none", sentimentCalls, response.Sentiment)
+				}
+				return
+			}
+
+			if sentimentCalls != 1 {
+				t.Errorf("%d sentiment calls, want one batch call", sentimentCalls)
+			}
+			var rated int
+			var total float64
+			for _, source := range response.Sources {
+				if source.Type != "article" {
+					continue
+				}
+				if source.Sentiment == nil {
+					t.Errorf("article source %s carries no sentiment", source.Title)
+					continue
+				}
+				rated++
+				total += source.Sentiment.Score
+			}
+			if rated == 0 || response.Sentiment == nil || response.Sentiment.ArticleCount != rated {
+				t.Fatalf("summary = %+v over %d rated sources, want one covering every rated article", response.Sentiment, rated)
+			}
+			if math.Abs(response.Sentiment.Score-total/float64(rated)) > 1e-9 {
+				t.Errorf("aggregate score = %.3f, want the mean %.3f", response.Sentiment.Score, total/float64(rated))
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"math"
+	"sort"
+)
+
+// cosineSimilarity returns the cosine of the angle between two embeddings, 0 when either is empty or mismatched
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// topArticlesBySimilarity returns the limit articles closest to the query, embeddings must line up with articles
+func topArticlesBySimilarity(articles []models.NewsArticle, embeddings [][]float64, queryEmbedding []float64, limit int) []models.NewsArticle {
+	type scoredArticle struct {
+		article models.NewsArticle
+		score   float64
+	}
+
+	scored := make([]scoredArticle, len(articles))
+	for i, article := range articles {
+		scored[i] = scoredArticle{article: article, score: cosineSimilarity(embeddings[i], queryEmbedding)}
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	if limit > len(scored) {
+		limit = len(scored)
+	}
+
+	result := make([]models.NewsArticle, limit)
+	for i := 0; i < limit; i++ {
+		result[i] = scored[i].article
+	}
+
+	return result
+}
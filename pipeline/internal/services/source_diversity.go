@@ -0,0 +1,70 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"sort"
+	"strings"
+)
+
+// capPerSource keeps at most maxPerSource items from any one source, choosing each source's highest scoring
+// items so the slots freed by a dominant outlet go to the next most relevant items from other sources.
+// Items keep their original order, items without a source are never capped and maxPerSource <= 0 disables the cap.
+func capPerSource[T any](items []T, maxPerSource int, source func(T) string, score func(T) float64) ([]T, int) {
+	if maxPerSource <= 0 || len(items) <= maxPerSource {
+		return items, 0
+	}
+
+	bySource := make(map[string][]int)
+	for i, item := range items {
+		if key := normalizeSourceKey(source(item)); key != "" {
+			bySource[key] = append(bySource[key], i)
+		}
+	}
+
+	dropped := make(map[int]bool)
+	for _, indexes := range bySource {
+		if len(indexes) <= maxPerSource {
+			continue
+		}
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return score(items[indexes[a]]) > score(items[indexes[b]])
+		})
+		for _, index := range indexes[maxPerSource:] {
+			dropped[index] = true
+		}
+	}
+
+	if len(dropped) == 0 {
+		return items, 0
+	}
+
+	kept := make([]T, 0, len(items)-len(dropped))
+	for i, item := range items {
+		if !dropped[i] {
+			kept = append(kept, item)
+		}
+	}
+	return kept, len(dropped)
+}
+
+// normalizeSourceKey treats "CNN", "cnn " and "www.cnn.com" as the same outlet
+func normalizeSourceKey(source string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(source)), "www.")
+}
+
+func diversifyArticles(articles []models.NewsArticle, maxPerSource int) ([]models.NewsArticle, int) {
+	return capPerSource(articles, maxPerSource,
+		func(article models.NewsArticle) string { return article.Source },
+		func(article models.NewsArticle) float64 { return article.RelevanceScore })
+}
+
+func diversifyVideos(videos []models.YouTubeVideo, maxPerChannel int) ([]models.YouTubeVideo, int) {
+	return capPerSource(videos, maxPerChannel,
+		func(video models.YouTubeVideo) string {
+			if video.ChannelID != "" {
+				return video.ChannelID
+			}
+			return video.Channel
+		},
+		func(video models.YouTubeVideo) float64 { return video.RelevancyScore })
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestDiversifyArticlesCapsEachSource(t *testing.T) {
+	t.Parallel()
+	article := func(title, source string, score float64) models.NewsArticle {
+		return models.NewsArticle{Title: title, Source: source, RelevanceScore: score}
+	}
+	articles := []models.NewsArticle{
+		article("cnn 1", "CNN", 0.5), article("cnn 2", "cnn ", 0.9), article("reuters 1", "Reuters", 0.4),
+		article("cnn 3", "CNN", 0.7), article("cnn 4", "CNN", 0.95), article("unsourced 1", "", 0.3),
+		article("unsourced 2", "", 0.2), article("unsourced 3", "", 0.1),
+	}
+
+	tests := []struct {
+		name        string
+		max         int
+		want        []string
+		wantDropped int
+	}{
+		{name: "keeps the most relevant per source in order", max: 2,
+			want: []string{"cnn 2", "reuters 1", "cnn 4", "unsourced 1", "unsourced 2", "unsourced 3"}, wantDropped: 2},
+		{name: "one per source", max: 1,
+			want: []string{"reuters 1", "cnn 4", "unsourced 1", "unsourced 2", "unsourced 3"}, wantDropped: 3},
+		{name: "disabled", max: 0,
+			want: []string{"cnn 1", "cnn 2", "reuters 1", "cnn 3", "cnn 4", "unsourced 1", "unsourced 2", "unsourced 3"}},
+	}
+	for _, tt := range tests {
+		kept, dropped := diversifyArticles(slices.Clone(articles), tt.max)
+		var titles []string
+		for _, article := range kept {
+			titles = append(titles, article.Title)
+		}
+		if !slices.Equal(titles, tt.want) || dropped != tt.wantDropped {
+			t.Errorf("%s: diversifyArticles() = %v, %d dropped, want %v, %d dropped", tt.name, titles, dropped, tt.want, tt.wantDropped)
+		}
+	}
+}
+
+func TestDiversifyVideosCapsEachChannel(t *testing.T) {
+	t.Parallel()
+	videos := []models.YouTubeVideo{
+		{ID: "a", ChannelID: "UC1", Channel: "News Channel", RelevancyScore: 0.6},
+		{ID: "b", ChannelID: "UC1", Channel: "News Channel", RelevancyScore: 0.8},
+		// a renamed channel is still the same channel ID
+		{ID: "c", ChannelID: "UC1", Channel: "News Channel Live", RelevancyScore: 0.7},
+		{ID: "d", Channel: "Local Reporter", RelevancyScore: 0.5},
+		{ID: "e", Channel: "Local Reporter", RelevancyScore: 0.9},
+	}
+
+	kept, dropped := diversifyVideos(videos, 1)
+	var ids []string
+	for _, video := range kept {
+		ids = append(ids, video.ID)
+	}
+	if !slices.Equal(ids, []string{"b", "e"}) || dropped != 3 {
+		t.Errorf("diversifyVideos() = %v, %d dropped, want [b e], 3 dropped", ids, dropped)
+	}
+}
+
+func TestRelevantArticlesRespectTheSourceCap(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"MAX_ARTICLES_PER_SOURCE": "2"}), "elections", models.IntentNewNewsQuery)
+	for i := range workflow.corpus.Articles {
+		workflow.corpus.Articles[i].Source = "CNN"
+	}
+	workflow.corpus.Articles[3].Source = "Reuters"
+	workflow.corpus.Articles[4].Source = "BBC News"
+
+	response, err := workflow.run("workflow-source-cap")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	perSource := make(map[string]int)
+	for _, source := range response.Sources {
+		if source.Type == "article" {
+			perSource[source.Source]++
+		}
+	}
+	if want := map[string]int{"CNN": 2, "Reuters": 1, "BBC News": 1}; !maps.Equal(perSource, want) {
+		t.Errorf("article sources = %v, want %v", perSource, want)
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// offloadSources stores a completed response's sources and swaps them for a reference, keeping the response
+// small. Stateless workflows, and any store failure, keep the sources inline.
+func (orchestrator *Orchestrator) offloadSources(ctx context.Context, workflowCtx *models.WorkflowContext, response *models.WorkflowResponse) {
+	if !orchestrator.config.Workflow.SourcesByReference || workflowCtx.Stateless || len(response.Sources) == 0 {
+		return
+	}
+
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	sources := &models.WorkflowSources{
+		WorkflowID: workflowCtx.ID,
+		UserID:     workflowCtx.UserID,
+		TenantID:   workflowCtx.TenantID,
+		Sources:    response.Sources,
+		StoredAt:   time.Now(),
+	}
+	if err := orchestrator.redisService.StoreWorkflowSources(storeCtx, sources, orchestrator.config.Workflow.SourcesTTL); err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to store workflow sources, returning them inline", "workflow_id", workflowCtx.ID)
+		return
+	}
+
+	response.SourcesURL = fmt.Sprintf("/api/v1/workflows/%s/sources", workflowCtx.ID)
+	response.SourcesCount = len(response.Sources)
+	response.Sources = nil
+}
+
+// GetWorkflowSources returns the sources a workflow's response referenced, falling back to the persisted result
+// once they expired. Nil without an error means neither is kept.
+func (orchestrator *Orchestrator) GetWorkflowSources(ctx context.Context, workflowID string) (*models.WorkflowSources, error) {
+	sources, err := orchestrator.redisService.GetWorkflowSources(ctx, workflowID)
+	if err != nil || sources != nil {
+		return sources, err
+	}
+
+	result, err := orchestrator.GetWorkflowResult(ctx, workflowID)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return &models.WorkflowSources{
+		WorkflowID: result.WorkflowID,
+		UserID:     result.UserID,
+		TenantID:   result.TenantID,
+		Sources:    result.Sources,
+		StoredAt:   result.StoredAt,
+	}, nil
+}
+
+// SourcesPageSize resolves a requested page size, zero or less takes the default and larger ones are capped
+func (orchestrator *Orchestrator) SourcesPageSize(requested int) int {
+	if requested <= 0 {
+		return orchestrator.config.Workflow.SourcesPageSize
+	}
+	return min(requested, orchestrator.config.Workflow.SourcesMaxPageSize)
+}
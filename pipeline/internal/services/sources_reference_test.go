@@ -0,0 +1,82 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSourcesAreServedByReference(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"SOURCES_BY_REFERENCE": "true", "SOURCES_TTL": "1h"}),
+		"elections", models.IntentNewNewsQuery)
+	redis, redisService := newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second, MaxContextBytes: 1 << 20})
+	workflow.orchestrator.redisService = redisService
+
+	response, err := workflow.run("workflow-referenced")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if response.Sources != nil || response.SourcesURL != "/api/v1/workflows/workflow-referenced/sources" || response.SourcesCount == 0 {
+		t.Fatalf("response carries %d sources, url %q and count %d, want only the reference", len(response.Sources), response.SourcesURL, response.SourcesCount)
+	}
+	sources, err := workflow.orchestrator.GetWorkflowSources(context.Background(), "workflow-referenced")
+	if err != nil || sources == nil {
+		t.Fatalf("GetWorkflowSources() = %v, %v, want the stored sources", sources, err)
+	}
+	if len(sources.Sources) != response.SourcesCount || sources.UserID != "user-1" {
+		t.Errorf("stored %d sources for %q, want %d for user-1", len(sources.Sources), sources.UserID, response.SourcesCount)
+	}
+
+	redis.Advance(2 * time.Hour)
+	if sources, err := workflow.orchestrator.GetWorkflowSources(context.Background(), "workflow-referenced"); err != nil || sources != nil {
+		t.Errorf("GetWorkflowSources() past the TTL = %v, %v, want nothing without a result store", sources, err)
+	}
+}
+
+func TestExpiredSourcesFallBackToThePersistedResult(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"SOURCES_BY_REFERENCE": "true", "SOURCES_TTL": "1h"}),
+		"elections", models.IntentNewNewsQuery)
+	redis, redisService := newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second, MaxContextBytes: 1 << 20})
+	workflow.orchestrator.redisService = redisService
+	workflow.orchestrator.SetResultStore(NewRedisResultStore(redisService, 30*24*time.Hour))
+
+	response, err := workflow.run("workflow-persisted")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	redis.Advance(2 * time.Hour)
+	sources, err := workflow.orchestrator.GetWorkflowSources(context.Background(), "workflow-persisted")
+	if err != nil || sources == nil {
+		t.Fatalf("GetWorkflowSources() = %v, %v, want the persisted result's sources", sources, err)
+	}
+	if len(sources.Sources) != response.SourcesCount || sources.UserID != "user-1" {
+		t.Errorf("persisted %d sources for %q, want %d for user-1", len(sources.Sources), sources.UserID, response.SourcesCount)
+	}
+}
+
+func TestStatelessWorkflowsKeepTheirSourcesInline(t *testing.T) {
+	// the test workflow's redis is unreachable, so there is nowhere to keep the sources
+	workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{"SOURCES_BY_REFERENCE": "true"}), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-inline")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	if len(response.Sources) == 0 || response.SourcesURL != "" {
+		t.Errorf("response carries %d sources and url %q, want the sources inline", len(response.Sources), response.SourcesURL)
+	}
+}
+
+func TestSourcesPageSize(t *testing.T) {
+	orchestrator := newTestOrchestrator(t, loadTestConfig(t, map[string]string{"SOURCES_PAGE_SIZE": "20", "SOURCES_MAX_PAGE_SIZE": "50"}))
+	for requested, want := range map[int]int{0: 20, -3: 20, 10: 10, 50: 50, 500: 50} {
+		if got := orchestrator.SourcesPageSize(requested); got != want {
+			t.Errorf("SourcesPageSize(%d) = %d, want %d", requested, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/pkg/logger"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DependencyCheck is one dependency probed by the startup health gate
+type DependencyCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// RetryWithBackoff calls fn until it succeeds or the context ends, doubling the wait between attempts up to the max backoff
+func RetryWithBackoff(ctx context.Context, startup config.StartupConfig, name string, log *logger.Logger, fn func(ctx context.Context) error) error {
+	backoff := startup.InitialBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, startup.AttemptTimeout)
+		err := fn(attemptCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				log.Info("Dependency became healthy", "dependency", name, "attempts", attempt)
+			}
+			return nil
+		}
+
+		log.WithError(err).Warn("Dependency not ready, retrying", "dependency", name, "attempt", attempt, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready after %d attempts: %w", name, attempt, err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if startup.MaxBackoff > 0 && backoff > startup.MaxBackoff {
+			backoff = startup.MaxBackoff
+		}
+	}
+}
+
+// WaitForDependencies probes every dependency in parallel until the startup deadline. Only critical
+// dependencies that never become healthy fail startup, the rest are logged and left degraded.
+func WaitForDependencies(ctx context.Context, startup config.StartupConfig, checks []DependencyCheck, log *logger.Logger) error {
+	gateCtx, cancel := context.WithTimeout(ctx, startup.Deadline)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(checks))
+
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check DependencyCheck) {
+			defer wg.Done()
+			errs[i] = RetryWithBackoff(gateCtx, startup, check.Name, log, check.Check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	var criticalErr error
+	for i, check := range checks {
+		if errs[i] == nil {
+			continue
+		}
+		if check.Critical {
+			log.WithError(errs[i]).Error("Critical dependency failed startup health gate", "dependency", check.Name)
+			if criticalErr == nil {
+				criticalErr = errs[i]
+			}
+			continue
+		}
+		log.WithError(errs[i]).Warn("Non-critical dependency unhealthy, starting degraded", "dependency", check.Name)
+	}
+
+	return criticalErr
+}
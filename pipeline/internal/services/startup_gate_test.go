@@ -0,0 +1,80 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testStartupConfig = config.StartupConfig{
+	HealthGate:     true,
+	Deadline:       2 * time.Second,
+	AttemptTimeout: 100 * time.Millisecond,
+	InitialBackoff: 5 * time.Millisecond,
+	MaxBackoff:     20 * time.Millisecond,
+}
+
+// healthyAfter fails its first failures calls and succeeds from then on
+func healthyAfter(failures int32, calls *atomic.Int32) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if calls.Add(1) <= failures {
+			return errors.New("still warming up")
+		}
+		return nil
+	}
+}
+
+func TestWaitForDependenciesRetriesUntilHealthy(t *testing.T) {
+	var calls atomic.Int32
+	checks := []DependencyCheck{{Name: "chromadb", Critical: true, Check: healthyAfter(3, &calls)}}
+
+	if err := WaitForDependencies(context.Background(), testStartupConfig, checks, newTestLogger(t)); err != nil {
+		t.Fatalf("WaitForDependencies() error = %v", err)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("health check ran %d times, want 4", got)
+	}
+}
+
+func TestWaitForDependenciesFailsOnlyForCriticalDependencies(t *testing.T) {
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	startup := testStartupConfig
+	startup.Deadline = 100 * time.Millisecond
+
+	var calls atomic.Int32
+	degraded := []DependencyCheck{
+		{Name: "redis", Critical: true, Check: healthyAfter(1, &calls)},
+		{Name: "scraper", Check: down},
+	}
+	if err := WaitForDependencies(context.Background(), startup, degraded, newTestLogger(t)); err != nil {
+		t.Errorf("a non-critical dependency failed startup: %v", err)
+	}
+
+	failed := []DependencyCheck{{Name: "gemini", Critical: true, Check: down}}
+	if err := WaitForDependencies(context.Background(), startup, failed, newTestLogger(t)); err == nil {
+		t.Error("a critical dependency that never became healthy did not fail startup")
+	}
+}
+
+func TestRetryWithBackoffBoundsEachAttempt(t *testing.T) {
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	startup := testStartupConfig
+	startup.AttemptTimeout = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := RetryWithBackoff(ctx, startup, "ollama", newTestLogger(t), hang); err == nil {
+		t.Fatal("RetryWithBackoff() succeeded for a dependency that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("RetryWithBackoff() took %s, the deadline was 200ms", elapsed)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrCallbackNotAllowed is returned for callback urls whose host is not on the callback allowlist or resolves
+// into the private network
+var ErrCallbackNotAllowed = models.NewValidationError("CALLBACK_NOT_ALLOWED", "Callback url is not allowed",
+	"callback hosts must be listed in SUBSCRIPTION_CALLBACK_HOSTS and resolve to public addresses")
+
+// CheckCallbackURL refuses callback urls the server must not post to. Callbacks are requested by callers, so
+// without these checks a subscription could make the server reach internal services.
+func (orchestrator *Orchestrator) CheckCallbackURL(ctx context.Context, callbackURL string) error {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: %q is not an absolute http or https url", ErrCallbackNotAllowed, callbackURL)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	allowed := false
+	for _, allowedHost := range orchestrator.config.Subscriptions.CallbackHosts {
+		if strings.EqualFold(allowedHost, host) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("%w: host %s is not on the callback allowlist", ErrCallbackNotAllowed, host)
+	}
+
+	if orchestrator.config.Subscriptions.AllowPrivateCallbacks {
+		return nil
+	}
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: failed to resolve %s: %v", ErrCallbackNotAllowed, host, err)
+	}
+	for _, address := range addresses {
+		if isPrivateAddress(address.IP) {
+			return fmt.Errorf("%w: %s resolves to the private address %s", ErrCallbackNotAllowed, host, address.IP)
+		}
+	}
+	return nil
+}
+
+// keep-alive connections to callback hosts are reused between polls but not held open indefinitely
+const (
+	callbackIdleConnTimeout     = 90 * time.Second
+	callbackTLSHandshakeTimeout = 10 * time.Second
+)
+
+// callbackClient posts callbacks without following redirects and refuses to connect to private addresses, the
+// host may resolve differently at send time than when the callback was checked. It is built once so every poll
+// shares one connection pool.
+func (orchestrator *Orchestrator) callbackClient() *http.Client {
+	orchestrator.callbackClientOnce.Do(func() {
+		orchestrator.callbackHTTPClient = orchestrator.newCallbackClient()
+	})
+	return orchestrator.callbackHTTPClient
+}
+
+func (orchestrator *Orchestrator) newCallbackClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: callbackTLSHandshakeTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if orchestrator.config.Subscriptions.AllowPrivateCallbacks {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateAddress(ip) {
+				return fmt.Errorf("%w: refusing to connect to %s", ErrCallbackNotAllowed, host)
+			}
+			return nil
+		},
+	}
+
+	responseHeaderTimeout := orchestrator.config.Subscriptions.CallbackTimeout
+	if responseHeaderTimeout <= 0 {
+		responseHeaderTimeout = 10 * time.Second
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			IdleConnTimeout:       callbackIdleConnTimeout,
+			TLSHandshakeTimeout:   callbackTLSHandshakeTimeout,
+			ResponseHeaderTimeout: responseHeaderTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPrivateAddress reports loopback, private, link-local and unspecified addresses
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// seen ids kept per subscription, well above what one poll can return so reprints of older stories stay known
+const maxSeenArticleIDs = 500
+
+// ErrSubscriptionLimit is returned when the user already follows the maximum number of stories
+var ErrSubscriptionLimit = models.NewValidationError("SUBSCRIPTION_LIMIT", "Subscription limit reached", "unsubscribe from a story before following another")
+
+// ErrSubscriptionNotFound is returned for unknown subscriptions and ones owned by another user
+var ErrSubscriptionNotFound = models.NewNotFoundError("SUBSCRIPTION_NOT_FOUND", "Subscription not found")
+
+// CreateSubscription follows a story for the user. The current articles are marked seen straight away
+// so the first notification only carries new developments.
+func (orchestrator *Orchestrator) CreateSubscription(ctx context.Context, tenantID string, req *models.CreateSubscriptionRequest) (*models.Subscription, error) {
+	existing, err := orchestrator.redisService.ListSubscriptions(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= orchestrator.config.Subscriptions.MaxPerUser {
+		return nil, ErrSubscriptionLimit
+	}
+	if req.CallbackURL != "" {
+		if err := orchestrator.CheckCallbackURL(ctx, req.CallbackURL); err != nil {
+			return nil, err
+		}
+	}
+
+	keywords := subscriptionKeywords(req.Topic, req.Keywords)
+
+	subscription := &models.Subscription{
+		ID:          models.GenerateWorkflowID(),
+		UserID:      req.UserID,
+		TenantID:    tenantID,
+		Topic:       strings.TrimSpace(req.Topic),
+		Keywords:    keywords,
+		CallbackURL: req.CallbackURL,
+		CreatedAt:   time.Now(),
+	}
+
+	if articles, err := orchestrator.fetchSubscriptionArticles(ctx, subscription); err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to prime subscription, the first poll will report current articles",
+			"subscription_id", subscription.ID)
+	} else {
+		_, subscription.SeenArticleIDs = diffSeenArticles(articles, nil, maxSeenArticleIDs)
+		subscription.LastCheckedAt = time.Now()
+	}
+
+	if err := orchestrator.redisService.StoreSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	orchestrator.logger.Info("Subscription created",
+		"subscription_id", subscription.ID,
+		"user_id", subscription.UserID,
+		"keywords", subscription.Keywords)
+
+	return subscription, nil
+}
+
+func (orchestrator *Orchestrator) SubscriptionsEnabled() bool {
+	return orchestrator.config.Subscriptions.Enabled
+}
+
+func (orchestrator *Orchestrator) ListSubscriptions(ctx context.Context, userID string) ([]*models.Subscription, error) {
+	return orchestrator.redisService.ListSubscriptions(ctx, userID)
+}
+
+func (orchestrator *Orchestrator) DeleteSubscription(ctx context.Context, userID, subscriptionID string) error {
+	subscription, err := orchestrator.redisService.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil || subscription.UserID != userID {
+		return ErrSubscriptionNotFound
+	}
+
+	return orchestrator.redisService.DeleteSubscription(ctx, subscription)
+}
+
+// RunSubscriptionPoller checks every subscription once per poll interval until ctx ends
+func (orchestrator *Orchestrator) RunSubscriptionPoller(ctx context.Context) {
+	interval := orchestrator.config.Subscriptions.PollInterval
+	orchestrator.logger.Info("Subscription poller started", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			orchestrator.logger.Info("Subscription poller stopped")
+			return
+		case <-ticker.C:
+			orchestrator.pollSubscriptions(ctx)
+		}
+	}
+}
+
+func (orchestrator *Orchestrator) pollSubscriptions(ctx context.Context) {
+	startTime := time.Now()
+
+	subscriptions, err := orchestrator.redisService.ListSubscriptions(ctx, "")
+	if err != nil {
+		orchestrator.logger.WithError(err).Error("Failed to list subscriptions for polling")
+		return
+	}
+
+	notified := 0
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			return
+		}
+		sent, err := orchestrator.pollSubscription(ctx, subscription)
+		if err != nil {
+			orchestrator.logger.WithError(err).Warn("Subscription poll failed", "subscription_id", subscription.ID)
+			continue
+		}
+		if sent {
+			notified++
+		}
+	}
+
+	orchestrator.logger.LogService("subscriptions", "poll", time.Since(startTime), map[string]interface{}{
+		"subscriptions": len(subscriptions),
+		"notified":      notified,
+	}, nil)
+}
+
+// pollSubscription fetches the story's recent articles and notifies the user about unseen ones,
+// reporting whether a notification went out
+func (orchestrator *Orchestrator) pollSubscription(ctx context.Context, subscription *models.Subscription) (bool, error) {
+	articles, err := orchestrator.fetchSubscriptionArticles(ctx, subscription)
+	if err != nil {
+		return false, err
+	}
+
+	newArticles, seen := diffSeenArticles(articles, subscription.SeenArticleIDs, maxSeenArticleIDs)
+	subscription.SeenArticleIDs = seen
+	subscription.LastCheckedAt = time.Now()
+
+	if len(newArticles) > 0 {
+		orchestrator.notifySubscriber(ctx, subscription, newArticles)
+		subscription.LastNotifiedAt = time.Now()
+	}
+
+	if err := orchestrator.redisService.StoreSubscription(ctx, subscription); err != nil {
+		return false, err
+	}
+
+	return len(newArticles) > 0, nil
+}
+
+// fetchSubscriptionArticles runs the lightweight fetch for a story, a single news search with no model calls,
+// keeping only the articles that mention one of the story's keywords
+func (orchestrator *Orchestrator) fetchSubscriptionArticles(ctx context.Context, subscription *models.Subscription) ([]models.NewsArticle, error) {
+	subscriptionConfig := orchestrator.config.Subscriptions
+
+	fetchCtx, cancel := context.WithTimeout(ctx, subscriptionConfig.FetchTimeout)
+	defer cancel()
+
+	query := strings.Join(subscription.Keywords, " OR ")
+	articles, err := orchestrator.newsService.SearchRecentNews(fetchCtx, query,
+		int(subscriptionConfig.Lookback.Hours()), subscriptionConfig.FetchLimit)
+	if err != nil {
+		return nil, fmt.Errorf("subscription fetch failed: %w", err)
+	}
+
+	relevant := make([]models.NewsArticle, 0, len(articles))
+	for _, article := range articles {
+		if mentionsAnyKeyword(article, subscription.Keywords) {
+			relevant = append(relevant, article)
+		}
+	}
+
+	return relevant, nil
+}
+
+// notifySubscriber publishes the new articles to the user's update stream and posts them to the callback url when set
+func (orchestrator *Orchestrator) notifySubscriber(ctx context.Context, subscription *models.Subscription, articles []models.NewsArticle) {
+	update := models.SubscriptionUpdate{
+		SubscriptionID: subscription.ID,
+		UserID:         subscription.UserID,
+		Topic:          subscription.Topic,
+		Articles:       buildResponseSources(articles, nil, time.Now(), SourceSortFreshness),
+		Timestamp:      time.Now(),
+	}
+
+	agentUpdate := models.NewAgentUpdate("subscription:"+subscription.ID, "", "subscription", models.AgentStatusCompleted,
+		fmt.Sprintf("%d new articles on %s", len(articles), subscription.Topic)).
+		WithData(map[string]interface{}{"subscription_update": update})
+	if err := orchestrator.redisService.PublishAgentUpdate(ctx, subscription.UserID, agentUpdate); err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to publish subscription update", "subscription_id", subscription.ID)
+	}
+
+	if subscription.CallbackURL == "" {
+		return
+	}
+	if err := orchestrator.postSubscriptionCallback(ctx, subscription.CallbackURL, update); err != nil {
+		orchestrator.logger.WithError(err).Warn("Subscription callback failed",
+			"subscription_id", subscription.ID,
+			"callback_url", subscription.CallbackURL)
+	}
+}
+
+func (orchestrator *Orchestrator) postSubscriptionCallback(ctx context.Context, callbackURL string, update models.SubscriptionUpdate) error {
+	// the allowlist may have changed and the host may resolve elsewhere since the subscription was created
+	if err := orchestrator.CheckCallbackURL(ctx, callbackURL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	callbackCtx, cancel := context.WithTimeout(ctx, orchestrator.config.Subscriptions.CallbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(callbackCtx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := orchestrator.callbackClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// the body is not needed, reading it to the end lets the connection go back to the pool
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// diffSeenArticles splits out the articles not seen before and returns the updated seen list,
+// newest ids last and trimmed to limit
+func diffSeenArticles(articles []models.NewsArticle, seen []string, limit int) ([]models.NewsArticle, []string) {
+	known := make(map[string]bool, len(seen)+len(articles))
+	for _, articleID := range seen {
+		known[articleID] = true
+	}
+
+	updated := append([]string(nil), seen...)
+	var newArticles []models.NewsArticle
+	for _, article := range articles {
+		if article.ID == "" || known[article.ID] {
+			continue
+		}
+		known[article.ID] = true
+		updated = append(updated, article.ID)
+		newArticles = append(newArticles, article)
+	}
+
+	if len(updated) > limit {
+		updated = updated[len(updated)-limit:]
+	}
+
+	return newArticles, updated
+}
+
+// subscriptionKeywords uses the given keywords, falling back to the topic itself, deduplicated case insensitively
+func subscriptionKeywords(topic string, keywords []string) []string {
+	if len(keywords) == 0 {
+		keywords = []string{topic}
+	}
+
+	seen := make(map[string]bool, len(keywords))
+	result := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" || seen[strings.ToLower(keyword)] {
+			continue
+		}
+		seen[strings.ToLower(keyword)] = true
+		result = append(result, keyword)
+	}
+	return result
+}
+
+func mentionsAnyKeyword(article models.NewsArticle, keywords []string) bool {
+	text := strings.ToLower(article.Title + " " + article.Description)
+	for _, keyword := range keywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
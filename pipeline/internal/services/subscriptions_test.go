@@ -0,0 +1,245 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiffSeenArticles(t *testing.T) {
+	articles := func(ids ...string) []models.NewsArticle {
+		result := make([]models.NewsArticle, len(ids))
+		for i, id := range ids {
+			result[i] = models.NewsArticle{ID: id}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		articles []models.NewsArticle
+		seen     []string
+		limit    int
+		wantNew  []string
+		wantSeen []string
+	}{
+		{name: "first poll reports everything", articles: articles("a", "b"), limit: 10,
+			wantNew: []string{"a", "b"}, wantSeen: []string{"a", "b"}},
+		{name: "seen articles are not reported again", articles: articles("a", "b", "c"), seen: []string{"a", "b"}, limit: 10,
+			wantNew: []string{"c"}, wantSeen: []string{"a", "b", "c"}},
+		{name: "nothing new", articles: articles("b", "a"), seen: []string{"a", "b"}, limit: 10,
+			wantSeen: []string{"a", "b"}},
+		{name: "repeats and missing ids within a poll", articles: articles("c", "", "c"), seen: []string{"a"}, limit: 10,
+			wantNew: []string{"c"}, wantSeen: []string{"a", "c"}},
+		{name: "the oldest seen ids are dropped past the limit", articles: articles("c", "d"), seen: []string{"a", "b"}, limit: 3,
+			wantNew: []string{"c", "d"}, wantSeen: []string{"b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newArticles, seen := diffSeenArticles(tt.articles, tt.seen, tt.limit)
+
+			var newIDs []string
+			for _, article := range newArticles {
+				newIDs = append(newIDs, article.ID)
+			}
+			if !slices.Equal(newIDs, tt.wantNew) {
+				t.Errorf("new = %v, want %v", newIDs, tt.wantNew)
+			}
+			if !slices.Equal(seen, tt.wantSeen) {
+				t.Errorf("seen = %v, want %v", seen, tt.wantSeen)
+			}
+		})
+	}
+}
+
+func newTestSubscriptions(t *testing.T, maxPerUser int) (*Orchestrator, *fakeNewsAPI) {
+	t.Helper()
+	cfg := config.Config{}
+	cfg.Subscriptions = config.SubscriptionConfig{Enabled: true, MaxPerUser: maxPerUser, FetchLimit: 20,
+		Lookback: 24 * time.Hour, FetchTimeout: 5 * time.Second, CallbackTimeout: 5 * time.Second,
+		CallbackHosts: []string{"127.0.0.1"}, AllowPrivateCallbacks: true}
+	orchestrator := newTestOrchestrator(t, cfg)
+	_, orchestrator.redisService = newFakeRedis(t, config.RedisConfig{})
+
+	api, newsService := newFakeNewsAPI(t, map[string][]APIArticles{"everything": {
+		apiArticle("Election results delayed", "https://example.com/results"),
+		apiArticle("Election turnout hits a record", "https://example.com/turnout"),
+		apiArticle("Cup final goes to penalties", "https://example.com/football"),
+	}})
+	orchestrator.newsService = newsService
+	return orchestrator, api
+}
+
+func TestSubscriptionPollsNotifyOnlyAboutNewArticles(t *testing.T) {
+	orchestrator, api := newTestSubscriptions(t, 5)
+
+	var mu sync.Mutex
+	var updates []models.SubscriptionUpdate
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var update models.SubscriptionUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			t.Errorf("callback body: %v", err)
+		}
+		mu.Lock()
+		updates = append(updates, update)
+		mu.Unlock()
+	}))
+	t.Cleanup(callback.Close)
+	callbacks := func() []models.SubscriptionUpdate {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]models.SubscriptionUpdate(nil), updates...)
+	}
+
+	ctx := context.Background()
+	subscription, err := orchestrator.CreateSubscription(ctx, "", &models.CreateSubscriptionRequest{
+		UserID: "user-1", Topic: "election", CallbackURL: callback.URL,
+	})
+	if err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+	// the stories already out when the user subscribed never trigger a notification
+	if len(subscription.SeenArticleIDs) != 2 {
+		t.Fatalf("seen = %v, want the two election articles primed", subscription.SeenArticleIDs)
+	}
+
+	orchestrator.pollSubscriptions(ctx)
+	if got := callbacks(); len(got) != 0 {
+		t.Fatalf("callbacks = %+v, want none before anything new is published", got)
+	}
+
+	api.publish("everything", apiArticle("Election recount ordered", "https://example.com/recount"))
+	orchestrator.pollSubscriptions(ctx)
+	orchestrator.pollSubscriptions(ctx)
+
+	got := callbacks()
+	if len(got) != 1 {
+		t.Fatalf("callbacks = %+v, want exactly one for the new article", got)
+	}
+	if update := got[0]; update.SubscriptionID != subscription.ID || len(update.Articles) != 1 ||
+		update.Articles[0].URL != "https://example.com/recount" {
+		t.Errorf("update = %+v, want only the recount article", update)
+	}
+
+	stored, err := orchestrator.redisService.GetSubscription(ctx, subscription.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetSubscription() = %v, %v", stored, err)
+	}
+	if len(stored.SeenArticleIDs) != 3 || stored.LastNotifiedAt.IsZero() {
+		t.Errorf("stored seen %v notified at %s, want three seen ids and a notification time", stored.SeenArticleIDs, stored.LastNotifiedAt)
+	}
+}
+
+func TestCreateSubscriptionEnforcesThePerUserLimit(t *testing.T) {
+	orchestrator, _ := newTestSubscriptions(t, 1)
+	ctx := context.Background()
+
+	if _, err := orchestrator.CreateSubscription(ctx, "", &models.CreateSubscriptionRequest{UserID: "user-1", Topic: "election"}); err != nil {
+		t.Fatalf("first CreateSubscription() error = %v", err)
+	}
+	if _, err := orchestrator.CreateSubscription(ctx, "", &models.CreateSubscriptionRequest{UserID: "user-1", Topic: "turnout"}); !errors.Is(err, ErrSubscriptionLimit) {
+		t.Errorf("second CreateSubscription() error = %v, want ErrSubscriptionLimit", err)
+	}
+	if _, err := orchestrator.CreateSubscription(ctx, "", &models.CreateSubscriptionRequest{UserID: "user-2", Topic: "election"}); err != nil {
+		t.Errorf("another user's CreateSubscription() error = %v, want the limit to be per user", err)
+	}
+}
+
+func TestCheckCallbackURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		callbackURL  string
+		hosts        []string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{name: "listed public host", callbackURL: "https://93.184.216.34/hook", hosts: []string{"93.184.216.34"}},
+		{name: "no hosts configured", callbackURL: "https://93.184.216.34/hook", wantErr: true},
+		{name: "host not listed", callbackURL: "https://hooks.example.net/hook", hosts: []string{"93.184.216.34"}, wantErr: true},
+		{name: "not http", callbackURL: "gopher://93.184.216.34/hook", hosts: []string{"93.184.216.34"}, wantErr: true},
+		{name: "loopback", callbackURL: "http://localhost:8080/hook", hosts: []string{"localhost"}, wantErr: true},
+		{name: "private network", callbackURL: "http://10.0.0.7/hook", hosts: []string{"10.0.0.7"}, wantErr: true},
+		{name: "cloud metadata", callbackURL: "http://169.254.169.254/latest", hosts: []string{"169.254.169.254"}, wantErr: true},
+		{name: "mapped loopback", callbackURL: "http://[::ffff:127.0.0.1]/hook", hosts: []string{"::ffff:127.0.0.1"}, wantErr: true},
+		{name: "private allowed for development", callbackURL: "http://127.0.0.1:8080/hook", hosts: []string{"127.0.0.1"}, allowPrivate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{}
+			cfg.Subscriptions = config.SubscriptionConfig{CallbackHosts: tt.hosts, AllowPrivateCallbacks: tt.allowPrivate}
+			orchestrator := newTestOrchestrator(t, cfg)
+
+			err := orchestrator.CheckCallbackURL(context.Background(), tt.callbackURL)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("CheckCallbackURL(%s) error = %v, want error %v", tt.callbackURL, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrCallbackNotAllowed) {
+				t.Errorf("CheckCallbackURL() error = %v, want ErrCallbackNotAllowed", err)
+			}
+		})
+	}
+}
+
+func TestCallbacksToPrivateAddressesAreRefusedAtSendTime(t *testing.T) {
+	orchestrator, _ := newTestSubscriptions(t, 5)
+	var posted bool
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { posted = true }))
+	t.Cleanup(callback.Close)
+
+	ctx := context.Background()
+	if _, err := orchestrator.CreateSubscription(ctx, "", &models.CreateSubscriptionRequest{
+		UserID: "user-1", Topic: "election", CallbackURL: callback.URL,
+	}); err != nil {
+		t.Fatalf("CreateSubscription() error = %v", err)
+	}
+
+	// a stored subscription is checked again, its host may resolve elsewhere by the time it is notified
+	orchestrator.config.Subscriptions.AllowPrivateCallbacks = false
+	if err := orchestrator.postSubscriptionCallback(ctx, callback.URL, models.SubscriptionUpdate{}); !errors.Is(err, ErrCallbackNotAllowed) {
+		t.Errorf("postSubscriptionCallback() error = %v, want ErrCallbackNotAllowed", err)
+	}
+	// the connection itself is refused too, whatever the host resolved to when it was checked
+	if _, err := orchestrator.callbackClient().Post(callback.URL, "application/json", nil); !errors.Is(err, ErrCallbackNotAllowed) {
+		t.Errorf("callback client error = %v, want ErrCallbackNotAllowed", err)
+	}
+	if posted {
+		t.Error("a callback reached the loopback server")
+	}
+}
+
+func TestCreateSubscriptionRefusesCallbacksOutsideTheAllowlist(t *testing.T) {
+	orchestrator, _ := newTestSubscriptions(t, 5)
+
+	_, err := orchestrator.CreateSubscription(context.Background(), "", &models.CreateSubscriptionRequest{
+		UserID: "user-1", Topic: "election", CallbackURL: "http://127.0.0.2:8080/hook",
+	})
+	if !errors.Is(err, ErrCallbackNotAllowed) {
+		t.Errorf("CreateSubscription() error = %v, want ErrCallbackNotAllowed", err)
+	}
+}
+
+func TestCallbackClientIsSharedAndBounded(t *testing.T) {
+	orchestrator, _ := newTestSubscriptions(t, 5)
+	orchestrator.config.Subscriptions.CallbackTimeout = 3 * time.Second
+
+	client := orchestrator.callbackClient()
+	if orchestrator.callbackClient() != client {
+		t.Error("callbackClient() built a second client, want one shared connection pool")
+	}
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("callback transport = %T, want *http.Transport", client.Transport)
+	}
+	if transport.IdleConnTimeout <= 0 || transport.TLSHandshakeTimeout <= 0 || transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("transport timeouts idle=%s tls=%s header=%s, want all bounded and the header timeout from config",
+			transport.IdleConnTimeout, transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// how many of a turn's keywords become topics and how many topics are kept at all
+	topicsPerTurn    = 5
+	maxCurrentTopics = 10
+)
+
+// TopicDriftResult is what one turn did to the conversation's topics
+type TopicDriftResult struct {
+	Similarity float64  `json:"similarity"`
+	Drifted    bool     `json:"drifted"`
+	Evicted    []string `json:"evicted,omitempty"`
+}
+
+// trackTopics decays the conversation's topics by how far the query moved from the topic centroid, evicts the ones
+// that faded below the evict weight and adds the turn's topics at full weight. A query below the drift threshold
+// starts a new thread: the centroid starts over and the old thread's topics go stale, decaying by the full rate
+// every turn until they are mentioned again. Otherwise the centroid follows the thread as a moving average.
+func trackTopics(conversation *models.ConversationContext, queryEmbedding []float64, turnTopics []string, drift config.WorkflowConfig) TopicDriftResult {
+	result := TopicDriftResult{Similarity: 1}
+	if conversation.TrackedTopics == nil {
+		conversation.TrackedTopics = make(map[string]models.TrackedTopic)
+	}
+
+	// topics recorded before tracking was turned on start at full weight
+	for _, topic := range conversation.CurrentTopics {
+		if _, ok := conversation.TrackedTopics[topic]; !ok {
+			conversation.TrackedTopics[topic] = models.TrackedTopic{Weight: 1}
+		}
+	}
+
+	if len(conversation.TopicCentroid) > 0 && len(conversation.TopicCentroid) == len(queryEmbedding) {
+		result.Similarity = cosineSimilarity(conversation.TopicCentroid, queryEmbedding)
+	}
+	result.Drifted = result.Similarity < drift.TopicDriftThreshold
+
+	decay := 1 - drift.TopicDecayRate*(1-max(result.Similarity, 0))
+	for topic, tracked := range conversation.TrackedTopics {
+		if tracked.Stale {
+			tracked.Weight *= 1 - drift.TopicDecayRate
+		} else {
+			tracked.Weight *= decay
+		}
+		if tracked.Weight < drift.TopicEvictWeight {
+			delete(conversation.TrackedTopics, topic)
+			result.Evicted = append(result.Evicted, topic)
+			continue
+		}
+		tracked.Stale = tracked.Stale || result.Drifted
+		conversation.TrackedTopics[topic] = tracked
+	}
+	sort.Strings(result.Evicted)
+
+	for _, topic := range turnTopics {
+		conversation.TrackedTopics[topic] = models.TrackedTopic{Weight: 1}
+	}
+
+	switch {
+	case len(queryEmbedding) == 0:
+	case result.Drifted || len(conversation.TopicCentroid) != len(queryEmbedding):
+		conversation.TopicCentroid = append([]float64(nil), queryEmbedding...)
+	default:
+		for i := range conversation.TopicCentroid {
+			conversation.TopicCentroid[i] = (1-drift.TopicDecayRate)*conversation.TopicCentroid[i] + drift.TopicDecayRate*queryEmbedding[i]
+		}
+	}
+
+	conversation.CurrentTopics = rankTopics(conversation.TrackedTopics, maxCurrentTopics)
+	for topic := range conversation.TrackedTopics {
+		if !slices.Contains(conversation.CurrentTopics, topic) {
+			delete(conversation.TrackedTopics, topic)
+		}
+	}
+
+	return result
+}
+
+// rankTopics orders topics by weight, strongest first, and keeps at most limit of them
+func rankTopics(tracked map[string]models.TrackedTopic, limit int) []string {
+	topics := make([]string, 0, len(tracked))
+	for topic := range tracked {
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if tracked[topics[i]].Weight != tracked[topics[j]].Weight {
+			return tracked[topics[i]].Weight > tracked[topics[j]].Weight
+		}
+		return topics[i] < topics[j]
+	})
+
+	if len(topics) > limit {
+		topics = topics[:limit]
+	}
+	return topics
+}
+
+// turnTopics picks this turn's topics from its keywords, normalized so the same topic is not tracked twice
+func turnTopics(keywords []string) []string {
+	var topics []string
+	for _, keyword := range keywords {
+		topic := strings.ToLower(strings.TrimSpace(keyword))
+		if topic == "" || slices.Contains(topics, topic) {
+			continue
+		}
+		topics = append(topics, topic)
+		if len(topics) == topicsPerTurn {
+			break
+		}
+	}
+	return topics
+}
+
+// trackTopicDrift runs the drift detector for the finished turn. The news workflow already embedded the query,
+// other intents embed it here, and a failed embedding only means this turn adds its topics without decaying the rest.
+func (workflowExecutor *WorkflowExecutor) trackTopicDrift(ctx context.Context) {
+	workflowCtx := workflowExecutor.workflowCtx
+
+	queryEmbedding, _ := workflowCtx.Metadata["query_embeddings"].([]float64)
+	if len(queryEmbedding) == 0 && strings.TrimSpace(workflowCtx.OriginalQuery) != "" {
+		embedding, err := workflowExecutor.orchestrator.embeddings.GenerateQueryEmbedding(ctx, workflowCtx.OriginalQuery)
+		if err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Query embedding for topic drift failed, topics not decayed this turn")
+		} else {
+			queryEmbedding = embedding
+		}
+	}
+	// the tracked topics were embedded by the primary provider, a fallback vector cannot be compared with them
+	if _, fallback := embeddingSpaceFromContext(ctx).state(); fallback != "" {
+		queryEmbedding = nil
+	}
+
+	result := trackTopics(&workflowCtx.ConversationContext, queryEmbedding, turnTopics(workflowCtx.Keywords), workflowExecutor.orchestrator.config.Workflow)
+	workflowCtx.Metadata["topic_drift"] = result
+
+	if len(result.Evicted) > 0 || result.Drifted {
+		workflowExecutor.logger.Info("Conversation topics drifted",
+			"workflow_id", workflowCtx.ID,
+			"similarity", result.Similarity,
+			"drifted", result.Drifted,
+			"evicted", result.Evicted,
+			"current_topics", workflowCtx.ConversationContext.CurrentTopics)
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"slices"
+	"testing"
+)
+
+var topicDriftConfig = config.WorkflowConfig{TopicDecayRate: 0.5, TopicEvictWeight: 0.25, TopicDriftThreshold: 0.5}
+
+func TestTrackTopicsEvictsTheTopicsOfAnAbandonedThread(t *testing.T) {
+	t.Parallel()
+	turns := []struct {
+		query       string
+		embedding   []float64
+		topics      []string
+		wantDrifted bool
+		wantTopics  []string
+		wantEvicted []string
+	}{
+		{query: "how did tesla earnings go", embedding: []float64{1, 0, 0}, topics: []string{"tesla", "earnings"},
+			wantTopics: []string{"earnings", "tesla"}},
+		{query: "what did that do to the stock", embedding: []float64{0.9, 0.1, 0}, topics: []string{"tesla", "stock"},
+			wantTopics: []string{"stock", "tesla", "earnings"}},
+		// the user moves on, the tesla thread goes stale
+		{query: "when is the next spacex launch", embedding: []float64{0, 1, 0}, topics: []string{"spacex", "launch"}, wantDrifted: true,
+			wantTopics: []string{"launch", "spacex", "stock", "tesla", "earnings"}},
+		{query: "who is on board", embedding: []float64{0.05, 1, 0}, topics: []string{"spacex", "crew"},
+			wantTopics: []string{"crew", "spacex", "launch", "stock", "tesla", "earnings"}},
+		{query: "how long is the mission", embedding: []float64{0, 1, 0.05}, topics: []string{"spacex", "mission"},
+			wantTopics: []string{"mission", "spacex", "crew", "launch"}, wantEvicted: []string{"earnings", "stock", "tesla"}},
+	}
+
+	var conversation models.ConversationContext
+	for i, turn := range turns {
+		result := trackTopics(&conversation, turn.embedding, turn.topics, topicDriftConfig)
+
+		if result.Drifted != turn.wantDrifted {
+			t.Errorf("turn %d %q: drifted = %t at similarity %.2f, want %t", i, turn.query, result.Drifted, result.Similarity, turn.wantDrifted)
+		}
+		if !slices.Equal(conversation.CurrentTopics, turn.wantTopics) {
+			t.Errorf("turn %d %q: current topics = %v, want %v", i, turn.query, conversation.CurrentTopics, turn.wantTopics)
+		}
+		if !slices.Equal(result.Evicted, turn.wantEvicted) {
+			t.Errorf("turn %d %q: evicted %v, want %v", i, turn.query, result.Evicted, turn.wantEvicted)
+		}
+	}
+}
+
+func TestTrackTopicsRevivesAStaleTopicMentionedAgain(t *testing.T) {
+	t.Parallel()
+	var conversation models.ConversationContext
+	trackTopics(&conversation, []float64{1, 0}, []string{"tesla", "earnings"}, topicDriftConfig)
+	trackTopics(&conversation, []float64{0, 1}, []string{"spacex"}, topicDriftConfig)
+
+	// tesla comes back into the conversation, earnings does not
+	trackTopics(&conversation, []float64{0, 1}, []string{"spacex", "tesla"}, topicDriftConfig)
+	trackTopics(&conversation, []float64{0, 1}, []string{"spacex"}, topicDriftConfig)
+
+	if tesla := conversation.TrackedTopics["tesla"]; tesla.Stale || tesla.Weight != 1 {
+		t.Errorf("tesla = %+v, want it revived at full weight", tesla)
+	}
+	if _, ok := conversation.TrackedTopics["earnings"]; ok || slices.Contains(conversation.CurrentTopics, "earnings") {
+		t.Errorf("earnings still tracked in %v, want it evicted", conversation.CurrentTopics)
+	}
+}
+
+func TestTrackTopicsKeepsTheTopicsRecordedBeforeTracking(t *testing.T) {
+	t.Parallel()
+	conversation := models.ConversationContext{CurrentTopics: []string{"elections"}}
+
+	result := trackTopics(&conversation, nil, []string{"polls"}, topicDriftConfig)
+
+	// without an embedding nothing can be compared, the turn only adds its topics
+	if result.Drifted || len(result.Evicted) != 0 || !slices.Equal(conversation.CurrentTopics, []string{"elections", "polls"}) {
+		t.Errorf("result %+v, current topics = %v, want [elections polls] with nothing evicted", result, conversation.CurrentTopics)
+	}
+	if conversation.TopicCentroid != nil {
+		t.Errorf("centroid = %v, want none before any query was embedded", conversation.TopicCentroid)
+	}
+}
+
+func TestTurnTopics(t *testing.T) {
+	t.Parallel()
+	got := turnTopics([]string{" Tesla ", "tesla", "", "Earnings", "Stock", "EV", "Musk", "Cybertruck"})
+	if want := []string{"tesla", "earnings", "stock", "ev", "musk"}; !slices.Equal(got, want) {
+		t.Errorf("turnTopics() = %v, want %v", got, want)
+	}
+}
+
+func TestTopicDriftRunsWhenTheExchangeIsStored(t *testing.T) {
+	orchestrator := newTestOrchestrator(t, loadTestConfig(t, map[string]string{"TOPIC_DRIFT_ENABLED": "true"}))
+	embeddings := &fakeEmbeddings{}
+	orchestrator.SetEmbeddingProvider(embeddings)
+	executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{Query: "when is the next spacex launch"})
+	executor.workflowCtx.Keywords = []string{"SpaceX", "launch"}
+	executor.workflowCtx.ConversationContext.CurrentTopics = []string{"tesla"}
+
+	if err := executor.storeConversationExchange(context.Background()); err != nil {
+		t.Fatalf("storeConversationExchange() error = %v", err)
+	}
+
+	// a chitchat or follow-up turn has no query embedding yet, the detector embeds the query itself
+	if !slices.Contains(embeddings.embeddedTexts(), "when is the next spacex launch") {
+		t.Errorf("embedded %v, want the query embedded for the drift detector", embeddings.embeddedTexts())
+	}
+	if _, ok := executor.workflowCtx.Metadata["topic_drift"].(TopicDriftResult); !ok {
+		t.Error("no topic drift result recorded for the turn")
+	}
+	if topics := executor.workflowCtx.ConversationContext.CurrentTopics; !slices.Equal(topics, []string{"launch", "spacex", "tesla"}) {
+		t.Errorf("current topics = %v, want the turn's topics tracked next to the earlier one", topics)
+	}
+}
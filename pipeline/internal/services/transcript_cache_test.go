@@ -0,0 +1,87 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCaptionsYouTube returns a YouTube service serving one english caption track per video and counting the fetches
+func newCaptionsYouTube(t *testing.T, transcript string) (*YouTubeService, *atomic.Int64) {
+	t.Helper()
+	var fetches atomic.Int64
+	mux := http.NewServeMux()
+	mux.HandleFunc("/captions", func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write([]byte(`{"items": [{"id": "caption-1", "snippet": {"language": "en"}}]}`))
+	})
+	mux.HandleFunc("/captions/caption-1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("1\n00:00:00,000 --> 00:00:04,000\n" + transcript + "\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &YouTubeService{apiKey: "test-key", client: server.Client(), logger: newTestLogger(t), baseURL: server.URL}, &fetches
+}
+
+func TestTranscriptsAreReusedAcrossWorkflows(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		stateless   bool
+		wantFetches int64
+	}{
+		{name: "cached", wantFetches: 1},
+		{name: "cache disabled", env: map[string]string{"YOUTUBE_TRANSCRIPT_CACHE_TTL": "0s"}, wantFetches: 2},
+		// without redis there is nowhere to keep the transcript
+		{name: "stateless", stateless: true, wantFetches: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, tt.env)
+			orchestrator := newTestOrchestrator(t, cfg)
+			_, orchestrator.redisService = newFakeRedis(t, cfg.Redis)
+			var fetches *atomic.Int64
+			orchestrator.youtubeService, fetches = newCaptionsYouTube(t, "the candidates debated the economy")
+
+			video := models.YouTubeVideo{ID: "video-1", Title: "Election debate explained"}
+			for i, workflowID := range []string{"workflow-first", "workflow-second"} {
+				executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{WorkflowID: workflowID, Query: "election debate"})
+				executor.workflowCtx.Stateless = tt.stateless
+
+				videos, err := executor.enhanceVideosWithTranscripts(context.Background(), []models.YouTubeVideo{video})
+				if err != nil {
+					t.Fatalf("enhanceVideosWithTranscripts() error = %v", err)
+				}
+				if videos[0].Transcript != "the candidates debated the economy" {
+					t.Errorf("workflow %d transcript = %q, want the caption text", i, videos[0].Transcript)
+				}
+			}
+
+			if got := fetches.Load(); got != tt.wantFetches {
+				t.Errorf("captions fetched %d times, want %d", got, tt.wantFetches)
+			}
+		})
+	}
+}
+
+func TestTranscriptCacheIsKeyedByLanguage(t *testing.T) {
+	_, redisService := newFakeRedis(t, loadTestConfig(t, nil).Redis)
+	ctx := context.Background()
+
+	if err := redisService.StoreTranscript(ctx, "video-1", "en", "english captions", time.Hour); err != nil {
+		t.Fatalf("StoreTranscript() error = %v", err)
+	}
+
+	if transcript, found, err := redisService.GetTranscript(ctx, "video-1", "en"); err != nil || !found || transcript != "english captions" {
+		t.Errorf("GetTranscript(en) = %q, %t, %v, want the stored captions", transcript, found, err)
+	}
+	if _, found, err := redisService.GetTranscript(ctx, "video-1", "fr"); err != nil || found {
+		t.Errorf("GetTranscript(fr) found = %t, %v, want a plain miss", found, err)
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// LanguageModeGenerate asks the summarizer and persona to write in the user's language directly
+	LanguageModeGenerate = "generate"
+	// LanguageModeTranslate writes the answer in the source language and translates the final response
+	LanguageModeTranslate = "translate"
+)
+
+// sourceLanguage is the language articles are fetched and summarized in when nothing else is asked for
+const sourceLanguage = "en"
+
+// languageNames spells out ISO 639-1 codes for prompts, unknown codes are passed through as is
+var languageNames = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"de": "German",
+	"pt": "Portuguese",
+	"it": "Italian",
+	"hi": "Hindi",
+	"bn": "Bengali",
+	"ta": "Tamil",
+	"te": "Telugu",
+	"mr": "Marathi",
+	"ur": "Urdu",
+	"ar": "Arabic",
+	"zh": "Chinese",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"ru": "Russian",
+}
+
+type responseLanguageKey struct{}
+
+// WithResponseLanguage attaches the language the summarizer and persona should write in
+func WithResponseLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, responseLanguageKey{}, strings.ToLower(strings.TrimSpace(language)))
+}
+
+func responseLanguageFromContext(ctx context.Context) string {
+	language, _ := ctx.Value(responseLanguageKey{}).(string)
+	return language
+}
+
+func languageName(language string) string {
+	if name, ok := languageNames[strings.ToLower(language)]; ok {
+		return name
+	}
+	return language
+}
+
+// needsTranslation reports whether an answer written in the source language has to be translated for the user
+func needsTranslation(language string) bool {
+	language = strings.ToLower(strings.TrimSpace(language))
+	return language != "" && language != sourceLanguage
+}
+
+// responseLanguageInstruction is the prompt section asking for the answer in the user's language
+func responseLanguageInstruction(language string) string {
+	if !needsTranslation(language) {
+		return ""
+	}
+	return fmt.Sprintf("\n**RESPONSE LANGUAGE**\n- Write the entire response in %s, even though the sources are in another language\n- Keep names of people, organisations and places, numbers, dates and URLs accurate\n", languageName(language))
+}
+
+// languageDirectives asks for the answer in each language in that language itself, a model told what to write in
+// the language it should write drifts back into english less often
+var languageDirectives = map[string]string{
+	"es": "Escribe toda la respuesta en español natural y fluido.",
+	"fr": "Rédige toute la réponse dans un français naturel et fluide.",
+	"de": "Schreibe die gesamte Antwort in natürlichem, flüssigem Deutsch.",
+	"pt": "Escreva toda a resposta em português natural e fluente.",
+	"it": "Scrivi l'intera risposta in un italiano naturale e scorrevole.",
+	"hi": "पूरा उत्तर स्वाभाविक और सहज हिंदी में लिखें।",
+	"bn": "পুরো উত্তরটি স্বাভাবিক ও সাবলীল বাংলায় লিখুন।",
+	"ta": "முழு பதிலையும் இயல்பான, சரளமான தமிழில் எழுதுங்கள்.",
+	"te": "మొత్తం సమాధానాన్ని సహజమైన, సరళమైన తెలుగులో రాయండి.",
+	"mr": "संपूर्ण उत्तर नैसर्गिक आणि ओघवत्या मराठीत लिहा.",
+	"ur": "پورا جواب فطری اور رواں اردو میں لکھیں۔",
+	"ar": "اكتب الإجابة كاملة بلغة عربية طبيعية وسلسة.",
+	"zh": "请用自然流畅的中文撰写完整的回答。",
+	"ja": "回答全体を自然で流暢な日本語で書いてください。",
+	"ko": "전체 답변을 자연스럽고 유창한 한국어로 작성하세요.",
+	"ru": "Напишите весь ответ на естественном, беглом русском языке.",
+}
+
+// personaLanguageInstruction is the persona prompts' language section. With native persona language on it opens
+// with the directive in the target language and asks for the persona's voice as a native speaker would use it,
+// otherwise it is the plain response language section.
+func (service *GeminiService) personaLanguageInstruction(language string) string {
+	if !needsTranslation(language) {
+		return ""
+	}
+	if !service.config.NativePersonaLanguage {
+		return responseLanguageInstruction(language)
+	}
+
+	name := languageName(language)
+	directive := languageDirectives[strings.ToLower(language)]
+	if directive == "" {
+		directive = fmt.Sprintf("Write the entire response in %s.", name)
+	}
+
+	return fmt.Sprintf(`**RESPONSE LANGUAGE: %s**
+%s
+- Keep this persona's tone, structure and characteristics, voiced the way a native %s speaker in the same role would, with natural idioms, register and greetings rather than translated English phrasing
+- Every heading, label and sign-off is in %s too, do not mix in English apart from names, quoted terms and URLs
+- Keep names of people, organisations and places, numbers, dates and URLs accurate`, name, directive, name, name)
+}
+
+// TranslateResponse translates the final answer into language, keeping its markdown, names and figures intact
+func (service *GeminiService) TranslateResponse(ctx context.Context, text string, language string) (string, error) {
+	start := time.Now()
+
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+
+	prompt := service.prompts.Render("translation", map[string]any{
+		"Language": languageName(language),
+		"Text":     text,
+	})
+
+	req := &GenerationRequest{
+		Prompt:          prompt,
+		Temperature:     &[]float32{0.2}[0],
+		SystemRole:      "You are an expert news translator. Return only the translated text.",
+		MaxTokens:       8192,
+		DisableThinking: true,
+	}
+	service.applyAgentSampling("translation", req)
+
+	resp, err := service.GenerateContent(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("Translation failed: %w", err)
+	}
+
+	translated := strings.TrimSpace(resp.Content)
+	if translated == "" {
+		return "", fmt.Errorf("Translation returned empty content")
+	}
+
+	service.logger.LogAgent("", "translator", "translate_response", time.Since(start), map[string]interface{}{
+		"language":    language,
+		"input_chars": len(text),
+		"tokens_used": resp.TokensUsed,
+	}, nil)
+
+	return translated, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"testing"
+)
+
+const hindiAnswer = "चुनाव के बारे में ताज़ा जानकारी।"
+
+func TestTranslateModeTranslatesTheFinalResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		language      string
+		translateFail bool
+		wantTranslate bool
+		wantInPrompt  bool
+	}{
+		{name: "translate mode into hindi", mode: LanguageModeTranslate, language: "hi", wantTranslate: true},
+		{name: "translate mode in the source language", mode: LanguageModeTranslate, language: "en"},
+		{name: "generate mode writes in hindi directly", mode: LanguageModeGenerate, language: "hi", wantInPrompt: true},
+		{name: "a failed translation keeps the answer", mode: LanguageModeTranslate, language: "hi", translateFail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{"LANGUAGE_MODE": tt.mode, "GEMINI_MAX_RETRIES": "1"})
+			workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+			workflow.answerAgent("news translator", func(fakeGeminiCall) string { return hindiAnswer })
+			if tt.translateFail {
+				workflow.failAgent("news translator")
+			}
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-translate", Query: "who is ahead in the elections",
+				UserPreferences: models.UserPreferences{Language: tt.language},
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var translations []fakeGeminiCall
+			var summaryInHindi bool
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "news translator") {
+					translations = append(translations, call)
+				}
+				if strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") {
+					summaryInHindi = strings.Contains(call.Prompt, "Write the entire response in Hindi")
+				}
+			}
+
+			if summaryInHindi != tt.wantInPrompt {
+				t.Errorf("summary prompt asks for hindi = %t, want %t", summaryInHindi, tt.wantInPrompt)
+			}
+			switch {
+			case tt.wantTranslate:
+				if len(translations) != 1 {
+					t.Fatalf("%d translation calls, want 1", len(translations))
+				}
+				if !strings.Contains(translations[0].Prompt, "into Hindi") || !strings.Contains(translations[0].Prompt, "Here is what is happening with elections.") {
+					t.Errorf("translation prompt = %q, want the persona's answer to translate into Hindi", translations[0].Prompt)
+				}
+				if response.Message != hindiAnswer {
+					t.Errorf("message = %q, want the translation", response.Message)
+				}
+			case tt.translateFail:
+				if response.Message != "Here is what is happening with elections." {
+					t.Errorf("message = %q, want the untranslated answer", response.Message)
+				}
+			default:
+				if len(translations) != 0 {
+					t.Errorf("%d translation calls, want none", len(translations))
+				}
+			}
+		})
+	}
+}
+
+func TestTranslateModeTranslatesTheNeutralSummary(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"LANGUAGE_MODE": LanguageModeTranslate})
+	workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+	workflow.answerAgent("news translator", func(fakeGeminiCall) string { return hindiAnswer })
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-translate-no-persona", Query: "who is ahead in the elections",
+		UserPreferences: models.UserPreferences{Language: "hi", NewsPersonality: models.NoPersona},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	var translations []fakeGeminiCall
+	for _, call := range workflow.gemini.received() {
+		if strings.Contains(call.SystemPrompt, "Content Personalizer") {
+			t.Error("persona ran, want it skipped")
+		}
+		if strings.Contains(call.SystemPrompt, "news translator") {
+			translations = append(translations, call)
+		}
+	}
+	if len(translations) != 1 {
+		t.Fatalf("%d translation calls, want 1", len(translations))
+	}
+	if !strings.Contains(translations[0].Prompt, "The elections story in brief") {
+		t.Errorf("translation prompt = %q, want the neutral summary", translations[0].Prompt)
+	}
+	if response.Message != hindiAnswer {
+		t.Errorf("message = %q, want the translation", response.Message)
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// newsAPICategories are the favourite topics top headlines are filtered by category, any other topic is searched for
+var newsAPICategories = map[string]bool{
+	"business": true, "entertainment": true, "general": true, "health": true, "science": true, "sports": true, "technology": true,
+}
+
+// alignedFavouriteTopic returns the first favourite topic the chitchat touches on, named in the query or among the
+// topics the conversation is on. Small talk mentioning none of them gets no suggestions.
+func alignedFavouriteTopic(query string, currentTopics, favourites []string) string {
+	words := topicWords(query)
+	for _, topic := range currentTopics {
+		words = append(words, topicWords(topic)...)
+	}
+	text := " " + strings.Join(words, " ") + " "
+
+	for _, favourite := range favourites {
+		topic := strings.Join(topicWords(favourite), " ")
+		if topic == "" {
+			continue
+		}
+		// "sport" and "sports" both align with a favourite of either form, short words like "news" keep their s
+		singular := topic
+		if len(topic) > 4 {
+			singular = strings.TrimSuffix(topic, "s")
+		}
+		if strings.Contains(text, " "+topic+" ") || strings.Contains(text, " "+singular+" ") || strings.Contains(text, " "+topic+"s ") {
+			return strings.ToLower(strings.TrimSpace(favourite))
+		}
+	}
+	return ""
+}
+
+func topicWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// attachTrendingSuggestions ends a chitchat answer that touches on a favourite topic with a few of that topic's top
+// headlines. A user is offered them at most once per interval and any failure leaves the answer as it is.
+func (workflowExecutor *WorkflowExecutor) attachTrendingSuggestions(ctx context.Context) {
+	workflowConfig := workflowExecutor.orchestrator.config.Workflow
+	if !workflowConfig.TrendingSuggestions {
+		return
+	}
+
+	conversationContext := workflowExecutor.workflowCtx.ConversationContext
+	topic := alignedFavouriteTopic(workflowExecutor.workflowCtx.OriginalQuery, conversationContext.CurrentTopics,
+		conversationContext.UserPreferences.FavouriteTopics)
+	if topic == "" {
+		return
+	}
+
+	claimed, err := workflowExecutor.orchestrator.redisService.ClaimTrendingSuggestion(ctx, workflowExecutor.workflowCtx.UserID,
+		workflowConfig.TrendingSuggestionInterval)
+	if err != nil {
+		workflowExecutor.logger.WithError(err).Warn("Failed to claim trending suggestion", "workflow_id", workflowExecutor.workflowCtx.ID)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	headlines, err := workflowExecutor.orchestrator.trendingHeadlines(ctx, topic, workflowConfig.TrendingSuggestionCount)
+	if err != nil {
+		workflowExecutor.logger.WithError(err).Warn("Failed to fetch trending headlines", "topic", topic)
+		return
+	}
+	if len(headlines) == 0 {
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString(strings.TrimRight(workflowExecutor.workflowCtx.Response, "\n"))
+	fmt.Fprintf(&builder, "\n\nTrending in %s right now, ask me about any of them:", topic)
+	for _, headline := range headlines {
+		builder.WriteString("\n- " + headline)
+	}
+
+	workflowExecutor.workflowCtx.Response = builder.String()
+	workflowExecutor.workflowCtx.Metadata["trending_topic"] = topic
+	workflowExecutor.workflowCtx.Metadata["trending_suggestions"] = headlines
+	workflowExecutor.logger.Info("Attached trending suggestions", "workflow_id", workflowExecutor.workflowCtx.ID,
+		"topic", topic, "count", len(headlines))
+}
+
+// trendingHeadlines returns up to count top headlines of a topic, cached so chitchat costs at most one news call per
+// topic and cache period
+func (orchestrator *Orchestrator) trendingHeadlines(ctx context.Context, topic string, count int) ([]string, error) {
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "trending_headlines", topic, func(ctx context.Context) ([]string, error) {
+			return orchestrator.trendingHeadlines(ctx, topic, count)
+		})
+	}
+	ttl := orchestrator.config.Workflow.TrendingCacheTTL
+	if cached, ok, err := orchestrator.redisService.GetTrendingHeadlines(ctx, topic); err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to read cached trending headlines", "topic", topic)
+	} else if ok {
+		return cached[:min(count, len(cached))], nil
+	}
+
+	request := &HeadlinesRequest{Query: topic, PageSize: count * 2}
+	if newsAPICategories[topic] {
+		request = &HeadlinesRequest{Category: topic, PageSize: count * 2}
+	}
+
+	articles, err := orchestrator.newsService.GetTopHeadlines(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	headlines := make([]string, 0, count)
+	seen := make(map[string]bool)
+	for _, article := range articles {
+		title := strings.TrimSpace(article.Title)
+		if title == "" || title == "[Removed]" || seen[strings.ToLower(title)] {
+			continue
+		}
+		seen[strings.ToLower(title)] = true
+		headlines = append(headlines, title)
+		if len(headlines) == count {
+			break
+		}
+	}
+
+	if err := orchestrator.redisService.StoreTrendingHeadlines(ctx, topic, headlines, ttl); err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to cache trending headlines", "topic", topic)
+	}
+	return headlines, nil
+}
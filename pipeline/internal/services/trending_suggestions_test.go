@@ -0,0 +1,112 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"Infiya-ai-pipeline/internal/pkg/redistest"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlignedFavouriteTopic(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		query         string
+		currentTopics []string
+		favourites    []string
+		want          string
+	}{
+		{name: "named in the query", query: "I watched sports all weekend", favourites: []string{"Technology", "Sports"}, want: "sports"},
+		{name: "singular form", query: "is sport still fun to watch?", favourites: []string{"sports"}, want: "sports"},
+		{name: "plural form", query: "I keep reading about elections", favourites: []string{"election"}, want: "election"},
+		{name: "conversation topic", query: "thanks, that was helpful!", currentTopics: []string{"artificial intelligence"},
+			favourites: []string{"Artificial Intelligence"}, want: "artificial intelligence"},
+		{name: "small talk", query: "hello, how are you today?", favourites: []string{"sports", "technology"}},
+		{name: "part of another word", query: "my passport expired", favourites: []string{"sport"}},
+		{name: "no favourites", query: "I watched sports all weekend"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alignedFavouriteTopic(tt.query, tt.currentTopics, tt.favourites); got != tt.want {
+				t.Errorf("alignedFavouriteTopic(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// newTrendingWorkflow answers chitchat about sports with redis served by a fake, so suggestions can be rate limited
+func newTrendingWorkflow(t *testing.T, env map[string]string) (*redistest.Server, func(userID, query string) *models.WorkflowResponse) {
+	t.Helper()
+	workflow := newTestWorkflow(t, loadTestConfig(t, env), "sports", models.IntentChitChat)
+	var redis *redistest.Server
+	redis, workflow.orchestrator.redisService = newFakeRedis(t, config.RedisConfig{DialTimeout: time.Second, MaxContextBytes: 1 << 20})
+
+	chat := func(userID, query string) *models.WorkflowResponse {
+		t.Helper()
+		response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+			UserID: userID, Query: query,
+			UserPreferences: models.UserPreferences{FavouriteTopics: []string{"sports"}},
+		})
+		if err != nil {
+			t.Fatalf("ExecuteWorkflow() error = %v", err)
+		}
+		return response
+	}
+	return redis, chat
+}
+
+func TestChitchatSuggestsTrendingHeadlinesOfAFavouriteTopic(t *testing.T) {
+	redis, chat := newTrendingWorkflow(t, map[string]string{"TRENDING_SUGGESTIONS": "true"})
+
+	response := chat("user-1", "I watched sports all weekend")
+
+	want := []string{"sports story 0", "sports story 1", "sports story 2"}
+	if !slices.Equal(response.TrendingSuggestions, want) {
+		t.Fatalf("trending suggestions = %v, want %v", response.TrendingSuggestions, want)
+	}
+	if !strings.HasPrefix(response.Message, "A short answer about sports.") ||
+		!strings.HasSuffix(response.Message, "Trending in sports right now, ask me about any of them:\n- sports story 0\n- sports story 1\n- sports story 2") {
+		t.Errorf("message = %q, want the answer followed by the headlines", response.Message)
+	}
+
+	if ttl := redis.TTL("trending:sports:headlines"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("cached headlines TTL = %s, want them kept for the cache period", ttl)
+	}
+
+	// the same user is not offered suggestions again within the interval, another user is
+	if again := chat("user-1", "the sports results were great"); again.TrendingSuggestions != nil || strings.Contains(again.Message, "Trending in") {
+		t.Errorf("second chat suggested %v, want nothing within the interval", again.TrendingSuggestions)
+	}
+	if other := chat("user-2", "what a day for sports"); !slices.Equal(other.TrendingSuggestions, want) {
+		t.Errorf("another user got %v, want %v", other.TrendingSuggestions, want)
+	}
+}
+
+func TestChitchatWithoutSuggestions(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		query string
+	}{
+		{name: "disabled", query: "I watched sports all weekend"},
+		{name: "small talk", env: map[string]string{"TRENDING_SUGGESTIONS": "true"}, query: "hello, how are you today?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redis, chat := newTrendingWorkflow(t, tt.env)
+
+			response := chat("user-1", tt.query)
+
+			if response.TrendingSuggestions != nil || response.Message != "A short answer about sports." {
+				t.Errorf("response = %q with suggestions %v, want the plain answer", response.Message, response.TrendingSuggestions)
+			}
+			if keys := append(redis.Keys("trending:"), redis.Keys("user:user-1:trending")...); len(keys) != 0 {
+				t.Errorf("redis holds %v, want no suggestion claimed or headlines fetched", keys)
+			}
+		})
+	}
+}
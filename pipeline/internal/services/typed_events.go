@@ -0,0 +1,106 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"time"
+)
+
+// summary chunks are cut at paragraph breaks once they reach this many characters
+const summaryChunkChars = 600
+
+// typedEventsEnabled reports whether the workflow emits typed events, a request's "typed_events" turns them on
+func (orchestrator *Orchestrator) typedEventsEnabled(workflowCtx *models.WorkflowContext) bool {
+	return orchestrator.config.Workflow.TypedEvents || workflowCtx.RequestBool("typed_events")
+}
+
+// publishEvent delivers a typed event the same way as agent updates, so websocket relays and stateless buffers
+// carry it too. Events are opt in and ignore the update verbosity.
+func (orchestrator *Orchestrator) publishEvent(ctx context.Context, workflowCtx *models.WorkflowContext, event models.EventType, payload any) {
+	if !orchestrator.typedEventsEnabled(workflowCtx) {
+		return
+	}
+
+	update := &models.AgentUpdate{
+		WorkflowID: workflowCtx.ID,
+		RequestID:  workflowCtx.RequestID,
+		AgentName:  string(event),
+		Event:      event,
+		Status:     models.AgentStatusCompleted,
+		Data:       map[string]interface{}{"payload": payload},
+		Timestamp:  time.Now(),
+	}
+
+	if err := orchestrator.deliverUpdate(ctx, workflowCtx, update); err != nil {
+		orchestrator.logger.WithError(err).Error("Failed to publish workflow event", "workflow_id", workflowCtx.ID, "event", event)
+	}
+	orchestrator.notifyEventListener(ctx, workflowCtx.ID, update)
+}
+
+// publishArticlesFound lists the relevant sources as soon as the search settles, before scraping and summarizing
+func (workflowExecutor *WorkflowExecutor) publishArticlesFound(ctx context.Context) {
+	workflowCtx := workflowExecutor.workflowCtx
+	if !workflowExecutor.orchestrator.typedEventsEnabled(workflowCtx) {
+		return
+	}
+	workflowExecutor.orchestrator.publishEvent(ctx, workflowCtx, models.EventArticlesFound, models.ArticlesFoundPayload{
+		Articles: len(workflowCtx.Articles),
+		Videos:   len(workflowCtx.Videos),
+		Sources:  buildResponseSources(workflowCtx.Articles, workflowCtx.Videos, time.Now(), workflowExecutor.orchestrator.config.Workflow.SourceSort),
+	})
+}
+
+// publishCompletionEvents streams the finished response as summary chunks, then its sources, then the complete event
+func (orchestrator *Orchestrator) publishCompletionEvents(ctx context.Context, workflowCtx *models.WorkflowContext, duration time.Duration) {
+	if !orchestrator.typedEventsEnabled(workflowCtx) {
+		return
+	}
+
+	chunks := summaryChunks(workflowCtx.Response, summaryChunkChars)
+	for i, chunk := range chunks {
+		orchestrator.publishEvent(ctx, workflowCtx, models.EventSummaryChunk, models.SummaryChunkPayload{
+			Index: i,
+			Text:  chunk,
+			Last:  i == len(chunks)-1,
+		})
+	}
+
+	if len(workflowCtx.Articles) > 0 || len(workflowCtx.Videos) > 0 {
+		orchestrator.publishEvent(ctx, workflowCtx, models.EventSources, models.SourcesPayload{
+			Sources: buildResponseSources(workflowCtx.Articles, workflowCtx.Videos, time.Now(), orchestrator.config.Workflow.SourceSort),
+		})
+	}
+
+	orchestrator.publishEvent(ctx, workflowCtx, models.EventComplete, models.CompletePayload{
+		Status:           string(workflowCtx.Status),
+		Intent:           workflowCtx.Intent,
+		ProcessingTimeMs: duration.Milliseconds(),
+		SummaryChunks:    len(chunks),
+	})
+}
+
+// summaryChunks splits the response at paragraph breaks into chunks of about maxChars, a paragraph longer than
+// that stays whole so markdown is never cut mid block
+func summaryChunks(text string, maxChars int) []string {
+	var chunks []string
+	var current strings.Builder
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+		if current.Len() > 0 && current.Len()+len(paragraph) > maxChars {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(paragraph)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
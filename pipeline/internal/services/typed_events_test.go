@@ -0,0 +1,137 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummaryChunks(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("x", 30)
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: ""},
+		{name: "short paragraphs share a chunk", text: "one\n\ntwo\n\nthree", want: []string{"one\n\ntwo\n\nthree"}},
+		{name: "split at paragraph breaks", text: "first paragraph\n\nsecond paragraph\n\nthird", want: []string{"first paragraph", "second paragraph\n\nthird"}},
+		{name: "an overlong paragraph stays whole", text: long + "\n\nend", want: []string{long, "end"}},
+		{name: "blank paragraphs dropped", text: "one\n\n\n\n  \n\ntwo", want: []string{"one\n\ntwo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summaryChunks(tt.text, 25); !slices.Equal(got, tt.want) {
+				t.Errorf("summaryChunks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewsWorkflowEmitsTypedEventsInOrder(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-events", Query: "what is the latest on elections",
+		Metadata: map[string]any{"typed_events": true},
+	})
+	if err != nil || response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("ExecuteWorkflow() = %v, %v", response, err)
+	}
+
+	var sequence []models.EventType
+	payloads := make(map[models.EventType][]any)
+	for _, update := range response.Updates {
+		if update.Event == "" {
+			continue
+		}
+		if update.AgentName != string(update.Event) {
+			t.Errorf("event %s sent as agent %s", update.Event, update.AgentName)
+		}
+		if len(sequence) == 0 || sequence[len(sequence)-1] != update.Event {
+			sequence = append(sequence, update.Event)
+		}
+		payloads[update.Event] = append(payloads[update.Event], update.Data["payload"])
+	}
+
+	want := []models.EventType{models.EventIntentClassified, models.EventKeywordsExtracted, models.EventArticlesFound,
+		models.EventSummaryChunk, models.EventSources, models.EventComplete}
+	if !slices.Equal(sequence, want) {
+		t.Fatalf("event sequence = %v, want %v", sequence, want)
+	}
+
+	if intent, ok := payloads[models.EventIntentClassified][0].(models.IntentClassifiedPayload); !ok || intent.Intent != string(models.IntentNewNewsQuery) || intent.Confidence != 0.95 {
+		t.Errorf("intent_classified payload = %#v", payloads[models.EventIntentClassified][0])
+	}
+	if keywords, ok := payloads[models.EventKeywordsExtracted][0].(models.KeywordsExtractedPayload); !ok || !slices.Contains(keywords.Keywords, "elections") {
+		t.Errorf("keywords_extracted payload = %#v", payloads[models.EventKeywordsExtracted][0])
+	}
+	found, ok := payloads[models.EventArticlesFound][0].(models.ArticlesFoundPayload)
+	if !ok || found.Articles == 0 || found.Articles+found.Videos != len(found.Sources) {
+		t.Errorf("articles_found payload = %#v, want the counts to match its sources", payloads[models.EventArticlesFound][0])
+	}
+
+	var summary []string
+	for i, payload := range payloads[models.EventSummaryChunk] {
+		chunk, ok := payload.(models.SummaryChunkPayload)
+		if !ok || chunk.Index != i || chunk.Last != (i == len(payloads[models.EventSummaryChunk])-1) {
+			t.Errorf("summary_chunk %d payload = %#v", i, payload)
+		}
+		summary = append(summary, chunk.Text)
+	}
+	if strings.Join(summary, "\n\n") != response.Message {
+		t.Errorf("summary chunks %q do not add up to the response %q", summary, response.Message)
+	}
+
+	if sources, ok := payloads[models.EventSources][0].(models.SourcesPayload); !ok || len(sources.Sources) != len(response.Sources) {
+		t.Errorf("sources payload = %#v, want the response's %d sources", payloads[models.EventSources][0], len(response.Sources))
+	}
+	complete, ok := payloads[models.EventComplete][0].(models.CompletePayload)
+	if !ok || complete.Status != string(models.WorkflowStatusCompleted) || complete.SummaryChunks != len(summary) {
+		t.Errorf("complete payload = %#v", payloads[models.EventComplete][0])
+	}
+}
+
+func TestTypedEventsAreOffByDefault(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-no-events")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	for _, update := range response.Updates {
+		if update.Event != "" {
+			t.Errorf("got event %s without opting in", update.Event)
+		}
+	}
+}
+
+func TestTypedEventsSurviveTheRedisStream(t *testing.T) {
+	_, redisService := newFakeRedis(t, loadTestConfig(t, nil).Redis)
+	ctx := context.Background()
+
+	err := redisService.PublishAgentUpdate(ctx, "user-1", &models.AgentUpdate{
+		WorkflowID: "workflow-1", AgentName: string(models.EventKeywordsExtracted), Event: models.EventKeywordsExtracted,
+		Status: models.AgentStatusCompleted, Timestamp: time.Now(),
+		Data: map[string]interface{}{"payload": models.KeywordsExtractedPayload{Keywords: []string{"elections"}}},
+	})
+	if err != nil {
+		t.Fatalf("PublishAgentUpdate() error = %v", err)
+	}
+
+	updates, _, err := redisService.ReadAgentUpdates(ctx, "user-1", "0", 0)
+	if err != nil || len(updates) != 1 {
+		t.Fatalf("ReadAgentUpdates() = %d updates, %v", len(updates), err)
+	}
+	if updates[0].Event != models.EventKeywordsExtracted {
+		t.Errorf("event = %q, want it read back from the stream", updates[0].Event)
+	}
+	payload, _ := updates[0].Data["payload"].(map[string]interface{})
+	if keywords, _ := payload["keywords"].([]interface{}); len(keywords) != 1 || keywords[0] != "elections" {
+		t.Errorf("payload = %#v, want the keywords as JSON", updates[0].Data["payload"])
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"strings"
+)
+
+const (
+	UncertaintyLimitedCoverage = "limited_coverage"
+	UncertaintyLowRelevance    = "low_relevance"
+	UncertaintySourcesDisagree = "sources_disagree"
+)
+
+var uncertaintyPhrases = map[string]string{
+	UncertaintyLimitedCoverage: "coverage of this is limited",
+	UncertaintyLowRelevance:    "the sources found only partly match the question",
+	UncertaintySourcesDisagree: "the sources disagree in how they report it",
+}
+
+// assessUncertainty lists why an answer should not be read as settled fact, drawing on the relevant article count,
+// their average relevance and, when sentiment was rated, how evenly the articles split between positive and negative
+func assessUncertainty(workflowCtx *models.WorkflowContext, qualityConfig config.QualityConfig) []string {
+	var reasons []string
+	articles := workflowCtx.Articles
+
+	if len(articles) < qualityConfig.UncertaintyMinArticles {
+		reasons = append(reasons, UncertaintyLimitedCoverage)
+	}
+
+	if len(articles) > 0 {
+		total := 0.0
+		for _, article := range articles {
+			total += article.RelevanceScore
+		}
+		if total/float64(len(articles)) < qualityConfig.UncertaintyMinRelevance {
+			reasons = append(reasons, UncertaintyLowRelevance)
+		}
+	}
+
+	positive, negative := 0, 0
+	for _, article := range articles {
+		if article.Sentiment == nil {
+			continue
+		}
+		switch article.Sentiment.Label {
+		case models.SentimentPositive:
+			positive++
+		case models.SentimentNegative:
+			negative++
+		}
+	}
+	if polar := positive + negative; polar >= 2 && float64(min(positive, negative))/float64(polar) >= qualityConfig.UncertaintyConflictShare {
+		reasons = append(reasons, UncertaintySourcesDisagree)
+	}
+
+	return reasons
+}
+
+// uncertaintyNote phrases the reasons as one closing sentence
+func uncertaintyNote(reasons []string) string {
+	if len(reasons) == 0 {
+		return ""
+	}
+
+	phrases := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		phrases = append(phrases, uncertaintyPhrases[reason])
+	}
+
+	note := strings.Join(phrases, " and ")
+	return "Note: " + strings.ToUpper(note[:1]) + note[1:] + ", so treat this summary with some caution."
+}
+
+// annotateUncertainty appends the caution note to the response when the signals call for one. It runs after the
+// persona so the note cannot be rewritten away, and before translation so it reaches the user in their language.
+func (workflowExecutor *WorkflowExecutor) annotateUncertainty() {
+	qualityConfig := workflowExecutor.orchestrator.config.Quality
+	if !qualityConfig.UncertaintyNote {
+		return
+	}
+	if emptyResult, _ := workflowExecutor.workflowCtx.Metadata["empty_result"].(bool); emptyResult {
+		return
+	}
+
+	reasons := assessUncertainty(workflowExecutor.workflowCtx, qualityConfig)
+	if len(reasons) == 0 {
+		return
+	}
+
+	workflowExecutor.workflowCtx.Response = strings.TrimRight(workflowExecutor.workflowCtx.Response, "\n") + "\n\n" + uncertaintyNote(reasons)
+	workflowExecutor.workflowCtx.Metadata["uncertainty"] = reasons
+	workflowExecutor.logger.Info("Uncertainty note added",
+		"workflow_id", workflowExecutor.workflowCtx.ID,
+		"reasons", reasons,
+		"articles", len(workflowExecutor.workflowCtx.Articles))
+}
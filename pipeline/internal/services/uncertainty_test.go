@@ -0,0 +1,114 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAssessUncertainty(t *testing.T) {
+	t.Parallel()
+	qualityConfig := config.QualityConfig{UncertaintyMinArticles: 3, UncertaintyMinRelevance: 0.5, UncertaintyConflictShare: 0.3}
+	article := func(score float64, sentiment string) models.NewsArticle {
+		article := models.NewsArticle{RelevanceScore: score}
+		if sentiment != "" {
+			article.Sentiment = &models.ArticleSentiment{Label: sentiment}
+		}
+		return article
+	}
+
+	tests := []struct {
+		name     string
+		articles []models.NewsArticle
+		want     []string
+	}{
+		{name: "well covered", articles: []models.NewsArticle{article(0.9, ""), article(0.8, ""), article(0.7, "")}},
+		{name: "no articles", want: []string{UncertaintyLimitedCoverage}},
+		{name: "too few articles", articles: []models.NewsArticle{article(0.9, ""), article(0.9, "")}, want: []string{UncertaintyLimitedCoverage}},
+		{name: "modest relevance", articles: []models.NewsArticle{article(0.6, ""), article(0.4, ""), article(0.3, "")}, want: []string{UncertaintyLowRelevance}},
+		{name: "sources disagree", articles: []models.NewsArticle{
+			article(0.9, models.SentimentPositive), article(0.9, models.SentimentPositive), article(0.9, models.SentimentNegative),
+		}, want: []string{UncertaintySourcesDisagree}},
+		{name: "one dissenting tone among many", articles: []models.NewsArticle{
+			article(0.9, models.SentimentPositive), article(0.9, models.SentimentPositive), article(0.9, models.SentimentPositive),
+			article(0.9, models.SentimentPositive), article(0.9, models.SentimentNegative),
+		}},
+		{name: "neutral articles do not conflict", articles: []models.NewsArticle{
+			article(0.9, models.SentimentPositive), article(0.9, models.SentimentNeutral), article(0.9, models.SentimentNeutral),
+		}},
+		{name: "every signal", articles: []models.NewsArticle{article(0.3, models.SentimentPositive), article(0.3, models.SentimentNegative)},
+			want: []string{UncertaintyLimitedCoverage, UncertaintyLowRelevance, UncertaintySourcesDisagree}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assessUncertainty(&models.WorkflowContext{Articles: tt.articles}, qualityConfig)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("assessUncertainty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUncertaintyNote(t *testing.T) {
+	t.Parallel()
+	if note := uncertaintyNote(nil); note != "" {
+		t.Errorf("uncertaintyNote(nil) = %q, want no note", note)
+	}
+	want := "Note: Coverage of this is limited and the sources disagree in how they report it, so treat this summary with some caution."
+	if note := uncertaintyNote([]string{UncertaintyLimitedCoverage, UncertaintySourcesDisagree}); note != want {
+		t.Errorf("uncertaintyNote() = %q, want %q", note, want)
+	}
+}
+
+func TestUncertaintyNoteFollowsCoverage(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		relevantIDs int
+		wantNote    bool
+	}{
+		{name: "low coverage", env: map[string]string{"QUALITY_UNCERTAINTY_NOTE": "true"}, relevantIDs: 1, wantNote: true},
+		{name: "high coverage", env: map[string]string{"QUALITY_UNCERTAINTY_NOTE": "true"}, relevantIDs: 5},
+		{name: "low coverage with the note off", relevantIDs: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, tt.env), "elections", models.IntentNewNewsQuery)
+			workflow.answerAgent("news relevancy", func(call fakeGeminiCall) string {
+				var items []string
+				for _, match := range promptArticleID.FindAllStringSubmatch(call.Prompt, -1) {
+					item := fmt.Sprintf(`{"id": %s, "relevance_score": 0.9}`, match[1])
+					if len(items) < tt.relevantIDs && !slices.Contains(items, item) {
+						items = append(items, item)
+					}
+				}
+				return fmt.Sprintf(`{"relevant_articles": [%s]}`, strings.Join(items, ", "))
+			})
+
+			response, err := workflow.run("workflow-uncertainty")
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			hasNote := strings.Contains(response.Message, "Coverage of this is limited")
+			if hasNote != tt.wantNote {
+				t.Errorf("response %q carries a note = %t, want %t", response.Message, hasNote, tt.wantNote)
+			}
+			if tt.wantNote {
+				if !strings.HasPrefix(response.Message, "Here is what is happening with elections.") {
+					t.Errorf("response = %q, want the note after the persona's answer", response.Message)
+				}
+				if !slices.Equal(response.Uncertainty, []string{UncertaintyLimitedCoverage}) {
+					t.Errorf("uncertainty = %v, want [%s]", response.Uncertainty, UncertaintyLimitedCoverage)
+				}
+			} else if response.Uncertainty != nil {
+				t.Errorf("uncertainty = %v, want none", response.Uncertainty)
+			}
+		})
+	}
+}
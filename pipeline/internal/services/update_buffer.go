@@ -0,0 +1,47 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"sync"
+)
+
+const statelessUpdateBufferSize = 64
+
+// updateRing keeps the most recent updates of a stateless workflow so the sync response can return them
+type updateRing struct {
+	mu       sync.Mutex
+	updates  []*models.AgentUpdate
+	capacity int
+	next     int
+}
+
+func newUpdateRing(capacity int) *updateRing {
+	return &updateRing{
+		updates:  make([]*models.AgentUpdate, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+func (ring *updateRing) add(update *models.AgentUpdate) {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	if len(ring.updates) < ring.capacity {
+		ring.updates = append(ring.updates, update)
+		return
+	}
+
+	ring.updates[ring.next] = update
+	ring.next = (ring.next + 1) % ring.capacity
+}
+
+// snapshot returns the buffered updates oldest first
+func (ring *updateRing) snapshot() []*models.AgentUpdate {
+	ring.mu.Lock()
+	defer ring.mu.Unlock()
+
+	result := make([]*models.AgentUpdate, 0, len(ring.updates))
+	result = append(result, ring.updates[ring.next:]...)
+	result = append(result, ring.updates[:ring.next]...)
+	return result
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"testing"
+)
+
+func TestUpdateRingKeepsTheMostRecentUpdatesInOrder(t *testing.T) {
+	ring := newUpdateRing(3)
+	for i := 0; i < 5; i++ {
+		ring.add(&models.AgentUpdate{Message: fmt.Sprintf("update %d", i)})
+	}
+
+	snapshot := ring.snapshot()
+	if len(snapshot) != 3 {
+		t.Fatalf("snapshot holds %d updates, want 3", len(snapshot))
+	}
+	for i, update := range snapshot {
+		if want := fmt.Sprintf("update %d", i+2); update.Message != want {
+			t.Errorf("snapshot[%d] = %q, want %q", i, update.Message, want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"time"
+)
+
+// how long a single stream read blocks before the relay re-checks whether the workflow already finished
+const relayReadBlock = 5 * time.Second
+
+// IsTerminalUpdate reports whether an update closes out its workflow
+func IsTerminalUpdate(update *models.AgentUpdate) bool {
+	switch models.UpdateType(update.AgentName) {
+	case models.UpdateTypeWorkflowCompleted, models.UpdateTypeWorkflowError, models.UpdateTypeWorkflowTimeout:
+		return true
+	}
+	return false
+}
+
+func isTerminalStatus(status models.WorkflowStatus) bool {
+	switch status {
+	case models.WorkflowStatusCompleted, models.WorkflowStatusFailed, models.WorkflowStatusCancelled, models.WorkflowStatusTimeout:
+		return true
+	}
+	return false
+}
+
+// RelayWorkflowUpdates replays and then follows one workflow's updates from the user's stream, handing each to
+// deliver in order. It returns after the terminal update, when deliver fails, or when ctx ends. Transports such
+// as websockets share this so they all see the same sequence.
+func (orchestrator *Orchestrator) RelayWorkflowUpdates(ctx context.Context, userID, workflowID string, deliver func(update *models.AgentUpdate) error) error {
+	lastID := "0"
+
+	for {
+		updates, nextID, err := orchestrator.redisService.ReadAgentUpdates(ctx, userID, lastID, relayReadBlock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		lastID = nextID
+
+		for _, update := range updates {
+			if update.WorkflowID != workflowID {
+				continue
+			}
+			if err := deliver(update); err != nil {
+				return err
+			}
+			if IsTerminalUpdate(update) {
+				return nil
+			}
+		}
+
+		// the terminal update may have been trimmed from the stream, fall back to the stored state
+		if len(updates) == 0 {
+			if workflowCtx, err := orchestrator.GetWorkflowStatus(workflowID); err == nil && isTerminalStatus(workflowCtx.Status) {
+				return nil
+			}
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import "Infiya-ai-pipeline/internal/models"
+
+// how chatty the update stream is, workflow level updates (started, completed, error, timeout) are always sent
+const (
+	// every agent's processing and completed updates
+	UpdateVerbosityVerbose = "verbose"
+	// agent updates except the processing ones
+	UpdateVerbosityQuiet = "quiet"
+	// no agent updates at all
+	UpdateVerbosityMilestones = "milestones"
+)
+
+// IsValidUpdateVerbosity reports whether verbosity is one of the supported update verbosities
+func IsValidUpdateVerbosity(verbosity string) bool {
+	return verbosity == UpdateVerbosityVerbose || verbosity == UpdateVerbosityQuiet || verbosity == UpdateVerbosityMilestones
+}
+
+// publishesAgentStatus reports whether an agent update with the status goes out at the verbosity
+func publishesAgentStatus(verbosity string, status models.AgentStatus) bool {
+	switch verbosity {
+	case UpdateVerbosityMilestones:
+		return false
+	case UpdateVerbosityQuiet:
+		return status != models.AgentStatusProcessing
+	default:
+		return true
+	}
+}
+
+// updateVerbosity is the configured verbosity unless the request's "update_verbosity" overrides it
+func (workflowExecutor *WorkflowExecutor) updateVerbosity() string {
+	if override, ok := workflowExecutor.workflowCtx.RequestString("update_verbosity"); ok {
+		return override
+	}
+	return workflowExecutor.orchestrator.config.Workflow.UpdateVerbosity
+}
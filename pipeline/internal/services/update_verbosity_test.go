@@ -0,0 +1,105 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestPublishesAgentStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		verbosity string
+		status    models.AgentStatus
+		want      bool
+	}{
+		{verbosity: UpdateVerbosityVerbose, status: models.AgentStatusProcessing, want: true},
+		{verbosity: UpdateVerbosityVerbose, status: models.AgentStatusCompleted, want: true},
+		{verbosity: UpdateVerbosityQuiet, status: models.AgentStatusProcessing, want: false},
+		{verbosity: UpdateVerbosityQuiet, status: models.AgentStatusCompleted, want: true},
+		{verbosity: UpdateVerbosityQuiet, status: models.AgentStatusFailed, want: true},
+		{verbosity: UpdateVerbosityMilestones, status: models.AgentStatusCompleted, want: false},
+		{verbosity: UpdateVerbosityMilestones, status: models.AgentStatusFailed, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := publishesAgentStatus(tt.verbosity, tt.status); got != tt.want {
+			t.Errorf("publishesAgentStatus(%s, %s) = %t, want %t", tt.verbosity, tt.status, got, tt.want)
+		}
+	}
+}
+
+// workflowMilestones are the update types sent whatever the verbosity
+var workflowMilestones = []string{string(models.UpdateTypeWorkflowStarted), string(models.UpdateTypeWorkflowCompleted),
+	string(models.UpdateTypeWorkflowError), string(models.UpdateTypeWorkflowTimeout)}
+
+func TestUpdateVerbosityFiltersTheStream(t *testing.T) {
+	tests := []struct {
+		name           string
+		env            map[string]string
+		metadata       map[string]any
+		wantProcessing bool
+		wantAgents     bool
+	}{
+		{name: "verbose by default", wantProcessing: true, wantAgents: true},
+		{name: "quiet", env: map[string]string{"UPDATE_VERBOSITY": "quiet"}, wantAgents: true},
+		{name: "milestones", env: map[string]string{"UPDATE_VERBOSITY": "milestones"}},
+		{name: "request overrides the config", env: map[string]string{"UPDATE_VERBOSITY": "milestones"},
+			metadata: map[string]any{"update_verbosity": "quiet"}, wantAgents: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, tt.env), "elections", models.IntentNewNewsQuery)
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-verbosity", Query: "what is the latest on elections", Metadata: tt.metadata,
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var processing, agents, completed bool
+			for _, update := range response.Updates {
+				if slices.Contains(workflowMilestones, update.AgentName) {
+					completed = completed || update.AgentName == string(models.UpdateTypeWorkflowCompleted)
+					continue
+				}
+				agents = true
+				processing = processing || update.Status == models.AgentStatusProcessing
+			}
+			if processing != tt.wantProcessing {
+				t.Errorf("processing updates sent = %t, want %t", processing, tt.wantProcessing)
+			}
+			if agents != tt.wantAgents {
+				t.Errorf("agent updates sent = %t, want %t", agents, tt.wantAgents)
+			}
+			if !completed {
+				t.Error("workflow_completed was not sent")
+			}
+		})
+	}
+}
+
+func TestRelevancyAgentPublishesItsCompletionOnce(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-relevancy-once")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	var completions []*models.AgentUpdate
+	for _, update := range response.Updates {
+		if update.AgentName == "relevancy_agent" && update.Status == models.AgentStatusCompleted {
+			completions = append(completions, update)
+		}
+	}
+	if len(completions) != 1 {
+		t.Fatalf("relevancy agent published %d completions, want 1", len(completions))
+	}
+	if completions[0].Message == "" {
+		t.Error("relevancy completion has no message")
+	}
+}
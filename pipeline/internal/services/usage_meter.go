@@ -0,0 +1,112 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"context"
+	"sync"
+)
+
+// ModelUsage is what one model was asked to do during a workflow
+type ModelUsage struct {
+	Calls        int `json:"calls"`
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// UsageMeter collects token counts and embedding requests across every agent call of a workflow.
+// Agents run concurrently, so it is safe for concurrent use.
+type UsageMeter struct {
+	mu     sync.Mutex
+	models map[string]*ModelUsage
+}
+
+func NewUsageMeter() *UsageMeter {
+	return &UsageMeter{models: make(map[string]*ModelUsage)}
+}
+
+func (meter *UsageMeter) record(model string, inputTokens, outputTokens int) {
+	meter.mu.Lock()
+	defer meter.mu.Unlock()
+
+	usage, ok := meter.models[model]
+	if !ok {
+		usage = &ModelUsage{}
+		meter.models[model] = usage
+	}
+	usage.Calls++
+	usage.InputTokens += inputTokens
+	usage.OutputTokens += outputTokens
+}
+
+// Snapshot copies the usage so far keyed by model
+func (meter *UsageMeter) Snapshot() map[string]ModelUsage {
+	meter.mu.Lock()
+	defer meter.mu.Unlock()
+
+	snapshot := make(map[string]ModelUsage, len(meter.models))
+	for model, usage := range meter.models {
+		snapshot[model] = *usage
+	}
+	return snapshot
+}
+
+type usageMeterKey struct{}
+
+// WithUsageMeter attaches the meter every gemini and embedding call of the workflow records into
+func WithUsageMeter(ctx context.Context, meter *UsageMeter) context.Context {
+	return context.WithValue(ctx, usageMeterKey{}, meter)
+}
+
+func usageMeterFromContext(ctx context.Context) *UsageMeter {
+	meter, _ := ctx.Value(usageMeterKey{}).(*UsageMeter)
+	return meter
+}
+
+// recordGeneration notes a gemini call's token counts, calls outside a workflow are not metered
+func recordGeneration(ctx context.Context, model string, inputTokens, outputTokens int) {
+	if meter := usageMeterFromContext(ctx); meter != nil {
+		meter.record(model, inputTokens, outputTokens)
+	}
+}
+
+// recordEmbedding notes one embedding request, these are priced per request rather than per token
+func recordEmbedding(ctx context.Context, model string) {
+	if meter := usageMeterFromContext(ctx); meter != nil {
+		meter.record(model, 0, 0)
+	}
+}
+
+// CostCalculator prices recorded usage with the configured per model or default prices
+type CostCalculator struct {
+	pricing        config.PricingConfig
+	embeddingModel string
+}
+
+func NewCostCalculator(pricing config.PricingConfig, embeddingModel string) *CostCalculator {
+	return &CostCalculator{pricing: pricing, embeddingModel: embeddingModel}
+}
+
+func (calculator *CostCalculator) price(model string) config.ModelPrice {
+	if price, ok := calculator.pricing.ModelPrices[model]; ok {
+		return price
+	}
+	if model == calculator.embeddingModel {
+		return config.ModelPrice{PerRequest: calculator.pricing.EmbeddingPerRequest}
+	}
+	return config.ModelPrice{
+		InputPer1K:  calculator.pricing.GeminiInputPer1K,
+		OutputPer1K: calculator.pricing.GeminiOutputPer1K,
+	}
+}
+
+// Cost is the estimated dollar cost of the usage
+func (calculator *CostCalculator) Cost(usage map[string]ModelUsage) float64 {
+	total := 0.0
+	for model, modelUsage := range usage {
+		price := calculator.price(model)
+		total += float64(modelUsage.InputTokens) / 1000 * price.InputPer1K
+		total += float64(modelUsage.OutputTokens) / 1000 * price.OutputPer1K
+		total += float64(modelUsage.Calls) * price.PerRequest
+	}
+	return total
+}
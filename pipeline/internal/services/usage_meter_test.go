@@ -0,0 +1,123 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCostCalculatorPricesRecordedUsage(t *testing.T) {
+	t.Parallel()
+	calculator := NewCostCalculator(config.PricingConfig{
+		GeminiInputPer1K: 0.001, GeminiOutputPer1K: 0.004, EmbeddingPerRequest: 0.0001,
+		ModelPrices: map[string]config.ModelPrice{"gemini-2.5-pro": {InputPer1K: 0.01, OutputPer1K: 0.02}},
+	}, "nomic-embed-text:latest")
+
+	tests := []struct {
+		name  string
+		usage map[string]ModelUsage
+		want  float64
+	}{
+		{name: "default gemini prices", usage: map[string]ModelUsage{"gemini-2.5-flash-lite": {Calls: 3, InputTokens: 2000, OutputTokens: 500}},
+			want: 2*0.001 + 0.5*0.004},
+		{name: "per model prices", usage: map[string]ModelUsage{"gemini-2.5-pro": {Calls: 1, InputTokens: 1000, OutputTokens: 1000}},
+			want: 0.01 + 0.02},
+		{name: "embeddings per request", usage: map[string]ModelUsage{"nomic-embed-text:latest": {Calls: 40}},
+			want: 40 * 0.0001},
+		{name: "mixed", usage: map[string]ModelUsage{
+			"gemini-2.5-flash-lite":   {Calls: 1, InputTokens: 1000},
+			"nomic-embed-text:latest": {Calls: 10},
+		}, want: 0.001 + 10*0.0001},
+		{name: "nothing used", usage: map[string]ModelUsage{}, want: 0},
+	}
+	for _, tt := range tests {
+		if got := calculator.Cost(tt.usage); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: Cost() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUsageMeterRecordsOnlyMeteredCalls(t *testing.T) {
+	t.Parallel()
+	meter := NewUsageMeter()
+	ctx := WithUsageMeter(context.Background(), meter)
+
+	recordGeneration(ctx, "gemini-2.5-flash-lite", 120, 30)
+	recordGeneration(ctx, "gemini-2.5-flash-lite", 80, 20)
+	recordEmbedding(ctx, "nomic-embed-text:latest")
+	// outside a workflow nothing is metered
+	recordGeneration(context.Background(), "gemini-2.5-flash-lite", 1000, 1000)
+
+	usage := meter.Snapshot()
+	if got, want := usage["gemini-2.5-flash-lite"], (ModelUsage{Calls: 2, InputTokens: 200, OutputTokens: 50}); got != want {
+		t.Errorf("gemini usage = %+v, want %+v", got, want)
+	}
+	if got, want := usage["nomic-embed-text:latest"], (ModelUsage{Calls: 1}); got != want {
+		t.Errorf("embedding usage = %+v, want %+v", got, want)
+	}
+}
+
+func TestWorkflowCostComesFromTheRecordedTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		input  float64
+		output float64
+	}{
+		{name: "default prices", env: map[string]string{
+			"COST_EXPOSE": "true", "COST_GEMINI_INPUT_PER_1K": "0.5", "COST_GEMINI_OUTPUT_PER_1K": "2",
+		}, input: 0.5, output: 2},
+		{name: "model prices", env: map[string]string{
+			"COST_EXPOSE": "true", "GEMINI_MODEL": "gemini-2.5-pro", "COST_MODEL_PRICES": "gemini-2.5-pro:input=3,output=7",
+		}, input: 3, output: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, tt.env), "elections", models.IntentNewNewsQuery)
+
+			response, err := workflow.run("workflow-cost")
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			// the fake API reports a quarter of the prompt length as input tokens and ten output tokens per call
+			var inputTokens, outputTokens int
+			for _, call := range workflow.gemini.received() {
+				inputTokens += len(call.Prompt) / 4
+				outputTokens += 10
+			}
+			want := float64(inputTokens)/1000*tt.input + float64(outputTokens)/1000*tt.output
+			if response.EstimatedCostUSD == nil || math.Abs(*response.EstimatedCostUSD-want) > 1e-9 {
+				t.Errorf("estimated cost = %v, want %v for %d input and %d output tokens", response.EstimatedCostUSD, want, inputTokens, outputTokens)
+			}
+		})
+	}
+}
+
+func TestWorkflowCostIsHiddenUnlessExposed(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+
+	response, err := workflow.run("workflow-cost-hidden")
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if response.EstimatedCostUSD != nil {
+		t.Errorf("estimated cost = %v, want none unless cost reporting is enabled", *response.EstimatedCostUSD)
+	}
+	if cost := workflow.orchestrator.costStats()["estimated_cost_usd"].(float64); cost <= 0 {
+		t.Errorf("running cost total = %v, want the workflow's cost counted in the metrics", cost)
+	}
+}
+
+func TestModelPricesAcceptTaggedModelNames(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"COST_MODEL_PRICES": "nomic-embed-text:latest:request=0.002; gemini-2.5-pro:input=1,output=bad"})
+
+	if got := cfg.Pricing.ModelPrices["nomic-embed-text:latest"]; got != (config.ModelPrice{PerRequest: 0.002}) {
+		t.Errorf("tagged model price = %+v, want 0.002 per request", got)
+	}
+	if got := cfg.Pricing.ModelPrices["gemini-2.5-pro"]; got != (config.ModelPrice{InputPer1K: 1}) {
+		t.Errorf("gemini-2.5-pro price = %+v, want the malformed output price ignored", got)
+	}
+}
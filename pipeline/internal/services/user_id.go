@@ -0,0 +1,40 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CanonicalUserID validates a user id before it reaches a redis key or stream name, ids with separators,
+// glob characters or control characters are rejected instead of being rewritten
+func (orchestrator *Orchestrator) CanonicalUserID(userID string) (string, error) {
+	userConfig := orchestrator.config.UserIDs
+
+	if userID == "" {
+		return "", fmt.Errorf("user_id is required")
+	}
+	if !utf8.ValidString(userID) {
+		return "", fmt.Errorf("user_id must be valid utf-8")
+	}
+	if utf8.RuneCountInString(userID) > userConfig.MaxLength {
+		return "", fmt.Errorf("user_id exceeds %d characters", userConfig.MaxLength)
+	}
+
+	for _, r := range userID {
+		switch {
+		case unicode.IsControl(r):
+			return "", fmt.Errorf("user_id must not contain control characters")
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+		case strings.ContainsRune(userConfig.AllowedPunctuation, r):
+		default:
+			return "", fmt.Errorf("user_id contains unsupported character %q", r)
+		}
+	}
+
+	if userConfig.Lowercase {
+		userID = strings.ToLower(userID)
+	}
+	return userID, nil
+}
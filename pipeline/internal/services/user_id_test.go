@@ -0,0 +1,50 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalUserID(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		lowercase bool
+		userID    string
+		want      string
+		wantErr   string
+	}{
+		{name: "plain", userID: "user-1", want: "user-1"},
+		{name: "email like", userID: "Alice.Smith_2@example.com", want: "Alice.Smith_2@example.com"},
+		{name: "folded when configured", lowercase: true, userID: "Alice@Example.com", want: "alice@example.com"},
+		{name: "empty", userID: "", wantErr: "required"},
+		{name: "key separator", userID: "user:1:conversation_context", wantErr: `unsupported character ':'`},
+		{name: "glob", userID: "user-*", wantErr: `unsupported character '*'`},
+		{name: "glob class", userID: "user[1]", wantErr: `unsupported character '['`},
+		{name: "space", userID: "user 1", wantErr: `unsupported character ' '`},
+		{name: "control character", userID: "user\n1", wantErr: "control characters"},
+		{name: "non ascii letter", userID: "usér", wantErr: `unsupported character 'é'`},
+		{name: "invalid utf8", userID: "user\xff", wantErr: "valid utf-8"},
+		{name: "at the length cap", userID: strings.Repeat("a", 32), want: strings.Repeat("a", 32)},
+		{name: "over the length cap", userID: strings.Repeat("a", 33), wantErr: "exceeds 32 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := &Orchestrator{config: config.Config{
+				UserIDs: config.UserIDConfig{MaxLength: 32, AllowedPunctuation: "-_.@", Lowercase: tt.lowercase},
+			}}
+
+			got, err := orchestrator.CanonicalUserID(tt.userID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("CanonicalUserID(%q) = %q, %v, want an error containing %q", tt.userID, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("CanonicalUserID(%q) = %q, %v, want %q", tt.userID, got, err, tt.want)
+			}
+		})
+	}
+}
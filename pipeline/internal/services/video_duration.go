@@ -0,0 +1,101 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseISODuration parses the ISO 8601 durations YouTube reports, such as PT4M13S, PT1H2M or P1DT3H.
+// Years, months and weeks are rejected since their length is ambiguous.
+func parseISODuration(value string) (time.Duration, error) {
+	rest, found := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(value)), "P")
+	if !found || rest == "" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+	}
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	// components read since the T, "PT" and "P1DT" name no time at all
+	timeComponents := 0
+
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9', r == '.':
+			number += string(r)
+		case r == 'T':
+			if inTime || number != "" {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+			}
+			inTime = true
+		default:
+			if number == "" {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+			}
+			amount, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q: %w", value, err)
+			}
+			number = ""
+
+			var unit time.Duration
+			switch {
+			case r == 'D' && !inTime:
+				unit = 24 * time.Hour
+			case r == 'H' && inTime:
+				unit = time.Hour
+			case r == 'M' && inTime:
+				unit = time.Minute
+			case r == 'S' && inTime:
+				unit = time.Second
+			default:
+				return 0, fmt.Errorf("unsupported ISO 8601 duration unit %q in %q", r, value)
+			}
+			total += time.Duration(amount * float64(unit))
+			if inTime {
+				timeComponents++
+			}
+		}
+	}
+
+	if number != "" || (inTime && timeComponents == 0) {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+	}
+	return total, nil
+}
+
+// videoDurationSeconds is the length of a video from YouTube's ISO 8601 duration, 0 when it is missing, malformed
+// or the video is a live stream, which YouTube reports as P0D
+func videoDurationSeconds(value string) int {
+	if strings.TrimSpace(value) == "" {
+		return 0
+	}
+	duration, err := parseISODuration(value)
+	if err != nil || duration < 0 {
+		return 0
+	}
+	return int(duration.Round(time.Second) / time.Second)
+}
+
+// videoDurationText is the readable length for prompts, falling back to the raw value YouTube sent
+func videoDurationText(video models.YouTubeVideo) string {
+	if text := formatVideoDuration(video.DurationSeconds); text != "" {
+		return text
+	}
+	return video.Duration
+}
+
+// formatVideoDuration renders a length the way video players do, 5:30 or 1:02:03, and "" when it is unknown
+func formatVideoDuration(seconds int) string {
+	if seconds <= 0 {
+		return ""
+	}
+	hours, minutes, secs := seconds/3600, seconds%3600/60, seconds%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, secs)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, secs)
+}
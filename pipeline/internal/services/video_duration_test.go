@@ -0,0 +1,127 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseISODuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT30S", want: 30 * time.Second},
+		{value: "PT4M13S", want: 4*time.Minute + 13*time.Second},
+		{value: "PT1H2M", want: time.Hour + 2*time.Minute},
+		{value: "pt1h0m5s", want: time.Hour + 5*time.Second},
+		{value: "P1DT3H", want: 27 * time.Hour},
+		{value: "PT1H", want: time.Hour},
+		{value: "PT0S", want: 0},
+		{value: "P0D", want: 0},
+		{value: "PT1.5S", want: 1500 * time.Millisecond},
+		{value: "", wantErr: true},
+		{value: "4M13S", wantErr: true},
+		{value: "P1M", wantErr: true},
+		{value: "PT5", wantErr: true},
+		{value: "PTM", wantErr: true},
+		{value: "P", wantErr: true},
+		{value: "PT", wantErr: true},
+		{value: "P1DT", wantErr: true},
+		{value: "P1W", wantErr: true},
+		{value: "PT1H30", wantErr: true},
+		{value: "PT1S1", wantErr: true},
+		{value: "P1H", wantErr: true},
+		{value: "PT1D", wantErr: true},
+		{value: "PT1..5S", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseISODuration(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseISODuration(%q) = %v, %v, want %v, error %t", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestVideoDurationSeconds(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "PT5M30S", want: 330},
+		{value: "PT1H", want: 3600},
+		{value: "PT1H2M3S", want: 3723},
+		{value: "PT45S", want: 45},
+		{value: "PT0S", want: 0},
+		{value: "PT1.6S", want: 2},
+		// live streams
+		{value: "P0D", want: 0},
+		{value: "", want: 0},
+		{value: "five minutes", want: 0},
+		{value: "PT", want: 0},
+	}
+	for _, tt := range tests {
+		if got := videoDurationSeconds(tt.value); got != tt.want {
+			t.Errorf("videoDurationSeconds(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFormatVideoDuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: 0, want: ""},
+		{seconds: -5, want: ""},
+		{seconds: 7, want: "0:07"},
+		{seconds: 330, want: "5:30"},
+		{seconds: 3600, want: "1:00:00"},
+		{seconds: 3723, want: "1:02:03"},
+	}
+	for _, tt := range tests {
+		if got := formatVideoDuration(tt.seconds); got != tt.want {
+			t.Errorf("formatVideoDuration(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+
+	// a video whose duration could not be parsed still shows what YouTube sent
+	if got := videoDurationText(models.YouTubeVideo{Duration: "P1W"}); got != "P1W" {
+		t.Errorf("videoDurationText() = %q, want the raw duration", got)
+	}
+}
+
+func TestVideoDetailsCarryTheParsedDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": [
+			{"id": "short", "snippet": {"title": "Short", "publishedAt": "2026-10-01T10:00:00Z"}, "contentDetails": {"duration": "PT5M30S"}},
+			{"id": "live", "snippet": {"title": "Live", "publishedAt": "2026-10-01T10:00:00Z"}, "contentDetails": {"duration": "P0D"}}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+	service := &YouTubeService{apiKey: "test-key", client: server.Client(), logger: newTestLogger(t), baseURL: server.URL}
+
+	videos, err := service.GetVideoDetails(context.Background(), []string{"short", "live"})
+	if err != nil {
+		t.Fatalf("GetVideoDetails() error = %v", err)
+	}
+	if len(videos) != 2 || videos[0].DurationSeconds != 330 || videos[1].DurationSeconds != 0 {
+		t.Fatalf("videos = %+v, want 330 seconds and an unknown live stream length", videos)
+	}
+
+	sources := buildResponseSources(nil, videos, time.Now(), "relevance")
+	durations := make(map[string]string)
+	for _, source := range sources {
+		durations[source.Title] = source.Duration
+	}
+	if durations["Short"] != "5:30" || durations["Live"] != "" {
+		t.Errorf("source durations = %v, want 5:30 and none for the live stream", durations)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newCaptionlessYouTube returns a YouTube service whose videos have no caption tracks
+func newCaptionlessYouTube(t *testing.T) *YouTubeService {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": []}`))
+	}))
+	t.Cleanup(server.Close)
+	return &YouTubeService{apiKey: "test-key", client: server.Client(), logger: newTestLogger(t), baseURL: server.URL}
+}
+
+func TestVideoFallbacksAreCappedPerWorkflow(t *testing.T) {
+	tests := []struct {
+		name          string
+		max           string
+		wantGenerated int
+	}{
+		{name: "capped", max: "2", wantGenerated: 2},
+		{name: "never generated", max: "0", wantGenerated: 0},
+		{name: "under the cap", max: "10", wantGenerated: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{"YOUTUBE_MAX_FALLBACK_GENERATIONS": tt.max, "YOUTUBE_TRANSCRIPT_CACHE_TTL": "0s"})
+			orchestrator := newTestOrchestrator(t, cfg)
+			orchestrator.youtubeService = newCaptionlessYouTube(t)
+			var gemini *fakeGemini
+			gemini, orchestrator.geminiService = newFakeGemini(t, cfg.Gemini, func(call fakeGeminiCall) string {
+				return "A generated summary of the video."
+			})
+			executor := newTestExecutor(t, orchestrator, models.WorkflowRequest{WorkflowID: "workflow-fallbacks", Query: "niche topic"})
+
+			var videos []models.YouTubeVideo
+			for i := 0; i < 5; i++ {
+				videos = append(videos, models.YouTubeVideo{ID: fmt.Sprintf("video-%d", i), Title: fmt.Sprintf("Video %d", i), Description: fmt.Sprintf("Short description %d", i)})
+			}
+			// a long description stands in for the transcript without a generation
+			longDescription := strings.Repeat("A detailed description of the video. ", 10)
+			videos = append(videos, models.YouTubeVideo{ID: "video-long", Title: "Long", Description: longDescription})
+
+			enhanced, err := executor.enhanceVideosWithTranscripts(context.Background(), videos)
+			if err != nil {
+				t.Fatalf("enhanceVideosWithTranscripts() error = %v", err)
+			}
+
+			for i, video := range enhanced[:5] {
+				want := videos[i].Description
+				if i < tt.wantGenerated {
+					want = "A generated summary of the video."
+				}
+				if video.Transcript != want {
+					t.Errorf("video %d content = %q, want %q", i, video.Transcript, want)
+				}
+			}
+			if enhanced[5].Transcript != longDescription {
+				t.Errorf("long description video content = %q, want its description", enhanced[5].Transcript)
+			}
+			if calls := len(gemini.received()); calls != tt.wantGenerated {
+				t.Errorf("gemini received %d calls, want %d", calls, tt.wantGenerated)
+			}
+			stats := executor.workflowCtx.ProcessingStats
+			if stats.VideoFallbacksGenerated != tt.wantGenerated || stats.VideoFallbacksSkipped != 5-tt.wantGenerated {
+				t.Errorf("fallbacks generated %d skipped %d, want %d and %d",
+					stats.VideoFallbacksGenerated, stats.VideoFallbacksSkipped, tt.wantGenerated, 5-tt.wantGenerated)
+			}
+		})
+	}
+}
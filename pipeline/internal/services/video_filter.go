@@ -0,0 +1,153 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// VideoFilter drops short clips and low reach videos, zero value fields are ignored
+type VideoFilter struct {
+	MinDuration    time.Duration `json:"min_duration"`
+	MinViews       int64         `json:"min_views"`
+	MinSubscribers int64         `json:"min_subscribers"`
+}
+
+func (filter VideoFilter) IsEmpty() bool {
+	return filter.MinDuration <= 0 && filter.MinViews <= 0 && filter.MinSubscribers <= 0
+}
+
+// videoFilter is the configured filter with any per request overrides applied
+func (workflowExecutor *WorkflowExecutor) videoFilter() VideoFilter {
+	youtubeConfig := workflowExecutor.orchestrator.config.Youtube
+	filter := VideoFilter{
+		MinDuration:    youtubeConfig.MinDuration,
+		MinViews:       youtubeConfig.MinViews,
+		MinSubscribers: youtubeConfig.MinSubscribers,
+	}
+
+	if seconds, ok := workflowExecutor.workflowCtx.RequestInt("min_video_duration_seconds"); ok && seconds >= 0 {
+		filter.MinDuration = time.Duration(seconds) * time.Second
+	}
+	if views, ok := workflowExecutor.workflowCtx.RequestInt("min_video_views"); ok && views >= 0 {
+		filter.MinViews = int64(views)
+	}
+	if subscribers, ok := workflowExecutor.workflowCtx.RequestInt("min_channel_subscribers"); ok && subscribers >= 0 {
+		filter.MinSubscribers = int64(subscribers)
+	}
+
+	return filter
+}
+
+// filterVideos drops videos below the filter thresholds before transcripts and relevancy are spent on them.
+// Search results carry no statistics so they are looked up first, a video whose figures stay unknown is kept.
+// Runs inside the video fetch goroutine, so the dropped count is returned rather than written to the metadata.
+func (workflowExecutor *WorkflowExecutor) filterVideos(ctx context.Context, videos []models.YouTubeVideo) ([]models.YouTubeVideo, int) {
+	filter := workflowExecutor.videoFilter()
+	if filter.IsEmpty() || len(videos) == 0 {
+		return videos, 0
+	}
+
+	youtubeService := workflowExecutor.orchestrator.youtubeService
+	videos = workflowExecutor.withVideoDetails(ctx, videos)
+
+	var subscribers map[string]int64
+	if filter.MinSubscribers > 0 {
+		var channelIDs []string
+		seen := make(map[string]bool)
+		for _, video := range videos {
+			if video.ChannelID != "" && !seen[video.ChannelID] {
+				seen[video.ChannelID] = true
+				channelIDs = append(channelIDs, video.ChannelID)
+			}
+		}
+
+		var err error
+		subscribers, err = youtubeService.GetChannelSubscribers(ctx, channelIDs)
+		if err != nil {
+			workflowExecutor.logger.WithError(err).Warn("Channel subscriber lookup failed, skipping subscriber filter")
+		}
+	}
+
+	kept := make([]models.YouTubeVideo, 0, len(videos))
+	dropped := 0
+	for _, video := range videos {
+		if reason := filter.rejects(video, subscribers); reason != "" {
+			workflowExecutor.logger.Debug("Video filtered out", "video_id", video.ID, "reason", reason)
+			dropped++
+			continue
+		}
+		kept = append(kept, video)
+	}
+
+	if dropped > 0 {
+		workflowExecutor.logger.Info("Low quality videos filtered",
+			"workflow_id", workflowExecutor.workflowCtx.ID,
+			"dropped", dropped,
+			"kept", len(kept))
+	}
+
+	return kept, dropped
+}
+
+// withVideoDetails fills in duration and view counts for videos that came from a search without them
+func (workflowExecutor *WorkflowExecutor) withVideoDetails(ctx context.Context, videos []models.YouTubeVideo) []models.YouTubeVideo {
+	var missing []string
+	for _, video := range videos {
+		if video.Duration == "" || video.ViewCount == "" {
+			missing = append(missing, video.ID)
+		}
+	}
+	if len(missing) == 0 {
+		return videos
+	}
+
+	detailed, err := workflowExecutor.orchestrator.youtubeService.GetVideoDetails(ctx, missing)
+	if err != nil {
+		workflowExecutor.logger.WithError(err).Warn("Video details lookup failed, filtering on known figures only")
+		return videos
+	}
+
+	details := make(map[string]models.YouTubeVideo, len(detailed))
+	for _, video := range detailed {
+		details[video.ID] = video
+	}
+
+	enriched := make([]models.YouTubeVideo, len(videos))
+	for i, video := range videos {
+		if detail, ok := details[video.ID]; ok {
+			video.Duration = detail.Duration
+			video.DurationSeconds = detail.DurationSeconds
+			video.ViewCount = detail.ViewCount
+			video.LikeCount = detail.LikeCount
+			video.CommentCount = detail.CommentCount
+		}
+		enriched[i] = video
+	}
+	return enriched
+}
+
+// rejects explains why a video falls below the filter, or returns "" when it passes or its figures are unknown
+func (filter VideoFilter) rejects(video models.YouTubeVideo, subscribers map[string]int64) string {
+	if filter.MinDuration > 0 && video.DurationSeconds > 0 {
+		if duration := time.Duration(video.DurationSeconds) * time.Second; duration < filter.MinDuration {
+			return fmt.Sprintf("duration %s below %s", duration, filter.MinDuration)
+		}
+	}
+
+	if filter.MinViews > 0 && video.ViewCount != "" {
+		if views, err := strconv.ParseInt(video.ViewCount, 10, 64); err == nil && views < filter.MinViews {
+			return fmt.Sprintf("%d views below %d", views, filter.MinViews)
+		}
+	}
+
+	if filter.MinSubscribers > 0 {
+		if count, ok := subscribers[video.ChannelID]; ok && count < filter.MinSubscribers {
+			return fmt.Sprintf("%d channel subscribers below %d", count, filter.MinSubscribers)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVideoFilterRejects(t *testing.T) {
+	t.Parallel()
+	filter := VideoFilter{MinDuration: time.Minute, MinViews: 1000, MinSubscribers: 5000}
+	subscribers := map[string]int64{"big": 100000, "tiny": 40}
+
+	tests := []struct {
+		name     string
+		video    models.YouTubeVideo
+		rejected bool
+	}{
+		{name: "passes", video: models.YouTubeVideo{DurationSeconds: 300, ViewCount: "25000", ChannelID: "big"}},
+		{name: "short", video: models.YouTubeVideo{DurationSeconds: 30, ViewCount: "25000", ChannelID: "big"}, rejected: true},
+		{name: "few views", video: models.YouTubeVideo{DurationSeconds: 300, ViewCount: "12", ChannelID: "big"}, rejected: true},
+		{name: "tiny channel", video: models.YouTubeVideo{DurationSeconds: 300, ViewCount: "25000", ChannelID: "tiny"}, rejected: true},
+		{name: "unknown figures are kept", video: models.YouTubeVideo{ChannelID: "hidden"}},
+	}
+	for _, tt := range tests {
+		if reason := filter.rejects(tt.video, subscribers); (reason != "") != tt.rejected {
+			t.Errorf("%s: rejects() = %q, want rejected %t", tt.name, reason, tt.rejected)
+		}
+	}
+	if reason := (VideoFilter{}).rejects(models.YouTubeVideo{DurationSeconds: 5, ViewCount: "0"}, nil); reason != "" {
+		t.Errorf("empty filter rejects() = %q, want every video kept", reason)
+	}
+}
+
+func TestVideosBelowTheFilterNeverReachRelevancy(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]any
+		want     []string
+	}{
+		{name: "configured", want: []string{"elections explained 1"}},
+		{name: "overridden per request", metadata: map[string]any{"min_video_duration_seconds": 0, "min_video_views": 0, "min_channel_subscribers": 0},
+			want: []string{"elections explained 0", "elections explained 1", "elections explained 2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newTestWorkflow(t, loadTestConfig(t, map[string]string{
+				"YOUTUBE_MIN_DURATION": "1m", "YOUTUBE_MIN_VIEWS": "1000", "YOUTUBE_MIN_SUBSCRIBERS": "5000",
+			}), "elections", models.IntentNewNewsQuery)
+			workflow.corpus.Videos = append(workflow.corpus.Videos, models.YouTubeVideo{
+				ID: "video-2", Title: "elections explained 2", Description: "A video about elections",
+				URL: "https://www.youtube.com/watch?v=video-2", Channel: "Tiny Channel", PublishedAt: time.Now(),
+			})
+			workflow.corpus.Transcripts["video-2"] = workflow.corpus.Transcripts["video-1"]
+			// a short, a long one on a big channel and a long one nobody watches on a tiny channel
+			for i, figures := range []struct {
+				duration, views, channel string
+			}{{"PT45S", "90000", "big"}, {"PT12M", "90000", "big"}, {"PT12M", "80", "tiny"}} {
+				workflow.corpus.Videos[i].Duration, workflow.corpus.Videos[i].DurationSeconds = figures.duration, videoDurationSeconds(figures.duration)
+				workflow.corpus.Videos[i].ViewCount, workflow.corpus.Videos[i].ChannelID = figures.views, figures.channel
+			}
+			workflow.corpus.ChannelSubscribers = map[string]int64{"big": 250000, "tiny": 30}
+
+			_, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-video-filter", Query: "what is the latest on elections", Metadata: tt.metadata,
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var relevancyPrompt string
+			for _, call := range workflow.gemini.received() {
+				if strings.Contains(call.SystemPrompt, "video relevancy") {
+					relevancyPrompt = call.Prompt
+				}
+			}
+			if got := len(promptVideoID.FindAllString(relevancyPrompt, -1)); got != len(tt.want) {
+				t.Errorf("video relevancy rated %d videos, want %d", got, len(tt.want))
+			}
+			for _, title := range tt.want {
+				if !strings.Contains(relevancyPrompt, title) {
+					t.Errorf("video relevancy prompt is missing %q", title)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/config"
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+)
+
+type workflowProfileKey struct{}
+
+// WithWorkflowProfile attaches the workflow type's profile to ctx so the response writing calls use its sampling
+func WithWorkflowProfile(ctx context.Context, profile config.WorkflowProfile) context.Context {
+	return context.WithValue(ctx, workflowProfileKey{}, &profile)
+}
+
+func workflowProfileFromContext(ctx context.Context) *config.WorkflowProfile {
+	profile, _ := ctx.Value(workflowProfileKey{}).(*config.WorkflowProfile)
+	return profile
+}
+
+// workflowProfileName maps a routed intent onto its profile, unknown intents run the chitchat workflow
+func workflowProfileName(intent models.Intent) string {
+	switch intent {
+	case models.IntentNewNewsQuery:
+		return "news"
+	case models.IntentFollowUpDiscussion:
+		return "follow_up"
+	default:
+		return "chitchat"
+	}
+}
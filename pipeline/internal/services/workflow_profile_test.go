@@ -0,0 +1,65 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNewsProfileAppliesWhenThePreferencesAreBlank(t *testing.T) {
+	tests := []struct {
+		name        string
+		personality string
+		want        string
+	}{
+		{name: "blank personality takes the profile's", personality: "", want: "calm-anchor"},
+		{name: "user personality wins", personality: "ai-analyst", want: "ai-analyst"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, map[string]string{
+				"WORKFLOW_PROFILES": "news:personality=calm-anchor,temperature=0.4,max_tokens=4096;chitchat:personality=youthful-trendspotter",
+			})
+			workflow := newTestWorkflow(t, cfg, "elections", models.IntentNewNewsQuery)
+
+			response, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+				UserID: "user-1", WorkflowID: "workflow-profile", Query: "who is ahead in the elections",
+				UserPreferences: models.UserPreferences{NewsPersonality: tt.personality},
+			})
+			if err != nil {
+				t.Fatalf("ExecuteWorkflow() error = %v", err)
+			}
+
+			var persona any
+			for _, execution := range response.AgentExecutions {
+				if execution.AgentName == "persona" {
+					persona = execution.Input["persona"]
+				}
+			}
+			if persona != tt.want {
+				t.Errorf("persona = %v, want %s", persona, tt.want)
+			}
+
+			// the profile's sampling shapes the written response only, never the internal agents
+			var userFacing int
+			for _, call := range workflow.gemini.received() {
+				config := call.GenerationConfig
+				profiled := config.Temperature != nil && *config.Temperature == 0.4 && config.MaxOutputTokens == 4096
+				switch {
+				case strings.Contains(call.SystemPrompt, "Multimedia News Synthesizer") || strings.Contains(call.SystemPrompt, "Content Personalizer"):
+					userFacing++
+					if !profiled {
+						t.Errorf("user facing call sampled at %v with %d tokens, want the profile's 0.4 and 4096",
+							config.Temperature, config.MaxOutputTokens)
+					}
+				case profiled:
+					t.Errorf("internal agent %.40q took the profile's sampling", call.SystemPrompt)
+				}
+			}
+			if userFacing != 2 {
+				t.Errorf("%d user facing calls, want the summary and the persona", userFacing)
+			}
+		})
+	}
+}
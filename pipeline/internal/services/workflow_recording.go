@@ -0,0 +1,372 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	WorkflowRecordingOff     = "off"
+	WorkflowRecordingRequest = "request"
+	WorkflowRecordingAll     = "all"
+)
+
+var (
+	// ErrRecordingNotFound is returned when no recording is kept for a workflow
+	ErrRecordingNotFound = errors.New("workflow recording not found")
+	// ErrReplayMissing is returned to a replayed call that has no recorded call of its kind left
+	ErrReplayMissing = errors.New("no recorded call left to replay")
+)
+
+// workflowRecorder collects the external calls of a recorded workflow, or serves them back to a replayed one
+type workflowRecorder struct {
+	replay bool
+
+	mu    sync.Mutex
+	calls []models.RecordedCall
+	// replay bookkeeping, which recorded calls were served and how
+	used     []bool
+	replayed int
+	diverged int
+	missing  int
+}
+
+type workflowRecorderKey struct{}
+
+// withWorkflowRecorder attaches a recorder to ctx, a nil recorder hides the one ctx already carries
+func withWorkflowRecorder(ctx context.Context, recorder *workflowRecorder) context.Context {
+	return context.WithValue(ctx, workflowRecorderKey{}, recorder)
+}
+
+func workflowRecorderFromContext(ctx context.Context) *workflowRecorder {
+	recorder, _ := ctx.Value(workflowRecorderKey{}).(*workflowRecorder)
+	return recorder
+}
+
+// replaying reports whether ctx belongs to a replayed workflow, which must not write to the stores it reads
+func replaying(ctx context.Context) bool {
+	recorder := workflowRecorderFromContext(ctx)
+	return recorder != nil && recorder.replay
+}
+
+// recordCall runs call and records its outcome under kind and key, or in a replay returns the recorded outcome
+// instead of calling out. The call runs without the recorder so calls it makes itself are not recorded twice.
+func recordCall[T any](ctx context.Context, recorder *workflowRecorder, kind, key string, call func(context.Context) (T, error)) (T, error) {
+	var result T
+	if recorder.replay {
+		recorded, err := recorder.next(kind, key)
+		if err != nil {
+			return result, err
+		}
+		if recorded.Error != "" {
+			return result, errors.New(recorded.Error)
+		}
+		if len(recorded.Result) > 0 {
+			if err := json.Unmarshal(recorded.Result, &result); err != nil {
+				return result, fmt.Errorf("failed to decode recorded %s call: %w", kind, err)
+			}
+		}
+		return result, nil
+	}
+
+	startTime := time.Now()
+	result, err := call(withWorkflowRecorder(ctx, nil))
+	recorder.add(kind, key, result, err, time.Since(startTime))
+	return result, err
+}
+
+func (recorder *workflowRecorder) add(kind, key string, result any, err error, duration time.Duration) {
+	recorded := models.RecordedCall{Kind: kind, Key: key, DurationMs: duration.Milliseconds()}
+	if err != nil {
+		recorded.Error = err.Error()
+	} else if resultJSON, marshalErr := json.Marshal(result); marshalErr == nil {
+		recorded.Result = resultJSON
+	} else {
+		recorded.Error = fmt.Sprintf("result could not be recorded: %v", marshalErr)
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.calls = append(recorder.calls, recorded)
+}
+
+// next serves the first unused recorded call with the same kind and key. Concurrent agents finish in a different
+// order from run to run, so failing an exact match the first unused call of the kind is served as diverged.
+func (recorder *workflowRecorder) next(kind, key string) (models.RecordedCall, error) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+
+	fallback := -1
+	for i, recorded := range recorder.calls {
+		if recorder.used[i] || recorded.Kind != kind {
+			continue
+		}
+		if recorded.Key == key {
+			recorder.used[i] = true
+			recorder.replayed++
+			return recorded, nil
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+
+	if fallback < 0 {
+		recorder.missing++
+		return models.RecordedCall{}, fmt.Errorf("%w: %s %s", ErrReplayMissing, kind, key)
+	}
+	recorder.used[fallback] = true
+	recorder.replayed++
+	recorder.diverged++
+	return recorder.calls[fallback], nil
+}
+
+// recordingEmbeddings records and replays the embeddings of the provider it wraps
+type recordingEmbeddings struct {
+	EmbeddingProvider
+}
+
+func (provider recordingEmbeddings) GenerateQueryEmbedding(ctx context.Context, text string) ([]float64, error) {
+	recorder := workflowRecorderFromContext(ctx)
+	if recorder == nil {
+		return provider.EmbeddingProvider.GenerateQueryEmbedding(ctx, text)
+	}
+	return recordCall(ctx, recorder, "query_embedding", hashContent([]byte(text)), func(ctx context.Context) ([]float64, error) {
+		return provider.EmbeddingProvider.GenerateQueryEmbedding(ctx, text)
+	})
+}
+
+func (provider recordingEmbeddings) GenerateNewsEmbedding(ctx context.Context, text string) ([]float64, error) {
+	recorder := workflowRecorderFromContext(ctx)
+	if recorder == nil {
+		return provider.EmbeddingProvider.GenerateNewsEmbedding(ctx, text)
+	}
+	return recordCall(ctx, recorder, "news_embedding", hashContent([]byte(text)), func(ctx context.Context) ([]float64, error) {
+		return provider.EmbeddingProvider.GenerateNewsEmbedding(ctx, text)
+	})
+}
+
+func (provider recordingEmbeddings) BatchGenerateNewsEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	recorder := workflowRecorderFromContext(ctx)
+	if recorder == nil {
+		return provider.EmbeddingProvider.BatchGenerateNewsEmbeddings(ctx, texts)
+	}
+	return recordCall(ctx, recorder, "news_embeddings", hashTexts(texts), func(ctx context.Context) ([][]float64, error) {
+		return provider.EmbeddingProvider.BatchGenerateNewsEmbeddings(ctx, texts)
+	})
+}
+
+func (provider recordingEmbeddings) BatchGenerateVideoEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
+	recorder := workflowRecorderFromContext(ctx)
+	if recorder == nil {
+		return provider.EmbeddingProvider.BatchGenerateVideoEmbeddings(ctx, texts)
+	}
+	return recordCall(ctx, recorder, "video_embeddings", hashTexts(texts), func(ctx context.Context) ([][]float64, error) {
+		return provider.EmbeddingProvider.BatchGenerateVideoEmbeddings(ctx, texts)
+	})
+}
+
+func hashTexts(texts []string) string {
+	textsJSON, _ := json.Marshal(texts)
+	return hashContent(textsJSON)
+}
+
+// ExecuteWorkflow runs a workflow, recording its external calls when workflow recording covers the request
+func (orchestrator *Orchestrator) ExecuteWorkflow(ctx context.Context, req *models.WorkflowRequest) (*models.WorkflowResponse, error) {
+	if !orchestrator.shouldRecord(ctx, req) {
+		return orchestrator.executeWorkflow(ctx, req)
+	}
+
+	recorder := &workflowRecorder{}
+	response, err := orchestrator.executeWorkflow(withWorkflowRecorder(ctx, recorder), req)
+	if response != nil {
+		orchestrator.saveRecording(ctx, req, recorder, response, err)
+	}
+	return response, err
+}
+
+func (orchestrator *Orchestrator) shouldRecord(ctx context.Context, req *models.WorkflowRequest) bool {
+	if workflowRecorderFromContext(ctx) != nil {
+		return false
+	}
+	switch orchestrator.config.Eval.WorkflowRecording {
+	case WorkflowRecordingAll:
+		return true
+	case WorkflowRecordingRequest:
+		record, _ := req.Metadata["record"].(bool)
+		return record
+	default:
+		return false
+	}
+}
+
+func (orchestrator *Orchestrator) recordingPath(workflowID string) (string, error) {
+	if !resultIDPattern.MatchString(workflowID) {
+		return "", fmt.Errorf("invalid workflow id for recording: %q", workflowID)
+	}
+	return filepath.Join(orchestrator.config.Eval.RecordingDir, workflowID+".json"), nil
+}
+
+// saveRecording writes the bundle of a recorded workflow, a failure only costs the ability to replay it
+func (orchestrator *Orchestrator) saveRecording(ctx context.Context, req *models.WorkflowRequest, recorder *workflowRecorder,
+	response *models.WorkflowResponse, runErr error) {
+	recorder.mu.Lock()
+	recording := &models.WorkflowRecording{
+		WorkflowID: response.WorkflowID,
+		UserID:     req.UserID,
+		Request:    *req,
+		Calls:      recorder.calls,
+		Response:   response,
+		RecordedAt: time.Now(),
+	}
+	recorder.mu.Unlock()
+	if runErr != nil {
+		recording.Error = runErr.Error()
+	}
+
+	path, err := orchestrator.recordingPath(recording.WorkflowID)
+	if err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to save workflow recording", "workflow_id", recording.WorkflowID)
+		return
+	}
+
+	// a recording holds raw sources, prompts and answers, only the service account may read it
+	recordingJSON, err := json.Marshal(recording)
+	if err == nil {
+		err = os.MkdirAll(orchestrator.config.Eval.RecordingDir, 0o700)
+	}
+	if err == nil {
+		err = os.WriteFile(path, recordingJSON, 0o600)
+	}
+	if err != nil {
+		orchestrator.logger.WithError(err).Warn("Failed to save workflow recording", "workflow_id", recording.WorkflowID)
+		return
+	}
+
+	orchestrator.logger.Info("Workflow recorded", "workflow_id", recording.WorkflowID, "calls", len(recording.Calls), "path", path)
+	orchestrator.pruneRecordings()
+}
+
+// pruneRecordings drops recordings older than the retention and then the oldest ones past the file cap,
+// judged by modification time like the file result store
+func (orchestrator *Orchestrator) pruneRecordings() {
+	evalConfig := orchestrator.config.Eval
+	entries, err := os.ReadDir(evalConfig.RecordingDir)
+	if err != nil {
+		return
+	}
+
+	type recordingFile struct {
+		path    string
+		modTime time.Time
+	}
+	var kept []recordingFile
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(evalConfig.RecordingDir, entry.Name())
+		if evalConfig.RecordingRetention > 0 && time.Since(info.ModTime()) > evalConfig.RecordingRetention {
+			os.Remove(path)
+			continue
+		}
+		kept = append(kept, recordingFile{path: path, modTime: info.ModTime()})
+	}
+
+	if evalConfig.RecordingMaxFiles <= 0 || len(kept) <= evalConfig.RecordingMaxFiles {
+		return
+	}
+	slices.SortFunc(kept, func(a, b recordingFile) int { return b.modTime.Compare(a.modTime) })
+	for _, file := range kept[evalConfig.RecordingMaxFiles:] {
+		os.Remove(file.path)
+	}
+}
+
+// LoadRecording reads the recording of a workflow, ErrRecordingNotFound when none is kept
+func (orchestrator *Orchestrator) LoadRecording(workflowID string) (*models.WorkflowRecording, error) {
+	path, err := orchestrator.recordingPath(workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordingJSON, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrRecordingNotFound
+		}
+		return nil, fmt.Errorf("failed to read workflow recording: %w", err)
+	}
+
+	var recording models.WorkflowRecording
+	if err := json.Unmarshal(recordingJSON, &recording); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow recording %s: %w", workflowID, err)
+	}
+	return &recording, nil
+}
+
+// ReplayWorkflow reruns a recorded workflow under a new id with every external call served from its recording.
+// A replay reads no live source and writes nothing back to chroma or the user's conversation.
+func (orchestrator *Orchestrator) ReplayWorkflow(ctx context.Context, workflowID string) (*models.ReplayReport, error) {
+	recording, err := orchestrator.LoadRecording(workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := recording.Request
+	req.WorkflowID = models.GenerateWorkflowID()
+	req.Metadata = maps.Clone(req.Metadata)
+	if req.Metadata == nil {
+		req.Metadata = make(map[string]interface{})
+	}
+	delete(req.Metadata, "record")
+	req.Metadata["replay_of"] = workflowID
+
+	recorder := &workflowRecorder{
+		replay: true,
+		calls:  recording.Calls,
+		used:   make([]bool, len(recording.Calls)),
+	}
+
+	orchestrator.logger.Info("Replaying workflow", "workflow_id", workflowID, "replay_workflow_id", req.WorkflowID,
+		"recorded_calls", len(recording.Calls))
+
+	response, runErr := orchestrator.executeWorkflow(withWorkflowRecorder(ctx, recorder), &req)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	report := &models.ReplayReport{
+		WorkflowID:       workflowID,
+		ReplayWorkflowID: req.WorkflowID,
+		RecordedCalls:    len(recording.Calls),
+		ReplayedCalls:    recorder.replayed,
+		DivergedCalls:    recorder.diverged,
+		MissingCalls:     recorder.missing,
+		Response:         response,
+	}
+	if recording.Response != nil {
+		report.RecordedMessage = recording.Response.Message
+	}
+	if response != nil {
+		report.ReplayedMessage = response.Message
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+	report.Matches = recording.Response != nil && response != nil && report.RecordedMessage == report.ReplayedMessage &&
+		recording.Response.Status == response.Status
+
+	return report, nil
+}
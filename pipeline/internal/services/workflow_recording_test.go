@@ -0,0 +1,108 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordedWorkflowReplaysToTheSameResponse(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"WORKFLOW_RECORDING": "all"})
+	workflow := newTestWorkflow(t, cfg, "earthquake", models.IntentNewNewsQuery)
+
+	recorded, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-recorded", Query: "latest on the earthquake",
+	})
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	geminiCalls := len(workflow.gemini.received())
+	storedArticles := len(workflow.chroma.writes(NewsCollectionName, false))
+
+	report, err := workflow.orchestrator.ReplayWorkflow(context.Background(), "workflow-recorded")
+	if err != nil {
+		t.Fatalf("ReplayWorkflow() error = %v", err)
+	}
+
+	if !report.Matches || report.ReplayedMessage != recorded.Message {
+		t.Errorf("replay answered %q, recorded %q", report.ReplayedMessage, recorded.Message)
+	}
+	if report.RecordedCalls == 0 {
+		t.Fatal("the workflow recorded no calls")
+	}
+	if report.MissingCalls != 0 || report.ReplayedCalls != report.RecordedCalls {
+		t.Errorf("replayed %d of %d recorded calls, %d missing", report.ReplayedCalls, report.RecordedCalls, report.MissingCalls)
+	}
+	if report.ReplayWorkflowID == "workflow-recorded" {
+		t.Error("the replay reused the recorded workflow id")
+	}
+	if got := len(workflow.gemini.received()); got != geminiCalls {
+		t.Errorf("the replay made %d live gemini calls", got-geminiCalls)
+	}
+	if got := len(workflow.chroma.writes(NewsCollectionName, false)); got != storedArticles {
+		t.Error("the replay wrote to chroma")
+	}
+}
+
+func TestRecordingsArePrivateToTheService(t *testing.T) {
+	cfg := loadTestConfig(t, map[string]string{"WORKFLOW_RECORDING": "all"})
+	workflow := newTestWorkflow(t, cfg, "flooding", models.IntentChitChat)
+
+	if _, err := workflow.orchestrator.ExecuteWorkflow(context.Background(), &models.WorkflowRequest{
+		UserID: "user-1", WorkflowID: "workflow-private", Query: "hello",
+	}); err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+
+	dirInfo, err := os.Stat(cfg.Eval.RecordingDir)
+	if err != nil {
+		t.Fatalf("recording dir missing: %v", err)
+	}
+	if mode := dirInfo.Mode().Perm(); mode != 0o700 {
+		t.Errorf("recording dir mode = %o, want 700", mode)
+	}
+	fileInfo, err := os.Stat(filepath.Join(cfg.Eval.RecordingDir, "workflow-private.json"))
+	if err != nil {
+		t.Fatalf("recording missing: %v", err)
+	}
+	if mode := fileInfo.Mode().Perm(); mode != 0o600 {
+		t.Errorf("recording mode = %o, want 600", mode)
+	}
+}
+
+func TestPruneRecordingsEnforcesRetentionAndFileCap(t *testing.T) {
+	cfg := loadTestConfig(t, nil)
+	cfg.Eval.RecordingDir = t.TempDir()
+	cfg.Eval.RecordingRetention = 24 * time.Hour
+	cfg.Eval.RecordingMaxFiles = 2
+	orchestrator := newTestOrchestrator(t, cfg)
+
+	ages := map[string]time.Duration{
+		"expired": 48 * time.Hour,
+		"oldest":  3 * time.Hour,
+		"older":   2 * time.Hour,
+		"newest":  time.Hour,
+	}
+	for name, age := range ages {
+		path := filepath.Join(cfg.Eval.RecordingDir, name+".json")
+		if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Now().Add(-age)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orchestrator.pruneRecordings()
+
+	for name, wantKept := range map[string]bool{"expired": false, "oldest": false, "older": true, "newest": true} {
+		_, err := os.Stat(filepath.Join(cfg.Eval.RecordingDir, name+".json"))
+		if kept := err == nil; kept != wantKept {
+			t.Errorf("%s kept = %v, want %v", name, kept, wantKept)
+		}
+	}
+}
@@ -4,24 +4,50 @@ import (
 	"Infiya-ai-pipeline/internal/models"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"Infiya-ai-pipeline/internal/config"
 	"Infiya-ai-pipeline/internal/pkg/logger"
+	"Infiya-ai-pipeline/internal/pkg/tracing"
 )
 
+// MaxYouTubeResults is the largest page the YouTube search API returns
+const MaxYouTubeResults = 50
+
+// ErrYouTubeQuotaExceeded is returned while the daily API quota is exhausted
+var ErrYouTubeQuotaExceeded = errors.New("YouTube API daily quota exceeded")
+
 type YouTubeService struct {
 	apiKey  string
 	client  *http.Client
 	logger  *logger.Logger
 	baseURL string
+
+	quotaMu             sync.Mutex
+	quotaExhaustedUntil time.Time
+
+	// set in evaluation mode, searches, details and transcripts then come from the corpus
+	fixtures *FixtureCorpus
+}
+
+type youtubeErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Errors  []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	} `json:"error"`
 }
 
 type YouTubeSearchResponse struct {
@@ -95,7 +121,7 @@ func NewYouTubeService(config config.YoutubeConfig, logger *logger.Logger) (*You
 
 	service := &YouTubeService{
 		apiKey:  config.APIKey,
-		client:  &http.Client{Timeout: 30 * time.Second},
+		client:  &http.Client{Timeout: 30 * time.Second, Transport: tracing.Transport(nil)},
 		logger:  logger,
 		baseURL: "https://www.googleapis.com/youtube/v3",
 	}
@@ -139,6 +165,15 @@ func (ys *YouTubeService) SearchVideosByQuery(ctx context.Context, query string,
 
 // searchVideos is the core search implementation
 func (ys *YouTubeService) searchVideos(ctx context.Context, query string, maxResults int, newsOnly bool) ([]models.YouTubeVideo, error) {
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "video_search", fmt.Sprintf("%s|%t", query, newsOnly), func(ctx context.Context) ([]models.YouTubeVideo, error) {
+			return ys.searchVideos(ctx, query, maxResults, newsOnly)
+		})
+	}
+	if ys.fixtures != nil {
+		return ys.fixtures.searchVideos(fixtureTerms(query), maxResults), nil
+	}
+
 	startTime := time.Now()
 
 	// Build search parameters
@@ -158,6 +193,13 @@ func (ys *YouTubeService) searchVideos(ctx context.Context, query string, maxRes
 		params.Set("videoDuration", "medium") // 4-20 minutes, good for news
 		params.Set("regionCode", "US")        // Adjust based on your target audience
 	}
+	if region := searchLocaleFromContext(ctx).Region; region != "" {
+		params.Set("regionCode", strings.ToUpper(region))
+	}
+
+	if err := ys.checkQuota(); err != nil {
+		return nil, err
+	}
 
 	searchURL := fmt.Sprintf("%s/search?%s", ys.baseURL, params.Encode())
 
@@ -174,7 +216,7 @@ func (ys *YouTubeService) searchVideos(ctx context.Context, query string, maxRes
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("YouTube API returned status %d", resp.StatusCode)
+		return nil, ys.handleErrorResponse(resp)
 	}
 
 	// Parse response
@@ -211,6 +253,14 @@ func (ys *YouTubeService) GetVideoDetails(ctx context.Context, videoIDs []string
 	if len(videoIDs) == 0 {
 		return []models.YouTubeVideo{}, nil
 	}
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "video_details", strings.Join(videoIDs, ","), func(ctx context.Context) ([]models.YouTubeVideo, error) {
+			return ys.GetVideoDetails(ctx, videoIDs)
+		})
+	}
+	if ys.fixtures != nil {
+		return ys.fixtures.videoDetails(videoIDs), nil
+	}
 
 	startTime := time.Now()
 
@@ -219,6 +269,10 @@ func (ys *YouTubeService) GetVideoDetails(ctx context.Context, videoIDs []string
 	params.Set("id", strings.Join(videoIDs, ","))
 	params.Set("key", ys.apiKey)
 
+	if err := ys.checkQuota(); err != nil {
+		return nil, err
+	}
+
 	detailsURL := fmt.Sprintf("%s/videos?%s", ys.baseURL, params.Encode())
 
 	req, err := http.NewRequestWithContext(ctx, "GET", detailsURL, nil)
@@ -233,7 +287,7 @@ func (ys *YouTubeService) GetVideoDetails(ctx context.Context, videoIDs []string
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("YouTube API returned status %d", resp.StatusCode)
+		return nil, ys.handleErrorResponse(resp)
 	}
 
 	// Parse response
@@ -264,6 +318,76 @@ func (ys *YouTubeService) GetVideoDetails(ctx context.Context, videoIDs []string
 	return videos, nil
 }
 
+type YouTubeChannelResponse struct {
+	Items []YouTubeChannelItem `json:"items"`
+}
+
+type YouTubeChannelItem struct {
+	ID         string `json:"id"`
+	Statistics struct {
+		SubscriberCount       string `json:"subscriberCount"`
+		HiddenSubscriberCount bool   `json:"hiddenSubscriberCount"`
+	} `json:"statistics"`
+}
+
+// GetChannelSubscribers returns subscriber counts by channel ID, channels hiding their count are left out
+func (ys *YouTubeService) GetChannelSubscribers(ctx context.Context, channelIDs []string) (map[string]int64, error) {
+	subscribers := make(map[string]int64)
+	if len(channelIDs) == 0 {
+		return subscribers, nil
+	}
+	if ys.fixtures != nil {
+		for _, channelID := range channelIDs {
+			if count, ok := ys.fixtures.ChannelSubscribers[channelID]; ok {
+				subscribers[channelID] = count
+			}
+		}
+		return subscribers, nil
+	}
+
+	params := url.Values{}
+	params.Set("part", "statistics")
+	params.Set("id", strings.Join(channelIDs, ","))
+	params.Set("key", ys.apiKey)
+
+	if err := ys.checkQuota(); err != nil {
+		return nil, err
+	}
+
+	channelsURL := fmt.Sprintf("%s/channels?%s", ys.baseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", channelsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create YouTube channels request: %w", err)
+	}
+
+	resp, err := ys.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("YouTube channels request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ys.handleErrorResponse(resp)
+	}
+
+	var channelResponse YouTubeChannelResponse
+	if err := json.NewDecoder(resp.Body).Decode(&channelResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode YouTube channels response: %w", err)
+	}
+
+	for _, item := range channelResponse.Items {
+		if item.Statistics.HiddenSubscriberCount {
+			continue
+		}
+		if count, err := strconv.ParseInt(item.Statistics.SubscriberCount, 10, 64); err == nil {
+			subscribers[item.ID] = count
+		}
+	}
+
+	return subscribers, nil
+}
+
 // convertToVideoModel converts YouTube search item to video model
 func (ys *YouTubeService) convertToVideoModel(item YouTubeSearchItem) (models.YouTubeVideo, error) {
 	publishedAt, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
@@ -310,7 +434,25 @@ type YoutubeCaptionsResponse struct {
 	} `json:"items"`
 }
 
+// TranscriptLanguage is the caption language GetVideoTranscript fetches
+const TranscriptLanguage = "en"
+
 func (ys *YouTubeService) GetVideoTranscript(ctx context.Context, videoID string) (string, error) {
+	if recorder := workflowRecorderFromContext(ctx); recorder != nil {
+		return recordCall(ctx, recorder, "transcript", videoID, func(ctx context.Context) (string, error) {
+			return ys.GetVideoTranscript(ctx, videoID)
+		})
+	}
+	if ys.fixtures != nil {
+		if transcript, ok := ys.fixtures.Transcripts[videoID]; ok {
+			return transcript, nil
+		}
+		return "", fmt.Errorf("No fixture transcript for video with ID %s", videoID)
+	}
+
+	if err := ys.checkQuota(); err != nil {
+		return "", err
+	}
 
 	captionsURL := fmt.Sprintf("%s/captions?part=snippet&videoId=%s&key=%s",
 		ys.baseURL, videoID, ys.apiKey)
@@ -326,13 +468,17 @@ func (ys *YouTubeService) GetVideoTranscript(ctx context.Context, videoID string
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return "", ys.handleErrorResponse(resp)
+	}
+
 	var captionsResponse YoutubeCaptionsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&captionsResponse); err != nil {
 		return "", fmt.Errorf("failed to decode captions response: %w", err)
 	}
 
 	for _, caption := range captionsResponse.Items {
-		if caption.Snippet.Language == "en" || caption.Snippet.Language == "en-US" {
+		if caption.Snippet.Language == TranscriptLanguage || caption.Snippet.Language == "en-US" {
 			return ys.downloadCaption(ctx, caption.ID)
 		}
 	}
@@ -413,12 +559,77 @@ func (ys *YouTubeService) convertDetailedVideoModel(item YouTubeVideoItem) (mode
 		CommentCount: item.Statistics.CommentCount,
 		Duration:     item.ContentDetails.Duration,
 		SourceType:   "youtube_video",
+
+		DurationSeconds: videoDurationSeconds(item.ContentDetails.Duration),
 	}
 
 	return video, nil
 }
 
+// checkQuota short-circuits calls while the quota circuit is open
+func (ys *YouTubeService) checkQuota() error {
+	ys.quotaMu.Lock()
+	defer ys.quotaMu.Unlock()
+
+	if time.Now().Before(ys.quotaExhaustedUntil) {
+		return ErrYouTubeQuotaExceeded
+	}
+	return nil
+}
+
+// handleErrorResponse turns a non 200 response into an error, tripping the quota circuit on quota errors
+func (ys *YouTubeService) handleErrorResponse(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var errorResponse youtubeErrorResponse
+	if err := json.Unmarshal(body, &errorResponse); err == nil && resp.StatusCode == http.StatusForbidden {
+		for _, apiErr := range errorResponse.Error.Errors {
+			if apiErr.Reason == "quotaExceeded" || apiErr.Reason == "dailyLimitExceeded" {
+				ys.tripQuotaCircuit()
+				return ErrYouTubeQuotaExceeded
+			}
+		}
+	}
+
+	return fmt.Errorf("YouTube API returned status %d", resp.StatusCode)
+}
+
+// tripQuotaCircuit blocks API calls until the quota resets at midnight Pacific time
+func (ys *YouTubeService) tripQuotaCircuit() {
+	resetAt := time.Now().Add(24 * time.Hour)
+	if pacific, err := time.LoadLocation("America/Los_Angeles"); err == nil {
+		now := time.Now().In(pacific)
+		resetAt = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, pacific)
+	}
+
+	ys.quotaMu.Lock()
+	ys.quotaExhaustedUntil = resetAt
+	ys.quotaMu.Unlock()
+
+	ys.logger.Warn("YouTube quota exhausted, pausing YouTube API calls", "until", resetAt)
+}
+
+// QuotaStatus reports "available" or when the exhausted quota resets
+func (ys *YouTubeService) QuotaStatus() string {
+	ys.quotaMu.Lock()
+	defer ys.quotaMu.Unlock()
+
+	if time.Now().Before(ys.quotaExhaustedUntil) {
+		return fmt.Sprintf("quota_exhausted_until %s", ys.quotaExhaustedUntil.Format(time.RFC3339))
+	}
+	return "available"
+}
+
 func (ys *YouTubeService) HealthCheck(ctx context.Context) error {
+	if ys.fixtures != nil {
+		return nil
+	}
+
+	// probing would only burn quota we do not have
+	if err := ys.checkQuota(); err != nil {
+		return err
+	}
+
 	testCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -441,5 +652,6 @@ func (ys *YouTubeService) GetStats() map[string]interface{} {
 		"api_version": "v3",
 		"base_url":    ys.baseURL,
 		"timeout":     "30s",
+		"quota":       ys.QuotaStatus(),
 	}
 }
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"Infiya-ai-pipeline/internal/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newQuotaExhaustedYouTube returns a YouTube service whose API answers every call with the given status and reason
+func newQuotaExhaustedYouTube(t *testing.T, status int, reason string) (*YouTubeService, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error": {"code": 403, "message": "The request cannot be completed", "errors": [{"reason": "` + reason + `"}]}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &YouTubeService{apiKey: "test-key", client: server.Client(), logger: newTestLogger(t), baseURL: server.URL}, &calls
+}
+
+func TestYouTubeQuotaErrorTripsTheCircuit(t *testing.T) {
+	service, calls := newQuotaExhaustedYouTube(t, http.StatusForbidden, "quotaExceeded")
+
+	if _, err := service.SearchVideosByQuery(context.Background(), "elections", 5); !errors.Is(err, ErrYouTubeQuotaExceeded) {
+		t.Fatalf("SearchVideosByQuery() error = %v, want ErrYouTubeQuotaExceeded", err)
+	}
+	if status := service.QuotaStatus(); !strings.HasPrefix(status, "quota_exhausted_until") {
+		t.Errorf("QuotaStatus() = %q, want the circuit reported open", status)
+	}
+
+	// every API call short-circuits until the quota resets, nothing reaches YouTube
+	if _, err := service.SearchNewsVideos(context.Background(), []string{"elections"}, 5); !errors.Is(err, ErrYouTubeQuotaExceeded) {
+		t.Errorf("SearchNewsVideos() error = %v, want ErrYouTubeQuotaExceeded", err)
+	}
+	if _, err := service.GetVideoDetails(context.Background(), []string{"video-1"}); !errors.Is(err, ErrYouTubeQuotaExceeded) {
+		t.Errorf("GetVideoDetails() error = %v, want ErrYouTubeQuotaExceeded", err)
+	}
+	if _, err := service.GetVideoTranscript(context.Background(), "video-1"); !errors.Is(err, ErrYouTubeQuotaExceeded) {
+		t.Errorf("GetVideoTranscript() error = %v, want ErrYouTubeQuotaExceeded", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("YouTube API received %d calls, want only the one that tripped the circuit", got)
+	}
+}
+
+func TestYouTubeOtherErrorsLeaveTheCircuitClosed(t *testing.T) {
+	service, calls := newQuotaExhaustedYouTube(t, http.StatusForbidden, "forbidden")
+
+	for i := 0; i < 2; i++ {
+		if _, err := service.SearchVideosByQuery(context.Background(), "elections", 5); err == nil || errors.Is(err, ErrYouTubeQuotaExceeded) {
+			t.Fatalf("SearchVideosByQuery() error = %v, want a plain API error", err)
+		}
+	}
+	if status := service.QuotaStatus(); status != "available" {
+		t.Errorf("QuotaStatus() = %q, want available", status)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("YouTube API received %d calls, want every call to go through", got)
+	}
+}
+
+func TestWorkflowServesCachedVideosWhileTheQuotaIsExhausted(t *testing.T) {
+	workflow := newTestWorkflow(t, loadTestConfig(t, nil), "elections", models.IntentNewNewsQuery)
+	youtube, _ := newQuotaExhaustedYouTube(t, http.StatusForbidden, "quotaExceeded")
+	workflow.orchestrator.youtubeService = youtube
+
+	// a video an earlier workflow stored for the same query
+	cached := workflow.corpus.Videos[0]
+	cached.ID = "cached-video"
+	cached.URL = "https://www.youtube.com/watch?v=cached-video"
+	if err := workflow.orchestrator.chromaDBService.StoreVideos(context.Background(), []models.YouTubeVideo{cached},
+		[][]float64{fakeEmbedding("latest elections news")}); err != nil {
+		t.Fatalf("StoreVideos() error = %v", err)
+	}
+
+	response, err := workflow.run("workflow-quota")
+	if err != nil || response.Status != string(models.WorkflowStatusCompleted) {
+		t.Fatalf("ExecuteWorkflow() = %v, %v", response, err)
+	}
+
+	var servedFromCache bool
+	for _, source := range response.Sources {
+		servedFromCache = servedFromCache || source.URL == cached.URL
+	}
+	if !servedFromCache {
+		t.Errorf("sources %+v do not include the cached video", response.Sources)
+	}
+}
+
+func TestYouTubeSearchUsesTheUsersRegion(t *testing.T) {
+	var regionCodes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		regionCodes = append(regionCodes, r.URL.Query().Get("regionCode"))
+		w.Write([]byte(`{"items": []}`))
+	}))
+	t.Cleanup(server.Close)
+	service := &YouTubeService{apiKey: "test-key", client: server.Client(), logger: newTestLogger(t), baseURL: server.URL}
+
+	ctx := WithSearchLocale(context.Background(), SearchLocale{Region: "in"})
+	if _, err := service.SearchNewsVideos(ctx, []string{"election results"}, 5); err != nil {
+		t.Fatalf("SearchNewsVideos() error = %v", err)
+	}
+	if _, err := service.SearchNewsVideos(context.Background(), []string{"election results"}, 5); err != nil {
+		t.Fatalf("SearchNewsVideos() error = %v", err)
+	}
+
+	if len(regionCodes) != 2 || regionCodes[0] != "IN" || regionCodes[1] != "US" {
+		t.Errorf("regionCode per search = %v, want IN for the indian user and the US default otherwise", regionCodes)
+	}
+}